@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/gob"
+	"expvar"
 	"log"
 	"log/slog"
 	"net/http"
@@ -11,11 +12,13 @@ import (
 	"github.com/delordemm1/qplayground/internal/core/config"
 	"github.com/delordemm1/qplayground/internal/modules/auth"
 	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/delordemm1/qplayground/internal/modules/media"
 	"github.com/delordemm1/qplayground/internal/modules/notification"
 	"github.com/delordemm1/qplayground/internal/modules/organization"
 	"github.com/delordemm1/qplayground/internal/modules/project"
 	"github.com/delordemm1/qplayground/internal/modules/storage"
 	"github.com/delordemm1/qplayground/internal/platform"
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -23,6 +26,13 @@ import (
 	_ "github.com/delordemm1/qplayground/internal/plugins/playwright"
 	_ "github.com/delordemm1/qplayground/internal/plugins/r2"
 	_ "github.com/delordemm1/qplayground/internal/plugins/api"
+	_ "github.com/delordemm1/qplayground/internal/plugins/data"
+	_ "github.com/delordemm1/qplayground/internal/plugins/util"
+	_ "github.com/delordemm1/qplayground/internal/plugins/clock"
+	_ "github.com/delordemm1/qplayground/internal/plugins/store"
+	_ "github.com/delordemm1/qplayground/internal/plugins/coordination"
+	_ "github.com/delordemm1/qplayground/internal/plugins/pool"
+	_ "github.com/delordemm1/qplayground/internal/plugins/metric"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -71,9 +81,8 @@ func main() {
 	storageService := storage.NewStorageService(r2Storage)
 
 	// MEDIA Dependencies
-	// imageProcessor := media.NewBimgProcessor()
-	// mediaService := media.NewMediaService(imageProcessor)
-	// _ = mediaService
+	imageProcessor := media.NewBimgProcessor()
+	mediaService := media.NewMediaService(imageProcessor)
 
 	// ORGANIZATION Dependencies
 	organizationRepo := organization.NewOrganizationRepository(pool)
@@ -86,8 +95,30 @@ func main() {
 	// AUTOMATION Dependencies
 	automationRepo := automation.NewAutomationRepository(pool)
 	runCache := automation.NewRedisRunCache(redisClient)
-	automationService := automation.NewAutomationService(automationRepo, runCache, pool)
+	automationService := automation.NewAutomationService(automationRepo, runCache, pool, notificationService)
 	automationRunner := automation.NewRunner(automationRepo, storageService, notificationService, sseManager)
+	// Heartbeat run progress so the scheduler can detect stalled runs
+	automationRunner.SetRunCache(runCache)
+
+	// Let store:* actions share encrypted data within and across runs when a key is configured
+	if platform.ENV_KV_ENCRYPTION_KEY != "" {
+		kvStore, err := automation.NewRedisKVStore(redisClient, platform.ENV_KV_ENCRYPTION_KEY)
+		if err != nil {
+			slog.Error("Failed to initialize key-value store, store actions disabled", "error", err)
+		} else {
+			automationRunner.SetKVStore(kvStore)
+		}
+	}
+
+	// Load extra languages for notifications and reports; English is built in
+	if platform.ENV_REPORT_BUNDLES_DIR != "" {
+		locales, err := i18n.LoadDir(platform.ENV_REPORT_BUNDLES_DIR)
+		if err != nil {
+			slog.Error("Failed to load report message bundles", "dir", platform.ENV_REPORT_BUNDLES_DIR, "error", err)
+		} else {
+			slog.Info("Loaded report message bundles", "locales", locales)
+		}
+	}
 
 	// Initialize automation scheduler
 	scheduler := automation.NewScheduler(automationRepo, automationService, runCache, automationRunner, sseManager)
@@ -117,14 +148,77 @@ func main() {
 	// Sync Redis with database on startup
 	syncRedisRunsOnStartup(pool, runCache)
 
+	// Upgrade stored configs to the latest schemas registered by plugins
+	if _, err := automationService.UpgradeStoredConfigs(context.Background()); err != nil {
+		slog.Error("Failed to upgrade stored automation configs", "error", err)
+	}
+
+	// Stream finished runs to the analytics warehouse when one is configured
+	if platform.ENV_WAREHOUSE_SINK != "" {
+		warehouseSink, err := automation.NewWarehouseSink(context.Background(), platform.ENV_WAREHOUSE_SINK, platform.ENV_WAREHOUSE_DSN, platform.ENV_WAREHOUSE_DATASET)
+		if err != nil {
+			slog.Error("Failed to initialize warehouse sink, run export disabled", "sink", platform.ENV_WAREHOUSE_SINK, "error", err)
+		} else {
+			warehouseExporter := automation.NewWarehouseExporter(automationRepo, warehouseSink)
+			warehouseExporter.Start(context.Background())
+			defer warehouseExporter.Stop()
+			scheduler.SetWarehouseExporter(warehouseExporter)
+		}
+	}
+
+	// Generate automation drafts from plain-English scenarios when an LLM backend is configured
+	var draftGenerator automation.DraftGenerator
+	if platform.ENV_DRAFT_LLM_BACKEND != "" {
+		generator, err := automation.NewDraftGenerator(platform.ENV_DRAFT_LLM_BACKEND, automation.DraftBackendConfig{
+			APIURL: platform.ENV_DRAFT_LLM_API_URL,
+			APIKey: platform.ENV_DRAFT_LLM_API_KEY,
+			Model:  platform.ENV_DRAFT_LLM_MODEL,
+		})
+		if err != nil {
+			slog.Error("Failed to initialize draft generator, automation drafts disabled", "backend", platform.ENV_DRAFT_LLM_BACKEND, "error", err)
+		} else {
+			draftGenerator = generator
+		}
+	}
+
+	// Flag runs and steps whose durations deviate from recent history
+	anomalyDetector := automation.NewAnomalyDetector(automationRepo, notificationService)
+	anomalyDetector.Start(context.Background())
+	defer anomalyDetector.Stop()
+	scheduler.SetAnomalyDetector(anomalyDetector)
+
+	// Email subscribers of saved run views about matching runs
+	runViewNotifier := automation.NewRunViewNotifier(automationRepo, notificationService)
+	runViewNotifier.Start(context.Background())
+	defer runViewNotifier.Stop()
+	scheduler.SetRunViewNotifier(runViewNotifier)
+
+	// Render summary cards of finished runs for link previews
+	runPreviewGenerator := automation.NewRunPreviewGenerator(automationRepo, storageService, mediaService)
+	runPreviewGenerator.Start(context.Background())
+	defer runPreviewGenerator.Stop()
+	scheduler.SetRunPreviewGenerator(runPreviewGenerator)
+
 	// Start automation scheduler
 	scheduler.Start(context.Background())
 	defer scheduler.Stop()
 
+	// Kill browser processes leaked by crashed runs
+	processWatchdog := automation.NewProcessWatchdog(scheduler.IsRunActive)
+	processWatchdog.Start(context.Background())
+	defer processWatchdog.Stop()
+
 	// Public routes (guest only)
 	publicRouter := web.NewPublicRouter(web.NewPublicHandler(i, sessionManager))
 	r.Mount("/", publicRouter)
 
+	// Public key run evidence bundles are signed with
+	r.Get("/.well-known/evidence-signing-key", web.EvidenceSigningKey)
+
+	// Read-only share links (no account required)
+	shareRouter := web.NewShareRouter(web.NewShareHandler(automationService))
+	r.Mount("/share", shareRouter)
+
 	// Authentication routes
 	authHandler := web.NewAuthHandler(i, sessionManager, authService)
 	authRouter := web.NewAuthRouter(authHandler)
@@ -145,8 +239,12 @@ func main() {
 			}
 		})
 
+		// Runtime metrics (expvar), including process watchdog leak counts; they expose the
+		// process command line and memory stats, so only platform admins may read them
+		r.With(authMiddleware.OnlyAdmin).Handle("/debug/vars", expvar.Handler())
+
 		// Organization routes
-		organizationHandler := web.NewOrganizationHandler(i, sessionManager, organizationService)
+		organizationHandler := web.NewOrganizationHandler(i, sessionManager, organizationService, automationService)
 		organizationRouter := web.NewOrganizationRouter(organizationHandler)
 		r.Mount("/organizations", organizationRouter)
 
@@ -157,7 +255,7 @@ func main() {
 
 		// Automation routes (nested under projects)
 		r.Route("/projects/{projectId}/automations", func(r chi.Router) {
-			automationHandler := web.NewAutomationHandler(i, sessionManager, automationService, projectService, scheduler, sseManager)
+			automationHandler := web.NewAutomationHandler(i, sessionManager, automationService, projectService, organizationService, scheduler, sseManager, draftGenerator)
 			automationRouter := web.NewAutomationRouter(automationHandler)
 			r.Mount("/", automationRouter)
 			// Nested routes for steps and actions
@@ -168,6 +266,24 @@ func main() {
 			})
 		})
 
+		// Dataset routes (nested under projects)
+		r.Route("/projects/{projectId}/datasets", func(r chi.Router) {
+			datasetRouter := web.NewDatasetRouter(web.NewDatasetHandler(automationService, projectService))
+			r.Mount("/", datasetRouter)
+		})
+
+		// Account pool routes (nested under projects)
+		r.Route("/projects/{projectId}/account-pools", func(r chi.Router) {
+			accountPoolRouter := web.NewAccountPoolRouter(web.NewAccountPoolHandler(automationService, projectService))
+			r.Mount("/", accountPoolRouter)
+		})
+
+		// Translation catalog routes (nested under projects), used by playwright:assert_text message keys
+		r.Route("/projects/{projectId}/translations", func(r chi.Router) {
+			translationCatalogRouter := web.NewTranslationCatalogRouter(web.NewTranslationCatalogHandler(automationService, projectService))
+			r.Mount("/", translationCatalogRouter)
+		})
+
 		// Mount SSE server for automation events
 		r.Mount("/events/", sseManager.GetServer())
 
@@ -203,7 +319,7 @@ func syncRedisRunsOnStartup(pool *pgxpool.Pool, runCache automation.RunCache) {
 	query := `
 		SELECT id, automation_id, status, start_time, end_time, logs_json, output_files_json, error_message, created_at, updated_at
 		FROM automation_runs
-		WHERE status IN ('pending', 'running', 'queued', 'completed', 'failed', 'cancelled')
+		WHERE status IN ('pending', 'running', 'stalled', 'queued', 'completed', 'failed', 'cancelled')
 		ORDER BY created_at DESC
 	`
 
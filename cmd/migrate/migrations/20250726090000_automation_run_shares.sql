@@ -0,0 +1,45 @@
+-- +goose Up
+/*
+# Create automation run shares table
+
+1. New Tables
+  - `automation_run_shares`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `run_id` (uuid, not null, foreign key to automation_runs.id)
+    - `token` (text, not null, unique) - opaque token embedded in the share URL
+    - `created_by_user_id` (uuid, nullable, foreign key to users.id)
+    - `expires_at` (timestamptz, not null)
+    - `revoked_at` (timestamptz, nullable)
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Unique index on token for share lookups
+  - Index on run_id for listing shares of a run
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_run_shares (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id uuid NOT NULL,
+    token text NOT NULL,
+    created_by_user_id uuid,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (run_id) REFERENCES automation_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_automation_run_shares_token
+    ON automation_run_shares(token);
+
+CREATE INDEX IF NOT EXISTS idx_automation_run_shares_run_id
+    ON automation_run_shares(run_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_automation_run_shares_token;
+DROP INDEX IF EXISTS idx_automation_run_shares_run_id;
+DROP TABLE IF EXISTS automation_run_shares;
+-- +goose StatementEnd
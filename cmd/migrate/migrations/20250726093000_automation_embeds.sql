@@ -0,0 +1,43 @@
+-- +goose Up
+/*
+# Create automation embeds table
+
+1. New Tables
+  - `automation_embeds`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `automation_id` (uuid, not null, foreign key to automations.id)
+    - `token` (text, not null, unique) - opaque token embedded in the widget URL
+    - `created_by_user_id` (uuid, nullable, foreign key to users.id)
+    - `revoked_at` (timestamptz, nullable)
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Unique index on token for widget lookups
+  - Index on automation_id for listing embeds of an automation
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_embeds (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    automation_id uuid NOT NULL,
+    token text NOT NULL,
+    created_by_user_id uuid,
+    revoked_at timestamptz,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_automation_embeds_token
+    ON automation_embeds(token);
+
+CREATE INDEX IF NOT EXISTS idx_automation_embeds_automation_id
+    ON automation_embeds(automation_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_automation_embeds_token;
+DROP INDEX IF EXISTS idx_automation_embeds_automation_id;
+DROP TABLE IF EXISTS automation_embeds;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- # Add settings_json column to organizations table
+
+-- 1. Changes
+--   - Add `settings_json` column to `organizations` table
+--   - Column type: jsonb, not null, with default value '{}'
+--   - This will store org-wide automation defaults and policies (default browser,
+--     default timeout, mandatory screenshot-on-error, default notifications, run retention)
+
+-- +goose StatementBegin
+DO $$ 
+BEGIN
+    -- Add settings_json column if it doesn't exist
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'organizations' 
+        AND column_name = 'settings_json'
+    ) THEN
+        ALTER TABLE organizations ADD COLUMN settings_json jsonb NOT NULL DEFAULT '{}';
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE organizations DROP COLUMN IF EXISTS settings_json;
+-- +goose StatementEnd
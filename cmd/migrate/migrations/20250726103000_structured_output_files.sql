@@ -0,0 +1,50 @@
+-- +goose Up
+-- # Convert automation run output files to structured records
+
+-- 1. Changes
+--   - `output_files_json` entries change from plain URL strings to objects
+--     ({"url", "kind", "content_type", "size", "step_name", "action_type", "loop_index", ...})
+--   - Existing string entries become {"url": <url>, "kind": <inferred kind>}
+--   - The kind is inferred from the file extension; unknown types become 'other'
+
+-- +goose StatementBegin
+UPDATE automation_runs
+SET output_files_json = (
+    SELECT COALESCE(jsonb_agg(
+        CASE
+            WHEN jsonb_typeof(entry) = 'string' THEN jsonb_build_object(
+                'url', entry #>> '{}',
+                'kind', CASE
+                    WHEN entry #>> '{}' ~* '\.(png|jpe?g|gif|webp)(\?|$)' THEN 'screenshot'
+                    WHEN entry #>> '{}' ~* '\.(webm|mp4)(\?|$)' THEN 'video'
+                    WHEN entry #>> '{}' ~* '\.(html?|pdf|json)(\?|$)' THEN 'report'
+                    ELSE 'other'
+                END,
+                'loop_index', 0,
+                'local_loop_index', 0
+            )
+            ELSE entry
+        END
+        ORDER BY position
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(output_files_json) WITH ORDINALITY AS files(entry, position)
+)
+WHERE jsonb_typeof(output_files_json) = 'array'
+AND EXISTS (
+    SELECT 1 FROM jsonb_array_elements(output_files_json) AS files(entry)
+    WHERE jsonb_typeof(entry) = 'string'
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE automation_runs
+SET output_files_json = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN jsonb_typeof(entry) = 'object' THEN entry -> 'url' ELSE entry END
+        ORDER BY position
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(output_files_json) WITH ORDINALITY AS files(entry, position)
+)
+WHERE jsonb_typeof(output_files_json) = 'array';
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+/*
+# Create automation run anomalies table
+
+1. New Tables
+  - `automation_run_anomalies`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `run_id` (uuid, not null, foreign key to automation_runs.id)
+    - `automation_id` (uuid, not null, foreign key to automations.id)
+    - `scope` (text, not null) - 'run' for the whole run, 'step' for a single step
+    - `step_id` (text, nullable) - set when scope is 'step'
+    - `step_name` (text, nullable)
+    - `duration_ms` (bigint, not null) - the anomalous duration
+    - `baseline_mean_ms` (double precision, not null) - mean of recent completed runs
+    - `baseline_stddev_ms` (double precision, not null)
+    - `deviation` (double precision, not null) - distance from the mean in standard deviations
+    - `sample_size` (integer, not null) - number of runs in the baseline
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Index on run_id for tagging a run's anomalies
+  - Index on automation_id for listing an automation's anomalies
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_run_anomalies (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id uuid NOT NULL,
+    automation_id uuid NOT NULL,
+    scope text NOT NULL,
+    step_id text,
+    step_name text,
+    duration_ms bigint NOT NULL,
+    baseline_mean_ms double precision NOT NULL,
+    baseline_stddev_ms double precision NOT NULL,
+    deviation double precision NOT NULL,
+    sample_size integer NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (run_id) REFERENCES automation_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_run_anomalies_run_id
+    ON automation_run_anomalies(run_id);
+
+CREATE INDEX IF NOT EXISTS idx_automation_run_anomalies_automation_id
+    ON automation_run_anomalies(automation_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_automation_run_anomalies_run_id;
+DROP INDEX IF EXISTS idx_automation_run_anomalies_automation_id;
+DROP TABLE IF EXISTS automation_run_anomalies;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+/*
+# Add execution profile tag filters to automation runs
+
+1. Changes
+  - `automation_runs.include_tags` (text[], not null, default '{}') - when non-empty, only steps and actions with one of these tags run
+  - `automation_runs.exclude_tags` (text[], not null, default '{}') - steps and actions with any of these tags are skipped
+
+2. Notes
+  - Steps and actions are tagged through a `tags` array in their config JSON
+  - Existing runs get empty filters, matching how they ran
+*/
+
+-- +goose StatementBegin
+ALTER TABLE automation_runs
+    ADD COLUMN IF NOT EXISTS include_tags text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS exclude_tags text[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE automation_runs
+    DROP COLUMN IF EXISTS exclude_tags,
+    DROP COLUMN IF EXISTS include_tags;
+-- +goose StatementEnd
@@ -0,0 +1,72 @@
+-- +goose Up
+/*
+# Create datasets and dataset versions tables
+
+1. New Tables
+  - `datasets`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `project_id` (uuid, not null, foreign key to projects.id)
+    - `name` (text, not null) - unique per project, used by automations to reference the dataset
+    - `source_url` (text, not null) - external URL returning the records as JSON or CSV
+    - `schema_json` (text, not null, default '{}') - required fields and their types, minimum record count
+    - `refresh_interval_minutes` (integer, not null, default 0) - 0 refreshes on demand only
+    - `current_version` (integer, not null, default 0) - 0 until the first successful refresh
+    - `last_refreshed_at` (timestamptz, nullable) - last refresh attempt, successful or not
+    - `last_refresh_error` (text, nullable) - why the last refresh failed
+    - `created_at` (timestamptz, default now())
+    - `updated_at` (timestamptz, default now())
+  - `dataset_versions`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `dataset_id` (uuid, not null, foreign key to datasets.id)
+    - `version` (integer, not null) - increasing per dataset
+    - `records_json` (jsonb, not null) - array of records
+    - `record_count` (integer, not null)
+    - `checksum` (text, not null) - sha256 of the records, so unchanged sources add no version
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Unique (project_id, name) on datasets
+  - Unique (dataset_id, version) on dataset_versions
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS datasets (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL,
+    name text NOT NULL,
+    source_url text NOT NULL,
+    schema_json text NOT NULL DEFAULT '{}',
+    refresh_interval_minutes integer NOT NULL DEFAULT 0,
+    current_version integer NOT NULL DEFAULT 0,
+    last_refreshed_at timestamptz,
+    last_refresh_error text,
+    created_at timestamptz DEFAULT now(),
+    updated_at timestamptz DEFAULT now(),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_datasets_project_id_name
+    ON datasets(project_id, name);
+
+CREATE TABLE IF NOT EXISTS dataset_versions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    dataset_id uuid NOT NULL,
+    version integer NOT NULL,
+    records_json jsonb NOT NULL,
+    record_count integer NOT NULL,
+    checksum text NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (dataset_id) REFERENCES datasets(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dataset_versions_dataset_id_version
+    ON dataset_versions(dataset_id, version);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_dataset_versions_dataset_id_version;
+DROP TABLE IF EXISTS dataset_versions;
+DROP INDEX IF EXISTS idx_datasets_project_id_name;
+DROP TABLE IF EXISTS datasets;
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+-- +goose Up
+/*
+# Create account pools and pool accounts tables
+
+1. New Tables
+  - `account_pools`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `project_id` (uuid, not null, foreign key to projects.id)
+    - `name` (text, not null)
+    - `environment` (text, not null, default 'default') - e.g. staging, production
+    - `lease_ttl_seconds` (integer, not null, default 3600) - leases older than this are treated as abandoned
+    - `created_at` (timestamptz, default now())
+    - `updated_at` (timestamptz, default now())
+  - `pool_accounts`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `pool_id` (uuid, not null, foreign key to account_pools.id)
+    - `label` (text, not null) - e.g. the username, shown in the UI and logs
+    - `credentials` (jsonb, not null, default '{}') - fields exposed to the leasing user, e.g. username and password
+    - `state` (text, not null, default 'available') - 'available', 'leased' or 'dirty'
+    - `leased_by_run_id` (uuid, nullable, foreign key to automation_runs.id)
+    - `lease_loop_index` (integer, nullable) - the user of the run holding the lease
+    - `leased_at` (timestamptz, nullable)
+    - `lease_expires_at` (timestamptz, nullable)
+    - `last_used_at` (timestamptz, nullable) - least recently used accounts are leased first
+    - `created_at` (timestamptz, default now())
+    - `updated_at` (timestamptz, default now())
+
+2. Indexes
+  - Unique (project_id, environment, name) on account_pools
+  - Index on (pool_id, state) for leasing
+  - Index on leased_by_run_id for releasing a run's leases
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS account_pools (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL,
+    name text NOT NULL,
+    environment text NOT NULL DEFAULT 'default',
+    lease_ttl_seconds integer NOT NULL DEFAULT 3600,
+    created_at timestamptz DEFAULT now(),
+    updated_at timestamptz DEFAULT now(),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_pools_project_environment_name
+    ON account_pools(project_id, environment, name);
+
+CREATE TABLE IF NOT EXISTS pool_accounts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    pool_id uuid NOT NULL,
+    label text NOT NULL,
+    credentials jsonb NOT NULL DEFAULT '{}',
+    state text NOT NULL DEFAULT 'available',
+    leased_by_run_id uuid,
+    lease_loop_index integer,
+    leased_at timestamptz,
+    lease_expires_at timestamptz,
+    last_used_at timestamptz,
+    created_at timestamptz DEFAULT now(),
+    updated_at timestamptz DEFAULT now(),
+    FOREIGN KEY (pool_id) REFERENCES account_pools(id) ON DELETE CASCADE,
+    FOREIGN KEY (leased_by_run_id) REFERENCES automation_runs(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_accounts_pool_id_state
+    ON pool_accounts(pool_id, state);
+
+CREATE INDEX IF NOT EXISTS idx_pool_accounts_leased_by_run_id
+    ON pool_accounts(leased_by_run_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pool_accounts_leased_by_run_id;
+DROP INDEX IF EXISTS idx_pool_accounts_pool_id_state;
+DROP TABLE IF EXISTS pool_accounts;
+DROP INDEX IF EXISTS idx_account_pools_project_environment_name;
+DROP TABLE IF EXISTS account_pools;
+-- +goose StatementEnd
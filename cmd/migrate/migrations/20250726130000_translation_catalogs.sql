@@ -0,0 +1,46 @@
+-- +goose Up
+/*
+# Create translation catalogs table and add a locale to automation runs
+
+1. New Tables
+  - `translation_catalogs`
+    - `id` (uuid, primary key, default gen_random_uuid())
+    - `project_id` (uuid, not null, foreign key to projects.id)
+    - `locale` (text, not null) - e.g. en, fr, pt-BR
+    - `messages` (jsonb, not null, default '{}') - message key (dotted for nested catalogs) -> translated text
+    - `created_at` (timestamptz, default now())
+    - `updated_at` (timestamptz, default now())
+
+2. Changes
+  - `automation_runs.locale` (text, not null, default '') - locale every user of the run resolves message keys in;
+    when empty, users take turns through the automation's configured locales
+
+3. Indexes
+  - Unique (project_id, locale) on translation_catalogs
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS translation_catalogs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL,
+    locale text NOT NULL,
+    messages jsonb NOT NULL DEFAULT '{}',
+    created_at timestamptz DEFAULT now(),
+    updated_at timestamptz DEFAULT now(),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_translation_catalogs_project_id_locale
+    ON translation_catalogs(project_id, locale);
+
+ALTER TABLE automation_runs
+    ADD COLUMN IF NOT EXISTS locale text NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE automation_runs
+    DROP COLUMN IF EXISTS locale;
+DROP INDEX IF EXISTS idx_translation_catalogs_project_id_locale;
+DROP TABLE IF EXISTS translation_catalogs;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+/*
+# Link automatic retries to the runs they retry
+
+1. Changes
+  - `automation_runs.parent_run_id` (uuid, nullable, foreign key to automation_runs.id) - the failed run
+    this run automatically retries; null for runs that aren't retries
+  - `automation_runs.attempt` (integer, not null, default 1) - 1 for the first attempt, 2 for its first retry...
+
+2. Indexes
+  - Index on parent_run_id for finding a run's retry
+*/
+
+-- +goose StatementBegin
+ALTER TABLE automation_runs
+    ADD COLUMN IF NOT EXISTS parent_run_id uuid REFERENCES automation_runs(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS attempt integer NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_parent_run_id
+    ON automation_runs(parent_run_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_automation_runs_parent_run_id;
+ALTER TABLE automation_runs
+    DROP COLUMN IF EXISTS attempt,
+    DROP COLUMN IF EXISTS parent_run_id;
+-- +goose StatementEnd
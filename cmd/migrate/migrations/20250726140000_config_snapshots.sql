@@ -0,0 +1,37 @@
+-- +goose Up
+/*
+# Create automation config snapshots table
+
+1. New Tables
+  - `automation_config_snapshots`
+    - `automation_id` (uuid, primary key, foreign key to automations.id)
+    - `config_json` (jsonb, not null) - exported config as last synced with version control, without action IDs
+    - `config_hash` (text, not null) - sha256 of config_json
+    - `commit_ref` (text, not null, default '') - commit the snapshot was synced at
+    - `synced_by_user_id` (uuid, nullable, foreign key to users.id)
+    - `synced_at` (timestamptz, default now())
+    - `drift_detected_at` (timestamptz, nullable) - when the automation was first seen differing from the snapshot
+    - `drift_checked_at` (timestamptz, nullable)
+    - `drift_paths` (jsonb, not null, default '[]') - config paths that differ from the snapshot
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_config_snapshots (
+    automation_id uuid PRIMARY KEY,
+    config_json jsonb NOT NULL,
+    config_hash text NOT NULL,
+    commit_ref text NOT NULL DEFAULT '',
+    synced_by_user_id uuid,
+    synced_at timestamptz DEFAULT now(),
+    drift_detected_at timestamptz,
+    drift_checked_at timestamptz,
+    drift_paths jsonb NOT NULL DEFAULT '[]',
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE,
+    FOREIGN KEY (synced_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_config_snapshots;
+-- +goose StatementEnd
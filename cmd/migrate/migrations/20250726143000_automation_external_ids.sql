@@ -0,0 +1,31 @@
+-- +goose Up
+/*
+# Create automation external IDs table
+
+1. New Tables
+  - `automation_external_ids`
+    - `project_id` (uuid, foreign key to projects.id)
+    - `external_id` (text, not null) - ID an infrastructure-as-code tool manages the automation by
+    - `automation_id` (uuid, unique, foreign key to automations.id)
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Primary key on (project_id, external_id)
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_external_ids (
+    project_id uuid NOT NULL,
+    external_id text NOT NULL,
+    automation_id uuid NOT NULL UNIQUE,
+    created_at timestamptz DEFAULT now(),
+    PRIMARY KEY (project_id, external_id),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_external_ids;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+/*
+# Create faker dictionaries table
+
+1. New Tables
+  - `faker_dictionaries`
+    - `id` (uuid, primary key)
+    - `organization_id` (uuid, foreign key to organizations.id)
+    - `name` (text, not null) - set name used in {{faker.custom.<name>}}
+    - `values` (jsonb, not null) - array of the set's values
+    - `created_at` (timestamptz, default now())
+    - `updated_at` (timestamptz, default now())
+
+2. Indexes
+  - Unique index on (organization_id, name)
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS faker_dictionaries (
+    id uuid PRIMARY KEY,
+    organization_id uuid NOT NULL,
+    name text NOT NULL,
+    "values" jsonb NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    updated_at timestamptz DEFAULT now(),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX IF NOT EXISTS idx_faker_dictionaries_organization_id_name ON faker_dictionaries(organization_id, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS faker_dictionaries;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+/*
+# Create automation run replay events table
+
+1. New Tables
+  - `automation_run_replay_events`
+    - `run_id` (uuid, not null, foreign key to automation_runs.id)
+    - `seq` (integer, not null) - position of the event in the run's timeline
+    - `offset_ms` (bigint, not null) - time since the run started, never decreasing with seq
+    - `kind` (text, not null) - step_start, step_end, action_start, action_end, action_failed, warning, output_file or variable
+    - `loop_index` (integer, not null, default 0) - user the event belongs to
+    - `step_id` (text, not null, default '')
+    - `step_name` (text, not null, default '')
+    - `action_id` (text, not null, default '')
+    - `action_type` (text, not null, default '')
+    - `data` (jsonb, not null, default '{}') - kind specific details, e.g. the variables an action changed
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Primary key on (run_id, seq) for paging through a run's timeline
+  - Index on (run_id, offset_ms) for seeking to a point in time
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_run_replay_events (
+    run_id uuid NOT NULL,
+    seq integer NOT NULL,
+    offset_ms bigint NOT NULL,
+    kind text NOT NULL,
+    loop_index integer NOT NULL DEFAULT 0,
+    step_id text NOT NULL DEFAULT '',
+    step_name text NOT NULL DEFAULT '',
+    action_id text NOT NULL DEFAULT '',
+    action_type text NOT NULL DEFAULT '',
+    data jsonb NOT NULL DEFAULT '{}',
+    created_at timestamptz DEFAULT now(),
+    PRIMARY KEY (run_id, seq),
+    FOREIGN KEY (run_id) REFERENCES automation_runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_run_replay_events_offset
+    ON automation_run_replay_events(run_id, offset_ms);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_run_replay_events;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+/*
+# Create automation owners table
+
+1. New Tables
+  - `automation_owners`
+    - `automation_id` (uuid, not null, foreign key to automations.id)
+    - `owner_type` (text, not null) - user or team
+    - `owner_id` (text, not null) - user ID, or the name of a team in the organization's settings
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Primary key on (automation_id, owner_type, owner_id)
+  - Index on (owner_type, owner_id) for finding the automations someone owns
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_owners (
+    automation_id uuid NOT NULL,
+    owner_type text NOT NULL CHECK (owner_type IN ('user', 'team')),
+    owner_id text NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    PRIMARY KEY (automation_id, owner_type, owner_id),
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_owners_owner
+    ON automation_owners(owner_type, owner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_owners;
+-- +goose StatementEnd
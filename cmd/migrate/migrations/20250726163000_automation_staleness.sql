@@ -0,0 +1,40 @@
+-- +goose Up
+/*
+# Create automation staleness table
+
+1. New Tables
+  - `automation_staleness`
+    - `automation_id` (uuid, primary key, foreign key to automations.id)
+    - `reasons` (jsonb, not null) - why the automation is stale: "unused" and/or "failing"
+    - `last_run_at` (timestamptz) - when the automation last ran, as of the last check
+    - `failing_since` (timestamptz) - first failed run since the automation last succeeded
+    - `detected_at` (timestamptz) - when the automation became stale, null while it isn't
+    - `checked_at` (timestamptz, default now())
+    - `disabled_at` (timestamptz) - when the automation was disabled for being stale
+    - `reenabled_at` (timestamptz) - when it was last re-enabled, staleness is measured from here
+
+2. Indexes
+  - Index on detected_at for listing stale automations
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_staleness (
+    automation_id uuid PRIMARY KEY,
+    reasons jsonb NOT NULL DEFAULT '[]',
+    last_run_at timestamptz,
+    failing_since timestamptz,
+    detected_at timestamptz,
+    checked_at timestamptz DEFAULT now(),
+    disabled_at timestamptz,
+    reenabled_at timestamptz,
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_staleness_detected_at
+    ON automation_staleness(detected_at) WHERE detected_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_staleness;
+-- +goose StatementEnd
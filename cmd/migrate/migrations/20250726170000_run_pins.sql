@@ -0,0 +1,33 @@
+-- +goose Up
+/*
+# Create run pins table
+
+1. New Tables
+  - `automation_run_pins`
+    - `run_id` (uuid, primary key, foreign key to automation_runs.id)
+    - `label` (text, not null) - why the run is kept, e.g. "release 2.3 evidence"
+    - `pinned_by_user_id` (uuid, nullable, foreign key to users.id)
+    - `pinned_at` (timestamptz, default now())
+
+2. Indexes
+  - Index on label for listing the runs pinned under a label
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_run_pins (
+    run_id uuid PRIMARY KEY,
+    label text NOT NULL,
+    pinned_by_user_id uuid,
+    pinned_at timestamptz DEFAULT now(),
+    FOREIGN KEY (run_id) REFERENCES automation_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (pinned_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_run_pins_label
+    ON automation_run_pins(label);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_run_pins;
+-- +goose StatementEnd
@@ -0,0 +1,71 @@
+-- +goose Up
+/*
+# Create config rollout tables
+
+1. New Tables
+  - `automation_config_rollouts`
+    - `id` (uuid, primary key)
+    - `automation_id` (uuid, not null, foreign key to automations.id) - the stable automation
+    - `candidate_automation_id` (uuid, nullable, foreign key to automations.id) - the copy holding the new version
+    - `shadow_runs` (integer, not null) - shadow runs to compare before promoting
+    - `status` (varchar, not null, default 'active') - active, promoted or aborted
+    - `started_by_user_id` (uuid, nullable, foreign key to users.id)
+    - `started_at` (timestamptz, default now())
+    - `finished_at` (timestamptz, nullable)
+    - `compared`, `matching`, `regressions`, `fixes` (integer, default 0) - comparison summary recorded when the rollout finishes
+  - `automation_config_rollout_runs`
+    - `shadow_run_id` (uuid, primary key, foreign key to automation_runs.id) - run of the candidate
+    - `rollout_id` (uuid, not null, foreign key to automation_config_rollouts.id)
+    - `stable_run_id` (uuid, not null, foreign key to automation_runs.id) - run of the stable automation it shadows
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Unique index on automation_id for active rollouts, so an automation has at most one
+  - Index on candidate_automation_id for finding the rollout a candidate belongs to
+  - Index on rollout_id for listing the runs of a rollout
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_config_rollouts (
+    id uuid PRIMARY KEY,
+    automation_id uuid NOT NULL,
+    candidate_automation_id uuid,
+    shadow_runs integer NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'active',
+    started_by_user_id uuid,
+    started_at timestamptz DEFAULT now(),
+    finished_at timestamptz,
+    compared integer NOT NULL DEFAULT 0,
+    matching integer NOT NULL DEFAULT 0,
+    regressions integer NOT NULL DEFAULT 0,
+    fixes integer NOT NULL DEFAULT 0,
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_automation_id) REFERENCES automations(id) ON DELETE SET NULL,
+    FOREIGN KEY (started_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_automation_config_rollouts_active
+    ON automation_config_rollouts(automation_id) WHERE status = 'active';
+
+CREATE INDEX IF NOT EXISTS idx_automation_config_rollouts_candidate
+    ON automation_config_rollouts(candidate_automation_id);
+
+CREATE TABLE IF NOT EXISTS automation_config_rollout_runs (
+    shadow_run_id uuid PRIMARY KEY,
+    rollout_id uuid NOT NULL,
+    stable_run_id uuid NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (shadow_run_id) REFERENCES automation_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (rollout_id) REFERENCES automation_config_rollouts(id) ON DELETE CASCADE,
+    FOREIGN KEY (stable_run_id) REFERENCES automation_runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_config_rollout_runs_rollout
+    ON automation_config_rollout_runs(rollout_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_config_rollout_runs;
+DROP TABLE IF EXISTS automation_config_rollouts;
+-- +goose StatementEnd
@@ -0,0 +1,65 @@
+-- +goose Up
+/*
+# Record who triggered runs and what runs are filtered by, and index runs for filtering
+
+1. Changes
+  - `automation_runs.triggered_by_user_id` (uuid, nullable, foreign key to users.id) - user who triggered the
+    run; null for automatic retries and runs triggered before it was recorded
+  - `automation_runs.failed_steps` (text[], not null, default '{}') - names of the steps an action failed in,
+    recorded when the run finishes
+  - `automation_runs.tags` (text[], not null, default '{}') - tags of the steps and actions the run executed,
+    recorded when the run finishes
+  - Finished runs are backfilled: failed steps from their logs, tags from their include tags
+
+2. Indexes
+  - Index on (automation_id, created_at) for listing an automation's runs newest first
+  - Index on triggered_by_user_id for triggered_by filters
+  - GIN index on tags for tag filters
+  - GIN index on failed_steps for failed_step filters
+  - Index on the run duration for duration filters
+*/
+
+-- +goose StatementBegin
+ALTER TABLE automation_runs
+    ADD COLUMN IF NOT EXISTS triggered_by_user_id uuid REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS failed_steps text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS tags text[] NOT NULL DEFAULT '{}';
+
+UPDATE automation_runs ar
+SET failed_steps = ARRAY(
+        SELECT DISTINCT entry->>'step_name'
+        FROM jsonb_array_elements(ar.logs_json) entry
+        WHERE entry->>'status' = 'failed' AND COALESCE(entry->>'step_name', '') <> ''
+    ),
+    tags = ar.include_tags
+WHERE ar.status IN ('completed', 'failed', 'cancelled')
+    AND jsonb_typeof(ar.logs_json) = 'array';
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_automation_created
+    ON automation_runs(automation_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_triggered_by
+    ON automation_runs(triggered_by_user_id);
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_tags
+    ON automation_runs USING gin (tags);
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_failed_steps
+    ON automation_runs USING gin (failed_steps);
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_duration
+    ON automation_runs((end_time - start_time));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_automation_runs_duration;
+DROP INDEX IF EXISTS idx_automation_runs_failed_steps;
+DROP INDEX IF EXISTS idx_automation_runs_tags;
+DROP INDEX IF EXISTS idx_automation_runs_triggered_by;
+DROP INDEX IF EXISTS idx_automation_runs_automation_created;
+ALTER TABLE automation_runs
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS failed_steps,
+    DROP COLUMN IF EXISTS triggered_by_user_id;
+-- +goose StatementEnd
@@ -0,0 +1,61 @@
+-- +goose Up
+/*
+# Create saved run view tables
+
+1. New Tables
+  - `run_views`
+    - `id` (uuid, primary key)
+    - `project_id` (uuid, not null, foreign key to projects.id)
+    - `name` (varchar, not null)
+    - `filter` (text, not null) - filter in the runs filter DSL
+    - `created_by_user_id` (uuid, nullable, foreign key to users.id)
+    - `shared` (boolean, default false) - whether other project members see the view
+    - `created_at` (timestamptz, default now())
+    - `updated_at` (timestamptz, default now())
+  - `run_view_subscriptions`
+    - `view_id` (uuid, foreign key to run_views.id)
+    - `user_id` (uuid, foreign key to users.id) - subscriber notified of new matching runs
+    - `created_at` (timestamptz, default now())
+    - `last_notified_at` (timestamptz, nullable)
+
+2. Indexes
+  - Unique index on (project_id, created_by_user_id, name), so a user's view names are unique per project
+  - Index on user_id for listing a user's subscriptions
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS run_views (
+    id uuid PRIMARY KEY,
+    project_id uuid NOT NULL,
+    name varchar(100) NOT NULL,
+    filter text NOT NULL,
+    created_by_user_id uuid,
+    shared boolean NOT NULL DEFAULT false,
+    created_at timestamptz DEFAULT now(),
+    updated_at timestamptz DEFAULT now(),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_run_views_project_user_name
+    ON run_views(project_id, created_by_user_id, name);
+
+CREATE TABLE IF NOT EXISTS run_view_subscriptions (
+    view_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    last_notified_at timestamptz,
+    PRIMARY KEY (view_id, user_id),
+    FOREIGN KEY (view_id) REFERENCES run_views(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_view_subscriptions_user
+    ON run_view_subscriptions(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS run_view_subscriptions;
+DROP TABLE IF EXISTS run_views;
+-- +goose StatementEnd
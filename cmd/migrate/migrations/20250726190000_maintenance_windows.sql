@@ -0,0 +1,54 @@
+-- +goose Up
+/*
+# Create maintenance calendar tables
+
+1. New Tables
+  - `maintenance_windows`
+    - `id` (uuid, primary key)
+    - `organization_id` (uuid, not null, foreign key to organizations.id)
+    - `uid` (varchar, not null) - UID of the calendar event
+    - `summary` (text, not null)
+    - `starts_at` (timestamptz, not null)
+    - `ends_at` (timestamptz, not null)
+    - `synced_at` (timestamptz, default now())
+  - `automation_run_maintenance`
+    - `run_id` (uuid, primary key, foreign key to automation_runs.id) - run executed during a window
+    - `summary` (text, not null) - copied from the window, which is replaced on every sync
+    - `starts_at` (timestamptz, not null)
+    - `ends_at` (timestamptz, not null)
+    - `created_at` (timestamptz, default now())
+
+2. Indexes
+  - Index on (organization_id, ends_at) for finding the windows of an organization around a time
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id uuid PRIMARY KEY,
+    organization_id uuid NOT NULL,
+    uid varchar(255) NOT NULL,
+    summary text NOT NULL,
+    starts_at timestamptz NOT NULL,
+    ends_at timestamptz NOT NULL,
+    synced_at timestamptz DEFAULT now(),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_org_ends
+    ON maintenance_windows(organization_id, ends_at);
+
+CREATE TABLE IF NOT EXISTS automation_run_maintenance (
+    run_id uuid PRIMARY KEY,
+    summary text NOT NULL,
+    starts_at timestamptz NOT NULL,
+    ends_at timestamptz NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (run_id) REFERENCES automation_runs(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_run_maintenance;
+DROP TABLE IF EXISTS maintenance_windows;
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+/*
+# Create artifact fixtures table
+
+1. New Tables
+  - `automation_artifact_fixtures`
+    - `id` (uuid, primary key)
+    - `automation_id` (uuid, not null, foreign key to automations.id)
+    - `name` (varchar, not null) - matched against the names of downloaded files
+    - `action_id` (uuid, nullable, foreign key to automation_actions.id) - action whose downloads are compared
+    - `compare_mode` (varchar, not null) - auto, text, json or binary
+    - `content_type` (varchar, not null)
+    - `data` (bytea, not null) - the expected file
+    - `size` (bigint, not null)
+    - `sha256` (varchar, not null)
+    - `created_by_user_id` (uuid, nullable, foreign key to users.id)
+    - `created_at` (timestamptz, default now())
+    - `updated_at` (timestamptz, default now())
+
+2. Indexes
+  - Unique index on (automation_id, name)
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_artifact_fixtures (
+    id uuid PRIMARY KEY,
+    automation_id uuid NOT NULL,
+    name varchar(255) NOT NULL,
+    action_id uuid,
+    compare_mode varchar(16) NOT NULL DEFAULT 'auto',
+    content_type varchar(255) NOT NULL DEFAULT 'application/octet-stream',
+    data bytea NOT NULL,
+    size bigint NOT NULL,
+    sha256 varchar(64) NOT NULL,
+    created_by_user_id uuid,
+    created_at timestamptz DEFAULT now(),
+    updated_at timestamptz DEFAULT now(),
+    FOREIGN KEY (automation_id) REFERENCES automations(id) ON DELETE CASCADE,
+    FOREIGN KEY (action_id) REFERENCES automation_actions(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_automation_artifact_fixtures_name
+    ON automation_artifact_fixtures(automation_id, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_artifact_fixtures;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+/*
+# Create run previews table
+
+1. New Tables
+  - `automation_run_previews`
+    - `run_id` (uuid, primary key, foreign key to automation_runs.id)
+    - `image_url` (text, not null) - PNG summary card of the finished run
+    - `created_at` (timestamptz, default now())
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_run_previews (
+    run_id uuid PRIMARY KEY,
+    image_url text NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (run_id) REFERENCES automation_runs(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_run_previews;
+-- +goose StatementEnd
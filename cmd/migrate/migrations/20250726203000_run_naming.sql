@@ -0,0 +1,47 @@
+-- +goose Up
+/*
+# Number runs per automation and store their display names
+
+1. Changes
+  - `automations.run_sequence` (integer, not null, default 0) - number of the automation's latest run
+  - `automation_runs.sequence` (integer, not null, default 0) - number of the run among its automation's runs,
+    starting at 1; existing runs are numbered in creation order
+  - `automation_runs.display_name` (text, not null, default '') - rendered from the automation's
+    naming.runName template; empty for runs of automations without one
+*/
+
+-- +goose StatementBegin
+ALTER TABLE automations
+    ADD COLUMN IF NOT EXISTS run_sequence integer NOT NULL DEFAULT 0;
+
+ALTER TABLE automation_runs
+    ADD COLUMN IF NOT EXISTS sequence integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS display_name text NOT NULL DEFAULT '';
+
+UPDATE automation_runs ar
+SET sequence = numbered.sequence
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY automation_id ORDER BY created_at, id) AS sequence
+    FROM automation_runs
+) numbered
+WHERE ar.id = numbered.id;
+
+UPDATE automations a
+SET run_sequence = counts.latest
+FROM (
+    SELECT automation_id, max(sequence) AS latest
+    FROM automation_runs
+    GROUP BY automation_id
+) counts
+WHERE a.id = counts.automation_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE automation_runs
+    DROP COLUMN IF EXISTS display_name,
+    DROP COLUMN IF EXISTS sequence;
+
+ALTER TABLE automations
+    DROP COLUMN IF EXISTS run_sequence;
+-- +goose StatementEnd
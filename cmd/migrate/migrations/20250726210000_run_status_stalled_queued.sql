@@ -0,0 +1,30 @@
+-- +goose Up
+/*
+# Allow queued and stalled run statuses
+
+1. Changes
+  - `automation_runs.status` also accepts 'queued' (waiting for a concurrency lock) and 'stalled'
+    (running without progress for longer than the automation's stall threshold)
+*/
+
+-- +goose StatementBegin
+ALTER TABLE automation_runs
+    DROP CONSTRAINT IF EXISTS automation_runs_status_check;
+
+ALTER TABLE automation_runs
+    ADD CONSTRAINT automation_runs_status_check
+    CHECK (status IN ('pending', 'queued', 'running', 'stalled', 'completed', 'failed', 'cancelled'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE automation_runs SET status = 'pending' WHERE status = 'queued';
+UPDATE automation_runs SET status = 'running' WHERE status = 'stalled';
+
+ALTER TABLE automation_runs
+    DROP CONSTRAINT IF EXISTS automation_runs_status_check;
+
+ALTER TABLE automation_runs
+    ADD CONSTRAINT automation_runs_status_check
+    CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled'));
+-- +goose StatementEnd
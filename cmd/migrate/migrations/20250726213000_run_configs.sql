@@ -0,0 +1,26 @@
+-- +goose Up
+/*
+# Create run configs table
+
+1. New Tables
+  - `automation_run_configs`
+    - `run_id` (uuid, primary key, foreign key to automation_runs.id)
+    - `config_hash` (text, not null) - hash of the config, as compared against config snapshots
+    - `config_json` (jsonb, not null) - the automation's exported config when the run was triggered
+    - `created_at` (timestamptz, default now())
+*/
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS automation_run_configs (
+    run_id uuid PRIMARY KEY,
+    config_hash text NOT NULL,
+    config_json jsonb NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    FOREIGN KEY (run_id) REFERENCES automation_runs(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS automation_run_configs;
+-- +goose StatementEnd
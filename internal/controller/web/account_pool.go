@@ -0,0 +1,348 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/delordemm1/qplayground/internal/modules/auth"
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/delordemm1/qplayground/internal/modules/project"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewAccountPoolRouter(accountPoolHandler *AccountPoolHandler) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", accountPoolHandler.ListAccountPools)
+	r.Post("/", accountPoolHandler.CreateAccountPool)
+	r.Get("/{poolId}", accountPoolHandler.GetAccountPool)
+	r.Delete("/{poolId}", accountPoolHandler.DeleteAccountPool)
+
+	// Accounts of a pool; reset makes a dirty or stuck account available again
+	r.Get("/{poolId}/accounts", accountPoolHandler.ListPoolAccounts)
+	r.Post("/{poolId}/accounts", accountPoolHandler.AddPoolAccounts)
+	r.Delete("/{poolId}/accounts/{accountId}", accountPoolHandler.DeletePoolAccount)
+	r.Post("/{poolId}/accounts/{accountId}/reset", accountPoolHandler.ResetPoolAccount)
+
+	return r
+}
+
+func NewAccountPoolHandler(automationService automation.AutomationService, projectService project.ProjectService) *AccountPoolHandler {
+	return &AccountPoolHandler{
+		automationService: automationService,
+		projectService:    projectService,
+	}
+}
+
+// AccountPoolHandler serves the JSON API for project account pools
+type AccountPoolHandler struct {
+	automationService automation.AutomationService
+	projectService    project.ProjectService
+}
+
+type CreateAccountPoolRequest struct {
+	Name            string `json:"name" validate:"required,min=1,max=255"`
+	Environment     string `json:"environment" validate:"max=100"`
+	LeaseTTLSeconds int    `json:"lease_ttl_seconds" validate:"min=0"`
+}
+
+type PoolAccountRequest struct {
+	Label       string                 `json:"label" validate:"max=255"`
+	Credentials map[string]interface{} `json:"credentials"`
+}
+
+type AddPoolAccountsRequest struct {
+	Accounts []PoolAccountRequest `json:"accounts" validate:"required,min=1,max=500,dive"`
+}
+
+func (h *AccountPoolHandler) ListAccountPools(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	pools, err := h.automationService.GetAccountPoolsByProject(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list account pools"})
+		return
+	}
+	if pools == nil {
+		pools = []*automation.AccountPool{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pools": pools,
+	})
+}
+
+func (h *AccountPoolHandler) CreateAccountPool(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req CreateAccountPoolRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	pool, err := h.automationService.CreateAccountPool(r.Context(), &automation.AccountPool{
+		ProjectID:       projectID,
+		Name:            req.Name,
+		Environment:     req.Environment,
+		LeaseTTLSeconds: req.LeaseTTLSeconds,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Failed to create account pool")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Account pool created successfully",
+		"pool":    pool,
+	})
+}
+
+func (h *AccountPoolHandler) GetAccountPool(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	pool, err := h.verifyPoolAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "poolId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pool": pool,
+	})
+}
+
+func (h *AccountPoolHandler) DeleteAccountPool(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	pool, err := h.verifyPoolAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "poolId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.DeleteAccountPool(r.Context(), pool.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete account pool"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Account pool deleted successfully"})
+}
+
+// ListPoolAccounts returns a pool's accounts with their credentials and current leases
+func (h *AccountPoolHandler) ListPoolAccounts(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	pool, err := h.verifyPoolAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "poolId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	accounts, err := h.automationService.GetPoolAccounts(r.Context(), pool.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list pool accounts"})
+		return
+	}
+	if accounts == nil {
+		accounts = []*automation.PoolAccount{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"accounts": accounts,
+	})
+}
+
+func (h *AccountPoolHandler) AddPoolAccounts(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	pool, err := h.verifyPoolAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "poolId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req AddPoolAccountsRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	accounts := make([]*automation.PoolAccount, len(req.Accounts))
+	for i, account := range req.Accounts {
+		accounts[i] = &automation.PoolAccount{Label: account.Label, Credentials: account.Credentials}
+	}
+
+	accounts, err = h.automationService.AddPoolAccounts(r.Context(), pool.ID, accounts)
+	if err != nil {
+		writeServiceError(w, err, "Failed to add pool accounts")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  fmt.Sprintf("%d accounts added successfully", len(accounts)),
+		"accounts": accounts,
+	})
+}
+
+func (h *AccountPoolHandler) DeletePoolAccount(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	pool, err := h.verifyPoolAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "poolId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.DeletePoolAccount(r.Context(), pool.ID, chi.URLParam(r, "accountId")); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete pool account"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Pool account deleted successfully"})
+}
+
+// ResetPoolAccount makes an account available again, ending any lease on it
+func (h *AccountPoolHandler) ResetPoolAccount(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	pool, err := h.verifyPoolAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "poolId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.ResetPoolAccount(r.Context(), pool.ID, chi.URLParam(r, "accountId")); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Pool account not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Pool account reset successfully"})
+}
+
+// decodeJSONRequest decodes and validates a request body into req, writing the error
+// response and returning false when the request is invalid
+func decodeJSONRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return false
+	}
+
+	if err := validate.Struct(req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return false
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return false
+	}
+	return true
+}
+
+func (h *AccountPoolHandler) verifyProjectAccess(ctx context.Context, user *auth.User, projectID string) error {
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project not found")
+	}
+
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		return fmt.Errorf("access denied to project")
+	}
+	return nil
+}
+
+func (h *AccountPoolHandler) verifyPoolAccess(ctx context.Context, user *auth.User, projectID, poolID string) (*automation.AccountPool, error) {
+	if err := h.verifyProjectAccess(ctx, user, projectID); err != nil {
+		return nil, err
+	}
+
+	pool, err := h.automationService.GetAccountPoolByID(ctx, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("account pool not found")
+	}
+
+	if pool.ProjectID != projectID {
+		return nil, fmt.Errorf("access denied to account pool")
+	}
+	return pool, nil
+}
@@ -3,12 +3,19 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/delordemm1/qplayground/internal/modules/auth"
 	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/delordemm1/qplayground/internal/modules/organization"
 	"github.com/delordemm1/qplayground/internal/modules/project"
 	"github.com/delordemm1/qplayground/internal/platform"
 	"github.com/go-playground/validator/v10"
@@ -23,6 +30,9 @@ func NewAutomationRouter(automationHandler *AutomationHandler) chi.Router {
 
 	r.Get("/", automationHandler.ListAutomations)
 	r.Post("/", automationHandler.CreateAutomation)
+	r.Post("/import", automationHandler.ImportAutomation)
+	r.Post("/drafts", automationHandler.GenerateAutomationDraft)
+	r.Post("/import/recording", automationHandler.ImportSessionRecording)
 	r.Get("/{id}", automationHandler.GetAutomation)
 	r.Put("/{id}", automationHandler.UpdateAutomation)
 	r.Delete("/{id}", automationHandler.DeleteAutomation)
@@ -32,29 +42,131 @@ func NewAutomationRouter(automationHandler *AutomationHandler) chi.Router {
 	r.Put("/{id}/steps/{stepId}", automationHandler.UpdateStep)
 	r.Delete("/{id}/steps/{stepId}", automationHandler.DeleteStep)
 
+	// Execute actions of a step in a throwaway browser from the editor, without a run
+	r.Post("/{id}/steps/{stepId}/test", automationHandler.TestStepActions)
+
 	// Run management
 	r.Post("/{id}/runs", automationHandler.TriggerRun)
 	r.Get("/{id}/runs", automationHandler.ListRuns)
 	r.Get("/{id}/runs/{runId}", automationHandler.GetRun)
 	r.Post("/{id}/runs/{runId}/cancel", automationHandler.CancelRun)
 
+	// Debug console for users paused by playwright:pause, results are sent over SSE
+	r.Get("/{id}/runs/{runId}/console", automationHandler.GetRunConsole)
+	r.Post("/{id}/runs/{runId}/console", automationHandler.SendConsoleCommand)
+	r.Post("/{id}/runs/{runId}/console/resume", automationHandler.ResumeConsole)
+
+	// Read-only share links for runs
+	r.Get("/{id}/runs/{runId}/shares", automationHandler.ListRunShares)
+	r.Post("/{id}/runs/{runId}/shares", automationHandler.CreateRunShare)
+	r.Delete("/{id}/runs/{runId}/shares/{shareId}", automationHandler.RevokeRunShare)
+
+	// Pinned runs are never deleted by retention
+	r.Get("/pinned-runs", automationHandler.ListPinnedRuns)
+
+	r.Put("/{id}/runs/{runId}/pin", automationHandler.PinRun)
+	r.Delete("/{id}/runs/{runId}/pin", automationHandler.UnpinRun)
+
+	// Runs of every automation of the project matching a filter, see automation.ParseRunFilter
+	r.Get("/runs", automationHandler.SearchRuns)
+
+	// Saved run filters, which users can subscribe to for emails about matching runs
+	r.Get("/run-views", automationHandler.ListRunViews)
+	r.Post("/run-views", automationHandler.CreateRunView)
+	r.Put("/run-views/{viewId}", automationHandler.UpdateRunView)
+	r.Delete("/run-views/{viewId}", automationHandler.DeleteRunView)
+	r.Put("/run-views/{viewId}/subscription", automationHandler.SubscribeRunView)
+	r.Delete("/run-views/{viewId}/subscription", automationHandler.UnsubscribeRunView)
+
+	// Duration anomalies
+	r.Get("/{id}/anomalies", automationHandler.ListAutomationAnomalies)
+	r.Get("/{id}/runs/{runId}/anomalies", automationHandler.ListRunAnomalies)
+
+	// Run reports
+	r.Get("/{id}/runs/{runId}/report", automationHandler.GetRunReport)
+	r.Get("/{id}/runs/{runId}/evidence", automationHandler.ExportRunEvidence)
+
+	// Retry diffs
+	r.Get("/{id}/runs/{runId}/diff", automationHandler.DiffRunAttempts)
+
+	// Expected content of downloaded files, and the diffs of a run's downloads against it
+	r.Get("/{id}/fixtures", automationHandler.ListArtifactFixtures)
+	r.Get("/{id}/fixtures/{name}", automationHandler.GetArtifactFixture)
+	r.Put("/{id}/fixtures/{name}", automationHandler.PutArtifactFixture)
+	r.Delete("/{id}/fixtures/{name}", automationHandler.DeleteArtifactFixture)
+	r.Get("/{id}/runs/{runId}/artifact-diff", automationHandler.CompareRunArtifacts)
+
+	// Time-indexed run events for replaying a run on a timeline
+	r.Get("/{id}/runs/{runId}/replay", automationHandler.GetRunReplay)
+	r.Get("/{id}/runs/{runId}/replay/state", automationHandler.GetRunReplayState)
+
+	// Managed automations, addressed by external ID for infrastructure-as-code tools
+	r.Get("/managed", automationHandler.ListManagedAutomations)
+	r.Get("/managed/{externalId}", automationHandler.GetManagedAutomation)
+	r.Put("/managed/{externalId}", automationHandler.PutManagedAutomation)
+	r.Delete("/managed/{externalId}", automationHandler.DeleteManagedAutomation)
+	r.Get("/managed/{externalId}/notifications/{channelId}", automationHandler.GetManagedNotificationChannel)
+	r.Put("/managed/{externalId}/notifications/{channelId}", automationHandler.PutManagedNotificationChannel)
+	r.Delete("/managed/{externalId}/notifications/{channelId}", automationHandler.DeleteManagedNotificationChannel)
+
+	// Config drift against the snapshot last synced with version control
+	r.Get("/config-drift", automationHandler.ListConfigDrift)
+	r.Get("/{id}/config-snapshot", automationHandler.GetConfigDrift)
+	r.Put("/{id}/config-snapshot", automationHandler.RecordConfigSnapshot)
+	r.Delete("/{id}/config-snapshot", automationHandler.DeleteConfigSnapshot)
+
+	// Maintenance report of automations that haven't run or keep failing
+	r.Get("/stale", automationHandler.ListStaleAutomations)
+	r.Post("/{id}/reenable", automationHandler.ReenableAutomation)
+
+	// Planned maintenance from the organization's calendar, during which runs don't alert
+	r.Get("/maintenance-windows", automationHandler.ListMaintenanceWindows)
+
+	// Run outcomes per day or hour, e.g. for a heatmap
+	r.Get("/{id}/history", automationHandler.GetRunHistory)
+
+	// Worker capabilities an automation needs and the live workers that have them
+	r.Get("/{id}/workers", automationHandler.GetAutomationWorkers)
+
+	// Rollouts running a candidate version in shadow before it replaces the automation
+	r.Get("/{id}/rollout", automationHandler.GetConfigRollout)
+	r.Post("/{id}/rollout", automationHandler.StartConfigRollout)
+	r.Post("/{id}/rollout/promote", automationHandler.PromoteConfigRollout)
+	r.Delete("/{id}/rollout", automationHandler.AbortConfigRollout)
+
+	// Owners notified of failures and asked to review changes
+	r.Get("/{id}/owners", automationHandler.GetAutomationOwners)
+	r.Put("/{id}/owners", automationHandler.SetAutomationOwners)
+
+	// Status embed routes
+	r.Get("/{id}/embeds", automationHandler.ListAutomationEmbeds)
+	r.Post("/{id}/embeds", automationHandler.CreateAutomationEmbed)
+	r.Delete("/{id}/embeds/{embedId}", automationHandler.RevokeAutomationEmbed)
+
 	// Export automation config
 	r.Get("/{id}/export", automationHandler.ExportAutomationConfig)
 
+	// Config lint (deprecations and other non-fatal warnings)
+	r.Get("/{id}/lint", automationHandler.LintAutomation)
+	r.Get("/{id}/flowchart", automationHandler.GetAutomationFlowchart)
+
 	// SSE endpoint for run progress
 	r.Get("/{id}/runs/{runId}/events", automationHandler.GetRunEvents)
 
 	return r
 }
 
-func NewAutomationHandler(inertia *inertia.Inertia, sessionManager *scs.SessionManager, automationService automation.AutomationService, projectService project.ProjectService, scheduler *automation.Scheduler, sseManager *automation.SSEManager) *AutomationHandler {
+// draftGenerator may be nil, in which case draft generation is disabled
+func NewAutomationHandler(inertia *inertia.Inertia, sessionManager *scs.SessionManager, automationService automation.AutomationService, projectService project.ProjectService, orgService organization.OrganizationService, scheduler *automation.Scheduler, sseManager *automation.SSEManager, draftGenerator automation.DraftGenerator) *AutomationHandler {
 	return &AutomationHandler{
 		inertia:           inertia,
 		sessionManager:    sessionManager,
 		automationService: automationService,
 		projectService:    projectService,
+		orgService:        orgService,
 		scheduler:         scheduler,
 		sseManager:        sseManager,
+		draftGenerator:    draftGenerator,
 		runContexts:       make(map[string]context.CancelFunc),
 		// stepService:       automationService, // AutomationService also handles steps
 		// actionService:     automationService, // AutomationService also handles actions
@@ -69,8 +181,10 @@ type AutomationHandler struct {
 	// stepService       automation.AutomationService
 	// actionService     automation.AutomationService
 	projectService project.ProjectService
+	orgService     organization.OrganizationService
 	scheduler      *automation.Scheduler
 	sseManager     *automation.SSEManager
+	draftGenerator automation.DraftGenerator
 	runContexts    map[string]context.CancelFunc
 	mu             sync.Mutex
 }
@@ -109,10 +223,51 @@ func (h *AutomationHandler) ListAutomations(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	configSnapshots, err := h.automationService.GetConfigSnapshotsByProject(r.Context(), projectID)
+	if err != nil {
+		platform.UtilHandleServerErr(w, err)
+		return
+	}
+
+	staleAutomations, err := h.automationService.GetStaleAutomationsByProject(r.Context(), projectID)
+	if err != nil {
+		platform.UtilHandleServerErr(w, err)
+		return
+	}
+
+	owners, err := h.automationService.GetProjectAutomationOwners(r.Context(), projectID)
+	if err != nil {
+		platform.UtilHandleServerErr(w, err)
+		return
+	}
+
+	// ?owner=me lists the automations the user owns, directly or through a team
+	ownerFilter := r.URL.Query().Get("owner")
+	if ownerFilter == "me" {
+		owned, err := h.automationService.GetOwnedAutomationIDs(r.Context(), projectID, user.ID)
+		if err != nil {
+			platform.UtilHandleServerErr(w, err)
+			return
+		}
+		mine := make([]*automation.Automation, 0, len(owned))
+		for _, a := range automations {
+			if owned[a.ID] {
+				mine = append(mine, a)
+			}
+		}
+		automations = mine
+	} else {
+		ownerFilter = ""
+	}
+
 	err = h.inertia.Render(w, r, "automations/index", inertia.Props{
-		"automations": automations,
-		"project":     project,
-		"user":        user,
+		"automations":      automations,
+		"configSnapshots":  configSnapshots,
+		"owners":           owners,
+		"ownerFilter":      ownerFilter,
+		"project":          project,
+		"staleAutomations": staleAutomations,
+		"user":             user,
 	})
 	if err != nil {
 		platform.UtilHandleServerErr(w, err)
@@ -171,11 +326,18 @@ func (h *AutomationHandler) CreateAutomation(w http.ResponseWriter, r *http.Requ
 		configJSON = "{}"
 	}
 
+	// Inherit organization defaults for anything the automation doesn't set itself
+	configJSON, err = h.applyOrganizationDefaults(r.Context(), project.OrganizationID, configJSON, true)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	automation, err := h.automationService.CreateAutomation(r.Context(), projectID, req.Name, req.Description, configJSON)
 	if err != nil {
 		platform.SetFlashError(r.Context(), h.sessionManager, "Failed to create automation")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create automation"})
+		writeServiceError(w, err, "Failed to create automation")
 		return
 	}
 
@@ -187,6 +349,221 @@ func (h *AutomationHandler) CreateAutomation(w http.ResponseWriter, r *http.Requ
 	})
 }
 
+type GenerateAutomationDraftRequest struct {
+	Description string `json:"description" validate:"required,min=10,max=5000"`
+}
+
+func (h *AutomationHandler) ImportAutomation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+
+	// Verify project belongs to user's organization
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	var imported automation.ExportedAutomationConfig
+	if err := json.NewDecoder(r.Body).Decode(&imported); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	h.importAutomation(w, r, projectID, project.OrganizationID, &imported, "Automation imported successfully", nil)
+}
+
+func (h *AutomationHandler) GenerateAutomationDraft(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	if h.draftGenerator == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Draft generation is not configured"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+
+	// Verify project belongs to user's organization
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	var req GenerateAutomationDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	draft, err := h.draftGenerator.GenerateDraft(r.Context(), automation.DraftRequest{
+		Description: req.Description,
+		ActionTypes: automation.RegisteredActionTypes(),
+	})
+	if err != nil {
+		slog.Error("Failed to generate automation draft", "error", err, "backend", h.draftGenerator.Name(), "projectID", projectID)
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to generate draft"})
+		return
+	}
+
+	// Drafts are clearly labelled until a human has reviewed them
+	if draft.Automation.Name == "" {
+		draft.Automation.Name = "Untitled"
+	}
+	draft.Automation.Name = "[Draft] " + draft.Automation.Name
+	if draft.Automation.Description == "" {
+		draft.Automation.Description = req.Description
+	}
+	if len(draft.Automation.Description) > 1000 {
+		draft.Automation.Description = draft.Automation.Description[:1000]
+	}
+
+	h.importAutomation(w, r, projectID, project.OrganizationID, draft, "Automation draft generated successfully", nil)
+}
+
+// maxSessionRecordingSize bounds uploaded session recordings, which include DOM snapshots
+const maxSessionRecordingSize = 50 << 20
+
+// ImportSessionRecording converts a Chrome DevTools Recorder export or rrweb events recorded in
+// the target app into a draft automation. The optional name query parameter names the draft.
+func (h *AutomationHandler) ImportSessionRecording(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+
+	// Verify project belongs to user's organization
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSessionRecordingSize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Recordings are limited to %d MB", maxSessionRecordingSize>>20)})
+		return
+	}
+
+	draft, conversionWarnings, err := automation.ConvertSessionRecording(data)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Drafts are clearly labelled until a human has reviewed them
+	if name := strings.TrimSpace(r.URL.Query().Get("name")); name != "" {
+		draft.Automation.Name = name
+	}
+	draft.Automation.Name = "[Draft] " + draft.Automation.Name
+	if runes := []rune(draft.Automation.Name); len(runes) > 255 {
+		draft.Automation.Name = string(runes[:255])
+	}
+
+	h.importAutomation(w, r, projectID, project.OrganizationID, draft, "Session recording imported as a draft", conversionWarnings)
+}
+
+// importAutomation applies organization defaults to an exported config and imports it,
+// responding with the new automation and any warnings, after those of converting the config
+func (h *AutomationHandler) importAutomation(w http.ResponseWriter, r *http.Request, projectID, orgID string, imported *automation.ExportedAutomationConfig, successMessage string, conversionWarnings []automation.ConfigWarning) {
+	if err := automation.ValidateAutomationImport(imported); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	configBytes, err := json.Marshal(imported.Automation.Config)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid automation config"})
+		return
+	}
+
+	// Inherit organization defaults for anything the automation doesn't set itself
+	configJSON, err := h.applyOrganizationDefaults(r.Context(), orgID, string(configBytes), true)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	newAutomation, warnings, err := h.automationService.ImportAutomation(r.Context(), projectID, configJSON, imported)
+	if err != nil {
+		platform.SetFlashError(r.Context(), h.sessionManager, "Failed to import automation")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to import automation"})
+		return
+	}
+
+	if len(conversionWarnings) > 0 {
+		warnings = append(conversionWarnings, warnings...)
+	}
+
+	platform.SetFlashSuccess(r.Context(), h.sessionManager, successMessage)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    successMessage,
+		"automation": newAutomation,
+		"warnings":   warnings,
+	})
+}
+
 func (h *AutomationHandler) GetAutomation(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 	if user == nil {
@@ -348,18 +725,26 @@ func (h *AutomationHandler) UpdateAutomation(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Organization policies can't be overridden per automation
+	configJSON, err := h.applyOrganizationDefaults(r.Context(), project.OrganizationID, req.ConfigJSON, false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	automation.Name = req.Name
 	automation.Description = req.Description
-	automation.ConfigJSON = req.ConfigJSON
+	automation.ConfigJSON = configJSON
 
 	err = h.automationService.UpdateAutomation(r.Context(), automation)
 	if err != nil {
 		platform.SetFlashError(r.Context(), h.sessionManager, "Failed to update automation")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update automation"})
+		writeServiceError(w, err, "Failed to update automation")
 		return
 	}
 
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "updated its settings")
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Automation updated successfully")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -472,12 +857,11 @@ func (h *AutomationHandler) CreateStep(w http.ResponseWriter, r *http.Request) {
 
 	step, err := h.automationService.CreateStep(r.Context(), automationID, req.Name, req.StepOrder, configJSON)
 	if err != nil {
-		platform.SetFlashError(r.Context(), h.sessionManager, "Failed to create step")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create step"})
+		writeServiceError(w, err, "Failed to create step")
 		return
 	}
 
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "added a step")
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Step created successfully")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -534,12 +918,11 @@ func (h *AutomationHandler) UpdateStep(w http.ResponseWriter, r *http.Request) {
 
 	err := h.automationService.UpdateStep(r.Context(), step)
 	if err != nil {
-		platform.SetFlashError(r.Context(), h.sessionManager, "Failed to update step")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update step"})
+		writeServiceError(w, err, "Failed to update step")
 		return
 	}
 
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "updated a step")
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Step updated successfully")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -574,6 +957,7 @@ func (h *AutomationHandler) DeleteStep(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "deleted a step")
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Step deleted successfully")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Step deleted successfully"})
@@ -604,7 +988,7 @@ func (h *AutomationHandler) TriggerRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	automation, err := h.automationService.GetAutomationByID(r.Context(), automationID)
+	existing, err := h.automationService.GetAutomationByID(r.Context(), automationID)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Automation not found"})
@@ -612,17 +996,40 @@ func (h *AutomationHandler) TriggerRun(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify automation belongs to the project
-	if automation.ProjectID != projectID {
+	if existing.ProjectID != projectID {
 		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
 		return
 	}
 
-	run, err := h.automationService.TriggerRun(r.Context(), automationID)
+	// The body is optional; without it every step and action runs
+	var req TriggerRunRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	tagFilter := automation.NewRunTagFilter(req.IncludeTags, req.ExcludeTags)
+	run, err := h.automationService.TriggerRun(r.Context(), automationID, tagFilter, req.Locale, user.ID)
 	if err != nil {
 		platform.SetFlashError(r.Context(), h.sessionManager, "Failed to trigger automation run")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to trigger run"})
+		writeServiceError(w, err, "Failed to trigger run")
 		return
 	}
 
@@ -670,18 +1077,28 @@ func (h *AutomationHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	runs, err := h.automationService.GetRunsByAutomation(r.Context(), automationID)
+	filterExpr := r.URL.Query().Get("filter")
+	runs, filterError, err := h.filterAutomationRuns(r.Context(), user.ID, projectID, automationID, filterExpr)
+	if err != nil {
+		platform.UtilHandleServerErr(w, err)
+		return
+	}
+
+	views, err := h.automationService.GetRunViews(r.Context(), projectID, user.ID)
 	if err != nil {
 		platform.UtilHandleServerErr(w, err)
 		return
 	}
 
 	err = h.inertia.Render(w, r, "projects/[projectId]/automations/[automationId]/runs", inertia.Props{
-		"params":     map[string]string{"automationId": automationID, "projectId": projectID},
-		"runs":       runs,
-		"automation": automation,
-		"project":    project,
-		"user":       user,
+		"params":      map[string]string{"automationId": automationID, "projectId": projectID},
+		"runs":        runs,
+		"filter":      filterExpr,
+		"filterError": filterError,
+		"views":       views,
+		"automation":  automation,
+		"project":     project,
+		"user":        user,
 	})
 	if err != nil {
 		platform.UtilHandleServerErr(w, err)
@@ -739,12 +1156,27 @@ func (h *AutomationHandler) GetRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pin, err := h.automationService.GetRunPin(r.Context(), runID)
+	if err != nil {
+		platform.UtilHandleServerErr(w, err)
+		return
+	}
+
+	maintenance, err := h.automationService.GetRunMaintenanceWindow(r.Context(), runID)
+	if err != nil {
+		platform.UtilHandleServerErr(w, err)
+		return
+	}
+
 	err = h.inertia.Render(w, r, "projects/[projectId]/automations/[automationId]/runs/[runId]", inertia.Props{
-		"params":     map[string]string{"automationId": automationID, "projectId": projectID, "runId": runID},
-		"run":        run,
-		"automation": automation,
-		"project":    project,
-		"user":       user,
+		"params":      map[string]string{"automationId": automationID, "projectId": projectID, "runId": runID},
+		"run":         run,
+		"pin":         pin,
+		"timers":      run.TimerStats(),
+		"maintenance": maintenance,
+		"automation":  automation,
+		"project":     project,
+		"user":        user,
 	})
 	if err != nil {
 		platform.UtilHandleServerErr(w, err)
@@ -805,11 +1237,13 @@ func (h *AutomationHandler) CreateAction(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "added an action")
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Action created successfully")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Action created successfully",
-		"action":  action,
+		"message":  "Action created successfully",
+		"action":   action,
+		"warnings": automation.CheckDeprecationsJSON(action.ActionType, action.ActionConfigJSON),
 	})
 }
 
@@ -869,11 +1303,13 @@ func (h *AutomationHandler) UpdateAction(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "updated an action")
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Action updated successfully")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Action updated successfully",
-		"action":  action,
+		"message":  "Action updated successfully",
+		"action":   action,
+		"warnings": automation.CheckDeprecationsJSON(action.ActionType, action.ActionConfigJSON),
 	})
 }
 
@@ -905,11 +1341,46 @@ func (h *AutomationHandler) DeleteAction(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "deleted an action")
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Action deleted successfully")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Action deleted successfully"})
 }
 
+// applyOrganizationDefaults applies the organization's automation settings to a config.
+// New automations inherit defaults for unset fields; existing ones only have policies enforced.
+func (h *AutomationHandler) applyOrganizationDefaults(ctx context.Context, orgID, configJSON string, inherit bool) (string, error) {
+	settings, err := h.orgService.GetOrganizationSettings(ctx, orgID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load organization settings")
+	}
+
+	defaults := automation.ConfigDefaults{
+		Browser:                  settings.DefaultBrowser,
+		Timeout:                  settings.DefaultTimeout,
+		RequireScreenshotOnError: settings.RequireScreenshotOnError,
+	}
+	for _, channel := range settings.DefaultNotifications {
+		defaults.Notifications = append(defaults.Notifications, automation.NotificationChannelConfig{
+			Type:       channel.Type,
+			OnComplete: channel.OnComplete,
+			OnError:    channel.OnError,
+			Config:     channel.Config,
+		})
+	}
+
+	if inherit {
+		configJSON, err = automation.ApplyConfigDefaults(configJSON, defaults)
+	} else {
+		configJSON, err = automation.EnforceConfigPolicy(configJSON, defaults)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid automation config")
+	}
+
+	return configJSON, nil
+}
+
 // Helper to verify access to automation based on project and organization ownership
 func (h *AutomationHandler) verifyAutomationAccess(ctx context.Context, user *auth.User, projectID, automationID string) error {
 	project, err := h.projectService.GetProjectByID(ctx, projectID)
@@ -1100,3 +1571,1871 @@ func (h *AutomationHandler) ExportAutomationConfig(w http.ResponseWriter, r *htt
 
 	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Automation config exported successfully")
 }
+
+// TriggerRunRequest selects a run's execution profile by step and action tags, and the locale
+// message keys resolve in
+type TriggerRunRequest struct {
+	IncludeTags []string `json:"include_tags" validate:"max=20,dive,max=50"`
+	ExcludeTags []string `json:"exclude_tags" validate:"max=20,dive,max=50"`
+	Locale      string   `json:"locale" validate:"max=35"`
+}
+
+type CreateRunShareRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" validate:"min=0,max=720"`
+}
+
+type RecordConfigSnapshotRequest struct {
+	CommitRef string `json:"commit_ref" validate:"max=255"`
+	// Config is the exported config as committed; the automation's current config when omitted
+	Config *automation.ExportedAutomationConfig `json:"config"`
+}
+
+type ConsoleCommandRequest struct {
+	LoopIndex  int    `json:"loop_index" validate:"min=0"`
+	Expression string `json:"expression" validate:"required,max=10000"`
+}
+
+type ResumeConsoleRequest struct {
+	LoopIndex int `json:"loop_index" validate:"min=0"`
+}
+
+// verifyRunAccess checks automation access and that the run belongs to the automation
+func (h *AutomationHandler) verifyRunAccess(ctx context.Context, user *auth.User, projectID, automationID, runID string) error {
+	if err := h.verifyAutomationAccess(ctx, user, projectID, automationID); err != nil {
+		return err
+	}
+
+	run, err := h.automationService.GetRunByID(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("run not found")
+	}
+
+	if run.AutomationID != automationID {
+		return fmt.Errorf("access denied to run")
+	}
+	return nil
+}
+
+func (h *AutomationHandler) ListRunShares(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	shares, err := h.automationService.GetRunShares(r.Context(), runID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get share links"})
+		return
+	}
+
+	result := make([]map[string]interface{}, len(shares))
+	for i, share := range shares {
+		result[i] = runShareResponse(share)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"shares": result,
+	})
+}
+
+func (h *AutomationHandler) ListRunAnomalies(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	anomalies, err := h.automationService.GetRunAnomalies(r.Context(), runID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get anomalies"})
+		return
+	}
+
+	tags := []string{}
+	if len(anomalies) > 0 {
+		tags = append(tags, "anomaly")
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"anomalies": anomalies,
+		"tags":      tags,
+	})
+}
+
+// GetRunReport downloads a run's report, as PDF unless ?format=html asks for the page it's printed from
+func (h *AutomationHandler) GetRunReport(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = automation.RunReportFormatPDF
+	}
+
+	report, err := h.automationService.GetRunReport(r.Context(), runID, format)
+	if err != nil {
+		writeServiceError(w, err, "Failed to generate run report")
+		return
+	}
+
+	if format == automation.RunReportFormatHTML {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"run-report-%s.pdf\"", runID))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(report)
+}
+
+// ExportRunEvidence downloads a finished run's evidence bundle: a ZIP of its report, logs,
+// artifacts and config with a signed manifest of their hashes
+func (h *AutomationHandler) ExportRunEvidence(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	evidence, err := h.automationService.GetRunEvidence(r.Context(), runID, user.Email)
+	if err != nil {
+		writeServiceError(w, err, "Failed to export run evidence")
+		return
+	}
+
+	// Artifacts are downloaded before the response starts, so failures still get an error status
+	defer evidence.Close()
+	if err := evidence.FetchArtifacts(r.Context()); err != nil {
+		slog.Error("Failed to fetch run evidence artifacts", "error", err, "runID", runID)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to export run evidence"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", evidence.FileName()))
+	w.WriteHeader(http.StatusOK)
+	if err := evidence.WriteZip(w); err != nil {
+		slog.Error("Failed to write run evidence", "error", err, "runID", runID)
+	}
+}
+
+// EvidenceSigningKey publishes the public key evidence manifests are signed with, so bundles are
+// verified against it rather than against anything shipped within them
+func EvidenceSigningKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	keyID, publicKey, err := automation.EvidencePublicKey()
+	if err != nil {
+		writeServiceError(w, err, "Failed to get evidence signing key")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"algorithm":  "Ed25519",
+		"key_id":     keyID,
+		"public_key": publicKey,
+	})
+}
+
+// DiffRunAttempts compares a run's events with the attempt it retries, or with ?base=<runId>,
+// optionally for one user with ?loop_index=
+func (h *AutomationHandler) DiffRunAttempts(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	loopIndex := -1
+	if value := r.URL.Query().Get("loop_index"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "loop_index must be a user's loop index"})
+			return
+		}
+		loopIndex = parsed
+	}
+
+	diff, err := h.automationService.DiffRunAttempts(r.Context(), runID, r.URL.Query().Get("base"), loopIndex)
+	if err != nil {
+		writeServiceError(w, err, "Failed to compare runs")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"diff": diff,
+	})
+}
+
+// maxArtifactFixtureSize bounds uploaded artifact fixtures
+const maxArtifactFixtureSize = 10 << 20
+
+// verifyArtifactFixtureAccess checks the user may manage the automation's fixtures, writing the
+// error response if not
+func (h *AutomationHandler) verifyArtifactFixtureAccess(w http.ResponseWriter, r *http.Request) (*auth.User, string, bool) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return nil, "", false
+	}
+
+	automationID := chi.URLParam(r, "id")
+	if err := h.verifyAutomationAccess(r.Context(), user, chi.URLParam(r, "projectId"), automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return nil, "", false
+	}
+	return user, automationID, true
+}
+
+func writeArtifactFixtureError(w http.ResponseWriter, err error, fallback string) {
+	if errors.Is(err, platform.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	writeServiceError(w, err, fallback)
+}
+
+// ListArtifactFixtures lists the automation's fixtures without their content
+func (h *AutomationHandler) ListArtifactFixtures(w http.ResponseWriter, r *http.Request) {
+	_, automationID, ok := h.verifyArtifactFixtureAccess(w, r)
+	if !ok {
+		return
+	}
+
+	fixtures, err := h.automationService.GetArtifactFixtures(r.Context(), automationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get artifact fixtures"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"fixtures": fixtures,
+	})
+}
+
+// GetArtifactFixture downloads the expected content of a fixture
+func (h *AutomationHandler) GetArtifactFixture(w http.ResponseWriter, r *http.Request) {
+	_, automationID, ok := h.verifyArtifactFixtureAccess(w, r)
+	if !ok {
+		return
+	}
+
+	fixture, err := h.automationService.GetArtifactFixtureContent(r.Context(), automationID, chi.URLParam(r, "name"))
+	if err != nil {
+		writeArtifactFixtureError(w, err, "Failed to get artifact fixture")
+		return
+	}
+
+	w.Header().Set("Content-Type", fixture.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fixture.Name))
+	w.WriteHeader(http.StatusOK)
+	w.Write(fixture.Data)
+}
+
+// PutArtifactFixture stores the request body as the expected content of the named download,
+// taking its content type from the request. action_id ties the fixture to the action whose
+// downloads it describes and mode picks how they are compared.
+func (h *AutomationHandler) PutArtifactFixture(w http.ResponseWriter, r *http.Request) {
+	user, automationID, ok := h.verifyArtifactFixtureAccess(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxArtifactFixtureSize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Fixtures are limited to %d MB", maxArtifactFixtureSize>>20)})
+		return
+	}
+
+	fixture, err := h.automationService.SaveArtifactFixture(r.Context(), &automation.ArtifactFixture{
+		AutomationID:    automationID,
+		Name:            chi.URLParam(r, "name"),
+		ActionID:        r.URL.Query().Get("action_id"),
+		CompareMode:     r.URL.Query().Get("mode"),
+		ContentType:     r.Header.Get("Content-Type"),
+		Data:            data,
+		CreatedByUserID: user.ID,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Failed to save artifact fixture")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Fixture saved successfully",
+		"fixture": fixture,
+	})
+}
+
+func (h *AutomationHandler) DeleteArtifactFixture(w http.ResponseWriter, r *http.Request) {
+	_, automationID, ok := h.verifyArtifactFixtureAccess(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.automationService.DeleteArtifactFixture(r.Context(), automationID, chi.URLParam(r, "name")); err != nil {
+		writeArtifactFixtureError(w, err, "Failed to delete artifact fixture")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Fixture deleted successfully"})
+}
+
+// CompareRunArtifacts diffs the run's downloads against the automation's fixtures. fixture
+// limits the comparison to one fixture and artifact to one output file, given by its URL.
+func (h *AutomationHandler) CompareRunArtifacts(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	diffs, err := h.automationService.CompareRunArtifacts(r.Context(), runID, r.URL.Query().Get("fixture"), r.URL.Query().Get("artifact"))
+	if err != nil {
+		writeArtifactFixtureError(w, err, "Failed to compare downloads")
+		return
+	}
+
+	equal := true
+	for _, diff := range diffs {
+		equal = equal && diff.Equal
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"equal": equal,
+		"diffs": diffs,
+	})
+}
+
+// GetRunReplay returns a page of a run's replay timeline. from_ms and to_ms limit it to a window
+// of the run, after_seq pages through it and limit caps the events returned.
+func (h *AutomationHandler) GetRunReplay(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	query := automation.RunReplayQuery{ToMs: -1}
+	for name, target := range map[string]*int64{"from_ms": &query.FromMs, "to_ms": &query.ToMs} {
+		if value := r.URL.Query().Get(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": name + " must be a number of milliseconds"})
+				return
+			}
+			*target = parsed
+		}
+	}
+	for name, target := range map[string]*int{"after_seq": &query.AfterSeq, "limit": &query.Limit} {
+		if value := r.URL.Query().Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": name + " must be a non-negative number"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	replay, err := h.automationService.GetRunReplay(r.Context(), runID, query)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get run replay")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"replay": replay,
+	})
+}
+
+// GetRunReplayState returns what each user of a run was doing at_ms milliseconds into it
+func (h *AutomationHandler) GetRunReplayState(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	atMs, err := strconv.ParseInt(r.URL.Query().Get("at_ms"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "at_ms must be a number of milliseconds"})
+		return
+	}
+
+	state, err := h.automationService.GetRunReplayState(r.Context(), runID, atMs)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get run replay state")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"state": state,
+	})
+}
+
+func (h *AutomationHandler) ListAutomationAnomalies(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	anomalies, err := h.automationService.GetAutomationAnomalies(r.Context(), automationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get anomalies"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"anomalies": anomalies,
+	})
+}
+
+// GetRunHistory returns run counts by status per day or hour. Query parameters: granularity
+// (day or hour), from and to (dates or RFC3339 times, both inclusive) and timezone.
+func (h *AutomationHandler) GetRunHistory(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	params := r.URL.Query()
+	query, err := automation.NewRunHistoryQuery(params.Get("granularity"), params.Get("from"), params.Get("to"), params.Get("timezone"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	history, err := h.automationService.GetRunHistory(r.Context(), automationID, query)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get run history"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *AutomationHandler) CreateRunShare(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req CreateRunShareRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	share, err := h.automationService.CreateRunShare(r.Context(), runID, user.ID, time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create share link"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Share link created successfully",
+		"share":   runShareResponse(share),
+	})
+}
+
+// PinRunRequest is the label a run is pinned under
+type PinRunRequest struct {
+	Label string `json:"label" validate:"required,max=100"`
+}
+
+// PinRun keeps a run from being deleted by retention
+func (h *AutomationHandler) PinRun(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req PinRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	pin, err := h.automationService.PinRun(r.Context(), runID, req.Label, user.ID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to pin run")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Run pinned successfully",
+		"pin":     pin,
+	})
+}
+
+// UnpinRun lets retention delete a run again
+func (h *AutomationHandler) UnpinRun(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.UnpinRun(r.Context(), runID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Run is not pinned"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Run unpinned successfully"})
+}
+
+// ListPinnedRuns lists a project's pinned runs, e.g. as audit evidence; ?label= narrows it to
+// the runs pinned under one label
+func (h *AutomationHandler) ListPinnedRuns(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	pins, err := h.automationService.GetPinnedRunsByProject(r.Context(), projectID, r.URL.Query().Get("label"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get pinned runs"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pins": pins,
+	})
+}
+
+func (h *AutomationHandler) RevokeRunShare(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+	shareID := chi.URLParam(r, "shareId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.RevokeRunShare(r.Context(), runID, shareID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Share link revoked successfully"})
+}
+
+// runShareResponse builds the JSON representation of a share link including its public URL
+func runShareResponse(share *automation.RunShare) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         share.ID,
+		"run_id":     share.RunID,
+		"url":         fmt.Sprintf("%s/share/runs/%s", platform.ENV_APP_URL, share.Token),
+		"preview_url": fmt.Sprintf("%s/share/runs/%s/preview", platform.ENV_APP_URL, share.Token),
+		"expires_at":  share.ExpiresAt,
+		"revoked_at":  share.RevokedAt,
+		"created_at":  share.CreatedAt,
+		"active":      share.IsActive(time.Now()),
+	}
+}
+
+func (h *AutomationHandler) ListAutomationEmbeds(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	embeds, err := h.automationService.GetAutomationEmbeds(r.Context(), automationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get embeds"})
+		return
+	}
+
+	result := make([]map[string]interface{}, len(embeds))
+	for i, embed := range embeds {
+		result[i] = automationEmbedResponse(embed)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"embeds": result,
+	})
+}
+
+func (h *AutomationHandler) CreateAutomationEmbed(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	embed, err := h.automationService.CreateAutomationEmbed(r.Context(), automationID, user.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create embed"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Embed created successfully",
+		"embed":   automationEmbedResponse(embed),
+	})
+}
+
+func (h *AutomationHandler) RevokeAutomationEmbed(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	embedID := chi.URLParam(r, "embedId")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.RevokeAutomationEmbed(r.Context(), automationID, embedID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Embed revoked successfully"})
+}
+
+// automationEmbedResponse builds the JSON representation of an embed including its widget and JSON URLs
+func automationEmbedResponse(embed *automation.AutomationEmbed) map[string]interface{} {
+	widgetURL := fmt.Sprintf("%s/share/automations/%s/widget", platform.ENV_APP_URL, embed.Token)
+	return map[string]interface{}{
+		"id":          embed.ID,
+		"widget_url":  widgetURL,
+		"json_url":    fmt.Sprintf("%s/share/automations/%s/status", platform.ENV_APP_URL, embed.Token),
+		"iframe_html": fmt.Sprintf(`<iframe src="%s" width="360" height="180" frameborder="0"></iframe>`, widgetURL),
+		"revoked_at":  embed.RevokedAt,
+		"created_at":  embed.CreatedAt,
+	}
+}
+
+func (h *AutomationHandler) LintAutomation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	warnings, err := h.automationService.LintAutomation(r.Context(), automationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to lint automation"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"warnings": warnings,
+	})
+}
+
+// GetAutomationFlowchart returns an automation as flowchart nodes and edges for read-only viewing
+func (h *AutomationHandler) GetAutomationFlowchart(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	flowchart, err := h.automationService.GetAutomationFlowchart(r.Context(), automationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to build flowchart"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(flowchart)
+}
+
+// verifyConsoleAccess writes an error response and returns false unless the debug console is
+// enabled and the user can access the run
+func (h *AutomationHandler) verifyConsoleAccess(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return "", false
+	}
+
+	if !platform.ENV_DEBUG_CONSOLE_ENABLED {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": automation.ErrConsoleDisabled.Error()})
+		return "", false
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	runID := chi.URLParam(r, "runId")
+
+	if err := h.verifyRunAccess(r.Context(), user, projectID, automationID, runID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return "", false
+	}
+	return runID, true
+}
+
+// GetRunConsole lists the users of a run paused for console commands in this worker
+func (h *AutomationHandler) GetRunConsole(w http.ResponseWriter, r *http.Request) {
+	runID, ok := h.verifyConsoleAccess(w, r)
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"paused_loop_indexes": h.scheduler.PausedConsoleUsers(runID),
+	})
+}
+
+// SendConsoleCommand evaluates an expression on a paused user's page. The result is sent over
+// the run's SSE stream as a console message carrying the returned command ID.
+func (h *AutomationHandler) SendConsoleCommand(w http.ResponseWriter, r *http.Request) {
+	runID, ok := h.verifyConsoleAccess(w, r)
+	if !ok {
+		return
+	}
+
+	var req ConsoleCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	commandID, err := h.scheduler.SendConsoleCommand(runID, req.LoopIndex, req.Expression)
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"command_id": commandID})
+}
+
+// ResumeConsole lets a paused user of a run continue with its next action
+func (h *AutomationHandler) ResumeConsole(w http.ResponseWriter, r *http.Request) {
+	runID, ok := h.verifyConsoleAccess(w, r)
+	if !ok {
+		return
+	}
+
+	var req ResumeConsoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	if err := h.scheduler.ResumeConsole(runID, req.LoopIndex); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Run resumed"})
+}
+
+// ListConfigDrift lists the config snapshots of a project's automations, drifted ones first
+func (h *AutomationHandler) ListConfigDrift(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	snapshots, err := h.automationService.GetConfigSnapshotsByProject(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get config drift"})
+		return
+	}
+	if snapshots == nil {
+		snapshots = []*automation.ConfigSnapshot{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"snapshots": snapshots,
+	})
+}
+
+// GetConfigDrift compares an automation with its config snapshot
+func (h *AutomationHandler) GetConfigDrift(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	snapshot, err := h.automationService.GetConfigDrift(r.Context(), automationID)
+	if err != nil {
+		if errors.Is(err, platform.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Automation has no config snapshot"})
+			return
+		}
+		writeServiceError(w, err, "Failed to check config drift")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"snapshot": snapshot,
+	})
+}
+
+// RecordConfigSnapshot is called by push/pull tooling after committing an automation's config,
+// so later edits made in the UI show up as drift
+func (h *AutomationHandler) RecordConfigSnapshot(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req RecordConfigSnapshotRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	snapshot, err := h.automationService.RecordConfigSnapshot(r.Context(), automationID, req.CommitRef, user.ID, req.Config)
+	if err != nil {
+		writeServiceError(w, err, "Failed to record config snapshot")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Config snapshot recorded successfully",
+		"snapshot": snapshot,
+	})
+}
+
+// DeleteConfigSnapshot stops checking an automation for config drift
+func (h *AutomationHandler) DeleteConfigSnapshot(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.DeleteConfigSnapshot(r.Context(), automationID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Automation has no config snapshot"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Config snapshot deleted successfully"})
+}
+
+// GetAutomationWorkers lists what a worker needs to run an automation and, for each live
+// worker, what it lacks
+func (h *AutomationHandler) GetAutomationWorkers(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	requirements, workers, err := h.automationService.GetWorkerMatches(r.Context(), automationID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get workers")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"requirements": requirements,
+		"workers":      workers,
+	})
+}
+
+// ListStaleAutomations is the maintenance report of a project's automations that are stale or
+// were disabled for it
+func (h *AutomationHandler) ListStaleAutomations(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	stale, err := h.automationService.GetStaleAutomationsByProject(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get stale automations"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"automations": stale,
+	})
+}
+
+// ListMaintenanceWindows lists the current and upcoming maintenance windows of the project's
+// organization
+func (h *AutomationHandler) ListMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	windows, err := h.automationService.GetUpcomingMaintenanceWindows(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get maintenance windows"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"windows": windows,
+	})
+}
+
+// ReenableAutomation lets an automation disabled for being stale run again
+func (h *AutomationHandler) ReenableAutomation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.ReenableAutomation(r.Context(), automationID); err != nil {
+		writeServiceError(w, err, "Failed to re-enable automation")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Automation re-enabled successfully"})
+}
+
+// GetConfigRollout returns an automation's rollout in progress with how its shadow runs compare,
+// or its last finished one
+func (h *AutomationHandler) GetConfigRollout(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	rollout, err := h.automationService.GetConfigRollout(r.Context(), automationID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get rollout")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rollout": rollout,
+	})
+}
+
+// StartConfigRolloutRequest is how many shadow runs a rollout compares before it can be promoted
+type StartConfigRolloutRequest struct {
+	ShadowRuns int `json:"shadow_runs" validate:"min=0,max=100"`
+}
+
+// StartConfigRollout copies an automation into a candidate that runs in shadow of it
+func (h *AutomationHandler) StartConfigRollout(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req StartConfigRolloutRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	rollout, err := h.automationService.StartConfigRollout(r.Context(), automationID, req.ShadowRuns, user.ID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to start rollout")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Rollout started successfully",
+		"rollout": rollout,
+	})
+}
+
+// PromoteConfigRollout replaces an automation with the candidate of its rollout. ?force=true
+// promotes it before its shadow runs were compared without regressions.
+func (h *AutomationHandler) PromoteConfigRollout(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	force := r.URL.Query().Get("force") == "true"
+	rollout, err := h.automationService.PromoteConfigRollout(r.Context(), automationID, force)
+	if err != nil {
+		writeServiceError(w, err, "Failed to promote rollout")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Rollout promoted successfully",
+		"rollout": rollout,
+	})
+}
+
+// AbortConfigRollout ends an automation's rollout without changing it
+func (h *AutomationHandler) AbortConfigRollout(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	rollout, err := h.automationService.AbortConfigRollout(r.Context(), automationID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to abort rollout")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Rollout aborted successfully",
+		"rollout": rollout,
+	})
+}
+
+// filterAutomationRuns lists the runs of an automation, or the first page of those matching
+// filterExpr when it is set. An invalid filter is reported by filterError rather than err.
+func (h *AutomationHandler) filterAutomationRuns(ctx context.Context, userID, projectID, automationID, filterExpr string) (runs []*automation.AutomationRun, filterError string, err error) {
+	if filterExpr == "" {
+		runs, err = h.automationService.GetRunsByAutomation(ctx, automationID)
+		return runs, "", err
+	}
+
+	filter, err := automation.ParseRunFilter(filterExpr, userID)
+	if err != nil {
+		return []*automation.AutomationRun{}, err.Error(), nil
+	}
+	runs, err = h.automationService.GetRuns(ctx, automation.RunQuery{
+		ProjectID:    projectID,
+		AutomationID: automationID,
+		Filter:       filter,
+	})
+	return runs, "", err
+}
+
+// SearchRuns lists the runs of a project's automations matching ?filter=, newest first, with
+// ?limit= and ?offset= paging through them
+func (h *AutomationHandler) SearchRuns(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return
+	}
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	filter, err := automation.ParseRunFilter(r.URL.Query().Get("filter"), user.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid filter: " + err.Error()})
+		return
+	}
+
+	query := automation.RunQuery{ProjectID: projectID, Filter: filter}
+	for name, target := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		if value := r.URL.Query().Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": name + " must be a non-negative number"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	runs, err := h.automationService.GetRuns(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get runs")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"runs": runs,
+	})
+}
+
+// verifyProjectMember checks that the user may access a project, writing the error response if not
+func (h *AutomationHandler) verifyProjectMember(w http.ResponseWriter, r *http.Request) (*auth.User, string, bool) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return nil, "", false
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return nil, "", false
+	}
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return nil, "", false
+	}
+	return user, projectID, true
+}
+
+// verifyRunViewAccess checks that the user may see the run view of the URL, writing the error
+// response if not
+func (h *AutomationHandler) verifyRunViewAccess(w http.ResponseWriter, r *http.Request) (*auth.User, *automation.RunView, bool) {
+	user, projectID, ok := h.verifyProjectMember(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	view, err := h.automationService.GetRunView(r.Context(), chi.URLParam(r, "viewId"), user.ID)
+	if err == nil && view.ProjectID != projectID {
+		err = platform.ErrNotFound
+	}
+	if err != nil {
+		writeRunViewError(w, err, "Failed to get run view")
+		return nil, nil, false
+	}
+	return user, view, true
+}
+
+func writeRunViewError(w http.ResponseWriter, err error, fallback string) {
+	if errors.Is(err, platform.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Run view not found"})
+		return
+	}
+	writeServiceError(w, err, fallback)
+}
+
+// ListRunViews lists the user's own and the shared run views of a project
+func (h *AutomationHandler) ListRunViews(w http.ResponseWriter, r *http.Request) {
+	user, projectID, ok := h.verifyProjectMember(w, r)
+	if !ok {
+		return
+	}
+
+	views, err := h.automationService.GetRunViews(r.Context(), projectID, user.ID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get run views")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"views": views,
+	})
+}
+
+// RunViewRequest is a run filter saved under a name, see automation.ParseRunFilter
+type RunViewRequest struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Filter string `json:"filter" validate:"required"`
+	Shared bool   `json:"shared"`
+}
+
+func decodeRunViewRequest(w http.ResponseWriter, r *http.Request) (*RunViewRequest, bool) {
+	var req RunViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return nil, false
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return nil, false
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return nil, false
+	}
+	return &req, true
+}
+
+// CreateRunView saves a run filter as a view of the project
+func (h *AutomationHandler) CreateRunView(w http.ResponseWriter, r *http.Request) {
+	user, projectID, ok := h.verifyProjectMember(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeRunViewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := h.automationService.CreateRunView(r.Context(), &automation.RunView{
+		ProjectID:       projectID,
+		Name:            req.Name,
+		Filter:          req.Filter,
+		CreatedByUserID: user.ID,
+		Shared:          req.Shared,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Failed to create run view")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Run view created successfully",
+		"view":    view,
+	})
+}
+
+// UpdateRunView changes a run view the user created
+func (h *AutomationHandler) UpdateRunView(w http.ResponseWriter, r *http.Request) {
+	user, view, ok := h.verifyRunViewAccess(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeRunViewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	view.Name, view.Filter, view.Shared = req.Name, req.Filter, req.Shared
+	view, err := h.automationService.UpdateRunView(r.Context(), view, user.ID)
+	if err != nil {
+		writeRunViewError(w, err, "Failed to update run view")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Run view updated successfully",
+		"view":    view,
+	})
+}
+
+// DeleteRunView deletes a run view the user created, along with its subscriptions
+func (h *AutomationHandler) DeleteRunView(w http.ResponseWriter, r *http.Request) {
+	user, view, ok := h.verifyRunViewAccess(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.automationService.DeleteRunView(r.Context(), view.ID, user.ID); err != nil {
+		writeRunViewError(w, err, "Failed to delete run view")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Run view deleted successfully"})
+}
+
+// SubscribeRunView emails the user about finished runs matching a run view
+func (h *AutomationHandler) SubscribeRunView(w http.ResponseWriter, r *http.Request) {
+	h.setRunViewSubscription(w, r, true)
+}
+
+// UnsubscribeRunView stops emailing the user about runs matching a run view
+func (h *AutomationHandler) UnsubscribeRunView(w http.ResponseWriter, r *http.Request) {
+	h.setRunViewSubscription(w, r, false)
+}
+
+func (h *AutomationHandler) setRunViewSubscription(w http.ResponseWriter, r *http.Request, subscribed bool) {
+	user, view, ok := h.verifyRunViewAccess(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := h.automationService.SetRunViewSubscription(r.Context(), view.ID, user.ID, subscribed)
+	if err != nil {
+		writeRunViewError(w, err, "Failed to change subscription")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"view": view,
+	})
+}
+
+// TestStepActionsRequest selects the actions of a step to test and the environment to test them
+// against, see automation.ActionTestRequest
+type TestStepActionsRequest struct {
+	ActionIDs      []string          `json:"action_ids" validate:"max=100"`
+	Variables      map[string]string `json:"variables"`
+	HostMappings   map[string]string `json:"host_mappings"`
+	Locale         string            `json:"locale" validate:"max=35"`
+	TimeoutSeconds int               `json:"timeout_seconds" validate:"min=0,max=300"`
+}
+
+// TestStepActions executes actions of a step in a throwaway browser and returns how they went,
+// with a screenshot of the page once they ended
+func (h *AutomationHandler) TestStepActions(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+	stepID := chi.URLParam(r, "stepId")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req TestStepActionsRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	result, err := h.scheduler.TestActions(r.Context(), automationID, automation.ActionTestRequest{
+		StepID:         stepID,
+		ActionIDs:      req.ActionIDs,
+		Variables:      req.Variables,
+		HostMappings:   req.HostMappings,
+		Locale:         req.Locale,
+		TimeoutSeconds: req.TimeoutSeconds,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Failed to test actions")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": result,
+	})
+}
@@ -0,0 +1,398 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delordemm1/qplayground/internal/modules/auth"
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/delordemm1/qplayground/internal/modules/project"
+	"github.com/delordemm1/qplayground/internal/platform"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewDatasetRouter(datasetHandler *DatasetHandler) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", datasetHandler.ListDatasets)
+	r.Post("/", datasetHandler.CreateDataset)
+	r.Get("/{datasetId}", datasetHandler.GetDataset)
+	r.Put("/{datasetId}", datasetHandler.UpdateDataset)
+	r.Delete("/{datasetId}", datasetHandler.DeleteDataset)
+
+	// On-demand refresh from the source URL and version history
+	r.Post("/{datasetId}/refresh", datasetHandler.RefreshDataset)
+	r.Get("/{datasetId}/versions", datasetHandler.ListDatasetVersions)
+	r.Get("/{datasetId}/versions/{version}", datasetHandler.GetDatasetVersion)
+
+	return r
+}
+
+func NewDatasetHandler(automationService automation.AutomationService, projectService project.ProjectService) *DatasetHandler {
+	return &DatasetHandler{
+		automationService: automationService,
+		projectService:    projectService,
+	}
+}
+
+// DatasetHandler serves the JSON API for project datasets
+type DatasetHandler struct {
+	automationService automation.AutomationService
+	projectService    project.ProjectService
+}
+
+type DatasetRequest struct {
+	Name                   string                    `json:"name" validate:"required,min=1,max=255"`
+	SourceURL              string                    `json:"source_url" validate:"required,url,max=2048"`
+	Schema                 *automation.DatasetSchema `json:"schema"`
+	RefreshIntervalMinutes int                       `json:"refresh_interval_minutes" validate:"min=0"`
+}
+
+func (h *DatasetHandler) ListDatasets(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	datasets, err := h.automationService.GetDatasetsByProject(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list datasets"})
+		return
+	}
+	if datasets == nil {
+		datasets = []*automation.Dataset{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"datasets": datasets,
+	})
+}
+
+// CreateDataset creates a dataset and refreshes it from its source right away; a failed first
+// refresh still creates the dataset, with the failure in last_refresh_error
+func (h *DatasetHandler) CreateDataset(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	dataset := &automation.Dataset{ProjectID: projectID}
+	if !h.decodeDatasetRequest(w, r, dataset) {
+		return
+	}
+
+	dataset, err := h.automationService.CreateDataset(r.Context(), dataset)
+	if err != nil {
+		writeServiceError(w, err, "Failed to create dataset")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Dataset created successfully",
+		"dataset": dataset,
+	})
+}
+
+func (h *DatasetHandler) GetDataset(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	dataset, err := h.verifyDatasetAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "datasetId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dataset": dataset,
+	})
+}
+
+func (h *DatasetHandler) UpdateDataset(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	dataset, err := h.verifyDatasetAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "datasetId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !h.decodeDatasetRequest(w, r, dataset) {
+		return
+	}
+
+	if err := h.automationService.UpdateDataset(r.Context(), dataset); err != nil {
+		writeServiceError(w, err, "Failed to update dataset")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Dataset updated successfully",
+		"dataset": dataset,
+	})
+}
+
+func (h *DatasetHandler) DeleteDataset(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	dataset, err := h.verifyDatasetAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "datasetId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.DeleteDataset(r.Context(), dataset.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete dataset"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Dataset deleted successfully"})
+}
+
+// RefreshDataset fetches the dataset's source now. A source that fails or doesn't match the
+// schema leaves the current version in place and responds 502 with the reason.
+func (h *DatasetHandler) RefreshDataset(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	dataset, err := h.verifyDatasetAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "datasetId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	refreshed, err := h.automationService.RefreshDataset(r.Context(), dataset.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Dataset refreshed successfully",
+		"dataset": refreshed,
+	})
+}
+
+func (h *DatasetHandler) ListDatasetVersions(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	dataset, err := h.verifyDatasetAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "datasetId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	versions, err := h.automationService.GetDatasetVersions(r.Context(), dataset.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list dataset versions"})
+		return
+	}
+	if versions == nil {
+		versions = []*automation.DatasetVersion{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"versions": versions,
+	})
+}
+
+// GetDatasetVersion returns one version of a dataset including its records
+func (h *DatasetHandler) GetDatasetVersion(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	dataset, err := h.verifyDatasetAccess(r.Context(), user, chi.URLParam(r, "projectId"), chi.URLParam(r, "datasetId"))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	versionNumber, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || versionNumber < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid version"})
+		return
+	}
+
+	version, err := h.automationService.GetDatasetVersion(r.Context(), dataset.ID, versionNumber)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Dataset version not found"})
+		return
+	}
+
+	records, err := automation.ParseDatasetRecordsJSON(version.RecordsJSON)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to read dataset records"})
+		return
+	}
+	version.RecordsJSON = ""
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"version": version,
+		"records": records,
+	})
+}
+
+// decodeDatasetRequest applies a create or update request to dataset, writing the error
+// response and returning false when the request is invalid
+func (h *DatasetHandler) decodeDatasetRequest(w http.ResponseWriter, r *http.Request, dataset *automation.Dataset) bool {
+	var req DatasetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return false
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return false
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return false
+	}
+
+	schemaJSON := "{}"
+	if req.Schema != nil {
+		encoded, err := json.Marshal(req.Schema)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid schema"})
+			return false
+		}
+		schemaJSON = string(encoded)
+	}
+
+	dataset.Name = req.Name
+	dataset.SourceURL = req.SourceURL
+	dataset.SchemaJSON = schemaJSON
+	dataset.RefreshIntervalMinutes = req.RefreshIntervalMinutes
+
+	if err := automation.ValidateDataset(dataset); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeServiceError responds with the service's message for invalid or conflicting requests and
+// fallback otherwise
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, platform.ErrInvalidRequest):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, platform.ErrConflict):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, platform.ErrUnavailable):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": fallback})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func (h *DatasetHandler) verifyProjectAccess(ctx context.Context, user *auth.User, projectID string) error {
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project not found")
+	}
+
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		return fmt.Errorf("access denied to project")
+	}
+	return nil
+}
+
+func (h *DatasetHandler) verifyDatasetAccess(ctx context.Context, user *auth.User, projectID, datasetID string) (*automation.Dataset, error) {
+	if err := h.verifyProjectAccess(ctx, user, projectID); err != nil {
+		return nil, err
+	}
+
+	dataset, err := h.automationService.GetDatasetByID(ctx, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("dataset not found")
+	}
+
+	if dataset.ProjectID != projectID {
+		return nil, fmt.Errorf("access denied to dataset")
+	}
+	return dataset, nil
+}
@@ -0,0 +1,237 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/delordemm1/qplayground/internal/platform"
+	"github.com/go-chi/chi/v5"
+)
+
+// Managed automations are addressed by an external ID chosen by an infrastructure-as-code tool
+// instead of their generated ID. PUT creates or replaces them idempotently and every response
+// carries an ETag; writes honour If-Match and If-None-Match.
+
+func (h *AutomationHandler) ListManagedAutomations(w http.ResponseWriter, r *http.Request) {
+	projectID, _, ok := h.verifyManagedProjectAccess(w, r)
+	if !ok {
+		return
+	}
+
+	managed, err := h.automationService.GetManagedAutomations(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list managed automations"})
+		return
+	}
+	if managed == nil {
+		managed = []*automation.ManagedAutomation{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"automations": managed,
+	})
+}
+
+func (h *AutomationHandler) GetManagedAutomation(w http.ResponseWriter, r *http.Request) {
+	projectID, _, ok := h.verifyManagedProjectAccess(w, r)
+	if !ok {
+		return
+	}
+
+	managed, err := h.automationService.GetManagedAutomation(r.Context(), projectID, chi.URLParam(r, "externalId"))
+	if err != nil {
+		writeManagedError(w, err, "Failed to get managed automation")
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(managed.ETag))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"automation": managed,
+	})
+}
+
+// PutManagedAutomation creates or replaces a managed automation with an exported config. Unlike
+// imports, organization defaults aren't inherited, so the stored automation matches the config
+// it was applied with; organization policies are still enforced.
+func (h *AutomationHandler) PutManagedAutomation(w http.ResponseWriter, r *http.Request) {
+	projectID, orgID, ok := h.verifyManagedProjectAccess(w, r)
+	if !ok {
+		return
+	}
+
+	var config automation.ExportedAutomationConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	configBytes, err := json.Marshal(config.Automation.Config)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid automation config"})
+		return
+	}
+	configJSON, err := h.applyOrganizationDefaults(r.Context(), orgID, string(configBytes), false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	managed, created, err := h.automationService.UpsertManagedAutomation(r.Context(), projectID, chi.URLParam(r, "externalId"), configJSON, &config, writePrecondition(r))
+	if err != nil {
+		writeManagedError(w, err, "Failed to apply managed automation")
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(managed.ETag))
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"automation": managed,
+	})
+}
+
+func (h *AutomationHandler) DeleteManagedAutomation(w http.ResponseWriter, r *http.Request) {
+	projectID, _, ok := h.verifyManagedProjectAccess(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.automationService.DeleteManagedAutomation(r.Context(), projectID, chi.URLParam(r, "externalId"), writePrecondition(r)); err != nil {
+		writeManagedError(w, err, "Failed to delete managed automation")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Automation deleted successfully"})
+}
+
+func (h *AutomationHandler) GetManagedNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, _, ok := h.verifyManagedProjectAccess(w, r)
+	if !ok {
+		return
+	}
+
+	channel, err := h.automationService.GetManagedNotificationChannel(r.Context(), projectID, chi.URLParam(r, "externalId"), chi.URLParam(r, "channelId"))
+	if err != nil {
+		writeManagedError(w, err, "Failed to get notification channel")
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(channel.ETag))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"notification_channel": channel,
+	})
+}
+
+// PutManagedNotificationChannel adds or replaces one notification channel of a managed
+// automation, identified by the channel ID in the path
+func (h *AutomationHandler) PutManagedNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, _, ok := h.verifyManagedProjectAccess(w, r)
+	if !ok {
+		return
+	}
+
+	var channel automation.NotificationChannelConfig
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+	channel.ID = chi.URLParam(r, "channelId")
+
+	managed, created, err := h.automationService.UpsertManagedNotificationChannel(r.Context(), projectID, chi.URLParam(r, "externalId"), channel, writePrecondition(r))
+	if err != nil {
+		writeManagedError(w, err, "Failed to apply notification channel")
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(managed.ETag))
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"notification_channel": managed,
+	})
+}
+
+func (h *AutomationHandler) DeleteManagedNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, _, ok := h.verifyManagedProjectAccess(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.automationService.DeleteManagedNotificationChannel(r.Context(), projectID, chi.URLParam(r, "externalId"), chi.URLParam(r, "channelId"), writePrecondition(r))
+	if err != nil {
+		writeManagedError(w, err, "Failed to delete notification channel")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification channel deleted successfully"})
+}
+
+// verifyManagedProjectAccess checks the user's access to the project of the request and returns
+// the project and organization IDs, writing the error response when access is denied
+func (h *AutomationHandler) verifyManagedProjectAccess(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return "", "", false
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Project not found"})
+		return "", "", false
+	}
+
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return "", "", false
+	}
+	return projectID, project.OrganizationID, true
+}
+
+func writePrecondition(r *http.Request) automation.WritePrecondition {
+	return automation.WritePrecondition{
+		IfMatch:     r.Header.Get("If-Match"),
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+	}
+}
+
+func quoteETag(etag string) string {
+	return `"` + etag + `"`
+}
+
+// writeManagedError writes a service error of a managed resource, with 404 for missing resources
+// and 412 for failed preconditions
+func writeManagedError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, platform.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, platform.ErrPreconditionFailed):
+		w.WriteHeader(http.StatusPreconditionFailed)
+	default:
+		writeServiceError(w, err, fallback)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -1,13 +1,20 @@
 package web
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/delordemm1/qplayground/internal/modules/automation"
 	"github.com/delordemm1/qplayground/internal/modules/organization"
 	"github.com/delordemm1/qplayground/internal/platform"
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
 
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
 	inertia "github.com/romsar/gonertia/v2"
 )
 
@@ -16,22 +23,32 @@ func NewOrganizationRouter(orgHandler *OrganizationHandler) chi.Router {
 	
 	r.Get("/", orgHandler.ListOrganizations)
 	r.Get("/{id}", orgHandler.GetOrganization)
+	r.Get("/{id}/settings", orgHandler.GetOrganizationSettings)
+	r.Put("/{id}/settings", orgHandler.UpdateOrganizationSettings)
+
+	// Custom faker value sets, used as {{faker.custom.<name>}}
+	r.Get("/{id}/faker-dictionaries", orgHandler.ListFakerDictionaries)
+	r.Get("/{id}/faker-dictionaries/{name}", orgHandler.GetFakerDictionary)
+	r.Put("/{id}/faker-dictionaries/{name}", orgHandler.SaveFakerDictionary)
+	r.Delete("/{id}/faker-dictionaries/{name}", orgHandler.DeleteFakerDictionary)
 	
 	return r
 }
 
-func NewOrganizationHandler(inertia *inertia.Inertia, sessionManager *scs.SessionManager, orgService organization.OrganizationService) *OrganizationHandler {
+func NewOrganizationHandler(inertia *inertia.Inertia, sessionManager *scs.SessionManager, orgService organization.OrganizationService, automationService automation.AutomationService) *OrganizationHandler {
 	return &OrganizationHandler{
-		inertia:        inertia,
-		sessionManager: sessionManager,
-		orgService:     orgService,
+		inertia:           inertia,
+		sessionManager:    sessionManager,
+		orgService:        orgService,
+		automationService: automationService,
 	}
 }
 
 type OrganizationHandler struct {
-	inertia        *inertia.Inertia
-	sessionManager *scs.SessionManager
-	orgService     organization.OrganizationService
+	inertia           *inertia.Inertia
+	sessionManager    *scs.SessionManager
+	orgService        organization.OrganizationService
+	automationService automation.AutomationService
 }
 
 func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
@@ -86,4 +103,362 @@ func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Req
 		platform.UtilHandleServerErr(w, err)
 		return
 	}
-}
\ No newline at end of file
+}
+
+type UpdateOrganizationSettingsRequest struct {
+	DefaultBrowser           string                              `json:"defaultBrowser" validate:"omitempty,oneof=chromium firefox webkit"`
+	DefaultTimeout           int                                 `json:"defaultTimeout" validate:"min=0,max=86400"`
+	RequireScreenshotOnError bool                                `json:"requireScreenshotOnError"`
+	DefaultNotifications     []DefaultNotificationChannelRequest `json:"defaultNotifications" validate:"dive"`
+	RunRetentionDays         int                                 `json:"runRetentionDays" validate:"min=0,max=3650"`
+	ReportLocale             string                              `json:"reportLocale" validate:"max=35"`
+	Teams                    []TeamRequest                       `json:"teams" validate:"max=100,dive"` // existing teams are kept when omitted
+	StaleAutomations         *StaleAutomationSettingsRequest     `json:"staleAutomations"`              // kept when omitted
+	MaintenanceCalendar      *MaintenanceCalendarSettingsRequest `json:"maintenanceCalendar"`           // kept when omitted
+	ScriptSandbox            *ScriptSandboxSettingsRequest       `json:"scriptSandbox"`                 // kept when omitted
+}
+
+type StaleAutomationSettingsRequest struct {
+	Disabled    bool `json:"disabled"`
+	UnusedDays  int  `json:"unusedDays" validate:"min=0,max=3650"`
+	FailingDays int  `json:"failingDays" validate:"min=0,max=3650"`
+	AutoDisable bool `json:"autoDisable"`
+}
+
+type MaintenanceCalendarSettingsRequest struct {
+	URL       string `json:"url" validate:"omitempty,url,max=2048"`
+	PauseRuns bool   `json:"pauseRuns"`
+}
+
+// ScriptSandboxSettingsRequest limits custom scripts. BannedAPIs is advisory only: scripts are
+// checked textually, so names built at runtime aren't caught.
+type ScriptSandboxSettingsRequest struct {
+	MaxScriptBytes int      `json:"maxScriptBytes" validate:"min=0,max=1048576"`
+	BannedAPIs     []string `json:"bannedApis" validate:"max=100,dive,required,max=200"`
+	TimeoutMs      int      `json:"timeoutMs" validate:"min=0,max=600000"`
+}
+
+type TeamRequest struct {
+	Name          string                              `json:"name" validate:"required,max=64"`
+	MemberIDs     []string                            `json:"memberIds" validate:"dive,uuid"`
+	Notifications []DefaultNotificationChannelRequest `json:"notifications" validate:"dive"`
+}
+
+type DefaultNotificationChannelRequest struct {
+	Type       string         `json:"type" validate:"required,oneof=slack email webhook"`
+	OnComplete bool           `json:"onComplete"`
+	OnError    bool           `json:"onError"`
+	Config     map[string]any `json:"config"`
+}
+
+// verifyOrganizationOwner loads the organization and checks the user owns it
+func (h *OrganizationHandler) verifyOrganizationOwner(r *http.Request, orgID, userID string) (*organization.Organization, int) {
+	org, err := h.orgService.GetOrganizationByID(r.Context(), orgID)
+	if err != nil {
+		return nil, http.StatusNotFound
+	}
+
+	if org.OwnerUserID != userID {
+		return nil, http.StatusForbidden
+	}
+	return org, http.StatusOK
+}
+
+func (h *OrganizationHandler) GetOrganizationSettings(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	if _, status := h.verifyOrganizationOwner(r, orgID, user.ID); status != http.StatusOK {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	settings, err := h.orgService.GetOrganizationSettings(r.Context(), orgID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get organization settings"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"settings":      settings,
+		"reportLocales": i18n.Locales(),
+	})
+}
+
+func (h *OrganizationHandler) UpdateOrganizationSettings(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	if _, status := h.verifyOrganizationOwner(r, orgID, user.ID); status != http.StatusOK {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	var req UpdateOrganizationSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": ConvertValidationErrorsToInertia(validationErrors),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+
+	if req.ReportLocale != "" && !i18n.IsSupported(req.ReportLocale) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"errors": map[string]string{"reportLocale": "No message bundle is installed for this locale"},
+		})
+		return
+	}
+
+	settings := &organization.OrganizationSettings{
+		DefaultBrowser:           req.DefaultBrowser,
+		DefaultTimeout:           req.DefaultTimeout,
+		RequireScreenshotOnError: req.RequireScreenshotOnError,
+		RunRetentionDays:         req.RunRetentionDays,
+		ReportLocale:             req.ReportLocale,
+	}
+	if req.StaleAutomations != nil {
+		settings.StaleAutomations = organization.StaleAutomationSettings{
+			Disabled:    req.StaleAutomations.Disabled,
+			UnusedDays:  req.StaleAutomations.UnusedDays,
+			FailingDays: req.StaleAutomations.FailingDays,
+			AutoDisable: req.StaleAutomations.AutoDisable,
+		}
+	}
+	if req.MaintenanceCalendar != nil {
+		settings.MaintenanceCalendar = organization.MaintenanceCalendarSettings{
+			URL:       req.MaintenanceCalendar.URL,
+			PauseRuns: req.MaintenanceCalendar.PauseRuns,
+		}
+	}
+	if req.ScriptSandbox != nil {
+		settings.ScriptSandbox = organization.ScriptSandboxSettings{
+			MaxScriptBytes: req.ScriptSandbox.MaxScriptBytes,
+			BannedAPIs:     req.ScriptSandbox.BannedAPIs,
+			TimeoutMs:      req.ScriptSandbox.TimeoutMs,
+		}
+	}
+	for _, channel := range req.DefaultNotifications {
+		settings.DefaultNotifications = append(settings.DefaultNotifications, organization.DefaultNotificationChannel{
+			Type:       channel.Type,
+			OnComplete: channel.OnComplete,
+			OnError:    channel.OnError,
+			Config:     channel.Config,
+		})
+	}
+
+	// Sections left out of the request keep their current values, so a save from a client that
+	// doesn't know about a section can't reset it
+	if req.Teams == nil || req.StaleAutomations == nil || req.MaintenanceCalendar == nil || req.ScriptSandbox == nil {
+		current, err := h.orgService.GetOrganizationSettings(r.Context(), orgID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get organization settings"})
+			return
+		}
+		if req.Teams == nil {
+			settings.Teams = current.Teams
+		}
+		if req.StaleAutomations == nil {
+			settings.StaleAutomations = current.StaleAutomations
+		}
+		if req.MaintenanceCalendar == nil {
+			settings.MaintenanceCalendar = current.MaintenanceCalendar
+		}
+		if req.ScriptSandbox == nil {
+			settings.ScriptSandbox = current.ScriptSandbox
+		}
+	}
+	for _, team := range req.Teams {
+		orgTeam := organization.Team{Name: team.Name, MemberIDs: team.MemberIDs}
+		for _, channel := range team.Notifications {
+			orgTeam.Notifications = append(orgTeam.Notifications, organization.DefaultNotificationChannel{
+				Type:       channel.Type,
+				OnComplete: channel.OnComplete,
+				OnError:    channel.OnError,
+				Config:     channel.Config,
+			})
+		}
+		settings.Teams = append(settings.Teams, orgTeam)
+	}
+
+	if err := h.orgService.UpdateOrganizationSettings(r.Context(), orgID, settings); err != nil {
+		platform.SetFlashError(r.Context(), h.sessionManager, "Failed to update organization settings")
+		writeServiceError(w, err, "Failed to update organization settings")
+		return
+	}
+
+	// Fetch the calendar now rather than at the next periodic sync, so a new feed takes effect
+	// right away; the fetch may be slow, so it doesn't hold the response
+	if req.MaintenanceCalendar != nil && settings.MaintenanceCalendar.URL != "" {
+		go h.automationService.SyncMaintenanceCalendar(context.WithoutCancel(r.Context()), orgID)
+	}
+
+	platform.SetFlashSuccess(r.Context(), h.sessionManager, "Organization settings updated successfully")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Organization settings updated successfully",
+		"settings": settings,
+	})
+}
+
+// SaveFakerDictionaryRequest holds a dictionary's values, as a list or as text with one value
+// per line, e.g. an uploaded word list
+type SaveFakerDictionaryRequest struct {
+	Values []string `json:"values" validate:"required_without=Text"`
+	Text   string   `json:"text" validate:"required_without=Values"`
+}
+
+func (h *OrganizationHandler) ListFakerDictionaries(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	if _, status := h.verifyOrganizationOwner(r, orgID, user.ID); status != http.StatusOK {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	dictionaries, err := h.orgService.GetFakerDictionaries(r.Context(), orgID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list faker dictionaries"})
+		return
+	}
+	if dictionaries == nil {
+		dictionaries = []*organization.FakerDictionary{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dictionaries": dictionaries,
+	})
+}
+
+func (h *OrganizationHandler) GetFakerDictionary(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	if _, status := h.verifyOrganizationOwner(r, orgID, user.ID); status != http.StatusOK {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	dictionary, err := h.orgService.GetFakerDictionary(r.Context(), orgID, chi.URLParam(r, "name"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Faker dictionary not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dictionary": dictionary,
+	})
+}
+
+// SaveFakerDictionary uploads a dictionary, replacing its values when the organization already
+// has one with that name
+func (h *OrganizationHandler) SaveFakerDictionary(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	if _, status := h.verifyOrganizationOwner(r, orgID, user.ID); status != http.StatusOK {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	var req SaveFakerDictionaryRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	values := req.Values
+	if req.Text != "" {
+		values = append(values, strings.Split(strings.ReplaceAll(req.Text, "\r\n", "\n"), "\n")...)
+	}
+
+	dictionary, err := h.orgService.SaveFakerDictionary(r.Context(), &organization.FakerDictionary{
+		OrganizationID: orgID,
+		Name:           chi.URLParam(r, "name"),
+		Values:         values,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Failed to save faker dictionary")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    fmt.Sprintf("Faker dictionary %s saved with %d values", dictionary.Name, dictionary.ValueCount),
+		"dictionary": dictionary,
+	})
+}
+
+func (h *OrganizationHandler) DeleteFakerDictionary(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	if _, status := h.verifyOrganizationOwner(r, orgID, user.ID); status != http.StatusOK {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Access denied"})
+		return
+	}
+
+	if err := h.orgService.DeleteFakerDictionary(r.Context(), orgID, chi.URLParam(r, "name")); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Faker dictionary not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Faker dictionary deleted successfully"})
+}
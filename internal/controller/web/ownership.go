@@ -0,0 +1,101 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetAutomationOwnersRequest replaces the owners of an automation
+type SetAutomationOwnersRequest struct {
+	Owners []automation.AutomationOwner `json:"owners" validate:"dive"`
+}
+
+// GetAutomationOwners returns the owners of an automation and who else could own it
+func (h *AutomationHandler) GetAutomationOwners(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	owners, err := h.automationService.GetAutomationOwners(r.Context(), automationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get automation owners"})
+		return
+	}
+	candidates, err := h.automationService.GetOwnerCandidates(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get owner candidates"})
+		return
+	}
+	if owners == nil {
+		owners = []automation.AutomationOwner{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"owners":     owners,
+		"candidates": candidates,
+	})
+}
+
+// SetAutomationOwners replaces the owners of an automation with users of the organization and
+// teams from its settings
+func (h *AutomationHandler) SetAutomationOwners(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	automationID := chi.URLParam(r, "id")
+
+	if err := h.verifyAutomationAccess(r.Context(), user, projectID, automationID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req SetAutomationOwnersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request format"})
+		return
+	}
+
+	target, err := h.automationService.GetAutomationByID(r.Context(), automationID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Automation not found"})
+		return
+	}
+
+	owners, err := h.automationService.SetAutomationOwners(r.Context(), target, req.Owners)
+	if err != nil {
+		writeServiceError(w, err, "Failed to set automation owners")
+		return
+	}
+	h.automationService.RequestOwnerReview(r.Context(), automationID, user.ID, user.Email, "changed its owners")
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"owners": owners,
+	})
+}
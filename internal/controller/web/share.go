@@ -0,0 +1,260 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/delordemm1/qplayground/internal/platform"
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewShareRouter(shareHandler *ShareHandler) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/runs/{token}", shareHandler.GetSharedRun)
+	r.Get("/runs/{token}/preview", shareHandler.GetSharedRunPreview)
+	r.Get("/automations/{token}/status", shareHandler.GetEmbeddedStatus)
+	r.Get("/automations/{token}/widget", shareHandler.GetEmbeddedWidget)
+
+	return r
+}
+
+func NewShareHandler(automationService automation.AutomationService) *ShareHandler {
+	return &ShareHandler{
+		automationService: automationService,
+	}
+}
+
+// ShareHandler serves read-only views of resources to holders of a share token.
+// Routes mounted here are public and must never expose anything beyond the shared resource.
+type ShareHandler struct {
+	automationService automation.AutomationService
+}
+
+func (h *ShareHandler) GetSharedRun(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	share, run, automation, err := h.automationService.GetSharedRun(r.Context(), token)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Share link is invalid or has expired"})
+		return
+	}
+
+	previewImageURL, err := h.automationService.GetRunPreviewURL(r.Context(), run.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get shared run"})
+		return
+	}
+
+	var logs []map[string]interface{}
+	if run.LogsJSON != "" {
+		json.Unmarshal([]byte(run.LogsJSON), &logs)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"automation": map[string]interface{}{
+			"name":        automation.Name,
+			"description": automation.Description,
+		},
+		"run": map[string]interface{}{
+			"id":            run.ID,
+			"status":        run.Status,
+			"start_time":    run.StartTime,
+			"end_time":      run.EndTime,
+			"error_message": run.ErrorMessage,
+			"logs":          logs,
+			"output_files":  run.OutputFiles(),
+		},
+		"preview_image_url": previewImageURL,
+		"expires_at":        share.ExpiresAt,
+	})
+}
+
+// GetSharedRunPreview renders a page carrying OpenGraph tags for a shared run, so links to it
+// pasted into Slack, Teams and the like unfurl with the run's summary card
+func (h *ShareHandler) GetSharedRunPreview(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	_, run, automation, err := h.automationService.GetSharedRun(r.Context(), token)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("Share link is invalid or has expired"))
+		return
+	}
+
+	previewImageURL, err := h.automationService.GetRunPreviewURL(r.Context(), run.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Failed to get shared run"))
+		return
+	}
+
+	l := i18n.For(run.Locale)
+	description := l.T("report.status") + ": " + l.T("status."+run.Status)
+	if run.StartTime != nil && run.EndTime != nil {
+		description += " · " + l.T("report.duration") + ": " + run.EndTime.Sub(*run.StartTime).Round(time.Second/10).String()
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := sharedRunPreviewTemplate.Execute(w, map[string]interface{}{
+		"Title":       automation.Name,
+		"Description": description,
+		"ImageURL":    previewImageURL,
+		"RunURL":      fmt.Sprintf("%s/share/runs/%s", platform.ENV_APP_URL, token),
+		"L":           l,
+	}); err != nil {
+		w.Write([]byte("Failed to render preview"))
+	}
+}
+
+// GetEmbeddedStatus returns the status summary behind an automation embed as JSON for dashboards
+func (h *ShareHandler) GetEmbeddedStatus(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	summary, err := h.automationService.GetEmbeddedStatus(r.Context(), token)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Embed is invalid or has been revoked"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(summary)
+}
+
+// GetEmbeddedWidget renders a small self-contained HTML status card intended to be loaded in an iframe
+func (h *ShareHandler) GetEmbeddedWidget(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	summary, err := h.automationService.GetEmbeddedStatus(r.Context(), token)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("Embed is invalid or has been revoked"))
+		return
+	}
+
+	var maxDuration int64
+	for _, point := range summary.DurationTrend {
+		if point.DurationMs > maxDuration {
+			maxDuration = point.DurationMs
+		}
+	}
+
+	bars := make([]map[string]interface{}, len(summary.DurationTrend))
+	for i, point := range summary.DurationTrend {
+		height := 2
+		if maxDuration > 0 {
+			height = max(2, int(point.DurationMs*40/maxDuration))
+		}
+		bars[i] = map[string]interface{}{
+			"Height":   height,
+			"Status":   point.Status,
+			"Duration": (time.Duration(point.DurationMs) * time.Millisecond).String(),
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := embedWidgetTemplate.Execute(w, map[string]interface{}{
+		"Summary": summary,
+		"Bars":    bars,
+		"L":       i18n.For(summary.Locale),
+	}); err != nil {
+		w.Write([]byte("Failed to render widget"))
+	}
+}
+
+var embedWidgetTemplate = template.Must(template.New("embed").Funcs(template.FuncMap{
+	"duration": func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).Round(time.Millisecond).String()
+	},
+	"timestamp": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.L.Locale}}">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>{{.Summary.AutomationName}}</title>
+<style>
+body{margin:0;padding:12px;font-family:system-ui,sans-serif;font-size:13px;color:#1f2937;background:#fff}
+h1{font-size:14px;margin:0 0 8px}
+.status{display:inline-block;padding:2px 8px;border-radius:9999px;font-weight:600;text-transform:capitalize;background:#e5e7eb}
+.status.completed{background:#d1fae5;color:#065f46}
+.status.failed{background:#fee2e2;color:#991b1b}
+.status.running{background:#dbeafe;color:#1e40af}
+.trend{display:flex;align-items:flex-end;gap:2px;height:40px;margin:10px 0}
+.trend span{width:8px;background:#10b981}
+.trend span.failed{background:#ef4444}
+.muted{color:#6b7280}
+.failure{color:#991b1b;overflow:hidden;text-overflow:ellipsis;white-space:nowrap}
+</style>
+</head>
+<body>
+<h1>{{.Summary.AutomationName}}</h1>
+{{with .Summary.LatestRun}}
+<div><span class="status {{.Status}}">{{$.L.T (printf "status.%s" .Status)}}</span> <span class="muted">{{timestamp .EndTime}}{{if .DurationMs}} &middot; {{duration .DurationMs}}{{end}}</span></div>
+{{else}}
+<div class="muted">{{.L.T "report.no_runs"}}</div>
+{{end}}
+{{if .Bars}}<div class="trend">{{range .Bars}}<span class="{{.Status}}" style="height:{{.Height}}px" title="{{.Duration}}"></span>{{end}}</div>{{end}}
+{{with .Summary.LastFailure}}<div class="failure" title="{{.ErrorMessage}}">{{$.L.T "report.last_failure" (timestamp .EndTime) .ErrorMessage}}</div>{{end}}
+</body>
+</html>
+`))
+
+var sharedRunPreviewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="{{.L.Locale}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+{{if .ImageURL}}<meta property="og:image" content="{{.ImageURL}}">
+<meta property="og:image:width" content="1200">
+<meta property="og:image:height" content="630">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.ImageURL}}">{{end}}
+<style>
+body{margin:0;padding:24px;font-family:system-ui,sans-serif;color:#1f2937;background:#f9fafb}
+img{max-width:100%;border:1px solid #e5e7eb;border-radius:8px}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Description}}</p>
+{{if .ImageURL}}<p><img src="{{.ImageURL}}" alt="{{.Title}}" width="600"></p>{{end}}
+<p><a href="{{.RunURL}}">{{.L.T "report.run_details"}}</a></p>
+</body>
+</html>
+`))
@@ -121,6 +121,20 @@ func (m *AuthMiddleware) OnlyUser(next http.Handler) http.Handler {
 	})
 }
 
+// OnlyAdmin allows platform admins only; others get a 404 so admin routes aren't advertised
+func (m *AuthMiddleware) OnlyAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authUser, err := m.authService.Auth(r.Context())
+		if err != nil || authUser.Role != auth.UserRoleAdmin {
+			http.NotFound(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), auth.AuthUserIDSessionKey, authUser)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func getUserAgentProps(r *http.Request) *platform.UserAgent {
 	return &platform.UserAgent{
 		UserAgent: r.UserAgent(),
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/delordemm1/qplayground/internal/modules/auth"
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+	"github.com/delordemm1/qplayground/internal/modules/project"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewTranslationCatalogRouter(translationCatalogHandler *TranslationCatalogHandler) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", translationCatalogHandler.ListTranslationCatalogs)
+	r.Get("/{locale}", translationCatalogHandler.GetTranslationCatalog)
+	r.Put("/{locale}", translationCatalogHandler.SaveTranslationCatalog)
+	r.Delete("/{locale}", translationCatalogHandler.DeleteTranslationCatalog)
+
+	return r
+}
+
+func NewTranslationCatalogHandler(automationService automation.AutomationService, projectService project.ProjectService) *TranslationCatalogHandler {
+	return &TranslationCatalogHandler{
+		automationService: automationService,
+		projectService:    projectService,
+	}
+}
+
+// TranslationCatalogHandler serves the JSON API for project translation catalogs
+type TranslationCatalogHandler struct {
+	automationService automation.AutomationService
+	projectService    project.ProjectService
+}
+
+// SaveTranslationCatalogRequest holds a catalog's messages, flat or nested by key
+type SaveTranslationCatalogRequest struct {
+	Messages map[string]interface{} `json:"messages" validate:"required"`
+}
+
+func (h *TranslationCatalogHandler) ListTranslationCatalogs(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	catalogs, err := h.automationService.GetTranslationCatalogsByProject(r.Context(), projectID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list translation catalogs"})
+		return
+	}
+	if catalogs == nil {
+		catalogs = []*automation.TranslationCatalog{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"catalogs": catalogs,
+	})
+}
+
+func (h *TranslationCatalogHandler) GetTranslationCatalog(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	catalog, err := h.automationService.GetTranslationCatalog(r.Context(), projectID, chi.URLParam(r, "locale"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Translation catalog not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"catalog": catalog,
+	})
+}
+
+// SaveTranslationCatalog uploads the catalog of a locale, replacing its messages when the
+// project already has one
+func (h *TranslationCatalogHandler) SaveTranslationCatalog(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req SaveTranslationCatalogRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	messages, err := automation.FlattenTranslationMessages(req.Messages)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	catalog, err := h.automationService.SaveTranslationCatalog(r.Context(), &automation.TranslationCatalog{
+		ProjectID: projectID,
+		Locale:    chi.URLParam(r, "locale"),
+		Messages:  messages,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Failed to save translation catalog")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": fmt.Sprintf("Translation catalog for %s saved with %d messages", catalog.Locale, catalog.MessageCount),
+		"catalog": catalog,
+	})
+}
+
+func (h *TranslationCatalogHandler) DeleteTranslationCatalog(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	if err := h.verifyProjectAccess(r.Context(), user, projectID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.automationService.DeleteTranslationCatalog(r.Context(), projectID, chi.URLParam(r, "locale")); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Translation catalog not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Translation catalog deleted successfully"})
+}
+
+func (h *TranslationCatalogHandler) verifyProjectAccess(ctx context.Context, user *auth.User, projectID string) error {
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project not found")
+	}
+
+	if user.CurrentOrgID == nil || project.OrganizationID != *user.CurrentOrgID {
+		return fmt.Errorf("access denied to project")
+	}
+	return nil
+}
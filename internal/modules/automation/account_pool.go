@@ -0,0 +1,53 @@
+package automation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Account pool limits
+const (
+	MinAccountPoolLeaseTTLSeconds     = 60
+	DefaultAccountPoolLeaseTTLSeconds = 3600
+	MaxPoolAccountsPerRequest         = 500
+)
+
+// ValidateAccountPool checks the user-editable fields of a pool, filling in defaults
+func ValidateAccountPool(pool *AccountPool) error {
+	pool.Name = strings.TrimSpace(pool.Name)
+	if pool.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	pool.Environment = strings.TrimSpace(pool.Environment)
+	if pool.Environment == "" {
+		pool.Environment = DefaultAccountPoolEnvironment
+	}
+
+	if pool.LeaseTTLSeconds == 0 {
+		pool.LeaseTTLSeconds = DefaultAccountPoolLeaseTTLSeconds
+	}
+	if pool.LeaseTTLSeconds < MinAccountPoolLeaseTTLSeconds {
+		return fmt.Errorf("lease_ttl_seconds must be at least %d", MinAccountPoolLeaseTTLSeconds)
+	}
+	return nil
+}
+
+// ValidatePoolAccount checks an account before it is added to a pool. Accounts without a label
+// are labelled with their username credential.
+func ValidatePoolAccount(account *PoolAccount) error {
+	if account.Credentials == nil {
+		account.Credentials = make(map[string]interface{})
+	}
+
+	account.Label = strings.TrimSpace(account.Label)
+	if account.Label == "" {
+		if username, ok := account.Credentials["username"].(string); ok {
+			account.Label = strings.TrimSpace(username)
+		}
+	}
+	if account.Label == "" {
+		return fmt.Errorf("label is required when credentials have no username")
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package automation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+	"github.com/playwright-community/playwright-go"
+)
+
+// Action test limits. Tests hold a browser for the duration of an HTTP request, so only a few
+// run at a time on a worker, next to its regular runs.
+const (
+	maxConcurrentActionTests     = 2
+	defaultActionTestTimeoutSecs = 60
+	maxActionTestTimeoutSecs     = 300
+)
+
+// TestActions executes actions of a step on their own in a throwaway browser, so a selector or
+// request can be checked without triggering a run. Nothing is recorded as a run; files the
+// actions write are uploaded like those of runs. Pool accounts can't be leased by tests, since
+// leases belong to runs.
+func (s *Scheduler) TestActions(ctx context.Context, automationID string, req ActionTestRequest) (*ActionTestResult, error) {
+	select {
+	case s.actionTestSlots <- struct{}{}:
+		defer func() { <-s.actionTestSlots }()
+	default:
+		return nil, fmt.Errorf("%w: %d action tests are already running, try again shortly", platform.ErrConflict, maxConcurrentActionTests)
+	}
+
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	step, err := s.automationRepo.GetStepByID(ctx, req.StepID)
+	if err != nil || step.AutomationID != automationID {
+		return nil, fmt.Errorf("%w: step not found in this automation", platform.ErrInvalidRequest)
+	}
+	actions, err := s.automationRepo.GetActionsByStepID(ctx, step.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions: %w", err)
+	}
+	if len(req.ActionIDs) > 0 {
+		var selected []*AutomationAction
+		for _, action := range actions {
+			if slices.Contains(req.ActionIDs, action.ID) {
+				selected = append(selected, action)
+			}
+		}
+		if len(selected) != len(req.ActionIDs) {
+			return nil, fmt.Errorf("%w: actions must be actions of the step, each given once", platform.ErrInvalidRequest)
+		}
+		actions = selected
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("%w: the step has no actions to test", platform.ErrInvalidRequest)
+	}
+
+	if req.Locale != "" {
+		if req.Locale, err = NormalizeLocale(req.Locale); err != nil {
+			return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+		}
+	}
+	if req.TimeoutSeconds <= 0 {
+		req.TimeoutSeconds = defaultActionTestTimeoutSecs
+	}
+	timeout := time.Duration(min(req.TimeoutSeconds, maxActionTestTimeoutSecs)) * time.Second
+
+	// Registered like a run, so the test is cancelled on shutdown and the process watchdog
+	// leaves its browser alone
+	testID := platform.UtilGenerateUUID()
+	testCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	s.mu.Lock()
+	s.runContexts[testID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.runContexts, testID)
+		s.mu.Unlock()
+	}()
+
+	slog.Info("Testing actions", "automation_id", automationID, "step_id", step.ID, "actions", len(actions), "test_id", testID)
+	result, err := s.runner.TestActions(testCtx, testID, automation, step, actions, req)
+	if err == nil && result.Status == "failed" && errors.Is(testCtx.Err(), context.DeadlineExceeded) {
+		result.Error = fmt.Sprintf("timed out after %s: %s", timeout, result.Error)
+	}
+	return result, err
+}
+
+// TestActions executes actions as the first user of a run with ID testID would, stopping at
+// the first failure, and screenshots the page once they ended
+func (r *Runner) TestActions(ctx context.Context, testID string, automation *Automation, step *AutomationStep, actions []*AutomationAction, req ActionTestRequest) (*ActionTestResult, error) {
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		configJSON, _, err := UpgradeAutomationConfigJSON(automation.ConfigJSON)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(configJSON), &automationConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse automation config: %w", err)
+		}
+	}
+	if req.HostMappings != nil {
+		automationConfig.HostMappings = req.HostMappings
+	}
+	if err := validateHostMappings(automationConfig.HostMappings); err != nil {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	httpClient, err := newRunHTTPClient(&automationConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid TLS configuration: %s", platform.ErrInvalidRequest, err)
+	}
+	defer httpClient.CloseIdleConnections()
+
+	fakerSets, err := r.automationRepo.GetFakerDictionariesByProjectID(ctx, automation.ProjectID)
+	if err != nil {
+		slog.Warn("Failed to load faker dictionaries", "test_id", testID, "error", err)
+	}
+
+	scriptSandbox, err := r.automationRepo.GetScriptSandboxByProjectID(ctx, automation.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load script sandbox: %w", err)
+	}
+
+	pw, err := playwright.Run()
+	if err != nil {
+		return nil, fmt.Errorf("could not start playwright: %w", err)
+	}
+	defer pw.Stop()
+
+	browser, err := launchBrowser(pw, &automationConfig, testID)
+	if err != nil {
+		return nil, fmt.Errorf("could not launch browser: %w", err)
+	}
+	defer browser.Close()
+
+	page, err := browser.NewPage(playwright.BrowserNewPageOptions{
+		JavaScriptEnabled: playwright.Bool(true),
+		IgnoreHttpsErrors: playwright.Bool(automationConfig.TLS.InsecureSkipVerify),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create page: %w", err)
+	}
+
+	varContext := &VariableContext{
+		Timestamp:    time.Now().Format("20060102-150405"),
+		RunID:        testID,
+		ProjectID:    automation.ProjectID,
+		AutomationID: automation.ID,
+		Locale:       runLocale(&AutomationRun{Locale: req.Locale}, &automationConfig, 0),
+		FakerSets:    fakerSets,
+		StaticVars:   make(map[string]string),
+		RuntimeVars:  make(map[string]interface{}),
+		GlobalVars:   make(map[string]interface{}),
+	}
+	for _, variable := range automationConfig.Variables {
+		if variable.Type == "static" {
+			varContext.StaticVars[variable.Key] = variable.Value
+		}
+	}
+	// Static variables take precedence over the automation's other variables of the same key
+	for key, value := range req.Variables {
+		varContext.StaticVars[key] = value
+	}
+	// The step's variables apply too, except those the test overrides
+	if stepConfig, err := ParseStepConfig(step.ConfigJSON); err == nil {
+		for _, variable := range stepConfig.Variables {
+			if _, overridden := req.Variables[variable.Key]; !overridden {
+				varContext.StepVars = append(varContext.StepVars, variable)
+			}
+		}
+	}
+
+	eventCh := make(chan RunEvent, 1000)
+	result := &ActionTestResult{Logs: []map[string]any{}}
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for event := range eventCh {
+			if entry := actionTestLogEntry(event); entry != nil {
+				result.Logs = append(result.Logs, entry)
+			}
+		}
+	}()
+
+	runContext := &RunContext{
+		PlaywrightBrowser: browser,
+		PlaywrightPage:    page,
+		StorageService:    r.storageService,
+		Logger:            slog.Default().With("automation_id", automation.ID, "test_id", testID),
+		EventCh:           eventCh,
+		StepName:          step.Name,
+		StepID:            step.ID,
+		Runner:            r,
+		VariableContext:   varContext,
+		AutomationConfig:  &automationConfig,
+		HTTPClient:        httpClient,
+		KVStore:           r.kvStore,
+		Sync:              NewSyncCoordinator(1),
+		Timers:            make(map[string]time.Time),
+		Attempt:           1,
+		ScriptSandbox:     scriptSandbox,
+	}
+
+	start := time.Now()
+	result.Status = "completed"
+	var failedSelector string
+	for _, action := range actions {
+		outcome := ActionTestOutcome{ActionID: action.ID, ActionName: action.Name, ActionType: action.ActionType}
+		if result.Status == "failed" {
+			outcome.Status = "skipped"
+			result.Actions = append(result.Actions, outcome)
+			continue
+		}
+
+		actionStart := time.Now()
+		resolvedConfig, actionErr := r.prepareTestAction(action, varContext, &automationConfig)
+		if actionErr == nil {
+			runContext.ActionID = action.ID
+			runContext.ActionName = action.Name
+			runContext.ParentActionID = ""
+			var pluginAction PluginAction
+			if pluginAction, actionErr = GetAction(action.ActionType); actionErr == nil {
+				actionErr = pluginAction.Execute(ctx, resolvedConfig, runContext)
+			}
+		}
+		outcome.DurationMs = time.Since(actionStart).Milliseconds()
+
+		if actionErr != nil {
+			outcome.Status = "failed"
+			outcome.Error = actionErr.Error()
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("action '%s' failed: %s", action.ActionType, actionErr)
+			failedSelector, _ = resolvedConfig["selector"].(string)
+		} else {
+			outcome.Status = "completed"
+		}
+		result.Actions = append(result.Actions, outcome)
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	reportIncompleteTimers(runContext)
+	close(eventCh)
+	<-collected
+
+	if screenshot, err := page.Screenshot(); err != nil {
+		slog.Warn("Failed to screenshot action test", "test_id", testID, "error", err)
+	} else {
+		if failedSelector != "" {
+			if annotated, err := AnnotateScreenshot(page, screenshot, failedSelector, false, 0); err == nil {
+				screenshot = annotated
+			}
+		}
+		result.Screenshot = base64.StdEncoding.EncodeToString(screenshot)
+	}
+
+	result.Variables = varContext.RuntimeVars
+	return result, nil
+}
+
+// prepareTestAction parses and resolves the config of an action like runs do
+func (r *Runner) prepareTestAction(action *AutomationAction, varContext *VariableContext, automationConfig *AutomationConfig) (map[string]any, error) {
+	actionConfigMap := make(map[string]any)
+	if action.ActionConfigJSON != "" {
+		if err := json.Unmarshal([]byte(action.ActionConfigJSON), &actionConfigMap); err != nil {
+			return nil, fmt.Errorf("failed to parse action config JSON: %w", err)
+		}
+	}
+	if _, err := UpgradeActionConfig(action.ActionType, actionConfigMap); err != nil {
+		return nil, err
+	}
+	return r.ResolveVariablesInConfig(actionConfigMap, varContext, automationConfig)
+}
+
+// actionTestLogEntry converts an event of an action test into a log entry, nil for events
+// that aren't logged
+func actionTestLogEntry(event RunEvent) map[string]any {
+	entry := map[string]any{
+		"timestamp":        event.Timestamp.Format(time.RFC3339),
+		"parent_action_id": event.ParentActionID,
+		"action_id":        event.ActionID,
+		"action_type":      event.ActionType,
+		"duration_ms":      event.Duration,
+	}
+	switch event.Type {
+	case RunEventTypeLog:
+		entry["message"] = event.Message
+		entry["status"] = "success"
+	case RunEventTypeError:
+		entry["error"] = event.Error
+		entry["status"] = "failed"
+		if event.Data != nil {
+			entry["data"] = event.Data
+		}
+	case RunEventTypeWarning:
+		entry["message"] = event.Message
+		entry["status"] = "warning"
+	case RunEventTypeOutputFile:
+		entry["output_file"] = event.OutputFile
+		entry["status"] = "success"
+	default:
+		return nil
+	}
+	return entry
+}
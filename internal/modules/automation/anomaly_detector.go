@@ -0,0 +1,283 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/delordemm1/qplayground/internal/modules/notification"
+	"github.com/delordemm1/qplayground/internal/platform"
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
+)
+
+// Anomaly detection defaults, used when an automation leaves the setting unset
+const (
+	defaultAnomalyThreshold   = 3.0
+	defaultAnomalyMinSamples  = 10
+	defaultAnomalyHistorySize = 50
+)
+
+// AnomalyDetector compares the durations of finished runs, and of each of their steps, with
+// recent completed runs of the same automation. Runs deviating beyond the configured number
+// of standard deviations are tagged with anomaly records and, optionally, notified.
+type AnomalyDetector struct {
+	automationRepo      AutomationRepository
+	notificationService notification.NotificationService
+	jobs                chan finishedRunJob
+	stopCh              chan struct{}
+	doneCh              chan struct{}
+}
+
+// NewAnomalyDetector creates an anomaly detector
+func NewAnomalyDetector(automationRepo AutomationRepository, notificationService notification.NotificationService) *AnomalyDetector {
+	return &AnomalyDetector{
+		automationRepo:      automationRepo,
+		notificationService: notificationService,
+		jobs:                make(chan finishedRunJob, 256),
+		stopCh:              make(chan struct{}),
+		doneCh:              make(chan struct{}),
+	}
+}
+
+// Start begins analyzing queued runs
+func (d *AnomalyDetector) Start(ctx context.Context) {
+	slog.Info("Run anomaly detector started")
+
+	go func() {
+		defer close(d.doneCh)
+
+		for {
+			select {
+			case job := <-d.jobs:
+				d.analyze(ctx, job)
+			case <-d.stopCh:
+				slog.Info("Run anomaly detector stopped")
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the detector; runs still queued are not analyzed
+func (d *AnomalyDetector) Stop() {
+	close(d.stopCh)
+	<-d.doneCh
+}
+
+// Enqueue queues a finished run for analysis. The run is copied, so callers may keep using it.
+func (d *AnomalyDetector) Enqueue(projectID string, run *AutomationRun) {
+	// Failed and cancelled runs end early, so their durations say nothing about performance
+	if run.Status != "completed" {
+		return
+	}
+
+	select {
+	case d.jobs <- finishedRunJob{projectID: projectID, run: *run}:
+	default:
+		slog.Warn("Anomaly detection queue full, skipping run", "run_id", run.ID)
+	}
+}
+
+func (d *AnomalyDetector) analyze(ctx context.Context, job finishedRunJob) {
+	run := &job.run
+
+	automation, err := d.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get automation for anomaly detection", "error", err, "automationID", run.AutomationID)
+		return
+	}
+
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		if err := json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig); err != nil {
+			return
+		}
+	}
+	config := automationConfig.AnomalyDetection
+	if !config.Enabled {
+		return
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = defaultAnomalyThreshold
+	}
+	if config.MinSamples <= 1 {
+		config.MinSamples = defaultAnomalyMinSamples
+	}
+	if config.HistorySize < config.MinSamples {
+		config.HistorySize = max(defaultAnomalyHistorySize, config.MinSamples)
+	}
+
+	history, err := d.automationRepo.GetRecentCompletedRuns(ctx, run.AutomationID, run.ID, config.HistorySize)
+	if err != nil {
+		slog.Error("Failed to get run history for anomaly detection", "error", err, "runID", run.ID)
+		return
+	}
+
+	anomalies := detectRunAnomalies(job.projectID, run, history, config)
+	for _, anomaly := range anomalies {
+		if err := d.automationRepo.CreateRunAnomaly(ctx, anomaly); err != nil {
+			slog.Error("Failed to save run anomaly", "error", err, "runID", run.ID)
+		}
+	}
+
+	if len(anomalies) == 0 {
+		return
+	}
+	slog.Warn("Run duration anomalies detected", "run_id", run.ID, "automation_id", run.AutomationID, "count", len(anomalies))
+
+	if config.Notify {
+		d.notify(ctx, automation, run, &automationConfig, anomalies)
+	}
+}
+
+// detectRunAnomalies compares a run and its steps against the baseline of history
+func detectRunAnomalies(projectID string, run *AutomationRun, history []*AutomationRun, config AnomalyDetectionConfig) []*RunAnomaly {
+	var anomalies []*RunAnomaly
+
+	flag := func(scope, stepID, stepName string, duration int64, baseline []float64) {
+		if len(baseline) < config.MinSamples {
+			return
+		}
+		mean, stddev := meanAndStdDev(baseline)
+		if stddev == 0 {
+			return
+		}
+		deviation := (float64(duration) - mean) / stddev
+		if math.Abs(deviation) < config.Threshold {
+			return
+		}
+		anomalies = append(anomalies, &RunAnomaly{
+			ID:               platform.UtilGenerateUUID(),
+			RunID:            run.ID,
+			AutomationID:     run.AutomationID,
+			Scope:            scope,
+			StepID:           stepID,
+			StepName:         stepName,
+			DurationMs:       duration,
+			BaselineMeanMs:   mean,
+			BaselineStdDevMs: stddev,
+			Deviation:        deviation,
+			SampleSize:       len(baseline),
+		})
+	}
+
+	// Whole-run duration
+	if run.StartTime != nil && run.EndTime != nil {
+		var baseline []float64
+		for _, past := range history {
+			baseline = append(baseline, float64(past.EndTime.Sub(*past.StartTime).Milliseconds()))
+		}
+		flag(AnomalyScopeRun, "", "", run.EndTime.Sub(*run.StartTime).Milliseconds(), baseline)
+	}
+
+	// Per-step durations, averaged over the loops of each run
+	stepBaselines := make(map[string][]float64)
+	for _, past := range history {
+		for stepID, duration := range averageStepDurations(projectID, past) {
+			stepBaselines[stepID] = append(stepBaselines[stepID], float64(duration.durationMs))
+		}
+	}
+	for stepID, duration := range averageStepDurations(projectID, run) {
+		flag(AnomalyScopeStep, stepID, duration.stepName, duration.durationMs, stepBaselines[stepID])
+	}
+
+	return anomalies
+}
+
+type stepDuration struct {
+	stepName   string
+	durationMs int64
+}
+
+// averageStepDurations returns each step's mean duration across the loops of a run
+func averageStepDurations(projectID string, run *AutomationRun) map[string]stepDuration {
+	// Same per step, per loop aggregation as the warehouse export
+	_, metrics := buildWarehouseRecords(projectID, "", run)
+
+	totals := make(map[string]int64)
+	loops := make(map[string]int64)
+	names := make(map[string]string)
+	for _, metric := range metrics {
+		totals[metric.StepID] += metric.DurationMs
+		loops[metric.StepID]++
+		names[metric.StepID] = metric.StepName
+	}
+
+	durations := make(map[string]stepDuration, len(totals))
+	for stepID, total := range totals {
+		durations[stepID] = stepDuration{stepName: names[stepID], durationMs: total / loops[stepID]}
+	}
+	return durations
+}
+
+// meanAndStdDev returns the mean and sample standard deviation of values
+func meanAndStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// notify sends an "anomaly" notification to channels with onAnomaly set
+func (d *AnomalyDetector) notify(ctx context.Context, automation *Automation, run *AutomationRun, automationConfig *AutomationConfig, anomalies []*RunAnomaly) {
+	var channels []notification.NotificationChannelConfig
+	for _, channel := range automationConfig.Notifications {
+		if !channel.OnAnomaly {
+			continue
+		}
+		channels = append(channels, notification.NotificationChannelConfig{
+			ID:        channel.ID,
+			Type:      channel.Type,
+			OnAnomaly: channel.OnAnomaly,
+			Config:    channel.Config,
+		})
+	}
+	if len(channels) == 0 || silencedForRollout(ctx, d.automationRepo, automation.ID, "anomaly") || silencedForMaintenance(ctx, d.automationRepo, run, "anomaly") {
+		return
+	}
+
+	locale := reportLocale(ctx, d.automationRepo, automation, automationConfig)
+	l := i18n.For(locale)
+	descriptions := make([]string, len(anomalies))
+	for i, anomaly := range anomalies {
+		if anomaly.Scope == AnomalyScopeStep {
+			descriptions[i] = l.T("anomaly.step", anomaly.StepName, anomaly.DurationMs, anomaly.Deviation, anomaly.BaselineMeanMs)
+		} else {
+			descriptions[i] = l.T("anomaly.run", anomaly.DurationMs, anomaly.Deviation, anomaly.BaselineMeanMs)
+		}
+	}
+
+	message := notification.NotificationMessage{
+		AutomationID:   automation.ID,
+		AutomationName: automation.Name,
+		ProjectID:      automation.ProjectID,
+		RunID:          run.ID,
+		Status:         "anomaly",
+		StartTime:      run.StartTime,
+		EndTime:        run.EndTime,
+		ErrorMessage:   strings.Join(descriptions, "\n"),
+		Locale:         locale,
+	}
+
+	if err := d.notificationService.DispatchAutomationNotification(ctx, message, channels); err != nil {
+		slog.Error("Failed to dispatch anomaly notifications", "automation_id", automation.ID, "run_id", run.ID, "error", err)
+	}
+}
@@ -0,0 +1,700 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// Artifact comparison limits
+const (
+	maxArtifactFixtureBytes    = 10 << 20
+	maxArtifactCompareBytes    = 50 << 20 // downloads compared against a fixture
+	maxArtifactComparisons     = 20       // downloads compared per request
+	maxArtifactDiffCells       = 4_000_000
+	maxArtifactDiffLines       = 500 // diff lines reported per comparison
+	maxArtifactJSONDifferences = 200
+	artifactDiffContextLines   = 3
+	maxArtifactDiffValueLength = 200 // of the JSON values in a structural diff
+)
+
+// ArtifactDiff compares a file a run downloaded against the fixture it is expected to match.
+// Text is diffed line by line, JSON structurally and anything else by size and checksum.
+type ArtifactDiff struct {
+	FixtureName string             `json:"fixture_name"`
+	ArtifactURL string             `json:"artifact_url,omitempty"`
+	StepName    string             `json:"step_name,omitempty"`
+	ActionName  string             `json:"action_name,omitempty"`
+	LoopIndex   int                `json:"loop_index"`
+	Mode        string             `json:"mode"` // text, json or binary, as compared
+	Equal       bool               `json:"equal"`
+	Summary     string             `json:"summary"`
+	Expected    ArtifactDigest     `json:"expected"`
+	Actual      *ArtifactDigest    `json:"actual,omitempty"`
+	Lines       []ArtifactDiffLine `json:"lines,omitempty"`       // text mode, changed lines with their context
+	Differences []JSONDifference   `json:"differences,omitempty"` // json mode
+	Truncated   bool               `json:"truncated,omitempty"`   // more lines or differences than reported
+	Error       string             `json:"error,omitempty"`       // the download couldn't be compared
+}
+
+// ArtifactDigest identifies the content of a file
+type ArtifactDigest struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ArtifactDiffLine is a line of a text diff. Unchanged lines only appear as context of changes,
+// so a jump in line numbers separates hunks.
+type ArtifactDiffLine struct {
+	Op           string `json:"op"` // RunDiffEqual, RunDiffRemoved or RunDiffAdded
+	ExpectedLine int    `json:"expected_line,omitempty"`
+	ActualLine   int    `json:"actual_line,omitempty"`
+	Text         string `json:"text"`
+}
+
+// JSONDifference is a value that differs between two JSON documents, with the values as JSON
+type JSONDifference struct {
+	Path     string `json:"path"`
+	Op       string `json:"op"` // RunDiffChanged, RunDiffRemoved or RunDiffAdded
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// DiffArtifact compares the content of a download against a fixture's expected content. The auto
+// mode compares JSON documents structurally, other text line by line and anything else by
+// checksum. JSON that only differs in formatting or key order counts as equal.
+func DiffArtifact(expected, actual []byte, mode, contentType string) *ArtifactDiff {
+	diff := &ArtifactDiff{Expected: digestArtifact(expected)}
+	actualDigest := digestArtifact(actual)
+	diff.Actual = &actualDigest
+
+	diff.Mode = mode
+	if mode == "" || mode == ArtifactCompareAuto {
+		diff.Mode = detectArtifactCompareMode(expected, actual, contentType)
+	}
+
+	if diff.Expected.SHA256 == actualDigest.SHA256 {
+		diff.Equal = true
+		diff.Summary = "identical"
+		return diff
+	}
+
+	switch diff.Mode {
+	case ArtifactCompareJSON:
+		if diffArtifactJSON(diff, expected, actual) {
+			return diff
+		}
+		// Invalid JSON is still worth a line diff
+		diff.Mode = ArtifactCompareText
+		summary := diff.Summary
+		diffArtifactText(diff, expected, actual)
+		diff.Summary = summary + "; " + diff.Summary
+	case ArtifactCompareText:
+		diffArtifactText(diff, expected, actual)
+	default:
+		diffArtifactBinary(diff, expected, actual)
+	}
+	return diff
+}
+
+func digestArtifact(data []byte) ArtifactDigest {
+	sum := sha256.Sum256(data)
+	return ArtifactDigest{Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// detectArtifactCompareMode picks how to compare two files from their content type and content
+func detectArtifactCompareMode(expected, actual []byte, contentType string) string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || looksLikeJSON(expected) {
+		if json.Valid(expected) {
+			return ArtifactCompareJSON
+		}
+	}
+	if isArtifactText(expected) && isArtifactText(actual) {
+		return ArtifactCompareText
+	}
+	return ArtifactCompareBinary
+}
+
+func looksLikeJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// isArtifactText reports whether data is UTF-8 text without the NUL bytes of binary formats
+func isArtifactText(data []byte) bool {
+	sample := data[:min(len(data), 8192)]
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return false
+	}
+	// The sample may end within a multi-byte character
+	for i := 0; i < utf8.UTFMax && len(sample) > 0 && !utf8.Valid(sample); i++ {
+		sample = sample[:len(sample)-1]
+	}
+	return utf8.Valid(sample)
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// diffArtifactText diffs two texts line by line. Line endings and byte order marks are
+// normalized first, and reported when they are all that differs.
+func diffArtifactText(diff *ArtifactDiff, expected, actual []byte) {
+	expectedText := normalizeArtifactText(expected)
+	actualText := normalizeArtifactText(actual)
+	if expectedText == actualText {
+		diff.Summary = "only line endings or a byte order mark differ"
+		return
+	}
+
+	expectedLines := strings.Split(expectedText, "\n")
+	actualLines := strings.Split(actualText, "\n")
+	ops := diffArtifactLines(expectedLines, actualLines)
+
+	// Keep changes and the lines around them
+	keep := make([]bool, len(ops))
+	var removed, added int
+	for i, op := range ops {
+		if op.Op == RunDiffEqual {
+			continue
+		}
+		if op.Op == RunDiffRemoved {
+			removed++
+		} else {
+			added++
+		}
+		for j := max(i-artifactDiffContextLines, 0); j <= min(i+artifactDiffContextLines, len(ops)-1); j++ {
+			keep[j] = true
+		}
+	}
+	for i, op := range ops {
+		if !keep[i] {
+			continue
+		}
+		if len(diff.Lines) >= maxArtifactDiffLines {
+			diff.Truncated = true
+			break
+		}
+		diff.Lines = append(diff.Lines, op)
+	}
+
+	first := ops[slices.IndexFunc(ops, func(op ArtifactDiffLine) bool { return op.Op != RunDiffEqual })]
+	if first.Op == RunDiffAdded {
+		diff.Summary = fmt.Sprintf("%d removed and %d added lines, first difference at line %d of the download",
+			removed, added, first.ActualLine)
+	} else {
+		diff.Summary = fmt.Sprintf("%d removed and %d added lines, first difference at line %d of the fixture",
+			removed, added, first.ExpectedLine)
+	}
+}
+
+func normalizeArtifactText(data []byte) string {
+	text := string(bytes.TrimPrefix(data, utf8BOM))
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// diffArtifactLines aligns two texts on their longest common subsequence of lines. Past
+// maxArtifactDiffCells, the lines between their common start and end are reported as replaced.
+func diffArtifactLines(expected, actual []string) []ArtifactDiffLine {
+	ops := make([]ArtifactDiffLine, 0, max(len(expected), len(actual)))
+	equal := func(i, j int) ArtifactDiffLine {
+		return ArtifactDiffLine{Op: RunDiffEqual, ExpectedLine: i + 1, ActualLine: j + 1, Text: expected[i]}
+	}
+	removed := func(i int) ArtifactDiffLine {
+		return ArtifactDiffLine{Op: RunDiffRemoved, ExpectedLine: i + 1, Text: expected[i]}
+	}
+	added := func(j int) ArtifactDiffLine {
+		return ArtifactDiffLine{Op: RunDiffAdded, ActualLine: j + 1, Text: actual[j]}
+	}
+
+	prefix := 0
+	for prefix < len(expected) && prefix < len(actual) && expected[prefix] == actual[prefix] {
+		ops = append(ops, equal(prefix, prefix))
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(expected)-prefix && suffix < len(actual)-prefix &&
+		expected[len(expected)-1-suffix] == actual[len(actual)-1-suffix] {
+		suffix++
+	}
+	expectedEnd, actualEnd := len(expected)-suffix, len(actual)-suffix
+	rows, cols := expectedEnd-prefix, actualEnd-prefix
+
+	if rows*cols > maxArtifactDiffCells {
+		for i := prefix; i < expectedEnd; i++ {
+			ops = append(ops, removed(i))
+		}
+		for j := prefix; j < actualEnd; j++ {
+			ops = append(ops, added(j))
+		}
+	} else {
+		// lengths[i][j] is the LCS length of the middles from line i and j on
+		lengths := make([][]int, rows+1)
+		for i := range lengths {
+			lengths[i] = make([]int, cols+1)
+		}
+		for i := rows - 1; i >= 0; i-- {
+			for j := cols - 1; j >= 0; j-- {
+				if expected[prefix+i] == actual[prefix+j] {
+					lengths[i][j] = lengths[i+1][j+1] + 1
+				} else {
+					lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < rows && j < cols {
+			switch {
+			case expected[prefix+i] == actual[prefix+j]:
+				ops = append(ops, equal(prefix+i, prefix+j))
+				i++
+				j++
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				ops = append(ops, removed(prefix+i))
+				i++
+			default:
+				ops = append(ops, added(prefix+j))
+				j++
+			}
+		}
+		for ; i < rows; i++ {
+			ops = append(ops, removed(prefix+i))
+		}
+		for ; j < cols; j++ {
+			ops = append(ops, added(prefix+j))
+		}
+	}
+
+	for k := suffix; k > 0; k-- {
+		ops = append(ops, equal(len(expected)-k, len(actual)-k))
+	}
+	return ops
+}
+
+// diffArtifactJSON compares two JSON documents value by value, returning false when either isn't
+// valid JSON
+func diffArtifactJSON(diff *ArtifactDiff, expected, actual []byte) bool {
+	expectedValue, err := decodeArtifactJSON(expected)
+	if err != nil {
+		diff.Summary = fmt.Sprintf("the fixture isn't valid JSON: %s", err)
+		return false
+	}
+	actualValue, err := decodeArtifactJSON(actual)
+	if err != nil {
+		diff.Summary = fmt.Sprintf("the download isn't valid JSON: %s", err)
+		return false
+	}
+
+	var differences []JSONDifference
+	diffJSONValues("$", expectedValue, actualValue, &differences)
+	if len(differences) == 0 {
+		diff.Equal = true
+		diff.Summary = "equivalent JSON, only formatting or key order differ"
+		return true
+	}
+
+	if len(differences) > maxArtifactJSONDifferences {
+		diff.Truncated = true
+		differences = differences[:maxArtifactJSONDifferences]
+	}
+	diff.Differences = differences
+	diff.Summary = fmt.Sprintf("%d values differ, first at %s", len(differences), differences[0].Path)
+	if diff.Truncated {
+		diff.Summary = fmt.Sprintf("more than %d values differ, first at %s", maxArtifactJSONDifferences, differences[0].Path)
+	}
+	return true
+}
+
+func decodeArtifactJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return value, nil
+}
+
+// diffJSONValues collects the differences between two decoded JSON values, stopping once there
+// are more than maxArtifactJSONDifferences. Object members are compared by key, array elements
+// by index.
+func diffJSONValues(path string, expected, actual any, differences *[]JSONDifference) {
+	if len(*differences) > maxArtifactJSONDifferences {
+		return
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]any:
+		actualValue, ok := actual.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(expectedValue)+len(actualValue))
+		for key := range expectedValue {
+			keys = append(keys, key)
+		}
+		for key := range actualValue {
+			if _, shared := expectedValue[key]; !shared {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			memberPath := jsonMemberPath(path, key)
+			expectedMember, inExpected := expectedValue[key]
+			actualMember, inActual := actualValue[key]
+			switch {
+			case !inActual:
+				*differences = append(*differences, JSONDifference{Path: memberPath, Op: RunDiffRemoved, Expected: formatJSONDiffValue(expectedMember)})
+			case !inExpected:
+				*differences = append(*differences, JSONDifference{Path: memberPath, Op: RunDiffAdded, Actual: formatJSONDiffValue(actualMember)})
+			default:
+				diffJSONValues(memberPath, expectedMember, actualMember, differences)
+			}
+		}
+		return
+
+	case []any:
+		actualValue, ok := actual.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(expectedValue), len(actualValue)); i++ {
+			elementPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(actualValue):
+				*differences = append(*differences, JSONDifference{Path: elementPath, Op: RunDiffRemoved, Expected: formatJSONDiffValue(expectedValue[i])})
+			case i >= len(expectedValue):
+				*differences = append(*differences, JSONDifference{Path: elementPath, Op: RunDiffAdded, Actual: formatJSONDiffValue(actualValue[i])})
+			default:
+				diffJSONValues(elementPath, expectedValue[i], actualValue[i], differences)
+			}
+		}
+		return
+
+	case json.Number:
+		// 1.0 and 1 are the same number
+		if actualValue, ok := actual.(json.Number); ok {
+			if expectedValue == actualValue {
+				return
+			}
+			expectedFloat, errExpected := expectedValue.Float64()
+			actualFloat, errActual := actualValue.Float64()
+			if errExpected == nil && errActual == nil && expectedFloat == actualFloat {
+				return
+			}
+		}
+
+	default:
+		// Strings, booleans and null
+		if expected == actual {
+			return
+		}
+	}
+
+	*differences = append(*differences, JSONDifference{
+		Path:     path,
+		Op:       RunDiffChanged,
+		Expected: formatJSONDiffValue(expected),
+		Actual:   formatJSONDiffValue(actual),
+	})
+}
+
+// jsonMemberPath appends an object member to a JSONPath, quoting keys that aren't identifiers
+func jsonMemberPath(path, key string) string {
+	if cssIdentPattern.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// formatJSONDiffValue encodes a value of a JSON difference, shortening long ones
+func formatJSONDiffValue(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if runes := []rune(string(encoded)); len(runes) > maxArtifactDiffValueLength {
+		return string(runes[:maxArtifactDiffValueLength-3]) + "..."
+	}
+	return string(encoded)
+}
+
+// diffArtifactBinary describes how two binary files differ
+func diffArtifactBinary(diff *ArtifactDiff, expected, actual []byte) {
+	offset := 0
+	for offset < len(expected) && offset < len(actual) && expected[offset] == actual[offset] {
+		offset++
+	}
+	if len(expected) != len(actual) {
+		diff.Summary = fmt.Sprintf("expected %d bytes, got %d, first difference at byte %d", len(expected), len(actual), offset)
+		return
+	}
+	diff.Summary = fmt.Sprintf("same size, checksums differ, first difference at byte %d", offset)
+}
+
+// Fixtures
+
+// SaveArtifactFixture stores the expected content of a download, replacing the automation's
+// fixture of the same name. A fixture tied to an action is compared against that action's
+// downloads, otherwise against downloads named like the fixture.
+func (s *automationService) SaveArtifactFixture(ctx context.Context, fixture *ArtifactFixture) (*ArtifactFixture, error) {
+	fixture.Name = strings.TrimSpace(fixture.Name)
+	if fixture.Name == "" || len(fixture.Name) > 255 || strings.ContainsAny(fixture.Name, "/\\") {
+		return nil, fmt.Errorf("%w: fixture names must be 1 to 255 characters without slashes", platform.ErrInvalidRequest)
+	}
+	switch fixture.CompareMode {
+	case "":
+		fixture.CompareMode = ArtifactCompareAuto
+	case ArtifactCompareAuto, ArtifactCompareText, ArtifactCompareJSON, ArtifactCompareBinary:
+	default:
+		return nil, fmt.Errorf("%w: compare mode must be auto, text, json or binary", platform.ErrInvalidRequest)
+	}
+	if len(fixture.Data) > maxArtifactFixtureBytes {
+		return nil, fmt.Errorf("%w: fixtures are limited to %d MB", platform.ErrInvalidRequest, maxArtifactFixtureBytes>>20)
+	}
+	if fixture.CompareMode == ArtifactCompareJSON && !json.Valid(bytes.TrimPrefix(fixture.Data, utf8BOM)) {
+		return nil, fmt.Errorf("%w: the fixture isn't valid JSON", platform.ErrInvalidRequest)
+	}
+
+	if fixture.ActionID != "" {
+		action, err := s.automationRepo.GetActionByID(ctx, fixture.ActionID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: action not found", platform.ErrInvalidRequest)
+		}
+		step, err := s.automationRepo.GetStepByID(ctx, action.StepID)
+		if err != nil || step.AutomationID != fixture.AutomationID {
+			return nil, fmt.Errorf("%w: the action isn't an action of this automation", platform.ErrInvalidRequest)
+		}
+	}
+
+	if fixture.ContentType == "" || fixture.ContentType == "application/octet-stream" {
+		if contentType := contentTypeFromPath(fixture.Name); contentType != "" {
+			fixture.ContentType = contentType
+		} else {
+			fixture.ContentType = http.DetectContentType(fixture.Data)
+		}
+	}
+	digest := digestArtifact(fixture.Data)
+	fixture.Size = digest.Size
+	fixture.SHA256 = digest.SHA256
+	fixture.ID = platform.UtilGenerateUUID()
+
+	if err := s.automationRepo.UpsertArtifactFixture(ctx, fixture); err != nil {
+		slog.Error("Failed to save artifact fixture", "error", err, "automationID", fixture.AutomationID, "name", fixture.Name)
+		return nil, fmt.Errorf("failed to save artifact fixture: %w", err)
+	}
+
+	slog.Info("Artifact fixture saved", "automationID", fixture.AutomationID, "name", fixture.Name, "size", fixture.Size)
+	return fixture, nil
+}
+
+// GetArtifactFixtures lists an automation's fixtures without their content
+func (s *automationService) GetArtifactFixtures(ctx context.Context, automationID string) ([]*ArtifactFixture, error) {
+	fixtures, err := s.automationRepo.GetArtifactFixturesByAutomationID(ctx, automationID, false)
+	if err != nil {
+		slog.Error("Failed to get artifact fixtures", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get artifact fixtures: %w", err)
+	}
+	if fixtures == nil {
+		fixtures = []*ArtifactFixture{}
+	}
+	return fixtures, nil
+}
+
+// GetArtifactFixtureContent returns an automation's fixture with its content
+func (s *automationService) GetArtifactFixtureContent(ctx context.Context, automationID, name string) (*ArtifactFixture, error) {
+	fixture, err := s.automationRepo.GetArtifactFixtureByName(ctx, automationID, name)
+	if err != nil {
+		slog.Error("Failed to get artifact fixture", "error", err, "automationID", automationID, "name", name)
+		return nil, fmt.Errorf("failed to get artifact fixture: %w", err)
+	}
+	if fixture == nil {
+		return nil, fmt.Errorf("%w: artifact fixture '%s' not found", platform.ErrNotFound, name)
+	}
+	return fixture, nil
+}
+
+func (s *automationService) DeleteArtifactFixture(ctx context.Context, automationID, name string) error {
+	if _, err := s.GetArtifactFixtureContent(ctx, automationID, name); err != nil {
+		return err
+	}
+	if err := s.automationRepo.DeleteArtifactFixture(ctx, automationID, name); err != nil {
+		slog.Error("Failed to delete artifact fixture", "error", err, "automationID", automationID, "name", name)
+		return fmt.Errorf("failed to delete artifact fixture: %w", err)
+	}
+
+	slog.Info("Artifact fixture deleted", "automationID", automationID, "name", name)
+	return nil
+}
+
+// CompareRunArtifacts compares the downloads of a run against the automation's fixtures, or
+// only against fixtureName. artifactURL limits the comparison to one output file of the run.
+// A fixture without a matching download is reported with an error, since the run should have
+// downloaded it.
+func (s *automationService) CompareRunArtifacts(ctx context.Context, runID, fixtureName, artifactURL string) ([]*ArtifactDiff, error) {
+	run, err := s.automationRepo.GetRunByID(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run for artifact comparison", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	var fixtures []*ArtifactFixture
+	if fixtureName != "" {
+		fixture, err := s.GetArtifactFixtureContent(ctx, run.AutomationID, fixtureName)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = []*ArtifactFixture{fixture}
+	} else {
+		fixtures, err = s.automationRepo.GetArtifactFixturesByAutomationID(ctx, run.AutomationID, true)
+		if err != nil {
+			slog.Error("Failed to get artifact fixtures", "error", err, "automationID", run.AutomationID)
+			return nil, fmt.Errorf("failed to get artifact fixtures: %w", err)
+		}
+	}
+
+	files := run.OutputFiles()
+	if artifactURL != "" {
+		var selected []OutputFile
+		for _, file := range files {
+			if file.URL == artifactURL {
+				selected = append(selected, file)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("%w: the run has no output file at that URL", platform.ErrInvalidRequest)
+		}
+		files = selected
+
+		// An explicitly chosen file is compared whether or not the fixture would match it
+		if fixtureName == "" {
+			var matching []*ArtifactFixture
+			for _, fixture := range fixtures {
+				if fixtureMatchesArtifact(fixture, files[0]) {
+					matching = append(matching, fixture)
+				}
+			}
+			if len(matching) == 0 {
+				return nil, fmt.Errorf("%w: no fixture matches the file, choose one with fixture", platform.ErrInvalidRequest)
+			}
+			fixtures = matching
+		}
+		return compareArtifacts(ctx, fixtures, files, true), nil
+	}
+
+	return compareArtifacts(ctx, fixtures, files, false), nil
+}
+
+// compareArtifacts compares each fixture against the files matching it, or against every file
+// when all is set
+func compareArtifacts(ctx context.Context, fixtures []*ArtifactFixture, files []OutputFile, all bool) []*ArtifactDiff {
+	diffs := []*ArtifactDiff{}
+	for _, fixture := range fixtures {
+		matched := false
+		for _, file := range files {
+			if !all && !fixtureMatchesArtifact(fixture, file) {
+				continue
+			}
+			matched = true
+			if len(diffs) >= maxArtifactComparisons {
+				break
+			}
+			diffs = append(diffs, compareArtifact(ctx, fixture, file))
+		}
+		if !matched && len(diffs) < maxArtifactComparisons {
+			diffs = append(diffs, &ArtifactDiff{
+				FixtureName: fixture.Name,
+				Mode:        fixture.CompareMode,
+				Expected:    ArtifactDigest{Size: fixture.Size, SHA256: fixture.SHA256},
+				Summary:     "no download",
+				Error:       "the run didn't download a file for this fixture",
+			})
+		}
+	}
+	return diffs
+}
+
+// compareArtifact downloads an output file and diffs it against a fixture
+func compareArtifact(ctx context.Context, fixture *ArtifactFixture, file OutputFile) *ArtifactDiff {
+	data, err := fetchEvidenceArtifact(ctx, file.URL, maxArtifactCompareBytes)
+
+	var diff *ArtifactDiff
+	if err != nil {
+		diff = &ArtifactDiff{
+			Mode:     fixture.CompareMode,
+			Expected: ArtifactDigest{Size: fixture.Size, SHA256: fixture.SHA256},
+			Summary:  "not compared",
+			Error:    err.Error(),
+		}
+	} else {
+		contentType := fixture.ContentType
+		if file.ContentType != "" {
+			contentType = file.ContentType
+		}
+		diff = DiffArtifact(fixture.Data, data, fixture.CompareMode, contentType)
+	}
+
+	diff.FixtureName = fixture.Name
+	diff.ArtifactURL = file.URL
+	diff.StepName = file.StepName
+	diff.ActionName = file.ActionName
+	diff.LoopIndex = file.LoopIndex
+	return diff
+}
+
+// fixtureMatchesArtifact reports whether an output file is a download a fixture describes. Names
+// are compared as downloads are stored, so a fixture named "Report 2025.csv" matches the
+// download stored as Report-2025.csv.
+func fixtureMatchesArtifact(fixture *ArtifactFixture, file OutputFile) bool {
+	if file.Kind != OutputFileKindDownload {
+		return false
+	}
+	if fixture.ActionID != "" {
+		return file.ActionID == fixture.ActionID
+	}
+	return artifactFileName(file) == DownloadFileName(fixture.Name)
+}
+
+// unsafeFileNameChars are replaced in the names of downloaded files
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DownloadFileName is the name a download suggested as name is stored under, with characters
+// unsafe in storage keys replaced
+func DownloadFileName(name string) string {
+	fileName := strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "-"), "-.")
+	if fileName == "" {
+		fileName = "download"
+	}
+	return fileName
+}
+
+// artifactFileName is the file name of an output file
+func artifactFileName(file OutputFile) string {
+	if file.Key != "" {
+		return path.Base(file.Key)
+	}
+	if parsed, err := url.Parse(file.URL); err == nil {
+		return path.Base(parsed.Path)
+	}
+	return ""
+}
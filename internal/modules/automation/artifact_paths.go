@@ -0,0 +1,190 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// DefaultScreenshotPath is used when neither the action nor the automation configures a screenshot path
+const DefaultScreenshotPath = "screenshots/{{runId}}/{{loopIndex}}-{{stepName}}-{{actionName}}-{{timestamp}}.png"
+
+// artifactPathTokens are only known once an artifact is written, so general variable
+// resolution leaves them in place for RenderArtifactPath
+var artifactPathTokens = map[string]bool{
+	"stepName":   true,
+	"stepId":     true,
+	"actionName": true,
+	"actionId":   true,
+	"attempt":    true,
+	"unique":     true,
+}
+
+var (
+	artifactTokenPattern   = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+	unsafePathCharsPattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	repeatedDashPattern    = regexp.MustCompile(`-{2,}`)
+	danglingDashPattern    = regexp.MustCompile(`-?([/.])-?`)
+	artifactSequence       atomic.Uint64
+)
+
+// RenderArtifactPath renders an artifact path template for the action currently executing in runContext.
+// Supported tokens: {{runId}}, {{automationId}}, {{projectId}}, {{stepName}}, {{stepId}}, {{actionName}},
+// {{actionId}}, {{loopIndex}}, {{localLoopIndex}}, {{attempt}}, {{timestamp}} and {{unique}}; any other
+// variable is resolved as usual. When uniqueSuffix is set and the template has no {{unique}} token, a
+// unique suffix is added before the extension so parallel users never overwrite each other's files.
+// Paths are placed under the run's artifact prefix, if the automation names one.
+func RenderArtifactPath(template string, runContext *RunContext, extension string, uniqueSuffix bool) string {
+	varContext := runContext.VariableContext
+	hasUnique := strings.Contains(template, "{{unique}}")
+
+	rendered := artifactTokenPattern.ReplaceAllStringFunc(template, func(match string) string {
+		switch strings.Trim(match, "{}") {
+		case "runId":
+			return varContext.RunID
+		case "automationId":
+			return varContext.AutomationID
+		case "projectId":
+			return varContext.ProjectID
+		case "stepName":
+			return sanitizePathSegment(runContext.StepName)
+		case "stepId":
+			return runContext.StepID
+		case "actionName":
+			return sanitizePathSegment(runContext.ActionName)
+		case "actionId":
+			return runContext.ActionID
+		case "loopIndex":
+			return strconv.Itoa(varContext.LoopIndex)
+		case "localLoopIndex":
+			return strconv.Itoa(varContext.LocalLoopIndex)
+		case "attempt":
+			return strconv.Itoa(max(runContext.Attempt, 1))
+		case "timestamp":
+			now := time.Now()
+			return fmt.Sprintf("%s-%03d", now.Format("20060102-150405"), now.Nanosecond()/int(time.Millisecond))
+		case "unique":
+			return uniqueArtifactID()
+		}
+		return match
+	})
+
+	// Anything left is an ordinary variable such as {{runtime.orderId}}
+	if runContext.Runner != nil && strings.Contains(rendered, "{{") {
+		if resolved, err := runContext.Runner.ResolveVariablesInString(rendered, varContext, runContext.AutomationConfig); err == nil {
+			rendered = resolved
+		}
+	}
+
+	// Empty values (e.g. an unnamed action) would leave dangling separators
+	rendered = repeatedDashPattern.ReplaceAllString(rendered, "-")
+	rendered = danglingDashPattern.ReplaceAllString(rendered, "$1")
+	rendered = path.Clean(strings.TrimPrefix(rendered, "/"))
+	if runContext.ArtifactPrefix != "" {
+		rendered = runContext.ArtifactPrefix + "/" + rendered
+	}
+
+	ext := path.Ext(rendered)
+	if ext == "" && extension != "" {
+		ext = "." + strings.TrimPrefix(extension, ".")
+		rendered += ext
+	}
+
+	if uniqueSuffix && !hasUnique {
+		rendered = strings.TrimSuffix(rendered, ext) + "-" + uniqueArtifactID() + ext
+	}
+
+	return rendered
+}
+
+// uniqueArtifactID combines a process-wide sequence with random bytes, so IDs never repeat
+// within a worker and are vanishingly unlikely to collide across workers
+func uniqueArtifactID() string {
+	sequence := artifactSequence.Add(1)
+	random, err := platform.UtilGenerateRandomString(4)
+	if err != nil {
+		return strconv.FormatUint(sequence, 36)
+	}
+	return fmt.Sprintf("%s%s", strconv.FormatUint(sequence, 36), random)
+}
+
+// sanitizePathSegment turns free text such as step names into a safe single path segment
+func sanitizePathSegment(value string) string {
+	value = unsafePathCharsPattern.ReplaceAllString(strings.TrimSpace(value), "-")
+	return strings.Trim(value, "-.")
+}
+
+// captureScreenshot takes a screenshot and uploads it under the automation's screenshot path template,
+// returning the storage key and the image bytes. The element selector matches, if any, is highlighted.
+func (r *Runner) captureScreenshot(ctx context.Context, runContext *RunContext, selector string) (string, []byte, error) {
+	if runContext.PlaywrightPage == nil {
+		return "", nil, fmt.Errorf("no page available")
+	}
+
+	screenshotBytes, err := runContext.PlaywrightPage.Screenshot()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to take screenshot: %w", err)
+	}
+	if selector != "" {
+		if annotated, err := AnnotateScreenshot(runContext.PlaywrightPage, screenshotBytes, selector, false, 0); err != nil {
+			runContext.Logger.Debug("Screenshot saved without highlight", "selector", selector, "error", err)
+		} else {
+			screenshotBytes = annotated
+		}
+	}
+
+	template := runContext.AutomationConfig.Screenshots.Path
+	if template == "" {
+		template = DefaultScreenshotPath
+	}
+	key := RenderArtifactPath(template, runContext, "png", true)
+
+	if _, err := r.storageService.UploadFile(ctx, key, bytes.NewReader(screenshotBytes), "image/png"); err != nil {
+		return "", nil, fmt.Errorf("failed to upload screenshot: %w", err)
+	}
+
+	return key, screenshotBytes, nil
+}
+
+// sendAutomaticScreenshot captures a screenshot required by the automation's screenshot settings
+// and reports it as an output file; failures are logged since they must not mask the run result.
+// The element of selector, e.g. the target of the action that failed, is highlighted.
+func (r *Runner) sendAutomaticScreenshot(ctx context.Context, runContext *RunContext, reason, selector string) {
+	start := time.Now()
+	key, screenshotBytes, err := r.captureScreenshot(ctx, runContext, selector)
+	if err != nil {
+		runContext.Logger.Warn("Failed to capture automatic screenshot", "reason", reason, "error", err)
+		return
+	}
+
+	select {
+	case runContext.EventCh <- RunEvent{
+		Type:           RunEventTypeOutputFile,
+		Timestamp:      time.Now(),
+		StepID:         runContext.StepID,
+		StepName:       runContext.StepName,
+		ActionID:       runContext.ActionID,
+		ActionName:     runContext.ActionName,
+		ActionType:     "screenshot:" + reason,
+		Message:        fmt.Sprintf("Automatic screenshot on %s", reason),
+		OutputFile:     r.storageService.GetPublicURL(key),
+		OutputFileKind: OutputFileKindScreenshot,
+		StorageKey:     key,
+		ContentType:    "image/png",
+		Size:           int64(len(screenshotBytes)),
+		Duration:       time.Since(start).Milliseconds(),
+		LoopIndex:      runContext.LoopIndex,
+		LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+	}:
+	default:
+		// Channel is full, skip this event to avoid blocking
+	}
+}
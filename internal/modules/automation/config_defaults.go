@@ -0,0 +1,90 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// ApplyConfigDefaults fills in fields missing from an automation config with organization defaults.
+// Fields explicitly present in the config are treated as per-automation overrides and left untouched.
+func ApplyConfigDefaults(configJSON string, defaults ConfigDefaults) (string, error) {
+	config, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := config["browser"]; !ok && defaults.Browser != "" {
+		config["browser"] = defaults.Browser
+	}
+
+	if _, ok := config["timeout"]; !ok && defaults.Timeout > 0 {
+		config["timeout"] = defaults.Timeout
+	}
+
+	if _, ok := config["notifications"]; !ok && len(defaults.Notifications) > 0 {
+		notifications := make([]NotificationChannelConfig, len(defaults.Notifications))
+		for i, channel := range defaults.Notifications {
+			channel.ID = platform.UtilGenerateUUID()
+			notifications[i] = channel
+		}
+		config["notifications"] = notifications
+	}
+
+	applyConfigPolicy(config, defaults)
+
+	return marshalConfigMap(config)
+}
+
+// EnforceConfigPolicy applies organization policies that automations are not allowed to override.
+func EnforceConfigPolicy(configJSON string, defaults ConfigDefaults) (string, error) {
+	config, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", err
+	}
+
+	applyConfigPolicy(config, defaults)
+
+	return marshalConfigMap(config)
+}
+
+// applyConfigPolicy forces policy-controlled settings on a parsed config
+func applyConfigPolicy(config map[string]any, defaults ConfigDefaults) {
+	if !defaults.RequireScreenshotOnError {
+		return
+	}
+
+	screenshots, _ := config["screenshots"].(map[string]any)
+	if screenshots == nil {
+		screenshots = map[string]any{
+			"onSuccess": false,
+			"path":      DefaultScreenshotPath,
+		}
+	}
+	screenshots["enabled"] = true
+	screenshots["onError"] = true
+	config["screenshots"] = screenshots
+}
+
+func parseConfigMap(configJSON string) (map[string]any, error) {
+	config := map[string]any{}
+	if configJSON == "" {
+		return config, nil
+	}
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse automation config: %w", err)
+	}
+	if config == nil {
+		config = map[string]any{}
+	}
+	return config, nil
+}
+
+func marshalConfigMap(config map[string]any) (string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal automation config: %w", err)
+	}
+	return string(data), nil
+}
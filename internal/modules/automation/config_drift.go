@@ -0,0 +1,122 @@
+package automation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxConfigDriftPaths caps the differing paths recorded for a drifted automation
+const maxConfigDriftPaths = 50
+
+// configDriftInterval is how often automations with a snapshot are compared against it
+const configDriftInterval = 15 * time.Minute
+
+// snapshotConfig turns an exported config into the document its snapshot stores. Action IDs are
+// dropped: they change whenever a config is imported, and nested actions without one get a new
+// ID on every export.
+func snapshotConfig(exported *ExportedAutomationConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	stripActionIDs(config)
+	return config, nil
+}
+
+// stripActionIDs removes the id of every action, i.e. every object with an action_type, in value
+func stripActionIDs(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, isAction := v["action_type"]; isAction {
+			delete(v, "id")
+		}
+		for _, child := range v {
+			stripActionIDs(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			stripActionIDs(child)
+		}
+	}
+}
+
+// configHash returns the sha256 of a snapshot document. encoding/json writes object keys in
+// sorted order, so equal documents hash the same.
+func configHash(config map[string]interface{}) (string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// configDriftPaths lists the paths, such as steps[1].actions[0].action_config.selector, at which
+// current differs from snapshot, up to maxConfigDriftPaths
+func configDriftPaths(snapshot, current map[string]interface{}) []string {
+	paths := []string{}
+	return appendConfigDriftPaths(paths, "", snapshot, current)
+}
+
+func appendConfigDriftPaths(paths []string, path string, snapshot, current interface{}) []string {
+	if len(paths) >= maxConfigDriftPaths {
+		return paths
+	}
+
+	switch s := snapshot.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(s)+len(c))
+		for key := range s {
+			keys = append(keys, key)
+		}
+		for key := range c {
+			if _, inSnapshot := s[key]; !inSnapshot {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			paths = appendConfigDriftPaths(paths, child, s[key], c[key])
+		}
+		return paths
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(s), len(c)); i++ {
+			var snapshotItem, currentItem interface{}
+			if i < len(s) {
+				snapshotItem = s[i]
+			}
+			if i < len(c) {
+				currentItem = c[i]
+			}
+			paths = appendConfigDriftPaths(paths, path+"["+strconv.Itoa(i)+"]", snapshotItem, currentItem)
+		}
+		return paths
+	}
+
+	if !reflect.DeepEqual(snapshot, current) {
+		paths = append(paths, path)
+	}
+	return paths
+}
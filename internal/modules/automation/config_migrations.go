@@ -0,0 +1,271 @@
+package automation
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ConfigVersionKey is the key under which an action config records its schema version.
+// Action configs without it are treated as version 0.
+const ConfigVersionKey = "config_version"
+
+// ConfigMigration upgrades a config map in place by one schema version.
+type ConfigMigration func(config map[string]interface{}) error
+
+type versionedMigration struct {
+	toVersion int
+	migrate   ConfigMigration
+}
+
+// Global registries for config migrations, keyed by action type
+var (
+	actionConfigMigrations     = make(map[string][]versionedMigration)
+	automationConfigMigrations []versionedMigration
+)
+
+// RegisterActionConfigMigration registers a migration that upgrades configs of an action type to toVersion.
+// Plugins register these in init() alongside RegisterAction whenever an action's config format changes.
+func RegisterActionConfigMigration(actionType string, toVersion int, migration ConfigMigration) {
+	actionConfigMigrations[actionType] = insertMigration(actionConfigMigrations[actionType], toVersion, migration)
+}
+
+// RegisterAutomationConfigMigration registers a migration that upgrades automation configs to toVersion.
+func RegisterAutomationConfigMigration(toVersion int, migration ConfigMigration) {
+	automationConfigMigrations = insertMigration(automationConfigMigrations, toVersion, migration)
+}
+
+// CurrentActionConfigVersion returns the latest config schema version for an action type.
+func CurrentActionConfigVersion(actionType string) int {
+	return latestVersion(actionConfigMigrations[actionType])
+}
+
+// CurrentAutomationConfigVersion returns the latest automation config schema version.
+func CurrentAutomationConfigVersion() int {
+	return latestVersion(automationConfigMigrations)
+}
+
+// UpgradeActionConfig migrates an action config, and any nested actions it contains,
+// to the latest schema version. It reports whether anything changed.
+func UpgradeActionConfig(actionType string, config map[string]interface{}) (bool, error) {
+	changed, err := applyMigrations(actionConfigMigrations[actionType], config)
+	if err != nil {
+		return false, fmt.Errorf("failed to upgrade config for action '%s': %w", actionType, err)
+	}
+
+	nestedChanged, err := upgradeNestedActions(config)
+	if err != nil {
+		return false, err
+	}
+
+	return changed || nestedChanged, nil
+}
+
+// UpgradeAutomationConfig migrates an automation config to the latest schema version.
+func UpgradeAutomationConfig(config map[string]interface{}) (bool, error) {
+	changed, err := applyMigrations(automationConfigMigrations, config)
+	if err != nil {
+		return false, fmt.Errorf("failed to upgrade automation config: %w", err)
+	}
+	return changed, nil
+}
+
+// StampActionConfig records the current schema version on an action config written in the
+// current format, such as one saved from the editor, and on the nested actions it contains, so
+// migrations don't re-apply to it later. Configs that already record a version are upgraded
+// from it instead. It reports whether anything changed.
+func StampActionConfig(actionType string, config map[string]interface{}) (bool, error) {
+	stamped := stampVersion(config, CurrentActionConfigVersion(actionType))
+	nestedStamped := stampNestedActions(config)
+	upgraded, err := UpgradeActionConfig(actionType, config)
+	if err != nil {
+		return false, err
+	}
+	return stamped || nestedStamped || upgraded, nil
+}
+
+// StampAutomationConfig is StampActionConfig for an automation config.
+func StampAutomationConfig(config map[string]interface{}) (bool, error) {
+	stamped := stampVersion(config, CurrentAutomationConfigVersion())
+	upgraded, err := UpgradeAutomationConfig(config)
+	if err != nil {
+		return false, err
+	}
+	return stamped || upgraded, nil
+}
+
+// RenameConfigField returns a migration that moves a field to a new key.
+// It is a no-op when the old key is absent or the new key is already set.
+func RenameConfigField(from, to string) ConfigMigration {
+	return func(config map[string]interface{}) error {
+		value, ok := config[from]
+		if !ok {
+			return nil
+		}
+		if _, exists := config[to]; !exists {
+			config[to] = value
+		}
+		delete(config, from)
+		return nil
+	}
+}
+
+func insertMigration(migrations []versionedMigration, toVersion int, migration ConfigMigration) []versionedMigration {
+	for _, existing := range migrations {
+		if existing.toVersion == toVersion {
+			panic(fmt.Sprintf("config migration to version %d registered twice", toVersion))
+		}
+	}
+
+	migrations = append(migrations, versionedMigration{toVersion: toVersion, migrate: migration})
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].toVersion < migrations[j].toVersion
+	})
+	return migrations
+}
+
+func latestVersion(migrations []versionedMigration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].toVersion
+}
+
+// applyMigrations runs every migration newer than the config's recorded version and stamps the result
+func applyMigrations(migrations []versionedMigration, config map[string]interface{}) (bool, error) {
+	if len(migrations) == 0 {
+		return false, nil
+	}
+
+	version := configVersion(config)
+	changed := false
+	for _, migration := range migrations {
+		if migration.toVersion <= version {
+			continue
+		}
+		if err := migration.migrate(config); err != nil {
+			return false, fmt.Errorf("migration to version %d failed: %w", migration.toVersion, err)
+		}
+		config[ConfigVersionKey] = migration.toVersion
+		version = migration.toVersion
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// configVersion reads the recorded schema version; JSON numbers decode as float64
+func configVersion(config map[string]interface{}) int {
+	switch v := config[ConfigVersionKey].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// stampVersion records version on a config that doesn't record one yet
+func stampVersion(config map[string]interface{}, version int) bool {
+	if version == 0 {
+		return false
+	}
+	if _, ok := config[ConfigVersionKey]; ok {
+		return false
+	}
+	config[ConfigVersionKey] = version
+	return true
+}
+
+// stampNestedActions records the current schema version on the nested actions of a config
+func stampNestedActions(config map[string]interface{}) bool {
+	changed := false
+	walkNestedActions(config, func(action nestedAction) error {
+		changed = stampVersion(action.Config, CurrentActionConfigVersion(action.ActionType)) || changed
+		changed = stampNestedActions(action.Config) || changed
+		return nil
+	})
+	return changed
+}
+
+// upgradeNestedActions upgrades the nested actions of a config, used by control-flow actions
+// such as if/else and loops
+func upgradeNestedActions(config map[string]interface{}) (bool, error) {
+	changed := false
+	err := walkNestedActions(config, func(action nestedAction) error {
+		nestedChanged, err := UpgradeActionConfig(action.ActionType, action.Config)
+		changed = changed || nestedChanged
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// UpgradeActionConfigJSON is UpgradeActionConfig for a stored JSON string.
+// The original string is returned untouched when no migration applies.
+func UpgradeActionConfigJSON(actionType, configJSON string) (string, bool, error) {
+	config, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", false, err
+	}
+
+	changed, err := UpgradeActionConfig(actionType, config)
+	if err != nil || !changed {
+		return configJSON, false, err
+	}
+
+	upgraded, err := marshalConfigMap(config)
+	if err != nil {
+		return "", false, err
+	}
+	return upgraded, true, nil
+}
+
+// UpgradeAutomationConfigJSON is UpgradeAutomationConfig for a stored JSON string.
+func UpgradeAutomationConfigJSON(configJSON string) (string, bool, error) {
+	config, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", false, err
+	}
+
+	changed, err := UpgradeAutomationConfig(config)
+	if err != nil || !changed {
+		return configJSON, false, err
+	}
+
+	upgraded, err := marshalConfigMap(config)
+	if err != nil {
+		return "", false, err
+	}
+	return upgraded, true, nil
+}
+
+// StampActionConfigJSON is StampActionConfig for a JSON string from the editor or API.
+func StampActionConfigJSON(actionType, configJSON string) (string, error) {
+	config, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", err
+	}
+
+	changed, err := StampActionConfig(actionType, config)
+	if err != nil || !changed {
+		return configJSON, err
+	}
+	return marshalConfigMap(config)
+}
+
+// StampAutomationConfigJSON is StampAutomationConfig for a JSON string from the editor or API.
+func StampAutomationConfigJSON(configJSON string) (string, error) {
+	config, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", err
+	}
+
+	changed, err := StampAutomationConfig(config)
+	if err != nil || !changed {
+		return configJSON, err
+	}
+	return marshalConfigMap(config)
+}
@@ -0,0 +1,370 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// Config rollout statuses
+const (
+	RolloutStatusActive   = "active"
+	RolloutStatusPromoted = "promoted"
+	RolloutStatusAborted  = "aborted"
+)
+
+// Shadow runs compared before a rollout can be promoted, when none are requested, and at most
+const (
+	defaultRolloutShadowRuns = 5
+	maxRolloutShadowRuns     = 100
+)
+
+// StartConfigRollout copies an automation into a candidate to edit the new version in. Until the
+// rollout is promoted or aborted, the next shadowRuns runs of the automation each also run the
+// candidate, whose runs notify no one.
+func (s *automationService) StartConfigRollout(ctx context.Context, automationID string, shadowRuns int, userID string) (*ConfigRollout, error) {
+	if shadowRuns <= 0 {
+		shadowRuns = defaultRolloutShadowRuns
+	}
+	if shadowRuns > maxRolloutShadowRuns {
+		return nil, fmt.Errorf("%w: rollouts compare at most %d shadow runs", platform.ErrInvalidRequest, maxRolloutShadowRuns)
+	}
+
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation for rollout", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	if rollout, err := s.automationRepo.GetActiveConfigRolloutByCandidateID(ctx, automationID); err != nil {
+		return nil, fmt.Errorf("failed to get config rollout: %w", err)
+	} else if rollout != nil {
+		return nil, fmt.Errorf("%w: this automation is the candidate of a rollout, promote or abort it first", platform.ErrInvalidRequest)
+	}
+	if rollout, err := s.automationRepo.GetActiveConfigRollout(ctx, automationID); err != nil {
+		return nil, fmt.Errorf("failed to get config rollout: %w", err)
+	} else if rollout != nil {
+		return nil, fmt.Errorf("%w: a rollout of this automation is already in progress", platform.ErrConflict)
+	}
+
+	exported, err := s.GetFullAutomationConfig(ctx, automationID)
+	if err != nil {
+		return nil, err
+	}
+	exported.Automation.Name = automation.Name + " (candidate)"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	txService := &automationService{automationRepo: NewAutomationRepository(tx), runCache: s.runCache, pool: s.pool}
+	candidate, _, err := txService.ImportAutomation(ctx, automation.ProjectID, automation.ConfigJSON, exported)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout := &ConfigRollout{
+		ID:                    platform.UtilGenerateUUID(),
+		AutomationID:          automationID,
+		CandidateAutomationID: candidate.ID,
+		ShadowRuns:            shadowRuns,
+		Status:                RolloutStatusActive,
+		StartedByUserID:       userID,
+		Comparisons:           []RolloutComparison{},
+	}
+	if err := txService.automationRepo.CreateConfigRollout(ctx, rollout); err != nil {
+		slog.Error("Failed to create config rollout", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("%w: a rollout of this automation was started concurrently", platform.ErrConflict)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit config rollout transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.Info("Config rollout started", "automationID", automationID, "candidateID", candidate.ID, "shadowRuns", shadowRuns)
+	return rollout, nil
+}
+
+// GetConfigRollout returns an automation's active rollout with how its shadow runs compare so
+// far, or its last finished rollout. It is nil when the automation was never rolled out.
+func (s *automationService) GetConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error) {
+	rollout, err := s.automationRepo.GetActiveConfigRollout(ctx, automationID)
+	if err == nil && rollout == nil {
+		rollout, err = s.automationRepo.GetLatestConfigRollout(ctx, automationID)
+	}
+	if err != nil {
+		slog.Error("Failed to get config rollout", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get config rollout: %w", err)
+	}
+	if rollout == nil {
+		return nil, nil
+	}
+
+	if err := s.compareRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// compareRollout loads the run pairs of a rollout. The summary of an active rollout is computed
+// from them, finished rollouts keep the summary recorded when they finished.
+func (s *automationService) compareRollout(ctx context.Context, rollout *ConfigRollout) error {
+	comparisons, err := s.automationRepo.GetRolloutComparisons(ctx, rollout.ID)
+	if err != nil {
+		slog.Error("Failed to get rollout comparisons", "error", err, "rolloutID", rollout.ID)
+		return fmt.Errorf("failed to get rollout comparisons: %w", err)
+	}
+	if comparisons == nil {
+		comparisons = []RolloutComparison{}
+	}
+	rollout.Comparisons = comparisons
+
+	if rollout.Status != RolloutStatusActive {
+		return nil
+	}
+	summary := RolloutSummary{}
+	for i := range comparisons {
+		comparison := &comparisons[i]
+		comparison.StableRun.DurationMs = rolloutRunDuration(comparison.StableRun)
+		comparison.ShadowRun.DurationMs = rolloutRunDuration(comparison.ShadowRun)
+		comparison.Finished = isFinishedRunStatus(comparison.StableRun.Status) && isFinishedRunStatus(comparison.ShadowRun.Status)
+		if !comparison.Finished {
+			continue
+		}
+		comparison.Match = comparison.StableRun.Status == comparison.ShadowRun.Status
+		comparison.DurationDeltaMs = comparison.ShadowRun.DurationMs - comparison.StableRun.DurationMs
+
+		summary.Compared++
+		switch {
+		case comparison.Match:
+			summary.Matching++
+		case comparison.StableRun.Status == "completed":
+			summary.Regressions++
+		case comparison.ShadowRun.Status == "completed":
+			summary.Fixes++
+		}
+	}
+	summary.Ready = summary.Compared >= rollout.ShadowRuns && summary.Regressions == 0
+	rollout.Summary = summary
+	return nil
+}
+
+func rolloutRunDuration(run RolloutRun) int64 {
+	if run.StartTime == nil || run.EndTime == nil {
+		return 0
+	}
+	return run.EndTime.Sub(*run.StartTime).Milliseconds()
+}
+
+func isFinishedRunStatus(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
+// PromoteConfigRollout replaces the steps and config of an automation with those of its
+// candidate and deletes the candidate. Unless forced, the rollout must have compared its shadow
+// runs without regressions first.
+func (s *automationService) PromoteConfigRollout(ctx context.Context, automationID string, force bool) (*ConfigRollout, error) {
+	rollout, err := s.activeConfigRollout(ctx, automationID)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.CandidateAutomationID == "" {
+		return nil, fmt.Errorf("%w: the candidate of this rollout was deleted, abort the rollout instead", platform.ErrConflict)
+	}
+	if !rollout.Summary.Ready && !force {
+		if rollout.Summary.Regressions > 0 {
+			return nil, fmt.Errorf("%w: the candidate failed %d runs the stable version completed", platform.ErrConflict, rollout.Summary.Regressions)
+		}
+		return nil, fmt.Errorf("%w: only %d of %d shadow runs were compared", platform.ErrConflict, rollout.Summary.Compared, rollout.ShadowRuns)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	txService := &automationService{automationRepo: NewAutomationRepository(tx), runCache: s.runCache, pool: s.pool}
+	if err := txService.automationRepo.LockAutomation(ctx, automationID); err != nil {
+		slog.Error("Failed to lock automation", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to lock automation: %w", err)
+	}
+	automation, err := txService.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	candidate, err := txService.automationRepo.GetAutomationByID(ctx, rollout.CandidateAutomationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candidate automation: %w", err)
+	}
+	candidateConfig, err := txService.GetFullAutomationConfig(ctx, candidate.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The automation keeps its name, so only the description of the candidate carries over
+	automation.Description = candidate.Description
+	automation.ConfigJSON = candidate.ConfigJSON
+	if err := txService.UpdateAutomation(ctx, automation); err != nil {
+		return nil, err
+	}
+	steps, err := txService.automationRepo.GetStepsByAutomationID(ctx, automationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get steps: %w", err)
+	}
+	for _, step := range steps {
+		if err := txService.automationRepo.DeleteStep(ctx, step.ID); err != nil {
+			slog.Error("Failed to delete step of promoted automation", "error", err, "stepID", step.ID)
+			return nil, fmt.Errorf("failed to replace steps: %w", err)
+		}
+	}
+	if _, err := txService.createImportedSteps(ctx, automationID, candidateConfig.Steps); err != nil {
+		slog.Error("Failed to create steps of promoted automation", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to create steps: %w", err)
+	}
+
+	if err := txService.finishConfigRollout(ctx, rollout, RolloutStatusPromoted); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit config rollout transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.Info("Config rollout promoted", "automationID", automationID, "candidateID", candidate.ID, "forced", !rollout.Summary.Ready)
+	return rollout, nil
+}
+
+// AbortConfigRollout ends a rollout without changing the automation, deleting its candidate
+func (s *automationService) AbortConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error) {
+	rollout, err := s.activeConfigRollout(ctx, automationID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	txService := &automationService{automationRepo: NewAutomationRepository(tx), runCache: s.runCache, pool: s.pool}
+	if err := txService.finishConfigRollout(ctx, rollout, RolloutStatusAborted); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit config rollout transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.Info("Config rollout aborted", "automationID", automationID, "candidateID", rollout.CandidateAutomationID)
+	return rollout, nil
+}
+
+// activeConfigRollout returns an automation's active rollout with its comparisons
+func (s *automationService) activeConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error) {
+	rollout, err := s.automationRepo.GetActiveConfigRollout(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get config rollout", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get config rollout: %w", err)
+	}
+	if rollout == nil {
+		return nil, fmt.Errorf("%w: this automation has no rollout in progress", platform.ErrInvalidRequest)
+	}
+	if err := s.compareRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// finishConfigRollout records the outcome of a rollout and deletes its candidate, along with the
+// shadow runs, so the summary is all that is kept of the comparisons
+func (s *automationService) finishConfigRollout(ctx context.Context, rollout *ConfigRollout, status string) error {
+	rollout.Status = status
+	if err := s.automationRepo.FinishConfigRollout(ctx, rollout); err != nil {
+		slog.Error("Failed to finish config rollout", "error", err, "rolloutID", rollout.ID)
+		return fmt.Errorf("%w: %s", platform.ErrConflict, err)
+	}
+	if rollout.CandidateAutomationID != "" {
+		if err := s.automationRepo.DeleteAutomation(ctx, rollout.CandidateAutomationID); err != nil {
+			slog.Error("Failed to delete rollout candidate", "error", err, "candidateID", rollout.CandidateAutomationID)
+			return fmt.Errorf("failed to delete candidate automation: %w", err)
+		}
+	}
+	rollout.Comparisons = []RolloutComparison{}
+	return nil
+}
+
+// silencedForRollout reports whether alerts about an automation's runs are silenced, since it is
+// the candidate of a rollout: its runs shadow those of the live automation, which does the alerting
+func silencedForRollout(ctx context.Context, automationRepo AutomationRepository, automationID, alert string) bool {
+	rollout, err := automationRepo.GetActiveConfigRolloutByCandidateID(ctx, automationID)
+	if err != nil {
+		slog.Warn("Failed to check for config rollout", "automation_id", automationID, "error", err)
+		return false
+	}
+	if rollout == nil {
+		return false
+	}
+	slog.Debug("Silenced alert for rollout candidate", "automation_id", automationID, "alert", alert)
+	return true
+}
+
+// queueShadowRun creates a run of the candidate of an automation's active rollout next to a run of
+// the automation, until the rollout has as many as it compares. Failing to do so doesn't fail the
+// run of the automation.
+func (s *automationService) queueShadowRun(ctx context.Context, stableRun *AutomationRun) {
+	rollout, err := s.automationRepo.GetActiveConfigRollout(ctx, stableRun.AutomationID)
+	if err != nil {
+		slog.Warn("Failed to get config rollout, skipping shadow run", "error", err, "automationID", stableRun.AutomationID)
+		return
+	}
+	if rollout == nil || rollout.CandidateAutomationID == "" {
+		return
+	}
+	count, err := s.automationRepo.CountRolloutRuns(ctx, rollout.ID)
+	if err != nil {
+		slog.Warn("Failed to count rollout runs, skipping shadow run", "error", err, "rolloutID", rollout.ID)
+		return
+	}
+	if count >= rollout.ShadowRuns {
+		return
+	}
+	if err := checkAutomationEnabled(ctx, s.automationRepo, rollout.CandidateAutomationID); err != nil {
+		slog.Info("Skipping shadow run of a disabled candidate", "rolloutID", rollout.ID, "reason", err)
+		return
+	}
+
+	shadowRun := &AutomationRun{
+		ID:                platform.UtilGenerateUUID(),
+		AutomationID:      rollout.CandidateAutomationID,
+		Status:            stableRun.Status,
+		LogsJSON:          "[]",
+		OutputFilesJSON:   "[]",
+		IncludeTags:       stableRun.IncludeTags,
+		ExcludeTags:       stableRun.ExcludeTags,
+		Locale:            stableRun.Locale,
+		TriggeredByUserID: stableRun.TriggeredByUserID,
+	}
+	nameRun(ctx, s.automationRepo, shadowRun)
+	if err := s.automationRepo.CreateRun(ctx, shadowRun); err != nil {
+		slog.Error("Failed to create shadow run", "error", err, "rolloutID", rollout.ID)
+		return
+	}
+	s.RecordRunConfig(ctx, shadowRun)
+	if err := s.automationRepo.CreateRolloutRun(ctx, rollout.ID, stableRun.ID, shadowRun.ID); err != nil {
+		slog.Error("Failed to record shadow run", "error", err, "rolloutID", rollout.ID, "runID", shadowRun.ID)
+	}
+	if err := s.runCache.SetRunStatus(ctx, shadowRun.ID, shadowRun.Status); err != nil {
+		slog.Warn("Failed to set shadow run status in cache", "run_id", shadowRun.ID, "error", err)
+	}
+
+	slog.Info("Shadow run queued", "runID", shadowRun.ID, "stableRunID", stableRun.ID, "rolloutID", rollout.ID, "shadowRun", count+1)
+}
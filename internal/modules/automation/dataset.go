@@ -0,0 +1,237 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dataset refresh limits
+const (
+	MinDatasetRefreshIntervalMinutes = 5
+	DatasetVersionsKept              = 20 // older versions are deleted after a refresh adds one
+	datasetSourceTimeout             = 30 * time.Second
+	maxDatasetSourceBytes            = 10 << 20
+	maxDatasetRecordErrors           = 5 // validation errors reported per refresh
+)
+
+var datasetFieldTypes = map[string]bool{"string": true, "number": true, "boolean": true, "any": true}
+
+// datasetHTTPClient fetches sources from public addresses only, so dataset URLs can't be used to
+// reach services inside the deployment
+var datasetHTTPClient = newPublicHTTPClient(datasetSourceTimeout)
+
+// ValidateDataset checks the user-editable fields of a dataset
+func ValidateDataset(dataset *Dataset) error {
+	if strings.TrimSpace(dataset.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	sourceURL, err := url.Parse(dataset.SourceURL)
+	if err != nil || (sourceURL.Scheme != "http" && sourceURL.Scheme != "https") || sourceURL.Host == "" {
+		return fmt.Errorf("source_url must be an http or https URL")
+	}
+	if err := checkPublicHost(sourceURL.Hostname()); err != nil {
+		return fmt.Errorf("source_url must point to a public address: %w", err)
+	}
+
+	if dataset.RefreshIntervalMinutes < 0 || (dataset.RefreshIntervalMinutes > 0 && dataset.RefreshIntervalMinutes < MinDatasetRefreshIntervalMinutes) {
+		return fmt.Errorf("refresh_interval_minutes must be 0 (on demand only) or at least %d", MinDatasetRefreshIntervalMinutes)
+	}
+
+	_, err = ParseDatasetSchema(dataset.SchemaJSON)
+	return err
+}
+
+// ParseDatasetSchema parses a dataset's schema; an empty string accepts any records
+func ParseDatasetSchema(schemaJSON string) (DatasetSchema, error) {
+	var schema DatasetSchema
+	if strings.TrimSpace(schemaJSON) == "" {
+		return schema, nil
+	}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return schema, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+
+	for field, fieldType := range schema.Fields {
+		if !datasetFieldTypes[fieldType] {
+			return schema, fmt.Errorf("field '%s' has unknown type '%s'; use string, number, boolean or any", field, fieldType)
+		}
+	}
+	if schema.MinRecords < 0 {
+		return schema, fmt.Errorf("min_records must not be negative")
+	}
+	return schema, nil
+}
+
+// fetchDatasetRecords downloads a dataset source and returns its records once they satisfy schema
+func fetchDatasetRecords(ctx context.Context, sourceURL string, schema DatasetSchema) ([]map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source URL: %w", err)
+	}
+	req.Header.Set("Accept", "application/json, text/csv")
+
+	resp, err := datasetHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("source returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDatasetSourceBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source: %w", err)
+	}
+	if len(body) > maxDatasetSourceBytes {
+		return nil, fmt.Errorf("source is larger than %d MB", maxDatasetSourceBytes>>20)
+	}
+
+	records, err := parseDatasetRecords(body, resp.Header.Get("Content-Type"), schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDatasetRecords(records, schema); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// parseDatasetRecords parses a JSON array of objects, a JSON object holding them in "records",
+// or CSV with a header row. Numbers keep their exact JSON text, so large IDs survive.
+func parseDatasetRecords(body []byte, contentType string, schema DatasetSchema) ([]map[string]interface{}, error) {
+	trimmed := bytes.TrimSpace(body)
+	if strings.Contains(contentType, "csv") || (len(trimmed) > 0 && trimmed[0] != '[' && trimmed[0] != '{') {
+		return parseDatasetCSV(trimmed, schema)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("source is not valid JSON: %w", err)
+	}
+
+	if wrapper, ok := parsed.(map[string]interface{}); ok {
+		parsed = wrapper["records"]
+	}
+	items, ok := parsed.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("source must be a JSON array of records or an object with a 'records' array")
+	}
+
+	records := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record %d is not an object", i)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseDatasetCSV parses CSV with a header row. Cells of number and boolean schema fields are
+// converted so CSV and JSON sources validate alike; everything else stays a string.
+func parseDatasetCSV(body []byte, schema DatasetSchema) ([]map[string]interface{}, error) {
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("source is not valid CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for j, column := range header {
+			cell := row[j]
+			switch schema.Fields[column] {
+			case "number":
+				if _, err := strconv.ParseFloat(cell, 64); err != nil {
+					return nil, fmt.Errorf("record %d: field '%s' is not a number", i, column)
+				}
+				record[column] = json.Number(cell)
+			case "boolean":
+				value, err := strconv.ParseBool(cell)
+				if err != nil {
+					return nil, fmt.Errorf("record %d: field '%s' is not a boolean", i, column)
+				}
+				record[column] = value
+			default:
+				record[column] = cell
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// validateDatasetRecords checks the record count and each record's required fields
+func validateDatasetRecords(records []map[string]interface{}, schema DatasetSchema) error {
+	if len(records) < schema.MinRecords {
+		return fmt.Errorf("source returned %d records, at least %d are required", len(records), schema.MinRecords)
+	}
+
+	var problems []string
+	for i, record := range records {
+		for field, fieldType := range schema.Fields {
+			value, ok := record[field]
+			if !ok || value == nil {
+				problems = append(problems, fmt.Sprintf("record %d: missing field '%s'", i, field))
+				continue
+			}
+
+			valid := true
+			switch fieldType {
+			case "string":
+				_, valid = value.(string)
+			case "number":
+				_, valid = value.(json.Number)
+			case "boolean":
+				_, valid = value.(bool)
+			}
+			if !valid {
+				problems = append(problems, fmt.Sprintf("record %d: field '%s' is not a %s", i, field, fieldType))
+			}
+		}
+		if len(problems) >= maxDatasetRecordErrors {
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("source failed schema validation: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// datasetChecksum identifies a set of records, so refreshes returning the same data add no version
+func datasetChecksum(recordsJSON []byte) string {
+	sum := sha256.Sum256(recordsJSON)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseDatasetRecordsJSON decodes a stored dataset version's records
+func ParseDatasetRecordsJSON(recordsJSON string) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	if err := json.Unmarshal([]byte(recordsJSON), &records); err != nil {
+		return nil, fmt.Errorf("failed to parse dataset records: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,240 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+const (
+	// DefaultConsolePauseTimeout is how long a paused user waits for console commands before resuming
+	DefaultConsolePauseTimeout = 5 * time.Minute
+	// MaxConsolePauseTimeout caps pauses so a forgotten debug session can't hold a worker
+	MaxConsolePauseTimeout = 30 * time.Minute
+	// consoleCommandBuffer is how many commands may wait while one is evaluated
+	consoleCommandBuffer = 10
+	// ConsoleEventType is the type of run progress messages carrying console results and pause state
+	ConsoleEventType = "console"
+)
+
+// ErrConsoleDisabled is returned when console commands are not enabled in this deployment
+var ErrConsoleDisabled = errors.New("debug console is disabled; set DEBUG_CONSOLE_ENABLED=true to enable it")
+
+// consoleCommand is an expression sent to a paused user's page
+type consoleCommand struct {
+	id         string
+	expression string
+}
+
+// pausedUser is a user of a run waiting on its page for console commands
+type pausedUser struct {
+	commands chan consoleCommand
+	resume   chan struct{}
+}
+
+// consoleSessionKey identifies a user of a run
+type consoleSessionKey struct {
+	runID     string
+	loopIndex int
+}
+
+// consoleSessions tracks the users of runs in this worker that are paused for console commands
+type consoleSessions struct {
+	mu     sync.Mutex
+	paused map[consoleSessionKey]*pausedUser
+}
+
+// PauseForConsole holds the current user on its page until it is resumed, timeout elapses or
+// the run is cancelled, evaluating console commands sent to it meanwhile. Results are sent to
+// the run's live view as console messages and recorded in the run's logs.
+func (r *Runner) PauseForConsole(ctx context.Context, runContext *RunContext, timeout time.Duration) error {
+	if !platform.ENV_DEBUG_CONSOLE_ENABLED {
+		return ErrConsoleDisabled
+	}
+	if runContext.PlaywrightPage == nil {
+		return fmt.Errorf("no page to debug")
+	}
+	if timeout <= 0 {
+		timeout = DefaultConsolePauseTimeout
+	}
+	timeout = min(timeout, MaxConsolePauseTimeout)
+
+	varContext := runContext.VariableContext
+	key := consoleSessionKey{runID: varContext.RunID, loopIndex: runContext.LoopIndex}
+	user := &pausedUser{
+		commands: make(chan consoleCommand, consoleCommandBuffer),
+		resume:   make(chan struct{}),
+	}
+
+	r.consoles.mu.Lock()
+	if r.consoles.paused == nil {
+		r.consoles.paused = make(map[consoleSessionKey]*pausedUser)
+	}
+	if _, exists := r.consoles.paused[key]; exists {
+		r.consoles.mu.Unlock()
+		return fmt.Errorf("user %d of this run is already paused", runContext.LoopIndex)
+	}
+	r.consoles.paused[key] = user
+	r.consoles.mu.Unlock()
+
+	// A paused run makes no progress, so stall detection is held off until the pause ends
+	if r.runCache != nil {
+		if err := r.runCache.SetRunPausedUntil(ctx, varContext.RunID, time.Now().Add(timeout)); err != nil {
+			slog.Error("Failed to record run pause", "run_id", varContext.RunID, "error", err)
+		}
+	}
+
+	defer func() {
+		r.consoles.mu.Lock()
+		delete(r.consoles.paused, key)
+		r.consoles.mu.Unlock()
+		if r.runCache != nil {
+			if err := r.runCache.SetRunHeartbeat(context.Background(), varContext.RunID, time.Now()); err != nil {
+				slog.Error("Failed to update run heartbeat", "run_id", varContext.RunID, "error", err)
+			}
+		}
+		r.sendConsoleMessage(varContext, RunProgressMessage{Status: "resumed", Data: map[string]interface{}{"loop_index": runContext.LoopIndex}})
+	}()
+
+	slog.Info("Run paused for console commands", "run_id", varContext.RunID, "loop_index", runContext.LoopIndex, "timeout", timeout)
+	r.sendConsoleMessage(varContext, RunProgressMessage{
+		Status:   "paused",
+		StepName: runContext.StepName,
+		Message:  fmt.Sprintf("User %d paused at step '%s' for up to %s", runContext.LoopIndex, runContext.StepName, timeout),
+		Data: map[string]interface{}{
+			"loop_index": runContext.LoopIndex,
+			"page_url":   runContext.PlaywrightPage.URL(),
+			"expires_at": time.Now().Add(timeout),
+		},
+	})
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		select {
+		case command := <-user.commands:
+			r.evaluateConsoleCommand(ctx, runContext, command)
+		case <-user.resume:
+			return nil
+		case <-deadline.C:
+			slog.Info("Console pause timed out, resuming", "run_id", varContext.RunID, "loop_index", runContext.LoopIndex)
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("automation cancelled")
+		}
+	}
+}
+
+// evaluateConsoleCommand evaluates a command on the page, within the organization's script
+// sandbox, and reports its result
+func (r *Runner) evaluateConsoleCommand(ctx context.Context, runContext *RunContext, command consoleCommand) {
+	startTime := time.Now()
+	result, err := EvaluateScript(ctx, runContext.PlaywrightPage, command.expression, runContext.ScriptSandbox)
+	duration := time.Since(startTime)
+
+	data := map[string]interface{}{
+		"command_id": command.id,
+		"expression": command.expression,
+		"loop_index": runContext.LoopIndex,
+	}
+	message := RunProgressMessage{Status: "result", Duration: duration.Milliseconds(), Data: data}
+	logMessage := fmt.Sprintf("console> %s", command.expression)
+	if err != nil {
+		data["error"] = err.Error()
+		var violation *ScriptViolation
+		if errors.As(err, &violation) {
+			data["violation"] = violation
+		}
+		message.Error = err.Error()
+		logMessage += " failed: " + err.Error()
+	} else {
+		data["result"] = result
+		logMessage += fmt.Sprintf(" = %v", result)
+	}
+	r.sendConsoleMessage(runContext.VariableContext, message)
+
+	// The log also counts as progress, so a run being debugged isn't reported stalled
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- RunEvent{
+			Type:       RunEventTypeLog,
+			Timestamp:  time.Now(),
+			StepName:   runContext.StepName,
+			StepID:     runContext.StepID,
+			ActionID:   runContext.ActionID,
+			ActionName: runContext.ActionName,
+			ActionType: "debug:console",
+			Message:    logMessage,
+			Duration:   duration.Milliseconds(),
+			LoopIndex:  runContext.LoopIndex,
+		}:
+		default:
+		}
+	}
+}
+
+func (r *Runner) sendConsoleMessage(varContext *VariableContext, message RunProgressMessage) {
+	if r.sseManager == nil {
+		return
+	}
+	message.Type = ConsoleEventType
+	message.RunID = varContext.RunID
+	r.sseManager.SendRunProgress(varContext.ProjectID, varContext.AutomationID, varContext.RunID, message)
+}
+
+// SendConsoleCommand queues an expression for a paused user of a run and returns the ID its
+// result will carry
+func (r *Runner) SendConsoleCommand(runID string, loopIndex int, expression string) (string, error) {
+	if !platform.ENV_DEBUG_CONSOLE_ENABLED {
+		return "", ErrConsoleDisabled
+	}
+	r.consoles.mu.Lock()
+	user, ok := r.consoles.paused[consoleSessionKey{runID: runID, loopIndex: loopIndex}]
+	r.consoles.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("user %d of this run is not paused in this worker", loopIndex)
+	}
+
+	command := consoleCommand{id: platform.UtilGenerateUUID(), expression: expression}
+	select {
+	case user.commands <- command:
+		return command.id, nil
+	default:
+		return "", fmt.Errorf("too many console commands are waiting; try again once they finish")
+	}
+}
+
+// ResumeConsole resumes a paused user of a run
+func (r *Runner) ResumeConsole(runID string, loopIndex int) error {
+	r.consoles.mu.Lock()
+	defer r.consoles.mu.Unlock()
+
+	key := consoleSessionKey{runID: runID, loopIndex: loopIndex}
+	user, ok := r.consoles.paused[key]
+	if !ok {
+		return fmt.Errorf("user %d of this run is not paused in this worker", loopIndex)
+	}
+	// Unregistered here so a second resume can't close the channel twice
+	delete(r.consoles.paused, key)
+	close(user.resume)
+	return nil
+}
+
+// PausedConsoleUsers returns the loop indexes of a run's users paused for console commands
+func (r *Runner) PausedConsoleUsers(runID string) []int {
+	r.consoles.mu.Lock()
+	defer r.consoles.mu.Unlock()
+
+	loopIndexes := []int{}
+	for key := range r.consoles.paused {
+		if key.runID == runID {
+			loopIndexes = append(loopIndexes, key.loopIndex)
+		}
+	}
+	return loopIndexes
+}
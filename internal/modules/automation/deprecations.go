@@ -0,0 +1,108 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ConfigWarning is a non-fatal problem found in an action or step config, such as use of a deprecated
+// action or field
+type ConfigWarning struct {
+	StepID      string `json:"step_id,omitempty"`
+	ActionID    string `json:"action_id,omitempty"`
+	ActionType  string `json:"action_type"`
+	Field       string `json:"field,omitempty"`
+	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+type deprecation struct {
+	replacement string
+	message     string
+}
+
+// Global registries for deprecated action types and fields
+var (
+	deprecatedActions = make(map[string]deprecation)
+	deprecatedFields  = make(map[string]map[string]deprecation)
+)
+
+// DeprecateAction marks an action type as deprecated. Deprecated actions keep working but
+// produce warnings at save time, in lint output and during runs.
+func DeprecateAction(actionType, replacement, message string) {
+	deprecatedActions[actionType] = deprecation{replacement: replacement, message: message}
+}
+
+// DeprecateActionField marks a config field of an action type as deprecated.
+func DeprecateActionField(actionType, field, replacement, message string) {
+	if deprecatedFields[actionType] == nil {
+		deprecatedFields[actionType] = make(map[string]deprecation)
+	}
+	deprecatedFields[actionType][field] = deprecation{replacement: replacement, message: message}
+}
+
+// CheckDeprecations returns warnings for deprecated usage in an action config, including nested actions.
+func CheckDeprecations(actionType string, config map[string]interface{}) []ConfigWarning {
+	var warnings []ConfigWarning
+
+	if d, ok := deprecatedActions[actionType]; ok {
+		warnings = append(warnings, ConfigWarning{
+			ActionType:  actionType,
+			Message:     deprecationMessage(fmt.Sprintf("Action type '%s' is deprecated", actionType), d),
+			Replacement: d.replacement,
+		})
+	}
+
+	fields := deprecatedFields[actionType]
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		if _, used := config[field]; used {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+	for _, field := range names {
+		d := fields[field]
+		warnings = append(warnings, ConfigWarning{
+			ActionType:  actionType,
+			Field:       field,
+			Message:     deprecationMessage(fmt.Sprintf("Field '%s' of '%s' is deprecated", field, actionType), d),
+			Replacement: d.replacement,
+		})
+	}
+
+	return append(warnings, checkNestedDeprecations(config)...)
+}
+
+// CheckDeprecationsJSON is CheckDeprecations for a stored JSON config. Unparseable configs yield no warnings.
+func CheckDeprecationsJSON(actionType, configJSON string) []ConfigWarning {
+	config := make(map[string]interface{})
+	if configJSON != "" {
+		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+			return nil
+		}
+	}
+	return CheckDeprecations(actionType, config)
+}
+
+func deprecationMessage(prefix string, d deprecation) string {
+	message := prefix
+	if d.replacement != "" {
+		message += fmt.Sprintf("; use '%s' instead", d.replacement)
+	}
+	if d.message != "" {
+		message += ". " + d.message
+	}
+	return message
+}
+
+// checkNestedDeprecations checks the nested actions of a config
+func checkNestedDeprecations(config map[string]interface{}) []ConfigWarning {
+	var warnings []ConfigWarning
+	walkNestedActions(config, func(action nestedAction) error {
+		warnings = append(warnings, withActionID(CheckDeprecations(action.ActionType, action.Config), action.ID)...)
+		return nil
+	})
+	return warnings
+}
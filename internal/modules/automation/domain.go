@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"log/slog"
+	"net/http"
 
 	"github.com/delordemm1/qplayground/internal/modules/storage"
 	"github.com/playwright-community/playwright-go"
@@ -20,6 +21,9 @@ const (
 	RunEventTypeOutputFile  RunEventType = "output_file"
 	RunEventTypeStep        RunEventType = "step"
 	RunEventTypeStepSummary RunEventType = "step_summary"
+	RunEventTypeWarning     RunEventType = "warning"
+	RunEventTypeAction      RunEventType = "action"   // an action starting, recorded for replays
+	RunEventTypeVariable    RunEventType = "variable" // variables an action changed, recorded for replays
 )
 
 // RunEvent represents an event emitted during automation execution
@@ -35,12 +39,45 @@ type RunEvent struct {
 	Message        string                 `json:"message,omitempty"`
 	Error          string                 `json:"error,omitempty"`
 	OutputFile     string                 `json:"output_file,omitempty"`
+	OutputFileKind OutputFileKind         `json:"output_file_kind,omitempty"` // Inferred from ContentType when empty
+	StorageKey     string                 `json:"storage_key,omitempty"`
+	ContentType    string                 `json:"content_type,omitempty"`
+	Size           int64                  `json:"size,omitempty"`
 	Duration       int64                  `json:"duration_ms,omitempty"`
 	LoopIndex      int                    `json:"loop_index,omitempty"`
 	LocalLoopIndex int                    `json:"local_loop_index,omitempty"`
 	Data           map[string]interface{} `json:"data,omitempty"`
 }
 
+// OutputFileKind categorizes an artifact produced by a run
+type OutputFileKind string
+
+const (
+	OutputFileKindScreenshot OutputFileKind = "screenshot"
+	OutputFileKindVideo      OutputFileKind = "video"
+	OutputFileKindReport     OutputFileKind = "report"
+	OutputFileKindDownload   OutputFileKind = "download"
+	OutputFileKindDataset    OutputFileKind = "dataset"
+	OutputFileKindOther      OutputFileKind = "other"
+)
+
+// OutputFile describes an artifact produced by a run, as stored in OutputFilesJSON
+type OutputFile struct {
+	URL            string         `json:"url"`
+	Key            string         `json:"key,omitempty"`
+	Kind           OutputFileKind `json:"kind"`
+	ContentType    string         `json:"content_type,omitempty"`
+	Size           int64          `json:"size,omitempty"`
+	StepID         string         `json:"step_id,omitempty"`
+	StepName       string         `json:"step_name,omitempty"`
+	ActionID       string         `json:"action_id,omitempty"`
+	ActionName     string         `json:"action_name,omitempty"`
+	ActionType     string         `json:"action_type,omitempty"`
+	LoopIndex      int            `json:"loop_index"`
+	LocalLoopIndex int            `json:"local_loop_index"`
+	CreatedAt      *time.Time     `json:"created_at,omitempty"`
+}
+
 // RunContext holds shared resources and state for a single automation run.
 // This will be passed to each plugin action.
 type RunContext struct {
@@ -49,15 +86,22 @@ type RunContext struct {
 	StorageService    storage.StorageService
 	Logger            *slog.Logger
 	EventCh           chan RunEvent
-	StepName          string            // Current step name for context
-	StepID            string            // Current step ID for context
-	ActionID          string            // Current action ID for context
-	ActionName        string            // Current action name for context
-	ParentActionID    string            // Parent action ID for context
-	LoopIndex         int               // Current loop index for multi-run context
-	Runner            *Runner           // Reference to runner for variable resolution
-	VariableContext   *VariableContext  // Variable context for resolution
-	AutomationConfig  *AutomationConfig // Automation config for variable resolution
+	StepName          string               // Current step name for context
+	StepID            string               // Current step ID for context
+	ActionID          string               // Current action ID for context
+	ActionName        string               // Current action name for context
+	ParentActionID    string               // Parent action ID for context
+	Attempt           int                  // Current attempt of the run, starting at 1 (retries of a failed run count up)
+	LoopIndex         int                  // Current loop index for multi-run context
+	Runner            *Runner              // Reference to runner for variable resolution
+	VariableContext   *VariableContext     // Variable context for resolution
+	AutomationConfig  *AutomationConfig    // Automation config for variable resolution
+	HTTPClient        *http.Client         // Pooled HTTP client shared by API actions across the run
+	KVStore           KVStore              // Encrypted key-value store, nil when not configured
+	Sync              *SyncCoordinator     // Barriers and signals shared by the users of a multirun
+	Timers            map[string]time.Time // Transaction timers started by metric:start_timer, by name
+	ScriptSandbox     *ScriptSandbox       // Organization limits on custom scripts, nil when unrestricted
+	ArtifactPrefix    string               // Rendered naming.artifactPrefix that artifact keys are stored under
 }
 
 // PluginAction defines the interface for any executable action provided by a plugin.
@@ -88,6 +132,22 @@ func GetAction(actionType string) (PluginAction, error) {
 	return factory(), nil
 }
 
+// SaveVariable stores a value a plugin action produced under the action's 'save_as' name, in
+// the scope its 'scope' asks for: the current loop's runtime variables, or global ones
+func SaveVariable(actionConfig map[string]interface{}, runContext *RunContext, value interface{}) (string, error) {
+	saveAs, _ := actionConfig["save_as"].(string)
+	if saveAs == "" {
+		return "", fmt.Errorf("action requires a 'save_as' string in config")
+	}
+
+	if scope, _ := actionConfig["scope"].(string); scope == "global" {
+		runContext.VariableContext.GlobalVars[saveAs] = value
+	} else {
+		runContext.VariableContext.RuntimeVars[saveAs] = value
+	}
+	return saveAs, nil
+}
+
 // VariableContext holds context variables for resolution
 type VariableContext struct {
 	LoopIndex      int
@@ -97,7 +157,10 @@ type VariableContext struct {
 	UserID         string
 	ProjectID      string
 	AutomationID   string
+	Locale         string              // locale message keys resolve in, empty when the run has none
+	FakerSets      map[string][]string // organization faker dictionaries, used as {{faker.custom.<name>}}
 	StaticVars     map[string]string
+	StepVars       []Variable             // variables of the executing step, shadowing the automation's
 	RuntimeVars    map[string]interface{} // Variables set during execution (local to current loop)
 	GlobalVars     map[string]interface{} // Variables set during execution (global across all loops)
 }
@@ -132,17 +195,85 @@ type NotificationChannelConfig struct {
 	Type       string         `json:"type"` // "slack", "email", "webhook"
 	OnComplete bool           `json:"onComplete"`
 	OnError    bool           `json:"onError"`
+	OnAnomaly  bool           `json:"onAnomaly,omitempty"`
 	Config     map[string]any `json:"config"`
 }
 
 // AutomationConfig represents the parsed automation configuration
 type AutomationConfig struct {
-	Variables     []Variable                  `json:"variables"`
-	Multirun      MultiRunConfig              `json:"multirun"`
-	Timeout       int                         `json:"timeout"` // in seconds
-	Retries       int                         `json:"retries"`
-	Screenshots   ScreenshotConfig            `json:"screenshots"`
-	Notifications []NotificationChannelConfig `json:"notifications"`
+	Variables        []Variable                  `json:"variables"`
+	Multirun         MultiRunConfig              `json:"multirun"`
+	Timeout          int                         `json:"timeout"` // in seconds
+	Retries          int                         `json:"retries"`
+	Screenshots      ScreenshotConfig            `json:"screenshots"`
+	Notifications    []NotificationChannelConfig `json:"notifications"`
+	Browser          string                      `json:"browser,omitempty"`       // "chromium" (default), "firefox", "webkit"
+	RetentionDays    int                         `json:"retentionDays,omitempty"` // overrides the organization's run retention when > 0
+	ConfigVersion    int                         `json:"config_version,omitempty"`
+	HTTP             HTTPConfig                  `json:"http"`
+	HostMappings     map[string]string           `json:"hostMappings,omitempty"` // hostname (or "*.domain") -> IP, like /etc/hosts
+	TLS              TLSConfig                   `json:"tls"`
+	AnomalyDetection AnomalyDetectionConfig      `json:"anomalyDetection"`
+	StallDetection   StallDetectionConfig        `json:"stallDetection"`
+	Locales          []string                    `json:"locales,omitempty"`      // users of a run without a locale take turns through these for message keys
+	ReportLocale     string                      `json:"reportLocale,omitempty"` // language of notifications and reports, overrides the organization's
+	PDFReport        bool                        `json:"pdfReport,omitempty"`    // link a PDF report of the run from its notifications
+	LiveEvents       LiveEventsConfig            `json:"liveEvents"`
+	OwnerRouting     string                      `json:"ownerRouting,omitempty"` // "fallback" (default), "always" or "off"
+	Requirements     RunRequirements             `json:"requirements"`           // worker capabilities runs are routed by
+	Concurrency      ConcurrencyConfig           `json:"concurrency"`
+	Naming           NamingConfig                `json:"naming"`
+}
+
+// LiveEventsConfig controls the events streamed to browsers watching a run. While a run reports
+// more events per second than the threshold, per-action events are sampled: they are dropped and
+// counted in periodic "sampled" markers, while errors, warnings and status changes are always sent.
+type LiveEventsConfig struct {
+	DisableSampling   bool `json:"disableSampling,omitempty"`
+	SamplingThreshold int  `json:"samplingThreshold,omitempty"` // events per second, defaults to 50
+}
+
+// StallDetectionConfig controls how runs that stop making progress are handled. A run is
+// stalled when no step or action has reported anything for the threshold; it is notified to
+// channels with onError set, and goes back to running if it reports again.
+type StallDetectionConfig struct {
+	Disabled         bool `json:"disabled,omitempty"`
+	ThresholdSeconds int  `json:"thresholdSeconds,omitempty"` // defaults to 10 minutes
+	CancelStalled    bool `json:"cancelStalled,omitempty"`    // cancel stalled runs instead of waiting for them
+}
+
+// AnomalyDetectionConfig controls flagging of runs and steps that are unusually slow or fast
+// compared to recent completed runs
+type AnomalyDetectionConfig struct {
+	Enabled     bool    `json:"enabled"`
+	Threshold   float64 `json:"threshold,omitempty"`   // standard deviations from the mean, defaults to 3
+	MinSamples  int     `json:"minSamples,omitempty"`  // completed runs required before flagging, defaults to 10
+	HistorySize int     `json:"historySize,omitempty"` // completed runs in the baseline, defaults to 50
+	Notify      bool    `json:"notify,omitempty"`      // notify channels with onAnomaly set
+}
+
+// TLSConfig controls certificate verification for API actions and browser contexts
+type TLSConfig struct {
+	CACertificates     []string `json:"caCertificates,omitempty"`     // PEM-encoded CA certificates trusted in addition to the system roots
+	InsecureSkipVerify bool     `json:"insecureSkipVerify,omitempty"` // accept any certificate, e.g. self-signed staging certs
+}
+
+// HTTPConfig controls the pooled HTTP client shared by all API actions within a run
+type HTTPConfig struct {
+	DisableKeepAlives   bool `json:"disableKeepAlives,omitempty"`
+	DisableHTTP2        bool `json:"disableHttp2,omitempty"`
+	MaxConnsPerHost     int  `json:"maxConnsPerHost,omitempty"`     // 0 means unlimited
+	MaxIdleConnsPerHost int  `json:"maxIdleConnsPerHost,omitempty"` // defaults to 100
+	IdleConnTimeout     int  `json:"idleConnTimeout,omitempty"`     // in milliseconds, defaults to 90s
+}
+
+// ConfigDefaults are organization-level defaults applied to automation configs.
+// Defaults only fill fields an automation leaves unset; policies are enforced on every save.
+type ConfigDefaults struct {
+	Browser                  string
+	Timeout                  int
+	RequireScreenshotOnError bool
+	Notifications            []NotificationChannelConfig
 }
 
 // StepConfig represents the parsed step configuration
@@ -150,8 +281,19 @@ type StepConfig struct {
 	SkipCondition    string  `json:"skip_condition,omitempty"`     // e.g., "loop_index_is_even", "loop_index_is_odd", "loop_index_is_prime", "random"
 	RunOnlyCondition string  `json:"run_only_condition,omitempty"` // alternative to skip_condition
 	Probability      float64 `json:"probability,omitempty"`        // for random condition, defaults to 0.5
+	// ExpectedDurationMs is the step's duration budget; a user taking longer gets a StepSlowActionType warning
+	ExpectedDurationMs int64 `json:"expected_duration_ms,omitempty"`
+	// Outputs and Inputs declare the data the step hands to later steps and needs from earlier ones
+	Outputs []StepOutput `json:"outputs,omitempty"`
+	Inputs  []StepInput  `json:"inputs,omitempty"`
+	// Variables are visible only to the step's actions, including nested ones, and shadow
+	// automation variables of the same key
+	Variables []Variable `json:"variables,omitempty"`
 }
 
+// StepSlowActionType is the action type of warnings for steps that exceeded their expected duration
+const StepSlowActionType = "step:slow"
+
 // Automation represents an automation workflow
 type Automation struct {
 	ID          string
@@ -188,21 +330,534 @@ type AutomationAction struct {
 
 // AutomationRun represents an execution of an automation
 type AutomationRun struct {
+	ID                string
+	AutomationID      string
+	Status            string // pending, queued, running, stalled, completed, failed, cancelled
+	StartTime         *time.Time
+	EndTime           *time.Time
+	LogsJSON          string // JSON string containing execution logs
+	OutputFilesJSON   string // JSON array of OutputFile; older runs hold plain URL strings, see ParseOutputFiles
+	ErrorMessage      string
+	IncludeTags       []string // execution profile: when set, only steps and actions with one of these tags run
+	ExcludeTags       []string // steps and actions with any of these tags are skipped
+	Locale            string   // locale message keys resolve in for every user; empty cycles through the automation's locales
+	ParentRunID       string   // failed run this run automatically retries; empty for first attempts
+	Attempt           int      // 1 for a first attempt, 2 for its first retry...
+	TriggeredByUserID string   // user who triggered the run; empty for automatic retries
+	Sequence          int      // number of the run among its automation's runs, starting at 1
+	DisplayName       string   // rendered from the automation's naming.runName template, empty without one
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// QueuedRun is a pending or queued run with the automation and organization it belongs to, so
+// the scheduler can share capacity fairly between them
+type QueuedRun struct {
+	RunID          string
+	AutomationID   string
+	ProjectID      string
+	OrganizationID string
+	CreatedAt      time.Time
+}
+
+const (
+	// DefaultRunShareTTL is how long a share link stays valid when no TTL is requested
+	DefaultRunShareTTL = 72 * time.Hour
+	// MaxRunShareTTL caps how long a share link may stay valid
+	MaxRunShareTTL = 30 * 24 * time.Hour
+)
+
+// ConfigUpgradeReport summarises a pass over stored configs by UpgradeStoredConfigs
+type ConfigUpgradeReport struct {
+	AutomationsUpgraded int
+	ActionsUpgraded     int
+	Failures            []string
+}
+
+// RunShare represents an expiring, token-protected read-only link to a run
+type RunShare struct {
+	ID              string
+	RunID           string
+	Token           string
+	CreatedByUserID string
+	ExpiresAt       time.Time
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
+
+// IsActive reports whether the share can still be used to view the run
+func (s *RunShare) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RunPin keeps a run from being deleted by retention, e.g. as audit evidence for a release
+type RunPin struct {
+	RunID          string    `json:"run_id"`
+	AutomationID   string    `json:"automation_id"`
+	AutomationName string    `json:"automation_name,omitempty"`
+	RunStatus      string    `json:"run_status"`
+	RunCreatedAt   time.Time `json:"run_created_at"`
+	Label          string    `json:"label"`
+	PinnedByUserID string    `json:"pinned_by_user_id,omitempty"`
+	PinnedAt       time.Time `json:"pinned_at"`
+}
+
+// AutomationEmbed represents a revocable token that exposes an automation's status widget
+type AutomationEmbed struct {
 	ID              string
 	AutomationID    string
-	Status          string // pending, running, completed, failed, cancelled
-	StartTime       *time.Time
-	EndTime         *time.Time
-	LogsJSON        string // JSON string containing execution logs
-	OutputFilesJSON string // JSON string containing file paths/URLs
-	ErrorMessage    string
+	Token           string
+	CreatedByUserID string
+	RevokedAt       *time.Time
 	CreatedAt       time.Time
-	UpdatedAt       time.Time
+}
+
+// Anomaly scopes
+const (
+	AnomalyScopeRun  = "run"
+	AnomalyScopeStep = "step"
+)
+
+// RunAnomaly flags a run, or one of its steps, whose duration deviates from recent history
+type RunAnomaly struct {
+	ID               string    `json:"id"`
+	RunID            string    `json:"run_id"`
+	AutomationID     string    `json:"automation_id"`
+	Scope            string    `json:"scope"`
+	StepID           string    `json:"step_id,omitempty"`
+	StepName         string    `json:"step_name,omitempty"`
+	DurationMs       int64     `json:"duration_ms"`
+	BaselineMeanMs   float64   `json:"baseline_mean_ms"`
+	BaselineStdDevMs float64   `json:"baseline_stddev_ms"`
+	Deviation        float64   `json:"deviation"` // signed, in standard deviations
+	SampleSize       int       `json:"sample_size"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// AutomationStatusSummary is the data rendered by the embeddable status widget
+type AutomationStatusSummary struct {
+	AutomationName string             `json:"automation_name"`
+	LatestRun      *RunStatusSummary  `json:"latest_run,omitempty"`
+	DurationTrend  []RunDurationPoint `json:"duration_trend"`
+	LastFailure    *RunFailureSummary `json:"last_failure,omitempty"`
+	Locale         string             `json:"locale"` // locale the widget's labels are rendered in
+	GeneratedAt    time.Time          `json:"generated_at"`
+}
+
+// RunStatusSummary is a lightweight view of a single run
+type RunStatusSummary struct {
+	RunID      string     `json:"run_id"`
+	Status     string     `json:"status"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	EndTime    *time.Time `json:"end_time,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+}
+
+// RunDurationPoint is one finished run in the duration trend
+type RunDurationPoint struct {
+	RunID      string    `json:"run_id"`
+	Status     string    `json:"status"`
+	EndTime    time.Time `json:"end_time"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// RunFailureSummary describes the most recent failed run
+type RunFailureSummary struct {
+	RunID        string     `json:"run_id"`
+	ErrorMessage string     `json:"error_message"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+}
+
+// RunHistoryQuery selects the window and bucket size of an automation's run history
+type RunHistoryQuery struct {
+	Granularity string         // "day" or "hour"
+	From        time.Time      // start of the first bucket
+	To          time.Time      // end of the last bucket, exclusive
+	Location    *time.Location // timezone buckets are aligned to
+}
+
+// ActionTestRequest selects actions of a step to execute on their own in a throwaway browser,
+// see Scheduler.TestActions. Variables and HostMappings point the actions at the environment
+// to test against.
+type ActionTestRequest struct {
+	StepID         string
+	ActionIDs      []string          // actions to execute in step order; every action of the step when empty
+	Variables      map[string]string // override the automation's variables of the same key
+	HostMappings   map[string]string // replace the automation's host mappings when set
+	Locale         string
+	TimeoutSeconds int
+}
+
+// ActionTestResult is the outcome of an action test. Actions run until the first failure.
+type ActionTestResult struct {
+	Status     string              `json:"status"` // completed or failed
+	DurationMs int64               `json:"duration_ms"`
+	Actions    []ActionTestOutcome `json:"actions"`
+	Logs       []map[string]any    `json:"logs"`
+	Variables  map[string]any      `json:"variables"`            // runtime variables set by the actions
+	Screenshot string              `json:"screenshot,omitempty"` // base64 PNG of the page once the actions ended
+	Error      string              `json:"error,omitempty"`
+}
+
+// ActionTestOutcome is how one action of an action test went
+type ActionTestOutcome struct {
+	ActionID   string `json:"action_id"`
+	ActionName string `json:"action_name,omitempty"`
+	ActionType string `json:"action_type"`
+	Status     string `json:"status"` // completed, failed or skipped
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// RunQuery selects runs of a project, or of one of its automations, a page at a time
+type RunQuery struct {
+	ProjectID    string
+	AutomationID string // empty for the runs of every automation of the project
+	RunID        string // set to check whether a single run matches the filter
+	Filter       *RunFilter
+	Limit        int
+	Offset       int
+}
+
+// RunFilter is a filter of the runs filter DSL, see ParseRunFilter. Runs must meet every
+// condition; the values of a condition are alternatives.
+type RunFilter struct {
+	Statuses           []string
+	ExcludeStatuses    []string
+	Tags               []string // runs that executed steps or actions with one of these tags
+	ExcludeTags        []string
+	Durations          []RunFilterDuration
+	Created            []RunFilterTime
+	FailedSteps        []string // runs where an action of one of these steps failed
+	TriggeredByUserIDs []string
+	TriggeredByEmails  []string
+	TriggeredByRetry   bool     // automatic retries
+	Automations        []string // automation IDs or names
+}
+
+// RunFilterDuration compares the duration of runs with Value
+type RunFilterDuration struct {
+	Op    string // >, >=, < or <=
+	Value time.Duration
+}
+
+// RunFilterTime compares when runs were created with Value
+type RunFilterTime struct {
+	Op    string // >= or <
+	Value time.Time
+}
+
+// RunView is a saved run filter of a project. Views are private to their creator unless shared
+// with the project's members, who can then also subscribe to them.
+type RunView struct {
+	ID              string    `json:"id"`
+	ProjectID       string    `json:"project_id"`
+	Name            string    `json:"name"`
+	Filter          string    `json:"filter"` // in the runs filter DSL, see ParseRunFilter
+	CreatedByUserID string    `json:"created_by_user_id,omitempty"`
+	Shared          bool      `json:"shared"`
+	Subscribed      bool      `json:"subscribed"` // whether the user listing the view is subscribed to it
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// RunViewSubscription subscribes a user to a run view, notifying them by email of finished runs
+// matching its filter
+type RunViewSubscription struct {
+	ViewID    string
+	ViewName  string
+	ProjectID string
+	Filter    string
+	UserID    string
+	Email     string
+}
+
+// RunStatusCount is how many runs with a status were created within a bucket
+type RunStatusCount struct {
+	Bucket time.Time // bucket start as wall-clock time in the query's timezone
+	Status string
+	Count  int
+}
+
+// RunHistoryBucket counts the runs created within one day or hour by status
+type RunHistoryBucket struct {
+	Start       time.Time      `json:"start"`
+	Total       int            `json:"total"`
+	Counts      map[string]int `json:"counts"`
+	SuccessRate *float64       `json:"success_rate"` // completed share of finished runs, null without finished runs
+}
+
+// RunHistory is an automation's run outcomes over time, one bucket per day or hour
+type RunHistory struct {
+	AutomationID string             `json:"automation_id"`
+	Granularity  string             `json:"granularity"`
+	Timezone     string             `json:"timezone"`
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	Total        int                `json:"total"`
+	Buckets      []RunHistoryBucket `json:"buckets"`
+}
+
+// Dataset is a project's list of records, such as a pool of test accounts, kept in sync with
+// an external source. Automations read its current version with data:dataset.
+type Dataset struct {
+	ID                     string     `json:"id"`
+	ProjectID              string     `json:"project_id"`
+	Name                   string     `json:"name"`
+	SourceURL              string     `json:"source_url"`
+	SchemaJSON             string     `json:"schema_json"`              // DatasetSchema the source's records must satisfy
+	RefreshIntervalMinutes int        `json:"refresh_interval_minutes"` // 0 refreshes on demand only
+	CurrentVersion         int        `json:"current_version"`          // 0 until the first successful refresh
+	LastRefreshedAt        *time.Time `json:"last_refreshed_at,omitempty"`
+	LastRefreshError       string     `json:"last_refresh_error,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+}
+
+// DatasetSchema is what a dataset source must return for a refresh to be accepted
+type DatasetSchema struct {
+	Fields     map[string]string `json:"fields,omitempty"`      // required field -> type: string, number, boolean or any
+	MinRecords int               `json:"min_records,omitempty"` // fewer records fail the refresh
+}
+
+// DatasetVersion is one accepted snapshot of a dataset's records
+type DatasetVersion struct {
+	ID          string    `json:"id"`
+	DatasetID   string    `json:"dataset_id"`
+	Version     int       `json:"version"`
+	RecordsJSON string    `json:"records_json,omitempty"` // left empty when listing versions
+	RecordCount int       `json:"record_count"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Pool account states
+const (
+	PoolAccountAvailable = "available"
+	PoolAccountLeased    = "leased"
+	PoolAccountDirty     = "dirty" // state unknown, e.g. its user failed mid-run; reset before it is leased again
+)
+
+// DefaultAccountPoolEnvironment is the environment of pools and leases that don't name one
+const DefaultAccountPoolEnvironment = "default"
+
+// AccountPool is a project's set of test accounts for one environment. Each account is leased
+// to one user of a run at a time, so parallel users never share a login.
+type AccountPool struct {
+	ID              string    `json:"id"`
+	ProjectID       string    `json:"project_id"`
+	Name            string    `json:"name"`
+	Environment     string    `json:"environment"`
+	LeaseTTLSeconds int       `json:"lease_ttl_seconds"` // older leases are treated as abandoned and can be taken over
+	AvailableCount  int       `json:"available_count"`
+	LeasedCount     int       `json:"leased_count"`
+	DirtyCount      int       `json:"dirty_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// PoolAccount is one account of a pool and its lease
+type PoolAccount struct {
+	ID             string                 `json:"id"`
+	PoolID         string                 `json:"pool_id"`
+	Label          string                 `json:"label"`
+	Credentials    map[string]interface{} `json:"credentials"` // exposed to the leasing user, e.g. username and password
+	State          string                 `json:"state"`
+	LeasedByRunID  string                 `json:"leased_by_run_id,omitempty"`
+	LeaseLoopIndex *int                   `json:"lease_loop_index,omitempty"`
+	LeasedAt       *time.Time             `json:"leased_at,omitempty"`
+	LeaseExpiresAt *time.Time             `json:"lease_expires_at,omitempty"`
+	LastUsedAt     *time.Time             `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// TranslationCatalog is a project's UI copy in one locale. Text assertions given a message key
+// compare against the message of the run's locale.
+type TranslationCatalog struct {
+	ID           string            `json:"id"`
+	ProjectID    string            `json:"project_id"`
+	Locale       string            `json:"locale"`
+	Messages     map[string]string `json:"messages,omitempty"` // message key -> text, left empty when listing catalogs
+	MessageCount int               `json:"message_count"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// RunConfig is the exported config of a run's automation when the run was triggered, so evidence
+// of the run shows the config it executed rather than the automation's current one
+type RunConfig struct {
+	RunID      string    `json:"run_id"`
+	ConfigHash string    `json:"config_hash"`
+	ConfigJSON string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ConfigSnapshot is an automation's exported config as last synced with version control. The
+// drift fields record whether the automation has since been edited without being synced again.
+type ConfigSnapshot struct {
+	AutomationID    string                 `json:"automation_id"`
+	AutomationName  string                 `json:"automation_name,omitempty"`
+	Config          map[string]interface{} `json:"-"`
+	ConfigHash      string                 `json:"config_hash"`
+	CommitRef       string                 `json:"commit_ref,omitempty"`
+	SyncedByUserID  string                 `json:"synced_by_user_id,omitempty"`
+	SyncedAt        time.Time              `json:"synced_at"`
+	Drifted         bool                   `json:"drifted"`
+	DriftDetectedAt *time.Time             `json:"drift_detected_at,omitempty"`
+	DriftCheckedAt  *time.Time             `json:"drift_checked_at,omitempty"`
+	DriftPaths      []string               `json:"drift_paths"` // config paths that differ from the snapshot
+}
+
+// StaleAutomation records whether an automation is stale, i.e. hasn't run for its organization's
+// unusedDays or has only failed for its failingDays, and whether it was disabled for it
+type StaleAutomation struct {
+	AutomationID   string     `json:"automation_id"`
+	AutomationName string     `json:"automation_name,omitempty"`
+	Stale          bool       `json:"stale"`
+	Reasons        []string   `json:"reasons"` // "unused" and/or "failing"
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	FailingSince   *time.Time `json:"failing_since,omitempty"` // first failed run since the last successful one
+	DetectedAt     *time.Time `json:"detected_at,omitempty"`
+	CheckedAt      *time.Time `json:"checked_at,omitempty"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"` // runs can't be triggered until it is re-enabled
+	ReenabledAt    *time.Time `json:"reenabled_at,omitempty"`
+}
+
+// AutomationActivity is an automation's run activity with its organization's stale automation
+// settings, as read by the stale automation check
+type AutomationActivity struct {
+	AutomationID   string
+	AutomationName string
+	CreatedAt      time.Time
+	LastRunAt      *time.Time
+	FailingSince   *time.Time
+	Settings       StaleAutomationSettings
+	Staleness      *StaleAutomation // nil when the automation was never checked
+}
+
+// StaleAutomationSettings are an organization's staleAutomations settings
+type StaleAutomationSettings struct {
+	Disabled    bool `json:"disabled,omitempty"`
+	UnusedDays  int  `json:"unusedDays,omitempty"`  // days without a run, defaults to 30
+	FailingDays int  `json:"failingDays,omitempty"` // days of failed runs without a successful one, defaults to 7
+	AutoDisable bool `json:"autoDisable,omitempty"` // disable stale automations until they are re-enabled
+}
+
+// MaintenanceCalendar is an organization's iCal feed of planned maintenance
+type MaintenanceCalendar struct {
+	OrganizationID string
+	URL            string
+	PauseRuns      bool // refuse to start runs during maintenance windows
+}
+
+// MaintenanceWindow is an event of a maintenance calendar. Runs executed during one are annotated
+// with it, and their failures don't alert.
+type MaintenanceWindow struct {
+	ID             string    `json:"id,omitempty"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	UID            string    `json:"uid,omitempty"` // UID of the calendar event
+	Summary        string    `json:"summary"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+}
+
+// Artifact fixture compare modes
+const (
+	ArtifactCompareAuto   = "auto"   // json or text by content, binary otherwise
+	ArtifactCompareText   = "text"   // line diff
+	ArtifactCompareJSON   = "json"   // structural diff of the decoded documents
+	ArtifactCompareBinary = "binary" // size and checksum only
+)
+
+// ArtifactFixture is the expected content of a file an automation downloads. Downloads of
+// ActionID are compared against it, or downloads named Name when it isn't tied to an action.
+type ArtifactFixture struct {
+	ID              string    `json:"id"`
+	AutomationID    string    `json:"automation_id"`
+	Name            string    `json:"name"`
+	ActionID        string    `json:"action_id,omitempty"`
+	CompareMode     string    `json:"compare_mode"`
+	ContentType     string    `json:"content_type"`
+	Data            []byte    `json:"-"`
+	Size            int64     `json:"size"`
+	SHA256          string    `json:"sha256"`
+	CreatedByUserID string    `json:"created_by_user_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ConfigRollout tries a new version of an automation before it goes live. The candidate is a copy
+// of the automation that is edited instead of it; each run of the stable automation also runs the
+// candidate in shadow until ShadowRuns pairs were run, and the candidate replaces the stable
+// version when the rollout is promoted.
+type ConfigRollout struct {
+	ID                    string              `json:"id"`
+	AutomationID          string              `json:"automation_id"`
+	CandidateAutomationID string              `json:"candidate_automation_id,omitempty"` // empty once the candidate is gone
+	ShadowRuns            int                 `json:"shadow_runs"`
+	Status                string              `json:"status"` // active, promoted, aborted
+	StartedByUserID       string              `json:"started_by_user_id,omitempty"`
+	StartedAt             time.Time           `json:"started_at"`
+	FinishedAt            *time.Time          `json:"finished_at,omitempty"`
+	Summary               RolloutSummary      `json:"summary"` // as of when the rollout finished, once it has
+	Comparisons           []RolloutComparison `json:"comparisons"`
+}
+
+// RolloutSummary counts how the shadow runs of a rollout compared with their stable runs
+type RolloutSummary struct {
+	Compared    int  `json:"compared"`    // pairs where both runs finished
+	Matching    int  `json:"matching"`    // pairs that ended with the same status
+	Regressions int  `json:"regressions"` // the candidate failed where the stable version completed
+	Fixes       int  `json:"fixes"`       // the candidate completed where the stable version failed
+	Ready       bool `json:"ready"`       // enough pairs compared without regressions to promote
+}
+
+// RolloutComparison pairs a run of the stable automation with the shadow run of its candidate
+type RolloutComparison struct {
+	StableRun       RolloutRun `json:"stable_run"`
+	ShadowRun       RolloutRun `json:"shadow_run"`
+	Finished        bool       `json:"finished"`
+	Match           bool       `json:"match"`
+	DurationDeltaMs int64      `json:"duration_delta_ms"` // how much longer the shadow run took
+}
+
+// RolloutRun is one side of a rollout comparison
+type RolloutRun struct {
+	ID           string     `json:"id"`
+	Status       string     `json:"status"`
+	StartTime    *time.Time `json:"start_time,omitempty"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+}
+
+// ManagedAutomation is an automation managed by an external ID, as read and written by
+// infrastructure-as-code tools
+type ManagedAutomation struct {
+	ExternalID   string                    `json:"external_id"`
+	AutomationID string                    `json:"automation_id"`
+	ETag         string                    `json:"etag"`
+	Config       *ExportedAutomationConfig `json:"config,omitempty"`
+	Warnings     []ConfigWarning           `json:"warnings,omitempty"`
+}
+
+// ManagedNotificationChannel is a notification channel of a managed automation
+type ManagedNotificationChannel struct {
+	AutomationExternalID string                    `json:"automation_external_id"`
+	ETag                 string                    `json:"etag"`
+	Channel              NotificationChannelConfig `json:"channel"`
+}
+
+// WritePrecondition carries the conditional headers of a write to a managed resource
+type WritePrecondition struct {
+	IfMatch     string // ETag the resource must have, or * for any existing resource
+	IfNoneMatch string // * to only create the resource
 }
 
 // RunProgressMessage represents a progress update for an automation run
 type RunProgressMessage struct {
-	Type        string                 `json:"type"` // "status", "log", "step", "action", "error", "complete", "step_summary"
+	Type        string                 `json:"type"` // "status", "log", "step", "action", "error", "complete", "step_summary", "warning", "console"
 	RunID       string                 `json:"runId"`
 	Status      string                 `json:"status,omitempty"`
 	StepName    string                 `json:"stepName,omitempty"`
@@ -245,6 +900,7 @@ type AutomationRepository interface {
 	// Action CRUD
 	CreateAction(ctx context.Context, action *AutomationAction) error
 	GetActionsByStepID(ctx context.Context, stepID string) ([]*AutomationAction, error)
+	GetActionsByAutomationID(ctx context.Context, automationID string) ([]*AutomationAction, error)
 	UpdateAction(ctx context.Context, action *AutomationAction) error
 	DeleteAction(ctx context.Context, id string) error
 
@@ -253,6 +909,168 @@ type AutomationRepository interface {
 	GetRunByID(ctx context.Context, id string) (*AutomationRun, error)
 	GetRunsByAutomationID(ctx context.Context, automationID string) ([]*AutomationRun, error)
 	UpdateRun(ctx context.Context, run *AutomationRun) error
+	UpdateRunProgress(ctx context.Context, runID, logsJSON, outputFilesJSON string) error
+	SetRunFacets(ctx context.Context, runID string, failedSteps, tags []string) error
+	TransitionRunStatus(ctx context.Context, runID, fromStatus, toStatus string) (bool, error)
+	DeleteExpiredRuns(ctx context.Context) (int64, error)
+	GetQueuedRuns(ctx context.Context, runIDs []string) ([]*QueuedRun, error)
+
+	// Run shares
+	CreateRunShare(ctx context.Context, share *RunShare) error
+	GetRunShareByToken(ctx context.Context, token string) (*RunShare, error)
+	GetRunSharesByRunID(ctx context.Context, runID string) ([]*RunShare, error)
+	RevokeRunShare(ctx context.Context, id string) error
+
+	// Run pins
+	UpsertRunPin(ctx context.Context, pin *RunPin) error
+	DeleteRunPin(ctx context.Context, runID string) error
+	GetRunPin(ctx context.Context, runID string) (*RunPin, error)
+	GetRunPinsByProjectID(ctx context.Context, projectID, label string) ([]*RunPin, error)
+
+	// Automation embeds
+	GetRecentRunsByAutomationID(ctx context.Context, automationID string, limit int) ([]*AutomationRun, error)
+	CreateAutomationEmbed(ctx context.Context, embed *AutomationEmbed) error
+	GetAutomationEmbedByToken(ctx context.Context, token string) (*AutomationEmbed, error)
+	GetAutomationEmbedsByAutomationID(ctx context.Context, automationID string) ([]*AutomationEmbed, error)
+	RevokeAutomationEmbed(ctx context.Context, id string) error
+
+	// Run anomalies
+	GetRecentCompletedRuns(ctx context.Context, automationID, excludeRunID string, limit int) ([]*AutomationRun, error)
+	CreateRunAnomaly(ctx context.Context, anomaly *RunAnomaly) error
+	GetRunAnomaliesByRunID(ctx context.Context, runID string) ([]*RunAnomaly, error)
+	GetRunAnomaliesByAutomationID(ctx context.Context, automationID string, limit int) ([]*RunAnomaly, error)
+
+	// Run history
+	CountRunsByBucket(ctx context.Context, automationID string, query RunHistoryQuery) ([]RunStatusCount, error)
+
+	// Run filters
+	GetRuns(ctx context.Context, query RunQuery) ([]*AutomationRun, error)
+
+	// Run views
+	CreateRunView(ctx context.Context, view *RunView) error
+	UpdateRunView(ctx context.Context, view *RunView) error
+	DeleteRunView(ctx context.Context, id string) error
+	GetRunViewByID(ctx context.Context, id, userID string) (*RunView, error)
+	GetRunViewsByProjectID(ctx context.Context, projectID, userID string) ([]*RunView, error)
+	SubscribeRunView(ctx context.Context, viewID, userID string) error
+	UnsubscribeRunView(ctx context.Context, viewID, userID string) error
+	GetRunViewSubscriptionsByProjectID(ctx context.Context, projectID string) ([]*RunViewSubscription, error)
+	MarkRunViewNotified(ctx context.Context, viewID, userID string) error
+
+	// Datasets
+	CreateDataset(ctx context.Context, dataset *Dataset) error
+	GetDatasetByID(ctx context.Context, id string) (*Dataset, error)
+	GetDatasetByName(ctx context.Context, projectID, name string) (*Dataset, error)
+	GetDatasetsByProjectID(ctx context.Context, projectID string) ([]*Dataset, error)
+	GetDatasetsDueForRefresh(ctx context.Context) ([]*Dataset, error)
+	UpdateDataset(ctx context.Context, dataset *Dataset) error
+	UpdateDatasetRefreshResult(ctx context.Context, id string, currentVersion int, refreshError string) error
+	DeleteDataset(ctx context.Context, id string) error
+	CreateDatasetVersion(ctx context.Context, version *DatasetVersion) error
+	GetDatasetVersion(ctx context.Context, datasetID string, version int) (*DatasetVersion, error)
+	GetDatasetVersions(ctx context.Context, datasetID string) ([]*DatasetVersion, error)
+	DeleteDatasetVersionsBefore(ctx context.Context, datasetID string, version int) error
+
+	// Account pools
+	CreateAccountPool(ctx context.Context, pool *AccountPool) error
+	GetAccountPoolByID(ctx context.Context, id string) (*AccountPool, error)
+	GetAccountPoolByName(ctx context.Context, projectID, environment, name string) (*AccountPool, error)
+	GetAccountPoolsByProjectID(ctx context.Context, projectID string) ([]*AccountPool, error)
+	DeleteAccountPool(ctx context.Context, id string) error
+	CreatePoolAccount(ctx context.Context, account *PoolAccount) error
+	GetPoolAccounts(ctx context.Context, poolID string) ([]*PoolAccount, error)
+	DeletePoolAccount(ctx context.Context, poolID, accountID string) error
+	LeasePoolAccount(ctx context.Context, poolID, runID string, loopIndex int, ttl time.Duration) (*PoolAccount, error)
+	ReleasePoolAccount(ctx context.Context, accountID, runID string, loopIndex int, state string) (bool, error)
+	ReleaseRunPoolAccounts(ctx context.Context, runID string, loopIndex int, state string) (int64, error)
+	ResetPoolAccount(ctx context.Context, poolID, accountID string) error
+
+	// Translation catalogs
+	UpsertTranslationCatalog(ctx context.Context, catalog *TranslationCatalog) error
+	GetTranslationCatalog(ctx context.Context, projectID, locale string) (*TranslationCatalog, error)
+	GetTranslationCatalogsByProjectID(ctx context.Context, projectID string) ([]*TranslationCatalog, error)
+	DeleteTranslationCatalog(ctx context.Context, projectID, locale string) error
+
+	// Report localization
+	GetOrganizationReportLocale(ctx context.Context, projectID string) (string, error)
+
+	// Managed automations
+	CreateAutomationExternalID(ctx context.Context, projectID, externalID, automationID string) error
+	GetAutomationIDByExternalID(ctx context.Context, projectID, externalID string) (string, error)
+	GetAutomationExternalIDs(ctx context.Context, projectID string) ([]*ManagedAutomation, error)
+	LockAutomation(ctx context.Context, id string) error
+
+	// Run replays
+	AppendRunReplayEvents(ctx context.Context, runID string, events []*RunReplayEvent) error
+	GetRunReplayEvents(ctx context.Context, runID string, query RunReplayQuery) ([]*RunReplayEvent, error)
+
+	// Faker dictionaries
+	GetFakerDictionariesByProjectID(ctx context.Context, projectID string) (map[string][]string, error)
+
+	// Script sandbox
+	GetScriptSandboxByProjectID(ctx context.Context, projectID string) (*ScriptSandbox, error)
+
+	// Ownership
+	GetAutomationOwners(ctx context.Context, automationID string) ([]AutomationOwner, error)
+	GetAutomationOwnersByProjectID(ctx context.Context, projectID string) (map[string][]AutomationOwner, error)
+	ReplaceAutomationOwners(ctx context.Context, automationID string, owners []AutomationOwner) error
+	GetOwnerDirectory(ctx context.Context, projectID string) (*OwnerDirectory, error)
+
+	// Config snapshots
+	UpsertConfigSnapshot(ctx context.Context, snapshot *ConfigSnapshot) error
+	GetConfigSnapshot(ctx context.Context, automationID string) (*ConfigSnapshot, error)
+	GetConfigSnapshotsByProjectID(ctx context.Context, projectID string) ([]*ConfigSnapshot, error)
+	GetAllConfigSnapshots(ctx context.Context) ([]*ConfigSnapshot, error)
+	UpdateConfigSnapshotDrift(ctx context.Context, automationID string, driftPaths []string) error
+	DeleteConfigSnapshot(ctx context.Context, automationID string) error
+
+	// Stale automations
+	GetAutomationActivity(ctx context.Context) ([]*AutomationActivity, error)
+	GetAutomationStaleness(ctx context.Context, automationID string) (*StaleAutomation, error)
+	GetStaleAutomationsByProjectID(ctx context.Context, projectID string) ([]*StaleAutomation, error)
+	UpsertAutomationStaleness(ctx context.Context, staleness *StaleAutomation) error
+	ReenableAutomation(ctx context.Context, automationID string) error
+
+	// Maintenance calendars
+	GetMaintenanceCalendars(ctx context.Context) ([]*MaintenanceCalendar, error)
+	GetMaintenanceCalendar(ctx context.Context, orgID string) (*MaintenanceCalendar, error)
+	GetMaintenanceCalendarByAutomationID(ctx context.Context, automationID string) (*MaintenanceCalendar, error)
+	ReplaceMaintenanceWindows(ctx context.Context, orgID string, windows []*MaintenanceWindow) error
+	GetMaintenanceWindowsByAutomationID(ctx context.Context, automationID string, from, to time.Time) ([]*MaintenanceWindow, error)
+	GetMaintenanceWindowsByProjectID(ctx context.Context, projectID string, from time.Time) ([]*MaintenanceWindow, error)
+	SetRunMaintenanceWindow(ctx context.Context, runID string, window *MaintenanceWindow) error
+	GetRunMaintenanceWindow(ctx context.Context, runID string) (*MaintenanceWindow, error)
+
+	// Artifact fixtures
+	UpsertArtifactFixture(ctx context.Context, fixture *ArtifactFixture) error
+	GetArtifactFixturesByAutomationID(ctx context.Context, automationID string, withData bool) ([]*ArtifactFixture, error)
+	GetArtifactFixtureByName(ctx context.Context, automationID, name string) (*ArtifactFixture, error)
+	DeleteArtifactFixture(ctx context.Context, automationID, name string) error
+
+	// Run naming
+	NextRunSequence(ctx context.Context, automationID string) (int, error)
+
+	// Run previews
+	UpsertRunPreview(ctx context.Context, runID, imageURL string) error
+	GetRunPreviewURL(ctx context.Context, runID string) (string, error)
+
+	// Run configs
+	CreateRunConfig(ctx context.Context, config *RunConfig) error
+	GetRunConfig(ctx context.Context, runID string) (*RunConfig, error)
+
+	// Config rollouts
+	CreateConfigRollout(ctx context.Context, rollout *ConfigRollout) error
+	GetActiveConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error)
+	GetActiveConfigRolloutByCandidateID(ctx context.Context, candidateAutomationID string) (*ConfigRollout, error)
+	GetLatestConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error)
+	FinishConfigRollout(ctx context.Context, rollout *ConfigRollout) error
+	CreateRolloutRun(ctx context.Context, rolloutID, stableRunID, shadowRunID string) error
+	CountRolloutRuns(ctx context.Context, rolloutID string) (int, error)
+	GetRolloutComparisons(ctx context.Context, rolloutID string) ([]RolloutComparison, error)
+
+	// Config upgrades
+	GetAllAutomations(ctx context.Context) ([]*Automation, error)
+	GetAllActions(ctx context.Context) ([]*AutomationAction, error)
 
 	// Order management
 	GetStepByID(ctx context.Context, id string) (*AutomationStep, error)
@@ -273,6 +1091,7 @@ type AutomationService interface {
 	GetAutomationsByProject(ctx context.Context, projectID string) ([]*Automation, error)
 	GetAutomationByID(ctx context.Context, id string) (*Automation, error)
 	GetFullAutomationConfig(ctx context.Context, automationID string) (*ExportedAutomationConfig, error)
+	ImportAutomation(ctx context.Context, projectID, configJSON string, imported *ExportedAutomationConfig) (*Automation, []ConfigWarning, error)
 	UpdateAutomation(ctx context.Context, automation *Automation) error
 	DeleteAutomation(ctx context.Context, id string) error
 
@@ -289,10 +1108,138 @@ type AutomationService interface {
 	DeleteAction(ctx context.Context, id string) error
 
 	// Run management
-	TriggerRun(ctx context.Context, automationID string) (*AutomationRun, error)
+	TriggerRun(ctx context.Context, automationID string, tagFilter RunTagFilter, locale, userID string) (*AutomationRun, error)
 	GetRunsByAutomation(ctx context.Context, automationID string) ([]*AutomationRun, error)
 	GetRunByID(ctx context.Context, id string) (*AutomationRun, error)
 
+	// Config upgrades
+	UpgradeStoredConfigs(ctx context.Context) (*ConfigUpgradeReport, error)
+	LintAutomation(ctx context.Context, automationID string) ([]ConfigWarning, error)
+	GetAutomationFlowchart(ctx context.Context, automationID string) (*Flowchart, error)
+
+	// Datasets
+	CreateDataset(ctx context.Context, dataset *Dataset) (*Dataset, error)
+	GetDatasetByID(ctx context.Context, id string) (*Dataset, error)
+	GetDatasetsByProject(ctx context.Context, projectID string) ([]*Dataset, error)
+	UpdateDataset(ctx context.Context, dataset *Dataset) error
+	DeleteDataset(ctx context.Context, id string) error
+	RefreshDataset(ctx context.Context, id string) (*Dataset, error)
+	RefreshDueDatasets(ctx context.Context)
+	GetDatasetVersions(ctx context.Context, datasetID string) ([]*DatasetVersion, error)
+	GetDatasetVersion(ctx context.Context, datasetID string, version int) (*DatasetVersion, error)
+
+	// Account pools
+	CreateAccountPool(ctx context.Context, pool *AccountPool) (*AccountPool, error)
+	GetAccountPoolByID(ctx context.Context, id string) (*AccountPool, error)
+	GetAccountPoolsByProject(ctx context.Context, projectID string) ([]*AccountPool, error)
+	DeleteAccountPool(ctx context.Context, id string) error
+	AddPoolAccounts(ctx context.Context, poolID string, accounts []*PoolAccount) ([]*PoolAccount, error)
+	GetPoolAccounts(ctx context.Context, poolID string) ([]*PoolAccount, error)
+	DeletePoolAccount(ctx context.Context, poolID, accountID string) error
+	ResetPoolAccount(ctx context.Context, poolID, accountID string) error
+
+	// Translation catalogs
+	SaveTranslationCatalog(ctx context.Context, catalog *TranslationCatalog) (*TranslationCatalog, error)
+	GetTranslationCatalog(ctx context.Context, projectID, locale string) (*TranslationCatalog, error)
+	GetTranslationCatalogsByProject(ctx context.Context, projectID string) ([]*TranslationCatalog, error)
+	DeleteTranslationCatalog(ctx context.Context, projectID, locale string) error
+
+	// Managed resources for infrastructure-as-code tools
+	GetManagedAutomations(ctx context.Context, projectID string) ([]*ManagedAutomation, error)
+	GetManagedAutomation(ctx context.Context, projectID, externalID string) (*ManagedAutomation, error)
+	UpsertManagedAutomation(ctx context.Context, projectID, externalID, configJSON string, config *ExportedAutomationConfig, precondition WritePrecondition) (*ManagedAutomation, bool, error)
+	DeleteManagedAutomation(ctx context.Context, projectID, externalID string, precondition WritePrecondition) error
+	GetManagedNotificationChannel(ctx context.Context, projectID, externalID, channelID string) (*ManagedNotificationChannel, error)
+	UpsertManagedNotificationChannel(ctx context.Context, projectID, externalID string, channel NotificationChannelConfig, precondition WritePrecondition) (*ManagedNotificationChannel, bool, error)
+	DeleteManagedNotificationChannel(ctx context.Context, projectID, externalID, channelID string, precondition WritePrecondition) error
+
+	// Config drift
+	RecordConfigSnapshot(ctx context.Context, automationID, commitRef, userID string, committed *ExportedAutomationConfig) (*ConfigSnapshot, error)
+	GetConfigDrift(ctx context.Context, automationID string) (*ConfigSnapshot, error)
+	GetConfigSnapshotsByProject(ctx context.Context, projectID string) ([]*ConfigSnapshot, error)
+	DeleteConfigSnapshot(ctx context.Context, automationID string) error
+	DetectConfigDrift(ctx context.Context)
+
+	// Stale automations
+	GetStaleAutomationsByProject(ctx context.Context, projectID string) ([]*StaleAutomation, error)
+	ReenableAutomation(ctx context.Context, automationID string) error
+	DetectStaleAutomations(ctx context.Context)
+
+	// Maintenance calendars
+	SyncMaintenanceCalendars(ctx context.Context)
+	SyncMaintenanceCalendar(ctx context.Context, orgID string)
+	GetUpcomingMaintenanceWindows(ctx context.Context, projectID string) ([]*MaintenanceWindow, error)
+	GetRunMaintenanceWindow(ctx context.Context, runID string) (*MaintenanceWindow, error)
+
+	// Artifact fixtures
+	SaveArtifactFixture(ctx context.Context, fixture *ArtifactFixture) (*ArtifactFixture, error)
+	GetArtifactFixtures(ctx context.Context, automationID string) ([]*ArtifactFixture, error)
+	GetArtifactFixtureContent(ctx context.Context, automationID, name string) (*ArtifactFixture, error)
+	DeleteArtifactFixture(ctx context.Context, automationID, name string) error
+	CompareRunArtifacts(ctx context.Context, runID, fixtureName, artifactURL string) ([]*ArtifactDiff, error)
+
+	// Config rollouts
+	StartConfigRollout(ctx context.Context, automationID string, shadowRuns int, userID string) (*ConfigRollout, error)
+	GetConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error)
+	PromoteConfigRollout(ctx context.Context, automationID string, force bool) (*ConfigRollout, error)
+	AbortConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error)
+
+	// Ownership
+	GetAutomationOwners(ctx context.Context, automationID string) ([]AutomationOwner, error)
+	SetAutomationOwners(ctx context.Context, automation *Automation, owners []AutomationOwner) ([]AutomationOwner, error)
+	GetOwnerCandidates(ctx context.Context, projectID string) ([]AutomationOwner, error)
+	GetProjectAutomationOwners(ctx context.Context, projectID string) (map[string][]AutomationOwner, error)
+	GetOwnedAutomationIDs(ctx context.Context, projectID, userID string) (map[string]bool, error)
+	RequestOwnerReview(ctx context.Context, automationID, editorID, editorEmail, change string)
+
+	// Run sharing
+	CreateRunShare(ctx context.Context, runID, userID string, ttl time.Duration) (*RunShare, error)
+	GetRunShares(ctx context.Context, runID string) ([]*RunShare, error)
+	RevokeRunShare(ctx context.Context, runID, shareID string) error
+	GetSharedRun(ctx context.Context, token string) (*RunShare, *AutomationRun, *Automation, error)
+
+	// Run pins
+	PinRun(ctx context.Context, runID, label, userID string) (*RunPin, error)
+	UnpinRun(ctx context.Context, runID string) error
+	GetRunPin(ctx context.Context, runID string) (*RunPin, error)
+	GetPinnedRunsByProject(ctx context.Context, projectID, label string) ([]*RunPin, error)
+
+	// Status embeds
+	CreateAutomationEmbed(ctx context.Context, automationID, userID string) (*AutomationEmbed, error)
+	GetAutomationEmbeds(ctx context.Context, automationID string) ([]*AutomationEmbed, error)
+	RevokeAutomationEmbed(ctx context.Context, automationID, embedID string) error
+	GetEmbeddedStatus(ctx context.Context, token string) (*AutomationStatusSummary, error)
+
+	// Run anomalies
+	GetRunAnomalies(ctx context.Context, runID string) ([]*RunAnomaly, error)
+	GetAutomationAnomalies(ctx context.Context, automationID string) ([]*RunAnomaly, error)
+
+	// Run reports
+	GetRunReport(ctx context.Context, runID, format string) ([]byte, error)
+	GetRunEvidence(ctx context.Context, runID, exportedBy string) (*RunEvidence, error)
+	RecordRunConfig(ctx context.Context, run *AutomationRun)
+	GetRunPreviewURL(ctx context.Context, runID string) (string, error)
+	GetWorkerMatches(ctx context.Context, automationID string) (*WorkerRequirements, []WorkerMatch, error)
+
+	// Retry diffs
+	DiffRunAttempts(ctx context.Context, runID, baseRunID string, loopIndex int) (*RunDiff, error)
+	GetRunReplay(ctx context.Context, runID string, query RunReplayQuery) (*RunReplay, error)
+	GetRunReplayState(ctx context.Context, runID string, atMs int64) (*RunReplayState, error)
+
+	// Run history
+	GetRunHistory(ctx context.Context, automationID string, query RunHistoryQuery) (*RunHistory, error)
+
+	// Run filters
+	GetRuns(ctx context.Context, query RunQuery) ([]*AutomationRun, error)
+
+	// Run views
+	CreateRunView(ctx context.Context, view *RunView) (*RunView, error)
+	UpdateRunView(ctx context.Context, view *RunView, userID string) (*RunView, error)
+	DeleteRunView(ctx context.Context, viewID, userID string) error
+	GetRunView(ctx context.Context, viewID, userID string) (*RunView, error)
+	GetRunViews(ctx context.Context, projectID, userID string) ([]*RunView, error)
+	SetRunViewSubscription(ctx context.Context, viewID, userID string, subscribed bool) (*RunView, error)
+
 	// Order management helpers
 	GetMaxStepOrder(ctx context.Context, automationID string) (int, error)
 	GetMaxActionOrder(ctx context.Context, stepID string) (int, error)
@@ -0,0 +1,240 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DraftSelectorPlaceholder prefixes selectors the generator could not know; humans replace them
+// after reviewing the draft
+const DraftSelectorPlaceholder = "TODO:"
+
+// DraftRequest describes the automation a DraftGenerator should produce
+type DraftRequest struct {
+	Description string   // plain-English scenario
+	ActionTypes []string // action types the draft may use
+}
+
+// DraftGenerator turns a plain-English scenario into a draft automation.
+// Drafts are imported like any exported config, so they must use that format.
+type DraftGenerator interface {
+	Name() string
+	GenerateDraft(ctx context.Context, request DraftRequest) (*ExportedAutomationConfig, error)
+}
+
+// DraftBackendConfig configures an LLM backend
+type DraftBackendConfig struct {
+	APIURL string
+	APIKey string
+	Model  string
+}
+
+// DraftBackendFactory creates a DraftGenerator for a backend
+type DraftBackendFactory func(config DraftBackendConfig) (DraftGenerator, error)
+
+// Global registry for draft generator backends
+var draftBackends = make(map[string]DraftBackendFactory)
+
+// RegisterDraftBackend registers an LLM backend for draft generation
+func RegisterDraftBackend(name string, factory DraftBackendFactory) {
+	draftBackends[name] = factory
+}
+
+// NewDraftGenerator creates the generator for a registered backend
+func NewDraftGenerator(backend string, config DraftBackendConfig) (DraftGenerator, error) {
+	factory, exists := draftBackends[backend]
+	if !exists {
+		return nil, fmt.Errorf("unknown draft generator backend: %s", backend)
+	}
+	return factory(config)
+}
+
+func init() {
+	RegisterDraftBackend("openai", newOpenAIDraftGenerator)
+	RegisterDraftBackend("anthropic", newAnthropicDraftGenerator)
+}
+
+// buildDraftPrompt instructs the model to answer with an exported automation config
+func buildDraftPrompt(request DraftRequest) (string, string) {
+	system := fmt.Sprintf(`You convert test scenarios into browser automation drafts for qplayground.
+Respond with a single JSON object and nothing else, in this format:
+{
+  "automation": {"name": string, "description": string, "config": {"variables": [], "timeout": 300}},
+  "steps": [
+    {"name": string, "step_order": number, "actions": [
+      {"name": string, "action_type": string, "action_config": object, "action_order": number}
+    ]}
+  ]
+}
+Rules:
+- Only use these action types: %s.
+- Group related actions into steps named after what the user accomplishes.
+- You cannot see the page, so every CSS selector must be a placeholder of the form "%s <what to select>",
+  e.g. "%s login button".
+- Use "{{faker.email}}"-style variables for realistic test data.`,
+		strings.Join(request.ActionTypes, ", "), DraftSelectorPlaceholder, DraftSelectorPlaceholder)
+
+	return system, "Scenario:\n" + request.Description
+}
+
+// parseDraftResponse extracts the JSON draft from a model reply, tolerating code fences or prose around it
+func parseDraftResponse(reply string) (*ExportedAutomationConfig, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("draft response contained no JSON object")
+	}
+
+	var draft ExportedAutomationConfig
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &draft); err != nil {
+		return nil, fmt.Errorf("failed to parse draft response: %w", err)
+	}
+	if len(draft.Steps) == 0 {
+		return nil, fmt.Errorf("draft response contained no steps")
+	}
+	return &draft, nil
+}
+
+// postDraftJSON sends a JSON request to an LLM API and decodes the JSON reply into out
+func postDraftJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call draft backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("draft backend returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode draft backend response: %w", err)
+	}
+	return nil
+}
+
+// openAIDraftGenerator uses the OpenAI chat completions API, or any API compatible with it
+type openAIDraftGenerator struct {
+	client *http.Client
+	config DraftBackendConfig
+}
+
+func newOpenAIDraftGenerator(config DraftBackendConfig) (DraftGenerator, error) {
+	if config.APIURL == "" {
+		config.APIURL = "https://api.openai.com/v1"
+	}
+	if config.Model == "" {
+		return nil, fmt.Errorf("openai draft backend requires a model")
+	}
+	return &openAIDraftGenerator{client: &http.Client{Timeout: 2 * time.Minute}, config: config}, nil
+}
+
+func (g *openAIDraftGenerator) Name() string {
+	return "openai"
+}
+
+func (g *openAIDraftGenerator) GenerateDraft(ctx context.Context, request DraftRequest) (*ExportedAutomationConfig, error) {
+	system, user := buildDraftPrompt(request)
+
+	headers := map[string]string{}
+	if g.config.APIKey != "" {
+		headers["Authorization"] = "Bearer " + g.config.APIKey
+	}
+
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	err := postDraftJSON(ctx, g.client, strings.TrimSuffix(g.config.APIURL, "/")+"/chat/completions", headers, map[string]any{
+		"model": g.config.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": user},
+		},
+		"response_format": map[string]string{"type": "json_object"},
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("draft backend returned no choices")
+	}
+
+	return parseDraftResponse(response.Choices[0].Message.Content)
+}
+
+// anthropicDraftGenerator uses the Anthropic messages API
+type anthropicDraftGenerator struct {
+	client *http.Client
+	config DraftBackendConfig
+}
+
+func newAnthropicDraftGenerator(config DraftBackendConfig) (DraftGenerator, error) {
+	if config.APIURL == "" {
+		config.APIURL = "https://api.anthropic.com/v1"
+	}
+	if config.APIKey == "" || config.Model == "" {
+		return nil, fmt.Errorf("anthropic draft backend requires an API key and a model")
+	}
+	return &anthropicDraftGenerator{client: &http.Client{Timeout: 2 * time.Minute}, config: config}, nil
+}
+
+func (g *anthropicDraftGenerator) Name() string {
+	return "anthropic"
+}
+
+func (g *anthropicDraftGenerator) GenerateDraft(ctx context.Context, request DraftRequest) (*ExportedAutomationConfig, error) {
+	system, user := buildDraftPrompt(request)
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	err := postDraftJSON(ctx, g.client, strings.TrimSuffix(g.config.APIURL, "/")+"/messages", map[string]string{
+		"x-api-key":         g.config.APIKey,
+		"anthropic-version": "2023-06-01",
+	}, map[string]any{
+		"model":      g.config.Model,
+		"max_tokens": 4096,
+		"system":     system,
+		"messages": []map[string]string{
+			{"role": "user", "content": user},
+		},
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			reply.WriteString(block.Text)
+		}
+	}
+	return parseDraftResponse(reply.String())
+}
@@ -15,12 +15,28 @@ type ExportedAutomation struct {
 
 // ExportedAutomationMeta represents the parsed automation configuration
 type ExportedAutomationMeta struct {
-	Variables     []ExportedVariable                  `json:"variables"`
-	Multirun      ExportedMultiRunConfig              `json:"multirun"`
-	Timeout       int                                 `json:"timeout"` // in seconds
-	Retries       int                                 `json:"retries"`
-	Screenshots   ExportedScreenshotConfig            `json:"screenshots"`
-	Notifications []ExportedNotificationChannelConfig `json:"notifications"`
+	Variables        []ExportedVariable                  `json:"variables"`
+	Multirun         ExportedMultiRunConfig              `json:"multirun"`
+	Timeout          int                                 `json:"timeout"` // in seconds
+	Retries          int                                 `json:"retries"`
+	Screenshots      ExportedScreenshotConfig            `json:"screenshots"`
+	Notifications    []ExportedNotificationChannelConfig `json:"notifications"`
+	Browser          string                              `json:"browser,omitempty"`
+	RetentionDays    int                                 `json:"retentionDays,omitempty"`
+	ConfigVersion    int                                 `json:"config_version,omitempty"`
+	HTTP             HTTPConfig                          `json:"http"`
+	HostMappings     map[string]string                   `json:"hostMappings,omitempty"`
+	TLS              TLSConfig                           `json:"tls"`
+	AnomalyDetection AnomalyDetectionConfig              `json:"anomalyDetection"`
+	StallDetection   StallDetectionConfig                `json:"stallDetection,omitzero"`
+	Locales          []string                            `json:"locales,omitempty"`
+	ReportLocale     string                              `json:"reportLocale,omitempty"`
+	PDFReport        bool                                `json:"pdfReport,omitempty"`
+	LiveEvents       LiveEventsConfig                    `json:"liveEvents,omitzero"`
+	OwnerRouting     string                              `json:"ownerRouting,omitempty"`
+	Requirements     RunRequirements                     `json:"requirements,omitzero"`
+	Concurrency      ConcurrencyConfig                   `json:"concurrency,omitzero"`
+	Naming           NamingConfig                        `json:"naming,omitzero"`
 }
 
 // ExportedVariable represents a configuration variable
@@ -53,6 +69,7 @@ type ExportedNotificationChannelConfig struct {
 	Type       string                 `json:"type"` // "slack", "email", "webhook"
 	OnComplete bool                   `json:"onComplete"`
 	OnError    bool                   `json:"onError"`
+	OnAnomaly  bool                   `json:"onAnomaly,omitempty"`
 	Config     map[string]interface{} `json:"config"`
 }
 
@@ -0,0 +1,256 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Flowchart node kinds
+const (
+	FlowchartNodeStart     = "start"
+	FlowchartNodeEnd       = "end"
+	FlowchartNodeStep      = "step"
+	FlowchartNodeAction    = "action"
+	FlowchartNodeCondition = "condition" // if_else actions and their else-if conditions
+	FlowchartNodeLoop      = "loop"      // loop_until actions
+	FlowchartNodeChoice    = "choice"    // util:random_choice
+)
+
+// Flowchart is a read-only graph of an automation's control flow, from a start node through
+// its steps, actions, branches and loops to an end node
+type Flowchart struct {
+	AutomationID string          `json:"automation_id"`
+	Name         string          `json:"name"`
+	Nodes        []FlowchartNode `json:"nodes"`
+	Edges        []FlowchartEdge `json:"edges"`
+}
+
+// FlowchartNode is a step, action or control-flow point of a flowchart. Actions keep their
+// IDs as node IDs; else-if conditions and nested actions without IDs get generated ones.
+type FlowchartNode struct {
+	ID         string                 `json:"id"`
+	Kind       string                 `json:"kind"`
+	Label      string                 `json:"label"`
+	ActionType string                 `json:"action_type,omitempty"`
+	StepID     string                 `json:"step_id,omitempty"` // the step the node belongs to
+	Depth      int                    `json:"depth"`             // how deeply the node is nested in branches and loops
+	Details    map[string]interface{} `json:"details,omitempty"` // scalar config fields, e.g. selector, condition_type, max_loops
+}
+
+// FlowchartEdge connects two nodes in execution order
+type FlowchartEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Label    string `json:"label,omitempty"`     // e.g. true, false, repeat, done, skipped
+	LoopBack bool   `json:"loop_back,omitempty"` // returns to the start of a loop
+}
+
+// flowchartExit is a loose end of the graph, connected to whatever executes next
+type flowchartExit struct {
+	from  string
+	label string
+}
+
+type flowchartBuilder struct {
+	chart     *Flowchart
+	stepID    string
+	ids       map[string]bool
+	generated int
+}
+
+// BuildFlowchart lays out an automation's steps and actions, including actions nested in
+// conditional branches, loops and random choices, as nodes and edges
+func BuildFlowchart(automation *Automation, steps []*AutomationStep, actionsByStep map[string][]*AutomationAction) *Flowchart {
+	b := &flowchartBuilder{
+		chart: &Flowchart{
+			AutomationID: automation.ID,
+			Name:         automation.Name,
+			Nodes:        []FlowchartNode{},
+			Edges:        []FlowchartEdge{},
+		},
+		ids: map[string]bool{FlowchartNodeStart: true, FlowchartNodeEnd: true},
+	}
+
+	b.chart.Nodes = append(b.chart.Nodes, FlowchartNode{ID: FlowchartNodeStart, Kind: FlowchartNodeStart, Label: automation.Name})
+	exits := []flowchartExit{{from: FlowchartNodeStart}}
+
+	for _, step := range steps {
+		b.stepID = step.ID
+
+		stepConfig := make(map[string]interface{})
+		if step.ConfigJSON != "" {
+			_ = json.Unmarshal([]byte(step.ConfigJSON), &stepConfig)
+		}
+		details := flowchartDetails(stepConfig)
+		if tags := ConfigTags(stepConfig); len(tags) > 0 {
+			if details == nil {
+				details = make(map[string]interface{})
+			}
+			details[TagsConfigKey] = tags
+		}
+
+		stepNodeID := b.addNode(FlowchartNode{ID: step.ID, Kind: FlowchartNodeStep, Label: step.Name, Details: details}, exits)
+		exits = []flowchartExit{{from: stepNodeID}}
+
+		for _, action := range actionsByStep[step.ID] {
+			config := make(map[string]interface{})
+			if action.ActionConfigJSON != "" {
+				_ = json.Unmarshal([]byte(action.ActionConfigJSON), &config)
+			}
+			exits = b.addAction(action.ID, action.ActionType, action.Name, config, exits, 0)
+		}
+
+		// Conditional steps may be skipped entirely
+		skipCondition, _ := stepConfig["skip_condition"].(string)
+		runOnlyCondition, _ := stepConfig["run_only_condition"].(string)
+		if skipCondition != "" || runOnlyCondition != "" {
+			exits = append(exits, flowchartExit{from: stepNodeID, label: "skipped"})
+		}
+	}
+
+	b.chart.Nodes = append(b.chart.Nodes, FlowchartNode{ID: FlowchartNodeEnd, Kind: FlowchartNodeEnd, Label: "End"})
+	b.connect(exits, FlowchartNodeEnd)
+	return b.chart
+}
+
+// addNode adds a node, reached from entries, and returns its ID
+func (b *flowchartBuilder) addNode(node FlowchartNode, entries []flowchartExit) string {
+	// Copied nested actions can share an ID
+	if node.ID == "" || b.ids[node.ID] {
+		node.ID = b.generateID()
+	}
+	b.ids[node.ID] = true
+	node.StepID = b.stepID
+	b.chart.Nodes = append(b.chart.Nodes, node)
+	b.connect(entries, node.ID)
+	return node.ID
+}
+
+func (b *flowchartBuilder) connect(entries []flowchartExit, to string) {
+	for _, entry := range entries {
+		b.chart.Edges = append(b.chart.Edges, FlowchartEdge{From: entry.from, To: to, Label: entry.label})
+	}
+}
+
+func (b *flowchartBuilder) generateID() string {
+	for {
+		b.generated++
+		id := fmt.Sprintf("node-%d", b.generated)
+		if !b.ids[id] {
+			return id
+		}
+	}
+}
+
+// addActions adds a sequence of nested actions ({"id", "action_type", "action_config"}) and
+// returns the exits of its last action, or entries when there are no actions
+func (b *flowchartBuilder) addActions(actions []interface{}, entries []flowchartExit, depth int) []flowchartExit {
+	for _, item := range actions {
+		nested, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := nested["id"].(string)
+		actionType, _ := nested["action_type"].(string)
+		config, _ := nested["action_config"].(map[string]interface{})
+		entries = b.addAction(id, actionType, "", config, entries, depth)
+	}
+	return entries
+}
+
+// addAction adds an action and, for control-flow actions, the actions nested in it, and
+// returns the exits to whatever runs after it
+func (b *flowchartBuilder) addAction(id, actionType, name string, config map[string]interface{}, entries []flowchartExit, depth int) []flowchartExit {
+	label := actionType
+	if name != "" {
+		label = name
+	}
+	node := FlowchartNode{ID: id, Label: label, ActionType: actionType, Depth: depth, Details: flowchartDetails(config)}
+
+	var exits []flowchartExit
+	switch {
+	case config["if_actions"] != nil || config["else_if_conditions"] != nil || config["else_actions"] != nil:
+		node.Kind = FlowchartNodeCondition
+		nodeID := b.addNode(node, entries)
+		exits = b.addBranches(nodeID, config, depth)
+
+	case config["loop_actions"] != nil:
+		node.Kind = FlowchartNodeLoop
+		nodeID := b.addNode(node, entries)
+		body := b.addActions(flowchartList(config["loop_actions"]), []flowchartExit{{from: nodeID, label: "repeat"}}, depth+1)
+		for _, exit := range body {
+			b.chart.Edges = append(b.chart.Edges, FlowchartEdge{From: exit.from, To: nodeID, Label: exit.label, LoopBack: true})
+		}
+		exits = []flowchartExit{{from: nodeID, label: "done"}}
+
+	case config["branches"] != nil:
+		node.Kind = FlowchartNodeChoice
+		nodeID := b.addNode(node, entries)
+		for i, item := range flowchartList(config["branches"]) {
+			branch, _ := item.(map[string]interface{})
+			branchLabel, _ := branch["name"].(string)
+			if branchLabel == "" {
+				branchLabel = fmt.Sprintf("branch %d", i+1)
+			}
+			if weight, ok := branch["weight"].(float64); ok {
+				branchLabel = fmt.Sprintf("%s (weight %g)", branchLabel, weight)
+			}
+			exits = append(exits, b.addActions(flowchartList(branch["actions"]), []flowchartExit{{from: nodeID, label: branchLabel}}, depth+1)...)
+		}
+
+	default:
+		node.Kind = FlowchartNodeAction
+		exits = []flowchartExit{{from: b.addNode(node, entries)}}
+	}
+
+	// final_actions run after an api:if_else whichever way it went; playwright:if_else has none
+	if finalActions := flowchartList(config["final_actions"]); len(finalActions) > 0 && actionType != "playwright:if_else" {
+		exits = b.addActions(finalActions, exits, depth)
+	}
+	return exits
+}
+
+// addBranches adds the if, else-if and else branches of a condition node. Each else-if is a
+// condition node of its own, reached when the previous condition is false.
+func (b *flowchartBuilder) addBranches(conditionID string, config map[string]interface{}, depth int) []flowchartExit {
+	exits := b.addActions(flowchartList(config["if_actions"]), []flowchartExit{{from: conditionID, label: "true"}}, depth+1)
+
+	previous := conditionID
+	for i, item := range flowchartList(config["else_if_conditions"]) {
+		elseIf, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		elseIfID := b.addNode(FlowchartNode{
+			Kind:    FlowchartNodeCondition,
+			Label:   fmt.Sprintf("else if #%d", i+1),
+			Depth:   depth,
+			Details: flowchartDetails(elseIf),
+		}, []flowchartExit{{from: previous, label: "false"}})
+		exits = append(exits, b.addActions(flowchartList(elseIf["actions"]), []flowchartExit{{from: elseIfID, label: "true"}}, depth+1)...)
+		previous = elseIfID
+	}
+
+	return append(exits, b.addActions(flowchartList(config["else_actions"]), []flowchartExit{{from: previous, label: "false"}}, depth+1)...)
+}
+
+// flowchartDetails returns the scalar fields of a config; nested actions and other objects are
+// left to the graph itself
+func flowchartDetails(config map[string]interface{}) map[string]interface{} {
+	var details map[string]interface{}
+	for key, value := range config {
+		switch value.(type) {
+		case string, float64, bool:
+			if details == nil {
+				details = make(map[string]interface{})
+			}
+			details[key] = value
+		}
+	}
+	return details
+}
+
+func flowchartList(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
@@ -0,0 +1,73 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// validateHostMappings checks that every mapping targets a literal IP address
+func validateHostMappings(hostMappings map[string]string) error {
+	for host, ip := range hostMappings {
+		if host == "" {
+			return fmt.Errorf("host mapping has an empty hostname")
+		}
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("host mapping for '%s' must be an IP address, got '%s'", host, ip)
+		}
+	}
+	return nil
+}
+
+// lookupHostMapping returns the mapped IP for a hostname. Exact entries win over
+// wildcard entries such as "*.example.com", which match any subdomain.
+func lookupHostMapping(hostMappings map[string]string, host string) (string, bool) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for pattern, ip := range hostMappings {
+		if strings.ToLower(pattern) == host {
+			return ip, true
+		}
+	}
+	for pattern, ip := range hostMappings {
+		suffix, ok := strings.CutPrefix(strings.ToLower(pattern), "*")
+		if ok && strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) {
+			return ip, true
+		}
+	}
+	return "", false
+}
+
+// hostMappingDialer wraps a dialer so mapped hostnames connect to their configured IP.
+// TLS still verifies against and sends SNI for the original hostname.
+func hostMappingDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error), hostMappings map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil {
+			if ip, ok := lookupHostMapping(hostMappings, host); ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// chromiumHostResolverRules renders host mappings as a Chromium --host-resolver-rules flag
+func chromiumHostResolverRules(hostMappings map[string]string) string {
+	hosts := make([]string, 0, len(hostMappings))
+	for host := range hostMappings {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	rules := make([]string, len(hosts))
+	for i, host := range hosts {
+		ip := hostMappings[host]
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		rules[i] = fmt.Sprintf("MAP %s %s", host, ip)
+	}
+	return "--host-resolver-rules=" + strings.Join(rules, ", ")
+}
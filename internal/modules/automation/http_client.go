@@ -0,0 +1,59 @@
+package automation
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Defaults for the pooled run HTTP client. Go's own default of 2 idle connections per host
+// forces constant reconnects once API actions run concurrently across parallel users.
+const (
+	defaultMaxIdleConnsPerHost = 100
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// newRunHTTPClient builds the pooled HTTP client shared by all API actions of a run.
+// Per-request timeouts are applied by the actions through the request context.
+func newRunHTTPClient(automationConfig *AutomationConfig) (*http.Client, error) {
+	config := automationConfig.HTTP
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if len(automationConfig.HostMappings) > 0 {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		transport.DialContext = hostMappingDialer(dialer.DialContext, automationConfig.HostMappings)
+	}
+
+	tlsConfig, err := buildTLSClientConfig(automationConfig.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	transport.DisableKeepAlives = config.DisableKeepAlives
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	transport.MaxIdleConns = 0 // no global cap; bounded per host instead
+
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = time.Duration(config.IdleConnTimeout) * time.Millisecond
+	}
+
+	if config.DisableHTTP2 {
+		// A non-nil, empty TLSNextProto map disables HTTP/2 negotiation
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	} else {
+		transport.ForceAttemptHTTP2 = true
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
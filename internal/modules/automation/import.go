@@ -0,0 +1,143 @@
+package automation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// validateAutomationConfigJSON checks the settings of an automation config saved from the editor
+// or API, returning the config with them in canonical form
+func validateAutomationConfigJSON(configJSON string) (string, error) {
+	config, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	var automationConfig AutomationConfig
+	if configJSON != "" {
+		if err := json.Unmarshal([]byte(configJSON), &automationConfig); err != nil {
+			return "", fmt.Errorf("%w: invalid automation config: %s", platform.ErrInvalidRequest, err)
+		}
+	}
+	if err := validateConcurrency(automationConfig.Concurrency); err != nil {
+		return "", fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	staticVars := make(map[string]bool)
+	for _, variable := range automationConfig.Variables {
+		if variable.Type == "static" {
+			staticVars[variable.Key] = true
+		}
+	}
+	if err := validateNaming(automationConfig.Naming, staticVars); err != nil {
+		return "", fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	changed := false
+	if locale, _ := config["reportLocale"].(string); locale != "" {
+		normalized, err := NormalizeLocale(locale)
+		if err != nil {
+			return "", fmt.Errorf("%w: reportLocale: %s", platform.ErrInvalidRequest, err)
+		}
+		if normalized != locale {
+			config["reportLocale"] = normalized
+			changed = true
+		}
+	}
+
+	if !changed {
+		return configJSON, nil
+	}
+	return marshalConfigMap(config)
+}
+
+// ValidateAutomationImport checks an exported automation config before it is imported:
+// names must be present, every action type (including nested actions) must be registered
+// and the automation-level settings must be usable by the runner.
+func ValidateAutomationImport(imported *ExportedAutomationConfig) error {
+	var problems []string
+
+	if strings.TrimSpace(imported.Automation.Name) == "" {
+		problems = append(problems, "automation name is required")
+	}
+	if len(imported.Automation.Name) > 255 {
+		problems = append(problems, "automation name must be at most 255 characters")
+	}
+	if err := validateHostMappings(imported.Automation.Config.HostMappings); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := validateConcurrency(imported.Automation.Config.Concurrency); err != nil {
+		problems = append(problems, err.Error())
+	}
+	staticVars := make(map[string]bool)
+	for _, variable := range imported.Automation.Config.Variables {
+		if variable.Type == "static" {
+			staticVars[variable.Key] = true
+		}
+	}
+	if err := validateNaming(imported.Automation.Config.Naming, staticVars); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if imported.Automation.Config.ReportLocale != "" {
+		if _, err := NormalizeLocale(imported.Automation.Config.ReportLocale); err != nil {
+			problems = append(problems, "reportLocale: "+err.Error())
+		}
+	}
+	if len(imported.Automation.Config.TLS.CACertificates) > 0 {
+		if _, err := parseCACertificates(imported.Automation.Config.TLS.CACertificates); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	for i, step := range imported.Steps {
+		stepLabel := fmt.Sprintf("step %d", i+1)
+		if strings.TrimSpace(step.Name) == "" {
+			problems = append(problems, stepLabel+": name is required")
+		} else {
+			stepLabel = fmt.Sprintf("step '%s'", step.Name)
+		}
+
+		for j, action := range step.Actions {
+			actionLabel := fmt.Sprintf("%s, action %d", stepLabel, j+1)
+			if action.ActionType == "" {
+				problems = append(problems, actionLabel+": action_type is required")
+				continue
+			}
+			for _, actionType := range append([]string{action.ActionType}, nestedActionTypes(action.ActionConfig)...) {
+				if _, err := GetAction(actionType); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: unknown action type '%s'", actionLabel, actionType))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// RegisteredActionTypes returns every registered action type, sorted
+func RegisteredActionTypes() []string {
+	actionTypes := make([]string, 0, len(actionRegistry))
+	for actionType := range actionRegistry {
+		actionTypes = append(actionTypes, actionType)
+	}
+	sort.Strings(actionTypes)
+	return actionTypes
+}
+
+// nestedActionTypes lists the types of the nested actions of a config, at any depth
+func nestedActionTypes(config map[string]interface{}) []string {
+	var actionTypes []string
+	walkNestedActions(config, func(action nestedAction) error {
+		actionTypes = append(actionTypes, action.ActionType)
+		actionTypes = append(actionTypes, nestedActionTypes(action.Config)...)
+		return nil
+	})
+	return actionTypes
+}
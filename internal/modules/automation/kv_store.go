@@ -0,0 +1,94 @@
+package automation
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunScopedKVTTL is how long run-scoped store entries outlive the run that wrote them
+const RunScopedKVTTL = 24 * time.Hour
+
+// KVStore is an encrypted key-value store actions use to pass data within a run or
+// between chained automations
+type KVStore interface {
+	// Put stores value under key; a zero ttl keeps it until overwritten
+	Put(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Get returns the value under key and whether it exists
+	Get(ctx context.Context, key string) (string, bool, error)
+}
+
+// RunKVKey namespaces a key to a single run
+func RunKVKey(runID, key string) string {
+	return fmt.Sprintf("kv:run:%s:%s", runID, key)
+}
+
+// AutomationKVKey namespaces a key to an automation, keeping it across runs. Keys include the
+// project so automations can only read entries written within their own project.
+func AutomationKVKey(projectID, automationID, key string) string {
+	return fmt.Sprintf("kv:project:%s:automation:%s:%s", projectID, automationID, key)
+}
+
+// RedisKVStore implements KVStore using Redis, encrypting values with AES-GCM
+type RedisKVStore struct {
+	client *redis.Client
+	aead   cipher.AEAD
+}
+
+// NewRedisKVStore creates a Redis-backed store whose values are encrypted with a key derived from secret
+func NewRedisKVStore(client *redis.Client, secret string) (KVStore, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("an encryption secret is required")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &RedisKVStore{client: client, aead: aead}, nil
+}
+
+// Put encrypts value and stores it under key
+func (s *RedisKVStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The key is bound as additional data so an entry copied to another key fails to decrypt
+	sealed := s.aead.Seal(nonce, nonce, []byte(value), []byte(key))
+	return s.client.Set(ctx, key, base64.StdEncoding.EncodeToString(sealed), ttl).Err()
+}
+
+// Get reads and decrypts the value under key
+func (s *RedisKVStore) Get(ctx context.Context, key string) (string, bool, error) {
+	encoded, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", false, fmt.Errorf("stored value for %s is corrupt", key)
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decrypt stored value for %s", key)
+	}
+	return string(plaintext), true, nil
+}
@@ -0,0 +1,797 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// Maintenance calendar limits
+const (
+	maintenanceSyncInterval        = 15 * time.Minute
+	maintenanceCalendarTimeout     = 30 * time.Second
+	publicHostLookupTimeout        = 5 * time.Second
+	maxMaintenanceCalendarBytes    = 5 << 20
+	maxMaintenanceWindows          = 1000 // per organization, earliest first
+	maintenanceWindowHistory       = 7 * 24 * time.Hour
+	maintenanceWindowHorizon       = 180 * 24 * time.Hour
+	maxMaintenanceRecurrenceSearch = 10000 // recurrence iterations per event
+)
+
+// maintenanceCalendarClient fetches feeds from public addresses only, so calendar URLs can't be
+// used to reach services inside the deployment
+var maintenanceCalendarClient = newPublicHTTPClient(maintenanceCalendarTimeout)
+
+// newPublicHTTPClient returns a client that refuses to connect to loopback, private, link-local
+// and other non-public addresses, checked on the resolved address of every connection it makes,
+// redirects included
+func newPublicHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, Control: publicAddressOnly}).DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// nonPublicPrefixes are address ranges beyond those net.IP classifies that aren't publicly routable
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// publicAddressOnly is a dialer control refusing connections to non-public addresses
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("invalid address %q", host)
+	}
+	return checkPublicAddress(ip)
+}
+
+// checkPublicAddress reports an error for an address that isn't publicly routable
+func checkPublicAddress(ip netip.Addr) error {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("address %s is not public", ip)
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("address %s is not public", ip)
+		}
+	}
+	return nil
+}
+
+// checkPublicHost rejects a URL host that is, or resolves to, a non-public address, so a URL
+// pointing inside the deployment is refused when it is saved and not only when it is fetched.
+// A name that doesn't resolve yet is accepted; the fetch checks it again.
+func checkPublicHost(host string) error {
+	if ip, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return checkPublicAddress(ip)
+	}
+	name := strings.ToLower(strings.TrimSuffix(host, "."))
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return fmt.Errorf("host %s is not public", host)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publicHostLookupTimeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupNetIP(ctx, "ip", name)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range addresses {
+		if err := checkPublicAddress(ip); err != nil {
+			return fmt.Errorf("host %s resolves to a non-public address", host)
+		}
+	}
+	return nil
+}
+
+// SyncMaintenanceCalendars refreshes the maintenance windows of every organization with a
+// maintenance calendar. Windows from a week ago to six months ahead are kept, recurring events
+// expanded. An organization keeps its previous windows when its feed can't be read.
+func (s *automationService) SyncMaintenanceCalendars(ctx context.Context) {
+	calendars, err := s.automationRepo.GetMaintenanceCalendars(ctx)
+	if err != nil {
+		slog.Error("Failed to get maintenance calendars", "error", err)
+		return
+	}
+
+	for _, calendar := range calendars {
+		if ctx.Err() != nil {
+			return
+		}
+		s.syncMaintenanceCalendar(ctx, calendar)
+	}
+}
+
+// SyncMaintenanceCalendar refreshes the maintenance windows of one organization, e.g. right after
+// its calendar URL is saved
+func (s *automationService) SyncMaintenanceCalendar(ctx context.Context, orgID string) {
+	calendar, err := s.automationRepo.GetMaintenanceCalendar(ctx, orgID)
+	if err != nil {
+		slog.Error("Failed to get maintenance calendar", "error", err, "orgID", orgID)
+		return
+	}
+	if calendar.URL == "" {
+		return
+	}
+	s.syncMaintenanceCalendar(ctx, calendar)
+}
+
+// syncMaintenanceCalendar replaces an organization's windows with those of its feed in one
+// transaction, so a failed write or a concurrent sync by another worker can't lose them
+func (s *automationService) syncMaintenanceCalendar(ctx context.Context, calendar *MaintenanceCalendar) {
+	now := time.Now()
+	windows, err := fetchMaintenanceCalendar(ctx, calendar.URL, now.Add(-maintenanceWindowHistory), now.Add(maintenanceWindowHorizon))
+	if err != nil {
+		slog.Warn("Failed to sync maintenance calendar", "error", err, "orgID", calendar.OrganizationID)
+		return
+	}
+	for _, window := range windows {
+		window.ID = platform.UtilGenerateUUID()
+		window.OrganizationID = calendar.OrganizationID
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := NewAutomationRepository(tx).ReplaceMaintenanceWindows(ctx, calendar.OrganizationID, windows); err != nil {
+		slog.Error("Failed to save maintenance windows", "error", err, "orgID", calendar.OrganizationID)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit maintenance windows", "error", err, "orgID", calendar.OrganizationID)
+		return
+	}
+	slog.Debug("Maintenance calendar synced", "orgID", calendar.OrganizationID, "windows", len(windows))
+}
+
+// GetUpcomingMaintenanceWindows lists the current and future maintenance windows of the
+// organization owning a project
+func (s *automationService) GetUpcomingMaintenanceWindows(ctx context.Context, projectID string) ([]*MaintenanceWindow, error) {
+	windows, err := s.automationRepo.GetMaintenanceWindowsByProjectID(ctx, projectID, time.Now())
+	if err != nil {
+		slog.Error("Failed to get maintenance windows", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	if windows == nil {
+		windows = []*MaintenanceWindow{}
+	}
+	return windows, nil
+}
+
+// GetRunMaintenanceWindow returns the maintenance window a run was executed during, or nil
+func (s *automationService) GetRunMaintenanceWindow(ctx context.Context, runID string) (*MaintenanceWindow, error) {
+	window, err := s.automationRepo.GetRunMaintenanceWindow(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run maintenance window", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run maintenance window: %w", err)
+	}
+	return window, nil
+}
+
+// checkMaintenancePause refuses to run an automation while its organization pauses runs for a
+// maintenance window
+func checkMaintenancePause(ctx context.Context, automationRepo AutomationRepository, automationID string) error {
+	calendar, err := automationRepo.GetMaintenanceCalendarByAutomationID(ctx, automationID)
+	if err != nil {
+		slog.Warn("Failed to get maintenance calendar, proceeding anyway", "error", err, "automationID", automationID)
+		return nil
+	}
+	if calendar.URL == "" || !calendar.PauseRuns {
+		return nil
+	}
+
+	window := maintenanceWindowDuring(ctx, automationRepo, automationID, time.Now(), time.Now())
+	if window != nil {
+		return fmt.Errorf("%w: runs are paused for maintenance '%s' until %s",
+			platform.ErrConflict, window.Summary, window.EndsAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// maintenanceWindowDuring returns the first maintenance window overlapping from..to of the
+// organization owning an automation, or nil. Failing lookups count as no maintenance.
+func maintenanceWindowDuring(ctx context.Context, automationRepo AutomationRepository, automationID string, from, to time.Time) *MaintenanceWindow {
+	if !to.After(from) {
+		// An instant overlaps the windows it falls in
+		to = from.Add(time.Millisecond)
+	}
+	windows, err := automationRepo.GetMaintenanceWindowsByAutomationID(ctx, automationID, from, to)
+	if err != nil {
+		slog.Warn("Failed to get maintenance windows", "error", err, "automationID", automationID)
+		return nil
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+	return windows[0]
+}
+
+// runMaintenanceWindow returns the maintenance window a run was executed during so far, or nil
+func runMaintenanceWindow(ctx context.Context, automationRepo AutomationRepository, run *AutomationRun) *MaintenanceWindow {
+	to := time.Now()
+	if run.EndTime != nil {
+		to = *run.EndTime
+	}
+	from := to
+	if run.StartTime != nil {
+		from = *run.StartTime
+	}
+	return maintenanceWindowDuring(ctx, automationRepo, run.AutomationID, from, to)
+}
+
+// silencedForMaintenance reports whether alerts about a run are silenced, since it was executed
+// during a maintenance window and failing or slowing down there is expected
+func silencedForMaintenance(ctx context.Context, automationRepo AutomationRepository, run *AutomationRun, alert string) bool {
+	window := runMaintenanceWindow(ctx, automationRepo, run)
+	if window == nil {
+		return false
+	}
+	slog.Info("Silenced alert for run during maintenance", "run_id", run.ID, "alert", alert, "maintenance", window.Summary)
+	return true
+}
+
+// annotateMaintenance records the maintenance window a finished run was executed during
+func (s *Scheduler) annotateMaintenance(ctx context.Context, run *AutomationRun) {
+	window := runMaintenanceWindow(ctx, s.automationRepo, run)
+	if window == nil {
+		return
+	}
+	if err := s.automationRepo.SetRunMaintenanceWindow(ctx, run.ID, window); err != nil {
+		slog.Error("Failed to annotate run with maintenance window", "run_id", run.ID, "error", err)
+		return
+	}
+	slog.Info("Run executed during maintenance", "run_id", run.ID, "maintenance", window.Summary)
+}
+
+// fetchMaintenanceCalendar downloads an iCal feed and returns its events overlapping from..to
+func fetchMaintenanceCalendar(ctx context.Context, feedURL string, from, to time.Time) ([]*MaintenanceWindow, error) {
+	// Calendar apps hand out feeds as webcal:// links to HTTPS
+	if rest, ok := strings.CutPrefix(feedURL, "webcal://"); ok {
+		feedURL = "https://" + rest
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar URL: %w", err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := maintenanceCalendarClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("calendar returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMaintenanceCalendarBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	if len(body) > maxMaintenanceCalendarBytes {
+		return nil, fmt.Errorf("calendar is larger than %d MB", maxMaintenanceCalendarBytes>>20)
+	}
+	return parseMaintenanceCalendar(string(body), from, to)
+}
+
+// icalProperty is a content line of an iCalendar object
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icalEvent holds the properties of a VEVENT used for maintenance windows
+type icalEvent struct {
+	uid          string
+	summary      string
+	start, end   time.Time
+	allDay       bool
+	duration     time.Duration
+	hasEnd       bool
+	cancelled    bool
+	rrule        string
+	exdates      []time.Time
+	recurrenceID *time.Time
+}
+
+// parseMaintenanceCalendar parses the events of an iCalendar object (RFC 5545) into maintenance
+// windows overlapping from..to, earliest first. Daily, weekly, monthly and yearly recurrence
+// rules are expanded with their INTERVAL, COUNT and UNTIL parts, BYDAY for weekly and monthly
+// rules and BYMONTHDAY for monthly ones; events with other rule parts are skipped with a warning
+// rather than expanded wrongly. Cancelled events and excluded or moved occurrences are left out.
+func parseMaintenanceCalendar(data string, from, to time.Time) ([]*MaintenanceWindow, error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	if !strings.Contains(data, "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("not an iCalendar feed")
+	}
+
+	// Unfold lines continued with a leading space or tab
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var events []*icalEvent
+	var event *icalEvent
+	depth := 0 // components nested in the current event, such as alarms
+	for _, line := range lines {
+		property, ok := parseICalProperty(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case property.name == "BEGIN" && property.value == "VEVENT":
+			event = &icalEvent{}
+			depth = 0
+			continue
+		case event == nil:
+			continue
+		case property.name == "BEGIN":
+			depth++
+			continue
+		case property.name == "END" && depth > 0:
+			depth--
+			continue
+		case property.name == "END" && property.value == "VEVENT":
+			events = append(events, event)
+			event = nil
+			continue
+		case depth > 0:
+			continue
+		}
+
+		var err error
+		switch property.name {
+		case "UID":
+			event.uid = property.value
+		case "SUMMARY":
+			event.summary = unescapeICalText(property.value)
+		case "STATUS":
+			event.cancelled = strings.EqualFold(property.value, "CANCELLED")
+		case "DTSTART":
+			event.start, event.allDay, err = parseICalTime(property)
+		case "DTEND":
+			event.end, _, err = parseICalTime(property)
+			event.hasEnd = true
+		case "DURATION":
+			event.duration, err = parseICalDuration(property.value)
+		case "RRULE":
+			event.rrule = property.value
+		case "EXDATE":
+			for _, value := range strings.Split(property.value, ",") {
+				exdate, _, exErr := parseICalTime(icalProperty{params: property.params, value: value})
+				if exErr == nil {
+					event.exdates = append(event.exdates, exdate)
+				}
+			}
+		case "RECURRENCE-ID":
+			var recurrenceID time.Time
+			recurrenceID, _, err = parseICalTime(property)
+			event.recurrenceID = &recurrenceID
+		}
+		if err != nil {
+			return nil, fmt.Errorf("event %q: invalid %s: %w", event.uid, property.name, err)
+		}
+	}
+
+	// Moved or cancelled occurrences of recurring events replace the original occurrence
+	overridden := make(map[string][]time.Time)
+	for _, e := range events {
+		if e.recurrenceID != nil {
+			overridden[e.uid] = append(overridden[e.uid], *e.recurrenceID)
+		}
+	}
+
+	var windows []*MaintenanceWindow
+	for _, e := range events {
+		if e.cancelled || e.start.IsZero() {
+			continue
+		}
+		length := e.end.Sub(e.start)
+		switch {
+		case e.hasEnd:
+		case e.duration > 0:
+			length = e.duration
+		case e.allDay:
+			length = 24 * time.Hour
+		}
+		if length <= 0 {
+			continue
+		}
+
+		starts := []time.Time{e.start}
+		if e.rrule != "" && e.recurrenceID == nil {
+			var err error
+			if starts, err = expandICalRecurrence(e.start, e.rrule, to); err != nil {
+				slog.Warn("Skipping maintenance event with unsupported recurrence", "uid", e.uid, "rrule", e.rrule, "error", err)
+				continue
+			}
+		}
+
+		summary := e.summary
+		if summary == "" {
+			summary = "Maintenance"
+		}
+		for _, start := range starts {
+			end := start.Add(length)
+			if !end.After(from) || !start.Before(to) {
+				continue
+			}
+			if slices.ContainsFunc(e.exdates, start.Equal) {
+				continue
+			}
+			if e.recurrenceID == nil && slices.ContainsFunc(overridden[e.uid], start.Equal) {
+				continue
+			}
+			windows = append(windows, &MaintenanceWindow{UID: e.uid, Summary: summary, StartsAt: start, EndsAt: end})
+		}
+	}
+
+	slices.SortFunc(windows, func(a, b *MaintenanceWindow) int { return a.StartsAt.Compare(b.StartsAt) })
+	if len(windows) > maxMaintenanceWindows {
+		windows = windows[:maxMaintenanceWindows]
+	}
+	return windows, nil
+}
+
+// parseICalProperty splits a content line into its name, parameters and value
+func parseICalProperty(line string) (icalProperty, bool) {
+	// The value starts at the first colon outside quoted parameter values
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return icalProperty{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	property := icalProperty{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			property.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return property, true
+}
+
+// parseICalTime parses a DATE or DATE-TIME value. UTC times end in Z, others are in their TZID
+// time zone; floating times and dates are taken as UTC.
+func parseICalTime(property icalProperty) (time.Time, bool, error) {
+	value := strings.TrimSpace(property.value)
+	if property.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+	if utc, ok := strings.CutSuffix(value, "Z"); ok {
+		t, err := time.Parse("20060102T150405", utc)
+		return t, false, err
+	}
+
+	location := time.UTC
+	if tzid := property.params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+// parseICalDuration parses a duration such as PT1H30M, P1D or P2W
+func parseICalDuration(value string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(value, "+"), "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var duration time.Duration
+	number := ""
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		default:
+			unit, ok := units[c]
+			n, err := strconv.Atoi(number)
+			if !ok || err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			duration += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return duration, nil
+}
+
+// icalWeekdays maps BYDAY codes to weekdays
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// icalByDay is a BYDAY entry: a weekday, in monthly rules optionally the n-th of the month
+// (2TU, the second Tuesday) or counted from its end (-1FR, the last Friday)
+type icalByDay struct {
+	ordinal int
+	weekday time.Weekday
+}
+
+// icalRuleParts are the recurrence rule parts expanded; a rule with any other part is refused
+var icalRuleParts = map[string]bool{
+	"FREQ": true, "INTERVAL": true, "COUNT": true, "UNTIL": true, "WKST": true, "BYDAY": true, "BYMONTHDAY": true,
+}
+
+// parseICalByDay parses a BYDAY entry such as TU, 2TU or -1FR
+func parseICalByDay(value string) (icalByDay, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return icalByDay{}, fmt.Errorf("invalid BYDAY %q", value)
+	}
+	weekday, ok := icalWeekdays[value[len(value)-2:]]
+	if !ok {
+		return icalByDay{}, fmt.Errorf("invalid BYDAY %q", value)
+	}
+	byDay := icalByDay{weekday: weekday}
+	if ordinal := value[:len(value)-2]; ordinal != "" {
+		n, err := strconv.Atoi(ordinal)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return icalByDay{}, fmt.Errorf("invalid BYDAY %q", value)
+		}
+		byDay.ordinal = n
+	}
+	return byDay, nil
+}
+
+// icalMonthDays returns the days of month's month selected by byDays and monthDays, earliest
+// first, at the time of day of month. Both narrow the days down when given together.
+func icalMonthDays(month time.Time, byDays []icalByDay, monthDays []int) []time.Time {
+	daysInMonth := month.AddDate(0, 1, -1).Day()
+
+	selected := make([]bool, daysInMonth+1)
+	if len(byDays) == 0 {
+		for day := 1; day <= daysInMonth; day++ {
+			selected[day] = true
+		}
+	}
+	for _, byDay := range byDays {
+		var matching []int
+		for day := 1; day <= daysInMonth; day++ {
+			if month.AddDate(0, 0, day-1).Weekday() == byDay.weekday {
+				matching = append(matching, day)
+			}
+		}
+		switch {
+		case byDay.ordinal == 0:
+			for _, day := range matching {
+				selected[day] = true
+			}
+		case byDay.ordinal > 0 && byDay.ordinal <= len(matching):
+			selected[matching[byDay.ordinal-1]] = true
+		case byDay.ordinal < 0 && -byDay.ordinal <= len(matching):
+			selected[matching[len(matching)+byDay.ordinal]] = true
+		}
+	}
+	if len(monthDays) > 0 {
+		inMonthDays := make([]bool, daysInMonth+1)
+		for _, day := range monthDays {
+			if day < 0 {
+				day = daysInMonth + day + 1
+			}
+			if day >= 1 && day <= daysInMonth {
+				inMonthDays[day] = true
+			}
+		}
+		for day := range selected {
+			selected[day] = selected[day] && inMonthDays[day]
+		}
+	}
+
+	var days []time.Time
+	for day := 1; day <= daysInMonth; day++ {
+		if selected[day] {
+			days = append(days, month.AddDate(0, 0, day-1))
+		}
+	}
+	return days
+}
+
+// expandICalRecurrence returns the starts of the occurrences of a recurrence rule that begin
+// before limit, the first being start itself. Rules it can't expand exactly are refused.
+func expandICalRecurrence(start time.Time, rrule string, limit time.Time) ([]time.Time, error) {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rrule, ";") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			key = strings.ToUpper(key)
+			if !icalRuleParts[key] {
+				return nil, fmt.Errorf("unsupported rule part %s", key)
+			}
+			parts[key] = value
+		}
+	}
+	freq := strings.ToUpper(parts["FREQ"])
+
+	interval := 1
+	if value := parts["INTERVAL"]; value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid INTERVAL %q", value)
+		}
+		interval = n
+	}
+	count := 0
+	if value := parts["COUNT"]; value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid COUNT %q", value)
+		}
+		count = n
+	}
+	if value := parts["UNTIL"]; value != "" {
+		until, _, err := parseICalTime(icalProperty{value: value, params: map[string]string{}})
+		if err != nil {
+			return nil, fmt.Errorf("invalid UNTIL %q", value)
+		}
+		if len(value) == 8 {
+			// A date includes the occurrences of its whole day
+			until = until.Add(24*time.Hour - time.Nanosecond)
+		}
+		if until.Before(limit) {
+			limit = until.Add(time.Nanosecond)
+		}
+	}
+
+	var byDays []icalByDay
+	if value := parts["BYDAY"]; value != "" {
+		if freq != "WEEKLY" && freq != "MONTHLY" {
+			return nil, fmt.Errorf("BYDAY is only supported in weekly and monthly rules")
+		}
+		for _, day := range strings.Split(value, ",") {
+			byDay, err := parseICalByDay(day)
+			if err != nil {
+				return nil, err
+			}
+			if byDay.ordinal != 0 && freq != "MONTHLY" {
+				return nil, fmt.Errorf("numbered BYDAY %q is only supported in monthly rules", day)
+			}
+			byDays = append(byDays, byDay)
+		}
+	}
+	var monthDays []int
+	if value := parts["BYMONTHDAY"]; value != "" {
+		if freq != "MONTHLY" {
+			return nil, fmt.Errorf("BYMONTHDAY is only supported in monthly rules")
+		}
+		for _, day := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(day))
+			if err != nil || n == 0 || n < -31 || n > 31 {
+				return nil, fmt.Errorf("invalid BYMONTHDAY %q", day)
+			}
+			monthDays = append(monthDays, n)
+		}
+	}
+	if wkst := parts["WKST"]; wkst != "" && !strings.EqualFold(wkst, "MO") && freq == "WEEKLY" && interval > 1 && len(byDays) > 0 {
+		return nil, fmt.Errorf("unsupported WKST %q", wkst)
+	}
+
+	// next returns the start of the i-th period after start's, by the calendar of start's zone
+	var next func(i int) time.Time
+	switch freq {
+	case "DAILY":
+		next = func(i int) time.Time { return start.AddDate(0, 0, i*interval) }
+	case "WEEKLY":
+		next = func(i int) time.Time { return start.AddDate(0, 0, 7*i*interval) }
+	case "MONTHLY":
+		next = func(i int) time.Time { return start.AddDate(0, i*interval, 0) }
+	case "YEARLY":
+		next = func(i int) time.Time { return start.AddDate(i*interval, 0, 0) }
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", freq)
+	}
+
+	starts := []time.Time{start}
+	add := func(occurrence time.Time) bool {
+		if !occurrence.Before(limit) || (count > 0 && len(starts) >= count) {
+			return false
+		}
+		starts = append(starts, occurrence)
+		return true
+	}
+
+	if freq == "MONTHLY" && (len(byDays) > 0 || len(monthDays) > 0) {
+		// The selected days of each period's month, at the time of day of start
+		for i := 0; i < maxMaintenanceRecurrenceSearch; i++ {
+			month := time.Date(start.Year(), start.Month()+time.Month(i*interval), 1,
+				start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+			for _, day := range icalMonthDays(month, byDays, monthDays) {
+				if !day.After(start) {
+					continue
+				}
+				if !add(day) {
+					return starts, nil
+				}
+			}
+		}
+		return starts, nil
+	}
+
+	if len(byDays) > 0 {
+		// Every listed day of each period's week, which starts on Monday, at the time of day of start
+		slices.SortFunc(byDays, func(a, b icalByDay) int { return (int(a.weekday)+6)%7 - (int(b.weekday)+6)%7 })
+		monday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for i := 0; i < maxMaintenanceRecurrenceSearch; i++ {
+			week := monday.AddDate(0, 0, 7*i*interval)
+			for _, byDay := range byDays {
+				day := week.AddDate(0, 0, (int(byDay.weekday)+6)%7)
+				if !day.After(start) {
+					continue
+				}
+				if !add(day) {
+					return starts, nil
+				}
+			}
+		}
+		return starts, nil
+	}
+
+	for i := 1; i < maxMaintenanceRecurrenceSearch; i++ {
+		occurrence := next(i)
+		if occurrence.Day() != start.Day() {
+			// Months without the day of start, e.g. February for the 31st, have no occurrence
+			continue
+		}
+		if !add(occurrence) {
+			break
+		}
+	}
+	return starts, nil
+}
+
+// unescapeICalText unescapes a TEXT value
+func unescapeICalText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
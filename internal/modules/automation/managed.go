@@ -0,0 +1,152 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// externalIDPattern matches the IDs infrastructure-as-code tools manage resources by: a letter or
+// digit followed by letters, digits, dots, dashes, underscores or colons
+var externalIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$`)
+
+// notificationChannelTypes are the channel types notifications can be sent to
+var notificationChannelTypes = map[string]bool{"slack": true, "email": true, "webhook": true}
+
+func validateExternalID(externalID string) error {
+	if !externalIDPattern.MatchString(externalID) {
+		return fmt.Errorf("external ID must be 1 to 128 letters, digits, dots, dashes, underscores or colons, starting with a letter or digit")
+	}
+	return nil
+}
+
+func validateNotificationChannel(channel NotificationChannelConfig) error {
+	if err := validateExternalID(channel.ID); err != nil {
+		return fmt.Errorf("channel ID: %w", err)
+	}
+	if !notificationChannelTypes[channel.Type] {
+		return fmt.Errorf("channel type must be slack, email or webhook")
+	}
+	return nil
+}
+
+// automationETag returns the entity tag of an exported automation: the hash of its config
+// without action IDs, so it only changes when the automation does
+func automationETag(exported *ExportedAutomationConfig) (string, error) {
+	config, err := snapshotConfig(exported)
+	if err != nil {
+		return "", err
+	}
+	return configHash(config)
+}
+
+// notificationChannelETag returns the entity tag of a notification channel
+func notificationChannelETag(channel NotificationChannelConfig) (string, error) {
+	data, err := json.Marshal(channel)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode channel: %w", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("failed to decode channel: %w", err)
+	}
+	return configHash(config)
+}
+
+// checkWritePrecondition checks a conditional write against the current ETag of its resource,
+// empty when the resource doesn't exist yet
+func checkWritePrecondition(precondition WritePrecondition, etag string) error {
+	if precondition.IfNoneMatch != "" && etag != "" && etagListMatches(precondition.IfNoneMatch, etag) {
+		return fmt.Errorf("%w: resource already exists", platform.ErrPreconditionFailed)
+	}
+	if precondition.IfMatch != "" && (etag == "" || !etagListMatches(precondition.IfMatch, etag)) {
+		if etag == "" {
+			return fmt.Errorf("%w: resource does not exist", platform.ErrPreconditionFailed)
+		}
+		return fmt.Errorf("%w: resource has changed", platform.ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// etagListMatches reports whether an If-Match or If-None-Match header value, a list of quoted
+// ETags or *, matches etag
+func etagListMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.Trim(strings.TrimPrefix(candidate, "W/"), `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// keepNotifications copies the current notification channels into configJSON when the upserted
+// config leaves 'notifications' out, since channels may be managed one by one through their own
+// endpoint; an explicit list, empty included, replaces them
+func keepNotifications(configJSON string, config, current *ExportedAutomationConfig) (string, error) {
+	if config.Automation.Config.Notifications != nil || current == nil {
+		return configJSON, nil
+	}
+
+	configMap, err := parseConfigMap(configJSON)
+	if err != nil {
+		return "", err
+	}
+	configMap["notifications"] = current.Automation.Config.Notifications
+	return marshalConfigMap(configMap)
+}
+
+// storedAutomationConfig returns config as it will be exported once stored with configJSON, with
+// config upgrades applied and steps and actions renumbered, so upserts that change nothing can
+// be told apart from those that do
+func storedAutomationConfig(configJSON string, config *ExportedAutomationConfig) (*ExportedAutomationConfig, error) {
+	configJSON, _, err := UpgradeAutomationConfigJSON(configJSON)
+	if err != nil {
+		return nil, err
+	}
+	stored := &ExportedAutomationConfig{
+		Automation: ExportedAutomation{Name: config.Automation.Name, Description: config.Automation.Description},
+	}
+	if err := json.Unmarshal([]byte(configJSON), &stored.Automation.Config); err != nil {
+		return nil, fmt.Errorf("invalid automation config: %w", err)
+	}
+
+	for i, step := range config.Steps {
+		storedStep := ExportedAutomationStep{Name: step.Name, StepOrder: i + 1}
+		if len(step.Config) > 0 {
+			storedStep.Config = step.Config
+		}
+		for j, action := range step.Actions {
+			actionConfig := action.ActionConfig
+			if actionConfig == nil {
+				actionConfig = map[string]interface{}{}
+			}
+			actionConfigJSON, err := marshalConfigMap(actionConfig)
+			if err != nil {
+				return nil, err
+			}
+			actionConfigJSON, _, err = UpgradeActionConfigJSON(action.ActionType, actionConfigJSON)
+			if err != nil {
+				return nil, err
+			}
+			var storedConfig map[string]interface{}
+			if err := json.Unmarshal([]byte(actionConfigJSON), &storedConfig); err != nil {
+				return nil, fmt.Errorf("invalid action config: %w", err)
+			}
+			storedStep.Actions = append(storedStep.Actions, ExportedAutomationAction{
+				Name:         action.Name,
+				ActionType:   action.ActionType,
+				ActionConfig: storedConfig,
+				ActionOrder:  j + 1,
+			})
+		}
+		stored.Steps = append(stored.Steps, storedStep)
+	}
+	return stored, nil
+}
@@ -0,0 +1,57 @@
+package automation
+
+import "sort"
+
+// nestedAction is an action held in another action's config, as control-flow actions such as
+// if/else and loops hold them: {"id", "action_type", "action_config"}
+type nestedAction struct {
+	ID         string
+	ActionType string
+	Config     map[string]interface{}
+}
+
+// walkNestedActions calls visit with each action nested directly in a config, at any depth of
+// objects and lists but not inside other nested actions, in a stable order. Actions nested in
+// those are left to visit, which usually walks their config in turn. The walk stops at the
+// first error visit returns.
+func walkNestedActions(value interface{}, visit func(action nestedAction) error) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if actionType, ok := v["action_type"].(string); ok {
+			if nestedConfig, ok := v["action_config"].(map[string]interface{}); ok {
+				id, _ := v["id"].(string)
+				return visit(nestedAction{ID: id, ActionType: actionType, Config: nestedConfig})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := walkNestedActions(v[key], visit); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if err := walkNestedActions(child, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withActionID attributes the warnings about a nested action that don't name an action to it
+func withActionID(warnings []ConfigWarning, actionID string) []ConfigWarning {
+	if actionID == "" {
+		return warnings
+	}
+	for i := range warnings {
+		if warnings[i].ActionID == "" {
+			warnings[i].ActionID = actionID
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,114 @@
+package automation
+
+import (
+	"encoding/json"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// NewOutputFileFromEvent builds the stored record for an output file event
+func NewOutputFileFromEvent(event RunEvent) OutputFile {
+	contentType := event.ContentType
+	if contentType == "" {
+		contentType = contentTypeFromPath(event.OutputFile)
+	}
+
+	kind := event.OutputFileKind
+	if kind == "" {
+		kind = InferOutputFileKind(contentType, event.ActionType)
+	}
+
+	createdAt := event.Timestamp
+	return OutputFile{
+		URL:            event.OutputFile,
+		Key:            event.StorageKey,
+		Kind:           kind,
+		ContentType:    contentType,
+		Size:           event.Size,
+		StepID:         event.StepID,
+		StepName:       event.StepName,
+		ActionID:       event.ActionID,
+		ActionName:     event.ActionName,
+		ActionType:     event.ActionType,
+		LoopIndex:      event.LoopIndex,
+		LocalLoopIndex: event.LocalLoopIndex,
+		CreatedAt:      &createdAt,
+	}
+}
+
+// InferOutputFileKind guesses an artifact's kind from its content type and the action that produced it
+func InferOutputFileKind(contentType, actionType string) OutputFileKind {
+	switch {
+	case strings.Contains(actionType, "screenshot"):
+		return OutputFileKindScreenshot
+	case strings.Contains(actionType, "video"):
+		return OutputFileKindVideo
+	case strings.Contains(actionType, "download"):
+		return OutputFileKindDownload
+	case strings.HasPrefix(contentType, "image/"):
+		return OutputFileKindScreenshot
+	case strings.HasPrefix(contentType, "video/"):
+		return OutputFileKindVideo
+	case strings.HasPrefix(contentType, "text/html"), contentType == "application/pdf", contentType == "application/json":
+		return OutputFileKindReport
+	default:
+		return OutputFileKindOther
+	}
+}
+
+// ParseOutputFiles decodes a run's OutputFilesJSON. Runs recorded before output files carried
+// metadata stored plain URL strings; those are upgraded to records with an inferred kind.
+func ParseOutputFiles(outputFilesJSON string) []OutputFile {
+	var raw []json.RawMessage
+	if outputFilesJSON == "" || json.Unmarshal([]byte(outputFilesJSON), &raw) != nil {
+		return []OutputFile{}
+	}
+
+	files := make([]OutputFile, 0, len(raw))
+	for _, item := range raw {
+		var fileURL string
+		if err := json.Unmarshal(item, &fileURL); err == nil {
+			contentType := contentTypeFromPath(fileURL)
+			files = append(files, OutputFile{
+				URL:         fileURL,
+				Kind:        InferOutputFileKind(contentType, ""),
+				ContentType: contentType,
+			})
+			continue
+		}
+
+		var file OutputFile
+		if err := json.Unmarshal(item, &file); err == nil && file.URL != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// OutputFiles returns the run's output files with their metadata
+func (r *AutomationRun) OutputFiles() []OutputFile {
+	return ParseOutputFiles(r.OutputFilesJSON)
+}
+
+// OutputFileURLs returns just the URLs of the given output files
+func OutputFileURLs(files []OutputFile) []string {
+	urls := make([]string, len(files))
+	for i, file := range files {
+		urls[i] = file.URL
+	}
+	return urls
+}
+
+// contentTypeFromPath guesses a content type from a URL or key's extension
+func contentTypeFromPath(location string) string {
+	if parsed, err := url.Parse(location); err == nil {
+		location = parsed.Path
+	}
+	contentType := mime.TypeByExtension(path.Ext(location))
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
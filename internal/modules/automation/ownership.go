@@ -0,0 +1,403 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/notification"
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// Automation owner types
+const (
+	OwnerTypeUser = "user"
+	OwnerTypeTeam = "team"
+)
+
+// Owner routing modes, see AutomationConfig.OwnerRouting
+const (
+	OwnerRoutingFallback = "fallback" // owners are notified of failures no configured channel is notified of
+	OwnerRoutingAlways   = "always"   // owners are notified of every failure
+	OwnerRoutingOff      = "off"
+)
+
+// maxAutomationOwners bounds the owners of one automation
+const maxAutomationOwners = 20
+
+// ownerReviewInterval is how long changes by the same editor are covered by one review request
+const ownerReviewInterval = 30 * time.Minute
+
+// AutomationOwner is a user or team responsible for an automation. Owners are notified of its
+// failures and asked to review changes others make to it.
+type AutomationOwner struct {
+	Type  string `json:"type"`            // "user" or "team"
+	ID    string `json:"id"`              // user ID, or team name
+	Label string `json:"label,omitempty"` // email of a user, filled when owners are read
+}
+
+// OwnerTeam is a team defined in an organization's settings
+type OwnerTeam struct {
+	Name          string                      `json:"name"`
+	MemberIDs     []string                    `json:"memberIds"`
+	Notifications []NotificationChannelConfig `json:"notifications,omitempty"` // failures of the team's automations go here instead of to members' emails
+}
+
+// OwnerDirectory holds who can own the automations of a project
+type OwnerDirectory struct {
+	Users map[string]string    // emails by user ID
+	Teams map[string]OwnerTeam // by name
+}
+
+// label fills in how an owner is displayed
+func (d *OwnerDirectory) label(owner AutomationOwner) AutomationOwner {
+	owner.Label = owner.ID
+	if owner.Type == OwnerTypeUser {
+		if email, ok := d.Users[owner.ID]; ok {
+			owner.Label = email
+		}
+	}
+	return owner
+}
+
+// memberOf tells whether a user is one of the owners, directly or through a team
+func (d *OwnerDirectory) memberOf(owners []AutomationOwner, userID string) bool {
+	for _, owner := range owners {
+		switch owner.Type {
+		case OwnerTypeUser:
+			if owner.ID == userID {
+				return true
+			}
+		case OwnerTypeTeam:
+			for _, memberID := range d.Teams[owner.ID].MemberIDs {
+				if memberID == userID {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// emails returns the addresses of the owners' users and team members, except excludeUserID's
+func (d *OwnerDirectory) emails(owners []AutomationOwner, excludeUserID string) []string {
+	seen := make(map[string]bool)
+	var emails []string
+	add := func(userID string) {
+		email, ok := d.Users[userID]
+		if !ok || userID == excludeUserID || seen[email] {
+			return
+		}
+		seen[email] = true
+		emails = append(emails, email)
+	}
+	for _, owner := range owners {
+		switch owner.Type {
+		case OwnerTypeUser:
+			add(owner.ID)
+		case OwnerTypeTeam:
+			for _, memberID := range d.Teams[owner.ID].MemberIDs {
+				add(memberID)
+			}
+		}
+	}
+	return emails
+}
+
+// validateAutomationOwners checks owners against the directory, dropping duplicates
+func validateAutomationOwners(owners []AutomationOwner, directory *OwnerDirectory) ([]AutomationOwner, error) {
+	if len(owners) > maxAutomationOwners {
+		return nil, fmt.Errorf("an automation can have at most %d owners", maxAutomationOwners)
+	}
+
+	seen := make(map[AutomationOwner]bool)
+	valid := make([]AutomationOwner, 0, len(owners))
+	for _, owner := range owners {
+		owner = AutomationOwner{Type: owner.Type, ID: strings.TrimSpace(owner.ID)}
+		switch owner.Type {
+		case OwnerTypeUser:
+			if _, ok := directory.Users[owner.ID]; !ok {
+				return nil, fmt.Errorf("user %q is not a member of the organization", owner.ID)
+			}
+		case OwnerTypeTeam:
+			if _, ok := directory.Teams[owner.ID]; !ok {
+				return nil, fmt.Errorf("team %q is not defined in the organization settings", owner.ID)
+			}
+		default:
+			return nil, fmt.Errorf("owner type must be %q or %q", OwnerTypeUser, OwnerTypeTeam)
+		}
+		if !seen[owner] {
+			seen[owner] = true
+			valid = append(valid, owner)
+		}
+	}
+	return valid, nil
+}
+
+// ownerFailureChannels returns the channels a failure of the automation is routed to on behalf of
+// its owners: a team's own channels with onError set, otherwise the emails of the owners and team
+// members. With the default fallback routing, owners are only notified when none of
+// configuredChannels is notified of failures. Owners of a rollout candidate aren't notified.
+func ownerFailureChannels(ctx context.Context, repo AutomationRepository, automation *Automation, automationConfig *AutomationConfig, configuredChannels []NotificationChannelConfig) []notification.NotificationChannelConfig {
+	if silencedForRollout(ctx, repo, automation.ID, "owner") {
+		return nil
+	}
+
+	switch automationConfig.OwnerRouting {
+	case OwnerRoutingOff:
+		return nil
+	case OwnerRoutingAlways:
+	default:
+		for _, channel := range configuredChannels {
+			if channel.OnError {
+				return nil
+			}
+		}
+	}
+
+	owners, err := repo.GetAutomationOwners(ctx, automation.ID)
+	if err != nil {
+		slog.Error("Failed to get automation owners", "automation_id", automation.ID, "error", err)
+		return nil
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	directory, err := repo.GetOwnerDirectory(ctx, automation.ProjectID)
+	if err != nil {
+		slog.Error("Failed to get owner directory", "automation_id", automation.ID, "error", err)
+		return nil
+	}
+
+	var channels []notification.NotificationChannelConfig
+	var mailed []AutomationOwner
+	for _, owner := range owners {
+		team, isTeam := directory.Teams[owner.ID]
+		routed := false
+		if owner.Type == OwnerTypeTeam && isTeam {
+			for _, channel := range team.Notifications {
+				if !channel.OnError {
+					continue
+				}
+				routed = true
+				channels = append(channels, notification.NotificationChannelConfig{
+					ID:      "owner:" + team.Name + ":" + channel.ID,
+					Type:    channel.Type,
+					OnError: true,
+					Config:  channel.Config,
+				})
+			}
+		}
+		if !routed {
+			mailed = append(mailed, owner)
+		}
+	}
+	for _, email := range directory.emails(mailed, "") {
+		channels = append(channels, notification.NotificationChannelConfig{
+			ID:      "owner:" + email,
+			Type:    "email",
+			OnError: true,
+			Config:  map[string]interface{}{"to": email},
+		})
+	}
+	return channels
+}
+
+// GetAutomationOwners returns the owners of an automation, labelled for display
+func (s *automationService) GetAutomationOwners(ctx context.Context, automationID string) ([]AutomationOwner, error) {
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	owners, err := s.automationRepo.GetAutomationOwners(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation owners", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation owners: %w", err)
+	}
+	directory, err := s.automationRepo.GetOwnerDirectory(ctx, automation.ProjectID)
+	if err != nil {
+		slog.Error("Failed to get owner directory", "error", err, "projectID", automation.ProjectID)
+		return nil, fmt.Errorf("failed to get owner directory: %w", err)
+	}
+
+	labelled := make([]AutomationOwner, len(owners))
+	for i, owner := range owners {
+		labelled[i] = directory.label(owner)
+	}
+	return labelled, nil
+}
+
+// SetAutomationOwners replaces the owners of an automation with users of its organization and
+// teams from the organization's settings
+func (s *automationService) SetAutomationOwners(ctx context.Context, automation *Automation, owners []AutomationOwner) ([]AutomationOwner, error) {
+	directory, err := s.automationRepo.GetOwnerDirectory(ctx, automation.ProjectID)
+	if err != nil {
+		slog.Error("Failed to get owner directory", "error", err, "projectID", automation.ProjectID)
+		return nil, fmt.Errorf("failed to get owner directory: %w", err)
+	}
+	owners, err = validateAutomationOwners(owners, directory)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := NewAutomationRepository(tx).ReplaceAutomationOwners(ctx, automation.ID, owners); err != nil {
+		slog.Error("Failed to set automation owners", "error", err, "automationID", automation.ID)
+		return nil, fmt.Errorf("failed to set automation owners: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i, owner := range owners {
+		owners[i] = directory.label(owner)
+	}
+	slog.Info("Automation owners updated", "automationID", automation.ID, "owners", len(owners))
+	return owners, nil
+}
+
+// GetOwnerCandidates lists who can own a project's automations: the users of its organization by
+// email, then its teams by name
+func (s *automationService) GetOwnerCandidates(ctx context.Context, projectID string) ([]AutomationOwner, error) {
+	directory, err := s.automationRepo.GetOwnerDirectory(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get owner directory", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get owner directory: %w", err)
+	}
+
+	users := make([]AutomationOwner, 0, len(directory.Users))
+	for id := range directory.Users {
+		users = append(users, directory.label(AutomationOwner{Type: OwnerTypeUser, ID: id}))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Label < users[j].Label })
+
+	teams := make([]AutomationOwner, 0, len(directory.Teams))
+	for name := range directory.Teams {
+		teams = append(teams, directory.label(AutomationOwner{Type: OwnerTypeTeam, ID: name}))
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Label < teams[j].Label })
+
+	return append(users, teams...), nil
+}
+
+// GetProjectAutomationOwners returns the labelled owners of a project's automations, keyed by
+// automation ID
+func (s *automationService) GetProjectAutomationOwners(ctx context.Context, projectID string) (map[string][]AutomationOwner, error) {
+	owners, err := s.automationRepo.GetAutomationOwnersByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get automation owners", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get automation owners: %w", err)
+	}
+	if len(owners) == 0 {
+		return owners, nil
+	}
+	directory, err := s.automationRepo.GetOwnerDirectory(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get owner directory", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get owner directory: %w", err)
+	}
+
+	for _, automationOwners := range owners {
+		for i, owner := range automationOwners {
+			automationOwners[i] = directory.label(owner)
+		}
+	}
+	return owners, nil
+}
+
+// GetOwnedAutomationIDs returns the automations of a project a user owns, directly or through
+// one of their teams
+func (s *automationService) GetOwnedAutomationIDs(ctx context.Context, projectID, userID string) (map[string]bool, error) {
+	owners, err := s.automationRepo.GetAutomationOwnersByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get automation owners", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get automation owners: %w", err)
+	}
+	directory, err := s.automationRepo.GetOwnerDirectory(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get owner directory", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get owner directory: %w", err)
+	}
+
+	owned := make(map[string]bool)
+	for automationID, automationOwners := range owners {
+		if directory.memberOf(automationOwners, userID) {
+			owned[automationID] = true
+		}
+	}
+	return owned, nil
+}
+
+// RequestOwnerReview asks the owners of an automation to review a change someone else made to
+// it. Further changes by the same editor within ownerReviewInterval are covered by the same
+// request. Emails are sent in the background.
+func (s *automationService) RequestOwnerReview(ctx context.Context, automationID, editorID, editorEmail, change string) {
+	if s.notificationService == nil {
+		return
+	}
+
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation", "error", err, "automationID", automationID)
+		return
+	}
+	owners, err := s.automationRepo.GetAutomationOwners(ctx, automation.ID)
+	if err != nil {
+		slog.Error("Failed to get automation owners", "error", err, "automationID", automation.ID)
+		return
+	}
+	if len(owners) == 0 {
+		return
+	}
+	directory, err := s.automationRepo.GetOwnerDirectory(ctx, automation.ProjectID)
+	if err != nil {
+		slog.Error("Failed to get owner directory", "error", err, "projectID", automation.ProjectID)
+		return
+	}
+	recipients := directory.emails(owners, editorID)
+	if len(recipients) == 0 {
+		return
+	}
+
+	key := automation.ID + ":" + editorID
+	s.reviewMu.Lock()
+	if last, ok := s.reviewRequestedAt[key]; ok && time.Since(last) < ownerReviewInterval {
+		s.reviewMu.Unlock()
+		return
+	}
+	s.reviewRequestedAt[key] = time.Now()
+	s.reviewMu.Unlock()
+
+	automationURL := fmt.Sprintf("%s/projects/%s/automations/%s", platform.ENV_APP_URL, automation.ProjectID, automation.ID)
+	subject := fmt.Sprintf("Review requested: %s was changed", automation.Name)
+	content := fmt.Sprintf(`
+			<html>
+			<body>
+				<h2>Review requested for %s</h2>
+				<p><strong>%s</strong> made a change to an automation you own: %s.</p>
+				<p><a href="%s">Review the automation</a></p>
+				<p>Further changes they make in the next %d minutes won't send another request.</p>
+			</body>
+			</html>
+		`, html.EscapeString(automation.Name), html.EscapeString(editorEmail), html.EscapeString(change), automationURL, int(ownerReviewInterval.Minutes()))
+
+	go func(ctx context.Context) {
+		for _, to := range recipients {
+			if err := s.notificationService.SendMail(ctx, notification.MailData{To: to, Subject: subject, Content: content}); err != nil {
+				slog.Error("Failed to send owner review request", "error", err, "automationID", automation.ID, "email", to)
+			}
+		}
+	}(context.WithoutCancel(ctx))
+}
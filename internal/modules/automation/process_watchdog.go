@@ -0,0 +1,153 @@
+package automation
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables used to tag browser processes with the run and worker that launched them.
+// Browsers inherit them, so every renderer/helper process of a run carries the same tag.
+const (
+	runTagEnvKey    = "QPLAYGROUND_RUN_ID"
+	workerTagEnvKey = "QPLAYGROUND_WORKER_PID"
+)
+
+// Watchdog metrics, published via expvar under "playwright_watchdog"
+var watchdogMetrics = expvar.NewMap("playwright_watchdog")
+
+// taggedProcess is a browser process carrying a run tag
+type taggedProcess struct {
+	PID       int
+	PGID      int
+	RunID     string
+	WorkerPID int
+}
+
+// browserProcessEnv returns the environment for a browser launched by a run: the worker's own
+// environment plus the run tag, since a launch env replaces rather than extends the inherited one
+func browserProcessEnv(runID string) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			env[key] = value
+		}
+	}
+	env[runTagEnvKey] = runID
+	env[workerTagEnvKey] = strconv.Itoa(os.Getpid())
+	return env
+}
+
+// ProcessWatchdog periodically kills browser processes leaked by crashed or abandoned runs.
+// A process is considered leaked when its run is no longer active in this worker, or when the
+// worker that launched it has exited. Processes must be seen leaked on two consecutive sweeps
+// before being killed so runs that are mid-shutdown are left alone.
+type ProcessWatchdog struct {
+	isRunActive func(runID string) bool
+	interval    time.Duration
+	stopCh      chan struct{}
+	mu          sync.Mutex
+	suspects    map[int]bool
+}
+
+// NewProcessWatchdog creates a watchdog that uses isRunActive to decide which runs still own their processes
+func NewProcessWatchdog(isRunActive func(runID string) bool) *ProcessWatchdog {
+	return &ProcessWatchdog{
+		isRunActive: isRunActive,
+		interval:    1 * time.Minute,
+		stopCh:      make(chan struct{}),
+		suspects:    make(map[int]bool),
+	}
+}
+
+// Start begins the watchdog's background sweeps
+func (w *ProcessWatchdog) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+
+	slog.Info("Playwright process watchdog started", "interval", w.interval.String())
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.sweep()
+			case <-w.stopCh:
+				slog.Info("Playwright process watchdog stopped")
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the watchdog
+func (w *ProcessWatchdog) Stop() {
+	close(w.stopCh)
+}
+
+// sweep finds leaked browser processes and kills their process groups
+func (w *ProcessWatchdog) sweep() {
+	processes, err := findTaggedProcesses()
+	if err != nil {
+		slog.Error("Failed to list browser processes", "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	workerPID := os.Getpid()
+	suspects := make(map[int]bool)
+	killedGroups := make(map[int]bool)
+	leaked := 0
+
+	for _, process := range processes {
+		if process.WorkerPID == workerPID {
+			if w.isRunActive(process.RunID) {
+				continue
+			}
+		} else if processAlive(process.WorkerPID) {
+			// Owned by another live worker on this host
+			continue
+		}
+
+		leaked++
+		if !w.suspects[process.PID] {
+			suspects[process.PID] = true
+			continue
+		}
+		// Counted once, when confirmed leaked on its second sweep
+		watchdogMetrics.Add("leaked_processes_found", 1)
+
+		if killedGroups[process.PGID] {
+			continue
+		}
+		group, err := killLeakedProcess(process.PID, process.PGID)
+		if err != nil {
+			slog.Error("Failed to kill leaked browser process", "pid", process.PID, "pgid", process.PGID, "run_id", process.RunID, "error", err)
+			continue
+		}
+		if !group {
+			watchdogMetrics.Add("killed_processes", 1)
+			slog.Warn("Killed leaked browser process in the worker's process group", "pid", process.PID, "run_id", process.RunID)
+			continue
+		}
+		killedGroups[process.PGID] = true
+		watchdogMetrics.Add("killed_process_groups", 1)
+		slog.Warn("Killed leaked browser process group", "pgid", process.PGID, "run_id", process.RunID)
+	}
+
+	w.suspects = suspects
+	watchdogMetrics.Add("sweeps", 1)
+	leakedGauge := new(expvar.Int)
+	leakedGauge.Set(int64(leaked))
+	watchdogMetrics.Set("leaked_processes_last_sweep", leakedGauge)
+}
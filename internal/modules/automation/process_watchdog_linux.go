@@ -0,0 +1,74 @@
+//go:build linux
+
+package automation
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// findTaggedProcesses scans /proc for processes carrying a run tag in their environment
+func findTaggedProcesses() ([]taggedProcess, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []taggedProcess
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		// Processes owned by other users or already gone are skipped
+		environ, err := os.ReadFile("/proc/" + entry.Name() + "/environ")
+		if err != nil {
+			continue
+		}
+
+		process := taggedProcess{PID: pid}
+		for _, variable := range bytes.Split(environ, []byte{0}) {
+			key, value, ok := bytes.Cut(variable, []byte("="))
+			if !ok {
+				continue
+			}
+			switch string(key) {
+			case runTagEnvKey:
+				process.RunID = string(value)
+			case workerTagEnvKey:
+				process.WorkerPID, _ = strconv.Atoi(string(value))
+			}
+		}
+		if process.RunID == "" || process.WorkerPID == 0 {
+			continue
+		}
+
+		pgid, err := syscall.Getpgid(pid)
+		if err != nil {
+			continue
+		}
+		process.PGID = pgid
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// killLeakedProcess kills every process in a leaked process's group, e.g. a browser and its
+// renderers. A process in the worker's own group is killed alone, since the group would take
+// the worker with it. It reports whether the whole group was killed.
+func killLeakedProcess(pid, pgid int) (bool, error) {
+	if pgid == syscall.Getpgrp() {
+		return false, syscall.Kill(pid, syscall.SIGKILL)
+	}
+	return true, syscall.Kill(-pgid, syscall.SIGKILL)
+}
+
+// processAlive reports whether a process exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
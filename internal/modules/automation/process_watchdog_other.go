@@ -0,0 +1,17 @@
+//go:build !linux
+
+package automation
+
+// Leaked process detection relies on /proc and is only supported on Linux.
+
+func findTaggedProcesses() ([]taggedProcess, error) {
+	return nil, nil
+}
+
+func killLeakedProcess(pid, pgid int) (bool, error) {
+	return false, nil
+}
+
+func processAlive(pid int) bool {
+	return true
+}
@@ -0,0 +1,27 @@
+package automation
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
+)
+
+// reportLocale returns the locale an automation's notifications and reports are written in: the
+// automation's reportLocale, otherwise its organization's, otherwise English. A locale without
+// a bundle falls back to English message by message.
+func reportLocale(ctx context.Context, repo AutomationRepository, automation *Automation, automationConfig *AutomationConfig) string {
+	if automationConfig != nil && automationConfig.ReportLocale != "" {
+		return automationConfig.ReportLocale
+	}
+
+	locale, err := repo.GetOrganizationReportLocale(ctx, automation.ProjectID)
+	if err != nil {
+		slog.Error("Failed to get organization report locale", "project_id", automation.ProjectID, "error", err)
+		return i18n.DefaultLocale
+	}
+	if locale == "" {
+		return i18n.DefaultLocale
+	}
+	return locale
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/delordemm1/qplayground/internal/platform"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -339,6 +341,44 @@ func (r *automationRepository) GetActionsByStepID(ctx context.Context, stepID st
 	return actions, nil
 }
 
+// GetActionsByAutomationID returns the actions of all of an automation's steps in one query
+func (r *automationRepository) GetActionsByAutomationID(ctx context.Context, automationID string) ([]*AutomationAction, error) {
+	query, args, err := r.sq.Select("a.id", "a.step_id", "a.action_name", "a.action_type", "a.action_config_json", "a.action_order", "a.created_at", "a.updated_at").
+		From("automation_actions a").
+		Join("automation_steps s ON s.id = a.step_id").
+		Where(sq.Eq{"s.automation_id": automationID}).
+		OrderBy("s.step_order ASC", "a.action_order ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*AutomationAction
+	for rows.Next() {
+		var action AutomationAction
+		var createdAt, updatedAt pgtype.Timestamp
+		var actionName pgtype.Text
+		err := rows.Scan(&action.ID, &action.StepID, &actionName, &action.ActionType, &action.ActionConfigJSON, &action.ActionOrder, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan action: %w", err)
+		}
+		if actionName.Valid {
+			action.Name = actionName.String
+		}
+		action.CreatedAt = createdAt.Time
+		action.UpdatedAt = updatedAt.Time
+		actions = append(actions, &action)
+	}
+
+	return actions, rows.Err()
+}
+
 func (r *automationRepository) UpdateAction(ctx context.Context, action *AutomationAction) error {
 	query, args, err := r.sq.Update("automation_actions").
 		Set("action_name", action.Name).
@@ -379,21 +419,30 @@ func (r *automationRepository) DeleteAction(ctx context.Context, id string) erro
 	return nil
 }
 
+// runCreatedAt is the creation time a run is inserted with: the one its name was rendered with,
+// if any
+func runCreatedAt(run *AutomationRun) time.Time {
+	if run.CreatedAt.IsZero() {
+		return time.Now()
+	}
+	return run.CreatedAt
+}
+
 // Run CRUD
 func (r *automationRepository) CreateRun(ctx context.Context, run *AutomationRun) error {
 	query, args, err := r.sq.Insert("automation_runs").
-		Columns("id", "automation_id", "status", "logs_json", "output_files_json", "error_message").
-		Values(run.ID, run.AutomationID, run.Status, run.LogsJSON, run.OutputFilesJSON, run.ErrorMessage).
-		Suffix("RETURNING id, automation_id, status, start_time, end_time, logs_json, output_files_json, error_message, created_at, updated_at").
+		Columns("id", "automation_id", "status", "logs_json", "output_files_json", "error_message", "include_tags", "exclude_tags", "locale", "parent_run_id", "attempt", "triggered_by_user_id", "sequence", "display_name", "created_at").
+		Values(run.ID, run.AutomationID, run.Status, run.LogsJSON, run.OutputFilesJSON, run.ErrorMessage, normalizeTags(run.IncludeTags), normalizeTags(run.ExcludeTags), run.Locale, platform.UtilStrPtr(run.ParentRunID), max(run.Attempt, 1), platform.UtilStrPtr(run.TriggeredByUserID), run.Sequence, run.DisplayName, runCreatedAt(run)).
+		Suffix("RETURNING id, automation_id, status, start_time, end_time, logs_json, output_files_json, error_message, include_tags, exclude_tags, locale, parent_run_id, attempt, created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
 	var createdAt, updatedAt, startTime, endTime pgtype.Timestamp
-	var logsJSON, outputFilesJSON, errorMessage pgtype.Text
+	var logsJSON, outputFilesJSON, errorMessage, parentRunID pgtype.Text
 	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &logsJSON, &outputFilesJSON, &errorMessage, &createdAt, &updatedAt,
+		&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &logsJSON, &outputFilesJSON, &errorMessage, &run.IncludeTags, &run.ExcludeTags, &run.Locale, &parentRunID, &run.Attempt, &createdAt, &updatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create run: %w", err)
@@ -414,13 +463,32 @@ func (r *automationRepository) CreateRun(ctx context.Context, run *AutomationRun
 	if errorMessage.Valid {
 		run.ErrorMessage = errorMessage.String
 	}
+	run.ParentRunID = parentRunID.String
 	run.CreatedAt = createdAt.Time
 	run.UpdatedAt = updatedAt.Time
 	return nil
 }
 
+// NextRunSequence takes the number of an automation's next run
+func (r *automationRepository) NextRunSequence(ctx context.Context, automationID string) (int, error) {
+	query, args, err := r.sq.Update("automations").
+		Set("run_sequence", sq.Expr("run_sequence + 1")).
+		Where(sq.Eq{"id": automationID}).
+		Suffix("RETURNING run_sequence").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var sequence int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&sequence); err != nil {
+		return 0, fmt.Errorf("failed to take run sequence: %w", err)
+	}
+	return sequence, nil
+}
+
 func (r *automationRepository) GetRunByID(ctx context.Context, id string) (*AutomationRun, error) {
-	query, args, err := r.sq.Select("id", "automation_id", "status", "start_time", "end_time", "logs_json", "output_files_json", "error_message", "created_at", "updated_at").
+	query, args, err := r.sq.Select("id", "automation_id", "status", "start_time", "end_time", "logs_json", "output_files_json", "error_message", "include_tags", "exclude_tags", "locale", "parent_run_id", "attempt", "triggered_by_user_id", "sequence", "display_name", "created_at", "updated_at").
 		From("automation_runs").
 		Where(sq.Eq{"id": id}).
 		ToSql()
@@ -430,9 +498,9 @@ func (r *automationRepository) GetRunByID(ctx context.Context, id string) (*Auto
 
 	var run AutomationRun
 	var createdAt, updatedAt, startTime, endTime pgtype.Timestamp
-	var logsJSON, outputFilesJSON, errorMessage pgtype.Text
+	var logsJSON, outputFilesJSON, errorMessage, parentRunID, triggeredBy pgtype.Text
 	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &logsJSON, &outputFilesJSON, &errorMessage, &createdAt, &updatedAt,
+		&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &logsJSON, &outputFilesJSON, &errorMessage, &run.IncludeTags, &run.ExcludeTags, &run.Locale, &parentRunID, &run.Attempt, &triggeredBy, &run.Sequence, &run.DisplayName, &createdAt, &updatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -456,13 +524,15 @@ func (r *automationRepository) GetRunByID(ctx context.Context, id string) (*Auto
 	if errorMessage.Valid {
 		run.ErrorMessage = errorMessage.String
 	}
+	run.ParentRunID = parentRunID.String
+	run.TriggeredByUserID = triggeredBy.String
 	run.CreatedAt = createdAt.Time
 	run.UpdatedAt = updatedAt.Time
 	return &run, nil
 }
 
 func (r *automationRepository) GetRunsByAutomationID(ctx context.Context, automationID string) ([]*AutomationRun, error) {
-	query, args, err := r.sq.Select("id", "automation_id", "status", "start_time", "end_time", "logs_json", "output_files_json", "error_message", "created_at", "updated_at").
+	query, args, err := r.sq.Select("id", "automation_id", "status", "start_time", "end_time", "logs_json", "output_files_json", "error_message", "include_tags", "exclude_tags", "locale", "parent_run_id", "attempt", "triggered_by_user_id", "sequence", "display_name", "created_at", "updated_at").
 		From("automation_runs").
 		Where(sq.Eq{"automation_id": automationID}).
 		OrderBy("created_at DESC").
@@ -481,8 +551,8 @@ func (r *automationRepository) GetRunsByAutomationID(ctx context.Context, automa
 	for rows.Next() {
 		var run AutomationRun
 		var createdAt, updatedAt, startTime, endTime pgtype.Timestamp
-		var logsJSON, outputFilesJSON, errorMessage pgtype.Text
-		err := rows.Scan(&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &logsJSON, &outputFilesJSON, &errorMessage, &createdAt, &updatedAt)
+		var logsJSON, outputFilesJSON, errorMessage, parentRunID, triggeredBy pgtype.Text
+		err := rows.Scan(&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &logsJSON, &outputFilesJSON, &errorMessage, &run.IncludeTags, &run.ExcludeTags, &run.Locale, &parentRunID, &run.Attempt, &triggeredBy, &run.Sequence, &run.DisplayName, &createdAt, &updatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan run: %w", err)
 		}
@@ -501,6 +571,8 @@ func (r *automationRepository) GetRunsByAutomationID(ctx context.Context, automa
 		if errorMessage.Valid {
 			run.ErrorMessage = errorMessage.String
 		}
+		run.ParentRunID = parentRunID.String
+		run.TriggeredByUserID = triggeredBy.String
 		run.CreatedAt = createdAt.Time
 		run.UpdatedAt = updatedAt.Time
 		runs = append(runs, &run)
@@ -535,14 +607,13 @@ func (r *automationRepository) UpdateRun(ctx context.Context, run *AutomationRun
 	return nil
 }
 
-func (r *automationRepository) ShiftActionOrdersAfterDelete(ctx context.Context, stepID string, deletedOrder int) error {
-	query, args, err := r.sq.Update("automation_actions").
-		Set("action_order", sq.Expr("action_order - 1")).
+// UpdateRunProgress saves the logs and output files of a run in progress, leaving its status alone
+func (r *automationRepository) UpdateRunProgress(ctx context.Context, runID, logsJSON, outputFilesJSON string) error {
+	query, args, err := r.sq.Update("automation_runs").
+		Set("logs_json", logsJSON).
+		Set("output_files_json", outputFilesJSON).
 		Set("updated_at", time.Now()).
-		Where(sq.And{
-			sq.Eq{"step_id": stepID},
-			sq.Gt{"action_order": deletedOrder},
-		}).
+		Where(sq.Eq{"id": runID}).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build query: %w", err)
@@ -550,255 +621,3237 @@ func (r *automationRepository) ShiftActionOrdersAfterDelete(ctx context.Context,
 
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to shift action orders after delete: %w", err)
+		return fmt.Errorf("failed to update run progress: %w", err)
 	}
 
 	return nil
 }
 
-// Order management methods
-func (r *automationRepository) GetStepByID(ctx context.Context, id string) (*AutomationStep, error) {
-	query, args, err := r.sq.Select("id", "automation_id", "name", "step_order", "config_json", "created_at", "updated_at").
-		From("automation_steps").
-		Where(sq.Eq{"id": id}).
+// SetRunFacets records what a finished run is filtered by: the steps actions failed in and the
+// tags of the steps and actions it executed
+func (r *automationRepository) SetRunFacets(ctx context.Context, runID string, failedSteps, tags []string) error {
+	query, args, err := r.sq.Update("automation_runs").
+		Set("failed_steps", failedSteps).
+		Set("tags", tags).
+		Where(sq.Eq{"id": runID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to set run facets: %w", err)
+	}
+	return nil
+}
+
+// TransitionRunStatus changes a run's status only while it is fromStatus, so a run that finished
+// meanwhile keeps its final status. It reports whether the status changed.
+func (r *automationRepository) TransitionRunStatus(ctx context.Context, runID, fromStatus, toStatus string) (bool, error) {
+	query, args, err := r.sq.Update("automation_runs").
+		Set("status", toStatus).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": runID, "status": fromStatus}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update run status: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// GetAllAutomations returns every automation; used by maintenance passes such as config upgrades
+func (r *automationRepository) GetAllAutomations(ctx context.Context) ([]*Automation, error) {
+	query, args, err := r.sq.Select("id", "project_id", "name", "description", "config_json", "created_at", "updated_at").
+		From("automations").
+		OrderBy("created_at ASC").
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var step AutomationStep
-	var createdAt, updatedAt pgtype.Timestamp
-	var configJSON pgtype.Text
-	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&step.ID, &step.AutomationID, &step.Name, &step.StepOrder, &configJSON, &createdAt, &updatedAt,
-	)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("step not found")
-		}
-		return nil, fmt.Errorf("failed to get step: %w", err)
+		return nil, fmt.Errorf("failed to query automations: %w", err)
 	}
+	defer rows.Close()
 
-	if configJSON.Valid {
-		step.ConfigJSON = configJSON.String
+	var automations []*Automation
+	for rows.Next() {
+		var automation Automation
+		var createdAt, updatedAt pgtype.Timestamp
+		var description, configJSON pgtype.Text
+		err := rows.Scan(&automation.ID, &automation.ProjectID, &automation.Name, &description, &configJSON, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automation: %w", err)
+		}
+		if description.Valid {
+			automation.Description = description.String
+		}
+		if configJSON.Valid {
+			automation.ConfigJSON = configJSON.String
+		}
+		automation.CreatedAt = createdAt.Time
+		automation.UpdatedAt = updatedAt.Time
+		automations = append(automations, &automation)
 	}
-	step.CreatedAt = createdAt.Time
-	step.UpdatedAt = updatedAt.Time
-	return &step, nil
+
+	return automations, nil
 }
 
-func (r *automationRepository) GetActionByID(ctx context.Context, id string) (*AutomationAction, error) {
+// GetAllActions returns every action; used by maintenance passes such as config upgrades
+func (r *automationRepository) GetAllActions(ctx context.Context) ([]*AutomationAction, error) {
 	query, args, err := r.sq.Select("id", "step_id", "action_name", "action_type", "action_config_json", "action_order", "created_at", "updated_at").
 		From("automation_actions").
-		Where(sq.Eq{"id": id}).
+		OrderBy("created_at ASC").
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var action AutomationAction
-	var createdAt, updatedAt pgtype.Timestamp
-	var actionName pgtype.Text
-	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&action.ID, &action.StepID, &actionName, &action.ActionType, &action.ActionConfigJSON, &action.ActionOrder, &createdAt, &updatedAt,
-	)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("action not found")
-		}
-		return nil, fmt.Errorf("failed to get action: %w", err)
+		return nil, fmt.Errorf("failed to query actions: %w", err)
 	}
+	defer rows.Close()
 
-	if actionName.Valid {
-		action.Name = actionName.String
+	var actions []*AutomationAction
+	for rows.Next() {
+		var action AutomationAction
+		var createdAt, updatedAt pgtype.Timestamp
+		var actionName pgtype.Text
+		err := rows.Scan(&action.ID, &action.StepID, &actionName, &action.ActionType, &action.ActionConfigJSON, &action.ActionOrder, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan action: %w", err)
+		}
+		if actionName.Valid {
+			action.Name = actionName.String
+		}
+		action.CreatedAt = createdAt.Time
+		action.UpdatedAt = updatedAt.Time
+		actions = append(actions, &action)
 	}
-	action.CreatedAt = createdAt.Time
-	action.UpdatedAt = updatedAt.Time
-	return &action, nil
+
+	return actions, nil
 }
 
-func (r *automationRepository) GetMaxStepOrder(ctx context.Context, automationID string) (int, error) {
-	query, args, err := r.sq.Select("COALESCE(MAX(step_order), 0)").
-		From("automation_steps").
-		Where(sq.Eq{"automation_id": automationID}).
+// DeleteExpiredRuns removes finished runs older than their retention period.
+// An automation's retentionDays overrides its organization's runRetentionDays; 0 keeps runs forever.
+// Pinned runs are always kept.
+func (r *automationRepository) DeleteExpiredRuns(ctx context.Context) (int64, error) {
+	query, args, err := r.sq.Delete("automation_runs").
+		Where(sq.Expr(`id IN (
+			SELECT ar.id FROM automation_runs ar
+			JOIN automations a ON a.id = ar.automation_id
+			JOIN projects p ON p.id = a.project_id
+			JOIN organizations o ON o.id = p.organization_id
+			CROSS JOIN LATERAL (
+				SELECT COALESCE(
+					NULLIF((a.config_json->>'retentionDays')::int, 0),
+					NULLIF((o.settings_json->>'runRetentionDays')::int, 0),
+					0
+				) AS days
+			) retention
+			WHERE retention.days > 0
+			AND ar.status IN ('completed', 'failed', 'cancelled')
+			AND ar.created_at < now() - make_interval(days => retention.days)
+			AND NOT EXISTS (SELECT 1 FROM automation_run_pins pin WHERE pin.run_id = ar.id)
+		)`)).
 		ToSql()
 	if err != nil {
 		return 0, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var maxOrder int
-	err = r.db.QueryRow(ctx, query, args...).Scan(&maxOrder)
+	tag, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get max step order: %w", err)
+		return 0, fmt.Errorf("failed to delete expired runs: %w", err)
 	}
 
-	return maxOrder, nil
+	return tag.RowsAffected(), nil
 }
 
-func (r *automationRepository) GetMaxActionOrder(ctx context.Context, stepID string) (int, error) {
-	query, args, err := r.sq.Select("COALESCE(MAX(action_order), 0)").
-		From("automation_actions").
-		Where(sq.Eq{"step_id": stepID}).
+// GetQueuedRuns returns the runs among runIDs that are still pending or queued, oldest first
+func (r *automationRepository) GetQueuedRuns(ctx context.Context, runIDs []string) ([]*QueuedRun, error) {
+	query, args, err := r.sq.Select("ar.id", "ar.automation_id", "a.project_id", "p.organization_id", "ar.created_at").
+		From("automation_runs ar").
+		Join("automations a ON a.id = ar.automation_id").
+		Join("projects p ON p.id = a.project_id").
+		Where(sq.Eq{"ar.id": runIDs, "ar.status": []string{"pending", "queued"}}).
+		OrderBy("ar.created_at ASC").
 		ToSql()
 	if err != nil {
-		return 0, fmt.Errorf("failed to build query: %w", err)
+		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var maxOrder int
-	err = r.db.QueryRow(ctx, query, args...).Scan(&maxOrder)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get max action order: %w", err)
+		return nil, fmt.Errorf("failed to query queued runs: %w", err)
 	}
+	defer rows.Close()
 
-	return maxOrder, nil
+	var runs []*QueuedRun
+	for rows.Next() {
+		var run QueuedRun
+		var createdAt pgtype.Timestamp
+		if err := rows.Scan(&run.RunID, &run.AutomationID, &run.ProjectID, &run.OrganizationID, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan queued run: %w", err)
+		}
+		run.CreatedAt = createdAt.Time
+		runs = append(runs, &run)
+	}
+
+	return runs, nil
 }
 
-// GetStepByAutomationIDAndOrder retrieves a step by automation ID and order
-func (r *automationRepository) GetStepByAutomationIDAndOrder(ctx context.Context, automationID string, order int) (*AutomationStep, error) {
-	query, args, err := r.sq.Select("id", "automation_id", "name", "step_order", "config_json", "created_at", "updated_at").
-		From("automation_steps").
-		Where(sq.And{
-			sq.Eq{"automation_id": automationID},
-			sq.Eq{"step_order": order},
-		}).
+// Run share CRUD
+func (r *automationRepository) CreateRunShare(ctx context.Context, share *RunShare) error {
+	query, args, err := r.sq.Insert("automation_run_shares").
+		Columns("id", "run_id", "token", "created_by_user_id", "expires_at").
+		Values(share.ID, share.RunID, share.Token, platform.UtilStrPtr(share.CreatedByUserID), share.ExpiresAt).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to create run share: %w", err)
+	}
+
+	share.CreatedAt = createdAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetRunShareByToken(ctx context.Context, token string) (*RunShare, error) {
+	query, args, err := r.sq.Select("id", "run_id", "token", "created_by_user_id", "expires_at", "revoked_at", "created_at").
+		From("automation_run_shares").
+		Where(sq.Eq{"token": token}).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var step AutomationStep
-	var createdAt, updatedAt pgtype.Timestamp
-	var configJSON pgtype.Text
-	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&step.ID, &step.AutomationID, &step.Name, &step.StepOrder, &configJSON, &createdAt, &updatedAt,
-	)
+	share, err := scanRunShare(r.db.QueryRow(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("step not found")
+			return nil, fmt.Errorf("run share not found")
 		}
-		return nil, fmt.Errorf("failed to get step: %w", err)
+		return nil, fmt.Errorf("failed to get run share: %w", err)
 	}
 
-	if configJSON.Valid {
-		step.ConfigJSON = configJSON.String
-	}
-	step.CreatedAt = createdAt.Time
-	step.UpdatedAt = updatedAt.Time
-	return &step, nil
+	return share, nil
 }
 
-// GetActionByStepIDAndOrder retrieves an action by step ID and order
-func (r *automationRepository) GetActionByStepIDAndOrder(ctx context.Context, stepID string, order int) (*AutomationAction, error) {
-	query, args, err := r.sq.Select("id", "step_id", "action_name", "action_type", "action_config_json", "action_order", "created_at", "updated_at").
-		From("automation_actions").
-		Where(sq.And{
-			sq.Eq{"step_id": stepID},
-			sq.Eq{"action_order": order},
-		}).
+func (r *automationRepository) GetRunSharesByRunID(ctx context.Context, runID string) ([]*RunShare, error) {
+	query, args, err := r.sq.Select("id", "run_id", "token", "created_by_user_id", "expires_at", "revoked_at", "created_at").
+		From("automation_run_shares").
+		Where(sq.Eq{"run_id": runID}).
+		OrderBy("created_at DESC").
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var action AutomationAction
-	var createdAt, updatedAt pgtype.Timestamp
-	var actionName pgtype.Text
-	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&action.ID, &action.StepID, &actionName, &action.ActionType, &action.ActionConfigJSON, &action.ActionOrder, &createdAt, &updatedAt,
-	)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("action not found")
-		}
-		return nil, fmt.Errorf("failed to get action: %w", err)
+		return nil, fmt.Errorf("failed to query run shares: %w", err)
 	}
+	defer rows.Close()
 
-	if actionName.Valid {
-		action.Name = actionName.String
+	var shares []*RunShare
+	for rows.Next() {
+		share, err := scanRunShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run share: %w", err)
+		}
+		shares = append(shares, share)
 	}
-	action.CreatedAt = createdAt.Time
-	action.UpdatedAt = updatedAt.Time
-	return &action, nil
+
+	return shares, nil
 }
 
-func (r *automationRepository) ShiftStepOrders(ctx context.Context, automationID string, startOrder, endOrder int, increment bool) error {
-	var query string
-	var args []interface{}
-	var err error
+func (r *automationRepository) RevokeRunShare(ctx context.Context, id string) error {
+	query, args, err := r.sq.Update("automation_run_shares").
+		Set("revoked_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
 
-	if increment {
-		// Shift orders up (increase by 1)
-		query, args, err = r.sq.Update("automation_steps").
-			Set("step_order", sq.Expr("step_order + 1")).
-			Set("updated_at", time.Now()).
-			Where(sq.And{
-				sq.Eq{"automation_id": automationID},
-				sq.GtOrEq{"step_order": startOrder},
-				sq.LtOrEq{"step_order": endOrder},
-			}).
-			ToSql()
-	} else {
-		// Shift orders down (decrease by 1)
-		query, args, err = r.sq.Update("automation_steps").
-			Set("step_order", sq.Expr("step_order - 1")).
-			Set("updated_at", time.Now()).
-			Where(sq.And{
-				sq.Eq{"automation_id": automationID},
-				sq.GtOrEq{"step_order": startOrder},
-				sq.LtOrEq{"step_order": endOrder},
-			}).
-			ToSql()
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to revoke run share: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecentRunsByAutomationID returns the most recent runs without their logs or output files
+func (r *automationRepository) GetRecentRunsByAutomationID(ctx context.Context, automationID string, limit int) ([]*AutomationRun, error) {
+	query, args, err := r.sq.Select("id", "automation_id", "status", "start_time", "end_time", "error_message", "created_at", "updated_at").
+		From("automation_runs").
+		Where(sq.Eq{"automation_id": automationID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*AutomationRun
+	for rows.Next() {
+		var run AutomationRun
+		var createdAt, updatedAt, startTime, endTime pgtype.Timestamp
+		var errorMessage pgtype.Text
+		err := rows.Scan(&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &errorMessage, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		if startTime.Valid {
+			run.StartTime = &startTime.Time
+		}
+		if endTime.Valid {
+			run.EndTime = &endTime.Time
+		}
+		if errorMessage.Valid {
+			run.ErrorMessage = errorMessage.String
+		}
+		run.CreatedAt = createdAt.Time
+		run.UpdatedAt = updatedAt.Time
+		runs = append(runs, &run)
+	}
+
+	return runs, nil
+}
+
+// Automation embed CRUD
+func (r *automationRepository) CreateAutomationEmbed(ctx context.Context, embed *AutomationEmbed) error {
+	query, args, err := r.sq.Insert("automation_embeds").
+		Columns("id", "automation_id", "token", "created_by_user_id").
+		Values(embed.ID, embed.AutomationID, embed.Token, platform.UtilStrPtr(embed.CreatedByUserID)).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to create automation embed: %w", err)
+	}
+
+	embed.CreatedAt = createdAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetAutomationEmbedByToken(ctx context.Context, token string) (*AutomationEmbed, error) {
+	query, args, err := r.sq.Select("id", "automation_id", "token", "created_by_user_id", "revoked_at", "created_at").
+		From("automation_embeds").
+		Where(sq.Eq{"token": token}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	embed, err := scanAutomationEmbed(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("automation embed not found")
+		}
+		return nil, fmt.Errorf("failed to get automation embed: %w", err)
+	}
+
+	return embed, nil
+}
+
+func (r *automationRepository) GetAutomationEmbedsByAutomationID(ctx context.Context, automationID string) ([]*AutomationEmbed, error) {
+	query, args, err := r.sq.Select("id", "automation_id", "token", "created_by_user_id", "revoked_at", "created_at").
+		From("automation_embeds").
+		Where(sq.Eq{"automation_id": automationID}).
+		OrderBy("created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation embeds: %w", err)
 	}
+	defer rows.Close()
+
+	var embeds []*AutomationEmbed
+	for rows.Next() {
+		embed, err := scanAutomationEmbed(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automation embed: %w", err)
+		}
+		embeds = append(embeds, embed)
+	}
+
+	return embeds, nil
+}
 
+func (r *automationRepository) RevokeAutomationEmbed(ctx context.Context, id string) error {
+	query, args, err := r.sq.Update("automation_embeds").
+		Set("revoked_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to shift step orders: %w", err)
+		return fmt.Errorf("failed to revoke automation embed: %w", err)
 	}
 
 	return nil
 }
 
-func (r *automationRepository) ShiftActionOrders(ctx context.Context, stepID string, startOrder, endOrder int, increment bool) error {
-	var query string
-	var args []interface{}
-	var err error
+// scanAutomationEmbed scans a single automation embed row
+func scanAutomationEmbed(row pgx.Row) (*AutomationEmbed, error) {
+	var embed AutomationEmbed
+	var createdByUserID pgtype.Text
+	var revokedAt, createdAt pgtype.Timestamp
+	err := row.Scan(&embed.ID, &embed.AutomationID, &embed.Token, &createdByUserID, &revokedAt, &createdAt)
+	if err != nil {
+		return nil, err
+	}
 
-	if increment {
-		// Shift orders up (increase by 1)
-		query, args, err = r.sq.Update("automation_actions").
-			Set("action_order", sq.Expr("action_order + 1")).
-			Set("updated_at", time.Now()).
-			Where(sq.And{
-				sq.Eq{"step_id": stepID},
-				sq.GtOrEq{"action_order": startOrder},
-				sq.LtOrEq{"action_order": endOrder},
-			}).
-			ToSql()
-	} else {
-		// Shift orders down (decrease by 1)
-		query, args, err = r.sq.Update("automation_actions").
-			Set("action_order", sq.Expr("action_order - 1")).
-			Set("updated_at", time.Now()).
-			Where(sq.And{
-				sq.Eq{"step_id": stepID},
-				sq.GtOrEq{"action_order": startOrder},
-				sq.LtOrEq{"action_order": endOrder},
-			}).
-			ToSql()
+	if createdByUserID.Valid {
+		embed.CreatedByUserID = createdByUserID.String
+	}
+	if revokedAt.Valid {
+		embed.RevokedAt = &revokedAt.Time
 	}
+	embed.CreatedAt = createdAt.Time
+	return &embed, nil
+}
+
+// scanRunShare scans a single run share row
+func scanRunShare(row pgx.Row) (*RunShare, error) {
+	var share RunShare
+	var createdByUserID pgtype.Text
+	var expiresAt, revokedAt, createdAt pgtype.Timestamp
+	err := row.Scan(&share.ID, &share.RunID, &share.Token, &createdByUserID, &expiresAt, &revokedAt, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdByUserID.Valid {
+		share.CreatedByUserID = createdByUserID.String
+	}
+	if revokedAt.Valid {
+		share.RevokedAt = &revokedAt.Time
+	}
+	share.ExpiresAt = expiresAt.Time
+	share.CreatedAt = createdAt.Time
+	return &share, nil
+}
 
+func (r *automationRepository) ShiftActionOrdersAfterDelete(ctx context.Context, stepID string, deletedOrder int) error {
+	query, args, err := r.sq.Update("automation_actions").
+		Set("action_order", sq.Expr("action_order - 1")).
+		Set("updated_at", time.Now()).
+		Where(sq.And{
+			sq.Eq{"step_id": stepID},
+			sq.Gt{"action_order": deletedOrder},
+		}).
+		ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to shift action orders: %w", err)
+		return fmt.Errorf("failed to shift action orders after delete: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Order management methods
+func (r *automationRepository) GetStepByID(ctx context.Context, id string) (*AutomationStep, error) {
+	query, args, err := r.sq.Select("id", "automation_id", "name", "step_order", "config_json", "created_at", "updated_at").
+		From("automation_steps").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var step AutomationStep
+	var createdAt, updatedAt pgtype.Timestamp
+	var configJSON pgtype.Text
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&step.ID, &step.AutomationID, &step.Name, &step.StepOrder, &configJSON, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("step not found")
+		}
+		return nil, fmt.Errorf("failed to get step: %w", err)
+	}
+
+	if configJSON.Valid {
+		step.ConfigJSON = configJSON.String
+	}
+	step.CreatedAt = createdAt.Time
+	step.UpdatedAt = updatedAt.Time
+	return &step, nil
+}
+
+func (r *automationRepository) GetActionByID(ctx context.Context, id string) (*AutomationAction, error) {
+	query, args, err := r.sq.Select("id", "step_id", "action_name", "action_type", "action_config_json", "action_order", "created_at", "updated_at").
+		From("automation_actions").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var action AutomationAction
+	var createdAt, updatedAt pgtype.Timestamp
+	var actionName pgtype.Text
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&action.ID, &action.StepID, &actionName, &action.ActionType, &action.ActionConfigJSON, &action.ActionOrder, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("action not found")
+		}
+		return nil, fmt.Errorf("failed to get action: %w", err)
+	}
+
+	if actionName.Valid {
+		action.Name = actionName.String
+	}
+	action.CreatedAt = createdAt.Time
+	action.UpdatedAt = updatedAt.Time
+	return &action, nil
+}
+
+func (r *automationRepository) GetMaxStepOrder(ctx context.Context, automationID string) (int, error) {
+	query, args, err := r.sq.Select("COALESCE(MAX(step_order), 0)").
+		From("automation_steps").
+		Where(sq.Eq{"automation_id": automationID}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var maxOrder int
+	err = r.db.QueryRow(ctx, query, args...).Scan(&maxOrder)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get max step order: %w", err)
+	}
+
+	return maxOrder, nil
+}
+
+func (r *automationRepository) GetMaxActionOrder(ctx context.Context, stepID string) (int, error) {
+	query, args, err := r.sq.Select("COALESCE(MAX(action_order), 0)").
+		From("automation_actions").
+		Where(sq.Eq{"step_id": stepID}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var maxOrder int
+	err = r.db.QueryRow(ctx, query, args...).Scan(&maxOrder)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get max action order: %w", err)
+	}
+
+	return maxOrder, nil
+}
+
+// GetStepByAutomationIDAndOrder retrieves a step by automation ID and order
+func (r *automationRepository) GetStepByAutomationIDAndOrder(ctx context.Context, automationID string, order int) (*AutomationStep, error) {
+	query, args, err := r.sq.Select("id", "automation_id", "name", "step_order", "config_json", "created_at", "updated_at").
+		From("automation_steps").
+		Where(sq.And{
+			sq.Eq{"automation_id": automationID},
+			sq.Eq{"step_order": order},
+		}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var step AutomationStep
+	var createdAt, updatedAt pgtype.Timestamp
+	var configJSON pgtype.Text
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&step.ID, &step.AutomationID, &step.Name, &step.StepOrder, &configJSON, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("step not found")
+		}
+		return nil, fmt.Errorf("failed to get step: %w", err)
+	}
+
+	if configJSON.Valid {
+		step.ConfigJSON = configJSON.String
+	}
+	step.CreatedAt = createdAt.Time
+	step.UpdatedAt = updatedAt.Time
+	return &step, nil
+}
+
+// GetActionByStepIDAndOrder retrieves an action by step ID and order
+func (r *automationRepository) GetActionByStepIDAndOrder(ctx context.Context, stepID string, order int) (*AutomationAction, error) {
+	query, args, err := r.sq.Select("id", "step_id", "action_name", "action_type", "action_config_json", "action_order", "created_at", "updated_at").
+		From("automation_actions").
+		Where(sq.And{
+			sq.Eq{"step_id": stepID},
+			sq.Eq{"action_order": order},
+		}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var action AutomationAction
+	var createdAt, updatedAt pgtype.Timestamp
+	var actionName pgtype.Text
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&action.ID, &action.StepID, &actionName, &action.ActionType, &action.ActionConfigJSON, &action.ActionOrder, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("action not found")
+		}
+		return nil, fmt.Errorf("failed to get action: %w", err)
+	}
+
+	if actionName.Valid {
+		action.Name = actionName.String
+	}
+	action.CreatedAt = createdAt.Time
+	action.UpdatedAt = updatedAt.Time
+	return &action, nil
+}
+
+func (r *automationRepository) ShiftStepOrders(ctx context.Context, automationID string, startOrder, endOrder int, increment bool) error {
+	var query string
+	var args []interface{}
+	var err error
+
+	if increment {
+		// Shift orders up (increase by 1)
+		query, args, err = r.sq.Update("automation_steps").
+			Set("step_order", sq.Expr("step_order + 1")).
+			Set("updated_at", time.Now()).
+			Where(sq.And{
+				sq.Eq{"automation_id": automationID},
+				sq.GtOrEq{"step_order": startOrder},
+				sq.LtOrEq{"step_order": endOrder},
+			}).
+			ToSql()
+	} else {
+		// Shift orders down (decrease by 1)
+		query, args, err = r.sq.Update("automation_steps").
+			Set("step_order", sq.Expr("step_order - 1")).
+			Set("updated_at", time.Now()).
+			Where(sq.And{
+				sq.Eq{"automation_id": automationID},
+				sq.GtOrEq{"step_order": startOrder},
+				sq.LtOrEq{"step_order": endOrder},
+			}).
+			ToSql()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to shift step orders: %w", err)
+	}
+
+	return nil
+}
+
+func (r *automationRepository) ShiftActionOrders(ctx context.Context, stepID string, startOrder, endOrder int, increment bool) error {
+	var query string
+	var args []interface{}
+	var err error
+
+	if increment {
+		// Shift orders up (increase by 1)
+		query, args, err = r.sq.Update("automation_actions").
+			Set("action_order", sq.Expr("action_order + 1")).
+			Set("updated_at", time.Now()).
+			Where(sq.And{
+				sq.Eq{"step_id": stepID},
+				sq.GtOrEq{"action_order": startOrder},
+				sq.LtOrEq{"action_order": endOrder},
+			}).
+			ToSql()
+	} else {
+		// Shift orders down (decrease by 1)
+		query, args, err = r.sq.Update("automation_actions").
+			Set("action_order", sq.Expr("action_order - 1")).
+			Set("updated_at", time.Now()).
+			Where(sq.And{
+				sq.Eq{"step_id": stepID},
+				sq.GtOrEq{"action_order": startOrder},
+				sq.LtOrEq{"action_order": endOrder},
+			}).
+			ToSql()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to shift action orders: %w", err)
+	}
+
+	return nil
+}
+// GetRecentCompletedRuns returns the most recent completed runs of an automation, with their logs,
+// excluding excludeRunID
+func (r *automationRepository) GetRecentCompletedRuns(ctx context.Context, automationID, excludeRunID string, limit int) ([]*AutomationRun, error) {
+	query, args, err := r.sq.Select("id", "automation_id", "status", "start_time", "end_time", "logs_json", "created_at", "updated_at").
+		From("automation_runs").
+		Where(sq.Eq{"automation_id": automationID, "status": "completed"}).
+		Where(sq.NotEq{"id": excludeRunID}).
+		Where(sq.NotEq{"start_time": nil}).
+		Where(sq.NotEq{"end_time": nil}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*AutomationRun
+	for rows.Next() {
+		var run AutomationRun
+		var createdAt, updatedAt, startTime, endTime pgtype.Timestamp
+		var logsJSON pgtype.Text
+		err := rows.Scan(&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &logsJSON, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		if startTime.Valid {
+			run.StartTime = &startTime.Time
+		}
+		if endTime.Valid {
+			run.EndTime = &endTime.Time
+		}
+		if logsJSON.Valid {
+			run.LogsJSON = logsJSON.String
+		}
+		run.CreatedAt = createdAt.Time
+		run.UpdatedAt = updatedAt.Time
+		runs = append(runs, &run)
+	}
+
+	return runs, nil
+}
+
+func (r *automationRepository) CreateRunAnomaly(ctx context.Context, anomaly *RunAnomaly) error {
+	query, args, err := r.sq.Insert("automation_run_anomalies").
+		Columns("id", "run_id", "automation_id", "scope", "step_id", "step_name", "duration_ms",
+			"baseline_mean_ms", "baseline_stddev_ms", "deviation", "sample_size").
+		Values(anomaly.ID, anomaly.RunID, anomaly.AutomationID, anomaly.Scope, platform.UtilStrPtr(anomaly.StepID),
+			platform.UtilStrPtr(anomaly.StepName), anomaly.DurationMs, anomaly.BaselineMeanMs, anomaly.BaselineStdDevMs,
+			anomaly.Deviation, anomaly.SampleSize).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to create run anomaly: %w", err)
+	}
+
+	anomaly.CreatedAt = createdAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetRunAnomaliesByRunID(ctx context.Context, runID string) ([]*RunAnomaly, error) {
+	return r.queryRunAnomalies(ctx, r.sq.Select(runAnomalyColumns...).
+		From("automation_run_anomalies").
+		Where(sq.Eq{"run_id": runID}).
+		OrderBy("created_at ASC"))
+}
+
+func (r *automationRepository) GetRunAnomaliesByAutomationID(ctx context.Context, automationID string, limit int) ([]*RunAnomaly, error) {
+	return r.queryRunAnomalies(ctx, r.sq.Select(runAnomalyColumns...).
+		From("automation_run_anomalies").
+		Where(sq.Eq{"automation_id": automationID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)))
+}
+
+var runAnomalyColumns = []string{"id", "run_id", "automation_id", "scope", "step_id", "step_name", "duration_ms",
+	"baseline_mean_ms", "baseline_stddev_ms", "deviation", "sample_size", "created_at"}
+
+func (r *automationRepository) queryRunAnomalies(ctx context.Context, builder sq.SelectBuilder) ([]*RunAnomaly, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []*RunAnomaly
+	for rows.Next() {
+		var anomaly RunAnomaly
+		var stepID, stepName pgtype.Text
+		var createdAt pgtype.Timestamp
+		err := rows.Scan(&anomaly.ID, &anomaly.RunID, &anomaly.AutomationID, &anomaly.Scope, &stepID, &stepName,
+			&anomaly.DurationMs, &anomaly.BaselineMeanMs, &anomaly.BaselineStdDevMs, &anomaly.Deviation,
+			&anomaly.SampleSize, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run anomaly: %w", err)
+		}
+		if stepID.Valid {
+			anomaly.StepID = stepID.String
+		}
+		if stepName.Valid {
+			anomaly.StepName = stepName.String
+		}
+		anomaly.CreatedAt = createdAt.Time
+		anomalies = append(anomalies, &anomaly)
+	}
+
+	return anomalies, nil
+}
+
+// CountRunsByBucket counts an automation's runs by status per day or hour of query's timezone
+func (r *automationRepository) CountRunsByBucket(ctx context.Context, automationID string, query RunHistoryQuery) ([]RunStatusCount, error) {
+	if query.Granularity != "day" && query.Granularity != "hour" {
+		return nil, fmt.Errorf("unsupported granularity: %s", query.Granularity)
+	}
+
+	sql, args, err := r.sq.Select().
+		Column(sq.Expr("date_trunc('"+query.Granularity+"', created_at AT TIME ZONE ?) AS bucket", query.Location.String())).
+		Columns("status", "COUNT(*)").
+		From("automation_runs").
+		Where(sq.Eq{"automation_id": automationID}).
+		Where(sq.GtOrEq{"created_at": query.From}).
+		Where(sq.Lt{"created_at": query.To}).
+		GroupBy("bucket", "status").
+		OrderBy("bucket ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count runs: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []RunStatusCount
+	for rows.Next() {
+		var count RunStatusCount
+		var bucket pgtype.Timestamp
+		if err := rows.Scan(&bucket, &count.Status, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan run count: %w", err)
+		}
+		count.Bucket = bucket.Time
+		counts = append(counts, count)
+	}
+
+	return counts, nil
+}
+
+// GetRuns lists the runs matching query, newest first. Logs are left out, since a page of runs
+// with their logs can be very large.
+func (r *automationRepository) GetRuns(ctx context.Context, query RunQuery) ([]*AutomationRun, error) {
+	builder := r.sq.Select("ar.id", "ar.automation_id", "ar.status", "ar.start_time", "ar.end_time", "ar.output_files_json", "ar.error_message",
+		"ar.include_tags", "ar.exclude_tags", "ar.locale", "ar.parent_run_id", "ar.attempt", "ar.triggered_by_user_id", "ar.sequence", "ar.display_name", "ar.created_at", "ar.updated_at").
+		From("automation_runs ar").
+		Join("automations a ON a.id = ar.automation_id").
+		Where(sq.Eq{"a.project_id": query.ProjectID}).
+		OrderBy("ar.created_at DESC").
+		Limit(uint64(query.Limit)).
+		Offset(uint64(query.Offset))
+	if query.AutomationID != "" {
+		builder = builder.Where(sq.Eq{"ar.automation_id": query.AutomationID})
+	}
+	if query.RunID != "" {
+		builder = builder.Where(sq.Eq{"ar.id": query.RunID})
+	}
+	builder, err := applyRunFilter(builder, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*AutomationRun
+	for rows.Next() {
+		var run AutomationRun
+		var createdAt, updatedAt, startTime, endTime pgtype.Timestamp
+		var outputFilesJSON, errorMessage, parentRunID, triggeredBy pgtype.Text
+		err := rows.Scan(&run.ID, &run.AutomationID, &run.Status, &startTime, &endTime, &outputFilesJSON, &errorMessage, &run.IncludeTags, &run.ExcludeTags, &run.Locale, &parentRunID, &run.Attempt, &triggeredBy, &run.Sequence, &run.DisplayName, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		run.StartTime = timestampPtr(startTime)
+		run.EndTime = timestampPtr(endTime)
+		run.OutputFilesJSON = outputFilesJSON.String
+		run.ErrorMessage = errorMessage.String
+		run.ParentRunID = parentRunID.String
+		run.TriggeredByUserID = triggeredBy.String
+		run.CreatedAt = createdAt.Time
+		run.UpdatedAt = updatedAt.Time
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+// applyRunFilter adds the conditions of a runs filter to a query of automation_runs ar joined with
+// automations a. The conditions are written to use the indexes of automation_runs.
+func applyRunFilter(builder sq.SelectBuilder, filter *RunFilter) (sq.SelectBuilder, error) {
+	if filter == nil {
+		return builder, nil
+	}
+	if len(filter.Statuses) > 0 {
+		builder = builder.Where(sq.Eq{"ar.status": filter.Statuses})
+	}
+	if len(filter.ExcludeStatuses) > 0 {
+		builder = builder.Where(sq.NotEq{"ar.status": filter.ExcludeStatuses})
+	}
+	if len(filter.Tags) > 0 {
+		builder = builder.Where("ar.tags && ?", filter.Tags)
+	}
+	if len(filter.ExcludeTags) > 0 {
+		builder = builder.Where("NOT (ar.tags && ?)", filter.ExcludeTags)
+	}
+	for _, duration := range filter.Durations {
+		switch duration.Op {
+		case ">", ">=", "<", "<=":
+		default:
+			return builder, fmt.Errorf("unsupported duration operator: %s", duration.Op)
+		}
+		builder = builder.Where("(ar.end_time - ar.start_time) "+duration.Op+" make_interval(secs => ?)", duration.Value.Seconds())
+	}
+	for _, created := range filter.Created {
+		if created.Op != ">=" && created.Op != "<" {
+			return builder, fmt.Errorf("unsupported created operator: %s", created.Op)
+		}
+		builder = builder.Where("ar.created_at "+created.Op+" ?", created.Value)
+	}
+	if len(filter.FailedSteps) > 0 {
+		builder = builder.Where("ar.failed_steps && ?", filter.FailedSteps)
+	}
+	triggeredBy := sq.Or{}
+	if len(filter.TriggeredByUserIDs) > 0 {
+		triggeredBy = append(triggeredBy, sq.Eq{"ar.triggered_by_user_id": filter.TriggeredByUserIDs})
+	}
+	if len(filter.TriggeredByEmails) > 0 {
+		triggeredBy = append(triggeredBy, sq.Expr("ar.triggered_by_user_id IN (SELECT id FROM users WHERE lower(email) = ANY(?))",
+			lowerStrings(filter.TriggeredByEmails)))
+	}
+	if filter.TriggeredByRetry {
+		triggeredBy = append(triggeredBy, sq.NotEq{"ar.parent_run_id": nil})
+	}
+	if len(triggeredBy) > 0 {
+		builder = builder.Where(triggeredBy)
+	}
+	if len(filter.Automations) > 0 {
+		builder = builder.Where("(a.id::text = ANY(?) OR a.name = ANY(?))", filter.Automations, filter.Automations)
+	}
+	return builder, nil
+}
+
+func lowerStrings(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
+
+// Datasets
+var datasetColumns = []string{"id", "project_id", "name", "source_url", "schema_json", "refresh_interval_minutes", "current_version", "last_refreshed_at", "last_refresh_error", "created_at", "updated_at"}
+
+func (r *automationRepository) CreateDataset(ctx context.Context, dataset *Dataset) error {
+	query, args, err := r.sq.Insert("datasets").
+		Columns("id", "project_id", "name", "source_url", "schema_json", "refresh_interval_minutes").
+		Values(dataset.ID, dataset.ProjectID, dataset.Name, dataset.SourceURL, dataset.SchemaJSON, dataset.RefreshIntervalMinutes).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt, updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&createdAt, &updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create dataset: %w", err)
+	}
+
+	dataset.CreatedAt = createdAt.Time
+	dataset.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetDatasetByID(ctx context.Context, id string) (*Dataset, error) {
+	return r.getDataset(ctx, sq.Eq{"id": id})
+}
+
+func (r *automationRepository) GetDatasetByName(ctx context.Context, projectID, name string) (*Dataset, error) {
+	return r.getDataset(ctx, sq.Eq{"project_id": projectID, "name": name})
+}
+
+func (r *automationRepository) getDataset(ctx context.Context, where sq.Eq) (*Dataset, error) {
+	query, args, err := r.sq.Select(datasetColumns...).
+		From("datasets").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	dataset, err := scanDataset(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("dataset not found")
+		}
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+
+	return dataset, nil
+}
+
+func (r *automationRepository) GetDatasetsByProjectID(ctx context.Context, projectID string) ([]*Dataset, error) {
+	query, args, err := r.sq.Select(datasetColumns...).
+		From("datasets").
+		Where(sq.Eq{"project_id": projectID}).
+		OrderBy("name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	return r.queryDatasets(ctx, query, args)
+}
+
+// GetDatasetsDueForRefresh returns datasets with a refresh interval whose last refresh attempt is
+// at least that long ago, or that were never refreshed
+func (r *automationRepository) GetDatasetsDueForRefresh(ctx context.Context) ([]*Dataset, error) {
+	query, args, err := r.sq.Select(datasetColumns...).
+		From("datasets").
+		Where(sq.Gt{"refresh_interval_minutes": 0}).
+		Where("(last_refreshed_at IS NULL OR last_refreshed_at + make_interval(mins => refresh_interval_minutes) <= NOW())").
+		OrderBy("last_refreshed_at ASC NULLS FIRST").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	return r.queryDatasets(ctx, query, args)
+}
+
+func (r *automationRepository) queryDatasets(ctx context.Context, query string, args []interface{}) ([]*Dataset, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query datasets: %w", err)
+	}
+	defer rows.Close()
+
+	var datasets []*Dataset
+	for rows.Next() {
+		dataset, err := scanDataset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dataset: %w", err)
+		}
+		datasets = append(datasets, dataset)
+	}
+
+	return datasets, nil
+}
+
+func (r *automationRepository) UpdateDataset(ctx context.Context, dataset *Dataset) error {
+	query, args, err := r.sq.Update("datasets").
+		Set("name", dataset.Name).
+		Set("source_url", dataset.SourceURL).
+		Set("schema_json", dataset.SchemaJSON).
+		Set("refresh_interval_minutes", dataset.RefreshIntervalMinutes).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": dataset.ID}).
+		Suffix("RETURNING updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update dataset: %w", err)
+	}
+
+	dataset.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+// UpdateDatasetRefreshResult records a refresh attempt; an empty refreshError marks it successful
+func (r *automationRepository) UpdateDatasetRefreshResult(ctx context.Context, id string, currentVersion int, refreshError string) error {
+	query, args, err := r.sq.Update("datasets").
+		Set("current_version", currentVersion).
+		Set("last_refreshed_at", time.Now()).
+		Set("last_refresh_error", platform.UtilStrPtr(refreshError)).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update dataset refresh result: %w", err)
+	}
+
+	return nil
+}
+
+func (r *automationRepository) DeleteDataset(ctx context.Context, id string) error {
+	query, args, err := r.sq.Delete("datasets").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete dataset: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDatasetVersion stores version.RecordsJSON as the dataset's next version number
+func (r *automationRepository) CreateDatasetVersion(ctx context.Context, version *DatasetVersion) error {
+	nextVersion := sq.Expr("(SELECT COALESCE(MAX(version), 0) + 1 FROM dataset_versions WHERE dataset_id = ?)", version.DatasetID)
+	query, args, err := r.sq.Insert("dataset_versions").
+		Columns("id", "dataset_id", "version", "records_json", "record_count", "checksum").
+		Values(version.ID, version.DatasetID, nextVersion, version.RecordsJSON, version.RecordCount, version.Checksum).
+		Suffix("RETURNING version, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&version.Version, &createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to create dataset version: %w", err)
+	}
+
+	version.CreatedAt = createdAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetDatasetVersion(ctx context.Context, datasetID string, version int) (*DatasetVersion, error) {
+	query, args, err := r.sq.Select("id", "dataset_id", "version", "records_json", "record_count", "checksum", "created_at").
+		From("dataset_versions").
+		Where(sq.Eq{"dataset_id": datasetID, "version": version}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var datasetVersion DatasetVersion
+	var createdAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&datasetVersion.ID, &datasetVersion.DatasetID, &datasetVersion.Version, &datasetVersion.RecordsJSON,
+		&datasetVersion.RecordCount, &datasetVersion.Checksum, &createdAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("dataset version not found")
+		}
+		return nil, fmt.Errorf("failed to get dataset version: %w", err)
+	}
+
+	datasetVersion.CreatedAt = createdAt.Time
+	return &datasetVersion, nil
+}
+
+// GetDatasetVersions returns a dataset's versions, newest first, without their records
+func (r *automationRepository) GetDatasetVersions(ctx context.Context, datasetID string) ([]*DatasetVersion, error) {
+	query, args, err := r.sq.Select("id", "dataset_id", "version", "record_count", "checksum", "created_at").
+		From("dataset_versions").
+		Where(sq.Eq{"dataset_id": datasetID}).
+		OrderBy("version DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dataset versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*DatasetVersion
+	for rows.Next() {
+		var version DatasetVersion
+		var createdAt pgtype.Timestamp
+		if err := rows.Scan(&version.ID, &version.DatasetID, &version.Version, &version.RecordCount, &version.Checksum, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dataset version: %w", err)
+		}
+		version.CreatedAt = createdAt.Time
+		versions = append(versions, &version)
+	}
+
+	return versions, nil
+}
+
+func (r *automationRepository) DeleteDatasetVersionsBefore(ctx context.Context, datasetID string, version int) error {
+	query, args, err := r.sq.Delete("dataset_versions").
+		Where(sq.Eq{"dataset_id": datasetID}).
+		Where(sq.Lt{"version": version}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete dataset versions: %w", err)
+	}
+
+	return nil
+}
+
+// scanDataset scans a single dataset row selected with datasetColumns
+func scanDataset(row pgx.Row) (*Dataset, error) {
+	var dataset Dataset
+	var lastRefreshedAt, createdAt, updatedAt pgtype.Timestamp
+	var lastRefreshError pgtype.Text
+	err := row.Scan(
+		&dataset.ID, &dataset.ProjectID, &dataset.Name, &dataset.SourceURL, &dataset.SchemaJSON,
+		&dataset.RefreshIntervalMinutes, &dataset.CurrentVersion, &lastRefreshedAt, &lastRefreshError,
+		&createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastRefreshedAt.Valid {
+		dataset.LastRefreshedAt = &lastRefreshedAt.Time
+	}
+	dataset.LastRefreshError = lastRefreshError.String
+	dataset.CreatedAt = createdAt.Time
+	dataset.UpdatedAt = updatedAt.Time
+	return &dataset, nil
+}
+
+// Account pools
+var poolAccountColumns = []string{"id", "pool_id", "label", "credentials", "state", "leased_by_run_id", "lease_loop_index", "leased_at", "lease_expires_at", "last_used_at", "created_at", "updated_at"}
+
+func (r *automationRepository) CreateAccountPool(ctx context.Context, pool *AccountPool) error {
+	query, args, err := r.sq.Insert("account_pools").
+		Columns("id", "project_id", "name", "environment", "lease_ttl_seconds").
+		Values(pool.ID, pool.ProjectID, pool.Name, pool.Environment, pool.LeaseTTLSeconds).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt, updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&createdAt, &updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create account pool: %w", err)
+	}
+
+	pool.CreatedAt = createdAt.Time
+	pool.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetAccountPoolByID(ctx context.Context, id string) (*AccountPool, error) {
+	pools, err := r.queryAccountPools(ctx, sq.Eq{"p.id": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("account pool not found")
+	}
+	return pools[0], nil
+}
+
+func (r *automationRepository) GetAccountPoolByName(ctx context.Context, projectID, environment, name string) (*AccountPool, error) {
+	pools, err := r.queryAccountPools(ctx, sq.Eq{"p.project_id": projectID, "p.environment": environment, "p.name": name})
+	if err != nil {
+		return nil, err
+	}
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("account pool not found")
+	}
+	return pools[0], nil
+}
+
+func (r *automationRepository) GetAccountPoolsByProjectID(ctx context.Context, projectID string) ([]*AccountPool, error) {
+	return r.queryAccountPools(ctx, sq.Eq{"p.project_id": projectID})
+}
+
+// queryAccountPools selects pools with their accounts counted by state
+func (r *automationRepository) queryAccountPools(ctx context.Context, where sq.Eq) ([]*AccountPool, error) {
+	query, args, err := r.sq.Select("p.id", "p.project_id", "p.name", "p.environment", "p.lease_ttl_seconds").
+		Column(sq.Expr("COUNT(a.id) FILTER (WHERE a.state = ?)", PoolAccountAvailable)).
+		Column(sq.Expr("COUNT(a.id) FILTER (WHERE a.state = ?)", PoolAccountLeased)).
+		Column(sq.Expr("COUNT(a.id) FILTER (WHERE a.state = ?)", PoolAccountDirty)).
+		Columns("p.created_at", "p.updated_at").
+		From("account_pools p").
+		LeftJoin("pool_accounts a ON a.pool_id = p.id").
+		Where(where).
+		GroupBy("p.id").
+		OrderBy("p.environment ASC", "p.name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account pools: %w", err)
+	}
+	defer rows.Close()
+
+	var pools []*AccountPool
+	for rows.Next() {
+		var pool AccountPool
+		var createdAt, updatedAt pgtype.Timestamp
+		err := rows.Scan(&pool.ID, &pool.ProjectID, &pool.Name, &pool.Environment, &pool.LeaseTTLSeconds,
+			&pool.AvailableCount, &pool.LeasedCount, &pool.DirtyCount, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account pool: %w", err)
+		}
+		pool.CreatedAt = createdAt.Time
+		pool.UpdatedAt = updatedAt.Time
+		pools = append(pools, &pool)
+	}
+
+	return pools, nil
+}
+
+func (r *automationRepository) DeleteAccountPool(ctx context.Context, id string) error {
+	query, args, err := r.sq.Delete("account_pools").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete account pool: %w", err)
+	}
+
+	return nil
+}
+
+func (r *automationRepository) CreatePoolAccount(ctx context.Context, account *PoolAccount) error {
+	query, args, err := r.sq.Insert("pool_accounts").
+		Columns("id", "pool_id", "label", "credentials").
+		Values(account.ID, account.PoolID, account.Label, account.Credentials).
+		Suffix("RETURNING state, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt, updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&account.State, &createdAt, &updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create pool account: %w", err)
+	}
+
+	account.CreatedAt = createdAt.Time
+	account.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetPoolAccounts(ctx context.Context, poolID string) ([]*PoolAccount, error) {
+	query, args, err := r.sq.Select(poolAccountColumns...).
+		From("pool_accounts").
+		Where(sq.Eq{"pool_id": poolID}).
+		OrderBy("label ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pool accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*PoolAccount
+	for rows.Next() {
+		account, err := scanPoolAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pool account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func (r *automationRepository) DeletePoolAccount(ctx context.Context, poolID, accountID string) error {
+	query, args, err := r.sq.Delete("pool_accounts").
+		Where(sq.Eq{"id": accountID, "pool_id": poolID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete pool account: %w", err)
+	}
+
+	return nil
+}
+
+// LeasePoolAccount leases the least recently used available account of a pool, or one whose
+// lease expired, to a user of a run. Concurrent leases skip each other's rows, so no account is
+// leased twice. It returns nil when every account is taken.
+func (r *automationRepository) LeasePoolAccount(ctx context.Context, poolID, runID string, loopIndex int, ttl time.Duration) (*PoolAccount, error) {
+	now := time.Now()
+	query, args, err := r.sq.Update("pool_accounts").
+		Set("state", PoolAccountLeased).
+		Set("leased_by_run_id", runID).
+		Set("lease_loop_index", loopIndex).
+		Set("leased_at", now).
+		Set("lease_expires_at", now.Add(ttl)).
+		Set("updated_at", now).
+		Where(sq.Expr(`id = (
+			SELECT id FROM pool_accounts
+			WHERE pool_id = ? AND (state = ? OR (state = ? AND lease_expires_at < ?))
+			ORDER BY last_used_at ASC NULLS FIRST, created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)`, poolID, PoolAccountAvailable, PoolAccountLeased, now)).
+		Suffix("RETURNING " + strings.Join(poolAccountColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	account, err := scanPoolAccount(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lease pool account: %w", err)
+	}
+
+	return account, nil
+}
+
+// ReleasePoolAccount ends one user's lease on an account, leaving it in state. It reports false
+// when the user doesn't hold the lease, e.g. because another user of the run leased it, or it
+// expired and was taken over.
+func (r *automationRepository) ReleasePoolAccount(ctx context.Context, accountID, runID string, loopIndex int, state string) (bool, error) {
+	query, args, err := r.releasePoolAccounts(state).
+		Where(sq.Eq{"id": accountID, "leased_by_run_id": runID, "lease_loop_index": loopIndex, "state": PoolAccountLeased}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to release pool account: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// ReleaseRunPoolAccounts ends every lease still held by one user of a run
+func (r *automationRepository) ReleaseRunPoolAccounts(ctx context.Context, runID string, loopIndex int, state string) (int64, error) {
+	query, args, err := r.releasePoolAccounts(state).
+		Where(sq.Eq{"leased_by_run_id": runID, "lease_loop_index": loopIndex, "state": PoolAccountLeased}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release run pool accounts: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ResetPoolAccount makes an account available whatever its state, e.g. after cleaning up a dirty one
+func (r *automationRepository) ResetPoolAccount(ctx context.Context, poolID, accountID string) error {
+	query, args, err := r.releasePoolAccounts(PoolAccountAvailable).
+		Where(sq.Eq{"id": accountID, "pool_id": poolID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to reset pool account: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("pool account not found")
+	}
+
+	return nil
+}
+
+// releasePoolAccounts clears the lease of the accounts it is narrowed to, leaving them in state
+func (r *automationRepository) releasePoolAccounts(state string) sq.UpdateBuilder {
+	now := time.Now()
+	return r.sq.Update("pool_accounts").
+		Set("state", state).
+		Set("leased_by_run_id", nil).
+		Set("lease_loop_index", nil).
+		Set("leased_at", nil).
+		Set("lease_expires_at", nil).
+		Set("last_used_at", now).
+		Set("updated_at", now)
+}
+
+// scanPoolAccount scans a single pool account row selected with poolAccountColumns
+func scanPoolAccount(row pgx.Row) (*PoolAccount, error) {
+	var account PoolAccount
+	var leasedByRunID pgtype.Text
+	var leaseLoopIndex pgtype.Int4
+	var leasedAt, leaseExpiresAt, lastUsedAt, createdAt, updatedAt pgtype.Timestamp
+	err := row.Scan(
+		&account.ID, &account.PoolID, &account.Label, &account.Credentials, &account.State,
+		&leasedByRunID, &leaseLoopIndex, &leasedAt, &leaseExpiresAt, &lastUsedAt, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	account.LeasedByRunID = leasedByRunID.String
+	if leaseLoopIndex.Valid {
+		loopIndex := int(leaseLoopIndex.Int32)
+		account.LeaseLoopIndex = &loopIndex
+	}
+	if leasedAt.Valid {
+		account.LeasedAt = &leasedAt.Time
+	}
+	if leaseExpiresAt.Valid {
+		account.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		account.LastUsedAt = &lastUsedAt.Time
+	}
+	account.CreatedAt = createdAt.Time
+	account.UpdatedAt = updatedAt.Time
+	return &account, nil
+}
+
+// Translation catalogs
+func (r *automationRepository) UpsertTranslationCatalog(ctx context.Context, catalog *TranslationCatalog) error {
+	query, args, err := r.sq.Insert("translation_catalogs").
+		Columns("id", "project_id", "locale", "messages").
+		Values(catalog.ID, catalog.ProjectID, catalog.Locale, catalog.Messages).
+		Suffix("ON CONFLICT (project_id, locale) DO UPDATE SET messages = EXCLUDED.messages, updated_at = now() RETURNING id, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt, updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&catalog.ID, &createdAt, &updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save translation catalog: %w", err)
+	}
+
+	catalog.MessageCount = len(catalog.Messages)
+	catalog.CreatedAt = createdAt.Time
+	catalog.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *automationRepository) GetTranslationCatalog(ctx context.Context, projectID, locale string) (*TranslationCatalog, error) {
+	query, args, err := r.sq.Select("id", "project_id", "locale", "messages", "created_at", "updated_at").
+		From("translation_catalogs").
+		Where(sq.Eq{"project_id": projectID, "locale": locale}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var catalog TranslationCatalog
+	var createdAt, updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&catalog.ID, &catalog.ProjectID, &catalog.Locale, &catalog.Messages, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("translation catalog not found")
+		}
+		return nil, fmt.Errorf("failed to get translation catalog: %w", err)
+	}
+
+	catalog.MessageCount = len(catalog.Messages)
+	catalog.CreatedAt = createdAt.Time
+	catalog.UpdatedAt = updatedAt.Time
+	return &catalog, nil
+}
+
+// GetTranslationCatalogsByProjectID lists a project's catalogs with their message counts but
+// without their messages
+func (r *automationRepository) GetTranslationCatalogsByProjectID(ctx context.Context, projectID string) ([]*TranslationCatalog, error) {
+	query, args, err := r.sq.Select("id", "project_id", "locale").
+		Column("(SELECT COUNT(*) FROM jsonb_object_keys(messages))").
+		Columns("created_at", "updated_at").
+		From("translation_catalogs").
+		Where(sq.Eq{"project_id": projectID}).
+		OrderBy("locale ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translation catalogs: %w", err)
+	}
+	defer rows.Close()
+
+	var catalogs []*TranslationCatalog
+	for rows.Next() {
+		var catalog TranslationCatalog
+		var createdAt, updatedAt pgtype.Timestamp
+		err := rows.Scan(&catalog.ID, &catalog.ProjectID, &catalog.Locale, &catalog.MessageCount, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan translation catalog: %w", err)
+		}
+		catalog.CreatedAt = createdAt.Time
+		catalog.UpdatedAt = updatedAt.Time
+		catalogs = append(catalogs, &catalog)
+	}
+
+	return catalogs, nil
+}
+
+func (r *automationRepository) DeleteTranslationCatalog(ctx context.Context, projectID, locale string) error {
+	query, args, err := r.sq.Delete("translation_catalogs").
+		Where(sq.Eq{"project_id": projectID, "locale": locale}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete translation catalog: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("translation catalog not found")
+	}
+
+	return nil
+}
+
+// GetOrganizationReportLocale returns the report locale of the organization owning a project,
+// empty when it has none
+func (r *automationRepository) GetOrganizationReportLocale(ctx context.Context, projectID string) (string, error) {
+	query, args, err := r.sq.Select("COALESCE(o.settings_json->>'reportLocale', '')").
+		From("projects p").
+		Join("organizations o ON o.id = p.organization_id").
+		Where(sq.Eq{"p.id": projectID}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var locale string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&locale); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("project not found")
+		}
+		return "", fmt.Errorf("failed to get organization report locale: %w", err)
+	}
+	return locale, nil
+}
+
+// replayInsertBatchSize keeps inserts of replay events under Postgres' limit of bind parameters
+const replayInsertBatchSize = 1000
+
+// AppendRunReplayEvents stores events of a run's replay timeline
+func (r *automationRepository) AppendRunReplayEvents(ctx context.Context, runID string, events []*RunReplayEvent) error {
+	for start := 0; start < len(events); start += replayInsertBatchSize {
+		builder := r.sq.Insert("automation_run_replay_events").
+			Columns("run_id", "seq", "offset_ms", "kind", "loop_index", "step_id", "step_name", "action_id", "action_type", "data")
+		for _, event := range events[start:min(start+replayInsertBatchSize, len(events))] {
+			data := event.Data
+			if data == nil {
+				data = map[string]interface{}{}
+			}
+			builder = builder.Values(runID, event.Seq, event.OffsetMs, event.Kind, event.LoopIndex, event.StepID,
+				event.StepName, event.ActionID, event.ActionType, data)
+		}
+		// Batches stored before a failed one are kept; retrying them is a no-op
+		query, args, err := builder.Suffix("ON CONFLICT (run_id, seq) DO NOTHING").ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+
+		if _, err := r.db.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to store run replay events: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetRunReplayEvents returns the replay events of a run in query's window, in order
+func (r *automationRepository) GetRunReplayEvents(ctx context.Context, runID string, query RunReplayQuery) ([]*RunReplayEvent, error) {
+	builder := r.sq.Select("seq", "offset_ms", "kind", "loop_index", "step_id", "step_name", "action_id", "action_type", "data").
+		From("automation_run_replay_events").
+		Where(sq.Eq{"run_id": runID}).
+		Where(sq.Gt{"seq": query.AfterSeq}).
+		OrderBy("seq ASC")
+	if query.FromMs > 0 {
+		builder = builder.Where(sq.GtOrEq{"offset_ms": query.FromMs})
+	}
+	if query.ToMs >= 0 {
+		builder = builder.Where(sq.LtOrEq{"offset_ms": query.ToMs})
+	}
+	if query.Limit > 0 {
+		builder = builder.Limit(uint64(query.Limit))
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run replay events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*RunReplayEvent
+	for rows.Next() {
+		var event RunReplayEvent
+		err := rows.Scan(&event.Seq, &event.OffsetMs, &event.Kind, &event.LoopIndex, &event.StepID, &event.StepName,
+			&event.ActionID, &event.ActionType, &event.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run replay event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetFakerDictionariesByProjectID returns the faker dictionaries of a project's organization,
+// keyed by name
+func (r *automationRepository) GetFakerDictionariesByProjectID(ctx context.Context, projectID string) (map[string][]string, error) {
+	query, args, err := r.sq.Select("d.name", `d."values"`).
+		From("projects p").
+		Join("faker_dictionaries d ON d.organization_id = p.organization_id").
+		Where(sq.Eq{"p.id": projectID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query faker dictionaries: %w", err)
+	}
+	defer rows.Close()
+
+	dictionaries := make(map[string][]string)
+	for rows.Next() {
+		var name string
+		var values []string
+		if err := rows.Scan(&name, &values); err != nil {
+			return nil, fmt.Errorf("failed to scan faker dictionary: %w", err)
+		}
+		dictionaries[name] = values
+	}
+
+	return dictionaries, rows.Err()
+}
+
+// GetScriptSandboxByProjectID returns the script sandbox of a project's organization, nil when
+// it sets no limits
+func (r *automationRepository) GetScriptSandboxByProjectID(ctx context.Context, projectID string) (*ScriptSandbox, error) {
+	query, args, err := r.sq.Select("COALESCE(o.settings_json->'scriptSandbox', '{}'::jsonb)").
+		From("projects p").
+		Join("organizations o ON o.id = p.organization_id").
+		Where(sq.Eq{"p.id": projectID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var sandbox ScriptSandbox
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&sandbox); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("project not found")
+		}
+		return nil, fmt.Errorf("failed to get script sandbox: %w", err)
+	}
+	if sandbox.MaxScriptBytes <= 0 && sandbox.TimeoutMs <= 0 && len(sandbox.BannedAPIs) == 0 {
+		return nil, nil
+	}
+	return &sandbox, nil
+}
+
+var configSnapshotColumns = []string{
+	"s.automation_id", "a.name", "s.config_json", "s.config_hash", "s.commit_ref", "s.synced_by_user_id",
+	"s.synced_at", "s.drift_detected_at", "s.drift_checked_at", "s.drift_paths",
+}
+
+// UpsertConfigSnapshot stores the snapshot of an automation, replacing the previous one and
+// clearing any drift recorded against it
+func (r *automationRepository) UpsertConfigSnapshot(ctx context.Context, snapshot *ConfigSnapshot) error {
+	var syncedBy interface{}
+	if snapshot.SyncedByUserID != "" {
+		syncedBy = snapshot.SyncedByUserID
+	}
+
+	query, args, err := r.sq.Insert("automation_config_snapshots").
+		Columns("automation_id", "config_json", "config_hash", "commit_ref", "synced_by_user_id", "drift_checked_at").
+		Values(snapshot.AutomationID, snapshot.Config, snapshot.ConfigHash, snapshot.CommitRef, syncedBy, sq.Expr("now()")).
+		Suffix(`ON CONFLICT (automation_id) DO UPDATE SET config_json = EXCLUDED.config_json, config_hash = EXCLUDED.config_hash,
+			commit_ref = EXCLUDED.commit_ref, synced_by_user_id = EXCLUDED.synced_by_user_id, synced_at = now(),
+			drift_detected_at = NULL, drift_checked_at = now(), drift_paths = '[]' RETURNING synced_at, drift_checked_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var syncedAt, checkedAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&syncedAt, &checkedAt); err != nil {
+		return fmt.Errorf("failed to save config snapshot: %w", err)
+	}
+
+	snapshot.SyncedAt = syncedAt.Time
+	snapshot.DriftCheckedAt = &checkedAt.Time
+	snapshot.Drifted = false
+	snapshot.DriftDetectedAt = nil
+	snapshot.DriftPaths = []string{}
+	return nil
+}
+
+func (r *automationRepository) GetConfigSnapshot(ctx context.Context, automationID string) (*ConfigSnapshot, error) {
+	snapshots, err := r.queryConfigSnapshots(ctx, r.sq.Select(configSnapshotColumns...).
+		From("automation_config_snapshots s").
+		Join("automations a ON a.id = s.automation_id").
+		Where(sq.Eq{"s.automation_id": automationID}))
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("config snapshot not found")
+	}
+	return snapshots[0], nil
+}
+
+// GetConfigSnapshotsByProjectID lists the snapshots of a project's automations, drifted first
+func (r *automationRepository) GetConfigSnapshotsByProjectID(ctx context.Context, projectID string) ([]*ConfigSnapshot, error) {
+	return r.queryConfigSnapshots(ctx, r.sq.Select(configSnapshotColumns...).
+		From("automation_config_snapshots s").
+		Join("automations a ON a.id = s.automation_id").
+		Where(sq.Eq{"a.project_id": projectID}).
+		OrderBy("s.drift_detected_at IS NULL", "s.drift_detected_at ASC", "a.name ASC"))
+}
+
+func (r *automationRepository) GetAllConfigSnapshots(ctx context.Context) ([]*ConfigSnapshot, error) {
+	return r.queryConfigSnapshots(ctx, r.sq.Select(configSnapshotColumns...).
+		From("automation_config_snapshots s").
+		Join("automations a ON a.id = s.automation_id"))
+}
+
+func (r *automationRepository) queryConfigSnapshots(ctx context.Context, builder sq.SelectBuilder) ([]*ConfigSnapshot, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*ConfigSnapshot
+	for rows.Next() {
+		var snapshot ConfigSnapshot
+		var syncedBy pgtype.Text
+		var syncedAt, detectedAt, checkedAt pgtype.Timestamp
+		err := rows.Scan(&snapshot.AutomationID, &snapshot.AutomationName, &snapshot.Config, &snapshot.ConfigHash, &snapshot.CommitRef,
+			&syncedBy, &syncedAt, &detectedAt, &checkedAt, &snapshot.DriftPaths)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan config snapshot: %w", err)
+		}
+		snapshot.SyncedByUserID = syncedBy.String
+		snapshot.SyncedAt = syncedAt.Time
+		if detectedAt.Valid {
+			snapshot.Drifted = true
+			snapshot.DriftDetectedAt = &detectedAt.Time
+		}
+		if checkedAt.Valid {
+			snapshot.DriftCheckedAt = &checkedAt.Time
+		}
+		if snapshot.DriftPaths == nil {
+			snapshot.DriftPaths = []string{}
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// UpdateConfigSnapshotDrift records the result of comparing an automation with its snapshot.
+// An automation drifts when driftPaths isn't empty; the time it first did is kept until it no
+// longer differs or a new snapshot is synced.
+func (r *automationRepository) UpdateConfigSnapshotDrift(ctx context.Context, automationID string, driftPaths []string) error {
+	if driftPaths == nil {
+		driftPaths = []string{}
+	}
+
+	query, args, err := r.sq.Update("automation_config_snapshots").
+		Set("drift_detected_at", sq.Expr("CASE WHEN ? THEN COALESCE(drift_detected_at, now()) END", len(driftPaths) > 0)).
+		Set("drift_checked_at", sq.Expr("now()")).
+		Set("drift_paths", driftPaths).
+		Where(sq.Eq{"automation_id": automationID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update config drift: %w", err)
+	}
+	return nil
+}
+
+func (r *automationRepository) DeleteConfigSnapshot(ctx context.Context, automationID string) error {
+	query, args, err := r.sq.Delete("automation_config_snapshots").
+		Where(sq.Eq{"automation_id": automationID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete config snapshot: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("config snapshot not found")
+	}
+
+	return nil
+}
+
+func (r *automationRepository) CreateAutomationExternalID(ctx context.Context, projectID, externalID, automationID string) error {
+	query, args, err := r.sq.Insert("automation_external_ids").
+		Columns("project_id", "external_id", "automation_id").
+		Values(projectID, externalID, automationID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create automation external ID: %w", err)
+	}
+	return nil
+}
+
+// GetAutomationIDByExternalID returns the automation a project knows by externalID, empty when
+// there is none
+func (r *automationRepository) GetAutomationIDByExternalID(ctx context.Context, projectID, externalID string) (string, error) {
+	query, args, err := r.sq.Select("automation_id").
+		From("automation_external_ids").
+		Where(sq.Eq{"project_id": projectID, "external_id": externalID}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var automationID string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&automationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get automation external ID: %w", err)
+	}
+	return automationID, nil
+}
+
+// GetAutomationExternalIDs lists the automations of a project that have an external ID, without
+// their config
+func (r *automationRepository) GetAutomationExternalIDs(ctx context.Context, projectID string) ([]*ManagedAutomation, error) {
+	query, args, err := r.sq.Select("external_id", "automation_id").
+		From("automation_external_ids").
+		Where(sq.Eq{"project_id": projectID}).
+		OrderBy("external_id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation external IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var managed []*ManagedAutomation
+	for rows.Next() {
+		var automation ManagedAutomation
+		if err := rows.Scan(&automation.ExternalID, &automation.AutomationID); err != nil {
+			return nil, fmt.Errorf("failed to scan automation external ID: %w", err)
+		}
+		managed = append(managed, &automation)
+	}
+
+	return managed, nil
+}
+
+// LockAutomation locks an automation's row until the end of the transaction, serializing writes
+// that check its current state first
+func (r *automationRepository) LockAutomation(ctx context.Context, id string) error {
+	query, args, err := r.sq.Select("id").
+		From("automations").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var lockedID string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&lockedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("automation not found")
+		}
+		return fmt.Errorf("failed to lock automation: %w", err)
+	}
+	return nil
+}
+
+// GetAutomationOwners returns the owners of an automation, users first
+func (r *automationRepository) GetAutomationOwners(ctx context.Context, automationID string) ([]AutomationOwner, error) {
+	owners, err := r.queryAutomationOwners(ctx, r.sq.Select("automation_id", "owner_type", "owner_id").
+		From("automation_owners").
+		Where(sq.Eq{"automation_id": automationID}))
+	if err != nil {
+		return nil, err
+	}
+	return owners[automationID], nil
+}
+
+// GetAutomationOwnersByProjectID returns the owners of a project's automations, keyed by
+// automation ID
+func (r *automationRepository) GetAutomationOwnersByProjectID(ctx context.Context, projectID string) (map[string][]AutomationOwner, error) {
+	return r.queryAutomationOwners(ctx, r.sq.Select("o.automation_id", "o.owner_type", "o.owner_id").
+		From("automation_owners o").
+		Join("automations a ON a.id = o.automation_id").
+		Where(sq.Eq{"a.project_id": projectID}))
+}
+
+func (r *automationRepository) queryAutomationOwners(ctx context.Context, builder sq.SelectBuilder) (map[string][]AutomationOwner, error) {
+	query, args, err := builder.OrderBy("owner_type DESC", "owner_id ASC").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation owners: %w", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[string][]AutomationOwner)
+	for rows.Next() {
+		var automationID string
+		var owner AutomationOwner
+		if err := rows.Scan(&automationID, &owner.Type, &owner.ID); err != nil {
+			return nil, fmt.Errorf("failed to scan automation owner: %w", err)
+		}
+		owners[automationID] = append(owners[automationID], owner)
+	}
+
+	return owners, rows.Err()
+}
+
+// ReplaceAutomationOwners sets the owners of an automation, removing any others
+func (r *automationRepository) ReplaceAutomationOwners(ctx context.Context, automationID string, owners []AutomationOwner) error {
+	query, args, err := r.sq.Delete("automation_owners").
+		Where(sq.Eq{"automation_id": automationID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete automation owners: %w", err)
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	insert := r.sq.Insert("automation_owners").
+		Columns("automation_id", "owner_type", "owner_id").
+		Suffix("ON CONFLICT DO NOTHING")
+	for _, owner := range owners {
+		insert = insert.Values(automationID, owner.Type, owner.ID)
+	}
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert automation owners: %w", err)
+	}
+
+	return nil
+}
+
+// GetOwnerDirectory returns the users and teams of the organization owning a project. Users are
+// those who belong to the organization or currently work in it.
+func (r *automationRepository) GetOwnerDirectory(ctx context.Context, projectID string) (*OwnerDirectory, error) {
+	query, args, err := r.sq.Select("o.id", "o.owner_user_id", "COALESCE(o.settings_json->'teams', '[]'::jsonb)").
+		From("projects p").
+		Join("organizations o ON o.id = p.organization_id").
+		Where(sq.Eq{"p.id": projectID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var orgID, orgOwnerID string
+	var teams []OwnerTeam
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&orgID, &orgOwnerID, &teams); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("project not found")
+		}
+		return nil, fmt.Errorf("failed to get organization teams: %w", err)
+	}
+
+	query, args, err = r.sq.Select("id", "email").
+		From("users").
+		Where(sq.Or{sq.Eq{"current_org_id": orgID}, sq.Eq{"id": orgOwnerID}}).
+		Where(sq.Eq{"deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organization users: %w", err)
+	}
+	defer rows.Close()
+
+	directory := &OwnerDirectory{Users: make(map[string]string), Teams: make(map[string]OwnerTeam, len(teams))}
+	for rows.Next() {
+		var id, email string
+		if err := rows.Scan(&id, &email); err != nil {
+			return nil, fmt.Errorf("failed to scan organization user: %w", err)
+		}
+		directory.Users[id] = email
+	}
+	for _, team := range teams {
+		directory.Teams[team.Name] = team
+	}
+
+	return directory, rows.Err()
+}
+
+// GetAutomationActivity returns every automation's latest run and failing streak, with its
+// organization's stale automation settings and its staleness as of the last check
+func (r *automationRepository) GetAutomationActivity(ctx context.Context) ([]*AutomationActivity, error) {
+	query, args, err := r.sq.Select("a.id", "a.name", "a.created_at",
+		"COALESCE(o.settings_json->'staleAutomations', '{}'::jsonb)",
+		"runs.last_run_at", "runs.failing_since",
+		"s.automation_id IS NOT NULL", "COALESCE(s.reasons, '[]'::jsonb)", "s.detected_at", "s.checked_at", "s.disabled_at", "s.reenabled_at").
+		From("automations a").
+		Join("projects p ON p.id = a.project_id").
+		Join("organizations o ON o.id = p.organization_id").
+		JoinClause(`LEFT JOIN LATERAL (
+			SELECT max(ar.created_at) AS last_run_at,
+				min(ar.created_at) FILTER (WHERE ar.status = 'failed' AND ar.created_at > COALESCE(
+					(SELECT max(ok.created_at) FROM automation_runs ok WHERE ok.automation_id = a.id AND ok.status = 'completed'),
+					'-infinity'
+				)) AS failing_since
+			FROM automation_runs ar
+			WHERE ar.automation_id = a.id
+		) runs ON true`).
+		LeftJoin("automation_staleness s ON s.automation_id = a.id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation activity: %w", err)
+	}
+	defer rows.Close()
+
+	var activities []*AutomationActivity
+	for rows.Next() {
+		var activity AutomationActivity
+		var staleness StaleAutomation
+		var checked bool
+		var createdAt, lastRunAt, failingSince, detectedAt, checkedAt, disabledAt, reenabledAt pgtype.Timestamp
+		err := rows.Scan(&activity.AutomationID, &activity.AutomationName, &createdAt, &activity.Settings,
+			&lastRunAt, &failingSince,
+			&checked, &staleness.Reasons, &detectedAt, &checkedAt, &disabledAt, &reenabledAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automation activity: %w", err)
+		}
+		activity.CreatedAt = createdAt.Time
+		activity.LastRunAt = timestampPtr(lastRunAt)
+		activity.FailingSince = timestampPtr(failingSince)
+		if checked {
+			staleness.AutomationID = activity.AutomationID
+			staleness.AutomationName = activity.AutomationName
+			staleness.Stale = detectedAt.Valid
+			staleness.DetectedAt = timestampPtr(detectedAt)
+			staleness.CheckedAt = timestampPtr(checkedAt)
+			staleness.DisabledAt = timestampPtr(disabledAt)
+			staleness.ReenabledAt = timestampPtr(reenabledAt)
+			activity.Staleness = &staleness
+		}
+		activities = append(activities, &activity)
+	}
+
+	return activities, rows.Err()
+}
+
+var staleAutomationColumns = []string{
+	"s.automation_id", "a.name", "s.reasons", "s.last_run_at", "s.failing_since",
+	"s.detected_at", "s.checked_at", "s.disabled_at", "s.reenabled_at",
+}
+
+// GetAutomationStaleness returns an automation's staleness as of the last check, or nil when it
+// was never checked
+func (r *automationRepository) GetAutomationStaleness(ctx context.Context, automationID string) (*StaleAutomation, error) {
+	staleness, err := r.queryStaleAutomations(ctx, r.sq.Select(staleAutomationColumns...).
+		From("automation_staleness s").
+		Join("automations a ON a.id = s.automation_id").
+		Where(sq.Eq{"s.automation_id": automationID}))
+	if err != nil || len(staleness) == 0 {
+		return nil, err
+	}
+	return staleness[0], nil
+}
+
+// GetStaleAutomationsByProjectID lists a project's stale or disabled automations, longest stale first
+func (r *automationRepository) GetStaleAutomationsByProjectID(ctx context.Context, projectID string) ([]*StaleAutomation, error) {
+	return r.queryStaleAutomations(ctx, r.sq.Select(staleAutomationColumns...).
+		From("automation_staleness s").
+		Join("automations a ON a.id = s.automation_id").
+		Where(sq.Eq{"a.project_id": projectID}).
+		Where(sq.Or{sq.NotEq{"s.detected_at": nil}, sq.NotEq{"s.disabled_at": nil}}).
+		OrderBy("s.detected_at ASC NULLS LAST", "a.name ASC"))
+}
+
+func (r *automationRepository) queryStaleAutomations(ctx context.Context, builder sq.SelectBuilder) ([]*StaleAutomation, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale automations: %w", err)
+	}
+	defer rows.Close()
+
+	var stale []*StaleAutomation
+	for rows.Next() {
+		var staleness StaleAutomation
+		var lastRunAt, failingSince, detectedAt, checkedAt, disabledAt, reenabledAt pgtype.Timestamp
+		err := rows.Scan(&staleness.AutomationID, &staleness.AutomationName, &staleness.Reasons, &lastRunAt, &failingSince,
+			&detectedAt, &checkedAt, &disabledAt, &reenabledAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stale automation: %w", err)
+		}
+		staleness.Stale = detectedAt.Valid
+		staleness.LastRunAt = timestampPtr(lastRunAt)
+		staleness.FailingSince = timestampPtr(failingSince)
+		staleness.DetectedAt = timestampPtr(detectedAt)
+		staleness.CheckedAt = timestampPtr(checkedAt)
+		staleness.DisabledAt = timestampPtr(disabledAt)
+		staleness.ReenabledAt = timestampPtr(reenabledAt)
+		if staleness.Reasons == nil {
+			staleness.Reasons = []string{}
+		}
+		stale = append(stale, &staleness)
+	}
+
+	return stale, rows.Err()
+}
+
+// UpsertAutomationStaleness records the result of checking whether an automation is stale
+func (r *automationRepository) UpsertAutomationStaleness(ctx context.Context, staleness *StaleAutomation) error {
+	reasons := staleness.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+
+	query, args, err := r.sq.Insert("automation_staleness").
+		Columns("automation_id", "reasons", "last_run_at", "failing_since", "detected_at", "checked_at", "disabled_at", "reenabled_at").
+		Values(staleness.AutomationID, reasons, staleness.LastRunAt, staleness.FailingSince, staleness.DetectedAt, sq.Expr("now()"),
+			staleness.DisabledAt, staleness.ReenabledAt).
+		Suffix(`ON CONFLICT (automation_id) DO UPDATE SET reasons = EXCLUDED.reasons, last_run_at = EXCLUDED.last_run_at,
+			failing_since = EXCLUDED.failing_since, detected_at = EXCLUDED.detected_at, checked_at = EXCLUDED.checked_at,
+			disabled_at = EXCLUDED.disabled_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to upsert automation staleness: %w", err)
+	}
+	return nil
+}
+
+// ReenableAutomation clears an automation's staleness and disabling, and measures its staleness
+// from now on
+func (r *automationRepository) ReenableAutomation(ctx context.Context, automationID string) error {
+	query, args, err := r.sq.Insert("automation_staleness").
+		Columns("automation_id", "reenabled_at").
+		Values(automationID, sq.Expr("now()")).
+		Suffix(`ON CONFLICT (automation_id) DO UPDATE SET reasons = '[]', failing_since = NULL, detected_at = NULL,
+			disabled_at = NULL, reenabled_at = EXCLUDED.reenabled_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to re-enable automation: %w", err)
+	}
+	return nil
+}
+
+// GetMaintenanceCalendars returns the maintenance calendars of organizations that set one
+func (r *automationRepository) GetMaintenanceCalendars(ctx context.Context) ([]*MaintenanceCalendar, error) {
+	query, args, err := r.sq.Select("o.id", "o.settings_json->'maintenanceCalendar'->>'url'",
+		"COALESCE((o.settings_json->'maintenanceCalendar'->>'pauseRuns')::boolean, false)").
+		From("organizations o").
+		Where(maintenanceCalendarSet).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance calendars: %w", err)
+	}
+	defer rows.Close()
+
+	var calendars []*MaintenanceCalendar
+	for rows.Next() {
+		var calendar MaintenanceCalendar
+		if err := rows.Scan(&calendar.OrganizationID, &calendar.URL, &calendar.PauseRuns); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance calendar: %w", err)
+		}
+		calendars = append(calendars, &calendar)
+	}
+
+	return calendars, rows.Err()
+}
+
+// GetMaintenanceCalendar returns the maintenance calendar of an organization; its URL is empty
+// when the organization has none
+func (r *automationRepository) GetMaintenanceCalendar(ctx context.Context, orgID string) (*MaintenanceCalendar, error) {
+	query, args, err := r.sq.Select("o.id", "COALESCE(o.settings_json->'maintenanceCalendar'->>'url', '')",
+		"COALESCE((o.settings_json->'maintenanceCalendar'->>'pauseRuns')::boolean, false)").
+		From("organizations o").
+		Where(sq.Eq{"o.id": orgID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var calendar MaintenanceCalendar
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&calendar.OrganizationID, &calendar.URL, &calendar.PauseRuns); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("organization not found")
+		}
+		return nil, fmt.Errorf("failed to get maintenance calendar: %w", err)
+	}
+	return &calendar, nil
+}
+
+// GetMaintenanceCalendarByAutomationID returns the maintenance calendar of the organization
+// owning an automation; its URL is empty when the organization has none
+func (r *automationRepository) GetMaintenanceCalendarByAutomationID(ctx context.Context, automationID string) (*MaintenanceCalendar, error) {
+	query, args, err := r.sq.Select("o.id", "COALESCE(o.settings_json->'maintenanceCalendar'->>'url', '')",
+		"COALESCE((o.settings_json->'maintenanceCalendar'->>'pauseRuns')::boolean, false)").
+		From("automations a").
+		Join("projects p ON p.id = a.project_id").
+		Join("organizations o ON o.id = p.organization_id").
+		Where(sq.Eq{"a.id": automationID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var calendar MaintenanceCalendar
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&calendar.OrganizationID, &calendar.URL, &calendar.PauseRuns); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("automation not found")
+		}
+		return nil, fmt.Errorf("failed to get maintenance calendar: %w", err)
+	}
+	return &calendar, nil
+}
+
+// ReplaceMaintenanceWindows sets the maintenance windows of an organization, removing any others
+func (r *automationRepository) ReplaceMaintenanceWindows(ctx context.Context, orgID string, windows []*MaintenanceWindow) error {
+	// Syncs of the same organization by several workers take turns until their transactions end
+	if _, err := r.db.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('maintenance_windows:' || $1))", orgID); err != nil {
+		return fmt.Errorf("failed to lock maintenance windows: %w", err)
+	}
+
+	query, args, err := r.sq.Delete("maintenance_windows").
+		Where(sq.Eq{"organization_id": orgID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete maintenance windows: %w", err)
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+
+	insert := r.sq.Insert("maintenance_windows").
+		Columns("id", "organization_id", "uid", "summary", "starts_at", "ends_at")
+	for _, window := range windows {
+		insert = insert.Values(window.ID, orgID, window.UID, window.Summary, window.StartsAt, window.EndsAt)
+	}
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert maintenance windows: %w", err)
+	}
+
+	return nil
+}
+
+var maintenanceWindowColumns = []string{"w.id", "w.organization_id", "w.uid", "w.summary", "w.starts_at", "w.ends_at"}
+
+// maintenanceCalendarSet ignores the windows of organizations that removed their calendar since
+// the last sync
+const maintenanceCalendarSet = "COALESCE(o.settings_json->'maintenanceCalendar'->>'url', '') <> ''"
+
+// GetMaintenanceWindowsByAutomationID returns the maintenance windows of the organization owning
+// an automation that overlap from..to, earliest first
+func (r *automationRepository) GetMaintenanceWindowsByAutomationID(ctx context.Context, automationID string, from, to time.Time) ([]*MaintenanceWindow, error) {
+	return r.queryMaintenanceWindows(ctx, r.sq.Select(maintenanceWindowColumns...).
+		From("maintenance_windows w").
+		Join("projects p ON p.organization_id = w.organization_id").
+		Join("automations a ON a.project_id = p.id").
+		Join("organizations o ON o.id = w.organization_id").
+		Where(sq.Eq{"a.id": automationID}).
+		Where(maintenanceCalendarSet).
+		Where(sq.Lt{"w.starts_at": to}).
+		Where(sq.Gt{"w.ends_at": from}).
+		OrderBy("w.starts_at"))
+}
+
+// GetMaintenanceWindowsByProjectID returns the maintenance windows of the organization owning a
+// project that end after from, earliest first
+func (r *automationRepository) GetMaintenanceWindowsByProjectID(ctx context.Context, projectID string, from time.Time) ([]*MaintenanceWindow, error) {
+	return r.queryMaintenanceWindows(ctx, r.sq.Select(maintenanceWindowColumns...).
+		From("maintenance_windows w").
+		Join("projects p ON p.organization_id = w.organization_id").
+		Join("organizations o ON o.id = w.organization_id").
+		Where(sq.Eq{"p.id": projectID}).
+		Where(maintenanceCalendarSet).
+		Where(sq.Gt{"w.ends_at": from}).
+		OrderBy("w.starts_at"))
+}
+
+func (r *automationRepository) queryMaintenanceWindows(ctx context.Context, builder sq.SelectBuilder) ([]*MaintenanceWindow, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []*MaintenanceWindow
+	for rows.Next() {
+		var window MaintenanceWindow
+		if err := rows.Scan(&window.ID, &window.OrganizationID, &window.UID, &window.Summary, &window.StartsAt, &window.EndsAt); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance window: %w", err)
+		}
+		windows = append(windows, &window)
+	}
+
+	return windows, rows.Err()
+}
+
+// SetRunMaintenanceWindow annotates a run with the maintenance window it was executed during
+func (r *automationRepository) SetRunMaintenanceWindow(ctx context.Context, runID string, window *MaintenanceWindow) error {
+	query, args, err := r.sq.Insert("automation_run_maintenance").
+		Columns("run_id", "summary", "starts_at", "ends_at").
+		Values(runID, window.Summary, window.StartsAt, window.EndsAt).
+		Suffix("ON CONFLICT (run_id) DO UPDATE SET summary = EXCLUDED.summary, starts_at = EXCLUDED.starts_at, ends_at = EXCLUDED.ends_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to annotate run with maintenance window: %w", err)
+	}
+	return nil
+}
+
+// GetRunMaintenanceWindow returns the maintenance window a run was executed during, or nil
+func (r *automationRepository) GetRunMaintenanceWindow(ctx context.Context, runID string) (*MaintenanceWindow, error) {
+	query, args, err := r.sq.Select("summary", "starts_at", "ends_at").
+		From("automation_run_maintenance").
+		Where(sq.Eq{"run_id": runID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var window MaintenanceWindow
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&window.Summary, &window.StartsAt, &window.EndsAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get run maintenance window: %w", err)
+	}
+	return &window, nil
+}
+
+func timestampPtr(ts pgtype.Timestamp) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	return &ts.Time
+}
+
+// UpsertRunPreview records the summary card generated for a run, replacing an earlier one
+func (r *automationRepository) UpsertRunPreview(ctx context.Context, runID, imageURL string) error {
+	query, args, err := r.sq.Insert("automation_run_previews").
+		Columns("run_id", "image_url").
+		Values(runID, imageURL).
+		Suffix("ON CONFLICT (run_id) DO UPDATE SET image_url = EXCLUDED.image_url, created_at = now()").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save run preview: %w", err)
+	}
+	return nil
+}
+
+// GetRunPreviewURL returns the URL of a run's summary card, or an empty string when none was generated
+func (r *automationRepository) GetRunPreviewURL(ctx context.Context, runID string) (string, error) {
+	query, args, err := r.sq.Select("image_url").
+		From("automation_run_previews").
+		Where(sq.Eq{"run_id": runID}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var imageURL string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&imageURL); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get run preview: %w", err)
+	}
+	return imageURL, nil
+}
+
+// CreateRunConfig records the config a run was triggered with
+func (r *automationRepository) CreateRunConfig(ctx context.Context, config *RunConfig) error {
+	query, args, err := r.sq.Insert("automation_run_configs").
+		Columns("run_id", "config_hash", "config_json").
+		Values(config.RunID, config.ConfigHash, config.ConfigJSON).
+		Suffix("ON CONFLICT (run_id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save run config: %w", err)
+	}
+	return nil
+}
+
+// GetRunConfig returns the config a run was triggered with
+func (r *automationRepository) GetRunConfig(ctx context.Context, runID string) (*RunConfig, error) {
+	query, args, err := r.sq.Select("run_id", "config_hash", "config_json", "created_at").
+		From("automation_run_configs").
+		Where(sq.Eq{"run_id": runID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var config RunConfig
+	var createdAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&config.RunID, &config.ConfigHash, &config.ConfigJSON, &createdAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("run config not found")
+		}
+		return nil, fmt.Errorf("failed to get run config: %w", err)
+	}
+	config.CreatedAt = createdAt.Time
+	return &config, nil
+}
+
+// UpsertRunPin pins a run, or relabels it when it is already pinned
+func (r *automationRepository) UpsertRunPin(ctx context.Context, pin *RunPin) error {
+	query, args, err := r.sq.Insert("automation_run_pins").
+		Columns("run_id", "label", "pinned_by_user_id").
+		Values(pin.RunID, pin.Label, platform.UtilStrPtr(pin.PinnedByUserID)).
+		Suffix(`ON CONFLICT (run_id) DO UPDATE SET label = EXCLUDED.label, pinned_by_user_id = EXCLUDED.pinned_by_user_id,
+			pinned_at = now() RETURNING pinned_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var pinnedAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&pinnedAt); err != nil {
+		return fmt.Errorf("failed to pin run: %w", err)
+	}
+
+	pin.PinnedAt = pinnedAt.Time
+	return nil
+}
+
+func (r *automationRepository) DeleteRunPin(ctx context.Context, runID string) error {
+	query, args, err := r.sq.Delete("automation_run_pins").
+		Where(sq.Eq{"run_id": runID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to unpin run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("run is not pinned")
+	}
+	return nil
+}
+
+var runPinColumns = []string{
+	"pin.run_id", "ar.automation_id", "a.name", "ar.status", "ar.created_at", "pin.label", "pin.pinned_by_user_id", "pin.pinned_at",
+}
+
+// GetRunPin returns a run's pin, or nil when it isn't pinned
+func (r *automationRepository) GetRunPin(ctx context.Context, runID string) (*RunPin, error) {
+	pins, err := r.queryRunPins(ctx, r.sq.Select(runPinColumns...).
+		From("automation_run_pins pin").
+		Join("automation_runs ar ON ar.id = pin.run_id").
+		Join("automations a ON a.id = ar.automation_id").
+		Where(sq.Eq{"pin.run_id": runID}))
+	if err != nil || len(pins) == 0 {
+		return nil, err
+	}
+	return pins[0], nil
+}
+
+// GetRunPinsByProjectID lists the pinned runs of a project's automations, newest pin first,
+// optionally only those pinned under label
+func (r *automationRepository) GetRunPinsByProjectID(ctx context.Context, projectID, label string) ([]*RunPin, error) {
+	builder := r.sq.Select(runPinColumns...).
+		From("automation_run_pins pin").
+		Join("automation_runs ar ON ar.id = pin.run_id").
+		Join("automations a ON a.id = ar.automation_id").
+		Where(sq.Eq{"a.project_id": projectID}).
+		OrderBy("pin.pinned_at DESC")
+	if label != "" {
+		builder = builder.Where(sq.Eq{"pin.label": label})
+	}
+	return r.queryRunPins(ctx, builder)
+}
+
+func (r *automationRepository) queryRunPins(ctx context.Context, builder sq.SelectBuilder) ([]*RunPin, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run pins: %w", err)
+	}
+	defer rows.Close()
+
+	var pins []*RunPin
+	for rows.Next() {
+		var pin RunPin
+		var pinnedBy pgtype.Text
+		var runCreatedAt, pinnedAt pgtype.Timestamp
+		err := rows.Scan(&pin.RunID, &pin.AutomationID, &pin.AutomationName, &pin.RunStatus, &runCreatedAt, &pin.Label, &pinnedBy, &pinnedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run pin: %w", err)
+		}
+		pin.PinnedByUserID = pinnedBy.String
+		pin.RunCreatedAt = runCreatedAt.Time
+		pin.PinnedAt = pinnedAt.Time
+		pins = append(pins, &pin)
+	}
+
+	return pins, rows.Err()
+}
+
+// UpsertArtifactFixture stores a fixture, replacing the content and settings of the
+// automation's fixture of the same name
+func (r *automationRepository) UpsertArtifactFixture(ctx context.Context, fixture *ArtifactFixture) error {
+	query, args, err := r.sq.Insert("automation_artifact_fixtures").
+		Columns("id", "automation_id", "name", "action_id", "compare_mode", "content_type", "data", "size", "sha256", "created_by_user_id").
+		Values(fixture.ID, fixture.AutomationID, fixture.Name, platform.UtilStrPtr(fixture.ActionID), fixture.CompareMode,
+			fixture.ContentType, fixture.Data, fixture.Size, fixture.SHA256, platform.UtilStrPtr(fixture.CreatedByUserID)).
+		Suffix(`ON CONFLICT (automation_id, name) DO UPDATE SET action_id = EXCLUDED.action_id, compare_mode = EXCLUDED.compare_mode,
+			content_type = EXCLUDED.content_type, data = EXCLUDED.data, size = EXCLUDED.size, sha256 = EXCLUDED.sha256,
+			updated_at = now() RETURNING id, created_by_user_id, created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdBy pgtype.Text
+	var createdAt, updatedAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&fixture.ID, &createdBy, &createdAt, &updatedAt); err != nil {
+		return fmt.Errorf("failed to save artifact fixture: %w", err)
+	}
+
+	fixture.CreatedByUserID = createdBy.String
+	fixture.CreatedAt = createdAt.Time
+	fixture.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+// GetArtifactFixturesByAutomationID lists an automation's fixtures by name, with their content
+// only when withData is set
+func (r *automationRepository) GetArtifactFixturesByAutomationID(ctx context.Context, automationID string, withData bool) ([]*ArtifactFixture, error) {
+	return r.queryArtifactFixtures(ctx, r.sq.Select(artifactFixtureColumns(withData)...).
+		From("automation_artifact_fixtures").
+		Where(sq.Eq{"automation_id": automationID}).
+		OrderBy("name"))
+}
+
+// GetArtifactFixtureByName returns an automation's fixture with its content, or nil when it has
+// none of that name
+func (r *automationRepository) GetArtifactFixtureByName(ctx context.Context, automationID, name string) (*ArtifactFixture, error) {
+	fixtures, err := r.queryArtifactFixtures(ctx, r.sq.Select(artifactFixtureColumns(true)...).
+		From("automation_artifact_fixtures").
+		Where(sq.Eq{"automation_id": automationID, "name": name}))
+	if err != nil || len(fixtures) == 0 {
+		return nil, err
+	}
+	return fixtures[0], nil
+}
+
+// artifactFixtureColumns selects fixtures, leaving their content out unless withData is set
+func artifactFixtureColumns(withData bool) []string {
+	data := "''::bytea"
+	if withData {
+		data = "data"
+	}
+	return []string{"id", "automation_id", "name", "action_id", "compare_mode", "content_type", data, "size", "sha256",
+		"created_by_user_id", "created_at", "updated_at"}
+}
+
+func (r *automationRepository) queryArtifactFixtures(ctx context.Context, builder sq.SelectBuilder) ([]*ArtifactFixture, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query artifact fixtures: %w", err)
+	}
+	defer rows.Close()
+
+	var fixtures []*ArtifactFixture
+	for rows.Next() {
+		var fixture ArtifactFixture
+		var actionID, createdBy pgtype.Text
+		var createdAt, updatedAt pgtype.Timestamp
+		err := rows.Scan(&fixture.ID, &fixture.AutomationID, &fixture.Name, &actionID, &fixture.CompareMode, &fixture.ContentType,
+			&fixture.Data, &fixture.Size, &fixture.SHA256, &createdBy, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan artifact fixture: %w", err)
+		}
+		fixture.ActionID = actionID.String
+		fixture.CreatedByUserID = createdBy.String
+		fixture.CreatedAt = createdAt.Time
+		fixture.UpdatedAt = updatedAt.Time
+		fixtures = append(fixtures, &fixture)
+	}
+
+	return fixtures, rows.Err()
+}
+
+func (r *automationRepository) DeleteArtifactFixture(ctx context.Context, automationID, name string) error {
+	query, args, err := r.sq.Delete("automation_artifact_fixtures").
+		Where(sq.Eq{"automation_id": automationID, "name": name}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete artifact fixture: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("artifact fixture not found")
+	}
+	return nil
+}
+
+func (r *automationRepository) CreateConfigRollout(ctx context.Context, rollout *ConfigRollout) error {
+	query, args, err := r.sq.Insert("automation_config_rollouts").
+		Columns("id", "automation_id", "candidate_automation_id", "shadow_runs", "status", "started_by_user_id").
+		Values(rollout.ID, rollout.AutomationID, rollout.CandidateAutomationID, rollout.ShadowRuns, rollout.Status, platform.UtilStrPtr(rollout.StartedByUserID)).
+		Suffix("RETURNING started_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var startedAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&startedAt); err != nil {
+		return fmt.Errorf("failed to create config rollout: %w", err)
+	}
+
+	rollout.StartedAt = startedAt.Time
+	return nil
+}
+
+var configRolloutColumns = []string{
+	"id", "automation_id", "candidate_automation_id", "shadow_runs", "status", "started_by_user_id", "started_at", "finished_at",
+	"compared", "matching", "regressions", "fixes",
+}
+
+// GetActiveConfigRollout returns an automation's active rollout, or nil when it has none
+func (r *automationRepository) GetActiveConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error) {
+	return r.queryConfigRollout(ctx, r.sq.Select(configRolloutColumns...).
+		From("automation_config_rollouts").
+		Where(sq.Eq{"automation_id": automationID, "status": "active"}))
+}
+
+// GetActiveConfigRolloutByCandidateID returns the active rollout an automation is the candidate
+// of, or nil when it isn't one
+func (r *automationRepository) GetActiveConfigRolloutByCandidateID(ctx context.Context, candidateAutomationID string) (*ConfigRollout, error) {
+	return r.queryConfigRollout(ctx, r.sq.Select(configRolloutColumns...).
+		From("automation_config_rollouts").
+		Where(sq.Eq{"candidate_automation_id": candidateAutomationID, "status": "active"}))
+}
+
+// GetLatestConfigRollout returns an automation's most recent rollout, or nil when it never had one
+func (r *automationRepository) GetLatestConfigRollout(ctx context.Context, automationID string) (*ConfigRollout, error) {
+	return r.queryConfigRollout(ctx, r.sq.Select(configRolloutColumns...).
+		From("automation_config_rollouts").
+		Where(sq.Eq{"automation_id": automationID}).
+		OrderBy("started_at DESC").
+		Limit(1))
+}
+
+func (r *automationRepository) queryConfigRollout(ctx context.Context, builder sq.SelectBuilder) (*ConfigRollout, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var rollout ConfigRollout
+	var candidateID, startedBy pgtype.Text
+	var startedAt, finishedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&rollout.ID, &rollout.AutomationID, &candidateID, &rollout.ShadowRuns, &rollout.Status,
+		&startedBy, &startedAt, &finishedAt, &rollout.Summary.Compared, &rollout.Summary.Matching, &rollout.Summary.Regressions, &rollout.Summary.Fixes)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get config rollout: %w", err)
+	}
+
+	rollout.CandidateAutomationID = candidateID.String
+	rollout.StartedByUserID = startedBy.String
+	rollout.StartedAt = startedAt.Time
+	rollout.FinishedAt = timestampPtr(finishedAt)
+	return &rollout, nil
+}
+
+// FinishConfigRollout records a rollout's final status and comparison summary
+func (r *automationRepository) FinishConfigRollout(ctx context.Context, rollout *ConfigRollout) error {
+	query, args, err := r.sq.Update("automation_config_rollouts").
+		Set("status", rollout.Status).
+		Set("finished_at", sq.Expr("now()")).
+		Set("compared", rollout.Summary.Compared).
+		Set("matching", rollout.Summary.Matching).
+		Set("regressions", rollout.Summary.Regressions).
+		Set("fixes", rollout.Summary.Fixes).
+		Where(sq.Eq{"id": rollout.ID, "status": "active"}).
+		Suffix("RETURNING finished_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var finishedAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&finishedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("config rollout is no longer active")
+		}
+		return fmt.Errorf("failed to finish config rollout: %w", err)
+	}
+
+	rollout.FinishedAt = timestampPtr(finishedAt)
+	return nil
+}
+
+func (r *automationRepository) CreateRolloutRun(ctx context.Context, rolloutID, stableRunID, shadowRunID string) error {
+	query, args, err := r.sq.Insert("automation_config_rollout_runs").
+		Columns("shadow_run_id", "rollout_id", "stable_run_id").
+		Values(shadowRunID, rolloutID, stableRunID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create rollout run: %w", err)
+	}
+	return nil
+}
+
+func (r *automationRepository) CountRolloutRuns(ctx context.Context, rolloutID string) (int, error) {
+	query, args, err := r.sq.Select("COUNT(*)").
+		From("automation_config_rollout_runs").
+		Where(sq.Eq{"rollout_id": rolloutID}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rollout runs: %w", err)
+	}
+	return count, nil
+}
+
+// GetRolloutComparisons returns the stable and shadow runs of a rollout, oldest pair first
+func (r *automationRepository) GetRolloutComparisons(ctx context.Context, rolloutID string) ([]RolloutComparison, error) {
+	query, args, err := r.sq.Select(
+		"stable.id", "stable.status", "stable.start_time", "stable.end_time", "stable.error_message",
+		"shadow.id", "shadow.status", "shadow.start_time", "shadow.end_time", "shadow.error_message",
+	).
+		From("automation_config_rollout_runs rr").
+		Join("automation_runs stable ON stable.id = rr.stable_run_id").
+		Join("automation_runs shadow ON shadow.id = rr.shadow_run_id").
+		Where(sq.Eq{"rr.rollout_id": rolloutID}).
+		OrderBy("rr.created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rollout runs: %w", err)
+	}
+	defer rows.Close()
+
+	var comparisons []RolloutComparison
+	for rows.Next() {
+		var comparison RolloutComparison
+		var stableStart, stableEnd, shadowStart, shadowEnd pgtype.Timestamp
+		var stableError, shadowError pgtype.Text
+		err := rows.Scan(&comparison.StableRun.ID, &comparison.StableRun.Status, &stableStart, &stableEnd, &stableError,
+			&comparison.ShadowRun.ID, &comparison.ShadowRun.Status, &shadowStart, &shadowEnd, &shadowError)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rollout run: %w", err)
+		}
+		comparison.StableRun.StartTime = timestampPtr(stableStart)
+		comparison.StableRun.EndTime = timestampPtr(stableEnd)
+		comparison.StableRun.ErrorMessage = stableError.String
+		comparison.ShadowRun.StartTime = timestampPtr(shadowStart)
+		comparison.ShadowRun.EndTime = timestampPtr(shadowEnd)
+		comparison.ShadowRun.ErrorMessage = shadowError.String
+		comparisons = append(comparisons, comparison)
+	}
+
+	return comparisons, rows.Err()
+}
+
+func (r *automationRepository) CreateRunView(ctx context.Context, view *RunView) error {
+	query, args, err := r.sq.Insert("run_views").
+		Columns("id", "project_id", "name", "filter", "created_by_user_id", "shared").
+		Values(view.ID, view.ProjectID, view.Name, view.Filter, platform.UtilStrPtr(view.CreatedByUserID), view.Shared).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt, updatedAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&createdAt, &updatedAt); err != nil {
+		return fmt.Errorf("failed to create run view: %w", err)
+	}
+
+	view.CreatedAt = createdAt.Time
+	view.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *automationRepository) UpdateRunView(ctx context.Context, view *RunView) error {
+	query, args, err := r.sq.Update("run_views").
+		Set("name", view.Name).
+		Set("filter", view.Filter).
+		Set("shared", view.Shared).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": view.ID}).
+		Suffix("RETURNING updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var updatedAt pgtype.Timestamp
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&updatedAt); err != nil {
+		return fmt.Errorf("failed to update run view: %w", err)
+	}
+
+	view.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *automationRepository) DeleteRunView(ctx context.Context, id string) error {
+	query, args, err := r.sq.Delete("run_views").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete run view: %w", err)
+	}
+	return nil
+}
+
+// GetRunViewByID returns a run view, or nil when it doesn't exist
+func (r *automationRepository) GetRunViewByID(ctx context.Context, id, userID string) (*RunView, error) {
+	views, err := r.queryRunViews(ctx, sq.Eq{"v.id": id}, userID)
+	if err != nil || len(views) == 0 {
+		return nil, err
+	}
+	return views[0], nil
+}
+
+// GetRunViewsByProjectID lists the run views of a project userID may see: their own and shared ones
+func (r *automationRepository) GetRunViewsByProjectID(ctx context.Context, projectID, userID string) ([]*RunView, error) {
+	return r.queryRunViews(ctx, sq.And{
+		sq.Eq{"v.project_id": projectID},
+		sq.Or{sq.Eq{"v.created_by_user_id": userID}, sq.Eq{"v.shared": true}},
+	}, userID)
+}
+
+func (r *automationRepository) queryRunViews(ctx context.Context, where sq.Sqlizer, userID string) ([]*RunView, error) {
+	query, args, err := r.sq.Select("v.id", "v.project_id", "v.name", "v.filter", "v.created_by_user_id", "v.shared", "v.created_at", "v.updated_at").
+		Column("EXISTS (SELECT 1 FROM run_view_subscriptions s WHERE s.view_id = v.id AND s.user_id = ?)", userID).
+		From("run_views v").
+		Where(where).
+		OrderBy("v.name", "v.created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []*RunView
+	for rows.Next() {
+		var view RunView
+		var createdBy pgtype.Text
+		var createdAt, updatedAt pgtype.Timestamp
+		if err := rows.Scan(&view.ID, &view.ProjectID, &view.Name, &view.Filter, &createdBy, &view.Shared, &createdAt, &updatedAt, &view.Subscribed); err != nil {
+			return nil, fmt.Errorf("failed to scan run view: %w", err)
+		}
+		view.CreatedByUserID = createdBy.String
+		view.CreatedAt = createdAt.Time
+		view.UpdatedAt = updatedAt.Time
+		views = append(views, &view)
+	}
+
+	return views, rows.Err()
+}
+
+func (r *automationRepository) SubscribeRunView(ctx context.Context, viewID, userID string) error {
+	query, args, err := r.sq.Insert("run_view_subscriptions").
+		Columns("view_id", "user_id").
+		Values(viewID, userID).
+		Suffix("ON CONFLICT (view_id, user_id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to subscribe to run view: %w", err)
+	}
+	return nil
+}
+
+func (r *automationRepository) UnsubscribeRunView(ctx context.Context, viewID, userID string) error {
+	query, args, err := r.sq.Delete("run_view_subscriptions").
+		Where(sq.Eq{"view_id": viewID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to unsubscribe from run view: %w", err)
+	}
+	return nil
+}
+
+// GetRunViewSubscriptionsByProjectID lists the subscriptions to the run views of a project with
+// the subscribers' emails. Subscriptions to views that are no longer shared only count for the
+// view's creator, and subscribers must still belong to the project's organization.
+func (r *automationRepository) GetRunViewSubscriptionsByProjectID(ctx context.Context, projectID string) ([]*RunViewSubscription, error) {
+	query, args, err := r.sq.Select("v.id", "v.name", "v.project_id", "v.filter", "u.id", "u.email").
+		From("run_view_subscriptions s").
+		Join("run_views v ON v.id = s.view_id").
+		Join("users u ON u.id = s.user_id").
+		Join("projects p ON p.id = v.project_id").
+		Join("organizations o ON o.id = p.organization_id").
+		Where(sq.Eq{"v.project_id": projectID}).
+		Where(sq.Or{sq.Eq{"v.shared": true}, sq.Expr("v.created_by_user_id = s.user_id")}).
+		// Users who left the organization, or were deleted, stop receiving its runs
+		Where(sq.Or{sq.Expr("u.current_org_id = o.id"), sq.Expr("u.id = o.owner_user_id")}).
+		Where(sq.Eq{"u.deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run view subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*RunViewSubscription
+	for rows.Next() {
+		var subscription RunViewSubscription
+		if err := rows.Scan(&subscription.ViewID, &subscription.ViewName, &subscription.ProjectID, &subscription.Filter, &subscription.UserID, &subscription.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan run view subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *automationRepository) MarkRunViewNotified(ctx context.Context, viewID, userID string) error {
+	query, args, err := r.sq.Update("run_view_subscriptions").
+		Set("last_notified_at", sq.Expr("now()")).
+		Where(sq.Eq{"view_id": viewID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to mark run view notified: %w", err)
+	}
+	return nil
+}
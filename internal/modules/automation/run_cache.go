@@ -2,7 +2,10 @@ package automation
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -36,8 +39,38 @@ type RunCache interface {
 	
 	// UpsertAllRuns syncs all runs from database to Redis
 	UpsertAllRuns(ctx context.Context, runs []*AutomationRun) error
+
+	// SetRunHeartbeat records when a run last made progress
+	SetRunHeartbeat(ctx context.Context, runID string, at time.Time) error
+
+	// GetRunHeartbeat returns when a run last made progress, or the zero time if it never reported
+	GetRunHeartbeat(ctx context.Context, runID string) (time.Time, error)
+
+	// SetRunPausedUntil records that a user of a run is held by playwright:pause until the given time
+	SetRunPausedUntil(ctx context.Context, runID string, until time.Time) error
+
+	// GetRunPausedUntil returns until when a run is held by playwright:pause, or the zero time
+	GetRunPausedUntil(ctx context.Context, runID string) (time.Time, error)
+
+	// RegisterWorker advertises a worker's capabilities until ttl passes without it registering again
+	RegisterWorker(ctx context.Context, worker *WorkerCapabilities, ttl time.Duration) error
+
+	// GetWorkers returns the capabilities of the workers currently registered
+	GetWorkers(ctx context.Context) ([]*WorkerCapabilities, error)
+
+	// AcquireRunLock takes a lock for a run unless another run holds it, returning the holder
+	AcquireRunLock(ctx context.Context, key, runID string, ttl time.Duration) (string, bool, error)
+
+	// RefreshRunLock extends a lock the run holds
+	RefreshRunLock(ctx context.Context, key, runID string, ttl time.Duration) error
+
+	// ReleaseRunLock releases a lock the run holds
+	ReleaseRunLock(ctx context.Context, key, runID string) error
 }
 
+// runHeartbeatTTL keeps heartbeats of runs that ended without cleaning up from piling up
+const runHeartbeatTTL = 24 * time.Hour
+
 // RedisRunCache implements RunCache using Redis
 type RedisRunCache struct {
 	client *redis.Client
@@ -115,6 +148,106 @@ func (r *RedisRunCache) GetPendingRuns(ctx context.Context) ([]string, error) {
 	return pendingRuns, iter.Err()
 }
 
+// SetRunHeartbeat records when a run last made progress
+func (r *RedisRunCache) SetRunHeartbeat(ctx context.Context, runID string, at time.Time) error {
+	key := fmt.Sprintf("run:%s:heartbeat", runID)
+	return r.client.Set(ctx, key, at.UnixMilli(), runHeartbeatTTL).Err()
+}
+
+// GetRunHeartbeat returns when a run last made progress, or the zero time if it never reported
+func (r *RedisRunCache) GetRunHeartbeat(ctx context.Context, runID string) (time.Time, error) {
+	key := fmt.Sprintf("run:%s:heartbeat", runID)
+	millis, err := r.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(millis), nil
+}
+
+// SetRunPausedUntil records that a user of a run is held by playwright:pause until the given time.
+// The key expires with the pause; an earlier deadline never shortens a later one.
+func (r *RedisRunCache) SetRunPausedUntil(ctx context.Context, runID string, until time.Time) error {
+	current, err := r.GetRunPausedUntil(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if current.After(until) {
+		return nil
+	}
+	key := fmt.Sprintf("run:%s:paused_until", runID)
+	return r.client.Set(ctx, key, until.UnixMilli(), time.Until(until)).Err()
+}
+
+// GetRunPausedUntil returns until when a run is held by playwright:pause, or the zero time
+func (r *RedisRunCache) GetRunPausedUntil(ctx context.Context, runID string) (time.Time, error) {
+	key := fmt.Sprintf("run:%s:paused_until", runID)
+	millis, err := r.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(millis), nil
+}
+
+// Workers are kept in a hash of their capabilities by ID, with their registrations' expiry
+// times in a sorted set, so listing them never scans the keyspace
+const (
+	workersKey       = "workers:capabilities"
+	workerExpiresKey = "workers:expires"
+)
+
+// RegisterWorker advertises a worker's capabilities until ttl passes without it registering again
+func (r *RedisRunCache) RegisterWorker(ctx context.Context, worker *WorkerCapabilities, ttl time.Duration) error {
+	data, err := json.Marshal(worker)
+	if err != nil {
+		return err
+	}
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, workersKey, worker.ID, data)
+	pipe.ZAdd(ctx, workerExpiresKey, redis.Z{Score: float64(time.Now().Add(ttl).UnixMilli()), Member: worker.ID})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetWorkers returns the capabilities of the workers currently registered, forgetting those
+// whose registration expired
+func (r *RedisRunCache) GetWorkers(ctx context.Context) ([]*WorkerCapabilities, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	expired, err := r.client.ZRangeByScore(ctx, workerExpiresKey, &redis.ZRangeBy{Min: "-inf", Max: now}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(expired) > 0 {
+		pipe := r.client.TxPipeline()
+		pipe.HDel(ctx, workersKey, expired...)
+		pipe.ZRemRangeByScore(ctx, workerExpiresKey, "-inf", now)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := r.client.HGetAll(ctx, workersKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	var workers []*WorkerCapabilities
+	for _, data := range entries {
+		var worker WorkerCapabilities
+		if err := json.Unmarshal([]byte(data), &worker); err != nil {
+			continue
+		}
+		workers = append(workers, &worker)
+	}
+
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ID < workers[j].ID })
+	return workers, nil
+}
+
 // UpsertAllRuns syncs all runs from database to Redis
 func (r *RedisRunCache) UpsertAllRuns(ctx context.Context, runs []*AutomationRun) error {
 	pipe := r.client.Pipeline()
@@ -129,8 +262,8 @@ func (r *RedisRunCache) UpsertAllRuns(ctx context.Context, runs []*AutomationRun
 			pipe.Set(ctx, key, run.Status, 0) // No expiry for active states
 		}
 		
-		// Add to running set if status is running
-		if run.Status == "running" {
+		// Add to running set if status is running; stalled runs still hold their slot
+		if run.Status == "running" || run.Status == "stalled" {
 			pipe.SAdd(ctx, "running_automation_ids", run.ID)
 		}
 	}
@@ -0,0 +1,239 @@
+package automation
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// maxRunDiffCells bounds the table a user's event sequences are aligned with. Past it, the part
+// of the sequences between their common start and end is reported as removed and added.
+const maxRunDiffCells = 4_000_000
+
+// Run diff entry operations
+const (
+	RunDiffEqual   = "equal"   // same action with the same outcome in both attempts
+	RunDiffChanged = "changed" // same action with a different status or error
+	RunDiffRemoved = "removed" // only in the base attempt
+	RunDiffAdded   = "added"   // only in the compared attempt
+)
+
+// RunDiff compares the event sequences of two attempts of a run, user by user
+type RunDiff struct {
+	BaseRunID   string         `json:"base_run_id"`
+	BaseAttempt int            `json:"base_attempt"`
+	RunID       string         `json:"run_id"`
+	Attempt     int            `json:"attempt"`
+	Diverged    bool           `json:"diverged"`
+	Summary     RunDiffSummary `json:"summary"`
+	Users       []UserRunDiff  `json:"users"`
+}
+
+// RunDiffSummary counts the entries of a diff by operation
+type RunDiffSummary struct {
+	Equal   int `json:"equal"`
+	Changed int `json:"changed"`
+	Removed int `json:"removed"`
+	Added   int `json:"added"`
+}
+
+// UserRunDiff is the aligned event sequences of one user in both attempts
+type UserRunDiff struct {
+	LoopIndex  int            `json:"loop_index"`
+	DivergedAt int            `json:"diverged_at"` // index of the first entry that isn't equal, -1 when none
+	Entries    []RunDiffEntry `json:"entries"`
+}
+
+// RunDiffEntry pairs an event of the base attempt with the matching event of the compared one
+type RunDiffEntry struct {
+	Op    string        `json:"op"`
+	Base  *RunDiffEvent `json:"base,omitempty"`
+	Retry *RunDiffEvent `json:"retry,omitempty"`
+}
+
+// RunDiffEvent is a log entry of a run as compared by a diff
+type RunDiffEvent struct {
+	StepID     string `json:"step_id"`
+	StepName   string `json:"step_name"`
+	ActionID   string `json:"action_id"`
+	ActionType string `json:"action_type"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Timestamp  string `json:"timestamp"`
+}
+
+// key identifies the action an event belongs to; messages often carry run specific values, so
+// they don't take part in matching
+func (e *RunDiffEvent) key() string {
+	return e.StepID + "\x00" + e.ActionID + "\x00" + e.ActionType
+}
+
+// DiffRuns aligns the events of each user of base and run, matching events of the same action in
+// order. Users are compared separately since parallel users interleave differently every run.
+// loopIndex limits the diff to one user when it is not negative.
+func DiffRuns(base, run *AutomationRun, loopIndex int) *RunDiff {
+	baseEvents := runDiffEventsByUser(base)
+	runEvents := runDiffEventsByUser(run)
+
+	users := make(map[int]bool)
+	for user := range baseEvents {
+		users[user] = true
+	}
+	for user := range runEvents {
+		users[user] = true
+	}
+	loopIndexes := make([]int, 0, len(users))
+	for user := range users {
+		if loopIndex < 0 || user == loopIndex {
+			loopIndexes = append(loopIndexes, user)
+		}
+	}
+	sort.Ints(loopIndexes)
+
+	diff := &RunDiff{
+		BaseRunID:   base.ID,
+		BaseAttempt: max(base.Attempt, 1),
+		RunID:       run.ID,
+		Attempt:     max(run.Attempt, 1),
+		Users:       make([]UserRunDiff, 0, len(loopIndexes)),
+	}
+	for _, user := range loopIndexes {
+		userDiff := UserRunDiff{LoopIndex: user, DivergedAt: -1, Entries: diffRunEvents(baseEvents[user], runEvents[user])}
+		for i, entry := range userDiff.Entries {
+			switch entry.Op {
+			case RunDiffEqual:
+				diff.Summary.Equal++
+			case RunDiffChanged:
+				diff.Summary.Changed++
+			case RunDiffRemoved:
+				diff.Summary.Removed++
+			case RunDiffAdded:
+				diff.Summary.Added++
+			}
+			if entry.Op != RunDiffEqual && userDiff.DivergedAt < 0 {
+				userDiff.DivergedAt = i
+				diff.Diverged = true
+			}
+		}
+		diff.Users = append(diff.Users, userDiff)
+	}
+	return diff
+}
+
+// runDiffEventsByUser returns a run's action events per loop index, in the order they were logged
+func runDiffEventsByUser(run *AutomationRun) map[int][]*RunDiffEvent {
+	var logs []map[string]any
+	if run.LogsJSON != "" {
+		json.Unmarshal([]byte(run.LogsJSON), &logs)
+	}
+
+	events := make(map[int][]*RunDiffEvent)
+	for _, entry := range logs {
+		// Output files are artifacts of an action rather than events of their own
+		if _, isOutputFile := entry["output_file"]; isOutputFile {
+			continue
+		}
+		event := &RunDiffEvent{}
+		event.StepID, _ = entry["step_id"].(string)
+		event.StepName, _ = entry["step_name"].(string)
+		event.ActionID, _ = entry["action_id"].(string)
+		event.ActionType, _ = entry["action_type"].(string)
+		event.Status, _ = entry["status"].(string)
+		event.Message, _ = entry["message"].(string)
+		event.Error, _ = entry["error"].(string)
+		event.Timestamp, _ = entry["timestamp"].(string)
+		duration, _ := entry["duration_ms"].(float64)
+		event.DurationMs = int64(duration)
+		loopIndex, _ := entry["loop_index"].(float64)
+
+		events[int(loopIndex)] = append(events[int(loopIndex)], event)
+	}
+	return events
+}
+
+// diffRunEvents aligns two event sequences on their longest common subsequence of actions
+func diffRunEvents(base, retry []*RunDiffEvent) []RunDiffEntry {
+	entries := make([]RunDiffEntry, 0, max(len(base), len(retry)))
+
+	// Attempts usually agree for a while before diverging, and often converge again
+	prefix := 0
+	for prefix < len(base) && prefix < len(retry) && base[prefix].key() == retry[prefix].key() {
+		entries = append(entries, matchedRunDiffEntry(base[prefix], retry[prefix]))
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(base)-prefix && suffix < len(retry)-prefix &&
+		base[len(base)-1-suffix].key() == retry[len(retry)-1-suffix].key() {
+		suffix++
+	}
+	baseMiddle := base[prefix : len(base)-suffix]
+	retryMiddle := retry[prefix : len(retry)-suffix]
+
+	if len(baseMiddle)*len(retryMiddle) > maxRunDiffCells {
+		for _, event := range baseMiddle {
+			entries = append(entries, RunDiffEntry{Op: RunDiffRemoved, Base: event})
+		}
+		for _, event := range retryMiddle {
+			entries = append(entries, RunDiffEntry{Op: RunDiffAdded, Retry: event})
+		}
+	} else {
+		entries = append(entries, alignRunEvents(baseMiddle, retryMiddle)...)
+	}
+
+	for i := suffix; i > 0; i-- {
+		entries = append(entries, matchedRunDiffEntry(base[len(base)-i], retry[len(retry)-i]))
+	}
+	return entries
+}
+
+// alignRunEvents diffs two event sequences with a longest common subsequence table
+func alignRunEvents(base, retry []*RunDiffEvent) []RunDiffEntry {
+	// lengths[i][j] is the LCS length of base[i:] and retry[j:]
+	lengths := make([][]int, len(base)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(retry)+1)
+	}
+	for i := len(base) - 1; i >= 0; i-- {
+		for j := len(retry) - 1; j >= 0; j-- {
+			if base[i].key() == retry[j].key() {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var entries []RunDiffEntry
+	i, j := 0, 0
+	for i < len(base) && j < len(retry) {
+		switch {
+		case base[i].key() == retry[j].key():
+			entries = append(entries, matchedRunDiffEntry(base[i], retry[j]))
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			entries = append(entries, RunDiffEntry{Op: RunDiffRemoved, Base: base[i]})
+			i++
+		default:
+			entries = append(entries, RunDiffEntry{Op: RunDiffAdded, Retry: retry[j]})
+			j++
+		}
+	}
+	for ; i < len(base); i++ {
+		entries = append(entries, RunDiffEntry{Op: RunDiffRemoved, Base: base[i]})
+	}
+	for ; j < len(retry); j++ {
+		entries = append(entries, RunDiffEntry{Op: RunDiffAdded, Retry: retry[j]})
+	}
+	return entries
+}
+
+// matchedRunDiffEntry pairs two events of the same action, equal unless their outcome differs
+func matchedRunDiffEntry(base, retry *RunDiffEvent) RunDiffEntry {
+	op := RunDiffEqual
+	if base.Status != retry.Status || base.Error != retry.Error {
+		op = RunDiffChanged
+	}
+	return RunDiffEntry{Op: op, Base: base, Retry: retry}
+}
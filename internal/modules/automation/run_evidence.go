@@ -0,0 +1,429 @@
+package automation
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// EvidenceFormat identifies the layout of run evidence bundles in their manifest
+const EvidenceFormat = "qplayground-evidence/v1"
+
+// Evidence bundle limits. Artifacts over them are listed as missing instead of being included.
+const (
+	maxEvidenceArtifactBytes = 100 << 20
+	maxEvidenceBundleBytes   = 1 << 30
+)
+
+var evidenceHTTPClient = &http.Client{Timeout: 2 * time.Minute}
+
+// unsafeEvidenceNameChars are replaced in the file names of artifacts within a bundle
+var unsafeEvidenceNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// EvidenceManifest lists the contents of a run evidence bundle with their SHA-256 hashes.
+// manifest.sig holds the Ed25519 signature of manifest.json by the key KeyID names, which
+// verifiers fetch from the well-known endpoint rather than trusting a key within the bundle.
+type EvidenceManifest struct {
+	Format      string            `json:"format"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	GeneratedBy string            `json:"generated_by,omitempty"`
+	Run         EvidenceRun       `json:"run"`
+	Config      EvidenceConfig    `json:"config"`
+	Files       []EvidenceFile    `json:"files"`
+	Missing     []EvidenceMissing `json:"missing,omitempty"` // artifacts that couldn't be included
+	KeyID       string            `json:"key_id"`            // see EvidenceKeyID
+}
+
+// EvidenceRun describes the run a bundle is evidence of
+type EvidenceRun struct {
+	ID             string     `json:"id"`
+	AutomationID   string     `json:"automation_id"`
+	AutomationName string     `json:"automation_name"`
+	ProjectID      string     `json:"project_id"`
+	Status         string     `json:"status"`
+	StartTime      *time.Time `json:"start_time,omitempty"`
+	EndTime        *time.Time `json:"end_time,omitempty"`
+	Attempt        int        `json:"attempt"`
+	ParentRunID    string     `json:"parent_run_id,omitempty"`
+	ErrorMessage   string     `json:"error_message,omitempty"`
+}
+
+// EvidenceConfig identifies the version of the automation's config in config.json: the one the
+// run was triggered with, or the automation's current one for runs that predate run configs
+type EvidenceConfig struct {
+	ConfigVersion       int        `json:"config_version"`
+	Hash                string     `json:"hash"` // as compared against config snapshots
+	RecordedAt          *time.Time `json:"recorded_at,omitempty"`
+	Current             bool       `json:"current,omitempty"` // no config was recorded for the run
+	AutomationUpdatedAt time.Time  `json:"automation_updated_at"`
+	CommitRef           string     `json:"commit_ref,omitempty"` // commit of the last config snapshot
+	Drifted             bool       `json:"drifted,omitempty"`    // edited since that snapshot
+}
+
+// EvidenceFile is a file of a bundle
+type EvidenceFile struct {
+	Path      string `json:"path"`
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+	SourceURL string `json:"source_url,omitempty"` // where an artifact was downloaded from
+}
+
+// EvidenceMissing is an artifact of the run that couldn't be included in its bundle
+type EvidenceMissing struct {
+	SourceURL string `json:"source_url"`
+	Error     string `json:"error"`
+}
+
+// RunEvidence is the evidence bundle of a finished run. Its artifacts are downloaded to a
+// temporary directory by FetchArtifacts before the bundle is written as a ZIP.
+type RunEvidence struct {
+	manifest   EvidenceManifest
+	report     []byte
+	logs       []byte
+	config     []byte
+	artifacts  []OutputFile
+	fetched    []evidenceArtifact
+	tempDir    string
+	signingKey ed25519.PrivateKey
+}
+
+// evidenceArtifact is an artifact downloaded into a bundle's temporary directory
+type evidenceArtifact struct {
+	name      string
+	tempPath  string
+	sourceURL string
+}
+
+// evidenceSigningKey returns the key manifests are signed with. Bundles are never exported
+// unsigned, so it fails when EVIDENCE_SIGNING_KEY is not set.
+func evidenceSigningKey() (ed25519.PrivateKey, error) {
+	if platform.ENV_EVIDENCE_SIGNING_KEY == "" {
+		return nil, fmt.Errorf("%w: evidence bundles must be signed; set EVIDENCE_SIGNING_KEY to export them", platform.ErrUnavailable)
+	}
+	seed, err := base64.StdEncoding.DecodeString(platform.ENV_EVIDENCE_SIGNING_KEY)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("EVIDENCE_SIGNING_KEY must be %d base64-encoded bytes", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// EvidenceKeyID identifies a signing key in manifests: the first 16 hex digits of the SHA-256
+// of its public key
+func EvidenceKeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+// EvidencePublicKey returns the base64 public key evidence manifests are signed with and its ID,
+// published so verifiers don't have to trust a key shipped within a bundle
+func EvidencePublicKey() (keyID, publicKey string, err error) {
+	signingKey, err := evidenceSigningKey()
+	if err != nil {
+		return "", "", err
+	}
+	public := signingKey.Public().(ed25519.PublicKey)
+	return EvidenceKeyID(public), base64.StdEncoding.EncodeToString(public), nil
+}
+
+// RecordRunConfig records the automation's exported config on a run it was just triggered with,
+// for the run's evidence bundle. Runs are still executed when it fails.
+func (s *automationService) RecordRunConfig(ctx context.Context, run *AutomationRun) {
+	exported, err := s.GetFullAutomationConfig(ctx, run.AutomationID)
+	if err != nil {
+		slog.Warn("Failed to get config to record for run", "run_id", run.ID, "error", err)
+		return
+	}
+	config, err := json.Marshal(exported)
+	if err != nil {
+		slog.Warn("Failed to encode config to record for run", "run_id", run.ID, "error", err)
+		return
+	}
+	snapshot, err := snapshotConfig(exported)
+	if err != nil {
+		slog.Warn("Failed to hash config to record for run", "run_id", run.ID, "error", err)
+		return
+	}
+	hash, err := configHash(snapshot)
+	if err != nil {
+		slog.Warn("Failed to hash config to record for run", "run_id", run.ID, "error", err)
+		return
+	}
+	if err := s.automationRepo.CreateRunConfig(ctx, &RunConfig{RunID: run.ID, ConfigHash: hash, ConfigJSON: string(config)}); err != nil {
+		slog.Warn("Failed to record run config", "run_id", run.ID, "error", err)
+	}
+}
+
+// GetRunEvidence prepares the signed evidence bundle of a finished run: its report, logs,
+// artifacts and the config it was triggered with, listed with their hashes in a manifest.
+// Runs that predate run configs get the automation's current config, flagged as such.
+func (s *automationService) GetRunEvidence(ctx context.Context, runID, exportedBy string) (*RunEvidence, error) {
+	signingKey, err := evidenceSigningKey()
+	if err != nil {
+		slog.Error("Failed to load evidence signing key", "error", err)
+		return nil, err
+	}
+
+	run, err := s.automationRepo.GetRunByID(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run for evidence", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	switch run.Status {
+	case "completed", "failed", "cancelled":
+	default:
+		return nil, fmt.Errorf("%w: evidence can only be exported for finished runs", platform.ErrInvalidRequest)
+	}
+
+	automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get automation for evidence", "error", err, "automationID", run.AutomationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig)
+	}
+
+	report, err := renderRunReport(ctx, s.automationRepo, automation, &automationConfig, run, RunReportFormatHTML)
+	if err != nil {
+		slog.Error("Failed to render run report for evidence", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to render run report: %w", err)
+	}
+
+	evidenceConfig := EvidenceConfig{AutomationUpdatedAt: automation.UpdatedAt}
+	var exported *ExportedAutomationConfig
+	if runConfig, err := s.automationRepo.GetRunConfig(ctx, run.ID); err == nil {
+		if err := json.Unmarshal([]byte(runConfig.ConfigJSON), &exported); err != nil {
+			return nil, fmt.Errorf("invalid run config: %w", err)
+		}
+		evidenceConfig.Hash = runConfig.ConfigHash
+		evidenceConfig.RecordedAt = &runConfig.CreatedAt
+	} else {
+		if exported, err = s.GetFullAutomationConfig(ctx, automation.ID); err != nil {
+			return nil, err
+		}
+		snapshot, err := snapshotConfig(exported)
+		if err != nil {
+			return nil, err
+		}
+		if evidenceConfig.Hash, err = configHash(snapshot); err != nil {
+			return nil, err
+		}
+		evidenceConfig.Current = true
+	}
+	evidenceConfig.ConfigVersion = exported.Automation.Config.ConfigVersion
+	config, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	logs := []byte(run.LogsJSON)
+	if len(logs) == 0 {
+		logs = []byte("[]")
+	}
+
+	evidence := &RunEvidence{
+		manifest: EvidenceManifest{
+			Format:      EvidenceFormat,
+			GeneratedAt: time.Now().UTC(),
+			GeneratedBy: exportedBy,
+			Run: EvidenceRun{
+				ID:             run.ID,
+				AutomationID:   automation.ID,
+				AutomationName: automation.Name,
+				ProjectID:      automation.ProjectID,
+				Status:         run.Status,
+				StartTime:      run.StartTime,
+				EndTime:        run.EndTime,
+				Attempt:        run.Attempt,
+				ParentRunID:    run.ParentRunID,
+				ErrorMessage:   run.ErrorMessage,
+			},
+			Config: evidenceConfig,
+			Files:  []EvidenceFile{},
+			KeyID:  EvidenceKeyID(signingKey.Public().(ed25519.PublicKey)),
+		},
+		report:     report,
+		logs:       logs,
+		config:     config,
+		artifacts:  run.OutputFiles(),
+		signingKey: signingKey,
+	}
+	if committed, err := s.automationRepo.GetConfigSnapshot(ctx, automation.ID); err == nil {
+		evidence.manifest.Config.CommitRef = committed.CommitRef
+		evidence.manifest.Config.Drifted = committed.ConfigHash != evidenceConfig.Hash
+	}
+
+	slog.Info("Run evidence exported", "runID", runID, "exportedBy", exportedBy)
+	return evidence, nil
+}
+
+// FileName is the name bundles are downloaded as
+func (e *RunEvidence) FileName() string {
+	return fmt.Sprintf("run-evidence-%s.zip", e.manifest.Run.ID)
+}
+
+// FetchArtifacts downloads the run's artifacts to a temporary directory, so the bundle can be
+// written without failing midway; call Close once it is written. Artifacts that can't be
+// downloaded, or would take the bundle over its size limit, are listed as missing in the manifest.
+func (e *RunEvidence) FetchArtifacts(ctx context.Context) error {
+	tempDir, err := os.MkdirTemp("", "run-evidence-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	e.tempDir = tempDir
+
+	var total int64
+	for i, file := range e.artifacts {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		data, err := fetchEvidenceArtifact(ctx, file.URL, maxEvidenceBundleBytes-total)
+		if err != nil {
+			e.manifest.Missing = append(e.manifest.Missing, EvidenceMissing{SourceURL: file.URL, Error: err.Error()})
+			continue
+		}
+		total += int64(len(data))
+
+		tempPath := filepath.Join(tempDir, strconv.Itoa(i))
+		if err := os.WriteFile(tempPath, data, 0o600); err != nil {
+			return fmt.Errorf("failed to store artifact: %w", err)
+		}
+		e.fetched = append(e.fetched, evidenceArtifact{
+			name:      fmt.Sprintf("artifacts/%03d-%s", i+1, evidenceArtifactName(file)),
+			tempPath:  tempPath,
+			sourceURL: file.URL,
+		})
+	}
+	return nil
+}
+
+// Close removes the artifacts downloaded by FetchArtifacts
+func (e *RunEvidence) Close() error {
+	if e.tempDir == "" {
+		return nil
+	}
+	return os.RemoveAll(e.tempDir)
+}
+
+// WriteZip writes the bundle, with the artifacts downloaded by FetchArtifacts, to w
+func (e *RunEvidence) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	manifest := e.manifest
+
+	add := func(name string, data []byte, sourceURL string) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: manifest.GeneratedAt})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, EvidenceFile{Path: name, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data)), SourceURL: sourceURL})
+		return nil
+	}
+
+	if err := add("report.html", e.report, ""); err != nil {
+		return err
+	}
+	if err := add("logs.json", e.logs, ""); err != nil {
+		return err
+	}
+	if err := add("config.json", e.config, ""); err != nil {
+		return err
+	}
+
+	for _, artifact := range e.fetched {
+		data, err := os.ReadFile(artifact.tempPath)
+		if err != nil {
+			return fmt.Errorf("failed to read artifact: %w", err)
+		}
+		if err := add(artifact.name, data, artifact.sourceURL); err != nil {
+			return err
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := add("manifest.json", manifestJSON, ""); err != nil {
+		return err
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(e.signingKey, manifestJSON))
+	if err := add("manifest.sig", []byte(signature+"\n"), ""); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(manifestJSON)
+	zw.SetComment("manifest.json sha256 " + hex.EncodeToString(sum[:]))
+	return zw.Close()
+}
+
+// fetchEvidenceArtifact downloads an artifact of at most min(limit, maxEvidenceArtifactBytes)
+func fetchEvidenceArtifact(ctx context.Context, location string, limit int64) ([]byte, error) {
+	limit = min(limit, maxEvidenceArtifactBytes)
+	if limit <= 0 {
+		return nil, fmt.Errorf("bundle size limit of %d MB reached", maxEvidenceBundleBytes>>20)
+	}
+	parsed, err := url.Parse(location)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("unsupported artifact URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact URL: %w", err)
+	}
+	resp, err := evidenceHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("artifact download returned HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("artifact is larger than %d MB", limit>>20)
+	}
+	return data, nil
+}
+
+// evidenceArtifactName is the file name of an artifact within a bundle
+func evidenceArtifactName(file OutputFile) string {
+	name := path.Base(file.Key)
+	if file.Key == "" {
+		if parsed, err := url.Parse(file.URL); err == nil {
+			name = path.Base(parsed.Path)
+		}
+	}
+	name = unsafeEvidenceNameChars.ReplaceAllString(name, "_")
+	if name == "" || name == "." || name == "_" {
+		name = "artifact"
+	}
+	return name
+}
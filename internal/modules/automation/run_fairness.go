@@ -0,0 +1,111 @@
+package automation
+
+// fairRunQueue orders queued runs so that capacity is shared round-robin instead of first come,
+// first served: organizations take turns, and within an organization its automations take turns.
+// Each automation's own runs start oldest first. Turns carry over between scheduler ticks, so an
+// organization or automation that started a run recently waits behind those that haven't, and
+// one automation triggering dozens of runs can't starve everyone else.
+type fairRunQueue struct {
+	turn        uint64
+	lastStarted map[string]uint64 // organization and automation keys to the turn they last started a run
+}
+
+func newFairRunQueue() *fairRunQueue {
+	return &fairRunQueue{lastStarted: make(map[string]uint64)}
+}
+
+func fairOrgKey(run *QueuedRun) string        { return "org:" + run.OrganizationID }
+func fairAutomationKey(run *QueuedRun) string { return "automation:" + run.AutomationID }
+
+// Order returns runs, given oldest first, in the order they should start. Turns of organizations
+// and automations with nothing queued are forgotten.
+func (q *fairRunQueue) Order(runs []*QueuedRun) []*QueuedRun {
+	// Queue each automation's runs under its organization, keeping them oldest first
+	byOrg := make(map[string]map[string][]*QueuedRun)
+	for _, run := range runs {
+		org := fairOrgKey(run)
+		if byOrg[org] == nil {
+			byOrg[org] = make(map[string][]*QueuedRun)
+		}
+		automation := fairAutomationKey(run)
+		byOrg[org][automation] = append(byOrg[org][automation], run)
+	}
+
+	lastStarted := make(map[string]uint64)
+	for key, turn := range q.lastStarted {
+		if _, queued := byOrg[key]; queued {
+			lastStarted[key] = turn
+		}
+	}
+	for _, automations := range byOrg {
+		for key := range automations {
+			if turn, ok := q.lastStarted[key]; ok {
+				lastStarted[key] = turn
+			}
+		}
+	}
+	q.lastStarted = lastStarted
+
+	// Simulate the turns on a copy, so only runs that actually start take a turn
+	simulated := make(map[string]uint64, len(lastStarted))
+	for key, turn := range lastStarted {
+		simulated[key] = turn
+	}
+	turn := q.turn
+
+	ordered := make([]*QueuedRun, 0, len(runs))
+	for len(ordered) < len(runs) {
+		org := nextFairKey(byOrg, simulated, func(automations map[string][]*QueuedRun) *QueuedRun {
+			var oldest *QueuedRun
+			for _, queue := range automations {
+				if len(queue) > 0 && (oldest == nil || queue[0].CreatedAt.Before(oldest.CreatedAt)) {
+					oldest = queue[0]
+				}
+			}
+			return oldest
+		})
+		automation := nextFairKey(byOrg[org], simulated, func(queue []*QueuedRun) *QueuedRun {
+			if len(queue) == 0 {
+				return nil
+			}
+			return queue[0]
+		})
+
+		run := byOrg[org][automation][0]
+		byOrg[org][automation] = byOrg[org][automation][1:]
+		ordered = append(ordered, run)
+
+		turn++
+		simulated[org] = turn
+		simulated[automation] = turn
+	}
+	return ordered
+}
+
+// MarkStarted records that run started, ending its organization's and automation's turn
+func (q *fairRunQueue) MarkStarted(run *QueuedRun) {
+	q.turn++
+	q.lastStarted[fairOrgKey(run)] = q.turn
+	q.lastStarted[fairAutomationKey(run)] = q.turn
+}
+
+// nextFairKey returns the key whose turn is next: the one that started a run longest ago, or
+// never, breaking ties by the oldest queued run and then by key. head returns a group's oldest
+// queued run, or nil when the group has nothing left.
+func nextFairKey[T any](groups map[string]T, lastStarted map[string]uint64, head func(T) *QueuedRun) string {
+	var next string
+	var nextHead *QueuedRun
+	for key, group := range groups {
+		groupHead := head(group)
+		if groupHead == nil {
+			continue
+		}
+		if nextHead == nil || lastStarted[key] < lastStarted[next] ||
+			(lastStarted[key] == lastStarted[next] && (groupHead.CreatedAt.Before(nextHead.CreatedAt) ||
+				(groupHead.CreatedAt.Equal(nextHead.CreatedAt) && key < next))) {
+			next = key
+			nextHead = groupHead
+		}
+	}
+	return next
+}
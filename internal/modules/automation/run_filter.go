@@ -0,0 +1,297 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default and maximum number of runs listed at a time
+const (
+	defaultRunQueryLimit = 50
+	maxRunQueryLimit     = 500
+)
+
+// maxRunFilterConditions bounds the conditions of one filter
+const maxRunFilterConditions = 20
+
+// runStatuses are the statuses a run can have
+var runStatuses = []string{"pending", "queued", "running", "stalled", "completed", "failed", "cancelled"}
+
+// runFilterOps are the comparison operators of the filter DSL, longest first so >= isn't read as >
+var runFilterOps = []string{"!=", ">=", "<=", "=", ">", "<"}
+
+// ParseRunFilter parses a filter of the runs filter DSL: whitespace separated conditions that all
+// have to hold, each a field, an operator and a value, e.g.
+//
+//	status=failed,cancelled tag=smoke duration>30s failed_step="Log in" triggered_by=me
+//
+// Conditions may also be joined with an uppercase AND. Comma separated values are alternatives
+// and values with spaces or commas are double quoted.
+// The fields are:
+//   - status = or != pending, queued, running, stalled, completed, failed or cancelled
+//   - tag = or != a tag of the steps and actions the run executed
+//   - duration >, >=, < or <= a duration such as 90s or 5m
+//   - created >, >=, < or <= a date (2006-01-02, UTC) or an RFC3339 time
+//   - failed_step = the name of a step an action failed in
+//
+// Tags and failed steps are recorded when runs finish, so runs in progress don't match them.
+//   - triggered_by = me, retry (automatic retries), a user ID or an email
+//   - automation = an automation ID or name
+//
+// me stands for userID.
+func ParseRunFilter(expr, userID string) (*RunFilter, error) {
+	filter := &RunFilter{}
+	conditions := 0
+	for rest := strings.TrimSpace(expr); rest != ""; rest = strings.TrimSpace(rest) {
+		if conditions > 0 {
+			if after, ok := strings.CutPrefix(rest, "AND"); ok && (after == "" || strings.ContainsAny(after[:1], " \t\n")) {
+				if rest = strings.TrimSpace(after); rest == "" {
+					return nil, fmt.Errorf("expected a condition after AND")
+				}
+			}
+		}
+		conditions++
+		if conditions > maxRunFilterConditions {
+			return nil, fmt.Errorf("filters have at most %d conditions", maxRunFilterConditions)
+		}
+
+		field, op, values, remainder, err := parseRunFilterCondition(rest)
+		if err != nil {
+			return nil, err
+		}
+		rest = remainder
+		if err := filter.add(field, op, values, userID); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// parseRunFilterCondition reads the condition at the start of expr, returning what follows it
+func parseRunFilterCondition(expr string) (field, op string, values []string, rest string, err error) {
+	end := strings.IndexFunc(expr, func(r rune) bool { return !(r >= 'a' && r <= 'z' || r == '_') })
+	if end < 0 {
+		end = len(expr)
+	}
+	if end == 0 {
+		return "", "", nil, "", fmt.Errorf("expected a field at '%s'", expr)
+	}
+	field, rest = expr[:end], expr[end:]
+
+	for _, candidate := range runFilterOps {
+		if strings.HasPrefix(rest, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return "", "", nil, "", fmt.Errorf("expected an operator after '%s'", field)
+	}
+	rest = rest[len(op):]
+
+	for {
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			if i == len(rest) {
+				return "", "", nil, "", fmt.Errorf("unterminated quote in the value of '%s'", field)
+			}
+			value, rest = b.String(), rest[i+1:]
+		} else {
+			end := strings.IndexAny(rest, ", \t\n")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		if value == "" {
+			return "", "", nil, "", fmt.Errorf("expected a value for '%s'", field)
+		}
+		values = append(values, value)
+
+		if !strings.HasPrefix(rest, ",") {
+			break
+		}
+		rest = rest[1:]
+	}
+	if rest != "" && !strings.ContainsAny(rest[:1], " \t\n") {
+		return "", "", nil, "", fmt.Errorf("expected a space after the value of '%s'", field)
+	}
+	return field, op, values, rest, nil
+}
+
+// add adds a condition to the filter
+func (f *RunFilter) add(field, op string, values []string, userID string) error {
+	switch field {
+	case "status":
+		if err := checkRunFilterOp(field, op, "=", "!="); err != nil {
+			return err
+		}
+		for _, status := range values {
+			if !slices.Contains(runStatuses, status) {
+				return fmt.Errorf("unknown status '%s', expected one of %s", status, strings.Join(runStatuses, ", "))
+			}
+		}
+		if op == "=" {
+			f.Statuses = append(f.Statuses, values...)
+		} else {
+			f.ExcludeStatuses = append(f.ExcludeStatuses, values...)
+		}
+
+	case "tag":
+		if err := checkRunFilterOp(field, op, "=", "!="); err != nil {
+			return err
+		}
+		if op == "=" {
+			f.Tags = append(f.Tags, normalizeTags(values)...)
+		} else {
+			f.ExcludeTags = append(f.ExcludeTags, normalizeTags(values)...)
+		}
+
+	case "duration":
+		if err := checkRunFilterOp(field, op, ">", ">=", "<", "<="); err != nil {
+			return err
+		}
+		if len(values) > 1 {
+			return fmt.Errorf("duration takes a single value")
+		}
+		duration, err := time.ParseDuration(values[0])
+		if err != nil || duration < 0 {
+			return fmt.Errorf("invalid duration '%s', expected e.g. 90s or 5m", values[0])
+		}
+		f.Durations = append(f.Durations, RunFilterDuration{Op: op, Value: duration})
+
+	case "created":
+		if err := checkRunFilterOp(field, op, ">", ">=", "<", "<="); err != nil {
+			return err
+		}
+		if len(values) > 1 {
+			return fmt.Errorf("created takes a single value")
+		}
+		t, isDate, err := parseRunHistoryTime(values[0], time.UTC)
+		if err != nil {
+			return fmt.Errorf("invalid created '%s': %w", values[0], err)
+		}
+		// Dates cover their whole day, and times are compared as half-open ranges
+		switch {
+		case op == ">" && isDate, op == "<=" && isDate:
+			t = t.AddDate(0, 0, 1)
+		case op == ">", op == "<=":
+			t = t.Add(time.Nanosecond)
+		}
+		if op == ">" || op == ">=" {
+			op = ">="
+		} else {
+			op = "<"
+		}
+		f.Created = append(f.Created, RunFilterTime{Op: op, Value: t})
+
+	case "failed_step":
+		if err := checkRunFilterOp(field, op, "="); err != nil {
+			return err
+		}
+		f.FailedSteps = append(f.FailedSteps, values...)
+
+	case "triggered_by":
+		if err := checkRunFilterOp(field, op, "="); err != nil {
+			return err
+		}
+		for _, value := range values {
+			switch {
+			case value == "me":
+				f.TriggeredByUserIDs = append(f.TriggeredByUserIDs, userID)
+			case value == "retry":
+				f.TriggeredByRetry = true
+			case strings.Contains(value, "@"):
+				f.TriggeredByEmails = append(f.TriggeredByEmails, value)
+			case uuid.Validate(value) == nil:
+				f.TriggeredByUserIDs = append(f.TriggeredByUserIDs, value)
+			default:
+				return fmt.Errorf("invalid triggered_by '%s', expected me, retry, a user ID or an email", value)
+			}
+		}
+
+	case "automation":
+		if err := checkRunFilterOp(field, op, "="); err != nil {
+			return err
+		}
+		f.Automations = append(f.Automations, values...)
+
+	default:
+		return fmt.Errorf("unknown field '%s', expected status, tag, duration, created, failed_step, triggered_by or automation", field)
+	}
+	return nil
+}
+
+func checkRunFilterOp(field, op string, allowed ...string) error {
+	if !slices.Contains(allowed, op) {
+		return fmt.Errorf("'%s' doesn't support %s, only %s", field, op, strings.Join(allowed, " "))
+	}
+	return nil
+}
+
+// GetRuns lists the runs of a project or automation that match a filter, newest first
+func (s *automationService) GetRuns(ctx context.Context, query RunQuery) ([]*AutomationRun, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultRunQueryLimit
+	}
+	query.Limit = min(query.Limit, maxRunQueryLimit)
+	query.Offset = max(query.Offset, 0)
+	if query.Filter == nil {
+		query.Filter = &RunFilter{}
+	}
+
+	runs, err := s.automationRepo.GetRuns(ctx, query)
+	if err != nil {
+		slog.Error("Failed to get filtered runs", "error", err, "projectID", query.ProjectID, "automationID", query.AutomationID)
+		return nil, fmt.Errorf("failed to get runs: %w", err)
+	}
+	if runs == nil {
+		runs = []*AutomationRun{}
+	}
+	return runs, nil
+}
+
+// recordRunFacets saves the failed steps and executed tags of a finished run, which failed_step and
+// tag filters match on instead of searching its logs
+func (s *Scheduler) recordRunFacets(ctx context.Context, run *AutomationRun) {
+	var logs []map[string]any
+	if run.LogsJSON != "" {
+		json.Unmarshal([]byte(run.LogsJSON), &logs)
+	}
+	failedSteps := []string{}
+	for _, entry := range logs {
+		stepName, _ := entry["step_name"].(string)
+		if status, _ := entry["status"].(string); status == "failed" && stepName != "" && !slices.Contains(failedSteps, stepName) {
+			failedSteps = append(failedSteps, stepName)
+		}
+	}
+
+	tags := normalizeTags(run.IncludeTags)
+	steps, err := s.automationRepo.GetStepsByAutomationID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Warn("Failed to get steps for run tags", "run_id", run.ID, "error", err)
+	} else if actions, err := s.automationRepo.GetActionsByAutomationID(ctx, run.AutomationID); err != nil {
+		slog.Warn("Failed to get actions for run tags", "run_id", run.ID, "error", err)
+	} else {
+		tags = executedTags(steps, actions, RunTagFilter{Include: run.IncludeTags, Exclude: run.ExcludeTags})
+	}
+
+	if err := s.automationRepo.SetRunFacets(ctx, run.ID, failedSteps, tags); err != nil {
+		slog.Error("Failed to record run facets", "run_id", run.ID, "error", err)
+	}
+}
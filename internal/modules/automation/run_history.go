@@ -0,0 +1,158 @@
+package automation
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default and maximum run history windows; the maximums bound a history to a year of days
+// or a month of hours
+const (
+	defaultRunHistoryDays  = 365
+	defaultRunHistoryHours = 7 * 24
+	maxRunHistoryDays      = 366
+	maxRunHistoryHours     = 31 * 24
+)
+
+// runHistoryStatuses are counted in every bucket, so buckets without runs still list them
+var runHistoryStatuses = []string{"pending", "running", "completed", "failed", "cancelled"}
+
+// NewRunHistoryQuery validates history parameters as given to the API. from and to are dates
+// (2006-01-02) or RFC3339 times and both are inclusive, a date covering its whole day; empty
+// values select the last year of days or the last week of hours, ending with the current bucket.
+func NewRunHistoryQuery(granularity, from, to, timezone string) (RunHistoryQuery, error) {
+	if granularity == "" {
+		granularity = "day"
+	}
+	if granularity != "day" && granularity != "hour" {
+		return RunHistoryQuery{}, fmt.Errorf("granularity must be day or hour")
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return RunHistoryQuery{}, fmt.Errorf("unknown timezone '%s'", timezone)
+		}
+	}
+
+	query := RunHistoryQuery{Granularity: granularity, Location: location}
+
+	query.To = query.nextBucket(query.bucketStart(time.Now()))
+	if to != "" {
+		parsed, isDate, err := parseRunHistoryTime(to, location)
+		if err != nil {
+			return RunHistoryQuery{}, fmt.Errorf("invalid to: %w", err)
+		}
+		if isDate {
+			query.To = parsed.AddDate(0, 0, 1)
+		} else {
+			query.To = query.nextBucket(query.bucketStart(parsed))
+		}
+	}
+
+	if from != "" {
+		parsed, _, err := parseRunHistoryTime(from, location)
+		if err != nil {
+			return RunHistoryQuery{}, fmt.Errorf("invalid from: %w", err)
+		}
+		query.From = query.bucketStart(parsed)
+	} else if granularity == "day" {
+		query.From = query.To.AddDate(0, 0, -defaultRunHistoryDays)
+	} else {
+		query.From = query.To.Add(-defaultRunHistoryHours * time.Hour)
+	}
+
+	if !query.From.Before(query.To) {
+		return RunHistoryQuery{}, fmt.Errorf("from must not be after to")
+	}
+	if granularity == "day" && query.From.AddDate(0, 0, maxRunHistoryDays).Before(query.To) {
+		return RunHistoryQuery{}, fmt.Errorf("a daily history covers at most %d days", maxRunHistoryDays)
+	}
+	if granularity == "hour" && query.To.Sub(query.From) > maxRunHistoryHours*time.Hour {
+		return RunHistoryQuery{}, fmt.Errorf("an hourly history covers at most %d days", maxRunHistoryHours/24)
+	}
+
+	return query, nil
+}
+
+// parseRunHistoryTime parses a date in location, reported by isDate, or an RFC3339 time
+func parseRunHistoryTime(value string, location *time.Location) (t time.Time, isDate bool, err error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected a date (2006-01-02) or an RFC3339 time")
+	}
+	return t.In(location), false, nil
+}
+
+// bucketStart truncates t to the start of its day or hour in the query's timezone
+func (q RunHistoryQuery) bucketStart(t time.Time) time.Time {
+	t = t.In(q.Location)
+	if q.Granularity == "hour" {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, q.Location)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, q.Location)
+}
+
+// nextBucket returns the start of the bucket after the one starting at start
+func (q RunHistoryQuery) nextBucket(start time.Time) time.Time {
+	if q.Granularity == "hour" {
+		return start.Add(time.Hour)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// bucketKey identifies a bucket by its wall-clock start, matching how the database truncates
+func bucketKey(t time.Time) string {
+	return t.Format("2006-01-02T15")
+}
+
+// buildRunHistory spreads counts over every bucket of the query, including buckets without runs
+func buildRunHistory(automationID string, query RunHistoryQuery, counts []RunStatusCount) *RunHistory {
+	countsByBucket := make(map[string]map[string]int)
+	for _, count := range counts {
+		key := bucketKey(count.Bucket)
+		if countsByBucket[key] == nil {
+			countsByBucket[key] = make(map[string]int)
+		}
+		countsByBucket[key][count.Status] += count.Count
+	}
+
+	history := &RunHistory{
+		AutomationID: automationID,
+		Granularity:  query.Granularity,
+		Timezone:     query.Location.String(),
+		From:         query.From,
+		To:           query.To,
+		Buckets:      []RunHistoryBucket{},
+	}
+
+	lastKey := ""
+	for start := query.From; start.Before(query.To); start = query.nextBucket(start) {
+		// A wall-clock hour repeated when clocks go back is a single bucket in the database
+		key := bucketKey(start)
+		if key == lastKey {
+			continue
+		}
+		lastKey = key
+
+		bucket := RunHistoryBucket{Start: start, Counts: make(map[string]int, len(runHistoryStatuses))}
+		for _, status := range runHistoryStatuses {
+			bucket.Counts[status] = countsByBucket[key][status]
+			bucket.Total += bucket.Counts[status]
+		}
+
+		if finished := bucket.Counts["completed"] + bucket.Counts["failed"]; finished > 0 {
+			rate := float64(bucket.Counts["completed"]) / float64(finished)
+			bucket.SuccessRate = &rate
+		}
+
+		history.Total += bucket.Total
+		history.Buckets = append(history.Buckets, bucket)
+	}
+
+	return history
+}
@@ -0,0 +1,179 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Run locks expire unless the worker holding them refreshes them, so a worker that dies mid-run
+// doesn't keep later runs queued for long
+const (
+	runLockTTL             = 2 * time.Minute
+	runLockRefreshInterval = 30 * time.Second
+)
+
+// maxConcurrencyGroupLength bounds the names of concurrency groups
+const maxConcurrencyGroupLength = 100
+
+// ConcurrencyConfig serializes runs that share mutable state. With Exclusive, a run of the
+// automation waits, queued, until its previous run ended; with a Group, such as "staging", it
+// also waits for runs of the project's other automations in the same group.
+type ConcurrencyConfig struct {
+	Exclusive bool   `json:"exclusive,omitempty"`
+	Group     string `json:"group,omitempty"`
+}
+
+// RunLockKeys returns the distributed locks a run of an automation must hold, always in the same
+// order so runs taking several can't deadlock
+func RunLockKeys(projectID, automationID string, config ConcurrencyConfig) []string {
+	var keys []string
+	if group := strings.TrimSpace(config.Group); group != "" {
+		keys = append(keys, fmt.Sprintf("lock:group:%s:%s", projectID, group))
+	}
+	if config.Exclusive {
+		keys = append(keys, fmt.Sprintf("lock:automation:%s", automationID))
+	}
+	return keys
+}
+
+func validateConcurrency(config ConcurrencyConfig) error {
+	if len(strings.TrimSpace(config.Group)) > maxConcurrencyGroupLength {
+		return fmt.Errorf("concurrency group names must be at most %d characters", maxConcurrencyGroupLength)
+	}
+	return nil
+}
+
+// acquireRunLocks takes the locks a run needs before it starts. When a lock is held by another
+// run, the locks already taken are released and the run is left queued for a later pass; the
+// run holding the lock is returned.
+func (s *Scheduler) acquireRunLocks(ctx context.Context, projectID string, run *AutomationRun) ([]string, string, error) {
+	automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get automation: %w", err)
+	}
+	var config AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &config)
+	}
+
+	keys := RunLockKeys(projectID, automation.ID, config.Concurrency)
+	for i, key := range keys {
+		holder, acquired, err := s.runCache.AcquireRunLock(ctx, key, run.ID, runLockTTL)
+		if err == nil && acquired {
+			continue
+		}
+		s.releaseRunLocks(keys[:i], run.ID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to acquire run lock %s: %w", key, err)
+		}
+		return nil, holder, nil
+	}
+	return keys, "", nil
+}
+
+// holdRunLocks refreshes a running run's locks until its context ends
+func (s *Scheduler) holdRunLocks(ctx context.Context, keys []string, runID string) {
+	ticker := time.NewTicker(runLockRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, key := range keys {
+				if err := s.runCache.RefreshRunLock(ctx, key, runID, runLockTTL); err != nil {
+					slog.Warn("Failed to refresh run lock", "run_id", runID, "lock", key, "error", err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// releaseRunLocks releases the locks a run holds
+func (s *Scheduler) releaseRunLocks(keys []string, runID string) {
+	for _, key := range keys {
+		if err := s.runCache.ReleaseRunLock(context.Background(), key, runID); err != nil {
+			slog.Error("Failed to release run lock", "run_id", runID, "lock", key, "error", err)
+		}
+	}
+}
+
+// queueLockedRun marks a pending run waiting for another run's lock as queued
+func (s *Scheduler) queueLockedRun(ctx context.Context, projectID string, run *AutomationRun, holder string) {
+	slog.Debug("Run waiting for a concurrency lock", "run_id", run.ID, "holder", holder)
+	if run.Status == "queued" {
+		return
+	}
+
+	run.Status = "queued"
+	if err := s.automationRepo.UpdateRun(ctx, run); err != nil {
+		slog.Error("Failed to queue run waiting for a lock", "run_id", run.ID, "error", err)
+		return
+	}
+	if err := s.runCache.SetRunStatus(ctx, run.ID, run.Status); err != nil {
+		slog.Error("Failed to update queued run status in cache", "run_id", run.ID, "error", err)
+	}
+	if s.sseManager != nil {
+		s.sseManager.SendRunStatusUpdate(projectID, run.AutomationID, run.ID, run.Status)
+	}
+	slog.Info("Run queued until a concurrent run ends", "run_id", run.ID, "automation_id", run.AutomationID, "holder", holder)
+}
+
+// Compare-and-set scripts, so a run only ever refreshes or releases locks it holds
+var (
+	refreshRunLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseRunLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// AcquireRunLock takes a lock for a run unless another run holds it, returning the holder
+func (r *RedisRunCache) AcquireRunLock(ctx context.Context, key, runID string, ttl time.Duration) (string, bool, error) {
+	acquired, err := r.client.SetNX(ctx, key, runID, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	if acquired {
+		return runID, true, nil
+	}
+
+	holder, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		// Released in between; try once more
+		acquired, err = r.client.SetNX(ctx, key, runID, ttl).Result()
+		if err != nil || acquired {
+			return runID, acquired, err
+		}
+		holder, err = r.client.Get(ctx, key).Result()
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if holder == runID {
+		return holder, true, r.RefreshRunLock(ctx, key, runID, ttl)
+	}
+	return holder, false, nil
+}
+
+// RefreshRunLock extends a lock the run holds
+func (r *RedisRunCache) RefreshRunLock(ctx context.Context, key, runID string, ttl time.Duration) error {
+	return refreshRunLockScript.Run(ctx, r.client, []string{key}, runID, ttl.Milliseconds()).Err()
+}
+
+// ReleaseRunLock releases a lock the run holds
+func (r *RedisRunCache) ReleaseRunLock(ctx context.Context, key, runID string) error {
+	return releaseRunLockScript.Run(ctx, r.client, []string{key}, runID).Err()
+}
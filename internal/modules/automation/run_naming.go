@@ -0,0 +1,138 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxNamingTemplateLength bounds run name and artifact prefix templates
+const maxNamingTemplateLength = 200
+
+// NamingConfig names an automation's runs and the storage keys of their artifacts after
+// templates such as "{{automationName}}-{{env}}-{{date}}-#{{sequence}}", instead of run IDs.
+// Templates take {{automationName}}, {{sequence}}, {{date}}, {{time}}, {{attempt}}, {{runId}},
+// {{automationId}}, {{projectId}} and the automation's static variables, e.g. {{env}}.
+type NamingConfig struct {
+	RunName        string `json:"runName,omitempty"`        // display name of runs in run lists
+	ArtifactPrefix string `json:"artifactPrefix,omitempty"` // folder artifact keys are stored under, may contain "/"
+}
+
+// namingTokens are the tokens naming templates take besides static variables
+var namingTokens = map[string]bool{
+	"automationName": true,
+	"sequence":       true,
+	"date":           true,
+	"time":           true,
+	"attempt":        true,
+	"runId":          true,
+	"automationId":   true,
+	"projectId":      true,
+}
+
+// RenderNamingTemplate renders a naming template for a run. Dates are those of the run's
+// creation in UTC, so every user of a run renders the same name; tokens that are neither a
+// naming token nor a static variable are left in place.
+func RenderNamingTemplate(template string, automation *Automation, automationConfig *AutomationConfig, run *AutomationRun) string {
+	createdAt := run.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	createdAt = createdAt.UTC()
+
+	return artifactTokenPattern.ReplaceAllStringFunc(template, func(match string) string {
+		token := strings.TrimSpace(strings.Trim(match, "{}"))
+		switch token {
+		case "automationName":
+			return automation.Name
+		case "sequence":
+			return strconv.Itoa(run.Sequence)
+		case "date":
+			return createdAt.Format("2006-01-02")
+		case "time":
+			return createdAt.Format("1504")
+		case "attempt":
+			return strconv.Itoa(max(run.Attempt, 1))
+		case "runId":
+			return run.ID
+		case "automationId":
+			return automation.ID
+		case "projectId":
+			return automation.ProjectID
+		}
+		for _, variable := range automationConfig.Variables {
+			if variable.Key == token && variable.Type == "static" {
+				return variable.Value
+			}
+		}
+		return match
+	})
+}
+
+// runArtifactPrefix renders the automation's artifact prefix for a run as a clean relative
+// path, or returns an empty string when the automation has none
+func runArtifactPrefix(automation *Automation, automationConfig *AutomationConfig, run *AutomationRun) string {
+	if automationConfig.Naming.ArtifactPrefix == "" {
+		return ""
+	}
+
+	rendered := RenderNamingTemplate(automationConfig.Naming.ArtifactPrefix, automation, automationConfig, run)
+	var segments []string
+	for _, segment := range strings.Split(rendered, "/") {
+		segment = repeatedDashPattern.ReplaceAllString(sanitizePathSegment(segment), "-")
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// validateNaming checks that naming templates only use known tokens and the keys of the
+// automation's static variables
+func validateNaming(naming NamingConfig, staticVars map[string]bool) error {
+	for field, template := range map[string]string{"runName": naming.RunName, "artifactPrefix": naming.ArtifactPrefix} {
+		if len(template) > maxNamingTemplateLength {
+			return fmt.Errorf("naming.%s must be at most %d characters", field, maxNamingTemplateLength)
+		}
+		for _, match := range artifactTokenPattern.FindAllStringSubmatch(template, -1) {
+			token := strings.TrimSpace(match[1])
+			if !namingTokens[token] && !staticVars[token] {
+				return fmt.Errorf("naming.%s uses '{{%s}}', which is neither a naming token nor a static variable", field, token)
+			}
+		}
+	}
+	return nil
+}
+
+// nameRun numbers a new run among its automation's runs and renders its display name. It fixes
+// the run's creation time first, so names and artifact prefixes rendered later share its date.
+// Runs are still created when naming fails, just without a number or name.
+func nameRun(ctx context.Context, repo AutomationRepository, run *AutomationRun) {
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now()
+	}
+
+	sequence, err := repo.NextRunSequence(ctx, run.AutomationID)
+	if err != nil {
+		slog.Warn("Failed to number run", "automation_id", run.AutomationID, "error", err)
+		return
+	}
+	run.Sequence = sequence
+
+	automation, err := repo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Warn("Failed to get automation for run name", "automation_id", run.AutomationID, "error", err)
+		return
+	}
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig)
+	}
+	if automationConfig.Naming.RunName != "" {
+		run.DisplayName = strings.TrimSpace(RenderNamingTemplate(automationConfig.Naming.RunName, automation, &automationConfig, run))
+	}
+}
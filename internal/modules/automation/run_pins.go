@@ -0,0 +1,79 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// maxRunPinLabelLength caps the length of the label a run is pinned under
+const maxRunPinLabelLength = 100
+
+// PinRun keeps a run from being deleted by retention under label, e.g. "release 2.3 evidence".
+// Pinning a pinned run relabels it.
+func (s *automationService) PinRun(ctx context.Context, runID, label, userID string) (*RunPin, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, fmt.Errorf("%w: a label is required to pin a run", platform.ErrInvalidRequest)
+	}
+	if utf8.RuneCountInString(label) > maxRunPinLabelLength {
+		return nil, fmt.Errorf("%w: labels are at most %d characters", platform.ErrInvalidRequest, maxRunPinLabelLength)
+	}
+
+	run, err := s.automationRepo.GetRunByID(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: run not found", platform.ErrNotFound)
+	}
+	switch run.Status {
+	case "completed", "failed", "cancelled":
+	default:
+		return nil, fmt.Errorf("%w: only finished runs can be pinned", platform.ErrInvalidRequest)
+	}
+
+	pin := &RunPin{RunID: runID, Label: label, PinnedByUserID: userID}
+	if err := s.automationRepo.UpsertRunPin(ctx, pin); err != nil {
+		slog.Error("Failed to pin run", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to pin run: %w", err)
+	}
+
+	slog.Info("Run pinned", "runID", runID, "label", label)
+	return s.GetRunPin(ctx, runID)
+}
+
+// UnpinRun lets retention delete a run again
+func (s *automationService) UnpinRun(ctx context.Context, runID string) error {
+	if err := s.automationRepo.DeleteRunPin(ctx, runID); err != nil {
+		slog.Error("Failed to unpin run", "error", err, "runID", runID)
+		return fmt.Errorf("failed to unpin run: %w", err)
+	}
+
+	slog.Info("Run unpinned", "runID", runID)
+	return nil
+}
+
+// GetRunPin returns a run's pin, or nil when it isn't pinned
+func (s *automationService) GetRunPin(ctx context.Context, runID string) (*RunPin, error) {
+	pin, err := s.automationRepo.GetRunPin(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run pin", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run pin: %w", err)
+	}
+	return pin, nil
+}
+
+// GetPinnedRunsByProject lists a project's pinned runs, optionally only those pinned under label
+func (s *automationService) GetPinnedRunsByProject(ctx context.Context, projectID, label string) ([]*RunPin, error) {
+	pins, err := s.automationRepo.GetRunPinsByProjectID(ctx, projectID, strings.TrimSpace(label))
+	if err != nil {
+		slog.Error("Failed to get pinned runs by project", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get pinned runs: %w", err)
+	}
+	if pins == nil {
+		pins = []*RunPin{}
+	}
+	return pins, nil
+}
@@ -0,0 +1,221 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"text/template"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/storage"
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
+)
+
+// Summary cards use the 1.91:1 size chat apps and social sites expect of preview images
+const (
+	runPreviewWidth  = 1200
+	runPreviewHeight = 630
+)
+
+// maxRunPreviewNameLength keeps long automation names on one line of the card
+const maxRunPreviewNameLength = 48
+
+// PreviewImageRenderer rasterizes the SVG summary cards of runs, see media.MediaService
+type PreviewImageRenderer interface {
+	RenderPNG(ctx context.Context, svg []byte) ([]byte, error)
+}
+
+// RunPreviewGenerator renders a PNG summary card of every finished run, with its status, pass
+// rate and duration, so share links pasted into chats unfurl with the run's results
+type RunPreviewGenerator struct {
+	automationRepo AutomationRepository
+	storageService storage.StorageService
+	renderer       PreviewImageRenderer
+	jobs           chan finishedRunJob
+	stopCh         chan struct{}
+	doneCh         chan struct{}
+}
+
+// NewRunPreviewGenerator creates a run preview generator
+func NewRunPreviewGenerator(automationRepo AutomationRepository, storageService storage.StorageService, renderer PreviewImageRenderer) *RunPreviewGenerator {
+	return &RunPreviewGenerator{
+		automationRepo: automationRepo,
+		storageService: storageService,
+		renderer:       renderer,
+		jobs:           make(chan finishedRunJob, 256),
+		stopCh:         make(chan struct{}),
+		doneCh:         make(chan struct{}),
+	}
+}
+
+// Start begins generating the cards of queued runs
+func (g *RunPreviewGenerator) Start(ctx context.Context) {
+	slog.Info("Run preview generator started")
+
+	go func() {
+		defer close(g.doneCh)
+
+		for {
+			select {
+			case job := <-g.jobs:
+				if err := g.generate(ctx, &job.run); err != nil {
+					slog.Error("Failed to generate run preview", "run_id", job.run.ID, "error", err)
+				}
+			case <-g.stopCh:
+				slog.Info("Run preview generator stopped")
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the generator; runs still queued go without a card
+func (g *RunPreviewGenerator) Stop() {
+	close(g.stopCh)
+	<-g.doneCh
+}
+
+// Enqueue queues a finished run for its card. The run is copied, so callers may keep using it.
+func (g *RunPreviewGenerator) Enqueue(projectID string, run *AutomationRun) {
+	switch run.Status {
+	case "completed", "failed", "stalled", "cancelled":
+	default:
+		return
+	}
+
+	select {
+	case g.jobs <- finishedRunJob{projectID: projectID, run: *run}:
+	default:
+		slog.Warn("Run preview queue full, skipping run", "run_id", run.ID)
+	}
+}
+
+func (g *RunPreviewGenerator) generate(ctx context.Context, run *AutomationRun) error {
+	automation, err := g.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		return fmt.Errorf("failed to get automation: %w", err)
+	}
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig)
+	}
+
+	svg, err := renderRunPreviewSVG(i18n.For(reportLocale(ctx, g.automationRepo, automation, &automationConfig)), automation, run)
+	if err != nil {
+		return err
+	}
+	png, err := g.renderer.RenderPNG(ctx, svg)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("previews/%s/summary.png", run.ID)
+	imageURL, err := g.storageService.UploadFile(ctx, key, bytes.NewReader(png), "image/png")
+	if err != nil {
+		return fmt.Errorf("failed to upload run preview: %w", err)
+	}
+	return g.automationRepo.UpsertRunPreview(ctx, run.ID, imageURL)
+}
+
+// GetRunPreviewURL returns the URL of a run's summary card, or an empty string while it has none
+func (s *automationService) GetRunPreviewURL(ctx context.Context, runID string) (string, error) {
+	imageURL, err := s.automationRepo.GetRunPreviewURL(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run preview", "error", err, "runID", runID)
+		return "", fmt.Errorf("failed to get run preview: %w", err)
+	}
+	return imageURL, nil
+}
+
+// runPreviewCard is the data rendered by the summary card template
+type runPreviewCard struct {
+	L              *i18n.Localizer
+	Width, Height  int
+	AutomationName string
+	Status         string
+	Color          string
+	PassRate       string
+	Steps          string
+	Duration       string
+	FinishedAt     string
+}
+
+// renderRunPreviewSVG lays out a run's summary card. The pass rate is the share of steps that ran
+// without errors, counted once per user of a multi-user run.
+func renderRunPreviewSVG(l *i18n.Localizer, automation *Automation, run *AutomationRun) ([]byte, error) {
+	record, metrics := buildWarehouseRecords(automation.ProjectID, automation.Name, run)
+
+	name := []rune(automation.Name)
+	if len(name) > maxRunPreviewNameLength {
+		name = append(name[:maxRunPreviewNameLength-1], '…')
+	}
+	card := runPreviewCard{
+		L:              l,
+		Width:          runPreviewWidth,
+		Height:         runPreviewHeight,
+		AutomationName: string(name),
+		Status:         l.T("status." + run.Status),
+		Color:          runPreviewColor(run.Status),
+		PassRate:       "-",
+		Steps:          "-",
+		Duration:       "-",
+	}
+
+	if len(metrics) > 0 {
+		passed := 0
+		for _, metric := range metrics {
+			if metric.ErrorCount == 0 {
+				passed++
+			}
+		}
+		card.PassRate = fmt.Sprintf("%d%%", passed*100/len(metrics))
+		card.Steps = fmt.Sprintf("%d/%d", passed, len(metrics))
+	}
+	if record.DurationMs > 0 {
+		card.Duration = (time.Duration(record.DurationMs) * time.Millisecond).Round(time.Second / 10).String()
+	}
+	if run.EndTime != nil {
+		card.FinishedAt = run.EndTime.UTC().Format("2006-01-02 15:04 UTC")
+	}
+
+	var svg bytes.Buffer
+	if err := runPreviewTemplate.Execute(&svg, card); err != nil {
+		return nil, fmt.Errorf("failed to render run preview: %w", err)
+	}
+	return svg.Bytes(), nil
+}
+
+func runPreviewColor(status string) string {
+	switch status {
+	case "completed":
+		return "#059669"
+	case "failed", "stalled":
+		return "#dc2626"
+	default:
+		return "#6b7280"
+	}
+}
+
+var runPreviewTemplate = template.Must(template.New("preview").Funcs(template.FuncMap{
+	"xml": html.EscapeString,
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+<rect width="100%" height="100%" fill="#ffffff"/>
+<rect width="100%" height="16" fill="{{.Color}}"/>
+<g font-family="DejaVu Sans, Helvetica, Arial, sans-serif" fill="#1f2937">
+<text x="64" y="130" font-size="52" font-weight="bold">{{xml .AutomationName}}</text>
+<rect x="64" y="170" width="300" height="64" rx="32" fill="{{.Color}}"/>
+<text x="214" y="213" font-size="32" font-weight="bold" fill="#ffffff" text-anchor="middle">{{xml .Status}}</text>
+<text x="64" y="330" font-size="26" fill="#6b7280">{{xml (.L.T "report.pass_rate")}}</text>
+<text x="64" y="400" font-size="64" font-weight="bold">{{xml .PassRate}}</text>
+<text x="64" y="445" font-size="24" fill="#6b7280">{{xml (.L.T "report.steps")}} {{xml .Steps}}</text>
+<text x="520" y="330" font-size="26" fill="#6b7280">{{xml (.L.T "report.duration")}}</text>
+<text x="520" y="400" font-size="64" font-weight="bold">{{xml .Duration}}</text>
+{{if .FinishedAt}}<text x="64" y="566" font-size="24" fill="#6b7280">{{xml (.L.T "report.finished")}} {{xml .FinishedAt}}</text>{{end}}
+</g>
+</svg>
+`))
@@ -0,0 +1,359 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Run replay event kinds
+const (
+	ReplayStepStart    = "step_start"
+	ReplayStepEnd      = "step_end"
+	ReplayActionStart  = "action_start"
+	ReplayActionEnd    = "action_end"
+	ReplayActionFailed = "action_failed"
+	ReplayWarning      = "warning"
+	ReplayOutputFile   = "output_file"
+	ReplayVariable     = "variable"
+)
+
+// Replay page sizes
+const (
+	defaultReplayPageSize = 500
+	maxReplayPageSize     = 5000
+)
+
+// maxReplayValueBytes caps the encoded size of a variable value recorded for replay; longer
+// values are recorded as a truncated preview
+const maxReplayValueBytes = 2048
+
+// sensitiveVariablePattern matches variable and field names whose values replays don't reveal
+var sensitiveVariablePattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|credential|authorization)`)
+
+// RunReplayEvent is an entry of a run's replay timeline. Events are numbered in the order they
+// were recorded and their offsets never decrease, so a timeline can be paged by seq and sought by
+// offset alike.
+type RunReplayEvent struct {
+	Seq        int                    `json:"seq"`
+	OffsetMs   int64                  `json:"offset_ms"`
+	Kind       string                 `json:"kind"`
+	LoopIndex  int                    `json:"loop_index"`
+	StepID     string                 `json:"step_id,omitempty"`
+	StepName   string                 `json:"step_name,omitempty"`
+	ActionID   string                 `json:"action_id,omitempty"`
+	ActionType string                 `json:"action_type,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// RunReplayQuery selects a window of a run's replay timeline
+type RunReplayQuery struct {
+	FromMs   int64 // earliest offset
+	ToMs     int64 // latest offset, negative for no bound
+	AfterSeq int   // only events after this seq, for paging
+	Limit    int   // maximum events, 0 for all
+}
+
+// RunReplay is a page of a run's replay timeline
+type RunReplay struct {
+	RunID      string            `json:"run_id"`
+	Status     string            `json:"status"`
+	DurationMs int64             `json:"duration_ms"`
+	Events     []*RunReplayEvent `json:"events"`
+	HasMore    bool              `json:"has_more"`
+	NextSeq    int               `json:"next_seq"` // after_seq of the next page
+}
+
+// RunReplayState is what every user of a run was doing at a point of its timeline
+type RunReplayState struct {
+	AtMs  int64                 `json:"at_ms"`
+	Users []*RunReplayUserState `json:"users"`
+}
+
+// RunReplayUserState is the state of one user of a run at a point of its timeline
+type RunReplayUserState struct {
+	LoopIndex    int                               `json:"loop_index"`
+	StepID       string                            `json:"step_id,omitempty"`
+	StepName     string                            `json:"step_name,omitempty"`
+	ActionID     string                            `json:"action_id,omitempty"`
+	ActionType   string                            `json:"action_type,omitempty"`
+	ActionStatus string                            `json:"action_status,omitempty"` // running, success or failed
+	Message      string                            `json:"message,omitempty"`
+	Screenshot   string                            `json:"screenshot,omitempty"` // latest screenshot URL
+	Variables    map[string]map[string]interface{} `json:"variables"`            // values by scope and name
+	LastSeq      int                               `json:"last_seq"`
+}
+
+// runReplayRecorder turns the events of a run into its replay timeline, buffering them until
+// the next save
+type runReplayRecorder struct {
+	runID      string
+	start      time.Time
+	seq        int
+	lastOffset int64
+	pending    []*RunReplayEvent
+}
+
+func newRunReplayRecorder(run *AutomationRun) *runReplayRecorder {
+	start := time.Now()
+	if run.StartTime != nil {
+		start = *run.StartTime
+	}
+	return &runReplayRecorder{runID: run.ID, start: start}
+}
+
+// record adds an event to the timeline; events that aren't part of replays are ignored
+func (rec *runReplayRecorder) record(event RunEvent) {
+	data := map[string]interface{}{}
+	if event.ActionName != "" {
+		data["action_name"] = event.ActionName
+	}
+	if event.ParentActionID != "" {
+		data["parent_action_id"] = event.ParentActionID
+	}
+	if event.Duration > 0 {
+		data["duration_ms"] = event.Duration
+	}
+
+	var kind string
+	switch event.Type {
+	case RunEventTypeStep:
+		kind = ReplayStepStart
+		if phase, _ := event.Data["phase"].(string); phase == "end" {
+			kind = ReplayStepEnd
+		}
+	case RunEventTypeAction:
+		kind = ReplayActionStart
+	case RunEventTypeLog:
+		kind = ReplayActionEnd
+		data["message"] = event.Message
+	case RunEventTypeError:
+		kind = ReplayActionFailed
+		data["error"] = event.Error
+	case RunEventTypeWarning:
+		kind = ReplayWarning
+		data["message"] = event.Message
+	case RunEventTypeOutputFile:
+		kind = ReplayOutputFile
+		outputFile := NewOutputFileFromEvent(event)
+		data["url"] = outputFile.URL
+		data["kind"] = outputFile.Kind
+		data["content_type"] = outputFile.ContentType
+	case RunEventTypeVariable:
+		kind = ReplayVariable
+		data["changes"] = event.Data["changes"]
+	default:
+		return
+	}
+
+	// Parallel users report slightly out of order; offsets are kept in recording order
+	offset := max(event.Timestamp.Sub(rec.start).Milliseconds(), rec.lastOffset)
+	rec.lastOffset = offset
+	rec.seq++
+
+	rec.pending = append(rec.pending, &RunReplayEvent{
+		Seq:        rec.seq,
+		OffsetMs:   offset,
+		Kind:       kind,
+		LoopIndex:  event.LoopIndex,
+		StepID:     event.StepID,
+		StepName:   event.StepName,
+		ActionID:   event.ActionID,
+		ActionType: event.ActionType,
+		Data:       data,
+	})
+}
+
+// flushRunReplay stores the events recorded since the last flush; they are kept for the next one
+// when storing fails
+func (r *Runner) flushRunReplay(ctx context.Context, rec *runReplayRecorder) {
+	if len(rec.pending) == 0 {
+		return
+	}
+	if err := r.automationRepo.AppendRunReplayEvents(ctx, rec.runID, rec.pending); err != nil {
+		slog.Warn("Failed to store run replay events", "run_id", rec.runID, "events", len(rec.pending), "error", err)
+		return
+	}
+	rec.pending = nil
+}
+
+// emitRunEvent sends an event without holding up the run when the channel is full
+func emitRunEvent(eventCh chan<- RunEvent, event RunEvent) {
+	select {
+	case eventCh <- event:
+	default:
+		// Channel is full, skip this event to avoid blocking
+	}
+}
+
+// replayVariableTracker remembers a user's variables as last recorded, so replays only record
+// the ones an action changed
+type replayVariableTracker struct {
+	seen map[string]string // encoded values by scope and name
+}
+
+func newReplayVariableTracker() *replayVariableTracker {
+	return &replayVariableTracker{seen: make(map[string]string)}
+}
+
+// changes returns the variables set, changed or removed since the last call, as recorded by
+// replays
+func (t *replayVariableTracker) changes(varContext *VariableContext) []map[string]interface{} {
+	var changes []map[string]interface{}
+	current := make(map[string]bool)
+
+	for _, scope := range []struct {
+		name string
+		vars map[string]interface{}
+	}{{"local", varContext.RuntimeVars}, {"global", varContext.GlobalVars}} {
+		names := make([]string, 0, len(scope.vars))
+		for name := range scope.vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			encoded, err := json.Marshal(scope.vars[name])
+			if err != nil {
+				encoded, _ = json.Marshal(fmt.Sprintf("%v", scope.vars[name]))
+			}
+			key := scope.name + "\x00" + name
+			current[key] = true
+			if t.seen[key] == string(encoded) {
+				continue
+			}
+			t.seen[key] = string(encoded)
+			changes = append(changes, replayVariableChange(scope.name, name, encoded))
+		}
+	}
+
+	removed := make([]string, 0)
+	for key := range t.seen {
+		if !current[key] {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		delete(t.seen, key)
+		scope, name, _ := strings.Cut(key, "\x00")
+		changes = append(changes, map[string]interface{}{"scope": scope, "name": name, "removed": true})
+	}
+
+	return changes
+}
+
+// replayVariableChange describes a variable's new value for a replay, hiding sensitive values and
+// shortening long ones
+func replayVariableChange(scope, name string, encoded []byte) map[string]interface{} {
+	change := map[string]interface{}{"scope": scope, "name": name}
+	if sensitiveVariablePattern.MatchString(name) {
+		change["value"] = "********"
+		return change
+	}
+
+	var value interface{}
+	json.Unmarshal(encoded, &value)
+	value = maskSensitiveFields(value)
+	if masked, err := json.Marshal(value); err == nil && len(masked) > maxReplayValueBytes {
+		change["value"] = string(masked[:maxReplayValueBytes])
+		change["truncated"] = true
+		return change
+	}
+	change["value"] = value
+	return change
+}
+
+// maskSensitiveFields hides the values of sensitive fields nested in a decoded JSON value, such as
+// the credentials of a leased account
+func maskSensitiveFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if sensitiveVariablePattern.MatchString(key) {
+				v[key] = "********"
+			} else {
+				v[key] = maskSensitiveFields(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = maskSensitiveFields(child)
+		}
+	}
+	return value
+}
+
+// ReplayStateAt folds a run's replay events, in order, into the state of each user at atMs
+func ReplayStateAt(events []*RunReplayEvent, atMs int64) *RunReplayState {
+	users := make(map[int]*RunReplayUserState)
+	for _, event := range events {
+		if event.OffsetMs > atMs {
+			break
+		}
+		user, ok := users[event.LoopIndex]
+		if !ok {
+			user = &RunReplayUserState{
+				LoopIndex: event.LoopIndex,
+				Variables: map[string]map[string]interface{}{"local": {}, "global": {}},
+			}
+			users[event.LoopIndex] = user
+		}
+		user.LastSeq = event.Seq
+
+		// Nested actions report within the action that runs them
+		_, nested := event.Data["parent_action_id"]
+
+		switch event.Kind {
+		case ReplayStepStart:
+			user.StepID, user.StepName = event.StepID, event.StepName
+			user.ActionID, user.ActionType, user.ActionStatus, user.Message = "", "", "", ""
+		case ReplayActionStart:
+			user.ActionID, user.ActionType, user.ActionStatus, user.Message = event.ActionID, event.ActionType, "running", ""
+		case ReplayActionEnd:
+			user.Message, _ = event.Data["message"].(string)
+			if !nested && event.ActionID == user.ActionID {
+				user.ActionStatus = "success"
+			}
+		case ReplayActionFailed:
+			user.Message, _ = event.Data["error"].(string)
+			if !nested {
+				user.ActionStatus = "failed"
+			}
+		case ReplayWarning:
+			user.Message, _ = event.Data["message"].(string)
+		case ReplayOutputFile:
+			if kind, _ := event.Data["kind"].(string); kind == string(OutputFileKindScreenshot) {
+				user.Screenshot, _ = event.Data["url"].(string)
+			}
+		case ReplayVariable:
+			changes, _ := event.Data["changes"].([]interface{})
+			for _, c := range changes {
+				change, _ := c.(map[string]interface{})
+				scope, _ := change["scope"].(string)
+				name, _ := change["name"].(string)
+				vars, ok := user.Variables[scope]
+				if !ok {
+					continue
+				}
+				if removed, _ := change["removed"].(bool); removed {
+					delete(vars, name)
+				} else {
+					vars[name] = change["value"]
+				}
+			}
+		}
+	}
+
+	state := &RunReplayState{AtMs: atMs, Users: make([]*RunReplayUserState, 0, len(users))}
+	for _, user := range users {
+		state.Users = append(state.Users, user)
+	}
+	sort.Slice(state.Users, func(i, j int) bool { return state.Users[i].LoopIndex < state.Users[j].LoopIndex })
+	return state
+}
@@ -0,0 +1,357 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
+	"github.com/playwright-community/playwright-go"
+)
+
+// Run report formats
+const (
+	RunReportFormatHTML = "html"
+	RunReportFormatPDF  = "pdf"
+)
+
+// runReportPDFTimeout bounds rendering a report to PDF, in milliseconds
+const runReportPDFTimeout = 30000
+
+// maxReportArtifactDiffRows bounds the diff lines or JSON differences a report shows per download
+const maxReportArtifactDiffRows = 50
+
+// runReportStep summarises one step across the users of a run
+type runReportStep struct {
+	Name          string
+	Users         int
+	Actions       int
+	Errors        int
+	SlowUsers     int // Users who took longer than the step's expected duration
+	AvgDurationMs int64
+	MaxDurationMs int64
+}
+
+// runReport is the data rendered by the run report template
+type runReport struct {
+	L              *i18n.Localizer
+	AutomationName string
+	Run            *AutomationRun
+	DurationMs     int64
+	LogCount       int
+	ErrorCount     int
+	Steps          []runReportStep
+	Timers         []TimerStat
+	Anomalies      []*RunAnomaly
+	OutputFiles    []OutputFile
+	ArtifactDiffs  []*ArtifactDiff
+	GeneratedAt    *time.Time
+}
+
+// renderRunReport renders the stakeholder report of a run as a standalone HTML page, or as a PDF
+// printed from that page, with labels in the automation's report locale
+func renderRunReport(ctx context.Context, repo AutomationRepository, automation *Automation, automationConfig *AutomationConfig, run *AutomationRun, format string) ([]byte, error) {
+	steps, err := repo.GetStepsByAutomationID(ctx, automation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get steps: %w", err)
+	}
+	anomalies, err := repo.GetRunAnomaliesByRunID(ctx, run.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run anomalies: %w", err)
+	}
+
+	now := time.Now()
+	record, metrics := buildWarehouseRecords(automation.ProjectID, automation.Name, run)
+	report := runReport{
+		L:              i18n.For(reportLocale(ctx, repo, automation, automationConfig)),
+		AutomationName: automation.Name,
+		Run:            run,
+		DurationMs:     record.DurationMs,
+		LogCount:       record.LogCount,
+		ErrorCount:     record.ErrorCount,
+		Steps:          summarizeReportSteps(steps, metrics, slowStepUsers(run)),
+		Timers:         run.TimerStats(),
+		Anomalies:      anomalies,
+		OutputFiles:    run.OutputFiles(),
+		ArtifactDiffs:  reportArtifactDiffs(ctx, repo, run),
+		GeneratedAt:    &now,
+	}
+
+	var html bytes.Buffer
+	if err := runReportTemplate.Execute(&html, report); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	if format == RunReportFormatHTML {
+		return html.Bytes(), nil
+	}
+	return printHTMLToPDF(ctx, html.String())
+}
+
+// reportArtifactDiffs compares the run's downloads against the automation's fixtures, shortening
+// the diffs for the report. The report goes without them when fixtures can't be loaded.
+func reportArtifactDiffs(ctx context.Context, repo AutomationRepository, run *AutomationRun) []*ArtifactDiff {
+	fixtures, err := repo.GetArtifactFixturesByAutomationID(ctx, run.AutomationID, true)
+	if err != nil {
+		slog.Warn("Failed to get artifact fixtures for report", "run_id", run.ID, "error", err)
+		return nil
+	}
+	if len(fixtures) == 0 {
+		return nil
+	}
+
+	diffs := compareArtifacts(ctx, fixtures, run.OutputFiles(), false)
+	for _, diff := range diffs {
+		if len(diff.Lines) > maxReportArtifactDiffRows {
+			diff.Lines = diff.Lines[:maxReportArtifactDiffRows]
+			diff.Truncated = true
+		}
+		if len(diff.Differences) > maxReportArtifactDiffRows {
+			diff.Differences = diff.Differences[:maxReportArtifactDiffRows]
+			diff.Truncated = true
+		}
+	}
+	return diffs
+}
+
+// slowStepUsers counts, per step ID, the users of a run warned that the step exceeded its
+// expected duration
+func slowStepUsers(run *AutomationRun) map[string]int {
+	var logs []map[string]any
+	if run.LogsJSON != "" {
+		json.Unmarshal([]byte(run.LogsJSON), &logs)
+	}
+
+	slow := make(map[string]int)
+	for _, entry := range logs {
+		if actionType, _ := entry["action_type"].(string); actionType != StepSlowActionType {
+			continue
+		}
+		if stepID, _ := entry["step_id"].(string); stepID != "" {
+			slow[stepID]++
+		}
+	}
+	return slow
+}
+
+// summarizeReportSteps folds per user step metrics into one row per step, in step order, with
+// how many users were slow in slowUsers. Steps deleted since the run follow in name order.
+func summarizeReportSteps(steps []*AutomationStep, metrics []WarehouseStepMetric, slowUsers map[string]int) []runReportStep {
+	order := make(map[string]int, len(steps))
+	for _, step := range steps {
+		order[step.ID] = step.StepOrder
+	}
+
+	byStep := make(map[string]*runReportStep)
+	totals := make(map[string]int64)
+	var stepIDs []string
+	for _, metric := range metrics {
+		summary, ok := byStep[metric.StepID]
+		if !ok {
+			summary = &runReportStep{Name: metric.StepName}
+			byStep[metric.StepID] = summary
+			stepIDs = append(stepIDs, metric.StepID)
+		}
+		summary.Users++
+		summary.Actions += metric.ActionCount
+		summary.Errors += metric.ErrorCount
+		summary.MaxDurationMs = max(summary.MaxDurationMs, metric.DurationMs)
+		totals[metric.StepID] += metric.DurationMs
+	}
+
+	sort.Slice(stepIDs, func(i, j int) bool {
+		orderI, knownI := order[stepIDs[i]]
+		orderJ, knownJ := order[stepIDs[j]]
+		if knownI != knownJ {
+			return knownI
+		}
+		if orderI != orderJ {
+			return orderI < orderJ
+		}
+		return byStep[stepIDs[i]].Name < byStep[stepIDs[j]].Name
+	})
+
+	summaries := make([]runReportStep, len(stepIDs))
+	for i, stepID := range stepIDs {
+		summary := byStep[stepID]
+		summary.AvgDurationMs = totals[stepID] / int64(summary.Users)
+		summary.SlowUsers = slowUsers[stepID]
+		summaries[i] = *summary
+	}
+	return summaries
+}
+
+// printHTMLToPDF prints an HTML page to an A4 PDF with a headless chromium. Cancelling ctx
+// abandons the print and closes the browser.
+func printHTMLToPDF(ctx context.Context, html string) ([]byte, error) {
+	pw, err := playwright.Run()
+	if err != nil {
+		return nil, fmt.Errorf("could not start playwright: %w", err)
+	}
+	defer pw.Stop()
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+		Args:     []string{"--no-sandbox", "--disable-setuid-sandbox", "--disable-dev-shm-usage", "--disable-gpu"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not launch browser: %w", err)
+	}
+	defer browser.Close()
+
+	type printed struct {
+		pdf []byte
+		err error
+	}
+	done := make(chan printed, 1)
+	go func() {
+		pdf, err := printPage(browser, html)
+		done <- printed{pdf, err}
+	}()
+
+	// Returning closes the browser, which fails a print still in progress
+	select {
+	case result := <-done:
+		return result.pdf, result.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("report printing cancelled: %w", ctx.Err())
+	}
+}
+
+// printPage loads a report into a new page of browser and prints it
+func printPage(browser playwright.Browser, html string) ([]byte, error) {
+	// The report is self-contained, so scripts are disabled and every request is refused
+	page, err := browser.NewPage(playwright.BrowserNewPageOptions{JavaScriptEnabled: playwright.Bool(false)})
+	if err != nil {
+		return nil, fmt.Errorf("could not create page: %w", err)
+	}
+	if err := page.Route("**/*", func(route playwright.Route) { route.Abort() }); err != nil {
+		return nil, fmt.Errorf("could not block report requests: %w", err)
+	}
+	if err := page.SetContent(html, playwright.PageSetContentOptions{Timeout: playwright.Float(runReportPDFTimeout)}); err != nil {
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+
+	pdf, err := page.PDF(playwright.PagePdfOptions{
+		Format:          playwright.String("A4"),
+		PrintBackground: playwright.Bool(true),
+		Margin: &playwright.Margin{
+			Top:    playwright.String("16mm"),
+			Right:  playwright.String("12mm"),
+			Bottom: playwright.String("16mm"),
+			Left:   playwright.String("12mm"),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to print report: %w", err)
+	}
+	return pdf, nil
+}
+
+var runReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).Round(time.Millisecond).String()
+	},
+	"timestamp": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+	"status": func(l *i18n.Localizer, status string) string {
+		return l.T("status." + status)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.L.Locale}}">
+<head>
+<meta charset="utf-8">
+<title>{{.L.T "report.title"}} · {{.AutomationName}}</title>
+<style>
+body{margin:0;font-family:system-ui,sans-serif;font-size:12px;color:#1f2937}
+h1{font-size:20px;margin:0 0 4px}
+h2{font-size:14px;margin:24px 0 8px;border-bottom:1px solid #e5e7eb;padding-bottom:4px}
+.muted{color:#6b7280}
+.status{display:inline-block;padding:2px 10px;border-radius:9999px;font-weight:600;background:#e5e7eb}
+.status.completed{background:#d1fae5;color:#065f46}
+.status.failed,.status.stalled{background:#fee2e2;color:#991b1b}
+.status.slow{background:#fef3c7;color:#92400e;font-size:10px;margin-left:4px}
+.summary{display:flex;flex-wrap:wrap;gap:12px;margin-top:16px}
+.summary div{flex:1;min-width:110px;border:1px solid #e5e7eb;border-radius:6px;padding:8px}
+.summary b{display:block;font-size:16px;margin-top:2px}
+table{width:100%;border-collapse:collapse}
+th,td{text-align:left;padding:4px 6px;border-bottom:1px solid #f3f4f6;vertical-align:top}
+th{font-weight:600;color:#4b5563}
+td.num,th.num{text-align:right}
+.error{color:#991b1b;white-space:pre-wrap;word-break:break-word}
+a{color:#1d4ed8;word-break:break-all}
+.artifact{border:1px solid #e5e7eb;border-radius:6px;padding:8px;margin-bottom:8px}
+pre.diff{font-size:11px;background:#f9fafb;padding:6px;white-space:pre-wrap;word-break:break-all}
+pre.diff .removed{background:#fee2e2;color:#991b1b}
+pre.diff .added{background:#d1fae5;color:#065f46}
+</style>
+</head>
+<body>
+<h1>{{.AutomationName}}</h1>
+<div class="muted">{{.L.T "report.title"}} · {{.L.T "report.run_id"}} {{.Run.ID}}</div>
+<div class="summary">
+<div>{{.L.T "report.status"}}<b><span class="status {{.Run.Status}}">{{status .L .Run.Status}}</span></b></div>
+<div>{{.L.T "report.duration"}}<b>{{duration .DurationMs}}</b></div>
+<div>{{.L.T "report.started"}}<b>{{timestamp .Run.StartTime}}</b></div>
+<div>{{.L.T "report.finished"}}<b>{{timestamp .Run.EndTime}}</b></div>
+<div>{{.L.T "report.log_entries"}}<b>{{.LogCount}}</b></div>
+<div>{{.L.T "report.errors"}}<b>{{.ErrorCount}}</b></div>
+</div>
+{{if .Run.ErrorMessage}}
+<h2>{{.L.T "report.error"}}</h2>
+<div class="error">{{.Run.ErrorMessage}}</div>
+{{end}}
+{{if .Steps}}
+<h2>{{.L.T "report.steps"}}</h2>
+<table>
+<tr><th>{{.L.T "report.step"}}</th><th class="num">{{.L.T "report.users"}}</th><th class="num">{{.L.T "report.actions"}}</th><th class="num">{{.L.T "report.errors"}}</th><th class="num">{{.L.T "report.slow_users"}}</th><th class="num">{{.L.T "report.avg_duration"}}</th><th class="num">{{.L.T "report.max_duration"}}</th></tr>
+{{range .Steps}}<tr><td>{{.Name}}{{if .SlowUsers}}<span class="status slow">{{$.L.T "report.slow"}}</span>{{end}}</td><td class="num">{{.Users}}</td><td class="num">{{.Actions}}</td><td class="num">{{.Errors}}</td><td class="num">{{.SlowUsers}}</td><td class="num">{{duration .AvgDurationMs}}</td><td class="num">{{duration .MaxDurationMs}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Timers}}
+<h2>{{.L.T "report.transactions"}}</h2>
+<table>
+<tr><th>{{.L.T "report.transaction"}}</th><th class="num">{{.L.T "report.count"}}</th><th class="num">{{.L.T "report.incomplete"}}</th><th class="num">{{.L.T "report.min_duration"}}</th><th class="num">{{.L.T "report.avg_duration"}}</th><th class="num">p50</th><th class="num">p90</th><th class="num">p95</th><th class="num">p99</th><th class="num">{{.L.T "report.max_duration"}}</th></tr>
+{{range .Timers}}<tr><td>{{.Name}}</td><td class="num">{{.Count}}</td><td class="num">{{.Incomplete}}</td><td class="num">{{duration .MinMs}}</td><td class="num">{{duration .AvgMs}}</td><td class="num">{{duration .P50Ms}}</td><td class="num">{{duration .P90Ms}}</td><td class="num">{{duration .P95Ms}}</td><td class="num">{{duration .P99Ms}}</td><td class="num">{{duration .MaxMs}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Anomalies}}
+<h2>{{.L.T "report.anomalies"}}</h2>
+<ul>
+{{range .Anomalies}}<li>{{if eq .Scope "step"}}{{$.L.T "anomaly.step" .StepName .DurationMs .Deviation .BaselineMeanMs}}{{else}}{{$.L.T "anomaly.run" .DurationMs .Deviation .BaselineMeanMs}}{{end}}</li>
+{{end}}</ul>
+{{end}}
+{{if .ArtifactDiffs}}
+<h2>{{.L.T "report.artifact_comparisons"}}</h2>
+{{range .ArtifactDiffs}}<div class="artifact">
+<b>{{.FixtureName}}</b> <span class="status {{if .Equal}}completed{{else}}failed{{end}}">{{if .Equal}}{{$.L.T "report.artifact_matches"}}{{else}}{{$.L.T "report.artifact_differs"}}{{end}}</span>
+{{if .ArtifactURL}}<div class="muted">{{.StepName}} · <a href="{{.ArtifactURL}}">{{.ArtifactURL}}</a></div>{{end}}
+{{if .Error}}<div class="error">{{.Error}}</div>{{else}}<div>{{.Summary}}</div>{{end}}
+{{if .Lines}}<pre class="diff">{{range .Lines}}<span class="{{.Op}}">{{if eq .Op "removed"}}-{{else if eq .Op "added"}}+{{else}} {{end}} {{.Text}}</span>
+{{end}}</pre>{{end}}
+{{if .Differences}}<table>
+<tr><th>{{$.L.T "report.json_path"}}</th><th>{{$.L.T "report.expected"}}</th><th>{{$.L.T "report.actual"}}</th></tr>
+{{range .Differences}}<tr><td>{{.Path}}</td><td>{{.Expected}}</td><td>{{.Actual}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Truncated}}<div class="muted">{{$.L.T "report.diff_truncated"}}</div>{{end}}
+</div>
+{{end}}
+{{end}}
+{{if .OutputFiles}}
+<h2>{{.L.T "report.output_files"}}</h2>
+<table>
+{{range .OutputFiles}}<tr><td>{{.Kind}}</td><td>{{.StepName}}</td><td><a href="{{.URL}}">{{.URL}}</a></td></tr>
+{{end}}</table>
+{{end}}
+<p class="muted">{{.L.T "report.generated_at" (timestamp .GeneratedAt)}}</p>
+</body>
+</html>
+`))
@@ -0,0 +1,76 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// maxRunRetries caps the retries an automation may configure
+const maxRunRetries = 10
+
+// retryFailedRun queues the next attempt of a failed run while the automation's retries allow,
+// linked to the run it retries so the attempts can be compared
+func (s *Scheduler) retryFailedRun(ctx context.Context, projectID string, run *AutomationRun) {
+	automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get automation for retry", "run_id", run.ID, "error", err)
+		return
+	}
+	// Retries read the config the way runs do, upgraded from older formats
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		configJSON, _, err := UpgradeAutomationConfigJSON(automation.ConfigJSON)
+		if err != nil {
+			slog.Error("Failed to upgrade automation config for retry", "run_id", run.ID, "error", err)
+			return
+		}
+		if err := json.Unmarshal([]byte(configJSON), &automationConfig); err != nil {
+			slog.Error("Failed to parse automation config for retry", "run_id", run.ID, "error", err)
+			return
+		}
+	}
+
+	attempt := max(run.Attempt, 1)
+	if attempt > min(automationConfig.Retries, maxRunRetries) {
+		return
+	}
+	if err := checkAutomationEnabled(ctx, s.automationRepo, run.AutomationID); err != nil {
+		slog.Info("Not retrying run of a disabled automation", "run_id", run.ID, "reason", err)
+		return
+	}
+	// Failures during planned maintenance are expected, retrying them would fail again
+	if window := runMaintenanceWindow(ctx, s.automationRepo, run); window != nil {
+		slog.Info("Not retrying run failed during maintenance", "run_id", run.ID, "maintenance", window.Summary)
+		return
+	}
+
+	retry := &AutomationRun{
+		ID:              platform.UtilGenerateUUID(),
+		AutomationID:    run.AutomationID,
+		Status:          "pending",
+		LogsJSON:        "[]",
+		OutputFilesJSON: "[]",
+		IncludeTags:     run.IncludeTags,
+		ExcludeTags:     run.ExcludeTags,
+		Locale:          run.Locale,
+		ParentRunID:     run.ID,
+		Attempt:         attempt + 1,
+	}
+	nameRun(ctx, s.automationRepo, retry)
+	if err := s.automationRepo.CreateRun(ctx, retry); err != nil {
+		slog.Error("Failed to create retry run", "run_id", run.ID, "error", err)
+		return
+	}
+	s.automationService.RecordRunConfig(ctx, retry)
+	if err := s.runCache.SetRunStatus(ctx, retry.ID, "pending"); err != nil {
+		slog.Warn("Failed to set pending status in cache", "run_id", retry.ID, "error", err)
+	}
+	if s.sseManager != nil {
+		s.sseManager.SendRunStatusUpdate(projectID, retry.AutomationID, retry.ID, "pending")
+	}
+
+	slog.Info("Retrying failed run", "run_id", run.ID, "retry_run_id", retry.ID, "attempt", retry.Attempt)
+}
@@ -0,0 +1,120 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Keys of the transaction timer fields in run events and log entries
+const (
+	TimerNameKey     = "timer"
+	TimerDurationKey = "timer_ms"
+)
+
+// TimerIncompleteActionType is the action type of the warning logged for a timer that was still
+// running when its user finished
+const TimerIncompleteActionType = "metric:timer_incomplete"
+
+// TimerStat summarises the durations of one transaction timer across the users of a run
+type TimerStat struct {
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+	Incomplete int    `json:"incomplete"` // users that started the timer but never stopped it
+	MinMs      int64  `json:"min_ms"`
+	MaxMs      int64  `json:"max_ms"`
+	AvgMs      int64  `json:"avg_ms"`
+	P50Ms      int64  `json:"p50_ms"`
+	P90Ms      int64  `json:"p90_ms"`
+	P95Ms      int64  `json:"p95_ms"`
+	P99Ms      int64  `json:"p99_ms"`
+}
+
+// reportIncompleteTimers logs a warning for each timer of a user that was started but never
+// stopped, e.g. because an action between them failed
+func reportIncompleteTimers(runContext *RunContext) {
+	if runContext.EventCh == nil {
+		return
+	}
+	names := make([]string, 0, len(runContext.Timers))
+	for name := range runContext.Timers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		emitRunEvent(runContext.EventCh, RunEvent{
+			Type:           RunEventTypeWarning,
+			Timestamp:      time.Now(),
+			ActionType:     TimerIncompleteActionType,
+			Message:        fmt.Sprintf("Timer '%s' was started but never stopped", name),
+			LoopIndex:      runContext.LoopIndex,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Data:           map[string]interface{}{TimerNameKey: name},
+		})
+	}
+}
+
+// TimerStats computes the transaction timer statistics of the run from its logs, ordered by timer
+// name. Percentiles use the nearest rank.
+func (r *AutomationRun) TimerStats() []TimerStat {
+	var logs []map[string]any
+	if r.LogsJSON != "" {
+		json.Unmarshal([]byte(r.LogsJSON), &logs)
+	}
+
+	durations := make(map[string][]int64)
+	incomplete := make(map[string]int)
+	for _, entry := range logs {
+		if actionType, _ := entry["action_type"].(string); actionType == TimerIncompleteActionType {
+			data, _ := entry["data"].(map[string]any)
+			if name, _ := data[TimerNameKey].(string); name != "" {
+				incomplete[name]++
+			}
+			continue
+		}
+		name, _ := entry[TimerNameKey].(string)
+		ms, ok := entry[TimerDurationKey].(float64)
+		if name == "" || !ok {
+			continue
+		}
+		durations[name] = append(durations[name], int64(ms))
+	}
+
+	names := make([]string, 0, len(durations))
+	for name := range durations {
+		names = append(names, name)
+	}
+	for name := range incomplete {
+		if _, ok := durations[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	stats := make([]TimerStat, 0, len(names))
+	for _, name := range names {
+		stat := TimerStat{Name: name, Count: len(durations[name]), Incomplete: incomplete[name]}
+		if sorted := durations[name]; len(sorted) > 0 {
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			var total int64
+			for _, ms := range sorted {
+				total += ms
+			}
+			percentile := func(p float64) int64 {
+				return sorted[max(int(math.Ceil(p*float64(len(sorted))))-1, 0)]
+			}
+			stat.MinMs = sorted[0]
+			stat.MaxMs = sorted[len(sorted)-1]
+			stat.AvgMs = total / int64(len(sorted))
+			stat.P50Ms = percentile(0.50)
+			stat.P90Ms = percentile(0.90)
+			stat.P95Ms = percentile(0.95)
+			stat.P99Ms = percentile(0.99)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
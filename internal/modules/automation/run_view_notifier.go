@@ -0,0 +1,147 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/delordemm1/qplayground/internal/modules/notification"
+)
+
+// RunViewNotifier emails the subscribers of run views about finished runs matching the views'
+// filters. Like automation notification channels, it notifies completed, failed and stalled runs.
+type RunViewNotifier struct {
+	automationRepo      AutomationRepository
+	notificationService notification.NotificationService
+	jobs                chan finishedRunJob
+	stopCh              chan struct{}
+	doneCh              chan struct{}
+}
+
+// NewRunViewNotifier creates a run view notifier
+func NewRunViewNotifier(automationRepo AutomationRepository, notificationService notification.NotificationService) *RunViewNotifier {
+	return &RunViewNotifier{
+		automationRepo:      automationRepo,
+		notificationService: notificationService,
+		jobs:                make(chan finishedRunJob, 256),
+		stopCh:              make(chan struct{}),
+		doneCh:              make(chan struct{}),
+	}
+}
+
+// Start begins matching queued runs against subscribed views
+func (n *RunViewNotifier) Start(ctx context.Context) {
+	slog.Info("Run view notifier started")
+
+	go func() {
+		defer close(n.doneCh)
+
+		for {
+			select {
+			case job := <-n.jobs:
+				n.notify(ctx, job)
+			case <-n.stopCh:
+				slog.Info("Run view notifier stopped")
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the notifier; runs still queued are not notified
+func (n *RunViewNotifier) Stop() {
+	close(n.stopCh)
+	<-n.doneCh
+}
+
+// Enqueue queues a finished run for matching. The run is copied, so callers may keep using it.
+func (n *RunViewNotifier) Enqueue(projectID string, run *AutomationRun) {
+	switch run.Status {
+	case "completed", "failed", "stalled":
+	default:
+		return
+	}
+
+	select {
+	case n.jobs <- finishedRunJob{projectID: projectID, run: *run}:
+	default:
+		slog.Warn("Run view notification queue full, skipping run", "run_id", run.ID)
+	}
+}
+
+func (n *RunViewNotifier) notify(ctx context.Context, job finishedRunJob) {
+	run := &job.run
+
+	subscriptions, err := n.automationRepo.GetRunViewSubscriptionsByProjectID(ctx, job.projectID)
+	if err != nil {
+		slog.Error("Failed to get run view subscriptions", "error", err, "projectID", job.projectID)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	if silencedForRollout(ctx, n.automationRepo, run.AutomationID, "run view") {
+		return
+	}
+	if silencedForMaintenance(ctx, n.automationRepo, run, "run view") {
+		return
+	}
+
+	automation, err := n.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get automation for run view notifications", "error", err, "automationID", run.AutomationID)
+		return
+	}
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig)
+	}
+	locale := reportLocale(ctx, n.automationRepo, automation, &automationConfig)
+
+	for _, subscription := range subscriptions {
+		// Filters are evaluated as the subscriber, so triggered_by=me means them
+		filter, err := ParseRunFilter(subscription.Filter, subscription.UserID)
+		if err != nil {
+			slog.Warn("Skipping run view with an invalid filter", "view_id", subscription.ViewID, "error", err)
+			continue
+		}
+		matches, err := n.automationRepo.GetRuns(ctx, RunQuery{ProjectID: job.projectID, RunID: run.ID, Filter: filter, Limit: 1})
+		if err != nil {
+			slog.Error("Failed to match run against run view", "view_id", subscription.ViewID, "run_id", run.ID, "error", err)
+			continue
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		message := notification.NotificationMessage{
+			AutomationID:   automation.ID,
+			AutomationName: automation.Name,
+			ProjectID:      automation.ProjectID,
+			RunID:          run.ID,
+			Status:         run.Status,
+			StartTime:      run.StartTime,
+			EndTime:        run.EndTime,
+			ErrorMessage:   run.ErrorMessage,
+			Locale:         locale,
+			SavedView:      subscription.ViewName,
+		}
+		channels := []notification.NotificationChannelConfig{{
+			ID:         "run-view-" + subscription.ViewID,
+			Type:       "email",
+			OnComplete: true,
+			OnError:    true,
+			Config:     map[string]interface{}{"to": subscription.Email},
+		}}
+		if err := n.notificationService.DispatchAutomationNotification(ctx, message, channels); err != nil {
+			slog.Error("Failed to dispatch run view notification", "view_id", subscription.ViewID, "run_id", run.ID, "error", err)
+			continue
+		}
+		if err := n.automationRepo.MarkRunViewNotified(ctx, subscription.ViewID, subscription.UserID); err != nil {
+			slog.Error("Failed to record run view notification", "view_id", subscription.ViewID, "error", err)
+		}
+	}
+}
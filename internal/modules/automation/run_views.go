@@ -0,0 +1,162 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// maxRunViewNameLength matches the name column of run_views
+const maxRunViewNameLength = 100
+
+// validateRunView normalizes a view and checks its name and filter
+func validateRunView(view *RunView, userID string) error {
+	view.Name = strings.TrimSpace(view.Name)
+	view.Filter = strings.TrimSpace(view.Filter)
+	if view.Name == "" {
+		return fmt.Errorf("%w: name is required", platform.ErrInvalidRequest)
+	}
+	if utf8.RuneCountInString(view.Name) > maxRunViewNameLength {
+		return fmt.Errorf("%w: name is at most %d characters", platform.ErrInvalidRequest, maxRunViewNameLength)
+	}
+	if view.Filter == "" {
+		return fmt.Errorf("%w: filter is required", platform.ErrInvalidRequest)
+	}
+	if _, err := ParseRunFilter(view.Filter, userID); err != nil {
+		return fmt.Errorf("%w: invalid filter: %s", platform.ErrInvalidRequest, err)
+	}
+	return nil
+}
+
+// checkRunViewName makes sure the views of a creator have distinct names within a project
+func (s *automationService) checkRunViewName(ctx context.Context, view *RunView) error {
+	views, err := s.automationRepo.GetRunViewsByProjectID(ctx, view.ProjectID, view.CreatedByUserID)
+	if err != nil {
+		slog.Error("Failed to get run views", "error", err, "projectID", view.ProjectID)
+		return fmt.Errorf("failed to get run views: %w", err)
+	}
+	for _, existing := range views {
+		if existing.ID != view.ID && existing.CreatedByUserID == view.CreatedByUserID && existing.Name == view.Name {
+			return fmt.Errorf("%w: you already have a view named '%s'", platform.ErrConflict, view.Name)
+		}
+	}
+	return nil
+}
+
+// CreateRunView saves a run filter of a project for view.CreatedByUserID
+func (s *automationService) CreateRunView(ctx context.Context, view *RunView) (*RunView, error) {
+	if err := validateRunView(view, view.CreatedByUserID); err != nil {
+		return nil, err
+	}
+	if err := s.checkRunViewName(ctx, view); err != nil {
+		return nil, err
+	}
+
+	view.ID = platform.UtilGenerateUUID()
+	view.Subscribed = false
+	if err := s.automationRepo.CreateRunView(ctx, view); err != nil {
+		slog.Error("Failed to create run view", "error", err, "projectID", view.ProjectID)
+		return nil, fmt.Errorf("failed to create run view: %w", err)
+	}
+
+	slog.Info("Run view created", "viewID", view.ID, "projectID", view.ProjectID)
+	return view, nil
+}
+
+// GetRunView returns a view userID may see: one of their own or a shared one
+func (s *automationService) GetRunView(ctx context.Context, viewID, userID string) (*RunView, error) {
+	view, err := s.automationRepo.GetRunViewByID(ctx, viewID, userID)
+	if err != nil {
+		slog.Error("Failed to get run view", "error", err, "viewID", viewID)
+		return nil, fmt.Errorf("failed to get run view: %w", err)
+	}
+	if view == nil || (!view.Shared && view.CreatedByUserID != userID) {
+		return nil, fmt.Errorf("%w: run view not found", platform.ErrNotFound)
+	}
+	return view, nil
+}
+
+// GetRunViews lists the views of a project userID may see
+func (s *automationService) GetRunViews(ctx context.Context, projectID, userID string) ([]*RunView, error) {
+	views, err := s.automationRepo.GetRunViewsByProjectID(ctx, projectID, userID)
+	if err != nil {
+		slog.Error("Failed to get run views", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get run views: %w", err)
+	}
+	if views == nil {
+		views = []*RunView{}
+	}
+	return views, nil
+}
+
+// UpdateRunView changes the name, filter and sharing of a view; only its creator may
+func (s *automationService) UpdateRunView(ctx context.Context, view *RunView, userID string) (*RunView, error) {
+	existing, err := s.GetRunView(ctx, view.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.CreatedByUserID != userID {
+		return nil, fmt.Errorf("%w: only the creator of a view can change it", platform.ErrInvalidRequest)
+	}
+
+	existing.Name, existing.Filter, existing.Shared = view.Name, view.Filter, view.Shared
+	if err := validateRunView(existing, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkRunViewName(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	if err := s.automationRepo.UpdateRunView(ctx, existing); err != nil {
+		slog.Error("Failed to update run view", "error", err, "viewID", existing.ID)
+		return nil, fmt.Errorf("failed to update run view: %w", err)
+	}
+
+	slog.Info("Run view updated", "viewID", existing.ID)
+	return existing, nil
+}
+
+// DeleteRunView deletes a view and its subscriptions; only its creator may
+func (s *automationService) DeleteRunView(ctx context.Context, viewID, userID string) error {
+	view, err := s.GetRunView(ctx, viewID, userID)
+	if err != nil {
+		return err
+	}
+	if view.CreatedByUserID != userID {
+		return fmt.Errorf("%w: only the creator of a view can delete it", platform.ErrInvalidRequest)
+	}
+
+	if err := s.automationRepo.DeleteRunView(ctx, viewID); err != nil {
+		slog.Error("Failed to delete run view", "error", err, "viewID", viewID)
+		return fmt.Errorf("failed to delete run view: %w", err)
+	}
+
+	slog.Info("Run view deleted", "viewID", viewID)
+	return nil
+}
+
+// SetRunViewSubscription subscribes userID to a view they may see, or unsubscribes them.
+// Subscribers are emailed about finished runs matching the view's filter.
+func (s *automationService) SetRunViewSubscription(ctx context.Context, viewID, userID string, subscribed bool) (*RunView, error) {
+	view, err := s.GetRunView(ctx, viewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscribed {
+		err = s.automationRepo.SubscribeRunView(ctx, viewID, userID)
+	} else {
+		err = s.automationRepo.UnsubscribeRunView(ctx, viewID, userID)
+	}
+	if err != nil {
+		slog.Error("Failed to change run view subscription", "error", err, "viewID", viewID, "userID", userID)
+		return nil, fmt.Errorf("failed to change subscription: %w", err)
+	}
+
+	view.Subscribed = subscribed
+	return view, nil
+}
@@ -1,11 +1,13 @@
 package automation
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/rand"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
@@ -24,6 +26,9 @@ type Runner struct {
 	storageService      storage.StorageService
 	notificationService notification.NotificationService
 	sseManager          *SSEManager
+	kvStore             KVStore
+	runCache            RunCache
+	consoles            consoleSessions
 }
 
 // NewRunner creates a new Runner instance.
@@ -36,6 +41,97 @@ func NewRunner(automationRepo AutomationRepository, storageService storage.Stora
 	}
 }
 
+// SetKVStore sets the key-value store available to store:* actions
+func (r *Runner) SetKVStore(store KVStore) {
+	r.kvStore = store
+}
+
+// SetRunCache makes the runner heartbeat run progress, so stalled runs can be detected
+func (r *Runner) SetRunCache(cache RunCache) {
+	r.runCache = cache
+}
+
+// GetDatasetRecords returns the records of a project's dataset at version, or at its current
+// version when version is 0, along with the version read
+func (r *Runner) GetDatasetRecords(ctx context.Context, projectID, name string, version int) ([]map[string]interface{}, int, error) {
+	dataset, err := r.automationRepo.GetDatasetByName(ctx, projectID, name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("dataset '%s': %w", name, err)
+	}
+	if version == 0 {
+		version = dataset.CurrentVersion
+	}
+	if version == 0 {
+		return nil, 0, fmt.Errorf("dataset '%s' has not been refreshed successfully yet", name)
+	}
+
+	datasetVersion, err := r.automationRepo.GetDatasetVersion(ctx, dataset.ID, version)
+	if err != nil {
+		return nil, 0, fmt.Errorf("dataset '%s' version %d: %w", name, version, err)
+	}
+
+	records, err := ParseDatasetRecordsJSON(datasetVersion.RecordsJSON)
+	return records, version, err
+}
+
+// ResolveMessage returns the text of a message key in a project's catalog for locale, falling
+// back from regional locales to their language, e.g. pt-BR to pt
+func (r *Runner) ResolveMessage(ctx context.Context, projectID, locale, key string) (string, error) {
+	if locale == "" {
+		return "", fmt.Errorf("message key '%s' needs a locale; trigger the run with one or set locales in the automation config", key)
+	}
+
+	found := false
+	for _, candidate := range localeFallbacks(locale) {
+		catalog, err := r.automationRepo.GetTranslationCatalog(ctx, projectID, candidate)
+		if err != nil {
+			continue
+		}
+		found = true
+		if message, ok := catalog.Messages[key]; ok {
+			return message, nil
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("no translation catalog for locale '%s'", locale)
+	}
+	return "", fmt.Errorf("message key '%s' not found in the '%s' translation catalog", key, locale)
+}
+
+// LeaseAccount leases an account of a project's pool to one user of a run. It returns nil when
+// every account of the pool is leased.
+func (r *Runner) LeaseAccount(ctx context.Context, projectID, environment, poolName, runID string, loopIndex int) (*PoolAccount, *AccountPool, error) {
+	if environment == "" {
+		environment = DefaultAccountPoolEnvironment
+	}
+	pool, err := r.automationRepo.GetAccountPoolByName(ctx, projectID, environment, poolName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("account pool '%s' (%s): %w", poolName, environment, err)
+	}
+
+	account, err := r.automationRepo.LeasePoolAccount(ctx, pool.ID, runID, loopIndex, time.Duration(pool.LeaseTTLSeconds)*time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, pool, nil
+}
+
+// ReleaseAccount ends a user's lease on an account, marking it dirty when its state is unknown
+func (r *Runner) ReleaseAccount(ctx context.Context, accountID, runID string, loopIndex int, dirty bool) error {
+	state := PoolAccountAvailable
+	if dirty {
+		state = PoolAccountDirty
+	}
+	released, err := r.automationRepo.ReleasePoolAccount(ctx, accountID, runID, loopIndex, state)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return fmt.Errorf("account %s is not leased by this user of the run", accountID)
+	}
+	return nil
+}
+
 // RunAutomation executes a given automation.
 func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *AutomationRun) error {
 	// 1. Fetch Automation details from DB
@@ -47,7 +143,12 @@ func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *Autom
 	// 2. Parse automation configuration
 	var automationConfig AutomationConfig
 	if automation.ConfigJSON != "" {
-		if err := json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig); err != nil {
+		// Older configs are upgraded in memory so they keep running after format changes
+		configJSON, _, err := UpgradeAutomationConfigJSON(automation.ConfigJSON)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(configJSON), &automationConfig); err != nil {
 			return fmt.Errorf("failed to parse automation config: %w", err)
 		}
 	} else {
@@ -62,7 +163,7 @@ func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *Autom
 			},
 			Timeout:       300,
 			Retries:       0,
-			Screenshots:   ScreenshotConfig{Enabled: true, OnError: true, OnSuccess: false, Path: "screenshots/{{timestamp}}-{{loopIndex}}.png"},
+			Screenshots:   ScreenshotConfig{Enabled: true, OnError: true, OnSuccess: false, Path: DefaultScreenshotPath},
 			Notifications: []NotificationChannelConfig{},
 		}
 	}
@@ -118,9 +219,37 @@ func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *Autom
 	// Create shared event channel and data structures for all runs
 	eventCh := make(chan RunEvent, 1000) // Large buffer for concurrent runs
 	var allLogs []map[string]any
-	var allOutputFiles []string
+	allOutputFiles := []OutputFile{}
 	var mu sync.Mutex // Protect shared data structures
 
+	if err = validateHostMappings(automationConfig.HostMappings); err != nil {
+		return err
+	}
+
+	// Pooled HTTP client shared by API actions across all loops, so connections are reused
+	httpClient, err := newRunHTTPClient(&automationConfig)
+	if err != nil {
+		return fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+	defer httpClient.CloseIdleConnections()
+
+	// Organization faker dictionaries are loaded once and shared by all loops
+	fakerSets, fakerErr := r.automationRepo.GetFakerDictionariesByProjectID(ctx, automation.ProjectID)
+	if fakerErr != nil {
+		slog.Warn("Failed to load faker dictionaries", "run_id", run.ID, "error", fakerErr)
+	}
+
+	// Custom scripts are refused rather than run unchecked when the sandbox can't be loaded
+	scriptSandbox, err := r.automationRepo.GetScriptSandboxByProjectID(ctx, automation.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to load script sandbox: %w", err)
+	}
+
+	// Browsers watching huge runs get sampled events, see LiveEventsConfig
+	if r.sseManager != nil {
+		r.sseManager.StartRunSampling(run.ID, automationConfig.LiveEvents)
+	}
+
 	// Start single event processor for all runs
 	eventProcessorDone := make(chan struct{})
 	go r.processAllEvents(ctx, eventCh, &allLogs, &allOutputFiles, &mu, run, projectID, eventProcessorDone)
@@ -131,12 +260,15 @@ func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *Autom
 	if runMode == "parallel" && runCount > 1 {
 		// Parallel execution
 		var wg sync.WaitGroup
+		syncCoordinator := NewSyncCoordinator(runCount)
 
 		for i := 0; i < runCount; i++ {
 			wg.Add(1)
 			go func(loopIndex int) {
 				defer wg.Done()
-				err := r.executeSingleRun(ctx, automation, &automationConfig, run, loopIndex, projectID, eventCh)
+				// Users that finish or fail no longer hold up barriers the others are waiting at
+				defer syncCoordinator.Leave()
+				err := r.executeSingleRun(ctx, automation, &automationConfig, run, loopIndex, projectID, eventCh, httpClient, syncCoordinator, fakerSets, scriptSandbox)
 
 				if err != nil {
 					// For parallel execution, we'll just log the error
@@ -148,9 +280,10 @@ func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *Autom
 		}
 		wg.Wait()
 	} else {
-		// Sequential execution
+		// Sequential execution: one user at a time, so barriers pass at once while signals carry over
+		syncCoordinator := NewSyncCoordinator(1)
 		for i := 0; i < runCount; i++ {
-			err := r.executeSingleRun(ctx, automation, &automationConfig, run, i, projectID, eventCh)
+			err := r.executeSingleRun(ctx, automation, &automationConfig, run, i, projectID, eventCh, httpClient, syncCoordinator, fakerSets, scriptSandbox)
 
 			if err != nil {
 				executionError = err
@@ -167,6 +300,9 @@ func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *Autom
 	// Close event channel and wait for processor to finish
 	close(eventCh)
 	<-eventProcessorDone
+	if r.sseManager != nil {
+		r.sseManager.EndRunSampling(projectID, run.AutomationID, run.ID)
+	}
 
 	if executionError != nil {
 		err = executionError
@@ -196,8 +332,73 @@ func (r *Runner) RunAutomation(ctx context.Context, projectID string, run *Autom
 	return nil
 }
 
+// launchBrowser launches the configured browser engine, defaulting to chromium
+// Browser processes are tagged with the run ID so the process watchdog can find them if they leak
+func launchBrowser(pw *playwright.Playwright, automationConfig *AutomationConfig, runID string) (playwright.Browser, error) {
+	env := browserProcessEnv(runID)
+
+	if automationConfig.Browser != "" && automationConfig.Browser != "chromium" {
+		if len(automationConfig.HostMappings) > 0 {
+			slog.Warn("Host mappings are only applied to chromium browsers", "browser", automationConfig.Browser, "run_id", runID)
+		}
+		if len(automationConfig.TLS.CACertificates) > 0 {
+			slog.Warn("Custom CA certificates are only applied to chromium browsers; use insecureSkipVerify instead", "browser", automationConfig.Browser, "run_id", runID)
+		}
+	}
+
+	switch automationConfig.Browser {
+	case "firefox":
+		return pw.Firefox.Launch(playwright.BrowserTypeLaunchOptions{
+			Headless: playwright.Bool(true),
+			Env:      env,
+		})
+	case "webkit":
+		return pw.WebKit.Launch(playwright.BrowserTypeLaunchOptions{
+			Headless: playwright.Bool(true),
+			Env:      env,
+		})
+	default:
+		args := []string{
+			"--no-sandbox",
+			"--disable-setuid-sandbox",
+			"--disable-dev-shm-usage",
+			"--disable-gpu",
+		}
+		if len(automationConfig.HostMappings) > 0 {
+			args = append(args, chromiumHostResolverRules(automationConfig.HostMappings))
+		}
+		if len(automationConfig.TLS.CACertificates) > 0 {
+			spkiFlag, err := chromiumTrustedSPKIFlag(automationConfig.TLS)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, spkiFlag)
+		}
+		return pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+			Headless: playwright.Bool(true), // Run headless for automation
+			Env:      env,
+			Args:     args,
+		})
+	}
+}
+
 // executeSingleRun executes a single run of the automation
-func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, automationConfig *AutomationConfig, run *AutomationRun, loopIndex int, projectID string, eventCh chan RunEvent) error {
+func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, automationConfig *AutomationConfig, run *AutomationRun, loopIndex int, projectID string, eventCh chan RunEvent, httpClient *http.Client, syncCoordinator *SyncCoordinator, fakerSets map[string][]string, scriptSandbox *ScriptSandbox) (runErr error) {
+	// Accounts this user leased and didn't release go back to their pools; after a failure
+	// their state is unknown, so they are marked dirty
+	defer func() {
+		state := PoolAccountAvailable
+		if runErr != nil {
+			state = PoolAccountDirty
+		}
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if released, err := r.automationRepo.ReleaseRunPoolAccounts(releaseCtx, run.ID, loopIndex, state); err != nil {
+			slog.Error("Failed to release pool accounts", "error", err, "runID", run.ID, "loopIndex", loopIndex)
+		} else if released > 0 {
+			slog.Info("Released pool accounts", "runID", run.ID, "loopIndex", loopIndex, "count", released, "state", state)
+		}
+	}()
 
 	// Initialize Playwright for this run
 	pw, err := playwright.Run()
@@ -207,15 +408,7 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 	defer pw.Stop()
 
 	// Launch browser
-	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
-		Headless: playwright.Bool(true), // Run headless for automation
-		Args: []string{
-			"--no-sandbox",
-			"--disable-setuid-sandbox",
-			"--disable-dev-shm-usage",
-			"--disable-gpu",
-		},
-	})
+	browser, err := launchBrowser(pw, automationConfig, run.ID)
 	if err != nil {
 		return fmt.Errorf("could not launch browser: %w", err)
 	}
@@ -224,6 +417,7 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 	// Create new page with context
 	page, err := browser.NewPage(playwright.BrowserNewPageOptions{
 		JavaScriptEnabled: playwright.Bool(true),
+		IgnoreHttpsErrors: playwright.Bool(automationConfig.TLS.InsecureSkipVerify),
 	})
 	if err != nil {
 		return fmt.Errorf("could not create page: %w", err)
@@ -237,6 +431,8 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 		UserID:       "", // TODO: Get from context if available
 		ProjectID:    automation.ProjectID,
 		AutomationID: automation.ID,
+		Locale:       runLocale(run, automationConfig, loopIndex),
+		FakerSets:    fakerSets,
 		StaticVars:   make(map[string]string),
 		RuntimeVars:  make(map[string]interface{}),
 		GlobalVars:   make(map[string]interface{}),
@@ -249,6 +445,9 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 		}
 	}
 
+	// Replays record the variables each action changes
+	replayVariables := newReplayVariableTracker()
+
 	// Create RunContext
 	runContext := &RunContext{
 		PlaywrightBrowser: browser,
@@ -260,14 +459,27 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 		Runner:            r,
 		VariableContext:   varContext,
 		AutomationConfig:  automationConfig,
+		HTTPClient:        httpClient,
+		KVStore:           r.kvStore,
+		Sync:              syncCoordinator,
+		Timers:            make(map[string]time.Time),
+		Attempt:           max(run.Attempt, 1),
+		ScriptSandbox:     scriptSandbox,
+		ArtifactPrefix:    runArtifactPrefix(automation, automationConfig, run),
 	}
 
+	// Timers left running are reported as incomplete transactions
+	defer reportIncompleteTimers(runContext)
+
 	// Fetch and execute steps
 	steps, err := r.automationRepo.GetStepsByAutomationID(ctx, automation.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get automation steps: %w", err)
 	}
 
+	// Steps and actions outside the run's execution profile are skipped
+	tagFilter := RunTagFilter{Include: run.IncludeTags, Exclude: run.ExcludeTags}
+
 	totalSteps := len(steps)
 	for stepIndex, step := range steps {
 		// Check for cancellation before each step
@@ -279,6 +491,11 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 
 		// Parse step configuration and check for skip conditions
 		shouldSkipStep := false
+		var expectedDuration time.Duration
+		var stepTags []string
+		var stepInputs []StepInput
+		var stepOutputs []StepOutput
+		var stepVariables []Variable
 
 		if step.ConfigJSON != "" {
 			var stepConfigMap map[string]interface{}
@@ -318,6 +535,18 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 							"loop_index", loopIndex)
 					}
 				}
+
+				if expectedMs, ok := stepConfigMap["expected_duration_ms"].(float64); ok && expectedMs > 0 {
+					expectedDuration = time.Duration(expectedMs) * time.Millisecond
+				}
+
+				stepTags = ConfigTags(stepConfigMap)
+
+				if stepConfig, err := ParseStepConfig(step.ConfigJSON); err != nil {
+					runContext.Logger.Warn("Failed to parse step inputs and outputs", "step_id", step.ID, "error", err)
+				} else {
+					stepInputs, stepOutputs, stepVariables = stepConfig.Inputs, stepConfig.Outputs, stepConfig.Variables
+				}
 			}
 		}
 
@@ -325,6 +554,23 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 		if shouldSkipStep {
 			continue
 		}
+
+		// Get actions for this step
+		stepActions, err := r.automationRepo.GetActionsByStepID(ctx, step.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get actions for step %s: %w", step.Name, err)
+		}
+
+		if tagFilter.Active() {
+			stepActions = tagFilter.FilterActions(stepTags, stepActions)
+			if len(stepActions) == 0 {
+				runContext.Logger.Info("Skipping step with no actions matching the run's tags",
+					"step_name", step.Name,
+					"step_tags", stepTags)
+				continue
+			}
+		}
+
 		// Update step context
 		runContext.StepName = step.Name
 		runContext.StepID = step.ID
@@ -336,11 +582,20 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 			r.sseManager.SendRunStep(automation.ProjectID, run.AutomationID, run.ID, step.Name, stepIndex+1, totalSteps)
 		}
 
-		// Get actions for this step
-		stepActions, err := r.automationRepo.GetActionsByStepID(ctx, step.ID)
-		if err != nil {
-			return fmt.Errorf("failed to get actions for step %s: %w", step.Name, err)
+		stepStartTime := time.Now()
+		emitRunEvent(eventCh, RunEvent{
+			Type:      RunEventTypeStep,
+			Timestamp: stepStartTime,
+			StepID:    step.ID,
+			StepName:  step.Name,
+			LoopIndex: loopIndex,
+			Data:      map[string]interface{}{"phase": "start"},
+		})
+
+		if err := checkStepInputs(step, stepInputs, varContext); err != nil {
+			return err
 		}
+		varContext.StepVars = stepVariables
 
 		for _, action := range stepActions {
 			// Check for cancellation before each action
@@ -357,6 +612,29 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 					return fmt.Errorf("failed to parse action config JSON for action %s: %w", action.ActionType, jsonErr)
 				}
 			}
+			if _, upgradeErr := UpgradeActionConfig(action.ActionType, actionConfigMap); upgradeErr != nil {
+				return upgradeErr
+			}
+
+			// Surface deprecated usage once per run rather than once per loop
+			if loopIndex == 0 {
+				for _, warning := range CheckDeprecations(action.ActionType, actionConfigMap) {
+					select {
+					case eventCh <- RunEvent{
+						Type:       RunEventTypeWarning,
+						Timestamp:  time.Now(),
+						StepID:     step.ID,
+						StepName:   step.Name,
+						ActionID:   action.ID,
+						ActionName: action.Name,
+						ActionType: warning.ActionType,
+						Message:    warning.Message,
+						LoopIndex:  loopIndex,
+					}:
+					default:
+					}
+				}
+			}
 
 			// Resolve variables in action config
 			resolvedActionConfig, resolveErr := r.ResolveVariablesInConfig(actionConfigMap, varContext, automationConfig)
@@ -373,8 +651,30 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 			runContext.ActionID = action.ID
 			runContext.ActionName = action.Name
 			runContext.ParentActionID = "" // Reset for top-level actions
+			emitRunEvent(eventCh, RunEvent{
+				Type:       RunEventTypeAction,
+				Timestamp:  time.Now(),
+				StepID:     step.ID,
+				StepName:   step.Name,
+				ActionID:   action.ID,
+				ActionName: action.Name,
+				ActionType: action.ActionType,
+				LoopIndex:  loopIndex,
+			})
 			// Execute action
 			actionErr := pluginAction.Execute(ctx, resolvedActionConfig, runContext)
+			if changes := replayVariables.changes(varContext); len(changes) > 0 {
+				emitRunEvent(eventCh, RunEvent{
+					Type:       RunEventTypeVariable,
+					Timestamp:  time.Now(),
+					StepID:     step.ID,
+					StepName:   step.Name,
+					ActionID:   action.ID,
+					ActionType: action.ActionType,
+					LoopIndex:  loopIndex,
+					Data:       map[string]interface{}{"changes": changes},
+				})
+			}
 
 			if actionErr != nil {
 				runContext.Logger.Error("Action failed",
@@ -383,6 +683,11 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 					"error", actionErr,
 					"loop_index", loopIndex)
 
+				if automationConfig.Screenshots.Enabled && automationConfig.Screenshots.OnError {
+					selector, _ := resolvedActionConfig["selector"].(string)
+					r.sendAutomaticScreenshot(ctx, runContext, "error", selector)
+				}
+
 				return fmt.Errorf("action '%s' failed: %w", action.ActionType, actionErr)
 			}
 
@@ -391,18 +696,65 @@ func (r *Runner) executeSingleRun(ctx context.Context, automation *Automation, a
 				"action_name", action.Name,
 				"loop_index", loopIndex)
 		}
+
+		varContext.StepVars = nil
+		if err := r.publishStepOutputs(step, stepOutputs, varContext); err != nil {
+			return err
+		}
+
+		emitRunEvent(eventCh, RunEvent{
+			Type:      RunEventTypeStep,
+			Timestamp: time.Now(),
+			StepID:    step.ID,
+			StepName:  step.Name,
+			Duration:  time.Since(stepStartTime).Milliseconds(),
+			LoopIndex: loopIndex,
+			Data:      map[string]interface{}{"phase": "end"},
+		})
+
+		// A step over its budget still passes, but is flagged so creeping slowness shows up early
+		if stepDuration := time.Since(stepStartTime); expectedDuration > 0 && stepDuration > expectedDuration {
+			select {
+			case eventCh <- RunEvent{
+				Type:       RunEventTypeWarning,
+				Timestamp:  time.Now(),
+				StepID:     step.ID,
+				StepName:   step.Name,
+				ActionType: StepSlowActionType,
+				Message: fmt.Sprintf("Step '%s' took %s, over its expected %s",
+					step.Name, stepDuration.Round(time.Millisecond), expectedDuration),
+				LoopIndex: loopIndex,
+				Data: map[string]interface{}{
+					"step_duration_ms":     stepDuration.Milliseconds(),
+					"expected_duration_ms": expectedDuration.Milliseconds(),
+				},
+			}:
+			default:
+			}
+		}
+	}
+
+	if automationConfig.Screenshots.Enabled && automationConfig.Screenshots.OnSuccess {
+		r.sendAutomaticScreenshot(ctx, runContext, "success", "")
 	}
 
 	return nil
 }
 
 // processAllEvents handles events from the shared event channel and updates the database periodically
-func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent, logs *[]map[string]any, outputFiles *[]string, mu *sync.Mutex, run *AutomationRun, projectID string, done chan<- struct{}) {
+func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent, logs *[]map[string]any, outputFiles *[]OutputFile, mu *sync.Mutex, run *AutomationRun, projectID string, done chan<- struct{}) {
 	defer close(done)
 
 	ticker := time.NewTicker(5 * time.Second) // Save to DB every 5 seconds
 	defer ticker.Stop()
 
+	// The replay timeline is stored along with the logs
+	replay := newRunReplayRecorder(run)
+
+	// Any event is progress; it is heartbeated with the periodic save rather than per event
+	lastProgress := time.Now()
+	var lastHeartbeat time.Time
+
 	for {
 		select {
 		case event, ok := <-eventCh:
@@ -410,11 +762,14 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 				// Channel closed, save final state and exit
 				mu.Lock()
 				r.saveRunProgress(ctx, run, *logs, *outputFiles)
+				r.flushRunReplay(ctx, replay)
 				mu.Unlock()
 				return
 			}
+			lastProgress = time.Now()
 
 			mu.Lock()
+			replay.record(event)
 			// Process the event
 			switch event.Type {
 			case RunEventTypeLog:
@@ -431,6 +786,10 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 					"duration_ms":      event.Duration,
 					"status":           "success",
 				}
+				if timer, ok := event.Data[TimerNameKey]; ok {
+					logEntry[TimerNameKey] = timer
+					logEntry[TimerDurationKey] = event.Data[TimerDurationKey]
+				}
 				*logs = append(*logs, logEntry)
 
 				// Send SSE update
@@ -452,6 +811,9 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 					"duration_ms":      event.Duration,
 					"status":           "failed",
 				}
+				if event.Data != nil {
+					logEntry["data"] = event.Data
+				}
 				*logs = append(*logs, logEntry)
 
 				// Send SSE update
@@ -459,8 +821,32 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 					r.sseManager.SendRunError(projectID, run.AutomationID, run.ID, event.StepName, event.ActionType, event.Error)
 				}
 
+			case RunEventTypeWarning:
+				logEntry := map[string]any{
+					"parent_action_id": event.ParentActionID,
+					"local_loop_index": event.LocalLoopIndex,
+					"timestamp":        event.Timestamp.Format(time.RFC3339),
+					"step_name":        event.StepName,
+					"step_id":          event.StepID,
+					"action_id":        event.ActionID,
+					"action_type":      event.ActionType,
+					"message":          event.Message,
+					"loop_index":       event.LoopIndex,
+					"status":           "warning",
+				}
+				if event.Data != nil {
+					logEntry["data"] = event.Data
+				}
+				*logs = append(*logs, logEntry)
+
+				// Send SSE update
+				if r.sseManager != nil {
+					r.sseManager.SendRunWarning(projectID, run.AutomationID, run.ID, event.StepName, event.ActionType, event.Message)
+				}
+
 			case RunEventTypeOutputFile:
-				*outputFiles = append(*outputFiles, event.OutputFile)
+				outputFile := NewOutputFileFromEvent(event)
+				*outputFiles = append(*outputFiles, outputFile)
 
 				// Also add to logs for completeness
 				logEntry := map[string]any{
@@ -472,6 +858,9 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 					"action_id":        event.ActionID,
 					"action_type":      event.ActionType,
 					"output_file":      event.OutputFile,
+					"output_file_kind": outputFile.Kind,
+					"content_type":     outputFile.ContentType,
+					"size":             outputFile.Size,
 					"loop_index":       event.LoopIndex,
 					"duration_ms":      event.Duration,
 					"status":           "success",
@@ -480,7 +869,7 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 
 				// Send SSE update
 				if r.sseManager != nil {
-					r.sseManager.SendRunOutputFile(projectID, run.AutomationID, run.ID, event.OutputFile)
+					r.sseManager.SendRunOutputFile(projectID, run.AutomationID, run.ID, outputFile)
 				}
 			}
 			mu.Unlock()
@@ -489,12 +878,22 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 			// Periodic save to database
 			mu.Lock()
 			r.saveRunProgress(ctx, run, *logs, *outputFiles)
+			r.flushRunReplay(ctx, replay)
 			mu.Unlock()
 
+			if r.runCache != nil && lastProgress.After(lastHeartbeat) {
+				if err := r.runCache.SetRunHeartbeat(ctx, run.ID, lastProgress); err != nil {
+					slog.Warn("Failed to heartbeat run progress", "run_id", run.ID, "error", err)
+				} else {
+					lastHeartbeat = lastProgress
+				}
+			}
+
 		case <-ctx.Done():
 			// Context cancelled, save final state and exit
 			mu.Lock()
 			r.saveRunProgress(ctx, run, *logs, *outputFiles)
+			r.flushRunReplay(ctx, replay)
 			mu.Unlock()
 			return
 		}
@@ -502,7 +901,7 @@ func (r *Runner) processAllEvents(ctx context.Context, eventCh <-chan RunEvent,
 }
 
 // saveRunProgress saves the current logs and output files to the database
-func (r *Runner) saveRunProgress(ctx context.Context, run *AutomationRun, logs []map[string]any, outputFiles []string) {
+func (r *Runner) saveRunProgress(ctx context.Context, run *AutomationRun, logs []map[string]any, outputFiles []OutputFile) {
 	// Update run with current logs and output files
 	logsBytes, _ := json.Marshal(logs)
 	run.LogsJSON = string(logsBytes)
@@ -510,8 +909,8 @@ func (r *Runner) saveRunProgress(ctx context.Context, run *AutomationRun, logs [
 	outputFilesBytes, _ := json.Marshal(outputFiles)
 	run.OutputFilesJSON = string(outputFilesBytes)
 
-	// Save to database
-	if err := r.automationRepo.UpdateRun(ctx, run); err != nil {
+	// Save to database; the status is left alone, as it may have been marked stalled meanwhile
+	if err := r.automationRepo.UpdateRunProgress(ctx, run.ID, run.LogsJSON, run.OutputFilesJSON); err != nil {
 		slog.Error("Failed to save run progress", "run_id", run.ID, "error", err)
 	}
 }
@@ -594,6 +993,13 @@ func (r *Runner) ResolveVariablesInString(input string, varContext *VariableCont
 			return varContext.ProjectID
 		case "automationId":
 			return varContext.AutomationID
+		case "locale":
+			return varContext.Locale
+		}
+
+		// Artifact path tokens are rendered when the artifact is written
+		if artifactPathTokens[varName] {
+			return match
 		}
 
 		// Handle runtime variables ({{runtime.varname}})
@@ -610,7 +1016,7 @@ func (r *Runner) ResolveVariablesInString(input string, varContext *VariableCont
 		// Handle faker variables
 		if strings.HasPrefix(varName, "faker.") {
 			fakerMethod := strings.TrimPrefix(varName, "faker.")
-			return r.generateFakerValue(fakerMethod)
+			return r.generateFakerValue(fakerMethod, varContext)
 		}
 
 		// Handle function variables
@@ -619,6 +1025,20 @@ func (r *Runner) ResolveVariablesInString(input string, varContext *VariableCont
 			return r.generateFunctionValue(fakerMethod)
 		}
 
+		// Variables of the executing step shadow the automation's. Their values see the variable
+		// they shadow, so "{{baseUrl}}/v2" extends the automation's baseUrl, whatever their type.
+		if variable, exists := varContext.stepVariable(varName); exists {
+			scoped := varContext.withoutStepVariable(varName)
+			if variable.Type == "static" {
+				value, err := r.ResolveVariablesInString(variable.Value, scoped, automationConfig)
+				if err != nil {
+					return ""
+				}
+				return value
+			}
+			return r.resolveVariable(variable, scoped, automationConfig)
+		}
+
 		// Handle static variables
 		if value, exists := varContext.StaticVars[varName]; exists {
 			return value
@@ -628,22 +1048,8 @@ func (r *Runner) ResolveVariablesInString(input string, varContext *VariableCont
 		for _, variable := range automationConfig.Variables {
 			if variable.Key == varName {
 				switch variable.Type {
-				case "static":
-					return variable.Value
-				case "dynamic":
-					// Variable.Value contains the faker method (e.g., "{{faker.email}}")
-					if strings.HasPrefix(variable.Value, "{{faker.") && strings.HasSuffix(variable.Value, "}}") {
-						fakerMethod := strings.TrimPrefix(strings.TrimSuffix(variable.Value, "}}"), "{{faker.")
-						return r.generateFakerValue(fakerMethod)
-					}
-					return variable.Value
-				case "environment":
-					// Variable.Value contains the environment variable (e.g., "{{timestamp}}")
-					v, err := r.ResolveVariablesInString(variable.Value, varContext, automationConfig)
-					if err != nil {
-						return ""
-					}
-					return v
+				case "static", "dynamic", "environment":
+					return r.resolveVariable(variable, varContext, automationConfig)
 				}
 			}
 		}
@@ -790,8 +1196,18 @@ func isPrime(n int) bool {
 	return true
 }
 
-// generateFakerValue generates a fake value based on the faker method
-func (r *Runner) generateFakerValue(method string) string {
+// generateFakerValue generates a fake value based on the faker method. custom.<name> methods pick
+// a random value of the organization's faker dictionary of that name.
+func (r *Runner) generateFakerValue(method string, varContext *VariableContext) string {
+	if name, ok := strings.CutPrefix(method, "custom."); ok {
+		values := varContext.FakerSets[name]
+		if len(values) == 0 {
+			slog.Warn("Unknown faker dictionary", "dictionary", name)
+			return fmt.Sprintf("{{faker.%s}}", method)
+		}
+		return values[rand.Intn(len(values))]
+	}
+
 	gofakeit.Seed(time.Now().UnixNano()) // Ensure randomness
 
 	switch method {
@@ -838,9 +1254,50 @@ func (r *Runner) generateFunctionValue(method string) string {
 	}
 }
 
+// uploadRunReport renders a run's PDF report and stores it, returning its URL, or an empty
+// string when it couldn't be generated so notifications still go out without it
+func (r *Runner) uploadRunReport(ctx context.Context, automation *Automation, run *AutomationRun, automationConfig *AutomationConfig) string {
+	pdf, err := renderRunReport(ctx, r.automationRepo, automation, automationConfig, run, RunReportFormatPDF)
+	if err != nil {
+		slog.Error("Failed to render run report", "run_id", run.ID, "error", err)
+		return ""
+	}
+
+	key := fmt.Sprintf("reports/%s/run-report.pdf", run.ID)
+	reportURL, err := r.storageService.UploadFile(ctx, key, bytes.NewReader(pdf), "application/pdf")
+	if err != nil {
+		slog.Error("Failed to upload run report", "run_id", run.ID, "error", err)
+		return ""
+	}
+	return reportURL
+}
+
 // sendNotifications sends notifications based on the automation configuration
 func (r *Runner) sendNotifications(ctx context.Context, automation *Automation, run *AutomationRun, automationConfig *AutomationConfig) {
-	if len(automationConfig.Notifications) == 0 {
+	if silencedForRollout(ctx, r.automationRepo, automation.ID, "notification") {
+		return
+	}
+	if silencedForMaintenance(ctx, r.automationRepo, run, "notification") {
+		return
+	}
+
+	// Convert our config to the notification service format
+	channels := make([]notification.NotificationChannelConfig, len(automationConfig.Notifications))
+	for i, channel := range automationConfig.Notifications {
+		channels[i] = notification.NotificationChannelConfig{
+			ID:         channel.ID,
+			Type:       channel.Type,
+			OnComplete: channel.OnComplete,
+			OnError:    channel.OnError,
+			Config:     channel.Config,
+		}
+	}
+
+	// Failures also reach the automation's owners
+	if run.Status == "failed" {
+		channels = append(channels, ownerFailureChannels(ctx, r.automationRepo, automation, automationConfig, automationConfig.Notifications)...)
+	}
+	if len(channels) == 0 {
 		return // No notifications configured
 	}
 
@@ -849,10 +1306,7 @@ func (r *Runner) sendNotifications(ctx context.Context, automation *Automation,
 	projectName := "Unknown Project" // TODO: Fetch actual project name if needed
 
 	// Parse output files from run
-	var outputFiles []string
-	if run.OutputFilesJSON != "" {
-		json.Unmarshal([]byte(run.OutputFilesJSON), &outputFiles)
-	}
+	outputFiles := OutputFileURLs(run.OutputFiles())
 
 	// Parse logs from run
 	var logs []map[string]any
@@ -873,18 +1327,11 @@ func (r *Runner) sendNotifications(ctx context.Context, automation *Automation,
 		ErrorMessage:   run.ErrorMessage,
 		OutputFiles:    outputFiles,
 		LogsCount:      len(logs),
+		Locale:         reportLocale(ctx, r.automationRepo, automation, automationConfig),
 	}
 
-	// Convert our config to the notification service format
-	channels := make([]notification.NotificationChannelConfig, len(automationConfig.Notifications))
-	for i, channel := range automationConfig.Notifications {
-		channels[i] = notification.NotificationChannelConfig{
-			ID:         channel.ID,
-			Type:       channel.Type,
-			OnComplete: channel.OnComplete,
-			OnError:    channel.OnError,
-			Config:     channel.Config,
-		}
+	if automationConfig.PDFReport {
+		message.ReportURL = r.uploadRunReport(ctx, automation, run, automationConfig)
 	}
 
 	// Dispatch notifications
@@ -12,16 +12,23 @@ import (
 
 // Scheduler handles background job scheduling for automation runs
 type Scheduler struct {
-	automationRepo    AutomationRepository
-	automationService AutomationService
-	runCache          RunCache
-	runner            *Runner
-	sseManager        *SSEManager
-	maxConcurrentRuns int
-	ticker            *time.Ticker
-	stopCh            chan struct{}
-	mu                sync.Mutex
-	runContexts       map[string]context.CancelFunc
+	automationRepo      AutomationRepository
+	automationService   AutomationService
+	runCache            RunCache
+	runner              *Runner
+	sseManager          *SSEManager
+	maxConcurrentRuns   int
+	ticker              *time.Ticker
+	stopCh              chan struct{}
+	mu                  sync.Mutex
+	runContexts         map[string]context.CancelFunc
+	fairQueue           *fairRunQueue
+	warehouseExporter   *WarehouseExporter
+	anomalyDetector     *AnomalyDetector
+	runViewNotifier     *RunViewNotifier
+	runPreviewGenerator *RunPreviewGenerator
+	worker              *WorkerCapabilities
+	actionTestSlots     chan struct{}
 }
 
 // NewScheduler creates a new automation scheduler
@@ -41,22 +48,56 @@ func NewScheduler(
 		maxConcurrentRuns: platform.ENV_MAX_CONCURRENT_RUNS,
 		stopCh:            make(chan struct{}),
 		runContexts:       make(map[string]context.CancelFunc),
+		fairQueue:         newFairRunQueue(),
+		actionTestSlots:   make(chan struct{}, maxConcurrentActionTests),
 	}
 }
 
 // Start begins the scheduler's background processing
 func (s *Scheduler) Start(ctx context.Context) {
 	s.ticker = time.NewTicker(10 * time.Second)
+	retentionTicker := time.NewTicker(1 * time.Hour)
+	stallTicker := time.NewTicker(stallCheckInterval)
+	driftTicker := time.NewTicker(configDriftInterval)
+	staleTicker := time.NewTicker(staleCheckInterval)
 
-	slog.Info("Automation scheduler started", "interval", "10s", "max_concurrent_runs", s.maxConcurrentRuns)
+	// Advertise what this worker can run, so runs are routed to workers able to run them. The
+	// registration is refreshed by its own goroutine, so slow scheduler work can't let it expire.
+	s.worker = DetectWorkerCapabilities(s.runner.kvStore != nil)
+	s.registerWorker(ctx)
+	go s.keepWorkerRegistered(ctx)
+
+	// Dataset refreshes fetch remote sources, so they run apart from the dispatch loop
+	go s.runEvery(ctx, 1*time.Minute, s.automationService.RefreshDueDatasets)
+
+	// Maintenance calendars are fetched once at startup, then periodically, apart from the loop
+	go func() {
+		s.automationService.SyncMaintenanceCalendars(ctx)
+		s.runEvery(ctx, maintenanceSyncInterval, s.automationService.SyncMaintenanceCalendars)
+	}()
+
+	slog.Info("Automation scheduler started", "interval", "10s", "max_concurrent_runs", s.maxConcurrentRuns,
+		"worker", s.worker.ID, "region", s.worker.Region, "browsers", s.worker.Browsers)
 
 	go func() {
 		defer s.ticker.Stop()
+		defer retentionTicker.Stop()
+		defer stallTicker.Stop()
+		defer driftTicker.Stop()
+		defer staleTicker.Stop()
 
 		for {
 			select {
 			case <-s.ticker.C:
 				s.processPendingRuns(ctx)
+			case <-retentionTicker.C:
+				s.purgeExpiredRuns(ctx)
+			case <-stallTicker.C:
+				s.detectStalledRuns(ctx)
+			case <-driftTicker.C:
+				s.automationService.DetectConfigDrift(ctx)
+			case <-staleTicker.C:
+				s.automationService.DetectStaleAutomations(ctx)
 			case <-s.stopCh:
 				slog.Info("Automation scheduler stopped")
 				return
@@ -110,36 +151,180 @@ func (s *Scheduler) processPendingRuns(ctx context.Context) {
 
 	slog.Debug("Processing pending runs", "pending_count", len(pendingRuns), "running_count", runningCount)
 
-	// Process pending runs up to capacity
+	// Share the free slots round-robin between organizations and their automations
+	queuedRuns, err := s.automationRepo.GetQueuedRuns(ctx, pendingRuns)
+	if err != nil {
+		slog.Error("Failed to get queued runs", "error", err)
+		return
+	}
+
+	// Runs this worker can't run are left to the others; nil when they can't be listed
+	workers, err := s.runCache.GetWorkers(ctx)
+	if err != nil {
+		slog.Warn("Failed to get workers", "error", err)
+		workers = nil
+	}
+
+	// Process pending runs up to capacity; requirements are derived once per automation
 	availableSlots := int(int64(s.maxConcurrentRuns) - runningCount)
-	for i, runID := range pendingRuns {
-		if i >= availableSlots {
+	requirementsByAutomation := make(map[string]*WorkerRequirements)
+	pausedByAutomation := make(map[string]bool)
+	started := 0
+	for _, queued := range s.fairQueue.Order(queuedRuns) {
+		if started >= availableSlots {
 			break // No more capacity
 		}
 
 		// Get run details from database
-		run, err := s.automationRepo.GetRunByID(ctx, runID)
+		run, err := s.automationRepo.GetRunByID(ctx, queued.RunID)
 		if err != nil {
-			slog.Error("Failed to get run details", "run_id", runID, "error", err)
+			slog.Error("Failed to get run details", "run_id", queued.RunID, "error", err)
 			continue
 		}
 		// Double-check status in case it changed
 		if run.Status != "pending" && run.Status != "queued" {
 			continue
 		}
+		// Runs queued before a maintenance window that pauses runs wait, still queued, for it to end
+		paused, checked := pausedByAutomation[run.AutomationID]
+		if !checked {
+			err := checkMaintenancePause(ctx, s.automationRepo, run.AutomationID)
+			if err != nil {
+				slog.Debug("Holding run during maintenance", "run_id", run.ID, "reason", err)
+			}
+			paused = err != nil
+			pausedByAutomation[run.AutomationID] = paused
+		}
+		if paused {
+			continue
+		}
+		if !s.canRunHere(ctx, queued.ProjectID, run, workers, requirementsByAutomation) {
+			continue
+		}
 
-		automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+		// Runs of automations serialized with others wait, queued, for the run holding the lock
+		lockKeys, holder, err := s.acquireRunLocks(ctx, queued.ProjectID, run)
 		if err != nil {
-			slog.Error("Failed to get automation details", "automation_id", run.AutomationID, "error", err)
+			slog.Error("Failed to acquire run locks", "run_id", run.ID, "error", err)
 			continue
 		}
+		if holder != "" {
+			s.queueLockedRun(ctx, queued.ProjectID, run, holder)
+			continue
+		}
+
 		// Start the run
-		s.startRun(ctx, automation.ProjectID, run)
+		s.startRun(ctx, queued.ProjectID, run, lockKeys)
+		s.fairQueue.MarkStarted(queued)
+		started++
+	}
+}
+
+// purgeExpiredRuns deletes finished runs that are past their retention period
+func (s *Scheduler) purgeExpiredRuns(ctx context.Context) {
+	deleted, err := s.automationRepo.DeleteExpiredRuns(ctx)
+	if err != nil {
+		slog.Error("Failed to purge expired runs", "error", err)
+		return
+	}
+
+	if deleted > 0 {
+		slog.Info("Purged expired runs", "count", deleted)
+	}
+}
+
+// registerWorker refreshes this worker's registration
+func (s *Scheduler) registerWorker(ctx context.Context) {
+	s.worker.LastSeenAt = time.Now()
+	if err := s.runCache.RegisterWorker(ctx, s.worker, workerRegistrationTTL); err != nil {
+		slog.Error("Failed to register worker", "worker", s.worker.ID, "error", err)
+	}
+}
+
+// runEvery runs task every interval until the scheduler stops, apart from the dispatch loop so
+// slow tasks don't hold up pending runs
+func (s *Scheduler) runEvery(ctx context.Context, interval time.Duration, task func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			task(ctx)
+		case <-s.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// keepWorkerRegistered refreshes this worker's registration until the scheduler stops
+func (s *Scheduler) keepWorkerRegistered(ctx context.Context) {
+	ticker := time.NewTicker(workerRegistrationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.registerWorker(ctx)
+		case <-s.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// canRunHere reports whether this worker can run a pending run. A run none of the live workers
+// can run is failed with the reason rather than left waiting. Requirements are cached in
+// requirementsByAutomation for the other runs of the same automation.
+func (s *Scheduler) canRunHere(ctx context.Context, projectID string, run *AutomationRun, workers []*WorkerCapabilities, requirementsByAutomation map[string]*WorkerRequirements) bool {
+	requirements, ok := requirementsByAutomation[run.AutomationID]
+	if !ok {
+		automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+		if err != nil {
+			slog.Error("Failed to get automation for routing", "run_id", run.ID, "error", err)
+			return false
+		}
+		if requirements, err = workerRequirements(ctx, s.automationRepo, automation); err != nil {
+			slog.Error("Failed to get worker requirements", "run_id", run.ID, "error", err)
+			return false
+		}
+		requirementsByAutomation[run.AutomationID] = requirements
+	}
+	if len(s.worker.Missing(requirements)) == 0 {
+		return true
+	}
+	if workers == nil {
+		return false
+	}
+
+	routeErr := noMatchingWorkerError(matchWorkers(workers, requirements))
+	if routeErr == nil {
+		return false // another worker will pick it up
+	}
+
+	endTime := time.Now()
+	run.Status = "failed"
+	run.EndTime = &endTime
+	run.ErrorMessage = routeErr.Error()
+	slog.Warn("Failing run no worker can run", "run_id", run.ID, "error", routeErr)
+
+	if err := s.automationRepo.UpdateRun(ctx, run); err != nil {
+		slog.Error("Failed to update unroutable run", "run_id", run.ID, "error", err)
+	}
+	if err := s.runCache.SetRunStatusWithExpiry(ctx, run.ID, run.Status, 1*time.Minute); err != nil {
+		slog.Error("Failed to update unroutable run status in cache", "run_id", run.ID, "error", err)
 	}
+	if s.sseManager != nil {
+		s.sseManager.SendRunStatusUpdate(projectID, run.AutomationID, run.ID, run.Status)
+	}
+	return false
 }
 
-// startRun starts a single automation run
-func (s *Scheduler) startRun(ctx context.Context, projectID string, run *AutomationRun) {
+// startRun starts a single automation run, holding lockKeys until it ends
+func (s *Scheduler) startRun(ctx context.Context, projectID string, run *AutomationRun, lockKeys []string) {
 	slog.Info("Starting automation run", "run_id", run.ID, "automation_id", run.AutomationID)
 
 	// Update status to running in DB and Redis
@@ -150,6 +335,7 @@ func (s *Scheduler) startRun(ctx context.Context, projectID string, run *Automat
 	err := s.automationRepo.UpdateRun(ctx, run)
 	if err != nil {
 		slog.Error("Failed to update run status to running", "run_id", run.ID, "error", err)
+		s.releaseRunLocks(lockKeys, run.ID)
 		return
 	}
 
@@ -175,6 +361,10 @@ func (s *Scheduler) startRun(ctx context.Context, projectID string, run *Automat
 	s.runContexts[run.ID] = cancel
 	s.mu.Unlock()
 
+	if len(lockKeys) > 0 {
+		go s.holdRunLocks(runCtx, lockKeys, run.ID)
+	}
+
 	// Start the automation in a goroutine
 	go func() {
 		defer func() {
@@ -184,6 +374,7 @@ func (s *Scheduler) startRun(ctx context.Context, projectID string, run *Automat
 			s.mu.Unlock()
 
 			cancel() // Release context resources
+			s.releaseRunLocks(lockKeys, run.ID)
 
 			// Remove from running set
 			if err := s.runCache.RemoveRunningRun(context.Background(), run.ID); err != nil {
@@ -221,9 +412,58 @@ func (s *Scheduler) startRun(ctx context.Context, projectID string, run *Automat
 		if s.sseManager != nil {
 			s.sseManager.SendRunStatusUpdate(projectID, run.AutomationID, run.ID, run.Status)
 		}
+
+		s.recordRunFacets(context.Background(), run)
+		s.annotateMaintenance(context.Background(), run)
+
+		if s.warehouseExporter != nil {
+			s.warehouseExporter.Enqueue(projectID, run)
+		}
+		if s.anomalyDetector != nil {
+			s.anomalyDetector.Enqueue(projectID, run)
+		}
+		if s.runViewNotifier != nil {
+			s.runViewNotifier.Enqueue(projectID, run)
+		}
+		if s.runPreviewGenerator != nil {
+			s.runPreviewGenerator.Enqueue(projectID, run)
+		}
+
+		// Cancelled runs, including stalled runs cancelled by the stall monitor, aren't retried
+		if run.Status == "failed" && runCtx.Err() == nil {
+			s.retryFailedRun(context.Background(), projectID, run)
+		}
 	}()
 }
 
+// SetWarehouseExporter makes the scheduler export every finished run through exporter
+func (s *Scheduler) SetWarehouseExporter(exporter *WarehouseExporter) {
+	s.warehouseExporter = exporter
+}
+
+// SetAnomalyDetector makes the scheduler check every finished run for duration anomalies
+func (s *Scheduler) SetAnomalyDetector(detector *AnomalyDetector) {
+	s.anomalyDetector = detector
+}
+
+// SetRunViewNotifier makes the scheduler notify subscribers of run views matching finished runs
+func (s *Scheduler) SetRunViewNotifier(notifier *RunViewNotifier) {
+	s.runViewNotifier = notifier
+}
+
+// SetRunPreviewGenerator makes the scheduler generate the summary card of every finished run
+func (s *Scheduler) SetRunPreviewGenerator(generator *RunPreviewGenerator) {
+	s.runPreviewGenerator = generator
+}
+
+// IsRunActive reports whether a run is currently executing in this worker
+func (s *Scheduler) IsRunActive(runID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.runContexts[runID]
+	return exists
+}
+
 // CancelRun cancels a specific automation run
 func (s *Scheduler) CancelRun(ctx context.Context, projectID, runID string) error {
 	s.mu.Lock()
@@ -269,3 +509,19 @@ func (s *Scheduler) CancelRun(ctx context.Context, projectID, runID string) erro
 	slog.Info("Automation run cancelled", "run_id", runID)
 	return nil
 }
+
+// SendConsoleCommand queues an expression for a user of a run paused in this worker, returning
+// the command ID its result will carry over SSE
+func (s *Scheduler) SendConsoleCommand(runID string, loopIndex int, expression string) (string, error) {
+	return s.runner.SendConsoleCommand(runID, loopIndex, expression)
+}
+
+// ResumeConsole resumes a user of a run paused in this worker
+func (s *Scheduler) ResumeConsole(runID string, loopIndex int) error {
+	return s.runner.ResumeConsole(runID, loopIndex)
+}
+
+// PausedConsoleUsers returns the loop indexes of a run's users paused in this worker
+func (s *Scheduler) PausedConsoleUsers(runID string) []int {
+	return s.runner.PausedConsoleUsers(runID)
+}
@@ -0,0 +1,108 @@
+package automation
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// highlightColor is the color of the box drawn around the target element of a screenshot
+var highlightColor = color.RGBA{R: 0xef, G: 0x44, B: 0x44, A: 0xff}
+
+// highlightThickness is the width of the highlight box in CSS pixels
+const highlightThickness = 3
+
+// highlightLookupTimeoutMs bounds the wait for the element to highlight; the element of a failed
+// action is often missing, and the screenshot is kept without a highlight then
+const highlightLookupTimeoutMs = 1000
+
+// AnnotateScreenshot draws a box around the element selector matches in screenshot, a PNG or JPEG
+// taken of page. fullPage tells whether the screenshot covers the whole page rather than the
+// viewport; JPEGs are re-encoded with quality, or the default quality when it is 0.
+func AnnotateScreenshot(page playwright.Page, screenshot []byte, selector string, fullPage bool, quality int) ([]byte, error) {
+	box, err := page.Locator(selector).First().BoundingBox(playwright.LocatorBoundingBoxOptions{
+		Timeout: playwright.Float(highlightLookupTimeoutMs),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate element: %w", err)
+	}
+	if box == nil {
+		return nil, fmt.Errorf("element is not visible")
+	}
+
+	// Bounding boxes are in CSS pixels relative to the viewport, screenshots in device pixels
+	metrics, err := page.Evaluate(`() => ({ x: window.scrollX, y: window.scrollY, ratio: window.devicePixelRatio })`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page metrics: %w", err)
+	}
+	m, _ := metrics.(map[string]interface{})
+	ratio := jsNumber(m["ratio"])
+	if ratio <= 0 {
+		ratio = 1
+	}
+	x, y := box.X, box.Y
+	if fullPage {
+		x += jsNumber(m["x"])
+		y += jsNumber(m["y"])
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(screenshot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	// The box surrounds the element rather than covering its edges
+	thickness := int(highlightThickness*ratio + 0.5)
+	outer := image.Rect(
+		int(x*ratio)-thickness,
+		int(y*ratio)-thickness,
+		int((x+box.Width)*ratio+0.5)+thickness,
+		int((y+box.Height)*ratio+0.5)+thickness,
+	)
+	if !outer.Overlaps(img.Bounds()) {
+		return nil, fmt.Errorf("element is outside the screenshot")
+	}
+	fill := image.NewUniform(highlightColor)
+	for _, edge := range []image.Rectangle{
+		image.Rect(outer.Min.X, outer.Min.Y, outer.Max.X, outer.Min.Y+thickness),
+		image.Rect(outer.Min.X, outer.Max.Y-thickness, outer.Max.X, outer.Max.Y),
+		image.Rect(outer.Min.X, outer.Min.Y, outer.Min.X+thickness, outer.Max.Y),
+		image.Rect(outer.Max.X-thickness, outer.Min.Y, outer.Max.X, outer.Max.Y),
+	} {
+		draw.Draw(img, edge, fill, image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if quality <= 0 {
+			quality = jpeg.DefaultQuality
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode screenshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// jsNumber converts a number returned by page evaluation, which arrives as an int when it is whole
+func jsNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	default:
+		return 0
+	}
+}
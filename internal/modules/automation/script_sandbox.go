@@ -0,0 +1,150 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ScriptSandbox is an organization's scriptSandbox settings, constraining the custom JavaScript
+// its automations run with playwright:evaluate and the run debug console. The size and time
+// limits are enforced; banned APIs are advisory, see Check.
+type ScriptSandbox struct {
+	MaxScriptBytes int      `json:"maxScriptBytes,omitempty"` // 0 for no limit
+	BannedAPIs     []string `json:"bannedApis,omitempty"`     // global names or dotted paths, e.g. "document.cookie"
+	TimeoutMs      int      `json:"timeoutMs,omitempty"`      // 0 for no limit
+}
+
+// Rules a script can violate
+const (
+	ScriptRuleMaxSize   = "max_script_size"
+	ScriptRuleBannedAPI = "banned_api"
+	ScriptRuleTimeout   = "timeout"
+)
+
+// ScriptViolation is a script breaking its organization's sandbox policy. Run events of
+// violations carry it as structured data under "violation".
+type ScriptViolation struct {
+	Rule    string `json:"rule"`
+	Limit   int    `json:"limit,omitempty"`  // bytes or milliseconds, by rule
+	Actual  int    `json:"actual,omitempty"` // script size in bytes
+	API     string `json:"api,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v *ScriptViolation) Error() string {
+	return "script sandbox violation: " + v.Message
+}
+
+// EventData is the violation as run event data
+func (v *ScriptViolation) EventData() map[string]interface{} {
+	return map[string]interface{}{"violation": v}
+}
+
+// Check returns how a script violates the sandbox before it runs, nil if it doesn't or there is
+// no sandbox. Banned APIs are found by name, also as properties such as window.fetch, and as
+// quoted property names, so window["fetch"] is caught too. The ban check is textual and
+// advisory only: it keeps honest scripts within policy, but names built at runtime, such as
+// window['fe'+'tch'], get through, so it is not a security boundary.
+func (s *ScriptSandbox) Check(script string) *ScriptViolation {
+	if s == nil {
+		return nil
+	}
+	if s.MaxScriptBytes > 0 && len(script) > s.MaxScriptBytes {
+		return &ScriptViolation{
+			Rule:    ScriptRuleMaxSize,
+			Limit:   s.MaxScriptBytes,
+			Actual:  len(script),
+			Message: fmt.Sprintf("the script is %d bytes, over the organization's limit of %d", len(script), s.MaxScriptBytes),
+		}
+	}
+	for _, api := range s.BannedAPIs {
+		if bannedAPIMatcher(api).MatchString(script) {
+			return &ScriptViolation{
+				Rule:    ScriptRuleBannedAPI,
+				API:     api,
+				Message: fmt.Sprintf("the script uses '%s', which the organization bans", api),
+			}
+		}
+	}
+	return nil
+}
+
+// bannedAPIMatcher matches uses of a global name or dotted path in a script, including a
+// bracket access of its last name such as document["cookie"]
+func bannedAPIMatcher(api string) *regexp.Regexp {
+	parts := strings.Split(api, ".")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	last := parts[len(parts)-1]
+	return regexp.MustCompile(`(^|[^A-Za-z0-9_$])` + strings.Join(parts, `\s*\.\s*`) + `($|[^A-Za-z0-9_$])` +
+		"|\\[\\s*[\"'`]" + last + "[\"'`]\\s*\\]")
+}
+
+// EvaluateScript checks a script against the sandbox and evaluates it on the page within the
+// sandbox's time limit. A script over the limit is reported as a violation; since a page can't
+// interrupt its own JavaScript, the script may keep it busy until the page is closed.
+func EvaluateScript(ctx context.Context, page playwright.Page, script string, sandbox *ScriptSandbox) (interface{}, error) {
+	if violation := sandbox.Check(script); violation != nil {
+		return nil, violation
+	}
+	if sandbox == nil || sandbox.TimeoutMs <= 0 {
+		return page.Evaluate(script)
+	}
+
+	type evaluation struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan evaluation, 1)
+	go func() {
+		result, err := page.Evaluate(script)
+		done <- evaluation{result, err}
+	}()
+
+	timer := time.NewTimer(time.Duration(sandbox.TimeoutMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case evaluated := <-done:
+		return evaluated.result, evaluated.err
+	case <-timer.C:
+		return nil, &ScriptViolation{
+			Rule:    ScriptRuleTimeout,
+			Limit:   sandbox.TimeoutMs,
+			Message: fmt.Sprintf("the script ran longer than the organization's limit of %d ms", sandbox.TimeoutMs),
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ScriptSandboxWarnings reports the playwright:evaluate expressions of an action config,
+// including those of nested actions, that violate the sandbox
+func ScriptSandboxWarnings(sandbox *ScriptSandbox, actionType string, config map[string]interface{}) []ConfigWarning {
+	var warnings []ConfigWarning
+	if expression, ok := config["expression"].(string); ok && actionType == "playwright:evaluate" {
+		if violation := sandbox.Check(expression); violation != nil {
+			warnings = append(warnings, ConfigWarning{
+				ActionType: actionType,
+				Field:      "expression",
+				Message:    violation.Message,
+			})
+		}
+	}
+	return append(warnings, nestedScriptSandboxWarnings(sandbox, config)...)
+}
+
+// nestedScriptSandboxWarnings checks the nested actions of a config
+func nestedScriptSandboxWarnings(sandbox *ScriptSandbox, config map[string]interface{}) []ConfigWarning {
+	var warnings []ConfigWarning
+	walkNestedActions(config, func(action nestedAction) error {
+		warnings = append(warnings, withActionID(ScriptSandboxWarnings(sandbox, action.ActionType, action.Config), action.ID)...)
+		return nil
+	})
+	return warnings
+}
@@ -5,28 +5,49 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/delordemm1/qplayground/internal/modules/notification"
 	"github.com/delordemm1/qplayground/internal/platform"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type automationService struct {
-	automationRepo AutomationRepository
-	runCache       RunCache
-	pool           *pgxpool.Pool
+	automationRepo      AutomationRepository
+	runCache            RunCache
+	pool                *pgxpool.Pool
+	notificationService notification.NotificationService
+
+	// Owner review requests, by automation and editor
+	reviewMu          sync.Mutex
+	reviewRequestedAt map[string]time.Time
 }
 
-func NewAutomationService(automationRepo AutomationRepository, runCache RunCache, pool *pgxpool.Pool) AutomationService {
+func NewAutomationService(automationRepo AutomationRepository, runCache RunCache, pool *pgxpool.Pool, notificationService notification.NotificationService) AutomationService {
 	return &automationService{
-		automationRepo: automationRepo,
-		runCache:       runCache,
-		pool:           pool,
+		automationRepo:      automationRepo,
+		runCache:            runCache,
+		pool:                pool,
+		notificationService: notificationService,
+		reviewRequestedAt:   make(map[string]time.Time),
 	}
 }
 
 // Automation management
 func (s *automationService) CreateAutomation(ctx context.Context, projectID, name, description, configJSON string) (*Automation, error) {
+	configJSON, err := validateAutomationConfigJSON(configJSON)
+	if err != nil {
+		return nil, err
+	}
+	// Stamp the current config version so future migrations don't re-apply to it
+	configJSON, err = StampAutomationConfigJSON(configJSON)
+	if err != nil {
+		return nil, err
+	}
+
 	automation := &Automation{
 		ID:          platform.UtilGenerateUUID(),
 		ProjectID:   projectID,
@@ -35,7 +56,7 @@ func (s *automationService) CreateAutomation(ctx context.Context, projectID, nam
 		ConfigJSON:  configJSON,
 	}
 
-	err := s.automationRepo.CreateAutomation(ctx, automation)
+	err = s.automationRepo.CreateAutomation(ctx, automation)
 	if err != nil {
 		slog.Error("Failed to create automation", "error", err, "projectID", projectID, "name", name)
 		return nil, fmt.Errorf("failed to create automation: %w", err)
@@ -66,7 +87,17 @@ func (s *automationService) GetAutomationByID(ctx context.Context, id string) (*
 }
 
 func (s *automationService) UpdateAutomation(ctx context.Context, automation *Automation) error {
-	err := s.automationRepo.UpdateAutomation(ctx, automation)
+	configJSON, err := validateAutomationConfigJSON(automation.ConfigJSON)
+	if err != nil {
+		return err
+	}
+	configJSON, err = StampAutomationConfigJSON(configJSON)
+	if err != nil {
+		return err
+	}
+	automation.ConfigJSON = configJSON
+
+	err = s.automationRepo.UpdateAutomation(ctx, automation)
 	if err != nil {
 		slog.Error("Failed to update automation", "error", err, "automationID", automation.ID)
 		return fmt.Errorf("failed to update automation: %w", err)
@@ -96,6 +127,9 @@ func (s *automationService) CreateStep(ctx context.Context, automationID, name s
 		StepOrder:    stepOrder,
 		ConfigJSON:   configJSON,
 	}
+	if err := s.validateStepPorts(ctx, step); err != nil {
+		return nil, err
+	}
 
 	err := s.automationRepo.CreateStep(ctx, step)
 	if err != nil {
@@ -118,6 +152,10 @@ func (s *automationService) GetStepsByAutomation(ctx context.Context, automation
 }
 
 func (s *automationService) UpdateStep(ctx context.Context, step *AutomationStep) error {
+	if err := s.validateStepPorts(ctx, step); err != nil {
+		return err
+	}
+
 	err := s.automationRepo.UpdateStep(ctx, step)
 	if err != nil {
 		slog.Error("Failed to update step", "error", err, "stepID", step.ID)
@@ -128,6 +166,48 @@ func (s *automationService) UpdateStep(ctx context.Context, step *AutomationStep
 	return nil
 }
 
+// validateStepPorts checks the inputs, outputs and variables a step declares, and that saving the step
+// leaves no step requiring an input that no earlier step outputs. Outputs aren't checked against
+// the step's actions here, since a new step has none yet; LintAutomation reports those.
+func (s *automationService) validateStepPorts(ctx context.Context, step *AutomationStep) error {
+	config, err := ParseStepConfig(step.ConfigJSON)
+	if err != nil {
+		return fmt.Errorf("%w: invalid step config: %s", platform.ErrInvalidRequest, err)
+	}
+	if err := ValidateStepPorts(config); err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if err := ValidateStepVariables(config.Variables); err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	current, err := s.automationRepo.GetStepsByAutomationID(ctx, step.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get steps for port validation", "error", err, "automationID", step.AutomationID)
+		return fmt.Errorf("failed to get steps: %w", err)
+	}
+	proposed := make([]*AutomationStep, 0, len(current)+1)
+	for _, existing := range current {
+		if existing.ID != step.ID {
+			proposed = append(proposed, existing)
+		}
+	}
+	proposed = append(proposed, step)
+	sort.SliceStable(proposed, func(i, j int) bool { return proposed[i].StepOrder < proposed[j].StepOrder })
+
+	// Problems the automation already had are left to lint, so unrelated edits aren't blocked
+	existing := make(map[string]bool)
+	for _, warning := range CheckStepDataFlow(current, nil) {
+		existing[warning.StepID+"/"+warning.Field] = true
+	}
+	for _, warning := range CheckStepDataFlow(proposed, nil) {
+		if !existing[warning.StepID+"/"+warning.Field] {
+			return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, warning.Message)
+		}
+	}
+	return nil
+}
+
 func (s *automationService) DeleteStep(ctx context.Context, id string) error {
 	err := s.automationRepo.DeleteStep(ctx, id)
 	if err != nil {
@@ -141,6 +221,12 @@ func (s *automationService) DeleteStep(ctx context.Context, id string) error {
 
 // Action management
 func (s *automationService) CreateAction(ctx context.Context, stepID, name, actionType, actionConfigJSON string, actionOrder int) (*AutomationAction, error) {
+	// Stamp the current config version so future migrations don't re-apply to it
+	actionConfigJSON, err := StampActionConfigJSON(actionType, actionConfigJSON)
+	if err != nil {
+		return nil, err
+	}
+
 	action := &AutomationAction{
 		ID:               platform.UtilGenerateUUID(),
 		StepID:           stepID,
@@ -150,7 +236,7 @@ func (s *automationService) CreateAction(ctx context.Context, stepID, name, acti
 		ActionOrder:      actionOrder,
 	}
 
-	err := s.automationRepo.CreateAction(ctx, action)
+	err = s.automationRepo.CreateAction(ctx, action)
 	if err != nil {
 		slog.Error("Failed to create action", "error", err, "stepID", stepID, "name", name, "actionType", actionType)
 		return nil, fmt.Errorf("failed to create action: %w", err)
@@ -171,6 +257,12 @@ func (s *automationService) GetActionsByStep(ctx context.Context, stepID string)
 }
 
 func (s *automationService) UpdateAction(ctx context.Context, action *AutomationAction) error {
+	actionConfigJSON, err := StampActionConfigJSON(action.ActionType, action.ActionConfigJSON)
+	if err != nil {
+		return err
+	}
+	action.ActionConfigJSON = actionConfigJSON
+
 	// Get the original action to compare orders
 	originalAction, err := s.automationRepo.GetActionByID(ctx, action.ID)
 	if err != nil {
@@ -382,7 +474,7 @@ func (s *automationService) GetFullAutomationConfig(ctx context.Context, automat
 			},
 			Timeout:       300,
 			Retries:       0,
-			Screenshots:   ExportedScreenshotConfig{Enabled: true, OnError: true, OnSuccess: false, Path: "screenshots/{{timestamp}}-{{loopIndex}}.png"},
+			Screenshots:   ExportedScreenshotConfig{Enabled: true, OnError: true, OnSuccess: false, Path: DefaultScreenshotPath},
 			Notifications: []ExportedNotificationChannelConfig{},
 		}
 	}
@@ -531,7 +623,31 @@ func (s *automationService) assignNestedActionIDs(actionConfig map[string]interf
 }
 
 // Run management
-func (s *automationService) TriggerRun(ctx context.Context, automationID string) (*AutomationRun, error) {
+
+// TriggerRun creates a pending run, or a queued one at capacity. tagFilter selects the run's
+// execution profile; an empty filter runs every step and action. locale, when set, is the
+// locale every user of the run resolves message keys in. Automations disabled for being stale
+// can't be run until they are re-enabled, runs no live worker can run are refused, and so are
+// runs during a maintenance window of an organization pausing runs for maintenance.
+func (s *automationService) TriggerRun(ctx context.Context, automationID string, tagFilter RunTagFilter, locale, userID string) (*AutomationRun, error) {
+	if err := checkAutomationEnabled(ctx, s.automationRepo, automationID); err != nil {
+		return nil, err
+	}
+	if err := checkMaintenancePause(ctx, s.automationRepo, automationID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWorkerAvailable(ctx, automationID); err != nil {
+		return nil, err
+	}
+
+	if locale != "" {
+		normalized, err := NormalizeLocale(locale)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+		}
+		locale = normalized
+	}
+
 	// Check current running count against max concurrent runs
 	runningCount, err := s.runCache.GetRunningRunCount(ctx)
 	if err != nil {
@@ -539,47 +655,62 @@ func (s *automationService) TriggerRun(ctx context.Context, automationID string)
 	} else if runningCount >= int64(platform.ENV_MAX_CONCURRENT_RUNS) {
 		// At capacity, queue the run
 		run := &AutomationRun{
-			ID:              platform.UtilGenerateUUID(),
-			AutomationID:    automationID,
-			Status:          "queued",
-			LogsJSON:        "[]",
-			OutputFilesJSON: "[]",
+			ID:                platform.UtilGenerateUUID(),
+			AutomationID:      automationID,
+			Status:            "queued",
+			LogsJSON:          "[]",
+			OutputFilesJSON:   "[]",
+			IncludeTags:       tagFilter.Include,
+			ExcludeTags:       tagFilter.Exclude,
+			Locale:            locale,
+			TriggeredByUserID: userID,
 		}
 
+		nameRun(ctx, s.automationRepo, run)
 		err := s.automationRepo.CreateRun(ctx, run)
 		if err != nil {
 			slog.Error("Failed to create queued run", "error", err, "automationID", automationID)
 			return nil, fmt.Errorf("failed to create run: %w", err)
 		}
+		s.RecordRunConfig(ctx, run)
 
 		// Set status in Redis
 		if cacheErr := s.runCache.SetRunStatus(ctx, run.ID, "queued"); cacheErr != nil {
 			slog.Warn("Failed to set queued status in cache", "run_id", run.ID, "error", cacheErr)
 		}
 
+		s.queueShadowRun(ctx, run)
 		slog.Info("Run queued due to capacity limit", "runID", run.ID, "automationID", automationID, "running_count", runningCount)
 		return run, nil
 	}
 
 	run := &AutomationRun{
-		ID:              platform.UtilGenerateUUID(),
-		AutomationID:    automationID,
-		Status:          "pending",
-		LogsJSON:        "[]",
-		OutputFilesJSON: "[]",
+		ID:                platform.UtilGenerateUUID(),
+		AutomationID:      automationID,
+		Status:            "pending",
+		LogsJSON:          "[]",
+		OutputFilesJSON:   "[]",
+		IncludeTags:       tagFilter.Include,
+		ExcludeTags:       tagFilter.Exclude,
+		Locale:            locale,
+		TriggeredByUserID: userID,
 	}
 
+	nameRun(ctx, s.automationRepo, run)
 	err = s.automationRepo.CreateRun(ctx, run)
 	if err != nil {
 		slog.Error("Failed to create run", "error", err, "automationID", automationID)
 		return nil, fmt.Errorf("failed to create run: %w", err)
 	}
+	s.RecordRunConfig(ctx, run)
 
 	// Set status in Redis
 	if err := s.runCache.SetRunStatus(ctx, run.ID, "pending"); err != nil {
 		slog.Warn("Failed to set pending status in cache", "run_id", run.ID, "error", err)
 	}
 
+	s.queueShadowRun(ctx, run)
+
 	// TODO: Trigger actual automation execution in background
 	// For now, just create the run record
 	slog.Info("Run triggered", "runID", run.ID, "automationID", automationID)
@@ -604,4 +735,1481 @@ func (s *automationService) GetRunByID(ctx context.Context, id string) (*Automat
 	}
 
 	return run, nil
-}
\ No newline at end of file
+}
+
+// Config upgrades
+
+// UpgradeStoredConfigs rewrites stored automation and action configs to their latest schema versions.
+// Failures are collected rather than aborting so one bad config can't block the rest.
+func (s *automationService) UpgradeStoredConfigs(ctx context.Context) (*ConfigUpgradeReport, error) {
+	report := &ConfigUpgradeReport{}
+
+	automations, err := s.automationRepo.GetAllAutomations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automations: %w", err)
+	}
+
+	for _, automation := range automations {
+		configJSON, changed, err := UpgradeAutomationConfigJSON(automation.ConfigJSON)
+		if err != nil {
+			slog.Error("Failed to upgrade automation config", "error", err, "automationID", automation.ID)
+			report.Failures = append(report.Failures, fmt.Sprintf("automation %s: %v", automation.ID, err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		automation.ConfigJSON = configJSON
+		if err := s.automationRepo.UpdateAutomation(ctx, automation); err != nil {
+			report.Failures = append(report.Failures, fmt.Sprintf("automation %s: %v", automation.ID, err))
+			continue
+		}
+		report.AutomationsUpgraded++
+	}
+
+	actions, err := s.automationRepo.GetAllActions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions: %w", err)
+	}
+
+	for _, action := range actions {
+		configJSON, changed, err := UpgradeActionConfigJSON(action.ActionType, action.ActionConfigJSON)
+		if err != nil {
+			slog.Error("Failed to upgrade action config", "error", err, "actionID", action.ID, "actionType", action.ActionType)
+			report.Failures = append(report.Failures, fmt.Sprintf("action %s: %v", action.ID, err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		action.ActionConfigJSON = configJSON
+		if err := s.automationRepo.UpdateAction(ctx, action); err != nil {
+			report.Failures = append(report.Failures, fmt.Sprintf("action %s: %v", action.ID, err))
+			continue
+		}
+		report.ActionsUpgraded++
+	}
+
+	slog.Info("Stored configs upgraded",
+		"automations_upgraded", report.AutomationsUpgraded,
+		"actions_upgraded", report.ActionsUpgraded,
+		"failures", len(report.Failures))
+	return report, nil
+}
+
+// LintAutomation reports non-fatal problems, such as deprecated actions and fields, across an automation's actions,
+// scripts its organization's sandbox would refuse, and step inputs and outputs the data flow between steps doesn't satisfy
+func (s *automationService) LintAutomation(ctx context.Context, automationID string) ([]ConfigWarning, error) {
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation for lint", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	scriptSandbox, err := s.automationRepo.GetScriptSandboxByProjectID(ctx, automation.ProjectID)
+	if err != nil {
+		slog.Error("Failed to get script sandbox for lint", "error", err, "projectID", automation.ProjectID)
+		return nil, fmt.Errorf("failed to get script sandbox: %w", err)
+	}
+
+	steps, err := s.automationRepo.GetStepsByAutomationID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get steps for lint", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get steps: %w", err)
+	}
+
+	warnings := []ConfigWarning{}
+	actionsByStep := make(map[string][]*AutomationAction, len(steps))
+	for _, step := range steps {
+		actions, err := s.automationRepo.GetActionsByStepID(ctx, step.ID)
+		if err != nil {
+			slog.Error("Failed to get actions for lint", "error", err, "stepID", step.ID)
+			return nil, fmt.Errorf("failed to get actions: %w", err)
+		}
+		actionsByStep[step.ID] = actions
+
+		for _, action := range actions {
+			for _, warning := range CheckDeprecationsJSON(action.ActionType, action.ActionConfigJSON) {
+				if warning.ActionID == "" {
+					warning.ActionID = action.ID
+				}
+				warnings = append(warnings, warning)
+			}
+
+			if scriptSandbox == nil {
+				continue
+			}
+			config := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(action.ActionConfigJSON), &config); err != nil {
+				continue
+			}
+			for _, warning := range ScriptSandboxWarnings(scriptSandbox, action.ActionType, config) {
+				if warning.ActionID == "" {
+					warning.ActionID = action.ID
+				}
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+
+	return append(warnings, CheckStepDataFlow(steps, actionsByStep)...), nil
+}
+
+// GetAutomationFlowchart returns an automation's steps and actions, including nested branches
+// and loops, as a graph for read-only viewing
+func (s *automationService) GetAutomationFlowchart(ctx context.Context, automationID string) (*Flowchart, error) {
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation for flowchart", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+
+	steps, err := s.automationRepo.GetStepsByAutomationID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get steps for flowchart", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get steps: %w", err)
+	}
+
+	actionsByStep := make(map[string][]*AutomationAction, len(steps))
+	for _, step := range steps {
+		actions, err := s.automationRepo.GetActionsByStepID(ctx, step.ID)
+		if err != nil {
+			slog.Error("Failed to get actions for flowchart", "error", err, "stepID", step.ID)
+			return nil, fmt.Errorf("failed to get actions: %w", err)
+		}
+		actionsByStep[step.ID] = actions
+	}
+
+	return BuildFlowchart(automation, steps, actionsByStep), nil
+}
+
+// Datasets
+func (s *automationService) CreateDataset(ctx context.Context, dataset *Dataset) (*Dataset, error) {
+	if err := ValidateDataset(dataset); err != nil {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if existing, err := s.automationRepo.GetDatasetByName(ctx, dataset.ProjectID, dataset.Name); err == nil && existing != nil {
+		return nil, fmt.Errorf("%w: a dataset named '%s' already exists in this project", platform.ErrConflict, dataset.Name)
+	}
+
+	dataset.ID = platform.UtilGenerateUUID()
+	if err := s.automationRepo.CreateDataset(ctx, dataset); err != nil {
+		slog.Error("Failed to create dataset", "error", err, "projectID", dataset.ProjectID, "name", dataset.Name)
+		return nil, fmt.Errorf("failed to create dataset: %w", err)
+	}
+
+	slog.Info("Dataset created", "datasetID", dataset.ID, "projectID", dataset.ProjectID, "name", dataset.Name)
+
+	// The first refresh makes the dataset usable right away; a failure is recorded on the dataset
+	if refreshed, err := s.RefreshDataset(ctx, dataset.ID); err == nil {
+		return refreshed, nil
+	}
+	return s.automationRepo.GetDatasetByID(ctx, dataset.ID)
+}
+
+func (s *automationService) GetDatasetByID(ctx context.Context, id string) (*Dataset, error) {
+	dataset, err := s.automationRepo.GetDatasetByID(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get dataset by ID", "error", err, "datasetID", id)
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+
+	return dataset, nil
+}
+
+func (s *automationService) GetDatasetsByProject(ctx context.Context, projectID string) ([]*Dataset, error) {
+	datasets, err := s.automationRepo.GetDatasetsByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get datasets by project", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get datasets: %w", err)
+	}
+
+	return datasets, nil
+}
+
+func (s *automationService) UpdateDataset(ctx context.Context, dataset *Dataset) error {
+	if err := ValidateDataset(dataset); err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if existing, err := s.automationRepo.GetDatasetByName(ctx, dataset.ProjectID, dataset.Name); err == nil && existing.ID != dataset.ID {
+		return fmt.Errorf("%w: a dataset named '%s' already exists in this project", platform.ErrConflict, dataset.Name)
+	}
+
+	if err := s.automationRepo.UpdateDataset(ctx, dataset); err != nil {
+		slog.Error("Failed to update dataset", "error", err, "datasetID", dataset.ID)
+		return fmt.Errorf("failed to update dataset: %w", err)
+	}
+
+	slog.Info("Dataset updated", "datasetID", dataset.ID)
+	return nil
+}
+
+func (s *automationService) DeleteDataset(ctx context.Context, id string) error {
+	if err := s.automationRepo.DeleteDataset(ctx, id); err != nil {
+		slog.Error("Failed to delete dataset", "error", err, "datasetID", id)
+		return fmt.Errorf("failed to delete dataset: %w", err)
+	}
+
+	slog.Info("Dataset deleted", "datasetID", id)
+	return nil
+}
+
+// RefreshDataset fetches a dataset's source and, when the records pass its schema and differ from
+// the current version, stores them as a new version. A failed refresh keeps the current version
+// and is recorded on the dataset.
+func (s *automationService) RefreshDataset(ctx context.Context, id string) (*Dataset, error) {
+	dataset, err := s.automationRepo.GetDatasetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+
+	schema, err := ParseDatasetSchema(dataset.SchemaJSON)
+	if err == nil {
+		err = s.refreshDatasetVersion(ctx, dataset, schema)
+	}
+	if err != nil {
+		slog.Warn("Dataset refresh failed", "error", err, "datasetID", id, "sourceURL", dataset.SourceURL)
+		if updateErr := s.automationRepo.UpdateDatasetRefreshResult(ctx, id, dataset.CurrentVersion, err.Error()); updateErr != nil {
+			slog.Error("Failed to record dataset refresh failure", "error", updateErr, "datasetID", id)
+		}
+		return nil, fmt.Errorf("failed to refresh dataset: %w", err)
+	}
+
+	return s.automationRepo.GetDatasetByID(ctx, id)
+}
+
+// refreshDatasetVersion stores the source's records as the dataset's current version
+func (s *automationService) refreshDatasetVersion(ctx context.Context, dataset *Dataset, schema DatasetSchema) error {
+	records, err := fetchDatasetRecords(ctx, dataset.SourceURL, schema)
+	if err != nil {
+		return err
+	}
+
+	recordsJSON, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+	checksum := datasetChecksum(recordsJSON)
+
+	currentVersion := dataset.CurrentVersion
+	if currentVersion > 0 {
+		current, err := s.automationRepo.GetDatasetVersion(ctx, dataset.ID, currentVersion)
+		if err == nil && current.Checksum == checksum {
+			slog.Info("Dataset source unchanged", "datasetID", dataset.ID, "version", currentVersion)
+			return s.automationRepo.UpdateDatasetRefreshResult(ctx, dataset.ID, currentVersion, "")
+		}
+	}
+
+	version := &DatasetVersion{
+		ID:          platform.UtilGenerateUUID(),
+		DatasetID:   dataset.ID,
+		RecordsJSON: string(recordsJSON),
+		RecordCount: len(records),
+		Checksum:    checksum,
+	}
+	if err := s.automationRepo.CreateDatasetVersion(ctx, version); err != nil {
+		return err
+	}
+	if err := s.automationRepo.UpdateDatasetRefreshResult(ctx, dataset.ID, version.Version, ""); err != nil {
+		return err
+	}
+
+	if oldest := version.Version - DatasetVersionsKept + 1; oldest > 1 {
+		if err := s.automationRepo.DeleteDatasetVersionsBefore(ctx, dataset.ID, oldest); err != nil {
+			slog.Error("Failed to prune dataset versions", "error", err, "datasetID", dataset.ID)
+		}
+	}
+
+	slog.Info("Dataset refreshed", "datasetID", dataset.ID, "version", version.Version, "records", version.RecordCount)
+	return nil
+}
+
+// RefreshDueDatasets refreshes every dataset whose refresh interval has elapsed
+func (s *automationService) RefreshDueDatasets(ctx context.Context) {
+	datasets, err := s.automationRepo.GetDatasetsDueForRefresh(ctx)
+	if err != nil {
+		slog.Error("Failed to get datasets due for refresh", "error", err)
+		return
+	}
+
+	for _, dataset := range datasets {
+		// Failures are logged and recorded on the dataset by RefreshDataset
+		_, _ = s.RefreshDataset(ctx, dataset.ID)
+	}
+}
+
+func (s *automationService) GetDatasetVersions(ctx context.Context, datasetID string) ([]*DatasetVersion, error) {
+	versions, err := s.automationRepo.GetDatasetVersions(ctx, datasetID)
+	if err != nil {
+		slog.Error("Failed to get dataset versions", "error", err, "datasetID", datasetID)
+		return nil, fmt.Errorf("failed to get dataset versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (s *automationService) GetDatasetVersion(ctx context.Context, datasetID string, version int) (*DatasetVersion, error) {
+	datasetVersion, err := s.automationRepo.GetDatasetVersion(ctx, datasetID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset version: %w", err)
+	}
+
+	return datasetVersion, nil
+}
+
+// Account pools
+func (s *automationService) CreateAccountPool(ctx context.Context, pool *AccountPool) (*AccountPool, error) {
+	if err := ValidateAccountPool(pool); err != nil {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if existing, err := s.automationRepo.GetAccountPoolByName(ctx, pool.ProjectID, pool.Environment, pool.Name); err == nil && existing != nil {
+		return nil, fmt.Errorf("%w: a pool named '%s' already exists for environment '%s'", platform.ErrConflict, pool.Name, pool.Environment)
+	}
+
+	pool.ID = platform.UtilGenerateUUID()
+	if err := s.automationRepo.CreateAccountPool(ctx, pool); err != nil {
+		slog.Error("Failed to create account pool", "error", err, "projectID", pool.ProjectID, "name", pool.Name)
+		return nil, fmt.Errorf("failed to create account pool: %w", err)
+	}
+
+	slog.Info("Account pool created", "poolID", pool.ID, "projectID", pool.ProjectID, "name", pool.Name, "environment", pool.Environment)
+	return pool, nil
+}
+
+func (s *automationService) GetAccountPoolByID(ctx context.Context, id string) (*AccountPool, error) {
+	pool, err := s.automationRepo.GetAccountPoolByID(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get account pool by ID", "error", err, "poolID", id)
+		return nil, fmt.Errorf("failed to get account pool: %w", err)
+	}
+
+	return pool, nil
+}
+
+func (s *automationService) GetAccountPoolsByProject(ctx context.Context, projectID string) ([]*AccountPool, error) {
+	pools, err := s.automationRepo.GetAccountPoolsByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get account pools by project", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get account pools: %w", err)
+	}
+
+	return pools, nil
+}
+
+func (s *automationService) DeleteAccountPool(ctx context.Context, id string) error {
+	if err := s.automationRepo.DeleteAccountPool(ctx, id); err != nil {
+		slog.Error("Failed to delete account pool", "error", err, "poolID", id)
+		return fmt.Errorf("failed to delete account pool: %w", err)
+	}
+
+	slog.Info("Account pool deleted", "poolID", id)
+	return nil
+}
+
+// AddPoolAccounts adds accounts to a pool as available. Every account is validated before any is added.
+func (s *automationService) AddPoolAccounts(ctx context.Context, poolID string, accounts []*PoolAccount) ([]*PoolAccount, error) {
+	for i, account := range accounts {
+		if err := ValidatePoolAccount(account); err != nil {
+			return nil, fmt.Errorf("%w: account %d: %s", platform.ErrInvalidRequest, i, err)
+		}
+	}
+
+	for _, account := range accounts {
+		account.ID = platform.UtilGenerateUUID()
+		account.PoolID = poolID
+		if err := s.automationRepo.CreatePoolAccount(ctx, account); err != nil {
+			slog.Error("Failed to create pool account", "error", err, "poolID", poolID, "label", account.Label)
+			return nil, fmt.Errorf("failed to add pool account: %w", err)
+		}
+	}
+
+	slog.Info("Pool accounts added", "poolID", poolID, "count", len(accounts))
+	return accounts, nil
+}
+
+func (s *automationService) GetPoolAccounts(ctx context.Context, poolID string) ([]*PoolAccount, error) {
+	accounts, err := s.automationRepo.GetPoolAccounts(ctx, poolID)
+	if err != nil {
+		slog.Error("Failed to get pool accounts", "error", err, "poolID", poolID)
+		return nil, fmt.Errorf("failed to get pool accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+func (s *automationService) DeletePoolAccount(ctx context.Context, poolID, accountID string) error {
+	if err := s.automationRepo.DeletePoolAccount(ctx, poolID, accountID); err != nil {
+		slog.Error("Failed to delete pool account", "error", err, "poolID", poolID, "accountID", accountID)
+		return fmt.Errorf("failed to delete pool account: %w", err)
+	}
+
+	slog.Info("Pool account deleted", "poolID", poolID, "accountID", accountID)
+	return nil
+}
+
+// ResetPoolAccount makes a dirty or stuck account available again
+func (s *automationService) ResetPoolAccount(ctx context.Context, poolID, accountID string) error {
+	if err := s.automationRepo.ResetPoolAccount(ctx, poolID, accountID); err != nil {
+		slog.Error("Failed to reset pool account", "error", err, "poolID", poolID, "accountID", accountID)
+		return fmt.Errorf("failed to reset pool account: %w", err)
+	}
+
+	slog.Info("Pool account reset", "poolID", poolID, "accountID", accountID)
+	return nil
+}
+
+// Translation catalogs
+
+// SaveTranslationCatalog creates a project's catalog for a locale, or replaces the messages of
+// the existing one
+func (s *automationService) SaveTranslationCatalog(ctx context.Context, catalog *TranslationCatalog) (*TranslationCatalog, error) {
+	if err := ValidateTranslationCatalog(catalog); err != nil {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	catalog.ID = platform.UtilGenerateUUID()
+	if err := s.automationRepo.UpsertTranslationCatalog(ctx, catalog); err != nil {
+		slog.Error("Failed to save translation catalog", "error", err, "projectID", catalog.ProjectID, "locale", catalog.Locale)
+		return nil, fmt.Errorf("failed to save translation catalog: %w", err)
+	}
+
+	slog.Info("Translation catalog saved", "catalogID", catalog.ID, "projectID", catalog.ProjectID, "locale", catalog.Locale, "messages", catalog.MessageCount)
+	return catalog, nil
+}
+
+func (s *automationService) GetTranslationCatalog(ctx context.Context, projectID, locale string) (*TranslationCatalog, error) {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	catalog, err := s.automationRepo.GetTranslationCatalog(ctx, projectID, locale)
+	if err != nil {
+		slog.Error("Failed to get translation catalog", "error", err, "projectID", projectID, "locale", locale)
+		return nil, fmt.Errorf("failed to get translation catalog: %w", err)
+	}
+
+	return catalog, nil
+}
+
+func (s *automationService) GetTranslationCatalogsByProject(ctx context.Context, projectID string) ([]*TranslationCatalog, error) {
+	catalogs, err := s.automationRepo.GetTranslationCatalogsByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get translation catalogs by project", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get translation catalogs: %w", err)
+	}
+
+	return catalogs, nil
+}
+
+func (s *automationService) DeleteTranslationCatalog(ctx context.Context, projectID, locale string) error {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	if err := s.automationRepo.DeleteTranslationCatalog(ctx, projectID, locale); err != nil {
+		slog.Error("Failed to delete translation catalog", "error", err, "projectID", projectID, "locale", locale)
+		return fmt.Errorf("failed to delete translation catalog: %w", err)
+	}
+
+	slog.Info("Translation catalog deleted", "projectID", projectID, "locale", locale)
+	return nil
+}
+
+// Managed resources
+
+// GetManagedAutomations lists the automations of a project that have an external ID
+func (s *automationService) GetManagedAutomations(ctx context.Context, projectID string) ([]*ManagedAutomation, error) {
+	managed, err := s.automationRepo.GetAutomationExternalIDs(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get managed automations", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get managed automations: %w", err)
+	}
+
+	return managed, nil
+}
+
+// GetManagedAutomation exports the automation a project knows by externalID with its ETag
+func (s *automationService) GetManagedAutomation(ctx context.Context, projectID, externalID string) (*ManagedAutomation, error) {
+	automationID, err := s.automationRepo.GetAutomationIDByExternalID(ctx, projectID, externalID)
+	if err != nil {
+		slog.Error("Failed to get managed automation", "error", err, "projectID", projectID, "externalID", externalID)
+		return nil, fmt.Errorf("failed to get managed automation: %w", err)
+	}
+	if automationID == "" {
+		return nil, fmt.Errorf("%w: no automation has external ID '%s'", platform.ErrNotFound, externalID)
+	}
+
+	config, err := s.GetFullAutomationConfig(ctx, automationID)
+	if err != nil {
+		return nil, err
+	}
+	etag, err := automationETag(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ManagedAutomation{ExternalID: externalID, AutomationID: automationID, ETag: etag, Config: config}, nil
+}
+
+// UpsertManagedAutomation creates the automation a project knows by externalID, or replaces its
+// steps and settings with config, returning whether it was created. Applying a config the
+// automation already has changes nothing, so tools can apply the same definition repeatedly.
+// Notification channels are kept when config leaves them out. The write is checked against
+// precondition under a lock on the automation.
+func (s *automationService) UpsertManagedAutomation(ctx context.Context, projectID, externalID, configJSON string, config *ExportedAutomationConfig, precondition WritePrecondition) (*ManagedAutomation, bool, error) {
+	if err := validateExternalID(externalID); err != nil {
+		return nil, false, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if err := ValidateAutomationImport(config); err != nil {
+		return nil, false, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	configJSON, _, err := UpgradeAutomationConfigJSON(configJSON)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Create transactional service
+	txService := &automationService{automationRepo: NewAutomationRepository(tx), runCache: s.runCache, pool: s.pool}
+
+	automationID, err := txService.automationRepo.GetAutomationIDByExternalID(ctx, projectID, externalID)
+	if err != nil {
+		slog.Error("Failed to get managed automation", "error", err, "projectID", projectID, "externalID", externalID)
+		return nil, false, fmt.Errorf("failed to get managed automation: %w", err)
+	}
+
+	var current *ExportedAutomationConfig
+	currentETag := ""
+	if automationID != "" {
+		if err := txService.automationRepo.LockAutomation(ctx, automationID); err != nil {
+			slog.Error("Failed to lock managed automation", "error", err, "automationID", automationID)
+			return nil, false, fmt.Errorf("failed to lock automation: %w", err)
+		}
+		if current, err = txService.GetFullAutomationConfig(ctx, automationID); err != nil {
+			return nil, false, err
+		}
+		if currentETag, err = automationETag(current); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := checkWritePrecondition(precondition, currentETag); err != nil {
+		return nil, false, err
+	}
+
+	if configJSON, err = keepNotifications(configJSON, config, current); err != nil {
+		return nil, false, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	desired, err := storedAutomationConfig(configJSON, config)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	desiredETag, err := automationETag(desired)
+	if err != nil {
+		return nil, false, err
+	}
+
+	created := automationID == ""
+	managed := &ManagedAutomation{ExternalID: externalID, AutomationID: automationID, ETag: currentETag, Config: current}
+	if created || currentETag != desiredETag {
+		if created {
+			automation, err := txService.CreateAutomation(ctx, projectID, config.Automation.Name, config.Automation.Description, configJSON)
+			if err != nil {
+				return nil, false, err
+			}
+			automationID = automation.ID
+			if err := txService.automationRepo.CreateAutomationExternalID(ctx, projectID, externalID, automationID); err != nil {
+				slog.Error("Failed to create automation external ID", "error", err, "projectID", projectID, "externalID", externalID)
+				return nil, false, fmt.Errorf("%w: automation '%s' was created concurrently", platform.ErrConflict, externalID)
+			}
+		} else {
+			automation, err := txService.automationRepo.GetAutomationByID(ctx, automationID)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get automation: %w", err)
+			}
+			automation.Name = config.Automation.Name
+			automation.Description = config.Automation.Description
+			automation.ConfigJSON = configJSON
+			if err := txService.UpdateAutomation(ctx, automation); err != nil {
+				return nil, false, err
+			}
+
+			steps, err := txService.automationRepo.GetStepsByAutomationID(ctx, automationID)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get steps: %w", err)
+			}
+			for _, step := range steps {
+				if err := txService.automationRepo.DeleteStep(ctx, step.ID); err != nil {
+					slog.Error("Failed to delete step of managed automation", "error", err, "stepID", step.ID)
+					return nil, false, fmt.Errorf("failed to replace steps: %w", err)
+				}
+			}
+		}
+
+		if managed.Warnings, err = txService.createImportedSteps(ctx, automationID, config.Steps); err != nil {
+			slog.Error("Failed to create steps of managed automation", "error", err, "automationID", automationID)
+			return nil, false, fmt.Errorf("failed to create steps: %w", err)
+		}
+		managed.AutomationID = automationID
+		if managed.Config, err = txService.GetFullAutomationConfig(ctx, automationID); err != nil {
+			return nil, false, err
+		}
+		if managed.ETag, err = automationETag(managed.Config); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit managed automation transaction", "error", err)
+		return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.Info("Managed automation applied", "automationID", automationID, "externalID", externalID, "created", created, "changed", managed.ETag != currentETag)
+	return managed, created, nil
+}
+
+// DeleteManagedAutomation deletes the automation a project knows by externalID
+func (s *automationService) DeleteManagedAutomation(ctx context.Context, projectID, externalID string, precondition WritePrecondition) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	txService := &automationService{automationRepo: NewAutomationRepository(tx), runCache: s.runCache, pool: s.pool}
+	current, err := txService.lockManagedAutomation(ctx, projectID, externalID)
+	if err != nil {
+		return err
+	}
+	etag, err := automationETag(current.Config)
+	if err != nil {
+		return err
+	}
+	if err := checkWritePrecondition(precondition, etag); err != nil {
+		return err
+	}
+
+	if err := txService.DeleteAutomation(ctx, current.AutomationID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit managed automation deletion", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.Info("Managed automation deleted", "automationID", current.AutomationID, "externalID", externalID)
+	return nil
+}
+
+// GetManagedNotificationChannel returns a notification channel of a managed automation with its
+// ETag
+func (s *automationService) GetManagedNotificationChannel(ctx context.Context, projectID, externalID, channelID string) (*ManagedNotificationChannel, error) {
+	managed, err := s.GetManagedAutomation(ctx, projectID, externalID)
+	if err != nil {
+		return nil, err
+	}
+	automation, err := s.automationRepo.GetAutomationByID(ctx, managed.AutomationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+
+	channels, err := automationNotificationChannels(automation)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		if channel.ID == channelID {
+			etag, err := notificationChannelETag(channel)
+			if err != nil {
+				return nil, err
+			}
+			return &ManagedNotificationChannel{AutomationExternalID: externalID, ETag: etag, Channel: channel}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: automation '%s' has no notification channel '%s'", platform.ErrNotFound, externalID, channelID)
+}
+
+// UpsertManagedNotificationChannel adds a notification channel to a managed automation, or
+// replaces the channel with the same ID, returning whether it was added
+func (s *automationService) UpsertManagedNotificationChannel(ctx context.Context, projectID, externalID string, channel NotificationChannelConfig, precondition WritePrecondition) (*ManagedNotificationChannel, bool, error) {
+	if err := validateNotificationChannel(channel); err != nil {
+		return nil, false, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if channel.Config == nil {
+		channel.Config = map[string]any{}
+	}
+	etag, err := notificationChannelETag(channel)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var created bool
+	err = s.updateManagedNotificationChannels(ctx, projectID, externalID, func(channels []NotificationChannelConfig) ([]NotificationChannelConfig, error) {
+		for i, existing := range channels {
+			if existing.ID != channel.ID {
+				continue
+			}
+			existingETag, err := notificationChannelETag(existing)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkWritePrecondition(precondition, existingETag); err != nil {
+				return nil, err
+			}
+			channels[i] = channel
+			return channels, nil
+		}
+		if err := checkWritePrecondition(precondition, ""); err != nil {
+			return nil, err
+		}
+		created = true
+		return append(channels, channel), nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &ManagedNotificationChannel{AutomationExternalID: externalID, ETag: etag, Channel: channel}, created, nil
+}
+
+// DeleteManagedNotificationChannel removes a notification channel from a managed automation
+func (s *automationService) DeleteManagedNotificationChannel(ctx context.Context, projectID, externalID, channelID string, precondition WritePrecondition) error {
+	return s.updateManagedNotificationChannels(ctx, projectID, externalID, func(channels []NotificationChannelConfig) ([]NotificationChannelConfig, error) {
+		for i, existing := range channels {
+			if existing.ID != channelID {
+				continue
+			}
+			existingETag, err := notificationChannelETag(existing)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkWritePrecondition(precondition, existingETag); err != nil {
+				return nil, err
+			}
+			return append(channels[:i], channels[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: automation '%s' has no notification channel '%s'", platform.ErrNotFound, externalID, channelID)
+	})
+}
+
+// updateManagedNotificationChannels replaces the notification channels of a managed automation
+// with those returned by update, under a lock on the automation. Other settings are kept as
+// stored, including ones this version doesn't know.
+func (s *automationService) updateManagedNotificationChannels(ctx context.Context, projectID, externalID string, update func([]NotificationChannelConfig) ([]NotificationChannelConfig, error)) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	txService := &automationService{automationRepo: NewAutomationRepository(tx), runCache: s.runCache, pool: s.pool}
+	managed, err := txService.lockManagedAutomation(ctx, projectID, externalID)
+	if err != nil {
+		return err
+	}
+	automation, err := txService.automationRepo.GetAutomationByID(ctx, managed.AutomationID)
+	if err != nil {
+		return fmt.Errorf("failed to get automation: %w", err)
+	}
+
+	channels, err := automationNotificationChannels(automation)
+	if err != nil {
+		return err
+	}
+	if channels, err = update(channels); err != nil {
+		return err
+	}
+
+	config, err := parseConfigMap(automation.ConfigJSON)
+	if err != nil {
+		return fmt.Errorf("invalid automation config: %w", err)
+	}
+	config["notifications"] = channels
+	if automation.ConfigJSON, err = marshalConfigMap(config); err != nil {
+		return err
+	}
+	if err := txService.UpdateAutomation(ctx, automation); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit notification channel update", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// lockManagedAutomation locks the automation a project knows by externalID for the transaction
+// of the service and exports it
+func (s *automationService) lockManagedAutomation(ctx context.Context, projectID, externalID string) (*ManagedAutomation, error) {
+	automationID, err := s.automationRepo.GetAutomationIDByExternalID(ctx, projectID, externalID)
+	if err != nil {
+		slog.Error("Failed to get managed automation", "error", err, "projectID", projectID, "externalID", externalID)
+		return nil, fmt.Errorf("failed to get managed automation: %w", err)
+	}
+	if automationID == "" {
+		return nil, fmt.Errorf("%w: no automation has external ID '%s'", platform.ErrNotFound, externalID)
+	}
+	if err := s.automationRepo.LockAutomation(ctx, automationID); err != nil {
+		slog.Error("Failed to lock managed automation", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to lock automation: %w", err)
+	}
+
+	config, err := s.GetFullAutomationConfig(ctx, automationID)
+	if err != nil {
+		return nil, err
+	}
+	return &ManagedAutomation{ExternalID: externalID, AutomationID: automationID, Config: config}, nil
+}
+
+// automationNotificationChannels returns the notification channels an automation's config sets
+func automationNotificationChannels(automation *Automation) ([]NotificationChannelConfig, error) {
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		if err := json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse automation config: %w", err)
+		}
+	}
+	return automationConfig.Notifications, nil
+}
+
+// Config drift
+
+// maxCommitRefLen caps the commit reference stored with a config snapshot
+const maxCommitRefLen = 255
+
+// RecordConfigSnapshot stores the config an automation was synced with version control at, as
+// called by push/pull tooling once the sync is committed. committed is the config as written to
+// the repository; when nil the automation's current config is snapshotted.
+func (s *automationService) RecordConfigSnapshot(ctx context.Context, automationID, commitRef, userID string, committed *ExportedAutomationConfig) (*ConfigSnapshot, error) {
+	commitRef = strings.TrimSpace(commitRef)
+	if len(commitRef) > maxCommitRefLen {
+		return nil, fmt.Errorf("%w: commit_ref must be at most %d characters", platform.ErrInvalidRequest, maxCommitRefLen)
+	}
+
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation for config snapshot", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+
+	fromRepository := committed != nil
+	if fromRepository {
+		if err := ValidateAutomationImport(committed); err != nil {
+			return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+		}
+	} else {
+		committed, err = s.GetFullAutomationConfig(ctx, automationID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := snapshotConfig(committed)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := configHash(config)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &ConfigSnapshot{
+		AutomationID:   automationID,
+		AutomationName: automation.Name,
+		Config:         config,
+		ConfigHash:     hash,
+		CommitRef:      commitRef,
+		SyncedByUserID: userID,
+	}
+	if err := s.automationRepo.UpsertConfigSnapshot(ctx, snapshot); err != nil {
+		slog.Error("Failed to save config snapshot", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to save config snapshot: %w", err)
+	}
+
+	// A committed config other than the current one means the automation already differs
+	if fromRepository {
+		if err := s.checkConfigDrift(ctx, snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	slog.Info("Config snapshot recorded", "automationID", automationID, "commitRef", commitRef, "hash", hash)
+	return snapshot, nil
+}
+
+// GetConfigDrift compares an automation with its snapshot and returns the snapshot with the result
+func (s *automationService) GetConfigDrift(ctx context.Context, automationID string) (*ConfigSnapshot, error) {
+	snapshot, err := s.automationRepo.GetConfigSnapshot(ctx, automationID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: automation has no config snapshot", platform.ErrNotFound)
+	}
+
+	if err := s.checkConfigDrift(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetConfigSnapshotsByProject lists the snapshots of a project's automations as of their last
+// drift check
+func (s *automationService) GetConfigSnapshotsByProject(ctx context.Context, projectID string) ([]*ConfigSnapshot, error) {
+	snapshots, err := s.automationRepo.GetConfigSnapshotsByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get config snapshots by project", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get config snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// DeleteConfigSnapshot stops checking an automation for drift
+func (s *automationService) DeleteConfigSnapshot(ctx context.Context, automationID string) error {
+	if err := s.automationRepo.DeleteConfigSnapshot(ctx, automationID); err != nil {
+		slog.Error("Failed to delete config snapshot", "error", err, "automationID", automationID)
+		return fmt.Errorf("failed to delete config snapshot: %w", err)
+	}
+
+	slog.Info("Config snapshot deleted", "automationID", automationID)
+	return nil
+}
+
+// DetectConfigDrift compares every automation that has a snapshot against it, flagging those
+// edited since they were last synced
+func (s *automationService) DetectConfigDrift(ctx context.Context) {
+	snapshots, err := s.automationRepo.GetAllConfigSnapshots(ctx)
+	if err != nil {
+		slog.Error("Failed to get config snapshots", "error", err)
+		return
+	}
+
+	drifted := 0
+	for _, snapshot := range snapshots {
+		if ctx.Err() != nil {
+			return
+		}
+		wasDrifted := snapshot.Drifted
+		if err := s.checkConfigDrift(ctx, snapshot); err != nil {
+			continue
+		}
+		if snapshot.Drifted && !wasDrifted {
+			drifted++
+			slog.Warn("Automation config drifted from its snapshot", "automationID", snapshot.AutomationID, "commitRef", snapshot.CommitRef, "paths", len(snapshot.DriftPaths))
+		}
+	}
+
+	if drifted > 0 {
+		slog.Info("Config drift check finished", "snapshots", len(snapshots), "newlyDrifted", drifted)
+	}
+}
+
+// checkConfigDrift compares an automation's current config with its snapshot, records the result
+// and updates snapshot with it
+func (s *automationService) checkConfigDrift(ctx context.Context, snapshot *ConfigSnapshot) error {
+	exported, err := s.GetFullAutomationConfig(ctx, snapshot.AutomationID)
+	if err != nil {
+		return err
+	}
+	current, err := snapshotConfig(exported)
+	if err != nil {
+		slog.Error("Failed to build config for drift check", "error", err, "automationID", snapshot.AutomationID)
+		return err
+	}
+	hash, err := configHash(current)
+	if err != nil {
+		return err
+	}
+
+	driftPaths := []string{}
+	if hash != snapshot.ConfigHash {
+		driftPaths = configDriftPaths(snapshot.Config, current)
+	}
+	if err := s.automationRepo.UpdateConfigSnapshotDrift(ctx, snapshot.AutomationID, driftPaths); err != nil {
+		slog.Error("Failed to record config drift", "error", err, "automationID", snapshot.AutomationID)
+		return fmt.Errorf("failed to record config drift: %w", err)
+	}
+
+	now := time.Now()
+	snapshot.DriftCheckedAt = &now
+	snapshot.DriftPaths = driftPaths
+	snapshot.Drifted = len(driftPaths) > 0
+	if !snapshot.Drifted {
+		snapshot.DriftDetectedAt = nil
+	} else if snapshot.DriftDetectedAt == nil {
+		snapshot.DriftDetectedAt = &now
+	}
+	return nil
+}
+
+// Run sharing
+func (s *automationService) CreateRunShare(ctx context.Context, runID, userID string, ttl time.Duration) (*RunShare, error) {
+	if ttl <= 0 {
+		ttl = DefaultRunShareTTL
+	}
+	if ttl > MaxRunShareTTL {
+		return nil, fmt.Errorf("share links cannot be valid for longer than %s", MaxRunShareTTL)
+	}
+
+	token, err := platform.UtilGenerateRandomString(32)
+	if err != nil {
+		slog.Error("Failed to generate run share token", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+
+	share := &RunShare{
+		ID:              platform.UtilGenerateUUID(),
+		RunID:           runID,
+		Token:           token,
+		CreatedByUserID: userID,
+		ExpiresAt:       time.Now().Add(ttl),
+	}
+
+	err = s.automationRepo.CreateRunShare(ctx, share)
+	if err != nil {
+		slog.Error("Failed to create run share", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to create run share: %w", err)
+	}
+
+	slog.Info("Run share created", "shareID", share.ID, "runID", runID, "expiresAt", share.ExpiresAt)
+	return share, nil
+}
+
+func (s *automationService) GetRunShares(ctx context.Context, runID string) ([]*RunShare, error) {
+	shares, err := s.automationRepo.GetRunSharesByRunID(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run shares", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+func (s *automationService) RevokeRunShare(ctx context.Context, runID, shareID string) error {
+	shares, err := s.automationRepo.GetRunSharesByRunID(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to get run shares: %w", err)
+	}
+
+	for _, share := range shares {
+		if share.ID != shareID {
+			continue
+		}
+		if err := s.automationRepo.RevokeRunShare(ctx, shareID); err != nil {
+			slog.Error("Failed to revoke run share", "error", err, "shareID", shareID)
+			return fmt.Errorf("failed to revoke run share: %w", err)
+		}
+		slog.Info("Run share revoked", "shareID", shareID, "runID", runID)
+		return nil
+	}
+
+	return fmt.Errorf("run share not found")
+}
+
+// GetSharedRun resolves a share token to the run it grants access to.
+// Expired and revoked shares are reported as not found so callers cannot
+// distinguish them from tokens that never existed.
+func (s *automationService) GetSharedRun(ctx context.Context, token string) (*RunShare, *AutomationRun, *Automation, error) {
+	share, err := s.automationRepo.GetRunShareByToken(ctx, token)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("run share not found")
+	}
+
+	if !share.IsActive(time.Now()) {
+		return nil, nil, nil, fmt.Errorf("run share not found")
+	}
+
+	run, err := s.automationRepo.GetRunByID(ctx, share.RunID)
+	if err != nil {
+		slog.Error("Failed to get shared run", "error", err, "runID", share.RunID)
+		return nil, nil, nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get automation for shared run", "error", err, "automationID", run.AutomationID)
+		return nil, nil, nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+
+	return share, run, automation, nil
+}
+
+// Status embeds
+func (s *automationService) CreateAutomationEmbed(ctx context.Context, automationID, userID string) (*AutomationEmbed, error) {
+	token, err := platform.UtilGenerateRandomString(32)
+	if err != nil {
+		slog.Error("Failed to generate embed token", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to generate embed token: %w", err)
+	}
+
+	embed := &AutomationEmbed{
+		ID:              platform.UtilGenerateUUID(),
+		AutomationID:    automationID,
+		Token:           token,
+		CreatedByUserID: userID,
+	}
+
+	err = s.automationRepo.CreateAutomationEmbed(ctx, embed)
+	if err != nil {
+		slog.Error("Failed to create automation embed", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to create automation embed: %w", err)
+	}
+
+	slog.Info("Automation embed created", "embedID", embed.ID, "automationID", automationID)
+	return embed, nil
+}
+
+func (s *automationService) GetAutomationEmbeds(ctx context.Context, automationID string) ([]*AutomationEmbed, error) {
+	embeds, err := s.automationRepo.GetAutomationEmbedsByAutomationID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation embeds", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation embeds: %w", err)
+	}
+
+	return embeds, nil
+}
+
+func (s *automationService) RevokeAutomationEmbed(ctx context.Context, automationID, embedID string) error {
+	embeds, err := s.automationRepo.GetAutomationEmbedsByAutomationID(ctx, automationID)
+	if err != nil {
+		return fmt.Errorf("failed to get automation embeds: %w", err)
+	}
+
+	for _, embed := range embeds {
+		if embed.ID != embedID {
+			continue
+		}
+		if err := s.automationRepo.RevokeAutomationEmbed(ctx, embedID); err != nil {
+			slog.Error("Failed to revoke automation embed", "error", err, "embedID", embedID)
+			return fmt.Errorf("failed to revoke automation embed: %w", err)
+		}
+		slog.Info("Automation embed revoked", "embedID", embedID, "automationID", automationID)
+		return nil
+	}
+
+	return fmt.Errorf("automation embed not found")
+}
+
+// GetEmbeddedStatus builds the status widget summary for an embed token
+func (s *automationService) GetEmbeddedStatus(ctx context.Context, token string) (*AutomationStatusSummary, error) {
+	embed, err := s.automationRepo.GetAutomationEmbedByToken(ctx, token)
+	if err != nil || embed.RevokedAt != nil {
+		return nil, fmt.Errorf("automation embed not found")
+	}
+
+	automation, err := s.automationRepo.GetAutomationByID(ctx, embed.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get automation for embed", "error", err, "automationID", embed.AutomationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+
+	runs, err := s.automationRepo.GetRecentRunsByAutomationID(ctx, embed.AutomationID, embedTrendRunLimit)
+	if err != nil {
+		slog.Error("Failed to get runs for embed", "error", err, "automationID", embed.AutomationID)
+		return nil, fmt.Errorf("failed to get runs: %w", err)
+	}
+
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig)
+	}
+
+	summary := buildAutomationStatusSummary(automation, runs)
+	summary.Locale = reportLocale(ctx, s.automationRepo, automation, &automationConfig)
+	return summary, nil
+}
+
+// embedTrendRunLimit is how many recent runs the status widget considers
+const embedTrendRunLimit = 20
+
+// buildAutomationStatusSummary summarises runs ordered newest first
+func buildAutomationStatusSummary(automation *Automation, runs []*AutomationRun) *AutomationStatusSummary {
+	summary := &AutomationStatusSummary{
+		AutomationName: automation.Name,
+		DurationTrend:  []RunDurationPoint{},
+		GeneratedAt:    time.Now(),
+	}
+
+	for i, run := range runs {
+		durationMs := int64(0)
+		if run.StartTime != nil && run.EndTime != nil {
+			durationMs = run.EndTime.Sub(*run.StartTime).Milliseconds()
+		}
+
+		if i == 0 {
+			summary.LatestRun = &RunStatusSummary{
+				RunID:      run.ID,
+				Status:     run.Status,
+				StartTime:  run.StartTime,
+				EndTime:    run.EndTime,
+				DurationMs: durationMs,
+			}
+		}
+
+		if summary.LastFailure == nil && run.Status == "failed" {
+			summary.LastFailure = &RunFailureSummary{
+				RunID:        run.ID,
+				ErrorMessage: run.ErrorMessage,
+				EndTime:      run.EndTime,
+			}
+		}
+
+		if run.EndTime != nil && (run.Status == "completed" || run.Status == "failed") {
+			summary.DurationTrend = append(summary.DurationTrend, RunDurationPoint{
+				RunID:      run.ID,
+				Status:     run.Status,
+				EndTime:    *run.EndTime,
+				DurationMs: durationMs,
+			})
+		}
+	}
+
+	// Present the trend oldest first so it reads left to right
+	for i, j := 0, len(summary.DurationTrend)-1; i < j; i, j = i+1, j-1 {
+		summary.DurationTrend[i], summary.DurationTrend[j] = summary.DurationTrend[j], summary.DurationTrend[i]
+	}
+
+	return summary
+}
+
+// automationAnomalyListLimit caps how many anomalies are listed for an automation
+const automationAnomalyListLimit = 100
+
+// GetRunAnomalies returns the duration anomalies flagged for a run
+func (s *automationService) GetRunAnomalies(ctx context.Context, runID string) ([]*RunAnomaly, error) {
+	anomalies, err := s.automationRepo.GetRunAnomaliesByRunID(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run anomalies", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run anomalies: %w", err)
+	}
+	return anomalies, nil
+}
+
+// GetRunReport renders a run's stakeholder report as html or pdf
+func (s *automationService) GetRunReport(ctx context.Context, runID, format string) ([]byte, error) {
+	if format != RunReportFormatHTML && format != RunReportFormatPDF {
+		return nil, fmt.Errorf("%w: unknown report format '%s'; use html or pdf", platform.ErrInvalidRequest, format)
+	}
+
+	run, err := s.automationRepo.GetRunByID(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run for report", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+	if err != nil {
+		slog.Error("Failed to get automation for report", "error", err, "automationID", run.AutomationID)
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+
+	var automationConfig AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig)
+	}
+
+	report, err := renderRunReport(ctx, s.automationRepo, automation, &automationConfig, run, format)
+	if err != nil {
+		slog.Error("Failed to render run report", "error", err, "runID", runID, "format", format)
+		return nil, fmt.Errorf("failed to render run report: %w", err)
+	}
+	return report, nil
+}
+
+// DiffRunAttempts compares a run's events with those of baseRunID, or of the run it retries when
+// baseRunID is empty. loopIndex limits the diff to one user when it is not negative.
+func (s *automationService) DiffRunAttempts(ctx context.Context, runID, baseRunID string, loopIndex int) (*RunDiff, error) {
+	run, err := s.automationRepo.GetRunByID(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run for diff", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	if baseRunID == "" {
+		baseRunID = run.ParentRunID
+	}
+	if baseRunID == "" {
+		return nil, fmt.Errorf("%w: run is not a retry; pass the run to compare it with", platform.ErrInvalidRequest)
+	}
+	if baseRunID == runID {
+		return nil, fmt.Errorf("%w: a run can't be compared with itself", platform.ErrInvalidRequest)
+	}
+
+	base, err := s.automationRepo.GetRunByID(ctx, baseRunID)
+	if err != nil || base.AutomationID != run.AutomationID {
+		return nil, fmt.Errorf("%w: the run to compare with must be a run of the same automation", platform.ErrInvalidRequest)
+	}
+
+	return DiffRuns(base, run, loopIndex), nil
+}
+
+// GetRunReplay returns a page of a run's replay timeline
+func (s *automationService) GetRunReplay(ctx context.Context, runID string, query RunReplayQuery) (*RunReplay, error) {
+	run, err := s.automationRepo.GetRunByID(ctx, runID)
+	if err != nil {
+		slog.Error("Failed to get run for replay", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultReplayPageSize
+	}
+	query.Limit = min(query.Limit, maxReplayPageSize)
+
+	// One more than the page tells whether another page follows
+	limit := query.Limit
+	query.Limit++
+	events, err := s.automationRepo.GetRunReplayEvents(ctx, runID, query)
+	if err != nil {
+		slog.Error("Failed to get run replay events", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run replay events: %w", err)
+	}
+
+	replay := &RunReplay{RunID: run.ID, Status: run.Status, Events: events, NextSeq: query.AfterSeq}
+	if len(events) > limit {
+		replay.Events = events[:limit]
+		replay.HasMore = true
+	}
+	if replay.Events == nil {
+		replay.Events = []*RunReplayEvent{}
+	}
+	if len(replay.Events) > 0 {
+		replay.NextSeq = replay.Events[len(replay.Events)-1].Seq
+	}
+
+	if run.StartTime != nil {
+		end := time.Now()
+		if run.EndTime != nil {
+			end = *run.EndTime
+		}
+		replay.DurationMs = end.Sub(*run.StartTime).Milliseconds()
+	}
+	return replay, nil
+}
+
+// GetRunReplayState returns what each user of a run was doing atMs into it
+func (s *automationService) GetRunReplayState(ctx context.Context, runID string, atMs int64) (*RunReplayState, error) {
+	if atMs < 0 {
+		return nil, fmt.Errorf("%w: at_ms must not be negative", platform.ErrInvalidRequest)
+	}
+
+	events, err := s.automationRepo.GetRunReplayEvents(ctx, runID, RunReplayQuery{ToMs: atMs})
+	if err != nil {
+		slog.Error("Failed to get run replay events", "error", err, "runID", runID)
+		return nil, fmt.Errorf("failed to get run replay events: %w", err)
+	}
+	return ReplayStateAt(events, atMs), nil
+}
+
+// GetAutomationAnomalies returns the most recent duration anomalies flagged for an automation's runs
+func (s *automationService) GetAutomationAnomalies(ctx context.Context, automationID string) ([]*RunAnomaly, error) {
+	anomalies, err := s.automationRepo.GetRunAnomaliesByAutomationID(ctx, automationID, automationAnomalyListLimit)
+	if err != nil {
+		slog.Error("Failed to get automation anomalies", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get automation anomalies: %w", err)
+	}
+	return anomalies, nil
+}
+
+// GetRunHistory returns an automation's run counts by status for every bucket of query
+func (s *automationService) GetRunHistory(ctx context.Context, automationID string, query RunHistoryQuery) (*RunHistory, error) {
+	counts, err := s.automationRepo.CountRunsByBucket(ctx, automationID, query)
+	if err != nil {
+		slog.Error("Failed to get run history", "error", err, "automationID", automationID)
+		return nil, fmt.Errorf("failed to get run history: %w", err)
+	}
+	return buildRunHistory(automationID, query, counts), nil
+}
+
+// ImportAutomation creates an automation with its steps and actions from an exported config.
+// configJSON is the automation-level config to store, already merged with any defaults by the caller.
+// Imported configs are upgraded from the config version they record; deprecated usage is
+// returned as warnings. A partially created automation is removed if any part fails.
+func (s *automationService) ImportAutomation(ctx context.Context, projectID, configJSON string, imported *ExportedAutomationConfig) (*Automation, []ConfigWarning, error) {
+	if err := ValidateAutomationImport(imported); err != nil {
+		return nil, nil, fmt.Errorf("invalid automation config: %w", err)
+	}
+
+	// Imported configs may predate config versions, so they are upgraded rather than stamped
+	configJSON, _, err := UpgradeAutomationConfigJSON(configJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	automation, err := s.CreateAutomation(ctx, projectID, imported.Automation.Name, imported.Automation.Description, configJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings, importErr := s.createImportedSteps(ctx, automation.ID, imported.Steps)
+	if importErr != nil {
+		slog.Error("Failed to import automation", "error", importErr, "automationID", automation.ID, "projectID", projectID)
+		if err := s.automationRepo.DeleteAutomation(ctx, automation.ID); err != nil {
+			slog.Error("Failed to remove partially imported automation", "error", err, "automationID", automation.ID)
+		}
+		return nil, nil, fmt.Errorf("failed to import automation: %w", importErr)
+	}
+
+	slog.Info("Automation imported", "automationID", automation.ID, "projectID", projectID, "steps", len(imported.Steps))
+	return automation, warnings, nil
+}
+
+// createImportedSteps creates the steps and actions of an imported config under an automation,
+// returning the deprecation warnings of the created actions and outputs none of their actions save
+func (s *automationService) createImportedSteps(ctx context.Context, automationID string, importedSteps []ExportedAutomationStep) ([]ConfigWarning, error) {
+	warnings := []ConfigWarning{}
+	steps := make([]*AutomationStep, 0, len(importedSteps))
+	actionsByStep := make(map[string][]*AutomationAction, len(importedSteps))
+	for i, importedStep := range importedSteps {
+		stepConfigJSON := ""
+		if len(importedStep.Config) > 0 {
+			configJSON, err := marshalConfigMap(importedStep.Config)
+			if err != nil {
+				return nil, err
+			}
+			stepConfigJSON = configJSON
+		}
+
+		// Steps are renumbered so gaps or duplicates in the imported orders can't break ordering
+		step, err := s.CreateStep(ctx, automationID, importedStep.Name, i+1, stepConfigJSON)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+
+		for j, importedAction := range importedStep.Actions {
+			actionConfig := importedAction.ActionConfig
+			if actionConfig == nil {
+				actionConfig = map[string]interface{}{}
+			}
+			actionConfigJSON, err := marshalConfigMap(actionConfig)
+			if err != nil {
+				return nil, err
+			}
+			if actionConfigJSON, _, err = UpgradeActionConfigJSON(importedAction.ActionType, actionConfigJSON); err != nil {
+				return nil, err
+			}
+
+			action, err := s.CreateAction(ctx, step.ID, importedAction.Name, importedAction.ActionType, actionConfigJSON, j+1)
+			if err != nil {
+				return nil, err
+			}
+			actionsByStep[step.ID] = append(actionsByStep[step.ID], action)
+
+			for _, warning := range CheckDeprecationsJSON(action.ActionType, action.ActionConfigJSON) {
+				if warning.ActionID == "" {
+					warning.ActionID = action.ID
+				}
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	return append(warnings, CheckStepDataFlow(steps, actionsByStep)...), nil
+}
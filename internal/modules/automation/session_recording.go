@@ -0,0 +1,625 @@
+package automation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxRecordingActions bounds the actions converted from one recording
+const maxRecordingActions = 1000
+
+// maxRecordingNameLength keeps generated step and action names readable
+const maxRecordingNameLength = 100
+
+// ConvertSessionRecording turns a recorded user session into a draft automation. It accepts a
+// Chrome DevTools Recorder export or rrweb events, either a bare array or {"events": [...]}.
+// Navigations start a new step, and clicks, typing, key presses, hovers and viewport changes
+// become playwright actions. Mouse moves, scrolls and other events are left out and reported as
+// warnings, as are selectors the recording doesn't pin down, which are DraftSelectorPlaceholder
+// placeholders.
+func ConvertSessionRecording(data []byte) (*ExportedAutomationConfig, []ConfigWarning, error) {
+	var probe struct {
+		Title  string          `json:"title"`
+		Steps  json.RawMessage `json:"steps"`
+		Events json.RawMessage `json:"events"`
+	}
+
+	draft := newRecordingDraft()
+	var name, description string
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := draft.convertRRWeb(trimmed); err != nil {
+			return nil, nil, err
+		}
+		name, description = "Recorded session", "Converted from an rrweb session recording"
+	case json.Unmarshal(trimmed, &probe) != nil:
+		return nil, nil, fmt.Errorf("the recording isn't valid JSON")
+	case probe.Events != nil:
+		if err := draft.convertRRWeb(probe.Events); err != nil {
+			return nil, nil, err
+		}
+		name, description = "Recorded session", "Converted from an rrweb session recording"
+	case probe.Steps != nil:
+		if err := draft.convertChromeRecording(probe.Steps); err != nil {
+			return nil, nil, err
+		}
+		name, description = probe.Title, "Converted from a Chrome DevTools Recorder recording"
+	default:
+		return nil, nil, fmt.Errorf("unknown recording format, expected a Chrome DevTools Recorder export or rrweb events")
+	}
+
+	if len(draft.steps) == 0 {
+		return nil, nil, fmt.Errorf("the recording has no interactions that convert to actions")
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "Recorded session"
+	}
+
+	return &ExportedAutomationConfig{
+		Automation: ExportedAutomation{
+			Name:        truncateRecordingName(name),
+			Description: description,
+			Config: ExportedAutomationMeta{
+				Variables:     []ExportedVariable{},
+				Timeout:       300,
+				Screenshots:   ExportedScreenshotConfig{Enabled: true, OnError: true, Path: DefaultScreenshotPath},
+				Notifications: []ExportedNotificationChannelConfig{},
+			},
+		},
+		Steps: draft.steps,
+	}, draft.result(), nil
+}
+
+// recordingDraft collects the steps converted from a recording
+type recordingDraft struct {
+	steps        []ExportedAutomationStep
+	actions      int
+	skipped      map[string]int // events left out, by kind
+	placeholders int
+	truncated    bool
+}
+
+func newRecordingDraft() *recordingDraft {
+	return &recordingDraft{skipped: make(map[string]int)}
+}
+
+// startStep starts a new step for a page of the session
+func (d *recordingDraft) startStep(prefix, pageURL string) {
+	name := prefix
+	if parsed, err := url.Parse(pageURL); err == nil && parsed.Host != "" {
+		name += " " + parsed.Host + parsed.Path
+	} else if pageURL != "" {
+		name += " " + pageURL
+	}
+	d.steps = append(d.steps, ExportedAutomationStep{
+		Name:      truncateRecordingName(name),
+		StepOrder: len(d.steps) + 1,
+		Actions:   []ExportedAutomationAction{},
+	})
+}
+
+// add appends an action to the current step, starting one if the session didn't open a page yet
+func (d *recordingDraft) add(name, actionType string, config map[string]interface{}) {
+	if d.actions >= maxRecordingActions {
+		d.truncated = true
+		return
+	}
+	if len(d.steps) == 0 {
+		d.startStep("Start", "")
+	}
+	step := &d.steps[len(d.steps)-1]
+	step.Actions = append(step.Actions, ExportedAutomationAction{
+		Name:         truncateRecordingName(name),
+		ActionType:   actionType,
+		ActionConfig: config,
+		ActionOrder:  len(step.Actions) + 1,
+	})
+	d.actions++
+}
+
+// last returns the last action of the current step, nil if it has none
+func (d *recordingDraft) last() *ExportedAutomationAction {
+	if len(d.steps) == 0 {
+		return nil
+	}
+	step := &d.steps[len(d.steps)-1]
+	if len(step.Actions) == 0 {
+		return nil
+	}
+	return &step.Actions[len(step.Actions)-1]
+}
+
+// dropLast removes the last action of the current step
+func (d *recordingDraft) dropLast() {
+	step := &d.steps[len(d.steps)-1]
+	step.Actions = step.Actions[:len(step.Actions)-1]
+	d.actions--
+}
+
+// placeholder is the selector of an element the recording doesn't identify
+func (d *recordingDraft) placeholder(what string) string {
+	d.placeholders++
+	return DraftSelectorPlaceholder + " " + what
+}
+
+// result lists what the conversion left out or couldn't determine
+func (d *recordingDraft) result() []ConfigWarning {
+	warnings := []ConfigWarning{}
+	kinds := make([]string, 0, len(d.skipped))
+	for kind := range d.skipped {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		warnings = append(warnings, ConfigWarning{
+			ActionType: "recording:" + kind,
+			Message:    fmt.Sprintf("%d %s events were left out", d.skipped[kind], kind),
+		})
+	}
+	if d.placeholders > 0 {
+		warnings = append(warnings, ConfigWarning{
+			ActionType: "recording",
+			Message:    fmt.Sprintf("%d selectors or values couldn't be taken from the recording; replace the '%s' placeholders", d.placeholders, DraftSelectorPlaceholder),
+		})
+	}
+	if d.truncated {
+		warnings = append(warnings, ConfigWarning{
+			ActionType: "recording",
+			Message:    fmt.Sprintf("the recording was cut off after %d actions", maxRecordingActions),
+		})
+	}
+	return warnings
+}
+
+// Chrome DevTools Recorder
+
+type chromeRecorderStep struct {
+	Type           string            `json:"type"`
+	URL            string            `json:"url"`
+	Selectors      []json.RawMessage `json:"selectors"`
+	Value          string            `json:"value"`
+	Key            string            `json:"key"`
+	Button         string            `json:"button"`
+	Width          int               `json:"width"`
+	Height         int               `json:"height"`
+	AssertedEvents []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"assertedEvents"`
+}
+
+func (d *recordingDraft) convertChromeRecording(data json.RawMessage) error {
+	var steps []chromeRecorderStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return fmt.Errorf("invalid Chrome recording steps: %w", err)
+	}
+
+	var lastSelector string
+	for _, step := range steps {
+		selector := ""
+		if len(step.Selectors) > 0 {
+			selector = chromeRecorderSelector(step.Selectors)
+			if selector == "" {
+				selector = d.placeholder(step.Type + " target")
+			}
+		}
+
+		switch step.Type {
+		case "navigate":
+			d.startStep("Open", step.URL)
+			d.add("Open "+step.URL, "playwright:goto", map[string]interface{}{"url": step.URL, "wait_until": "load"})
+		case "setViewport":
+			d.add("Set viewport", "playwright:set_viewport", map[string]interface{}{"width": step.Width, "height": step.Height})
+		case "click", "doubleClick":
+			config := map[string]interface{}{"selector": selector}
+			switch step.Button {
+			case "secondary":
+				config["button"] = "right"
+			case "auxiliary":
+				config["button"] = "middle"
+			}
+			if step.Type == "doubleClick" {
+				config["click_count"] = 2
+			}
+			d.add("Click "+selector, "playwright:click", config)
+		case "change":
+			d.add("Fill "+selector, "playwright:fill", map[string]interface{}{"selector": selector, "value": step.Value})
+		case "hover":
+			d.add("Hover "+selector, "playwright:hover", map[string]interface{}{"selector": selector})
+		case "waitForElement":
+			d.add("Wait for "+selector, "playwright:wait_for_selector", map[string]interface{}{"selector": selector, "state": "visible"})
+		case "keyDown":
+			// Keys go to the focused element, usually the one interacted with last
+			target := lastSelector
+			if target == "" {
+				target = "body"
+			}
+			d.add("Press "+step.Key, "playwright:press", map[string]interface{}{"selector": target, "key": step.Key})
+		case "keyUp":
+			// Pressing covers the key going up
+		default:
+			d.skipped[step.Type]++
+		}
+		if selector != "" {
+			lastSelector = selector
+		}
+
+		// Interactions that load another page end the step of the current one
+		for _, event := range step.AssertedEvents {
+			if event.Type == "navigation" && step.Type != "navigate" {
+				d.startStep("On", event.URL)
+				d.add("Wait for the page to load", "playwright:wait_for_load_state", map[string]interface{}{"state": "load"})
+				lastSelector = ""
+			}
+		}
+	}
+	return nil
+}
+
+// ariaSelectorPattern matches Chrome's aria selectors naming a role, e.g. Log in[role="button"]
+var ariaSelectorPattern = regexp.MustCompile(`^(.*)\[role="([^"]+)"\]$`)
+
+// chromeRecorderSelector picks the most robust of the alternative selectors Chrome recorded for
+// an element and converts it to a Playwright selector: CSS, then XPath, aria and text selectors.
+// Each alternative is a string or a chain through shadow roots.
+func chromeRecorderSelector(alternatives []json.RawMessage) string {
+	candidates := map[string]string{}
+	for _, raw := range alternatives {
+		var chain []string
+		if err := json.Unmarshal(raw, &chain); err != nil {
+			var single string
+			if json.Unmarshal(raw, &single) != nil {
+				continue
+			}
+			chain = []string{single}
+		}
+		if len(chain) == 0 {
+			continue
+		}
+
+		kind, value := "css", chain[0]
+		for _, prefix := range []string{"aria", "xpath", "pierce", "text"} {
+			if rest, ok := strings.CutPrefix(chain[0], prefix+"/"); ok {
+				kind, value = prefix, rest
+			}
+		}
+		if _, seen := candidates[kind]; seen {
+			continue
+		}
+
+		switch kind {
+		case "css", "pierce":
+			// Playwright's CSS engine pierces open shadow roots, so chains join as descendants
+			parts := make([]string, len(chain))
+			for i, part := range chain {
+				parts[i] = strings.TrimPrefix(part, "pierce/")
+			}
+			candidates["css"] = strings.Join(parts, " ")
+		case "xpath":
+			if len(chain) == 1 {
+				candidates[kind] = "xpath=" + value
+			}
+		case "text":
+			candidates[kind] = "text=" + strconv.Quote(value)
+		case "aria":
+			if match := ariaSelectorPattern.FindStringSubmatch(value); match != nil {
+				candidates[kind] = fmt.Sprintf("role=%s[name=%s]", match[2], strconv.Quote(match[1]))
+			} else {
+				candidates[kind] = "text=" + strconv.Quote(value)
+			}
+		}
+	}
+
+	for _, kind := range []string{"css", "xpath", "aria", "text"} {
+		if selector := candidates[kind]; selector != "" {
+			return selector
+		}
+	}
+	return ""
+}
+
+// rrweb
+
+// rrweb event types, incremental sources and mouse interactions
+const (
+	rrwebEventFullSnapshot = 2
+	rrwebEventIncremental  = 3
+	rrwebEventMeta         = 4
+
+	rrwebSourceMutation         = 0
+	rrwebSourceMouseMove        = 1
+	rrwebSourceMouseInteraction = 2
+	rrwebSourceScroll           = 3
+	rrwebSourceViewportResize   = 4
+	rrwebSourceInput            = 5
+
+	rrwebMouseClick    = 2
+	rrwebMouseDblClick = 4
+
+	rrwebNodeElement = 2
+	rrwebNodeText    = 3
+)
+
+type rrwebEvent struct {
+	Type int       `json:"type"`
+	Data rrwebData `json:"data"`
+}
+
+type rrwebData struct {
+	Href      string     `json:"href"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	Node      *rrwebNode `json:"node"`
+	Source    *int       `json:"source"`
+	Type      int        `json:"type"`
+	ID        int        `json:"id"`
+	Text      string     `json:"text"`
+	IsChecked *bool      `json:"isChecked"`
+	Adds      []struct {
+		ParentID int        `json:"parentId"`
+		Node     *rrwebNode `json:"node"`
+	} `json:"adds"`
+}
+
+type rrwebNode struct {
+	ID          int            `json:"id"`
+	Type        int            `json:"type"`
+	TagName     string         `json:"tagName"`
+	Attributes  map[string]any `json:"attributes"`
+	ChildNodes  []*rrwebNode   `json:"childNodes"`
+	TextContent string         `json:"textContent"`
+}
+
+// rrwebDOM indexes the nodes of the snapshots and mutations seen so far
+type rrwebDOM struct {
+	nodes   map[int]*rrwebNode
+	parents map[int]int
+}
+
+func (dom *rrwebDOM) index(node *rrwebNode, parentID int) {
+	if node == nil {
+		return
+	}
+	dom.nodes[node.ID] = node
+	dom.parents[node.ID] = parentID
+	for _, child := range node.ChildNodes {
+		dom.index(child, node.ID)
+	}
+}
+
+func (dom *rrwebDOM) attr(node *rrwebNode, name string) string {
+	value, _ := node.Attributes[name].(string)
+	return value
+}
+
+func (dom *rrwebDOM) element(id int) *rrwebNode {
+	if node := dom.nodes[id]; node != nil && node.Type == rrwebNodeElement {
+		return node
+	}
+	return nil
+}
+
+// text returns the whitespace-collapsed text within a node
+func (dom *rrwebDOM) text(node *rrwebNode) string {
+	var b strings.Builder
+	var walk func(*rrwebNode)
+	walk = func(n *rrwebNode) {
+		if n.Type == rrwebNodeText {
+			b.WriteString(n.TextContent)
+			b.WriteByte(' ')
+		}
+		for _, child := range n.ChildNodes {
+			walk(child)
+		}
+	}
+	walk(node)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// cssIdentPattern matches IDs usable in a CSS selector without escaping
+var cssIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// selector builds a selector for an element from its most stable attributes, falling back to
+// its position below the nearest ancestor with an ID
+func (dom *rrwebDOM) selector(node *rrwebNode) string {
+	tag := strings.ToLower(node.TagName)
+	if id := dom.attr(node, "id"); cssIdentPattern.MatchString(id) {
+		return "#" + id
+	}
+	for _, name := range []string{"data-testid", "data-test", "data-cy", "name", "aria-label", "placeholder"} {
+		if value := dom.attr(node, name); value != "" {
+			return fmt.Sprintf("%s[%s=%s]", tag, name, strconv.Quote(value))
+		}
+	}
+	if tag == "button" || tag == "a" {
+		if text := dom.text(node); text != "" && len(text) <= 50 {
+			return fmt.Sprintf("%s:has-text(%s)", tag, strconv.Quote(text))
+		}
+	}
+
+	var path []string
+	for current := node; current != nil; {
+		currentTag := strings.ToLower(current.TagName)
+		if id := dom.attr(current, "id"); current != node && cssIdentPattern.MatchString(id) {
+			path = append(path, "#"+id)
+			break
+		}
+		parent := dom.element(dom.parents[current.ID])
+		segment := currentTag
+		if parent != nil {
+			position, sameTag := 0, 0
+			for _, sibling := range parent.ChildNodes {
+				if sibling.Type == rrwebNodeElement && strings.EqualFold(sibling.TagName, current.TagName) {
+					sameTag++
+					if sibling.ID == current.ID {
+						position = sameTag
+					}
+				}
+			}
+			if sameTag > 1 {
+				segment = fmt.Sprintf("%s:nth-of-type(%d)", currentTag, position)
+			}
+		}
+		path = append(path, segment)
+		current = parent
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return strings.Join(path, " > ")
+}
+
+func (d *recordingDraft) convertRRWeb(data []byte) error {
+	var events []rrwebEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return fmt.Errorf("invalid rrweb events: %w", err)
+	}
+	if len(events) == 0 {
+		return errors.New("the recording has no events")
+	}
+
+	dom := &rrwebDOM{nodes: make(map[int]*rrwebNode), parents: make(map[int]int)}
+	var currentURL string
+	viewportSet := false
+	lastInputID := -1
+
+	for _, event := range events {
+		switch event.Type {
+		case rrwebEventMeta:
+			if !viewportSet && event.Data.Width > 0 && event.Data.Height > 0 {
+				d.add("Set viewport", "playwright:set_viewport", map[string]interface{}{"width": event.Data.Width, "height": event.Data.Height})
+				viewportSet = true
+			}
+			if event.Data.Href != "" && event.Data.Href != currentURL {
+				currentURL = event.Data.Href
+				d.startStep("Open", currentURL)
+				d.add("Open "+currentURL, "playwright:goto", map[string]interface{}{"url": currentURL, "wait_until": "load"})
+				lastInputID = -1
+			}
+
+		case rrwebEventFullSnapshot:
+			// Each page load snapshots the whole document again
+			dom.nodes, dom.parents = make(map[int]*rrwebNode), make(map[int]int)
+			dom.index(event.Data.Node, 0)
+
+		case rrwebEventIncremental:
+			if event.Data.Source == nil {
+				continue
+			}
+			switch *event.Data.Source {
+			case rrwebSourceMutation:
+				for _, add := range event.Data.Adds {
+					dom.index(add.Node, add.ParentID)
+					if parent := dom.nodes[add.ParentID]; parent != nil && add.Node != nil {
+						parent.ChildNodes = append(parent.ChildNodes, add.Node)
+					}
+				}
+
+			case rrwebSourceMouseInteraction:
+				if event.Data.Type != rrwebMouseClick && event.Data.Type != rrwebMouseDblClick {
+					continue
+				}
+				node := dom.element(event.Data.ID)
+				if node != nil {
+					// Inputs report checking and selecting themselves
+					inputType := strings.ToLower(dom.attr(node, "type"))
+					if strings.EqualFold(node.TagName, "select") || inputType == "checkbox" || inputType == "radio" {
+						continue
+					}
+				}
+				selector := d.placeholder(fmt.Sprintf("clicked element %d", event.Data.ID))
+				if node != nil {
+					d.placeholders--
+					selector = dom.selector(node)
+				}
+
+				config := map[string]interface{}{"selector": selector}
+				if event.Data.Type == rrwebMouseDblClick {
+					// The clicks of a double click were recorded before it
+					for i := 0; i < 2; i++ {
+						if last := d.last(); last != nil && last.ActionType == "playwright:click" && last.ActionConfig["selector"] == selector {
+							d.dropLast()
+						}
+					}
+					config["click_count"] = 2
+				}
+				d.add("Click "+selector, "playwright:click", config)
+				lastInputID = -1
+
+			case rrwebSourceInput:
+				node := dom.element(event.Data.ID)
+				if node == nil {
+					d.skipped["input on an unknown element"]++
+					continue
+				}
+				selector := dom.selector(node)
+				inputType := strings.ToLower(dom.attr(node, "type"))
+
+				switch {
+				case inputType == "checkbox" || inputType == "radio":
+					if event.Data.IsChecked == nil {
+						continue
+					}
+					if *event.Data.IsChecked {
+						d.add("Check "+selector, "playwright:check", map[string]interface{}{"selector": selector})
+					} else if inputType == "checkbox" {
+						d.add("Uncheck "+selector, "playwright:uncheck", map[string]interface{}{"selector": selector})
+					}
+					lastInputID = -1
+				case strings.EqualFold(node.TagName, "select"):
+					d.add("Select in "+selector, "playwright:select_option", map[string]interface{}{"selector": selector, "value": event.Data.Text})
+					lastInputID = -1
+				default:
+					value := event.Data.Text
+					// rrweb masks passwords and other sensitive inputs with asterisks; each field
+					// filled with a masked value is one placeholder to fill in
+					maskedValue := DraftSelectorPlaceholder + " value"
+					masked := value != "" && strings.Trim(value, "*") == ""
+					if masked {
+						value = maskedValue
+					}
+					// Typing reports every keystroke; the field's final value is what counts
+					if last := d.last(); last != nil && lastInputID == event.Data.ID && last.ActionType == "playwright:fill" {
+						wasMasked := last.ActionConfig["value"] == maskedValue
+						if masked && !wasMasked {
+							d.placeholders++
+						} else if !masked && wasMasked {
+							d.placeholders--
+						}
+						last.ActionConfig["value"] = value
+						continue
+					}
+					if masked {
+						d.placeholders++
+					}
+					d.add("Fill "+selector, "playwright:fill", map[string]interface{}{"selector": selector, "value": value})
+					lastInputID = event.Data.ID
+				}
+
+			case rrwebSourceViewportResize:
+				d.add("Set viewport", "playwright:set_viewport", map[string]interface{}{"width": event.Data.Width, "height": event.Data.Height})
+				lastInputID = -1
+
+			case rrwebSourceMouseMove:
+				d.skipped["mouse move"]++
+			case rrwebSourceScroll:
+				d.skipped["scroll"]++
+			}
+		}
+	}
+	return nil
+}
+
+func truncateRecordingName(name string) string {
+	if runes := []rune(name); len(runes) > maxRecordingNameLength {
+		return string(runes[:maxRecordingNameLength-3]) + "..."
+	}
+	return name
+}
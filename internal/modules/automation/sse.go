@@ -3,6 +3,7 @@ package automation
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/alexandrevicenzi/go-sse"
@@ -11,6 +12,9 @@ import (
 // SSEManager handles Server-Sent Events for automation runs
 type SSEManager struct {
 	server *sse.Server
+
+	mu       sync.Mutex
+	samplers map[string]*runEventSampler // by run ID, for runs being executed
 }
 
 // NewSSEManager creates a new SSE manager
@@ -24,7 +28,8 @@ func NewSSEManager() *SSEManager {
 	})
 
 	return &SSEManager{
-		server: server,
+		server:   server,
+		samplers: make(map[string]*runEventSampler),
 	}
 }
 
@@ -56,8 +61,60 @@ func (s *SSEManager) Shutdown() {
 // 	Data        map[string]interface{} `json:"data,omitempty"`
 // }
 
-// SendRunProgress sends a progress update for a specific run
+// StartRunSampling samples the live events of a run while it streams more than its threshold,
+// see LiveEventsConfig
+func (s *SSEManager) StartRunSampling(runID string, config LiveEventsConfig) {
+	if config.DisableSampling {
+		return
+	}
+	threshold := config.SamplingThreshold
+	if threshold <= 0 {
+		threshold = DefaultSSESamplingThreshold
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samplers[runID] = newRunEventSampler(threshold, time.Now())
+}
+
+// EndRunSampling stops sampling a run, sending the marker for any messages dropped since the last one
+func (s *SSEManager) EndRunSampling(projectID, automationID, runID string) {
+	s.mu.Lock()
+	sampler, ok := s.samplers[runID]
+	delete(s.samplers, runID)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	for _, marker := range sampler.flush(time.Now()) {
+		marker.RunID = runID
+		s.sendRunProgress(projectID, automationID, runID, marker)
+	}
+}
+
+// SendRunProgress sends a progress update for a specific run, unless the run is being sampled and
+// the update is one of those dropped
 func (s *SSEManager) SendRunProgress(projectID, automationID, runID string, message RunProgressMessage) error {
+	s.mu.Lock()
+	sampler := s.samplers[runID]
+	forward, markers := true, []RunProgressMessage(nil)
+	if sampler != nil {
+		forward, markers = sampler.admit(&message, time.Now())
+	}
+	s.mu.Unlock()
+
+	for _, marker := range markers {
+		marker.RunID = runID
+		s.sendRunProgress(projectID, automationID, runID, marker)
+	}
+	if !forward {
+		return nil
+	}
+	return s.sendRunProgress(projectID, automationID, runID, message)
+}
+
+func (s *SSEManager) sendRunProgress(projectID, automationID, runID string, message RunProgressMessage) error {
 	message.Timestamp = time.Now()
 
 	data, err := json.Marshal(message)
@@ -108,6 +165,17 @@ func (s *SSEManager) SendRunError(projectID, automationID, runID, stepName, acti
 	})
 }
 
+// SendRunWarning sends a non-fatal warning update, e.g. use of a deprecated action
+func (s *SSEManager) SendRunWarning(projectID, automationID, runID, stepName, actionType, message string) error {
+	return s.SendRunProgress(projectID, automationID, runID, RunProgressMessage{
+		Type:       "warning",
+		RunID:      runID,
+		StepName:   stepName,
+		ActionType: actionType,
+		Message:    message,
+	})
+}
+
 // SendRunStep sends a step progress update
 func (s *SSEManager) SendRunStep(projectID, automationID, runID, stepName string, currentStep, totalSteps int) error {
 	progress := 0
@@ -126,7 +194,7 @@ func (s *SSEManager) SendRunStep(projectID, automationID, runID, stepName string
 }
 
 // SendRunComplete sends a completion update
-func (s *SSEManager) SendRunComplete(projectID, automationID, runID, status string, duration int64, outputFiles []string) error {
+func (s *SSEManager) SendRunComplete(projectID, automationID, runID, status string, duration int64, outputFiles []OutputFile) error {
 	return s.SendRunProgress(projectID, automationID, runID, RunProgressMessage{
 		Type:     "complete",
 		RunID:    runID,
@@ -139,11 +207,14 @@ func (s *SSEManager) SendRunComplete(projectID, automationID, runID, status stri
 }
 
 // SendRunOutputFile sends an output file update
-func (s *SSEManager) SendRunOutputFile(projectID, automationID, runID, outputFile string) error {
+func (s *SSEManager) SendRunOutputFile(projectID, automationID, runID string, outputFile OutputFile) error {
 	return s.SendRunProgress(projectID, automationID, runID, RunProgressMessage{
 		Type:       "output",
 		RunID:      runID,
-		OutputFile: outputFile,
+		OutputFile: outputFile.URL,
+		Data: map[string]interface{}{
+			"file": outputFile,
+		},
 	})
 }
 
@@ -0,0 +1,145 @@
+package automation
+
+import (
+	"time"
+)
+
+// DefaultSSESamplingThreshold is the events per second a run may stream before its per-action
+// events are sampled
+const DefaultSSESamplingThreshold = 50
+
+// sseSamplingWindow is the period events are counted over, and how often sampled markers are sent
+const sseSamplingWindow = time.Second
+
+// sampledMessageTypes are the per-action messages dropped while a run is sampled
+var sampledMessageTypes = map[string]bool{"log": true, "step": true, "output": true}
+
+// runEventSampler decides which of a run's messages reach the browser. Sampling starts once a
+// window holds more messages than the threshold, and stops after a window with at most half of it,
+// so a run hovering around the threshold doesn't flip on every window.
+type runEventSampler struct {
+	threshold    int
+	windowStart  time.Time
+	windowCount  int
+	sampling     bool
+	dropped      map[string]int
+	droppedSteps map[string]*sampledStep
+	lastStep     *RunProgressMessage // latest step progress dropped, sent with the next marker
+}
+
+// sampledStep counts the actions of a step whose messages were dropped
+type sampledStep struct {
+	Actions         int   `json:"actions"`
+	Errors          int   `json:"errors"`
+	TotalDurationMs int64 `json:"-"`
+	AvgDurationMs   int64 `json:"avg_duration_ms"`
+}
+
+func newRunEventSampler(threshold int, now time.Time) *runEventSampler {
+	return &runEventSampler{
+		threshold:    threshold,
+		windowStart:  now,
+		dropped:      make(map[string]int),
+		droppedSteps: make(map[string]*sampledStep),
+	}
+}
+
+// admit reports whether message is sent, returning the markers to send before it
+func (s *runEventSampler) admit(message *RunProgressMessage, now time.Time) (bool, []RunProgressMessage) {
+	var markers []RunProgressMessage
+	if elapsed := now.Sub(s.windowStart); elapsed >= sseSamplingWindow {
+		markers = s.closeWindow(elapsed)
+		s.windowStart = now
+		s.windowCount = 0
+	}
+
+	s.windowCount++
+	if !s.sampling && s.windowCount > s.threshold {
+		s.sampling = true
+		markers = append(markers, s.marker(true, now.Sub(s.windowStart)))
+	}
+
+	if message.Type == "error" && s.sampling {
+		s.step(message.StepName).Errors++
+	}
+	if !s.sampling || !sampledMessageTypes[message.Type] {
+		return true, markers
+	}
+
+	s.dropped[message.Type]++
+	switch message.Type {
+	case "log":
+		step := s.step(message.StepName)
+		step.Actions++
+		step.TotalDurationMs += message.Duration
+	case "step":
+		s.lastStep = message
+	}
+	return false, markers
+}
+
+// closeWindow ends the current window, returning the markers summarizing it
+func (s *runEventSampler) closeWindow(elapsed time.Duration) []RunProgressMessage {
+	if !s.sampling {
+		return nil
+	}
+
+	// A quiet window ends sampling
+	active := s.windowCount > s.threshold/2
+	var markers []RunProgressMessage
+	if len(s.dropped) > 0 || !active {
+		markers = append(markers, s.marker(active, elapsed))
+	}
+	if s.lastStep != nil {
+		markers = append(markers, *s.lastStep)
+		s.lastStep = nil
+	}
+	s.sampling = active
+	return markers
+}
+
+// flush returns the markers for messages dropped since the last one, e.g. when the run ends
+func (s *runEventSampler) flush(now time.Time) []RunProgressMessage {
+	if !s.sampling {
+		return nil
+	}
+	s.windowCount = 0
+	return s.closeWindow(now.Sub(s.windowStart))
+}
+
+func (s *runEventSampler) step(name string) *sampledStep {
+	step, ok := s.droppedSteps[name]
+	if !ok {
+		step = &sampledStep{}
+		s.droppedSteps[name] = step
+	}
+	return step
+}
+
+// marker describes the messages dropped in the window so far, resetting the counts
+func (s *runEventSampler) marker(active bool, elapsed time.Duration) RunProgressMessage {
+	total := 0
+	for _, count := range s.dropped {
+		total += count
+	}
+	for _, step := range s.droppedSteps {
+		if step.Actions > 0 {
+			step.AvgDurationMs = step.TotalDurationMs / int64(step.Actions)
+		}
+	}
+
+	message := RunProgressMessage{
+		Type: "sampled",
+		Data: map[string]interface{}{
+			"active":          active,
+			"dropped":         total,
+			"dropped_by_type": s.dropped,
+			"steps":           s.droppedSteps,
+			"window_ms":       elapsed.Milliseconds(),
+			"threshold":       s.threshold,
+		},
+	}
+	s.dropped = make(map[string]int)
+	s.droppedSteps = make(map[string]*sampledStep)
+	return message
+}
@@ -0,0 +1,159 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// Reasons an automation is stale
+const (
+	StaleReasonUnused  = "unused"  // it hasn't run for the organization's unusedDays
+	StaleReasonFailing = "failing" // its runs have only failed for the organization's failingDays
+)
+
+// Stale automation defaults, used when an organization doesn't set them
+const (
+	defaultStaleUnusedDays  = 30
+	defaultStaleFailingDays = 7
+)
+
+// staleCheckInterval is how often automations are checked for staleness
+const staleCheckInterval = 1 * time.Hour
+
+// staleReasons returns why an automation is stale at now, if it is. Both periods are measured
+// from when the automation was last re-enabled at the earliest, so re-enabling it gives it a
+// fresh start.
+func staleReasons(activity *AutomationActivity, now time.Time) []string {
+	unusedDays := activity.Settings.UnusedDays
+	if unusedDays <= 0 {
+		unusedDays = defaultStaleUnusedDays
+	}
+	failingDays := activity.Settings.FailingDays
+	if failingDays <= 0 {
+		failingDays = defaultStaleFailingDays
+	}
+
+	var reenabledAt time.Time
+	if activity.Staleness != nil && activity.Staleness.ReenabledAt != nil {
+		reenabledAt = *activity.Staleness.ReenabledAt
+	}
+
+	reasons := []string{}
+	lastActive := activity.CreatedAt
+	if activity.LastRunAt != nil && activity.LastRunAt.After(lastActive) {
+		lastActive = *activity.LastRunAt
+	}
+	if reenabledAt.After(lastActive) {
+		lastActive = reenabledAt
+	}
+	if now.Sub(lastActive) >= time.Duration(unusedDays)*24*time.Hour {
+		reasons = append(reasons, StaleReasonUnused)
+	}
+
+	if activity.FailingSince != nil {
+		failingSince := *activity.FailingSince
+		if reenabledAt.After(failingSince) {
+			failingSince = reenabledAt
+		}
+		if now.Sub(failingSince) >= time.Duration(failingDays)*24*time.Hour {
+			reasons = append(reasons, StaleReasonFailing)
+		}
+	}
+	return reasons
+}
+
+// GetStaleAutomationsByProject lists a project's stale and disabled automations for its
+// maintenance report
+func (s *automationService) GetStaleAutomationsByProject(ctx context.Context, projectID string) ([]*StaleAutomation, error) {
+	stale, err := s.automationRepo.GetStaleAutomationsByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to get stale automations by project", "error", err, "projectID", projectID)
+		return nil, fmt.Errorf("failed to get stale automations: %w", err)
+	}
+	if stale == nil {
+		stale = []*StaleAutomation{}
+	}
+	return stale, nil
+}
+
+// ReenableAutomation lets a disabled automation run again and clears its staleness
+func (s *automationService) ReenableAutomation(ctx context.Context, automationID string) error {
+	if err := s.automationRepo.ReenableAutomation(ctx, automationID); err != nil {
+		slog.Error("Failed to re-enable automation", "error", err, "automationID", automationID)
+		return fmt.Errorf("failed to re-enable automation: %w", err)
+	}
+
+	slog.Info("Automation re-enabled", "automationID", automationID)
+	return nil
+}
+
+// DetectStaleAutomations flags automations that haven't run or have only failed for too long,
+// and disables them when their organization's settings ask for it
+func (s *automationService) DetectStaleAutomations(ctx context.Context) {
+	activities, err := s.automationRepo.GetAutomationActivity(ctx)
+	if err != nil {
+		slog.Error("Failed to get automation activity", "error", err)
+		return
+	}
+
+	now := time.Now()
+	newlyStale, disabled := 0, 0
+	for _, activity := range activities {
+		if ctx.Err() != nil {
+			return
+		}
+
+		staleness := activity.Staleness
+		if staleness == nil {
+			staleness = &StaleAutomation{AutomationID: activity.AutomationID, AutomationName: activity.AutomationName}
+		}
+
+		reasons := []string{}
+		if !activity.Settings.Disabled {
+			reasons = staleReasons(activity, now)
+		}
+		staleness.Reasons = reasons
+		staleness.LastRunAt = activity.LastRunAt
+		staleness.FailingSince = activity.FailingSince
+		staleness.Stale = len(reasons) > 0
+		if !staleness.Stale {
+			staleness.DetectedAt = nil
+		} else if staleness.DetectedAt == nil {
+			staleness.DetectedAt = &now
+			newlyStale++
+			slog.Warn("Automation is stale", "automationID", activity.AutomationID, "name", activity.AutomationName, "reasons", reasons)
+		}
+		if staleness.Stale && activity.Settings.AutoDisable && staleness.DisabledAt == nil {
+			staleness.DisabledAt = &now
+			disabled++
+			slog.Warn("Stale automation disabled", "automationID", activity.AutomationID, "name", activity.AutomationName)
+		}
+
+		if err := s.automationRepo.UpsertAutomationStaleness(ctx, staleness); err != nil {
+			slog.Error("Failed to record automation staleness", "error", err, "automationID", activity.AutomationID)
+		}
+	}
+
+	if newlyStale > 0 || disabled > 0 {
+		slog.Info("Stale automation check finished", "automations", len(activities), "newlyStale", newlyStale, "disabled", disabled)
+	}
+}
+
+// checkAutomationEnabled refuses to run an automation that was disabled for being stale, whether
+// it is triggered, retried or shadows another one in a rollout
+func checkAutomationEnabled(ctx context.Context, automationRepo AutomationRepository, automationID string) error {
+	staleness, err := automationRepo.GetAutomationStaleness(ctx, automationID)
+	if err != nil {
+		slog.Warn("Failed to get automation staleness, proceeding anyway", "error", err, "automationID", automationID)
+		return nil
+	}
+	if staleness != nil && staleness.DisabledAt != nil {
+		return fmt.Errorf("%w: automation was disabled on %s for being stale, re-enable it to run it again",
+			platform.ErrConflict, staleness.DisabledAt.Format(time.DateOnly))
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/notification"
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
+)
+
+const (
+	// defaultStallThreshold is how long a run may go without progress before it is stalled
+	defaultStallThreshold = 10 * time.Minute
+	// minStallThreshold keeps a typo from stalling every run between two heartbeats
+	minStallThreshold = time.Minute
+	// stallCheckInterval is how often running runs are checked for stalls
+	stallCheckInterval = 30 * time.Second
+)
+
+// stallThreshold returns how long a run of an automation with config may go without progress
+func stallThreshold(config StallDetectionConfig) time.Duration {
+	if config.ThresholdSeconds <= 0 {
+		return defaultStallThreshold
+	}
+	return max(time.Duration(config.ThresholdSeconds)*time.Second, minStallThreshold)
+}
+
+// detectStalledRuns marks running runs whose last heartbeat is older than their automation's
+// stall threshold as stalled, notifying and optionally cancelling them, and puts stalled runs
+// that made progress again back to running
+func (s *Scheduler) detectStalledRuns(ctx context.Context) {
+	runIDs, err := s.runCache.GetAllRunningRuns(ctx)
+	if err != nil {
+		slog.Error("Failed to get running runs for stall detection", "error", err)
+		return
+	}
+
+	for _, runID := range runIDs {
+		run, err := s.automationRepo.GetRunByID(ctx, runID)
+		if err != nil {
+			slog.Error("Failed to get run for stall detection", "run_id", runID, "error", err)
+			continue
+		}
+		if run.Status != "running" && run.Status != "stalled" {
+			continue
+		}
+
+		automation, err := s.automationRepo.GetAutomationByID(ctx, run.AutomationID)
+		if err != nil {
+			slog.Error("Failed to get automation for stall detection", "automation_id", run.AutomationID, "error", err)
+			continue
+		}
+		var automationConfig AutomationConfig
+		if automation.ConfigJSON != "" {
+			if err := json.Unmarshal([]byte(automation.ConfigJSON), &automationConfig); err != nil {
+				continue
+			}
+		}
+		if automationConfig.StallDetection.Disabled {
+			continue
+		}
+
+		lastProgress, err := s.runCache.GetRunHeartbeat(ctx, run.ID)
+		if err != nil {
+			slog.Error("Failed to get run heartbeat", "run_id", run.ID, "error", err)
+			continue
+		}
+		if lastProgress.IsZero() {
+			if run.StartTime == nil {
+				continue
+			}
+			lastProgress = *run.StartTime
+		}
+
+		// Users held by playwright:pause (up to MaxConsolePauseTimeout) don't count as stalled
+		pausedUntil, err := s.runCache.GetRunPausedUntil(ctx, run.ID)
+		if err != nil {
+			slog.Error("Failed to get run pause", "run_id", run.ID, "error", err)
+		} else if pausedUntil.After(time.Now()) {
+			lastProgress = time.Now()
+		}
+
+		idle := time.Since(lastProgress)
+		threshold := stallThreshold(automationConfig.StallDetection)
+		switch {
+		case run.Status == "running" && idle > threshold:
+			s.markRunStalled(ctx, automation, &automationConfig, run, idle)
+		case run.Status == "stalled" && idle <= threshold:
+			s.setRunStatus(ctx, automation.ProjectID, run, "stalled", "running")
+			slog.Info("Stalled run made progress again", "run_id", run.ID)
+		}
+	}
+}
+
+// markRunStalled marks a run stalled, notifies channels with onError set and, when configured,
+// cancels the run if it executes in this worker
+func (s *Scheduler) markRunStalled(ctx context.Context, automation *Automation, automationConfig *AutomationConfig, run *AutomationRun, idle time.Duration) {
+	// Only the worker that makes the transition notifies, so a stall is reported once
+	if !s.setRunStatus(ctx, automation.ProjectID, run, "running", "stalled") {
+		return
+	}
+	slog.Warn("Run stalled", "run_id", run.ID, "automation_id", automation.ID, "idle", idle.Round(time.Second))
+
+	cancelled := false
+	if automationConfig.StallDetection.CancelStalled {
+		if s.IsRunActive(run.ID) {
+			if err := s.CancelRun(ctx, automation.ProjectID, run.ID); err != nil {
+				slog.Error("Failed to cancel stalled run", "run_id", run.ID, "error", err)
+			} else {
+				cancelled = true
+			}
+		} else {
+			slog.Warn("Stalled run executes in another worker and can't be cancelled here", "run_id", run.ID)
+		}
+	}
+
+	s.runner.notifyStalledRun(ctx, automation, run, automationConfig, idle.Round(time.Second), cancelled)
+}
+
+// setRunStatus moves a run from one status to another in the database, cache and live view,
+// reporting false when the run was no longer in fromStatus
+func (s *Scheduler) setRunStatus(ctx context.Context, projectID string, run *AutomationRun, fromStatus, toStatus string) bool {
+	changed, err := s.automationRepo.TransitionRunStatus(ctx, run.ID, fromStatus, toStatus)
+	if err != nil {
+		slog.Error("Failed to update run status", "run_id", run.ID, "status", toStatus, "error", err)
+		return false
+	}
+	if !changed {
+		return false
+	}
+	run.Status = toStatus
+
+	if err := s.runCache.SetRunStatus(ctx, run.ID, toStatus); err != nil {
+		slog.Error("Failed to update run status in cache", "run_id", run.ID, "error", err)
+	}
+	if s.sseManager != nil {
+		s.sseManager.SendRunStatusUpdate(projectID, run.AutomationID, run.ID, toStatus)
+	}
+	return true
+}
+
+// notifyStalledRun sends a "stalled" notification to channels with onError set and to the
+// automation's owners
+func (r *Runner) notifyStalledRun(ctx context.Context, automation *Automation, run *AutomationRun, automationConfig *AutomationConfig, idle time.Duration, cancelled bool) {
+	if silencedForRollout(ctx, r.automationRepo, automation.ID, "stalled") || silencedForMaintenance(ctx, r.automationRepo, run, "stalled") {
+		return
+	}
+
+	var channels []notification.NotificationChannelConfig
+	for _, channel := range automationConfig.Notifications {
+		if !channel.OnError {
+			continue
+		}
+		channels = append(channels, notification.NotificationChannelConfig{
+			ID:      channel.ID,
+			Type:    channel.Type,
+			OnError: channel.OnError,
+			Config:  channel.Config,
+		})
+	}
+	channels = append(channels, ownerFailureChannels(ctx, r.automationRepo, automation, automationConfig, automationConfig.Notifications)...)
+	if len(channels) == 0 {
+		return
+	}
+
+	locale := reportLocale(ctx, r.automationRepo, automation, automationConfig)
+	l := i18n.For(locale)
+	reason := l.T("stall.no_progress", idle)
+	if cancelled {
+		reason = l.T("stall.no_progress_cancel", idle)
+	}
+
+	message := notification.NotificationMessage{
+		AutomationID:   automation.ID,
+		AutomationName: automation.Name,
+		ProjectID:      automation.ProjectID,
+		RunID:          run.ID,
+		Status:         "stalled",
+		StartTime:      run.StartTime,
+		ErrorMessage:   reason,
+		Locale:         locale,
+	}
+
+	if err := r.notificationService.DispatchAutomationNotification(ctx, message, channels); err != nil {
+		slog.Error("Failed to dispatch stalled run notifications", "automation_id", automation.ID, "run_id", run.ID, "error", err)
+	}
+}
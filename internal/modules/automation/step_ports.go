@@ -0,0 +1,261 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Types of step inputs and outputs; an empty type accepts any value
+const (
+	PortTypeString  = "string"
+	PortTypeNumber  = "number"
+	PortTypeBoolean = "boolean"
+	PortTypeObject  = "object"
+	PortTypeArray   = "array"
+)
+
+// Action types of the data flow problems reported for step inputs and outputs
+const (
+	StepInputsActionType  = "step:inputs"
+	StepOutputsActionType = "step:outputs"
+)
+
+// StepOutput publishes a runtime variable set by the step's actions, or a path into one, under a
+// name later steps can declare as an input
+type StepOutput struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"` // e.g. "order" or "response.body.id"; defaults to Name
+	Type string `json:"type,omitempty"`
+}
+
+// StepInput is a value a step requires from the output of an earlier step. Actions read it as
+// {{runtime.<name>}}.
+type StepInput struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+var (
+	portNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	portFromPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z0-9_]+)*$`)
+)
+
+// ParseStepConfig parses a step's config JSON, an empty config being an empty StepConfig
+func ParseStepConfig(configJSON string) (*StepConfig, error) {
+	config := &StepConfig{}
+	if strings.TrimSpace(configJSON) == "" {
+		return config, nil
+	}
+	if err := json.Unmarshal([]byte(configJSON), config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ValidateStepPorts checks the names and types of a step's inputs and outputs
+func ValidateStepPorts(config *StepConfig) error {
+	inputs := make(map[string]bool, len(config.Inputs))
+	for _, input := range config.Inputs {
+		if !portNamePattern.MatchString(input.Name) {
+			return fmt.Errorf("input name '%s' must start with a letter or underscore and contain only letters, digits and underscores", input.Name)
+		}
+		if inputs[input.Name] {
+			return fmt.Errorf("input '%s' is declared more than once", input.Name)
+		}
+		if !validPortType(input.Type) {
+			return fmt.Errorf("input '%s' has unknown type '%s'", input.Name, input.Type)
+		}
+		inputs[input.Name] = true
+	}
+
+	outputs := make(map[string]bool, len(config.Outputs))
+	for _, output := range config.Outputs {
+		if !portNamePattern.MatchString(output.Name) {
+			return fmt.Errorf("output name '%s' must start with a letter or underscore and contain only letters, digits and underscores", output.Name)
+		}
+		if outputs[output.Name] {
+			return fmt.Errorf("output '%s' is declared more than once", output.Name)
+		}
+		if output.From != "" && !portFromPattern.MatchString(output.From) {
+			return fmt.Errorf("output '%s' maps from '%s', which isn't a runtime variable or a dotted path into one", output.Name, output.From)
+		}
+		if !validPortType(output.Type) {
+			return fmt.Errorf("output '%s' has unknown type '%s'", output.Name, output.Type)
+		}
+		outputs[output.Name] = true
+	}
+	return nil
+}
+
+func validPortType(portType string) bool {
+	switch portType {
+	case "", PortTypeString, PortTypeNumber, PortTypeBoolean, PortTypeObject, PortTypeArray:
+		return true
+	}
+	return false
+}
+
+// outputSource is the runtime variable path an output is read from
+func outputSource(output StepOutput) string {
+	if output.From != "" {
+		return output.From
+	}
+	return output.Name
+}
+
+// CheckStepDataFlow reports inputs of steps that no earlier step outputs, or outputs with another
+// type, for steps in execution order. With actionsByStep it also reports outputs mapped from a
+// runtime variable none of the step's actions saves, the usual missing-extraction bug.
+func CheckStepDataFlow(steps []*AutomationStep, actionsByStep map[string][]*AutomationAction) []ConfigWarning {
+	type producer struct {
+		stepName string
+		portType string
+	}
+
+	warnings := []ConfigWarning{}
+	produced := make(map[string]producer)
+	for _, step := range steps {
+		config, err := ParseStepConfig(step.ConfigJSON)
+		if err != nil {
+			continue
+		}
+
+		for _, input := range config.Inputs {
+			output, ok := produced[input.Name]
+			switch {
+			case !ok:
+				warnings = append(warnings, ConfigWarning{
+					StepID:     step.ID,
+					ActionType: StepInputsActionType,
+					Field:      "inputs." + input.Name,
+					Message:    fmt.Sprintf("Step '%s' requires input '%s', which no earlier step outputs", step.Name, input.Name),
+				})
+			case input.Type != "" && output.portType != "" && input.Type != output.portType:
+				warnings = append(warnings, ConfigWarning{
+					StepID:     step.ID,
+					ActionType: StepInputsActionType,
+					Field:      "inputs." + input.Name,
+					Message: fmt.Sprintf("Step '%s' requires input '%s' as %s, but step '%s' outputs it as %s",
+						step.Name, input.Name, input.Type, output.stepName, output.portType),
+				})
+			}
+		}
+
+		if actionsByStep != nil {
+			saved := savedVariables(actionsByStep[step.ID])
+			for _, input := range config.Inputs {
+				saved[input.Name] = true
+			}
+			for _, output := range config.Outputs {
+				variable, _, _ := strings.Cut(outputSource(output), ".")
+				if !saved[variable] {
+					warnings = append(warnings, ConfigWarning{
+						StepID:     step.ID,
+						ActionType: StepOutputsActionType,
+						Field:      "outputs." + output.Name,
+						Message: fmt.Sprintf("Step '%s' outputs '%s' from runtime variable '%s', which none of its actions saves",
+							step.Name, output.Name, variable),
+					})
+				}
+			}
+		}
+
+		for _, output := range config.Outputs {
+			produced[output.Name] = producer{stepName: step.Name, portType: output.Type}
+		}
+	}
+	return warnings
+}
+
+// savedVariables collects the 'save_as' names of actions, including those of nested actions and
+// API after hooks
+func savedVariables(actions []*AutomationAction) map[string]bool {
+	saved := make(map[string]bool)
+	var walk func(value any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			if name, ok := v["save_as"].(string); ok && name != "" {
+				saved[name] = true
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	for _, action := range actions {
+		var config any
+		if json.Unmarshal([]byte(action.ActionConfigJSON), &config) == nil {
+			walk(config)
+		}
+	}
+	return saved
+}
+
+// checkStepInputs fails a step whose inputs weren't set, e.g. because the step outputting one
+// was skipped, or were set to a value of another type
+func checkStepInputs(step *AutomationStep, inputs []StepInput, varContext *VariableContext) error {
+	for _, input := range inputs {
+		value, ok := varContext.RuntimeVars[input.Name]
+		if !ok {
+			return fmt.Errorf("step '%s' requires input '%s', which no earlier step output", step.Name, input.Name)
+		}
+		if kind := portValueType(value); input.Type != "" && kind != input.Type {
+			return fmt.Errorf("step '%s' requires input '%s' as %s, got %s", step.Name, input.Name, input.Type, kind)
+		}
+	}
+	return nil
+}
+
+// publishStepOutputs sets the outputs of a step that completed as runtime variables, failing the
+// step if one wasn't produced or has another type than declared
+func (r *Runner) publishStepOutputs(step *AutomationStep, outputs []StepOutput, varContext *VariableContext) error {
+	for _, output := range outputs {
+		value, err := r.resolveRuntimeVariable("runtime."+outputSource(output), varContext)
+		if err != nil {
+			return fmt.Errorf("step '%s' didn't produce output '%s': %w", step.Name, output.Name, err)
+		}
+		if kind := portValueType(value); output.Type != "" && kind != output.Type {
+			return fmt.Errorf("step '%s' output '%s' should be %s, got %s", step.Name, output.Name, output.Type, kind)
+		}
+		varContext.RuntimeVars[output.Name] = value
+	}
+	return nil
+}
+
+// portValueType names the port type of a runtime value
+func portValueType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return PortTypeString
+	case bool:
+		return PortTypeBoolean
+	case json.Number:
+		return PortTypeNumber
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return PortTypeNumber
+	case reflect.Map, reflect.Struct:
+		return PortTypeObject
+	case reflect.Slice, reflect.Array:
+		return PortTypeArray
+	case reflect.Pointer:
+		if pointer := reflect.ValueOf(value); !pointer.IsNil() {
+			return portValueType(pointer.Elem().Interface())
+		}
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,72 @@
+package automation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateStepVariables checks the variables a step declares. Like automation variables they are
+// static, dynamic or environment variables; within the step they shadow automation variables of
+// the same key.
+func ValidateStepVariables(variables []Variable) error {
+	keys := make(map[string]bool, len(variables))
+	for _, variable := range variables {
+		if !portNamePattern.MatchString(variable.Key) {
+			return fmt.Errorf("variable key '%s' must start with a letter or underscore and contain only letters, digits and underscores", variable.Key)
+		}
+		if keys[variable.Key] {
+			return fmt.Errorf("variable '%s' is declared more than once", variable.Key)
+		}
+		switch variable.Type {
+		case "static", "dynamic", "environment":
+		default:
+			return fmt.Errorf("variable '%s' has unknown type '%s'", variable.Key, variable.Type)
+		}
+		keys[variable.Key] = true
+	}
+	return nil
+}
+
+// stepVariable returns the variable of the executing step with the given key
+func (vc *VariableContext) stepVariable(key string) (Variable, bool) {
+	for _, variable := range vc.StepVars {
+		if variable.Key == key {
+			return variable, true
+		}
+	}
+	return Variable{}, false
+}
+
+// withoutStepVariable returns a copy of the context in which the step variable key is hidden
+func (vc *VariableContext) withoutStepVariable(key string) *VariableContext {
+	scoped := *vc
+	scoped.StepVars = make([]Variable, 0, len(vc.StepVars))
+	for _, variable := range vc.StepVars {
+		if variable.Key != key {
+			scoped.StepVars = append(scoped.StepVars, variable)
+		}
+	}
+	return &scoped
+}
+
+// resolveVariable resolves the value of a declared automation or step variable
+func (r *Runner) resolveVariable(variable Variable, varContext *VariableContext, automationConfig *AutomationConfig) string {
+	switch variable.Type {
+	case "dynamic":
+		// Variable.Value contains the faker method (e.g., "{{faker.email}}")
+		if strings.HasPrefix(variable.Value, "{{faker.") && strings.HasSuffix(variable.Value, "}}") {
+			fakerMethod := strings.TrimPrefix(strings.TrimSuffix(variable.Value, "}}"), "{{faker.")
+			return r.generateFakerValue(fakerMethod, varContext)
+		}
+		return variable.Value
+	case "environment":
+		// Variable.Value contains the environment variable (e.g., "{{timestamp}}")
+		v, err := r.ResolveVariablesInString(variable.Value, varContext, automationConfig)
+		if err != nil {
+			return ""
+		}
+		return v
+	default:
+		return variable.Value
+	}
+}
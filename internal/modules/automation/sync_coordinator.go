@@ -0,0 +1,137 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SyncCoordinator lets the parallel virtual users of a run coordinate through named barriers
+// and signals. One coordinator is shared by all loops of a run.
+type SyncCoordinator struct {
+	mu       sync.Mutex
+	active   int // users still running; barriers without an explicit party count wait for all of them
+	barriers map[string]*barrierGeneration
+	signals  map[string]chan struct{}
+}
+
+// barrierGeneration is one use of a named barrier; a new generation starts once it releases,
+// so the same barrier can be reused inside loops
+type barrierGeneration struct {
+	parties int // explicit party count, 0 for all active users
+	arrived int
+	release chan struct{}
+}
+
+// NewSyncCoordinator creates a coordinator for the given number of concurrent users
+func NewSyncCoordinator(participants int) *SyncCoordinator {
+	return &SyncCoordinator{
+		active:   participants,
+		barriers: make(map[string]*barrierGeneration),
+		signals:  make(map[string]chan struct{}),
+	}
+}
+
+// required returns how many arrivals release the generation, never more than the users still running
+func (c *SyncCoordinator) required(generation *barrierGeneration) int {
+	if generation.parties > 0 && generation.parties < c.active {
+		return generation.parties
+	}
+	return max(c.active, 1)
+}
+
+// Barrier blocks until parties users (all running users when parties is 0) reached the barrier
+// called name, and returns the caller's arrival position starting at 1
+func (c *SyncCoordinator) Barrier(ctx context.Context, name string, parties int) (int, error) {
+	c.mu.Lock()
+	generation, exists := c.barriers[name]
+	if !exists {
+		generation = &barrierGeneration{parties: parties, release: make(chan struct{})}
+		c.barriers[name] = generation
+	}
+	generation.arrived++
+	position := generation.arrived
+	if generation.arrived >= c.required(generation) {
+		c.releaseLocked(name, generation)
+		c.mu.Unlock()
+		return position, nil
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-generation.release:
+		return position, nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		select {
+		case <-generation.release:
+			// Released while we were giving up, so the barrier was passed after all
+			return position, nil
+		default:
+		}
+		generation.arrived--
+		return position, fmt.Errorf("barrier '%s' was not released: %d of %d users arrived", name, generation.arrived+1, c.required(generation))
+	}
+}
+
+// releaseLocked opens a barrier generation and starts a fresh one; c.mu must be held
+func (c *SyncCoordinator) releaseLocked(name string, generation *barrierGeneration) {
+	close(generation.release)
+	if c.barriers[name] == generation {
+		delete(c.barriers, name)
+	}
+}
+
+// Leave marks a user as finished, releasing barriers that were only waiting for users that left
+func (c *SyncCoordinator) Leave() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.active--
+	for name, generation := range c.barriers {
+		if generation.arrived >= c.required(generation) {
+			c.releaseLocked(name, generation)
+		}
+	}
+}
+
+// signalLocked returns the channel closed when name is signalled; c.mu must be held
+func (c *SyncCoordinator) signalLocked(name string) chan struct{} {
+	signal, exists := c.signals[name]
+	if !exists {
+		signal = make(chan struct{})
+		c.signals[name] = signal
+	}
+	return signal
+}
+
+// Signal raises the signal called name. Signals stay raised, so users that wait later pass at once.
+// It reports whether this call raised it.
+func (c *SyncCoordinator) Signal(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	signal := c.signalLocked(name)
+	select {
+	case <-signal:
+		return false
+	default:
+		close(signal)
+		return true
+	}
+}
+
+// Wait blocks until the signal called name is raised
+func (c *SyncCoordinator) Wait(ctx context.Context, name string) error {
+	c.mu.Lock()
+	signal := c.signalLocked(name)
+	c.mu.Unlock()
+
+	select {
+	case <-signal:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("signal '%s' was not raised", name)
+	}
+}
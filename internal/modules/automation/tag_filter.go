@@ -0,0 +1,114 @@
+package automation
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// TagsConfigKey is the step and action config field holding execution profile tags (e.g. smoke, destructive)
+const TagsConfigKey = "tags"
+
+// RunTagFilter selects the steps and actions a run executes, so one automation can serve
+// several execution profiles. An action's tags include the tags of its step.
+type RunTagFilter struct {
+	Include []string // when set, only actions with one of these tags run
+	Exclude []string // actions with any of these tags never run
+}
+
+// NewRunTagFilter normalizes the tags of a filter
+func NewRunTagFilter(include, exclude []string) RunTagFilter {
+	return RunTagFilter{Include: normalizeTags(include), Exclude: normalizeTags(exclude)}
+}
+
+// Active reports whether the filter skips anything
+func (f RunTagFilter) Active() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// Matches reports whether something with the given tags runs under the filter
+func (f RunTagFilter) Matches(tags []string) bool {
+	for _, tag := range tags {
+		if slices.Contains(f.Exclude, tag) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if slices.Contains(f.Include, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterActions returns the actions of a step tagged stepTags that run under the filter
+func (f RunTagFilter) FilterActions(stepTags []string, actions []*AutomationAction) []*AutomationAction {
+	var matching []*AutomationAction
+	for _, action := range actions {
+		if f.Matches(append(slices.Clone(stepTags), actionTags(action)...)) {
+			matching = append(matching, action)
+		}
+	}
+	return matching
+}
+
+// ConfigTags reads the tags of a step or action config, as an array or a comma-separated string
+func ConfigTags(config map[string]interface{}) []string {
+	var tags []string
+	switch value := config[TagsConfigKey].(type) {
+	case []interface{}:
+		for _, item := range value {
+			if tag, ok := item.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+	case string:
+		tags = strings.Split(value, ",")
+	}
+	return normalizeTags(tags)
+}
+
+// actionTags reads the tags of a stored action's config
+func actionTags(action *AutomationAction) []string {
+	var config map[string]interface{}
+	if action.ActionConfigJSON == "" || json.Unmarshal([]byte(action.ActionConfigJSON), &config) != nil {
+		return nil
+	}
+	return ConfigTags(config)
+}
+
+// executedTags returns the tags of the steps and actions that run under the filter, i.e. the tags
+// a run of them covers
+func executedTags(steps []*AutomationStep, actions []*AutomationAction, filter RunTagFilter) []string {
+	stepTags := make(map[string][]string, len(steps))
+	for _, step := range steps {
+		var config map[string]interface{}
+		if step.ConfigJSON != "" && json.Unmarshal([]byte(step.ConfigJSON), &config) == nil {
+			stepTags[step.ID] = ConfigTags(config)
+		}
+	}
+
+	var tags []string
+	for _, action := range actions {
+		combined := append(slices.Clone(stepTags[action.StepID]), actionTags(action)...)
+		if filter.Matches(combined) {
+			tags = append(tags, combined...)
+		}
+	}
+	return normalizeTags(tags)
+}
+
+// normalizeTags lowercases and trims tags, dropping empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,86 @@
+package automation
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// parseCACertificates decodes every PEM certificate in the configured CA bundles
+func parseCACertificates(bundles []string) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for i, bundle := range bundles {
+		rest := []byte(bundle)
+		found := false
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid certificate in CA bundle %d: %w", i+1, err)
+			}
+			certificates = append(certificates, certificate)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("CA bundle %d contains no PEM certificates", i+1)
+		}
+	}
+	return certificates, nil
+}
+
+// buildTLSClientConfig returns the TLS settings for API clients, or nil to use Go's defaults
+func buildTLSClientConfig(config TLSConfig) (*tls.Config, error) {
+	if !config.InsecureSkipVerify && len(config.CACertificates) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if len(config.CACertificates) > 0 {
+		certificates, err := parseCACertificates(config.CACertificates)
+		if err != nil {
+			return nil, err
+		}
+
+		// Extend the system roots rather than replacing them so public endpoints keep working
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		for _, certificate := range certificates {
+			rootCAs.AddCert(certificate)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return tlsConfig, nil
+}
+
+// chromiumTrustedSPKIFlag renders the custom CAs as a Chromium --ignore-certificate-errors-spki-list flag,
+// which accepts certificate chains containing any of the listed public keys
+func chromiumTrustedSPKIFlag(config TLSConfig) (string, error) {
+	certificates, err := parseCACertificates(config.CACertificates)
+	if err != nil {
+		return "", err
+	}
+
+	hashes := make([]string, len(certificates))
+	for i, certificate := range certificates {
+		sum := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+		hashes[i] = base64.StdEncoding.EncodeToString(sum[:])
+	}
+	return "--ignore-certificate-errors-spki-list=" + strings.Join(hashes, ","), nil
+}
@@ -0,0 +1,144 @@
+package automation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Translation catalog limits
+const (
+	MaxTranslationMessages      = 20000
+	maxTranslationMessageKeyLen = 255
+)
+
+// localePattern matches BCP 47 style tags such as en, pt-BR or zh-Hant-TW
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// messageParamPattern matches {name} placeholders in catalog messages
+var messageParamPattern = regexp.MustCompile(`\{([A-Za-z0-9_.]+)\}`)
+
+// NormalizeLocale checks a locale tag and returns it in canonical case, e.g. pt_br -> pt-BR
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if !localePattern.MatchString(locale) {
+		return "", fmt.Errorf("'%s' is not a valid locale; use a tag such as en, fr or pt-BR", locale)
+	}
+
+	parts := strings.Split(locale, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2: // region
+			parts[i] = strings.ToUpper(parts[i])
+		case 4: // script
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// ValidateTranslationCatalog normalizes a catalog's locale and checks its messages
+func ValidateTranslationCatalog(catalog *TranslationCatalog) error {
+	locale, err := NormalizeLocale(catalog.Locale)
+	if err != nil {
+		return err
+	}
+	catalog.Locale = locale
+
+	if len(catalog.Messages) == 0 {
+		return fmt.Errorf("messages must contain at least one message")
+	}
+	if len(catalog.Messages) > MaxTranslationMessages {
+		return fmt.Errorf("messages may contain at most %d messages", MaxTranslationMessages)
+	}
+	for key := range catalog.Messages {
+		if strings.TrimSpace(key) == "" || len(key) > maxTranslationMessageKeyLen {
+			return fmt.Errorf("message keys must be between 1 and %d characters", maxTranslationMessageKeyLen)
+		}
+	}
+	catalog.MessageCount = len(catalog.Messages)
+	return nil
+}
+
+// FlattenTranslationMessages turns an uploaded catalog, flat or nested like most i18n
+// libraries' JSON files, into message keys joined with dots: {"nav": {"home": "Home"}} becomes
+// nav.home. Numbers and booleans are kept as their text; lists are rejected.
+func FlattenTranslationMessages(raw map[string]interface{}) (map[string]string, error) {
+	messages := make(map[string]string)
+	if err := flattenTranslationMessages("", raw, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func flattenTranslationMessages(prefix string, raw map[string]interface{}, messages map[string]string) error {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case string:
+			messages[key] = v
+		case float64:
+			messages[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			messages[key] = strconv.FormatBool(v)
+		case map[string]interface{}:
+			if err := flattenTranslationMessages(key, v, messages); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message '%s' must be text or an object of messages", key)
+		}
+	}
+	return nil
+}
+
+// localeFallbacks returns the locales a message is looked up in, most specific first:
+// pt-BR falls back to pt
+func localeFallbacks(locale string) []string {
+	var locales []string
+	for {
+		locales = append(locales, locale)
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			return locales
+		}
+		locale = locale[:i]
+	}
+}
+
+// FormatMessage replaces {name} placeholders in a catalog message with params. Placeholders
+// without a param are left as they are, so a missing param shows up in the failed assertion.
+func FormatMessage(message string, params map[string]string) string {
+	if len(params) == 0 {
+		return message
+	}
+	return messageParamPattern.ReplaceAllStringFunc(message, func(match string) string {
+		if value, ok := params[match[1:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// runLocale returns the locale a user of a run resolves message keys in: the run's locale when
+// it was triggered with one, otherwise users take turns through the automation's locales
+func runLocale(run *AutomationRun, automationConfig *AutomationConfig, loopIndex int) string {
+	if run.Locale != "" {
+		return run.Locale
+	}
+	if len(automationConfig.Locales) == 0 {
+		return ""
+	}
+	locale := automationConfig.Locales[loopIndex%len(automationConfig.Locales)]
+	if normalized, err := NormalizeLocale(locale); err == nil {
+		return normalized
+	}
+	// Kept as configured, so lookups fail naming the locale instead of asking for one
+	return locale
+}
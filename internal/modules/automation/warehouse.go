@@ -0,0 +1,258 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Warehouse table names, shared by every sink
+const (
+	warehouseRunsTable        = "qplayground_runs"
+	warehouseStepMetricsTable = "qplayground_step_metrics"
+)
+
+// WarehouseRunRecord is the summary of a finished run exported to a data warehouse
+type WarehouseRunRecord struct {
+	RunID           string     `json:"run_id"`
+	AutomationID    string     `json:"automation_id"`
+	AutomationName  string     `json:"automation_name"`
+	ProjectID       string     `json:"project_id"`
+	Status          string     `json:"status"`
+	StartTime       *time.Time `json:"start_time"`
+	EndTime         *time.Time `json:"end_time"`
+	DurationMs      int64      `json:"duration_ms"`
+	ErrorMessage    string     `json:"error_message"`
+	LogCount        int        `json:"log_count"`
+	ErrorCount      int        `json:"error_count"`
+	OutputFileCount int        `json:"output_file_count"`
+	ExportedAt      time.Time  `json:"exported_at"`
+}
+
+// WarehouseStepMetric aggregates the actions of one step in one loop of a run
+type WarehouseStepMetric struct {
+	RunID        string `json:"run_id"`
+	AutomationID string `json:"automation_id"`
+	ProjectID    string `json:"project_id"`
+	StepID       string `json:"step_id"`
+	StepName     string `json:"step_name"`
+	LoopIndex    int    `json:"loop_index"`
+	ActionCount  int    `json:"action_count"`
+	ErrorCount   int    `json:"error_count"`
+	DurationMs   int64  `json:"duration_ms"`
+}
+
+// WarehouseSink writes run summaries and step metrics to an external data warehouse
+type WarehouseSink interface {
+	Name() string
+	WriteRun(ctx context.Context, run WarehouseRunRecord, steps []WarehouseStepMetric) error
+	Close() error
+}
+
+// NewWarehouseSink creates the sink for a kind ("bigquery", "clickhouse" or "postgres").
+// dsn and dataset are interpreted per sink: see each sink's constructor.
+func NewWarehouseSink(ctx context.Context, kind, dsn, dataset string) (WarehouseSink, error) {
+	switch strings.ToLower(kind) {
+	case "bigquery":
+		return NewBigQuerySink(ctx, dataset)
+	case "clickhouse":
+		return NewClickHouseSink(dsn, dataset)
+	case "postgres":
+		return NewPostgresWarehouseSink(ctx, dsn, dataset)
+	default:
+		return nil, fmt.Errorf("unsupported warehouse sink: %s", kind)
+	}
+}
+
+// finishedRunJob is a finished run queued for background processing
+type finishedRunJob struct {
+	projectID string
+	run       AutomationRun
+}
+
+// WarehouseExporter streams finished runs to a WarehouseSink in the background so exports
+// never delay run completion. Failed writes are retried with backoff and then dropped.
+type WarehouseExporter struct {
+	automationRepo AutomationRepository
+	sink           WarehouseSink
+	jobs           chan finishedRunJob
+	maxAttempts    int
+	stopCh         chan struct{}
+	doneCh         chan struct{}
+}
+
+// NewWarehouseExporter creates an exporter writing to sink
+func NewWarehouseExporter(automationRepo AutomationRepository, sink WarehouseSink) *WarehouseExporter {
+	return &WarehouseExporter{
+		automationRepo: automationRepo,
+		sink:           sink,
+		jobs:           make(chan finishedRunJob, 256),
+		maxAttempts:    5,
+		stopCh:         make(chan struct{}),
+		doneCh:         make(chan struct{}),
+	}
+}
+
+// Start begins exporting queued runs
+func (e *WarehouseExporter) Start(ctx context.Context) {
+	slog.Info("Warehouse exporter started", "sink", e.sink.Name())
+
+	go func() {
+		defer close(e.doneCh)
+
+		for {
+			select {
+			case job := <-e.jobs:
+				e.export(ctx, job)
+			case <-e.stopCh:
+				// Flush what is already queued before shutting down
+				for {
+					select {
+					case job := <-e.jobs:
+						e.export(ctx, job)
+					default:
+						slog.Info("Warehouse exporter stopped")
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop flushes queued exports and closes the sink
+func (e *WarehouseExporter) Stop() {
+	close(e.stopCh)
+	<-e.doneCh
+	if err := e.sink.Close(); err != nil {
+		slog.Error("Failed to close warehouse sink", "sink", e.sink.Name(), "error", err)
+	}
+}
+
+// Enqueue queues a finished run for export. The run is copied, so callers may keep using it.
+func (e *WarehouseExporter) Enqueue(projectID string, run *AutomationRun) {
+	select {
+	case e.jobs <- finishedRunJob{projectID: projectID, run: *run}:
+	default:
+		slog.Warn("Warehouse export queue full, dropping run", "run_id", run.ID)
+	}
+}
+
+func (e *WarehouseExporter) export(ctx context.Context, job finishedRunJob) {
+	automationName := ""
+	if automation, err := e.automationRepo.GetAutomationByID(ctx, job.run.AutomationID); err == nil {
+		automationName = automation.Name
+	}
+
+	record, steps := buildWarehouseRecords(job.projectID, automationName, &job.run)
+
+	backoff := 2 * time.Second
+	for attempt := 1; attempt <= e.maxAttempts; attempt++ {
+		err := e.sink.WriteRun(ctx, record, steps)
+		if err == nil {
+			return
+		}
+		if attempt == e.maxAttempts {
+			slog.Error("Failed to export run to warehouse", "sink", e.sink.Name(), "run_id", job.run.ID, "attempts", attempt, "error", err)
+			return
+		}
+
+		slog.Warn("Warehouse export failed, retrying", "sink", e.sink.Name(), "run_id", job.run.ID, "attempt", attempt, "error", err)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// buildWarehouseRecords derives the run summary and per-step metrics from a run's logs
+func buildWarehouseRecords(projectID, automationName string, run *AutomationRun) (WarehouseRunRecord, []WarehouseStepMetric) {
+	var logs []map[string]any
+	if run.LogsJSON != "" {
+		json.Unmarshal([]byte(run.LogsJSON), &logs)
+	}
+
+	record := WarehouseRunRecord{
+		RunID:           run.ID,
+		AutomationID:    run.AutomationID,
+		AutomationName:  automationName,
+		ProjectID:       projectID,
+		Status:          run.Status,
+		StartTime:       run.StartTime,
+		EndTime:         run.EndTime,
+		ErrorMessage:    run.ErrorMessage,
+		LogCount:        len(logs),
+		OutputFileCount: len(run.OutputFiles()),
+		ExportedAt:      time.Now().UTC(),
+	}
+	if run.StartTime != nil && run.EndTime != nil {
+		record.DurationMs = run.EndTime.Sub(*run.StartTime).Milliseconds()
+	}
+
+	type stepKey struct {
+		stepID    string
+		loopIndex int
+	}
+	metrics := make(map[stepKey]*WarehouseStepMetric)
+
+	for _, entry := range logs {
+		status, _ := entry["status"].(string)
+		if status == "failed" {
+			record.ErrorCount++
+		}
+		// Output file and warning entries don't represent action executions
+		if _, isOutputFile := entry["output_file"]; isOutputFile || status == "warning" {
+			continue
+		}
+
+		stepID, _ := entry["step_id"].(string)
+		if stepID == "" {
+			continue
+		}
+		loopIndex, _ := entry["loop_index"].(float64)
+		key := stepKey{stepID: stepID, loopIndex: int(loopIndex)}
+
+		metric, exists := metrics[key]
+		if !exists {
+			stepName, _ := entry["step_name"].(string)
+			metric = &WarehouseStepMetric{
+				RunID:        run.ID,
+				AutomationID: run.AutomationID,
+				ProjectID:    projectID,
+				StepID:       stepID,
+				StepName:     stepName,
+				LoopIndex:    key.loopIndex,
+			}
+			metrics[key] = metric
+		}
+
+		metric.ActionCount++
+		if status == "failed" {
+			metric.ErrorCount++
+		}
+		if duration, ok := entry["duration_ms"].(float64); ok {
+			metric.DurationMs += int64(duration)
+		}
+	}
+
+	steps := make([]WarehouseStepMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		steps = append(steps, *metric)
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		if steps[i].LoopIndex != steps[j].LoopIndex {
+			return steps[i].LoopIndex < steps[j].LoopIndex
+		}
+		return steps[i].StepID < steps[j].StepID
+	})
+
+	return record, steps
+}
@@ -0,0 +1,103 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2/google"
+)
+
+const bigQueryScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+
+// BigQuerySink streams rows into BigQuery through the tabledata.insertAll API.
+// The qplayground_runs and qplayground_step_metrics tables must already exist in the dataset.
+type BigQuerySink struct {
+	client    *http.Client
+	projectID string
+	datasetID string
+}
+
+// NewBigQuerySink creates a sink for dataset, given as "project.dataset".
+// Credentials come from Google application default credentials.
+func NewBigQuerySink(ctx context.Context, dataset string) (*BigQuerySink, error) {
+	projectID, datasetID, ok := strings.Cut(dataset, ".")
+	if !ok || projectID == "" || datasetID == "" {
+		return nil, fmt.Errorf("bigquery dataset must be in the form 'project.dataset'")
+	}
+
+	client, err := google.DefaultClient(ctx, bigQueryScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load google credentials: %w", err)
+	}
+
+	return &BigQuerySink{client: client, projectID: projectID, datasetID: datasetID}, nil
+}
+
+func (s *BigQuerySink) Name() string {
+	return "bigquery"
+}
+
+func (s *BigQuerySink) WriteRun(ctx context.Context, run WarehouseRunRecord, steps []WarehouseStepMetric) error {
+	// insertId makes retried inserts idempotent within BigQuery's deduplication window
+	if err := s.insertRows(ctx, warehouseRunsTable, []bigQueryRow{{InsertID: run.RunID, JSON: run}}); err != nil {
+		return err
+	}
+
+	if len(steps) == 0 {
+		return nil
+	}
+	rows := make([]bigQueryRow, len(steps))
+	for i, step := range steps {
+		rows[i] = bigQueryRow{InsertID: run.RunID + ":" + step.StepID + ":" + strconv.Itoa(step.LoopIndex), JSON: step}
+	}
+	return s.insertRows(ctx, warehouseStepMetricsTable, rows)
+}
+
+func (s *BigQuerySink) Close() error {
+	return nil
+}
+
+type bigQueryRow struct {
+	InsertID string `json:"insertId"`
+	JSON     any    `json:"json"`
+}
+
+func (s *BigQuerySink) insertRows(ctx context.Context, table string, rows []bigQueryRow) error {
+	body, err := json.Marshal(map[string]any{"rows": rows})
+	if err != nil {
+		return fmt.Errorf("failed to marshal rows: %w", err)
+	}
+
+	url := fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll", s.projectID, s.datasetID, table)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to insert rows into %s: %w", table, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bigquery insert into %s returned status %d: %s", table, resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		InsertErrors []json.RawMessage `json:"insertErrors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err == nil && len(result.InsertErrors) > 0 {
+		return fmt.Errorf("bigquery rejected %d rows for %s: %s", len(result.InsertErrors), table, string(result.InsertErrors[0]))
+	}
+
+	return nil
+}
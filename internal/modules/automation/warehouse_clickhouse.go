@@ -0,0 +1,146 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// clickHouseSchema creates the warehouse tables; %s is the database
+var clickHouseSchema = []string{
+	`CREATE TABLE IF NOT EXISTS %s.qplayground_runs (
+		run_id String,
+		automation_id String,
+		automation_name String,
+		project_id String,
+		status LowCardinality(String),
+		start_time Nullable(DateTime64(3)),
+		end_time Nullable(DateTime64(3)),
+		duration_ms Int64,
+		error_message String,
+		log_count UInt32,
+		error_count UInt32,
+		output_file_count UInt32,
+		exported_at DateTime64(3)
+	) ENGINE = ReplacingMergeTree(exported_at) ORDER BY (project_id, automation_id, run_id)`,
+	`CREATE TABLE IF NOT EXISTS %s.qplayground_step_metrics (
+		run_id String,
+		automation_id String,
+		project_id String,
+		step_id String,
+		step_name String,
+		loop_index UInt32,
+		action_count UInt32,
+		error_count UInt32,
+		duration_ms Int64
+	) ENGINE = ReplacingMergeTree ORDER BY (project_id, automation_id, run_id, step_id, loop_index)`,
+}
+
+// ClickHouseSink writes rows through ClickHouse's HTTP interface using JSONEachRow inserts.
+// ReplacingMergeTree tables make retried inserts collapse into a single row.
+type ClickHouseSink struct {
+	client      *http.Client
+	endpoint    string
+	database    string
+	schemaReady bool
+}
+
+// NewClickHouseSink creates a sink for the HTTP endpoint dsn (credentials may be given as URL
+// user info) writing into database, which defaults to "default". Tables are created on first write.
+func NewClickHouseSink(dsn, database string) (*ClickHouseSink, error) {
+	if _, err := url.Parse(dsn); err != nil || dsn == "" {
+		return nil, fmt.Errorf("invalid clickhouse url: %q", dsn)
+	}
+	if database == "" {
+		database = "default"
+	}
+
+	return &ClickHouseSink{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		endpoint: dsn,
+		database: database,
+	}, nil
+}
+
+func (s *ClickHouseSink) Name() string {
+	return "clickhouse"
+}
+
+func (s *ClickHouseSink) WriteRun(ctx context.Context, run WarehouseRunRecord, steps []WarehouseStepMetric) error {
+	if !s.schemaReady {
+		for _, statement := range clickHouseSchema {
+			if err := s.exec(ctx, fmt.Sprintf(statement, s.database), nil); err != nil {
+				return err
+			}
+		}
+		s.schemaReady = true
+	}
+
+	if err := s.insert(ctx, warehouseRunsTable, []any{run}); err != nil {
+		return err
+	}
+
+	if len(steps) == 0 {
+		return nil
+	}
+	rows := make([]any, len(steps))
+	for i, step := range steps {
+		rows[i] = step
+	}
+	return s.insert(ctx, warehouseStepMetricsTable, rows)
+}
+
+func (s *ClickHouseSink) Close() error {
+	return nil
+}
+
+func (s *ClickHouseSink) insert(ctx context.Context, table string, rows []any) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return fmt.Errorf("failed to marshal row: %w", err)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", s.database, table)
+	return s.exec(ctx, query, &body)
+}
+
+// exec runs a query, with body as the insert payload when given
+func (s *ClickHouseSink) exec(ctx context.Context, query string, body io.Reader) error {
+	endpoint, err := url.Parse(s.endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid clickhouse url: %w", err)
+	}
+	params := endpoint.Query()
+	params.Set("query", query)
+	// RFC 3339 timestamps from encoding/json need the lenient parser
+	params.Set("date_time_input_format", "best_effort")
+	endpoint.RawQuery = params.Encode()
+
+	if body == nil {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute clickhouse query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("clickhouse returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
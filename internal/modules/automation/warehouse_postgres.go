@@ -0,0 +1,125 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresWarehouseSchema creates the warehouse tables; %[1]s is the quoted schema
+var postgresWarehouseSchema = []string{
+	`CREATE SCHEMA IF NOT EXISTS %[1]s`,
+	`CREATE TABLE IF NOT EXISTS %[1]s.qplayground_runs (
+		run_id text PRIMARY KEY,
+		automation_id text NOT NULL,
+		automation_name text NOT NULL,
+		project_id text NOT NULL,
+		status text NOT NULL,
+		start_time timestamptz,
+		end_time timestamptz,
+		duration_ms bigint NOT NULL,
+		error_message text NOT NULL,
+		log_count integer NOT NULL,
+		error_count integer NOT NULL,
+		output_file_count integer NOT NULL,
+		exported_at timestamptz NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS %[1]s.qplayground_step_metrics (
+		run_id text NOT NULL,
+		automation_id text NOT NULL,
+		project_id text NOT NULL,
+		step_id text NOT NULL,
+		step_name text NOT NULL,
+		loop_index integer NOT NULL,
+		action_count integer NOT NULL,
+		error_count integer NOT NULL,
+		duration_ms bigint NOT NULL,
+		PRIMARY KEY (run_id, step_id, loop_index)
+	)`,
+}
+
+// PostgresWarehouseSink writes into a schema of a separate Postgres database, such as a
+// foreign schema exposed by an analytics warehouse. Writes are upserts, so retries are safe.
+type PostgresWarehouseSink struct {
+	pool   *pgxpool.Pool
+	schema string
+}
+
+// NewPostgresWarehouseSink connects to dsn and creates the warehouse tables in schema,
+// which defaults to "qplayground_analytics"
+func NewPostgresWarehouseSink(ctx context.Context, dsn, schema string) (*PostgresWarehouseSink, error) {
+	if schema == "" {
+		schema = "qplayground_analytics"
+	}
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to warehouse database: %w", err)
+	}
+
+	sink := &PostgresWarehouseSink{pool: pool, schema: pgx.Identifier{schema}.Sanitize()}
+	for _, statement := range postgresWarehouseSchema {
+		if _, err := pool.Exec(ctx, fmt.Sprintf(statement, sink.schema)); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to create warehouse tables: %w", err)
+		}
+	}
+
+	return sink, nil
+}
+
+func (s *PostgresWarehouseSink) Name() string {
+	return "postgres"
+}
+
+func (s *PostgresWarehouseSink) WriteRun(ctx context.Context, run WarehouseRunRecord, steps []WarehouseStepMetric) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %s.qplayground_runs (run_id, automation_id, automation_name, project_id, status, start_time, end_time,
+			duration_ms, error_message, log_count, error_count, output_file_count, exported_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (run_id) DO UPDATE SET
+			automation_name = EXCLUDED.automation_name, status = EXCLUDED.status, start_time = EXCLUDED.start_time,
+			end_time = EXCLUDED.end_time, duration_ms = EXCLUDED.duration_ms, error_message = EXCLUDED.error_message,
+			log_count = EXCLUDED.log_count, error_count = EXCLUDED.error_count,
+			output_file_count = EXCLUDED.output_file_count, exported_at = EXCLUDED.exported_at`, s.schema),
+		run.RunID, run.AutomationID, run.AutomationName, run.ProjectID, run.Status, run.StartTime, run.EndTime,
+		run.DurationMs, run.ErrorMessage, run.LogCount, run.ErrorCount, run.OutputFileCount, run.ExportedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write run summary: %w", err)
+	}
+
+	for _, step := range steps {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO %s.qplayground_step_metrics (run_id, automation_id, project_id, step_id, step_name, loop_index,
+				action_count, error_count, duration_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (run_id, step_id, loop_index) DO UPDATE SET
+				step_name = EXCLUDED.step_name, action_count = EXCLUDED.action_count,
+				error_count = EXCLUDED.error_count, duration_ms = EXCLUDED.duration_ms`, s.schema),
+			step.RunID, step.AutomationID, step.ProjectID, step.StepID, step.StepName, step.LoopIndex,
+			step.ActionCount, step.ErrorCount, step.DurationMs,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write step metric: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresWarehouseSink) Close() error {
+	s.pool.Close()
+	return nil
+}
@@ -0,0 +1,258 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform"
+)
+
+// Workers re-register their capabilities every workerRegistrationInterval, independently of the
+// scheduler's other work; a worker that stops doing so is considered gone once its registration
+// expires, several missed registrations later
+const (
+	workerRegistrationInterval = 10 * time.Second
+	workerRegistrationTTL      = 1 * time.Minute
+)
+
+// supportedBrowsers are the browser engines runs can launch
+var supportedBrowsers = []string{"chromium", "firefox", "webkit"}
+
+// WorkerCapabilities are what a worker advertises to have runs routed to it
+type WorkerCapabilities struct {
+	ID         string    `json:"id"`
+	Region     string    `json:"region,omitempty"`
+	GPU        bool      `json:"gpu"`
+	Browsers   []string  `json:"browsers"`
+	Plugins    []string  `json:"plugins"` // action namespaces it runs, e.g. playwright, api
+	StartedAt  time.Time `json:"started_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// RunRequirements are the worker capabilities an automation asks for beyond those its browser
+// and actions imply
+type RunRequirements struct {
+	Region string `json:"region,omitempty"`
+	GPU    bool   `json:"gpu,omitempty"`
+}
+
+// WorkerRequirements are everything a worker needs to run an automation
+type WorkerRequirements struct {
+	Browser string   `json:"browser"`
+	Region  string   `json:"region,omitempty"`
+	GPU     bool     `json:"gpu,omitempty"`
+	Plugins []string `json:"plugins"`
+}
+
+// WorkerMatch is whether a worker can run an automation, and what it lacks when it can't
+type WorkerMatch struct {
+	Worker  *WorkerCapabilities `json:"worker"`
+	Missing []string            `json:"missing"`
+}
+
+// Missing lists the requirements the worker doesn't meet, empty when it can run the automation
+func (c *WorkerCapabilities) Missing(requirements *WorkerRequirements) []string {
+	missing := []string{}
+	if !slices.Contains(c.Browsers, requirements.Browser) {
+		missing = append(missing, "browser "+requirements.Browser)
+	}
+	if requirements.Region != "" && c.Region != requirements.Region {
+		missing = append(missing, "region "+requirements.Region)
+	}
+	if requirements.GPU && !c.GPU {
+		missing = append(missing, "GPU")
+	}
+	for _, plugin := range requirements.Plugins {
+		if !slices.Contains(c.Plugins, plugin) {
+			missing = append(missing, "plugin "+plugin)
+		}
+	}
+	return missing
+}
+
+// DetectWorkerCapabilities builds the capabilities of this process from its environment, the
+// browsers installed for Playwright and the registered actions. store actions are only enabled
+// with a key-value store.
+func DetectWorkerCapabilities(kvStoreEnabled bool) *WorkerCapabilities {
+	id := platform.ENV_WORKER_ID
+	if id == "" {
+		hostname, _ := os.Hostname()
+		id = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	browsers := splitList(platform.ENV_WORKER_BROWSERS)
+	if len(browsers) == 0 {
+		browsers = detectInstalledBrowsers()
+	}
+	if len(browsers) == 0 {
+		slog.Warn("Could not detect installed browsers, advertising all of them; set WORKER_BROWSERS to be explicit")
+		browsers = supportedBrowsers
+	}
+
+	disabled := splitList(platform.ENV_WORKER_DISABLED_PLUGINS)
+	if !kvStoreEnabled {
+		disabled = append(disabled, "store")
+	}
+	plugins := []string{}
+	for _, actionType := range RegisteredActionTypes() {
+		plugin := actionPlugin(actionType)
+		if !slices.Contains(plugins, plugin) && !slices.Contains(disabled, plugin) {
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	now := time.Now()
+	return &WorkerCapabilities{
+		ID:        id,
+		Region:    platform.ENV_WORKER_REGION,
+		GPU:       platform.ENV_WORKER_GPU,
+		Browsers:  browsers,
+		Plugins:   plugins,
+		StartedAt: now,
+	}
+}
+
+// detectInstalledBrowsers lists the browsers installed in the Playwright browsers directory
+func detectInstalledBrowsers() []string {
+	dir := os.Getenv("PLAYWRIGHT_BROWSERS_PATH")
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil
+		}
+		dir = filepath.Join(cacheDir, "ms-playwright")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var browsers []string
+	for _, browser := range supportedBrowsers {
+		for _, entry := range entries {
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), browser+"-") {
+				browsers = append(browsers, browser)
+				break
+			}
+		}
+	}
+	return browsers
+}
+
+// actionPlugin is the namespace of an action type, e.g. playwright for playwright:click
+func actionPlugin(actionType string) string {
+	plugin, _, _ := strings.Cut(actionType, ":")
+	return plugin
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// workerRequirements derives what a worker needs to run an automation from its config and the
+// actions of its steps, nested ones included
+func workerRequirements(ctx context.Context, repo AutomationRepository, automation *Automation) (*WorkerRequirements, error) {
+	var config AutomationConfig
+	if automation.ConfigJSON != "" {
+		json.Unmarshal([]byte(automation.ConfigJSON), &config)
+	}
+
+	requirements := &WorkerRequirements{
+		Browser: config.Browser,
+		Region:  config.Requirements.Region,
+		GPU:     config.Requirements.GPU,
+		Plugins: []string{},
+	}
+	if requirements.Browser == "" {
+		requirements.Browser = "chromium"
+	}
+
+	actions, err := repo.GetActionsByAutomationID(ctx, automation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions: %w", err)
+	}
+	for _, action := range actions {
+		var actionConfig map[string]interface{}
+		json.Unmarshal([]byte(action.ActionConfigJSON), &actionConfig)
+		for _, actionType := range append([]string{action.ActionType}, nestedActionTypes(actionConfig)...) {
+			if plugin := actionPlugin(actionType); !slices.Contains(requirements.Plugins, plugin) {
+				requirements.Plugins = append(requirements.Plugins, plugin)
+			}
+		}
+	}
+	sort.Strings(requirements.Plugins)
+	return requirements, nil
+}
+
+// matchWorkers checks each live worker against requirements
+func matchWorkers(workers []*WorkerCapabilities, requirements *WorkerRequirements) []WorkerMatch {
+	matches := make([]WorkerMatch, 0, len(workers))
+	for _, worker := range workers {
+		matches = append(matches, WorkerMatch{Worker: worker, Missing: worker.Missing(requirements)})
+	}
+	return matches
+}
+
+// noMatchingWorkerError explains why none of the live workers can run an automation
+func noMatchingWorkerError(matches []WorkerMatch) error {
+	if len(matches) == 0 {
+		return fmt.Errorf("no worker is online to run this automation")
+	}
+	reasons := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if len(match.Missing) == 0 {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%s lacks %s", match.Worker.ID, strings.Join(match.Missing, ", ")))
+	}
+	return fmt.Errorf("no worker can run this automation: %s", strings.Join(reasons, "; "))
+}
+
+// GetWorkerMatches returns what a worker needs to run an automation and how each live worker
+// measures up
+func (s *automationService) GetWorkerMatches(ctx context.Context, automationID string) (*WorkerRequirements, []WorkerMatch, error) {
+	automation, err := s.automationRepo.GetAutomationByID(ctx, automationID)
+	if err != nil {
+		slog.Error("Failed to get automation for worker matching", "error", err, "automationID", automationID)
+		return nil, nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	requirements, err := workerRequirements(ctx, s.automationRepo, automation)
+	if err != nil {
+		slog.Error("Failed to get worker requirements", "error", err, "automationID", automationID)
+		return nil, nil, err
+	}
+	workers, err := s.runCache.GetWorkers(ctx)
+	if err != nil {
+		slog.Error("Failed to get workers", "error", err)
+		return nil, nil, fmt.Errorf("failed to get workers: %w", err)
+	}
+	return requirements, matchWorkers(workers, requirements), nil
+}
+
+// checkWorkerAvailable fails fast when no live worker can run an automation. Runs are let
+// through when the workers can't be listed.
+func (s *automationService) checkWorkerAvailable(ctx context.Context, automationID string) error {
+	_, matches, err := s.GetWorkerMatches(ctx, automationID)
+	if err != nil {
+		slog.Warn("Failed to match workers, proceeding anyway", "error", err, "automationID", automationID)
+		return nil
+	}
+	if err := noMatchingWorkerError(matches); err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	return nil
+}
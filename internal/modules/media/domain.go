@@ -51,4 +51,6 @@ type ImageInfo struct {
 type MediaService interface {
 	ProcessImage(ctx context.Context, input io.Reader, options *ImageProcessOptions) (*ProcessedImage, error)
 	ValidateImage(ctx context.Context, input io.Reader) (*ImageInfo, error)
+	// RenderPNG rasterizes an SVG document, such as a generated summary card, at its own size
+	RenderPNG(ctx context.Context, svg []byte) ([]byte, error)
 }
\ No newline at end of file
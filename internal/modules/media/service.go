@@ -1,6 +1,7 @@
 package media
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -73,4 +74,17 @@ func (s *mediaService) ValidateImage(ctx context.Context, input io.Reader) (*Ima
 	)
 
 	return info, nil
-}
\ No newline at end of file
+}
+func (s *mediaService) RenderPNG(ctx context.Context, svg []byte) ([]byte, error) {
+	// libvips loads SVG input like any other image format
+	result, err := s.processor.Process(ctx, bytes.NewReader(svg), &ImageProcessOptions{
+		Quality: 100,
+		Format:  PNG,
+	})
+	if err != nil {
+		slog.Error("Failed to render SVG", "error", err)
+		return nil, fmt.Errorf("failed to render SVG: %w", err)
+	}
+
+	return result.Data, nil
+}
@@ -54,12 +54,15 @@ type NotificationMessage struct {
 	ProjectID      string
 	ProjectName    string
 	RunID          string
-	Status         string // "completed", "failed"
+	Status         string // "completed", "failed", "anomaly", "stalled"
 	StartTime      *time.Time
 	EndTime        *time.Time
 	ErrorMessage   string
 	OutputFiles    []string
 	LogsCount      int
+	Locale         string // language of the notification, English when empty
+	ReportURL      string // PDF report of the run, when one was generated
+	SavedView      string // saved run view whose subscription the run matched, if sent for one
 }
 
 // NotificationChannelConfig represents a notification channel configuration
@@ -68,6 +71,7 @@ type NotificationChannelConfig struct {
 	Type       string                 `json:"type"` // "slack", "email", "webhook"
 	OnComplete bool                   `json:"onComplete"`
 	OnError    bool                   `json:"onError"`
+	OnAnomaly  bool                   `json:"onAnomaly"`
 	Config     map[string]interface{} `json:"config"`
 }
 
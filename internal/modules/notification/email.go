@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
+)
+
+// EmailNotifier implements ChannelNotifier by mailing run notifications
+type EmailNotifier struct {
+	send func(ctx context.Context, mailData MailData) error
+}
+
+// NewEmailNotifier creates an EmailNotifier sending mail with send
+func NewEmailNotifier(send func(ctx context.Context, mailData MailData) error) ChannelNotifier {
+	return &EmailNotifier{send: send}
+}
+
+// markup strips the Slack formatting of notification messages
+var markup = strings.NewReplacer("*", "", "`", "")
+
+// Send mails a notification to the comma-separated addresses in the channel's "to"
+func (e *EmailNotifier) Send(ctx context.Context, message NotificationMessage, channelConfig map[string]interface{}) error {
+	to, _ := channelConfig["to"].(string)
+	var recipients []string
+	for _, address := range strings.Split(to, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("email recipient is required")
+	}
+
+	l := i18n.For(message.Locale)
+	var subject string
+	switch message.Status {
+	case "completed", "failed", "anomaly", "stalled":
+		subject = l.T("notification."+message.Status, message.AutomationName)
+	default:
+		subject = l.T("notification.finished", message.AutomationName, l.T("status."+message.Status))
+	}
+	subject = markup.Replace(subject)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "<html>\n<body>\n<h2>%s</h2>\n<table>\n", html.EscapeString(subject))
+	row := func(title, value string) {
+		fmt.Fprintf(&body, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\n", html.EscapeString(title), value)
+	}
+	if message.ProjectName != "" {
+		row(l.T("report.project"), html.EscapeString(message.ProjectName))
+	}
+	row(l.T("report.run_id"), html.EscapeString(message.RunID))
+	if message.SavedView != "" {
+		row(l.T("report.saved_view"), html.EscapeString(message.SavedView))
+	}
+	if message.StartTime != nil && message.EndTime != nil {
+		row(l.T("report.duration"), message.EndTime.Sub(*message.StartTime).Round(time.Millisecond).String())
+	}
+	if message.ReportURL != "" {
+		row(l.T("report.pdf"), fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(message.ReportURL), html.EscapeString(l.T("report.download"))))
+	}
+	if message.ErrorMessage != "" {
+		row(l.T("report.error"), "<pre>"+html.EscapeString(message.ErrorMessage)+"</pre>")
+	}
+	body.WriteString("</table>\n</body>\n</html>\n")
+
+	var errs []error
+	for _, recipient := range recipients {
+		if err := e.send(ctx, MailData{To: recipient, Subject: subject, Content: body.String()}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
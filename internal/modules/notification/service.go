@@ -20,10 +20,11 @@ type MailService struct {
 	
 	// Notification channel implementations
 	slackNotifier ChannelNotifier
+	emailNotifier ChannelNotifier
 }
 
 func NewMailService() *MailService {
-	return &MailService{
+	s := &MailService{
 		host:     platform.ENV_SMTP_HOST,
 		port:     platform.ENV_SMTP_PORT,
 		username: platform.ENV_SMTP_USERNAME,
@@ -32,6 +33,8 @@ func NewMailService() *MailService {
 		
 		slackNotifier: NewSlackNotifier(),
 	}
+	s.emailNotifier = NewEmailNotifier(s.SendMail)
+	return s
 }
 
 func (s *MailService) SendMail(ctx context.Context, m MailData) error {
@@ -127,7 +130,9 @@ func (s *MailService) DispatchAutomationNotification(ctx context.Context, messag
 		shouldSend := false
 		if message.Status == "completed" && channel.OnComplete {
 			shouldSend = true
-		} else if message.Status == "failed" && channel.OnError {
+		} else if (message.Status == "failed" || message.Status == "stalled") && channel.OnError {
+			shouldSend = true
+		} else if message.Status == "anomaly" && channel.OnAnomaly {
 			shouldSend = true
 		}
 
@@ -141,9 +146,7 @@ func (s *MailService) DispatchAutomationNotification(ctx context.Context, messag
 		case "slack":
 			err = s.slackNotifier.Send(ctx, message, channel.Config)
 		case "email":
-			// TODO: Implement email notifications
-			slog.Warn("Email notifications not yet implemented", "channel_id", channel.ID)
-			continue
+			err = s.emailNotifier.Send(ctx, message, channel.Config)
 		case "webhook":
 			// TODO: Implement generic webhook notifications
 			slog.Warn("Generic webhook notifications not yet implemented", "channel_id", channel.ID)
@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/delordemm1/qplayground/internal/platform/i18n"
 )
 
 // SlackNotifier implements ChannelNotifier for Slack incoming webhooks
@@ -101,42 +103,60 @@ func (s *SlackNotifier) buildSlackMessage(message NotificationMessage, channelCo
 
 	channel, _ := channelConfig["channel"].(string)
 
+	l := i18n.For(message.Locale)
+
 	// Determine color and main text based on status
 	var color, statusEmoji, mainText string
 	switch message.Status {
 	case "completed":
 		color = "good"
 		statusEmoji = ":white_check_mark:"
-		mainText = fmt.Sprintf("%s Automation *%s* completed successfully!", statusEmoji, message.AutomationName)
+		mainText = statusEmoji + " " + l.T("notification.completed", message.AutomationName)
 	case "failed":
 		color = "danger"
 		statusEmoji = ":x:"
-		mainText = fmt.Sprintf("%s Automation *%s* failed!", statusEmoji, message.AutomationName)
+		mainText = statusEmoji + " " + l.T("notification.failed", message.AutomationName)
+	case "anomaly":
+		color = "warning"
+		statusEmoji = ":snail:"
+		mainText = statusEmoji + " " + l.T("notification.anomaly", message.AutomationName)
+	case "stalled":
+		color = "danger"
+		statusEmoji = ":hourglass:"
+		mainText = statusEmoji + " " + l.T("notification.stalled", message.AutomationName)
 	default:
 		color = "warning"
 		statusEmoji = ":warning:"
-		mainText = fmt.Sprintf("%s Automation *%s* finished with status: %s", statusEmoji, message.AutomationName, message.Status)
+		mainText = statusEmoji + " " + l.T("notification.finished", message.AutomationName, l.T("status."+message.Status))
 	}
 
 	// Build fields
 	fields := []SlackField{
 		{
-			Title: "Project",
+			Title: l.T("report.project"),
 			Value: message.ProjectName,
 			Short: true,
 		},
 		{
-			Title: "Run ID",
+			Title: l.T("report.run_id"),
 			Value: message.RunID[:8] + "...", // Shortened for display
 			Short: true,
 		},
 	}
 
+	if message.SavedView != "" {
+		fields = append(fields, SlackField{
+			Title: l.T("report.saved_view"),
+			Value: message.SavedView,
+			Short: true,
+		})
+	}
+
 	// Add duration if both start and end times are available
 	if message.StartTime != nil && message.EndTime != nil {
 		duration := message.EndTime.Sub(*message.StartTime)
 		fields = append(fields, SlackField{
-			Title: "Duration",
+			Title: l.T("report.duration"),
 			Value: s.formatDuration(duration),
 			Short: true,
 		})
@@ -145,7 +165,7 @@ func (s *SlackNotifier) buildSlackMessage(message NotificationMessage, channelCo
 	// Add logs count
 	if message.LogsCount > 0 {
 		fields = append(fields, SlackField{
-			Title: "Log Entries",
+			Title: l.T("report.log_entries"),
 			Value: fmt.Sprintf("%d", message.LogsCount),
 			Short: true,
 		})
@@ -154,8 +174,17 @@ func (s *SlackNotifier) buildSlackMessage(message NotificationMessage, channelCo
 	// Add output files count
 	if len(message.OutputFiles) > 0 {
 		fields = append(fields, SlackField{
-			Title: "Output Files",
-			Value: fmt.Sprintf("%d files generated", len(message.OutputFiles)),
+			Title: l.T("report.output_files"),
+			Value: l.T("report.files_generated", len(message.OutputFiles)),
+			Short: true,
+		})
+	}
+
+	// Link the PDF report if one was generated
+	if message.ReportURL != "" {
+		fields = append(fields, SlackField{
+			Title: l.T("report.pdf"),
+			Value: fmt.Sprintf("<%s|%s>", message.ReportURL, l.T("report.download")),
 			Short: true,
 		})
 	}
@@ -163,7 +192,7 @@ func (s *SlackNotifier) buildSlackMessage(message NotificationMessage, channelCo
 	// Add error message if present
 	if message.ErrorMessage != "" {
 		fields = append(fields, SlackField{
-			Title: "Error",
+			Title: l.T("report.error"),
 			Value: message.ErrorMessage,
 			Short: false,
 		})
@@ -171,8 +200,8 @@ func (s *SlackNotifier) buildSlackMessage(message NotificationMessage, channelCo
 
 	attachment := SlackAttachment{
 		Color:  color,
-		Title:  fmt.Sprintf("Automation Run Details"),
-		Text:   fmt.Sprintf("Run ID: `%s`", message.RunID),
+		Title:  l.T("report.run_details"),
+		Text:   l.T("notification.run_id", message.RunID),
 		Fields: fields,
 	}
 
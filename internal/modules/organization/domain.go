@@ -2,16 +2,100 @@ package organization
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
 // Organization represents a workspace that contains projects
 type Organization struct {
-	ID          string
-	Name        string
-	OwnerUserID string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID           string
+	Name         string
+	OwnerUserID  string
+	SettingsJSON string // JSON string containing OrganizationSettings
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// OrganizationSettings holds org-wide defaults inherited by new automations
+// and policies that apply to every automation in the organization
+type OrganizationSettings struct {
+	DefaultBrowser           string                       `json:"defaultBrowser,omitempty"` // "chromium", "firefox", "webkit"
+	DefaultTimeout           int                          `json:"defaultTimeout,omitempty"` // in seconds
+	RequireScreenshotOnError bool                         `json:"requireScreenshotOnError"`
+	DefaultNotifications     []DefaultNotificationChannel `json:"defaultNotifications,omitempty"`
+	RunRetentionDays         int                          `json:"runRetentionDays,omitempty"` // 0 keeps runs forever
+	ReportLocale             string                       `json:"reportLocale,omitempty"`     // language of notifications and reports, English when empty
+	Teams                    []Team                       `json:"teams,omitempty"`
+	StaleAutomations         StaleAutomationSettings      `json:"staleAutomations"`
+	MaintenanceCalendar      MaintenanceCalendarSettings  `json:"maintenanceCalendar"`
+	ScriptSandbox            ScriptSandboxSettings        `json:"scriptSandbox"`
+}
+
+// StaleAutomationSettings control when automations are flagged as stale in the maintenance
+// report: after unusedDays without a run, or failingDays of only failed runs
+type StaleAutomationSettings struct {
+	Disabled    bool `json:"disabled,omitempty"`
+	UnusedDays  int  `json:"unusedDays,omitempty"`  // defaults to 30
+	FailingDays int  `json:"failingDays,omitempty"` // defaults to 7
+	AutoDisable bool `json:"autoDisable,omitempty"` // stop stale automations from running until they are re-enabled
+}
+
+// MaintenanceCalendarSettings point to an iCal feed of planned maintenance. Runs executed during
+// its events are annotated and don't alert, and pauseRuns refuses to start runs during them.
+type MaintenanceCalendarSettings struct {
+	URL       string `json:"url,omitempty"`
+	PauseRuns bool   `json:"pauseRuns,omitempty"`
+}
+
+// ScriptSandboxSettings constrain the custom JavaScript the organization's automations run with
+// playwright:evaluate and the run debug console. Zero limits and no banned APIs leave scripts
+// unrestricted. Banned APIs are found in the script text, so they are advisory only: scripts
+// that build names at runtime aren't caught.
+type ScriptSandboxSettings struct {
+	MaxScriptBytes int      `json:"maxScriptBytes,omitempty"`
+	BannedAPIs     []string `json:"bannedApis,omitempty"` // e.g. "fetch", "eval", "document.cookie"
+	TimeoutMs      int      `json:"timeoutMs,omitempty"`
+}
+
+// Team is a group of the organization's users that can own automations. Failures of automations
+// a team owns go to its notification channels, or to its members by email when it has none.
+type Team struct {
+	Name          string                       `json:"name"`
+	MemberIDs     []string                     `json:"memberIds"`
+	Notifications []DefaultNotificationChannel `json:"notifications,omitempty"`
+}
+
+// DefaultNotificationChannel is a notification channel added to new automations
+type DefaultNotificationChannel struct {
+	Type       string         `json:"type"` // "slack", "email", "webhook"
+	OnComplete bool           `json:"onComplete"`
+	OnError    bool           `json:"onError"`
+	Config     map[string]any `json:"config"`
+}
+
+// FakerDictionary is a named set of domain values, such as product names, SKUs or region codes,
+// that the organization's automations draw generated data from with {{faker.custom.<name>}}
+type FakerDictionary struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Values         []string  `json:"values,omitempty"` // left empty when listing dictionaries
+	ValueCount     int       `json:"value_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Settings parses the organization's settings, returning empty settings when none are stored
+func (o *Organization) Settings() (*OrganizationSettings, error) {
+	settings := &OrganizationSettings{}
+	if o.SettingsJSON == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(o.SettingsJSON), settings); err != nil {
+		return nil, fmt.Errorf("failed to parse organization settings: %w", err)
+	}
+	return settings, nil
 }
 
 // OrganizationRepository defines the interface for organization data operations
@@ -20,7 +104,14 @@ type OrganizationRepository interface {
 	GetByID(ctx context.Context, id string) (*Organization, error)
 	GetByOwnerUserID(ctx context.Context, ownerUserID string) ([]*Organization, error)
 	Update(ctx context.Context, org *Organization) error
+	UpdateSettings(ctx context.Context, id, settingsJSON string) error
 	Delete(ctx context.Context, id string) error
+
+	// Faker dictionaries
+	UpsertFakerDictionary(ctx context.Context, dictionary *FakerDictionary) error
+	GetFakerDictionary(ctx context.Context, orgID, name string) (*FakerDictionary, error)
+	GetFakerDictionaries(ctx context.Context, orgID string) ([]*FakerDictionary, error)
+	DeleteFakerDictionary(ctx context.Context, orgID, name string) error
 }
 
 // OrganizationService defines the interface for organization business logic
@@ -28,4 +119,12 @@ type OrganizationService interface {
 	CreatePersonalOrganization(ctx context.Context, userID, userEmail string) (*Organization, error)
 	GetUserOrganizations(ctx context.Context, userID string) ([]*Organization, error)
 	GetOrganizationByID(ctx context.Context, id string) (*Organization, error)
-}
\ No newline at end of file
+	GetOrganizationSettings(ctx context.Context, id string) (*OrganizationSettings, error)
+	UpdateOrganizationSettings(ctx context.Context, id string, settings *OrganizationSettings) error
+
+	// Faker dictionaries
+	SaveFakerDictionary(ctx context.Context, dictionary *FakerDictionary) (*FakerDictionary, error)
+	GetFakerDictionary(ctx context.Context, orgID, name string) (*FakerDictionary, error)
+	GetFakerDictionaries(ctx context.Context, orgID string) ([]*FakerDictionary, error)
+	DeleteFakerDictionary(ctx context.Context, orgID, name string) error
+}
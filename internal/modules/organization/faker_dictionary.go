@@ -0,0 +1,47 @@
+package organization
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Faker dictionary limits
+const (
+	MaxFakerDictionaryValues   = 10000
+	maxFakerDictionaryValueLen = 1000
+)
+
+// fakerDictionaryNamePattern matches the names dictionaries are referenced by in
+// {{faker.custom.<name>}}
+var fakerDictionaryNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateFakerDictionary checks a dictionary's name and values, dropping blank values and
+// surrounding whitespace. Duplicates are kept, so a value listed twice is drawn twice as often.
+func ValidateFakerDictionary(dictionary *FakerDictionary) error {
+	if !fakerDictionaryNamePattern.MatchString(dictionary.Name) {
+		return fmt.Errorf("name must be 1 to 64 letters, digits, dashes or underscores")
+	}
+
+	values := make([]string, 0, len(dictionary.Values))
+	for _, value := range dictionary.Values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if len(value) > maxFakerDictionaryValueLen {
+			return fmt.Errorf("values must be at most %d characters", maxFakerDictionaryValueLen)
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("values must contain at least one value")
+	}
+	if len(values) > MaxFakerDictionaryValues {
+		return fmt.Errorf("values may contain at most %d values", MaxFakerDictionaryValues)
+	}
+
+	dictionary.Values = values
+	dictionary.ValueCount = len(values)
+	return nil
+}
@@ -0,0 +1,29 @@
+package organization
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidateMaintenanceCalendar checks the calendar URL is an http(s) or webcal feed, trimming it
+func ValidateMaintenanceCalendar(calendar *MaintenanceCalendarSettings) error {
+	calendar.URL = strings.TrimSpace(calendar.URL)
+	if calendar.URL == "" {
+		if calendar.PauseRuns {
+			return fmt.Errorf("pausing runs for maintenance requires a calendar URL")
+		}
+		return nil
+	}
+
+	parsed, err := url.Parse(calendar.URL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid maintenance calendar URL")
+	}
+	switch parsed.Scheme {
+	case "http", "https", "webcal":
+		return nil
+	default:
+		return fmt.Errorf("maintenance calendar URLs must be http, https or webcal URLs")
+	}
+}
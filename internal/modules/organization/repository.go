@@ -34,7 +34,7 @@ func (r *organizationRepository) Create(ctx context.Context, org *Organization)
 	query, args, err := r.sq.Insert("organizations").
 		Columns("id", "name", "owner_user_id").
 		Values(org.ID, org.Name, org.OwnerUserID).
-		Suffix("RETURNING id, name, owner_user_id, created_at, updated_at").
+		Suffix("RETURNING id, name, owner_user_id, settings_json, created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build query: %w", err)
@@ -42,7 +42,7 @@ func (r *organizationRepository) Create(ctx context.Context, org *Organization)
 
 	var createdAt, updatedAt pgtype.Timestamp
 	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&org.ID, &org.Name, &org.OwnerUserID, &createdAt, &updatedAt,
+		&org.ID, &org.Name, &org.OwnerUserID, &org.SettingsJSON, &createdAt, &updatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create organization: %w", err)
@@ -54,7 +54,7 @@ func (r *organizationRepository) Create(ctx context.Context, org *Organization)
 }
 
 func (r *organizationRepository) GetByID(ctx context.Context, id string) (*Organization, error) {
-	query, args, err := r.sq.Select("id", "name", "owner_user_id", "created_at", "updated_at").
+	query, args, err := r.sq.Select("id", "name", "owner_user_id", "settings_json", "created_at", "updated_at").
 		From("organizations").
 		Where(sq.Eq{"id": id}).
 		ToSql()
@@ -65,7 +65,7 @@ func (r *organizationRepository) GetByID(ctx context.Context, id string) (*Organ
 	var org Organization
 	var createdAt, updatedAt pgtype.Timestamp
 	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&org.ID, &org.Name, &org.OwnerUserID, &createdAt, &updatedAt,
+		&org.ID, &org.Name, &org.OwnerUserID, &org.SettingsJSON, &createdAt, &updatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -80,7 +80,7 @@ func (r *organizationRepository) GetByID(ctx context.Context, id string) (*Organ
 }
 
 func (r *organizationRepository) GetByOwnerUserID(ctx context.Context, ownerUserID string) ([]*Organization, error) {
-	query, args, err := r.sq.Select("id", "name", "owner_user_id", "created_at", "updated_at").
+	query, args, err := r.sq.Select("id", "name", "owner_user_id", "settings_json", "created_at", "updated_at").
 		From("organizations").
 		Where(sq.Eq{"owner_user_id": ownerUserID}).
 		OrderBy("created_at ASC").
@@ -99,7 +99,7 @@ func (r *organizationRepository) GetByOwnerUserID(ctx context.Context, ownerUser
 	for rows.Next() {
 		var org Organization
 		var createdAt, updatedAt pgtype.Timestamp
-		err := rows.Scan(&org.ID, &org.Name, &org.OwnerUserID, &createdAt, &updatedAt)
+		err := rows.Scan(&org.ID, &org.Name, &org.OwnerUserID, &org.SettingsJSON, &createdAt, &updatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan organization: %w", err)
 		}
@@ -132,6 +132,24 @@ func (r *organizationRepository) Update(ctx context.Context, org *Organization)
 	return nil
 }
 
+func (r *organizationRepository) UpdateSettings(ctx context.Context, id, settingsJSON string) error {
+	query, args, err := r.sq.Update("organizations").
+		Set("settings_json", settingsJSON).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update organization settings: %w", err)
+	}
+
+	return nil
+}
+
 func (r *organizationRepository) Delete(ctx context.Context, id string) error {
 	query, args, err := r.sq.Delete("organizations").
 		Where(sq.Eq{"id": id}).
@@ -146,4 +164,104 @@ func (r *organizationRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+// Faker dictionaries
+func (r *organizationRepository) UpsertFakerDictionary(ctx context.Context, dictionary *FakerDictionary) error {
+	query, args, err := r.sq.Insert("faker_dictionaries").
+		Columns("id", "organization_id", "name", `"values"`).
+		Values(dictionary.ID, dictionary.OrganizationID, dictionary.Name, dictionary.Values).
+		Suffix(`ON CONFLICT (organization_id, name) DO UPDATE SET "values" = EXCLUDED."values", updated_at = now() RETURNING id, created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var createdAt, updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&dictionary.ID, &createdAt, &updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save faker dictionary: %w", err)
+	}
+
+	dictionary.ValueCount = len(dictionary.Values)
+	dictionary.CreatedAt = createdAt.Time
+	dictionary.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+func (r *organizationRepository) GetFakerDictionary(ctx context.Context, orgID, name string) (*FakerDictionary, error) {
+	query, args, err := r.sq.Select("id", "organization_id", "name", `"values"`, "created_at", "updated_at").
+		From("faker_dictionaries").
+		Where(sq.Eq{"organization_id": orgID, "name": name}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var dictionary FakerDictionary
+	var createdAt, updatedAt pgtype.Timestamp
+	err = r.db.QueryRow(ctx, query, args...).Scan(&dictionary.ID, &dictionary.OrganizationID, &dictionary.Name, &dictionary.Values, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("faker dictionary not found")
+		}
+		return nil, fmt.Errorf("failed to get faker dictionary: %w", err)
+	}
+
+	dictionary.ValueCount = len(dictionary.Values)
+	dictionary.CreatedAt = createdAt.Time
+	dictionary.UpdatedAt = updatedAt.Time
+	return &dictionary, nil
+}
+
+// GetFakerDictionaries lists an organization's dictionaries with their value counts but without
+// their values
+func (r *organizationRepository) GetFakerDictionaries(ctx context.Context, orgID string) ([]*FakerDictionary, error) {
+	query, args, err := r.sq.Select("id", "organization_id", "name", `jsonb_array_length("values")`, "created_at", "updated_at").
+		From("faker_dictionaries").
+		Where(sq.Eq{"organization_id": orgID}).
+		OrderBy("name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query faker dictionaries: %w", err)
+	}
+	defer rows.Close()
+
+	var dictionaries []*FakerDictionary
+	for rows.Next() {
+		var dictionary FakerDictionary
+		var createdAt, updatedAt pgtype.Timestamp
+		err := rows.Scan(&dictionary.ID, &dictionary.OrganizationID, &dictionary.Name, &dictionary.ValueCount, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan faker dictionary: %w", err)
+		}
+		dictionary.CreatedAt = createdAt.Time
+		dictionary.UpdatedAt = updatedAt.Time
+		dictionaries = append(dictionaries, &dictionary)
+	}
+
+	return dictionaries, nil
+}
+
+func (r *organizationRepository) DeleteFakerDictionary(ctx context.Context, orgID, name string) error {
+	query, args, err := r.sq.Delete("faker_dictionaries").
+		Where(sq.Eq{"organization_id": orgID, "name": name}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete faker dictionary: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("faker dictionary not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package organization
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Script sandbox limits
+const (
+	maxScriptSandboxBytes     = 1 << 20
+	maxScriptSandboxTimeoutMs = 600000
+	maxScriptSandboxBannedAPI = 100
+)
+
+// bannedAPIPattern matches global names and dotted paths such as fetch or document.cookie
+var bannedAPIPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// ValidateScriptSandbox checks the sandbox limits are in range and banned APIs are names or
+// dotted paths, trimming them and dropping duplicates
+func ValidateScriptSandbox(sandbox *ScriptSandboxSettings) error {
+	if sandbox.MaxScriptBytes < 0 || sandbox.MaxScriptBytes > maxScriptSandboxBytes {
+		return fmt.Errorf("the maximum script size must be between 0 and %d bytes", maxScriptSandboxBytes)
+	}
+	if sandbox.TimeoutMs < 0 || sandbox.TimeoutMs > maxScriptSandboxTimeoutMs {
+		return fmt.Errorf("the script time limit must be between 0 and %d ms", maxScriptSandboxTimeoutMs)
+	}
+	if len(sandbox.BannedAPIs) > maxScriptSandboxBannedAPI {
+		return fmt.Errorf("at most %d APIs can be banned", maxScriptSandboxBannedAPI)
+	}
+
+	seen := make(map[string]bool, len(sandbox.BannedAPIs))
+	banned := make([]string, 0, len(sandbox.BannedAPIs))
+	for _, api := range sandbox.BannedAPIs {
+		api = strings.TrimSpace(api)
+		if !bannedAPIPattern.MatchString(api) {
+			return fmt.Errorf("banned API %q must be a global name or a dotted path such as document.cookie", api)
+		}
+		if !seen[api] {
+			seen[api] = true
+			banned = append(banned, api)
+		}
+	}
+	sandbox.BannedAPIs = banned
+	return nil
+}
@@ -2,6 +2,7 @@ package organization
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 
@@ -53,4 +54,95 @@ func (s *organizationService) GetOrganizationByID(ctx context.Context, id string
 	}
 
 	return org, nil
-}
\ No newline at end of file
+}
+
+func (s *organizationService) GetOrganizationSettings(ctx context.Context, id string) (*OrganizationSettings, error) {
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get organization by ID", "error", err, "orgID", id)
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	settings, err := org.Settings()
+	if err != nil {
+		slog.Error("Failed to parse organization settings", "error", err, "orgID", id)
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *organizationService) UpdateOrganizationSettings(ctx context.Context, id string, settings *OrganizationSettings) error {
+	if err := ValidateTeams(settings.Teams); err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if err := ValidateMaintenanceCalendar(&settings.MaintenanceCalendar); err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+	if err := ValidateScriptSandbox(&settings.ScriptSandbox); err != nil {
+		return fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal organization settings: %w", err)
+	}
+
+	err = s.orgRepo.UpdateSettings(ctx, id, string(settingsJSON))
+	if err != nil {
+		slog.Error("Failed to update organization settings", "error", err, "orgID", id)
+		return fmt.Errorf("failed to update organization settings: %w", err)
+	}
+
+	slog.Info("Organization settings updated", "orgID", id)
+	return nil
+}
+
+// Faker dictionaries
+
+// SaveFakerDictionary creates an organization's dictionary, or replaces the values of the
+// existing one with the same name
+func (s *organizationService) SaveFakerDictionary(ctx context.Context, dictionary *FakerDictionary) (*FakerDictionary, error) {
+	if err := ValidateFakerDictionary(dictionary); err != nil {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRequest, err)
+	}
+
+	dictionary.ID = platform.UtilGenerateUUID()
+	if err := s.orgRepo.UpsertFakerDictionary(ctx, dictionary); err != nil {
+		slog.Error("Failed to save faker dictionary", "error", err, "orgID", dictionary.OrganizationID, "name", dictionary.Name)
+		return nil, fmt.Errorf("failed to save faker dictionary: %w", err)
+	}
+
+	slog.Info("Faker dictionary saved", "dictionaryID", dictionary.ID, "orgID", dictionary.OrganizationID, "name", dictionary.Name, "values", dictionary.ValueCount)
+	return dictionary, nil
+}
+
+func (s *organizationService) GetFakerDictionary(ctx context.Context, orgID, name string) (*FakerDictionary, error) {
+	dictionary, err := s.orgRepo.GetFakerDictionary(ctx, orgID, name)
+	if err != nil {
+		slog.Error("Failed to get faker dictionary", "error", err, "orgID", orgID, "name", name)
+		return nil, fmt.Errorf("failed to get faker dictionary: %w", err)
+	}
+
+	return dictionary, nil
+}
+
+func (s *organizationService) GetFakerDictionaries(ctx context.Context, orgID string) ([]*FakerDictionary, error) {
+	dictionaries, err := s.orgRepo.GetFakerDictionaries(ctx, orgID)
+	if err != nil {
+		slog.Error("Failed to get faker dictionaries", "error", err, "orgID", orgID)
+		return nil, fmt.Errorf("failed to get faker dictionaries: %w", err)
+	}
+
+	return dictionaries, nil
+}
+
+func (s *organizationService) DeleteFakerDictionary(ctx context.Context, orgID, name string) error {
+	if err := s.orgRepo.DeleteFakerDictionary(ctx, orgID, name); err != nil {
+		slog.Error("Failed to delete faker dictionary", "error", err, "orgID", orgID, "name", name)
+		return fmt.Errorf("failed to delete faker dictionary: %w", err)
+	}
+
+	slog.Info("Faker dictionary deleted", "orgID", orgID, "name", name)
+	return nil
+}
@@ -0,0 +1,23 @@
+package organization
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateTeams checks team names are set and unique, trimming them
+func ValidateTeams(teams []Team) error {
+	seen := make(map[string]bool, len(teams))
+	for i := range teams {
+		name := strings.TrimSpace(teams[i].Name)
+		if name == "" {
+			return fmt.Errorf("team names are required")
+		}
+		if seen[name] {
+			return fmt.Errorf("team %q is defined more than once", name)
+		}
+		seen[name] = true
+		teams[i].Name = name
+	}
+	return nil
+}
@@ -63,6 +63,36 @@ var (
 	
 	// Automation Configuration
 	ENV_MAX_CONCURRENT_RUNS = mustHaveEnvInt("MAX_CONCURRENT_RUNS")
+
+	// Warehouse export (optional): WAREHOUSE_SINK is bigquery, clickhouse or postgres
+	ENV_WAREHOUSE_SINK    = os.Getenv("WAREHOUSE_SINK")
+	ENV_WAREHOUSE_DSN     = os.Getenv("WAREHOUSE_DSN")
+	ENV_WAREHOUSE_DATASET = os.Getenv("WAREHOUSE_DATASET")
+
+	// Automation drafts from plain-English scenarios (optional): DRAFT_LLM_BACKEND is openai or anthropic
+	ENV_DRAFT_LLM_BACKEND = os.Getenv("DRAFT_LLM_BACKEND")
+	ENV_DRAFT_LLM_API_URL = os.Getenv("DRAFT_LLM_API_URL")
+	ENV_DRAFT_LLM_API_KEY = os.Getenv("DRAFT_LLM_API_KEY")
+	ENV_DRAFT_LLM_MODEL   = os.Getenv("DRAFT_LLM_MODEL")
+
+	// Encrypted key-value store for store:* actions (optional): disabled when unset
+	ENV_KV_ENCRYPTION_KEY = os.Getenv("KV_ENCRYPTION_KEY")
+
+	// Console commands on runs paused by playwright:pause (optional): debug only, disabled unless "true"
+	ENV_DEBUG_CONSOLE_ENABLED = os.Getenv("DEBUG_CONSOLE_ENABLED") == "true"
+
+	// Extra report and notification languages (optional): a directory of <locale>.json message bundles
+	ENV_REPORT_BUNDLES_DIR = os.Getenv("REPORT_BUNDLES_DIR")
+
+	// Signing of run evidence bundles: a base64 Ed25519 seed, evidence can't be exported when unset
+	ENV_EVIDENCE_SIGNING_KEY = os.Getenv("EVIDENCE_SIGNING_KEY")
+
+	// Capabilities this worker advertises for run routing (optional): browsers are detected when unset
+	ENV_WORKER_ID               = os.Getenv("WORKER_ID")
+	ENV_WORKER_REGION           = os.Getenv("WORKER_REGION")
+	ENV_WORKER_GPU              = os.Getenv("WORKER_GPU") == "true"
+	ENV_WORKER_BROWSERS         = os.Getenv("WORKER_BROWSERS")
+	ENV_WORKER_DISABLED_PLUGINS = os.Getenv("WORKER_DISABLED_PLUGINS")
 )
 
 func init() {
@@ -3,7 +3,9 @@ package platform
 import "errors"
 
 var (
-	ErrNotFound       = errors.New("not found")
-	ErrConflict       = errors.New("resource already exists")
-	ErrInvalidRequest = errors.New("invalid request")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("resource already exists")
+	ErrInvalidRequest     = errors.New("invalid request")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrUnavailable        = errors.New("unavailable")
 )
@@ -0,0 +1,73 @@
+package i18n
+
+func init() {
+	Register(DefaultLocale, map[string]string{
+		// Run statuses
+		"status.pending":   "pending",
+		"status.queued":    "queued",
+		"status.running":   "running",
+		"status.stalled":   "stalled",
+		"status.completed": "completed",
+		"status.failed":    "failed",
+		"status.cancelled": "cancelled",
+
+		// Report labels
+		"report.run_details":     "Automation Run Details",
+		"report.project":         "Project",
+		"report.run_id":          "Run ID",
+		"report.duration":        "Duration",
+		"report.log_entries":     "Log Entries",
+		"report.output_files":    "Output Files",
+		"report.files_generated": "%d files generated",
+		"report.error":           "Error",
+		"report.no_runs":         "No runs yet",
+		"report.last_failure":    "Last failure %s: %s",
+		"report.title":           "Run Report",
+		"report.status":          "Status",
+		"report.started":         "Started",
+		"report.finished":        "Finished",
+		"report.errors":          "Errors",
+		"report.steps":           "Steps",
+		"report.step":            "Step",
+		"report.users":           "Users",
+		"report.actions":         "Actions",
+		"report.avg_duration":    "Avg duration",
+		"report.max_duration":    "Max duration",
+		"report.min_duration":    "Min duration",
+		"report.transactions":    "Transactions",
+		"report.transaction":     "Transaction",
+		"report.count":           "Count",
+		"report.incomplete":      "Incomplete",
+		"report.anomalies":       "Anomalies",
+		"report.generated_at":    "Generated %s",
+		"report.pdf":             "PDF Report",
+		"report.download":        "Download",
+		"report.saved_view":      "Saved view",
+		"report.pass_rate":       "Pass rate",
+		"report.slow":            "slow",
+		"report.slow_users":      "Slow users",
+
+		// Download comparisons in reports
+		"report.artifact_comparisons": "Download Comparisons",
+		"report.artifact_matches":     "matches fixture",
+		"report.artifact_differs":     "differs from fixture",
+		"report.json_path":            "Path",
+		"report.expected":             "Expected",
+		"report.actual":               "Actual",
+		"report.diff_truncated":       "Only the first differences are shown; compare through the API for the rest",
+
+		// Notifications
+		"notification.completed": "Automation *%s* completed successfully!",
+		"notification.failed":    "Automation *%s* failed!",
+		"notification.anomaly":   "Automation *%s* ran unusually slow or fast",
+		"notification.stalled":   "Automation *%s* stalled and stopped making progress",
+		"notification.finished":  "Automation *%s* finished with status: %s",
+		"notification.run_id":    "Run ID: `%s`",
+
+		// Anomaly and stall descriptions
+		"anomaly.run":              "Run took %dms, %.1fσ from the recent mean of %.0fms",
+		"anomaly.step":             "Step '%s' took %dms, %.1fσ from the recent mean of %.0fms",
+		"stall.no_progress":        "No progress for %s",
+		"stall.no_progress_cancel": "No progress for %s; the run was cancelled",
+	})
+}
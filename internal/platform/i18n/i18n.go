@@ -0,0 +1,167 @@
+// Package i18n holds the message bundles used to localize generated reports and notifications.
+//
+// English is built in and is the fallback for every message. More locales are plugged in by
+// registering a bundle, either from Go code in an init function or from <locale>.json files
+// loaded at startup. A bundle only needs the messages it translates; anything it leaves out
+// falls back to the locale's language (pt-BR to pt) and then to English.
+//
+// Messages are fmt format strings. Translations may reorder arguments with explicit indexes,
+// e.g. "%[2]s: %[1]s".
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale of the built-in bundle and the fallback for every message
+const DefaultLocale = "en"
+
+var (
+	mu      sync.RWMutex
+	bundles = map[string]map[string]string{}
+)
+
+// Register adds messages to the bundle of locale, replacing messages it already has
+func Register(locale string, messages map[string]string) {
+	locale = canonicalLocale(locale)
+
+	mu.Lock()
+	defer mu.Unlock()
+	bundle := bundles[locale]
+	if bundle == nil {
+		bundle = make(map[string]string, len(messages))
+		bundles[locale] = bundle
+	}
+	for key, message := range messages {
+		bundle[key] = message
+	}
+}
+
+// LoadDir registers a bundle for every <locale>.json file in dir, each a flat object of
+// message key to message, and returns the locales loaded
+func LoadDir(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message bundles: %w", err)
+	}
+
+	var locales []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return locales, fmt.Errorf("failed to read message bundle %s: %w", path, err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return locales, fmt.Errorf("invalid message bundle %s: %w", path, err)
+		}
+
+		locale := strings.TrimSuffix(filepath.Base(path), ".json")
+		Register(locale, messages)
+		locales = append(locales, canonicalLocale(locale))
+	}
+	return locales, nil
+}
+
+// Locales returns the locales that have a bundle, sorted
+func Locales() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	locales := make([]string, 0, len(bundles))
+	for locale := range bundles {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// IsSupported reports whether locale, or its language, has a bundle
+func IsSupported(locale string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, candidate := range fallbacks(canonicalLocale(locale)) {
+		if _, ok := bundles[candidate]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Localizer formats messages in one locale
+type Localizer struct {
+	locale string
+	chain  []string // locales messages are looked up in, most specific first, ending with English
+}
+
+// For returns a Localizer for locale; an empty or unknown locale formats messages in English
+func For(locale string) *Localizer {
+	locale = canonicalLocale(locale)
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	chain := fallbacks(locale)
+	if chain[len(chain)-1] != DefaultLocale {
+		chain = append(chain, DefaultLocale)
+	}
+	return &Localizer{locale: locale, chain: chain}
+}
+
+// Locale returns the locale the Localizer was created for
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// T formats the message for key with args. A key missing from every bundle is returned as is,
+// so it shows up in the output instead of an empty label.
+func (l *Localizer) T(key string, args ...any) string {
+	mu.RLock()
+	var message string
+	found := false
+	for _, locale := range l.chain {
+		if message, found = bundles[locale][key]; found {
+			break
+		}
+	}
+	mu.RUnlock()
+
+	if !found {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// canonicalLocale lower-cases the language and upper-cases a region, e.g. pt_br -> pt-BR
+func canonicalLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// fallbacks returns locale followed by its less specific forms: pt-BR, pt
+func fallbacks(locale string) []string {
+	chain := []string{locale}
+	for {
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			return chain
+		}
+		locale = locale[:i]
+		chain = append(chain, locale)
+	}
+}
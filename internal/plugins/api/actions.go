@@ -3,11 +3,18 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/delordemm1/qplayground/internal/modules/automation"
@@ -21,6 +28,9 @@ func init() {
 	automation.RegisterAction("api:delete", func() automation.PluginAction { return &ApiDeleteAction{} })
 	automation.RegisterAction("api:if_else", func() automation.PluginAction { return &ApiIfElseAction{} })
 	automation.RegisterAction("api:runtime_loop_until", func() automation.PluginAction { return &ApiRuntimeLoopUntilAction{} })
+	automation.RegisterAction("api:wait_until", func() automation.PluginAction { return &ApiWaitUntilAction{} })
+	automation.RegisterAction("api:create_and_wait", func() automation.PluginAction { return &ApiCreateAndWaitAction{} })
+	automation.RegisterAction("api:batch", func() automation.PluginAction { return &ApiBatchAction{} })
 	automation.RegisterAction("api:log", func() automation.PluginAction { return &ApiLogAction{} })
 }
 
@@ -83,11 +93,51 @@ func sendApiErrorEvent(runContext *automation.RunContext, actionType, errorMsg s
 type BaseApiAction struct{}
 
 func (b *BaseApiAction) executeApiRequest(ctx context.Context, method string, config ApiActionConfigBase, runContext *automation.RunContext) error {
+	actionType := fmt.Sprintf("api:%s", strings.ToLower(method))
+
+	responseData, responseBody, err := b.sendRequest(ctx, method, config, runContext)
+	duration := time.Duration(responseData.ResponseTime) * time.Millisecond
+	if err != nil {
+		if responseData.Error != "" {
+			sendApiErrorEvent(runContext, actionType, responseData.Error, duration, &responseData)
+		}
+		return err
+	}
+
+	// Check for HTTP errors
+	if responseData.StatusCode >= 400 {
+		errorMsg := fmt.Sprintf("HTTP %d: %s", responseData.StatusCode, http.StatusText(responseData.StatusCode))
+		responseData.Error = errorMsg
+		sendApiErrorEvent(runContext, actionType, errorMsg, duration, &responseData)
+		return fmt.Errorf("HTTP request failed with status %d", responseData.StatusCode)
+	}
+
+	// Parse response as JSON for after_hooks processing
+	var responseJSON map[string]interface{}
+	if len(responseBody) > 0 {
+		if err := json.Unmarshal(responseBody, &responseJSON); err != nil {
+			runContext.Logger.Warn("Failed to parse response as JSON, after_hooks will be skipped", "error", err)
+		}
+	}
+
+	b.applyAfterHooks(responseJSON, config.AfterHooks, runContext, &responseData)
+
+	message := fmt.Sprintf("Successfully executed %s request to %s (HTTP %d)", method, responseData.URL, responseData.StatusCode)
+	sendApiSuccessEvent(runContext, actionType, message, duration, responseData)
+
+	return nil
+}
+
+// sendRequest resolves variables in the request, sends it and reads the response body.
+// HTTP error statuses are not treated as errors. When the request fails after it was
+// built, the returned response data has Error set.
+func (b *BaseApiAction) sendRequest(ctx context.Context, method string, config ApiActionConfigBase, runContext *automation.RunContext) (ApiResponseData, []byte, error) {
 	startTime := time.Now()
+	responseData := ApiResponseData{Method: method}
 
 	// Validate required fields
 	if config.URL == "" {
-		return fmt.Errorf("%s action requires a 'url' string in config", method)
+		return responseData, nil, fmt.Errorf("%s action requires a 'url' string in config", method)
 	}
 
 	runContext.Logger.Info("Executing API request", "method", method, "url", config.URL)
@@ -95,15 +145,16 @@ func (b *BaseApiAction) executeApiRequest(ctx context.Context, method string, co
 	// Resolve variables in URL
 	resolvedURL, err := runContext.Runner.ResolveVariablesInString(config.URL, runContext.VariableContext, runContext.AutomationConfig)
 	if err != nil {
-		return fmt.Errorf("failed to resolve variables in URL: %w", err)
+		return responseData, nil, fmt.Errorf("failed to resolve variables in URL: %w", err)
 	}
+	responseData.URL = resolvedURL
 
 	// Resolve variables in body
 	resolvedBody := ""
 	if config.Body != "" {
 		resolvedBody, err = runContext.Runner.ResolveVariablesInString(config.Body, runContext.VariableContext, runContext.AutomationConfig)
 		if err != nil {
-			return fmt.Errorf("failed to resolve variables in body: %w", err)
+			return responseData, nil, fmt.Errorf("failed to resolve variables in body: %w", err)
 		}
 	}
 
@@ -113,16 +164,19 @@ func (b *BaseApiAction) executeApiRequest(ctx context.Context, method string, co
 		bodyReader = strings.NewReader(resolvedBody)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, resolvedURL, bodyReader)
+	// Set timeout; it covers the whole exchange including reading the body
+	timeout := 30 * time.Second // Default timeout
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Millisecond
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, method, resolvedURL, bodyReader)
 	if err != nil {
-		duration := time.Since(startTime)
-		responseData := ApiResponseData{
-			URL:    resolvedURL,
-			Method: method,
-			Error:  err.Error(),
-		}
-		sendApiErrorEvent(runContext, fmt.Sprintf("api:%s", strings.ToLower(method)), err.Error(), duration, &responseData)
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		responseData.ResponseTime = time.Since(startTime).Milliseconds()
+		responseData.Error = err.Error()
+		return responseData, nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Set default headers
@@ -135,11 +189,11 @@ func (b *BaseApiAction) executeApiRequest(ctx context.Context, method string, co
 	for key, value := range config.Headers {
 		resolvedKey, err := runContext.Runner.ResolveVariablesInString(key, runContext.VariableContext, runContext.AutomationConfig)
 		if err != nil {
-			return fmt.Errorf("failed to resolve variables in header key '%s': %w", key, err)
+			return responseData, nil, fmt.Errorf("failed to resolve variables in header key '%s': %w", key, err)
 		}
 		resolvedValue, err := runContext.Runner.ResolveVariablesInString(value, runContext.VariableContext, runContext.AutomationConfig)
 		if err != nil {
-			return fmt.Errorf("failed to resolve variables in header value '%s': %w", value, err)
+			return responseData, nil, fmt.Errorf("failed to resolve variables in header value '%s': %w", value, err)
 		}
 		resolvedHeaders[resolvedKey] = resolvedValue
 		req.Header.Set(resolvedKey, resolvedValue)
@@ -149,37 +203,30 @@ func (b *BaseApiAction) executeApiRequest(ctx context.Context, method string, co
 	if config.Auth != nil {
 		err := b.setAuthHeader(req, config.Auth, runContext)
 		if err != nil {
-			return fmt.Errorf("failed to set authentication header: %w", err)
+			return responseData, nil, fmt.Errorf("failed to set authentication header: %w", err)
 		}
 	} else {
 		// Auto-detect common authentication tokens from runtime variables
 		b.autoSetAuthHeaders(req, runContext)
 	}
 
-	// Set timeout
-	timeout := 30 * time.Second // Default timeout
-	if config.Timeout > 0 {
-		timeout = time.Duration(config.Timeout) * time.Millisecond
+	// Use the run's pooled client so connections are reused across actions and users
+	client := runContext.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
 	}
 
-	client := &http.Client{Timeout: timeout}
-
 	// Execute request
 	resp, err := client.Do(req)
 	duration := time.Since(startTime)
 
-	responseData := ApiResponseData{
-		URL:            resolvedURL,
-		Method:         method,
-		RequestHeaders: resolvedHeaders,
-		ResponseTime:   duration.Milliseconds(),
-		ExtractedVars:  make(map[string]interface{}),
-	}
+	responseData.RequestHeaders = resolvedHeaders
+	responseData.ResponseTime = duration.Milliseconds()
+	responseData.ExtractedVars = make(map[string]interface{})
 
 	if err != nil {
 		responseData.Error = err.Error()
-		sendApiErrorEvent(runContext, fmt.Sprintf("api:%s", strings.ToLower(method)), err.Error(), duration, &responseData)
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return responseData, nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -189,68 +236,51 @@ func (b *BaseApiAction) executeApiRequest(ctx context.Context, method string, co
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		responseData.Error = "failed to read response body"
-		sendApiErrorEvent(runContext, fmt.Sprintf("api:%s", strings.ToLower(method)), "failed to read response body", duration, &responseData)
-		return fmt.Errorf("failed to read response body: %w", err)
+		return responseData, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	responseData.ResponseBody = string(responseBody)
+	return responseData, responseBody, nil
+}
 
-	// Check for HTTP errors
-	if resp.StatusCode >= 400 {
-		errorMsg := fmt.Sprintf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
-		responseData.Error = errorMsg
-		sendApiErrorEvent(runContext, fmt.Sprintf("api:%s", strings.ToLower(method)), errorMsg, duration, &responseData)
-		return fmt.Errorf("HTTP request failed with status %d", resp.StatusCode)
-	}
-
-	// Parse response as JSON for after_hooks processing
-	var responseJSON map[string]interface{}
-	if len(responseBody) > 0 {
-		if err := json.Unmarshal(responseBody, &responseJSON); err != nil {
-			runContext.Logger.Warn("Failed to parse response as JSON, after_hooks will be skipped", "error", err)
-		}
+// applyAfterHooks extracts runtime variables from a JSON response
+func (b *BaseApiAction) applyAfterHooks(responseJSON map[string]interface{}, hooks []AfterHookConfig, runContext *automation.RunContext, responseData *ApiResponseData) {
+	if responseJSON == nil || len(hooks) == 0 {
+		return
 	}
 
-	// Process after_hooks to extract runtime variables
-	if responseJSON != nil && len(config.AfterHooks) > 0 {
-		for _, hook := range config.AfterHooks {
-			var extractedValue interface{}
-			var err error
-
-			if hook.Path == "" || hook.Path == "." {
-				// Store the entire response
-				extractedValue = responseJSON
-			} else {
-				extractedValue, err = b.extractJSONPath(responseJSON, hook.Path)
-			}
-
-			if err != nil {
-				runContext.Logger.Warn("Failed to extract value from JSON path", "path", hook.Path, "error", err)
-				continue
-			}
+	for _, hook := range hooks {
+		var extractedValue interface{}
+		var err error
 
-			// Determine scope and save variable
-			if hook.Scope == "global" {
-				// Save to global vars (global scope)
-				runContext.VariableContext.GlobalVars[hook.SaveAs] = extractedValue
-			} else {
-				// Save to runtime vars (local scope - default) as interface{}
-				runContext.VariableContext.RuntimeVars[hook.SaveAs] = extractedValue
-			}
+		if hook.Path == "" || hook.Path == "." {
+			// Store the entire response
+			extractedValue = responseJSON
+		} else {
+			extractedValue, err = b.extractJSONPath(responseJSON, hook.Path)
+		}
 
-			responseData.ExtractedVars[hook.SaveAs] = extractedValue
-			runContext.Logger.Info("Extracted runtime variable",
-				"path", hook.Path,
-				"save_as", hook.SaveAs,
-				"value_type", fmt.Sprintf("%T", extractedValue),
-				"scope", hook.Scope)
+		if err != nil {
+			runContext.Logger.Warn("Failed to extract value from JSON path", "path", hook.Path, "error", err)
+			continue
 		}
-	}
 
-	message := fmt.Sprintf("Successfully executed %s request to %s (HTTP %d)", method, resolvedURL, resp.StatusCode)
-	sendApiSuccessEvent(runContext, fmt.Sprintf("api:%s", strings.ToLower(method)), message, duration, responseData)
+		// Determine scope and save variable
+		if hook.Scope == "global" {
+			// Save to global vars (global scope)
+			runContext.VariableContext.GlobalVars[hook.SaveAs] = extractedValue
+		} else {
+			// Save to runtime vars (local scope - default) as interface{}
+			runContext.VariableContext.RuntimeVars[hook.SaveAs] = extractedValue
+		}
 
-	return nil
+		responseData.ExtractedVars[hook.SaveAs] = extractedValue
+		runContext.Logger.Info("Extracted runtime variable",
+			"path", hook.Path,
+			"save_as", hook.SaveAs,
+			"value_type", fmt.Sprintf("%T", extractedValue),
+			"scope", hook.Scope)
+	}
 }
 
 // setAuthHeader sets the authentication header based on auth configuration
@@ -1009,7 +1039,7 @@ func (a *ApiRuntimeLoopUntilAction) Execute(ctx context.Context, actionConfig ma
 			message := fmt.Sprintf("Runtime variable loop force stopped: %s", forceStopReason)
 			if failOnForceStop {
 				runContext.Logger.Error("Runtime variable loop force stopped", "reason", forceStopReason, "loops_completed", loopCount)
-				executionError = fmt.Errorf(message)
+				executionError = errors.New(message)
 				return executionError
 			} else {
 				runContext.Logger.Warn("Runtime variable loop force stopped", "reason", forceStopReason, "loops_completed", loopCount)
@@ -1289,6 +1319,535 @@ func (a *ApiRuntimeLoopUntilAction) resolveNestedPath(base interface{}, pathPart
 	return current, nil
 }
 
+// ApiWaitUntilAction polls an endpoint until a value in its JSON response satisfies a condition,
+// for eventual-consistency checks
+type ApiWaitUntilAction struct {
+	BaseApiAction
+}
+
+func (a *ApiWaitUntilAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	config, err := a.parseApiConfig(actionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse API wait_until config: %w", err)
+	}
+	method := http.MethodGet
+	if m, ok := actionConfig["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+	path, _ := actionConfig["path"].(string)
+	conditionType, _ := actionConfig["condition_type"].(string)
+	expectedValue := actionConfig["expected_value"]
+	if conditionType == "" {
+		return fmt.Errorf("api:wait_until requires a 'condition_type' string in config")
+	}
+
+	schedule := parsePollSchedule(actionConfig)
+
+	runContext.Logger.Info("Executing api:wait_until", "method", method, "url", config.URL, "path", path, "condition_type", conditionType)
+
+	deadline := startTime.Add(schedule.timeout)
+	var lastResponse ApiResponseData
+	var lastValue interface{}
+	var lastProblem string
+
+	for attempt := 1; ; attempt++ {
+		responseData, responseBody, err := a.sendRequest(ctx, method, config, runContext)
+		lastResponse = responseData
+		if err != nil {
+			// Configuration errors can't fix themselves; transport errors may
+			if responseData.Error == "" {
+				return err
+			}
+			lastProblem = err.Error()
+		} else {
+			var responseJSON map[string]interface{}
+			if len(responseBody) > 0 {
+				json.Unmarshal(responseBody, &responseJSON)
+			}
+
+			if responseJSON == nil {
+				lastProblem = fmt.Sprintf("HTTP %d response is not a JSON object", responseData.StatusCode)
+			} else {
+				lastValue = responseJSON
+				if path != "" && path != "." {
+					lastValue, err = a.extractJSONPath(responseJSON, path)
+				}
+
+				if err != nil {
+					lastProblem = err.Error()
+				} else if met, condErr := evaluateValueCondition(lastValue, conditionType, expectedValue); condErr != nil {
+					sendApiErrorEvent(runContext, "api:wait_until", condErr.Error(), time.Since(startTime), &responseData)
+					return condErr
+				} else if met {
+					a.applyAfterHooks(responseJSON, config.AfterHooks, runContext, &responseData)
+					message := fmt.Sprintf("Condition '%s' on '%s' met after %d attempt(s)", conditionType, path, attempt)
+					sendApiSuccessEvent(runContext, "api:wait_until", message, time.Since(startTime), responseData)
+					return nil
+				} else {
+					lastProblem = fmt.Sprintf("condition '%s' not met, last value: %v", conditionType, lastValue)
+				}
+			}
+		}
+
+		runContext.Logger.Debug("api:wait_until condition not met", "attempt", attempt, "reason", lastProblem)
+
+		if err := schedule.wait(ctx, attempt, deadline); err != nil {
+			err = fmt.Errorf("api:wait_until %w: %s", err, lastProblem)
+			sendApiErrorEvent(runContext, "api:wait_until", err.Error(), time.Since(startTime), &lastResponse)
+			return err
+		}
+	}
+}
+
+// pollSchedule is the schedule of actions polling an endpoint: the interval grows by
+// backoff_multiplier after each attempt, up to max_interval_ms
+type pollSchedule struct {
+	interval          time.Duration
+	backoffMultiplier float64
+	maxInterval       time.Duration
+	timeout           time.Duration
+	maxAttempts       int
+}
+
+// parsePollSchedule reads interval_ms, backoff_multiplier, max_interval_ms, timeout_ms and
+// max_attempts from an action config
+func parsePollSchedule(actionConfig map[string]interface{}) *pollSchedule {
+	schedule := &pollSchedule{
+		interval:          time.Second,
+		backoffMultiplier: 1.0,
+		maxInterval:       30 * time.Second,
+		timeout:           60 * time.Second,
+	}
+	if intervalMs, ok := actionConfig["interval_ms"].(float64); ok && intervalMs > 0 {
+		schedule.interval = time.Duration(intervalMs) * time.Millisecond
+	}
+	if multiplier, ok := actionConfig["backoff_multiplier"].(float64); ok && multiplier >= 1 {
+		schedule.backoffMultiplier = multiplier
+	}
+	if maxIntervalMs, ok := actionConfig["max_interval_ms"].(float64); ok && maxIntervalMs > 0 {
+		schedule.maxInterval = time.Duration(maxIntervalMs) * time.Millisecond
+	}
+	if timeoutMs, ok := actionConfig["timeout_ms"].(float64); ok && timeoutMs > 0 {
+		schedule.timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+	if maxAttempts, ok := actionConfig["max_attempts"].(float64); ok {
+		schedule.maxAttempts = int(maxAttempts)
+	}
+	return schedule
+}
+
+// wait sleeps until the attempt after the given one, or returns an error when no attempt is left
+// before the deadline
+func (p *pollSchedule) wait(ctx context.Context, attempt int, deadline time.Time) error {
+	if p.maxAttempts > 0 && attempt >= p.maxAttempts {
+		return fmt.Errorf("gave up after %d attempts", attempt)
+	}
+	if time.Now().Add(p.interval).After(deadline) {
+		return fmt.Errorf("timed out after %s (%d attempts)", p.timeout, attempt)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("cancelled")
+	case <-time.After(p.interval):
+	}
+
+	p.interval = time.Duration(float64(p.interval) * p.backoffMultiplier)
+	if p.interval > p.maxInterval {
+		p.interval = p.maxInterval
+	}
+	return nil
+}
+
+// responseTemplatePattern matches {{response.<path>}} placeholders, which api:create_and_wait fills
+// from the creation response
+var responseTemplatePattern = regexp.MustCompile(`\{\{\s*response\.([^}\s]+)\s*\}\}`)
+
+// ApiCreateAndWaitAction creates a resource and polls its status endpoint until the resource
+// reaches a target state, for APIs that provision entities asynchronously. The status URL is
+// derived from the creation response through {{response.<path>}} placeholders.
+type ApiCreateAndWaitAction struct {
+	BaseApiAction
+}
+
+func (a *ApiCreateAndWaitAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	config, err := a.parseApiConfig(actionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse API create_and_wait config: %w", err)
+	}
+	method := http.MethodPost
+	if m, ok := actionConfig["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+	statusURL, _ := actionConfig["status_url"].(string)
+	if statusURL == "" {
+		return fmt.Errorf("api:create_and_wait requires a 'status_url' string in config")
+	}
+	statusPath, _ := actionConfig["status_path"].(string)
+	if statusPath == "" {
+		statusPath = "status"
+	}
+	targetState, _ := actionConfig["target_state"].(string)
+	if targetState == "" {
+		return fmt.Errorf("api:create_and_wait requires a 'target_state' string in config")
+	}
+	var failureStates []string
+	switch states := actionConfig["failure_states"].(type) {
+	case string:
+		for _, state := range strings.Split(states, ",") {
+			if state = strings.TrimSpace(state); state != "" {
+				failureStates = append(failureStates, state)
+			}
+		}
+	case []interface{}:
+		for _, state := range states {
+			failureStates = append(failureStates, fmt.Sprintf("%v", state))
+		}
+	}
+	saveAs, _ := actionConfig["save_as"].(string)
+	schedule := parsePollSchedule(actionConfig)
+
+	runContext.Logger.Info("Executing api:create_and_wait", "method", method, "url", config.URL, "status_url", statusURL, "target_state", targetState)
+
+	// Create the resource
+	createResponse, createBody, err := a.sendRequest(ctx, method, config, runContext)
+	if err != nil {
+		if createResponse.Error != "" {
+			sendApiErrorEvent(runContext, "api:create_and_wait", createResponse.Error, time.Since(startTime), &createResponse)
+		}
+		return err
+	}
+	if createResponse.StatusCode >= 400 {
+		createResponse.Error = fmt.Sprintf("HTTP %d: %s", createResponse.StatusCode, http.StatusText(createResponse.StatusCode))
+		sendApiErrorEvent(runContext, "api:create_and_wait", "creation failed with "+createResponse.Error, time.Since(startTime), &createResponse)
+		return fmt.Errorf("api:create_and_wait creation request failed with status %d", createResponse.StatusCode)
+	}
+
+	var createJSON map[string]interface{}
+	if len(createBody) > 0 {
+		json.Unmarshal(createBody, &createJSON)
+	}
+	a.applyAfterHooks(createJSON, config.AfterHooks, runContext, &createResponse)
+
+	// Derive the status endpoint of the new resource
+	var templateErr error
+	statusURL = responseTemplatePattern.ReplaceAllStringFunc(statusURL, func(match string) string {
+		path := responseTemplatePattern.FindStringSubmatch(match)[1]
+		if createJSON == nil {
+			templateErr = fmt.Errorf("the creation response is not a JSON object, cannot resolve '%s'", path)
+			return match
+		}
+		value, err := a.extractJSONPath(createJSON, path)
+		if err != nil || value == nil {
+			templateErr = fmt.Errorf("cannot resolve '%s' from the creation response: %v", path, err)
+			return match
+		}
+		if number, ok := value.(float64); ok {
+			// Large IDs would otherwise be formatted in exponent notation
+			return strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		return url.PathEscape(fmt.Sprintf("%v", value))
+	})
+	if templateErr != nil {
+		sendApiErrorEvent(runContext, "api:create_and_wait", templateErr.Error(), time.Since(startTime), &createResponse)
+		return fmt.Errorf("api:create_and_wait: %w", templateErr)
+	}
+
+	// Poll with the same headers and authentication
+	statusConfig := config
+	statusConfig.URL = statusURL
+	statusConfig.Body = ""
+
+	deadline := time.Now().Add(schedule.timeout)
+	var lastResponse ApiResponseData
+	var lastProblem string
+
+	for attempt := 1; ; attempt++ {
+		responseData, responseBody, err := a.sendRequest(ctx, http.MethodGet, statusConfig, runContext)
+		lastResponse = responseData
+		if err != nil {
+			// Configuration errors can't fix themselves; transport errors may
+			if responseData.Error == "" {
+				return err
+			}
+			lastProblem = err.Error()
+		} else if responseData.StatusCode >= 400 {
+			// The resource may not be visible to reads yet
+			lastProblem = fmt.Sprintf("HTTP %d from the status endpoint", responseData.StatusCode)
+		} else {
+			var responseJSON map[string]interface{}
+			if len(responseBody) > 0 {
+				json.Unmarshal(responseBody, &responseJSON)
+			}
+
+			if responseJSON == nil {
+				lastProblem = fmt.Sprintf("HTTP %d response is not a JSON object", responseData.StatusCode)
+			} else if value, err := a.extractJSONPath(responseJSON, statusPath); err != nil {
+				lastProblem = err.Error()
+			} else {
+				state := fmt.Sprintf("%v", value)
+				if state == targetState {
+					if saveAs != "" {
+						automation.SaveVariable(actionConfig, runContext, responseJSON)
+						responseData.ExtractedVars[saveAs] = responseJSON
+					}
+					message := fmt.Sprintf("Created resource reached state '%s' after %d status check(s)", targetState, attempt)
+					sendApiSuccessEvent(runContext, "api:create_and_wait", message, time.Since(startTime), responseData)
+					return nil
+				}
+				if slices.Contains(failureStates, state) {
+					err := fmt.Errorf("api:create_and_wait: created resource reached failure state '%s'", state)
+					responseData.Error = err.Error()
+					sendApiErrorEvent(runContext, "api:create_and_wait", err.Error(), time.Since(startTime), &responseData)
+					return err
+				}
+				lastProblem = fmt.Sprintf("state is '%s'", state)
+			}
+		}
+
+		runContext.Logger.Debug("api:create_and_wait target state not reached", "attempt", attempt, "reason", lastProblem)
+
+		if err := schedule.wait(ctx, attempt, deadline); err != nil {
+			err = fmt.Errorf("api:create_and_wait %w waiting for state '%s': %s", err, targetState, lastProblem)
+			sendApiErrorEvent(runContext, "api:create_and_wait", err.Error(), time.Since(startTime), &lastResponse)
+			return err
+		}
+	}
+}
+
+// ApiBatchAction executes a list of requests, optionally in parallel, and saves the aggregated
+// results and latencies as a runtime variable
+type ApiBatchAction struct {
+	BaseApiAction
+}
+
+// batchRequest is one request of an api:batch action
+type batchRequest struct {
+	name   string
+	method string
+	config ApiActionConfigBase
+}
+
+func (a *ApiBatchAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	requestsInterface, _ := actionConfig["requests"].([]interface{})
+	if len(requestsInterface) == 0 {
+		return fmt.Errorf("api:batch action requires a non-empty 'requests' array in config")
+	}
+
+	// Shared settings apply to every request that doesn't set its own
+	defaults, err := a.parseApiConfig(actionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse API batch config: %w", err)
+	}
+
+	concurrency := 1
+	if c, ok := actionConfig["concurrency"].(float64); ok && c > 1 {
+		concurrency = int(c)
+	}
+	repeat := 1
+	if r, ok := actionConfig["repeat"].(float64); ok && r > 1 {
+		repeat = int(r)
+	}
+	saveAs, _ := actionConfig["save_as"].(string)
+	if saveAs == "" {
+		saveAs = "batch_results"
+	}
+	includeBodies, _ := actionConfig["include_bodies"].(bool)
+	failOnError := true
+	if f, ok := actionConfig["fail_on_error"].(bool); ok {
+		failOnError = f
+	}
+
+	var requests []batchRequest
+	for i, requestInterface := range requestsInterface {
+		requestMap, ok := requestInterface.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("api:batch request %d must be an object", i+1)
+		}
+		config, err := a.parseApiConfig(requestMap)
+		if err != nil {
+			return fmt.Errorf("failed to parse api:batch request %d: %w", i+1, err)
+		}
+		if config.URL == "" {
+			return fmt.Errorf("api:batch request %d requires a 'url' string", i+1)
+		}
+		for key, value := range defaults.Headers {
+			if _, exists := config.Headers[key]; !exists {
+				if config.Headers == nil {
+					config.Headers = make(map[string]string)
+				}
+				config.Headers[key] = value
+			}
+		}
+		if config.Auth == nil {
+			config.Auth = defaults.Auth
+		}
+		if config.Timeout == 0 {
+			config.Timeout = defaults.Timeout
+		}
+
+		method := http.MethodGet
+		if m, ok := requestMap["method"].(string); ok && m != "" {
+			method = strings.ToUpper(m)
+		}
+		name, _ := requestMap["name"].(string)
+		requests = append(requests, batchRequest{name: name, method: method, config: config})
+	}
+
+	total := len(requests) * repeat
+	runContext.Logger.Info("Executing api:batch", "requests", total, "concurrency", concurrency)
+
+	// Requests only read the variable context; results are written once they have all finished
+	results := make([]map[string]interface{}, total)
+	latencies := make([]int64, total)
+	failed := make([]bool, total)
+
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return fmt.Errorf("api:batch cancelled")
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(index int, request batchRequest) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			responseData, responseBody, err := a.sendRequest(ctx, request.method, request.config, runContext)
+			result := map[string]interface{}{
+				"index":            index,
+				"name":             request.name,
+				"method":           request.method,
+				"url":              responseData.URL,
+				"status_code":      responseData.StatusCode,
+				"response_time_ms": responseData.ResponseTime,
+			}
+			if err == nil && responseData.StatusCode >= 400 {
+				err = fmt.Errorf("HTTP %d: %s", responseData.StatusCode, http.StatusText(responseData.StatusCode))
+			}
+			if err != nil {
+				result["error"] = err.Error()
+				failed[index] = true
+			}
+			if includeBodies && len(responseBody) > 0 {
+				var body interface{}
+				if json.Unmarshal(responseBody, &body) == nil {
+					result["body"] = body
+				} else {
+					result["body"] = string(responseBody)
+				}
+			}
+
+			results[index] = result
+			latencies[index] = responseData.ResponseTime
+		}(i, requests[i%len(requests)])
+	}
+	wg.Wait()
+
+	failedCount := 0
+	resultList := make([]interface{}, total)
+	for i, result := range results {
+		resultList[i] = result
+		if failed[i] {
+			failedCount++
+		}
+	}
+
+	sortedLatencies := append([]int64(nil), latencies...)
+	sort.Slice(sortedLatencies, func(i, j int) bool { return sortedLatencies[i] < sortedLatencies[j] })
+	var latencySum int64
+	for _, latency := range sortedLatencies {
+		latencySum += latency
+	}
+	percentile := func(p float64) int64 {
+		return sortedLatencies[int(math.Ceil(p*float64(total)))-1]
+	}
+
+	duration := time.Since(startTime)
+	summary := map[string]interface{}{
+		"total":       total,
+		"succeeded":   total - failedCount,
+		"failed":      failedCount,
+		"duration_ms": duration.Milliseconds(),
+		"latency": map[string]interface{}{
+			"min_ms": sortedLatencies[0],
+			"max_ms": sortedLatencies[total-1],
+			"avg_ms": latencySum / int64(total),
+			"p50_ms": percentile(0.5),
+			"p95_ms": percentile(0.95),
+		},
+		"results": resultList,
+	}
+
+	automation.SaveVariable(actionConfig, runContext, summary)
+
+	message := fmt.Sprintf("Executed %d batch requests (%d failed, p95 %dms), saved as runtime.%s", total, failedCount, percentile(0.95), saveAs)
+	if failedCount > 0 && failOnError {
+		err := fmt.Errorf("api:batch: %d of %d requests failed", failedCount, total)
+		sendApiErrorEvent(runContext, "api:batch", err.Error(), duration, nil)
+		return err
+	}
+	sendApiSuccessEvent(runContext, "api:batch", message, duration, ApiResponseData{})
+	return nil
+}
+
+// evaluateValueCondition checks a value against the conditions supported by api:if_else
+func evaluateValueCondition(actualValue interface{}, conditionType string, expectedValue interface{}) (bool, error) {
+	switch conditionType {
+	case "equals":
+		return fmt.Sprintf("%v", actualValue) == fmt.Sprintf("%v", expectedValue), nil
+	case "not_equals":
+		return fmt.Sprintf("%v", actualValue) != fmt.Sprintf("%v", expectedValue), nil
+	case "contains":
+		return strings.Contains(fmt.Sprintf("%v", actualValue), fmt.Sprintf("%v", expectedValue)), nil
+	case "not_contains":
+		return !strings.Contains(fmt.Sprintf("%v", actualValue), fmt.Sprintf("%v", expectedValue)), nil
+	case "is_null":
+		return actualValue == nil, nil
+	case "is_not_null":
+		return actualValue != nil, nil
+	case "is_true":
+		if boolVal, ok := actualValue.(bool); ok {
+			return boolVal, nil
+		}
+		return fmt.Sprintf("%v", actualValue) == "true", nil
+	case "is_false":
+		if boolVal, ok := actualValue.(bool); ok {
+			return !boolVal, nil
+		}
+		return fmt.Sprintf("%v", actualValue) == "false", nil
+	case "greater_than", "less_than", "greater_than_or_equal", "less_than_or_equal":
+		actualFloat, err1 := strconv.ParseFloat(fmt.Sprintf("%v", actualValue), 64)
+		expectedFloat, err2 := strconv.ParseFloat(fmt.Sprintf("%v", expectedValue), 64)
+		if err1 != nil || err2 != nil {
+			// Not a number yet; keep polling
+			return false, nil
+		}
+		switch conditionType {
+		case "greater_than":
+			return actualFloat > expectedFloat, nil
+		case "less_than":
+			return actualFloat < expectedFloat, nil
+		case "greater_than_or_equal":
+			return actualFloat >= expectedFloat, nil
+		default:
+			return actualFloat <= expectedFloat, nil
+		}
+	default:
+		return false, fmt.Errorf("unsupported condition type: %s", conditionType)
+	}
+}
+
 // ApiLogAction implements logging messages with runtime variable support
 type ApiLogAction struct{}
 
@@ -0,0 +1,281 @@
+// Package clock provides the time:* actions for reading, shifting and waiting for clock times.
+package clock
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Timezones must resolve even on hosts without a zoneinfo database
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+)
+
+// defaultMaxWait bounds time:wait_until so a wrong date can't hold a run forever
+const defaultMaxWait = time.Hour
+
+func init() {
+	automation.RegisterAction("time:now", func() automation.PluginAction { return &NowAction{} })
+	automation.RegisterAction("time:add", func() automation.PluginAction { return &AddAction{} })
+	automation.RegisterAction("time:wait_until", func() automation.PluginAction { return &WaitUntilAction{} })
+}
+
+// Helper function to send success event for time actions
+func sendTimeSuccessEvent(runContext *automation.RunContext, actionType, message string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeLog,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Message:        message,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// Helper function to send error event for time actions
+func sendTimeErrorEvent(runContext *automation.RunContext, actionType, errorMsg string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeError,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Error:          errorMsg,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// formatPresets maps friendly format names to Go layouts; other formats are used as Go layouts
+var formatPresets = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"iso8601":  time.RFC3339,
+	"date":     "2006-01-02",
+	"time":     "15:04:05",
+	"datetime": "2006-01-02 15:04:05",
+}
+
+// loadLocation returns the 'timezone' of the config, UTC when unset
+func loadLocation(actionConfig map[string]interface{}) (*time.Location, error) {
+	timezone, _ := actionConfig["timezone"].(string)
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone '%s'", timezone)
+	}
+	return location, nil
+}
+
+// formatTime renders t with a preset, "unix", "unix_ms" or a Go layout
+func formatTime(t time.Time, format string) string {
+	switch format {
+	case "", "rfc3339", "iso8601":
+		return t.Format(time.RFC3339)
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	if layout, ok := formatPresets[format]; ok {
+		return t.Format(layout)
+	}
+	return t.Format(format)
+}
+
+// parseTime parses value with the given format, falling back to RFC3339 and unix timestamps
+func parseTime(value, format string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if layout, ok := formatPresets[format]; ok {
+		format = layout
+	}
+	if format != "" && format != "unix" && format != "unix_ms" {
+		if t, err := time.ParseInLocation(format, value, location); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(location), nil
+	}
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if format == "unix_ms" || number > 1e12 {
+			return time.UnixMilli(number).In(location), nil
+		}
+		return time.Unix(number, 0).In(location), nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse time '%s'", value)
+}
+
+// NowAction saves the current time, formatted in a timezone
+type NowAction struct{}
+
+func (a *NowAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	location, err := loadLocation(actionConfig)
+	if err != nil {
+		return fmt.Errorf("time:now %w", err)
+	}
+	format, _ := actionConfig["format"].(string)
+
+	value := formatTime(time.Now().In(location), format)
+	saveAs, err := automation.SaveVariable(actionConfig, runContext, value)
+	if err != nil {
+		return fmt.Errorf("time:now %w", err)
+	}
+
+	runContext.Logger.Info("Executing time:now", "value", value, "save_as", saveAs)
+	sendTimeSuccessEvent(runContext, "time:now", fmt.Sprintf("Saved %s as runtime.%s", value, saveAs), time.Since(startTime))
+	return nil
+}
+
+// AddAction shifts a time (now by default) by a duration and/or calendar days, months and years
+type AddAction struct{}
+
+func (a *AddAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	location, err := loadLocation(actionConfig)
+	if err != nil {
+		return fmt.Errorf("time:add %w", err)
+	}
+	format, _ := actionConfig["format"].(string)
+
+	base := time.Now().In(location)
+	if input, _ := actionConfig["input"].(string); input != "" {
+		inputFormat, _ := actionConfig["input_format"].(string)
+		if inputFormat == "" {
+			inputFormat = format
+		}
+		base, err = parseTime(input, inputFormat, location)
+		if err != nil {
+			return fmt.Errorf("time:add %w", err)
+		}
+	}
+
+	years, _ := actionConfig["years"].(float64)
+	months, _ := actionConfig["months"].(float64)
+	days, _ := actionConfig["days"].(float64)
+	result := base.AddDate(int(years), int(months), int(days))
+
+	if durationStr, _ := actionConfig["duration"].(string); durationStr != "" {
+		duration, err := time.ParseDuration(durationStr)
+		if err != nil {
+			return fmt.Errorf("time:add invalid duration '%s': %w", durationStr, err)
+		}
+		result = result.Add(duration)
+	}
+
+	value := formatTime(result, format)
+	saveAs, err := automation.SaveVariable(actionConfig, runContext, value)
+	if err != nil {
+		return fmt.Errorf("time:add %w", err)
+	}
+
+	runContext.Logger.Info("Executing time:add", "value", value, "save_as", saveAs)
+	sendTimeSuccessEvent(runContext, "time:add", fmt.Sprintf("Saved %s as runtime.%s", value, saveAs), time.Since(startTime))
+	return nil
+}
+
+// WaitUntilAction pauses until an absolute clock time, e.g. the minute a booking window opens
+type WaitUntilAction struct{}
+
+func (a *WaitUntilAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	location, err := loadLocation(actionConfig)
+	if err != nil {
+		return fmt.Errorf("time:wait_until %w", err)
+	}
+	target, err := resolveTargetTime(actionConfig, location)
+	if err != nil {
+		return fmt.Errorf("time:wait_until %w", err)
+	}
+
+	// A target that has already passed either continues, fails or waits for the same time tomorrow
+	wait := time.Until(target)
+	if wait < 0 {
+		ifPassed, _ := actionConfig["if_passed"].(string)
+		switch ifPassed {
+		case "fail":
+			err := fmt.Errorf("time:wait_until target %s has already passed", target.Format(time.RFC3339))
+			sendTimeErrorEvent(runContext, "time:wait_until", err.Error(), time.Since(startTime))
+			return err
+		case "next_day":
+			target = target.AddDate(0, 0, 1)
+			wait = time.Until(target)
+		default:
+			sendTimeSuccessEvent(runContext, "time:wait_until", fmt.Sprintf("Target %s already passed, continuing", target.Format(time.RFC3339)), time.Since(startTime))
+			return nil
+		}
+	}
+
+	maxWait := defaultMaxWait
+	if maxWaitMs, ok := actionConfig["max_wait_ms"].(float64); ok && maxWaitMs > 0 {
+		maxWait = time.Duration(maxWaitMs) * time.Millisecond
+	}
+	if wait > maxWait {
+		err := fmt.Errorf("time:wait_until target %s is %s away, more than the maximum wait of %s", target.Format(time.RFC3339), wait.Round(time.Second), maxWait)
+		sendTimeErrorEvent(runContext, "time:wait_until", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	runContext.Logger.Info("Executing time:wait_until", "target", target.Format(time.RFC3339Nano), "wait", wait)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("time:wait_until cancelled")
+	case <-timer.C:
+	}
+
+	sendTimeSuccessEvent(runContext, "time:wait_until", fmt.Sprintf("Reached %s after waiting %s", target.Format(time.RFC3339), wait.Round(time.Millisecond)), time.Since(startTime))
+	return nil
+}
+
+// resolveTargetTime reads 'time' as a clock time today ("15:04" or "15:04:05") or a full date and time
+func resolveTargetTime(actionConfig map[string]interface{}, location *time.Location) (time.Time, error) {
+	value, _ := actionConfig["time"].(string)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("requires a 'time' string in config")
+	}
+
+	for _, layout := range []string{"15:04:05.000", "15:04:05", "15:04"} {
+		if clock, err := time.ParseInLocation(layout, value, location); err == nil {
+			now := time.Now().In(location)
+			return time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), location), nil
+		}
+	}
+
+	format, _ := actionConfig["format"].(string)
+	if format == "" {
+		format = "datetime"
+	}
+	return parseTime(value, format, location)
+}
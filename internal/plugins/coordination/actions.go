@@ -0,0 +1,165 @@
+// Package coordination provides the sync:* actions that let parallel users of a multirun
+// wait for each other.
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+)
+
+// defaultSyncTimeout bounds how long a user waits for the others before failing
+const defaultSyncTimeout = 60 * time.Second
+
+func init() {
+	automation.RegisterAction("sync:barrier", func() automation.PluginAction { return &BarrierAction{} })
+	automation.RegisterAction("sync:signal", func() automation.PluginAction { return &SignalAction{} })
+	automation.RegisterAction("sync:wait", func() automation.PluginAction { return &WaitAction{} })
+}
+
+// Helper function to send success event for sync actions
+func sendSyncSuccessEvent(runContext *automation.RunContext, actionType, message string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeLog,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Message:        message,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// Helper function to send error event for sync actions
+func sendSyncErrorEvent(runContext *automation.RunContext, actionType, errorMsg string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeError,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Error:          errorMsg,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// syncTimeout returns the 'timeout_ms' of the config, defaulting to defaultSyncTimeout
+func syncTimeout(actionConfig map[string]interface{}) time.Duration {
+	if timeoutMs, ok := actionConfig["timeout_ms"].(float64); ok && timeoutMs > 0 {
+		return time.Duration(timeoutMs) * time.Millisecond
+	}
+	return defaultSyncTimeout
+}
+
+// syncName reads the required 'name' of a barrier or signal
+func syncName(actionType string, actionConfig map[string]interface{}, runContext *automation.RunContext) (string, error) {
+	if runContext.Sync == nil {
+		return "", fmt.Errorf("%s is only available in automation runs", actionType)
+	}
+	name, _ := actionConfig["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("%s action requires a 'name' string in config", actionType)
+	}
+	return name, nil
+}
+
+// BarrierAction holds each user until enough users reached the same barrier, then releases them together
+type BarrierAction struct{}
+
+func (a *BarrierAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	name, err := syncName("sync:barrier", actionConfig, runContext)
+	if err != nil {
+		return err
+	}
+	parties, _ := actionConfig["parties"].(float64)
+
+	runContext.Logger.Info("Executing sync:barrier", "name", name, "parties", int(parties))
+
+	waitCtx, cancel := context.WithTimeout(ctx, syncTimeout(actionConfig))
+	defer cancel()
+	position, err := runContext.Sync.Barrier(waitCtx, name, int(parties))
+	if err != nil {
+		err = fmt.Errorf("sync:barrier %w", err)
+		sendSyncErrorEvent(runContext, "sync:barrier", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	if saveAs, _ := actionConfig["save_as"].(string); saveAs != "" {
+		runContext.VariableContext.RuntimeVars[saveAs] = position
+	}
+
+	sendSyncSuccessEvent(runContext, "sync:barrier", fmt.Sprintf("Passed barrier '%s' (arrived #%d)", name, position), time.Since(startTime))
+	return nil
+}
+
+// SignalAction raises a named signal for users waiting on it
+type SignalAction struct{}
+
+func (a *SignalAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	name, err := syncName("sync:signal", actionConfig, runContext)
+	if err != nil {
+		return err
+	}
+
+	runContext.Logger.Info("Executing sync:signal", "name", name)
+
+	message := fmt.Sprintf("Raised signal '%s'", name)
+	if !runContext.Sync.Signal(name) {
+		message = fmt.Sprintf("Signal '%s' was already raised", name)
+	}
+	sendSyncSuccessEvent(runContext, "sync:signal", message, time.Since(startTime))
+	return nil
+}
+
+// WaitAction holds a user until another user raises a named signal
+type WaitAction struct{}
+
+func (a *WaitAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	name, err := syncName("sync:wait", actionConfig, runContext)
+	if err != nil {
+		return err
+	}
+
+	runContext.Logger.Info("Executing sync:wait", "name", name)
+
+	waitCtx, cancel := context.WithTimeout(ctx, syncTimeout(actionConfig))
+	defer cancel()
+	if err := runContext.Sync.Wait(waitCtx, name); err != nil {
+		err = fmt.Errorf("sync:wait %w", err)
+		sendSyncErrorEvent(runContext, "sync:wait", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	sendSyncSuccessEvent(runContext, "sync:wait", fmt.Sprintf("Received signal '%s'", name), time.Since(startTime))
+	return nil
+}
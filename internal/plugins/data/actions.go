@@ -0,0 +1,305 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+)
+
+// maxGeneratedRecords caps data:generate so a typo can't exhaust the worker's memory
+const maxGeneratedRecords = 10000
+
+func init() {
+	automation.RegisterAction("data:generate", func() automation.PluginAction { return &GenerateAction{} })
+	automation.RegisterAction("data:dataset", func() automation.PluginAction { return &DatasetAction{} })
+}
+
+// Helper function to send success event for data actions
+func sendDataSuccessEvent(runContext *automation.RunContext, actionType, message string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeLog,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Message:        message,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// Helper function to send error event for data actions
+func sendDataErrorEvent(runContext *automation.RunContext, actionType, errorMsg string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeError,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Error:          errorMsg,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// datasetField describes one column of a generated dataset
+type datasetField struct {
+	name  string
+	kind  string // faker method (e.g. "email"), "function.<name>", "sequence" or "static"
+	value string // value for "static" fields
+}
+
+// GenerateAction generates a dataset of fake records, saves it as a runtime variable and
+// uploads it as a CSV artifact
+type GenerateAction struct{}
+
+func (a *GenerateAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	count, _ := actionConfig["count"].(float64)
+	if count < 1 {
+		return fmt.Errorf("data:generate action requires a positive 'count' in config")
+	}
+	if count > maxGeneratedRecords {
+		return fmt.Errorf("data:generate can generate at most %d records", maxGeneratedRecords)
+	}
+	saveAs, _ := actionConfig["save_as"].(string)
+	if saveAs == "" {
+		return fmt.Errorf("data:generate action requires a 'save_as' string in config")
+	}
+
+	fields, err := parseDatasetFields(actionConfig)
+	if err != nil {
+		return err
+	}
+
+	runContext.Logger.Info("Executing data:generate", "count", int(count), "fields", len(fields), "save_as", saveAs)
+
+	records := make([]interface{}, int(count))
+	for i := range records {
+		record := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			value, err := generateFieldValue(field, i, runContext)
+			if err != nil {
+				sendDataErrorEvent(runContext, "data:generate", err.Error(), time.Since(startTime))
+				return err
+			}
+			record[field.name] = value
+		}
+		records[i] = record
+	}
+
+	dataset := map[string]interface{}{
+		"count":   len(records),
+		"records": records,
+		// The record for this user, so parallel loops can each work with their own row
+		"row": records[runContext.LoopIndex%len(records)],
+	}
+
+	uploadCSV := true
+	if upload, ok := actionConfig["upload_csv"].(bool); ok {
+		uploadCSV = upload
+	}
+	if uploadCSV && runContext.StorageService != nil {
+		url, err := uploadDatasetCSV(ctx, actionConfig, saveAs, fields, records, runContext)
+		if err != nil {
+			sendDataErrorEvent(runContext, "data:generate", err.Error(), time.Since(startTime))
+			return err
+		}
+		dataset["csv_url"] = url
+	}
+
+	automation.SaveVariable(actionConfig, runContext, dataset)
+
+	sendDataSuccessEvent(runContext, "data:generate", fmt.Sprintf("Generated %d records, saved as runtime.%s", len(records), saveAs), time.Since(startTime))
+	return nil
+}
+
+// DatasetAction loads the records of a project dataset, kept in sync with its external source,
+// and saves them as a runtime variable shaped like data:generate's
+type DatasetAction struct{}
+
+func (a *DatasetAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	name, _ := actionConfig["dataset"].(string)
+	if name == "" {
+		return fmt.Errorf("data:dataset action requires a 'dataset' name in config")
+	}
+	saveAs, _ := actionConfig["save_as"].(string)
+	if saveAs == "" {
+		return fmt.Errorf("data:dataset action requires a 'save_as' string in config")
+	}
+	// 0 reads the current version; a pinned version keeps a run reproducible across refreshes
+	version, _ := actionConfig["version"].(float64)
+
+	runContext.Logger.Info("Executing data:dataset", "dataset", name, "version", int(version), "save_as", saveAs)
+
+	records, loadedVersion, err := runContext.Runner.GetDatasetRecords(ctx, runContext.VariableContext.ProjectID, name, int(version))
+	if err == nil && len(records) == 0 {
+		err = fmt.Errorf("dataset '%s' version %d has no records", name, loadedVersion)
+	}
+	if err != nil {
+		sendDataErrorEvent(runContext, "data:dataset", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	items := make([]interface{}, len(records))
+	for i, record := range records {
+		items[i] = record
+	}
+	dataset := map[string]interface{}{
+		"name":    name,
+		"version": loadedVersion,
+		"count":   len(items),
+		"records": items,
+		// The record for this user, so parallel loops can each work with their own row
+		"row": items[runContext.LoopIndex%len(items)],
+	}
+
+	automation.SaveVariable(actionConfig, runContext, dataset)
+
+	sendDataSuccessEvent(runContext, "data:dataset", fmt.Sprintf("Loaded %d records of dataset '%s' version %d, saved as runtime.%s", len(items), name, loadedVersion, saveAs), time.Since(startTime))
+	return nil
+}
+
+// parseDatasetFields reads the ordered 'fields' array ({name, type, value}) from the config
+func parseDatasetFields(actionConfig map[string]interface{}) ([]datasetField, error) {
+	fieldsInterface, _ := actionConfig["fields"].([]interface{})
+	if len(fieldsInterface) == 0 {
+		return nil, fmt.Errorf("data:generate action requires a non-empty 'fields' array in config")
+	}
+
+	seen := make(map[string]bool)
+	fields := make([]datasetField, 0, len(fieldsInterface))
+	for i, fieldInterface := range fieldsInterface {
+		fieldMap, ok := fieldInterface.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("data:generate field %d must be an object", i+1)
+		}
+		name, _ := fieldMap["name"].(string)
+		kind, _ := fieldMap["type"].(string)
+		if name == "" || kind == "" {
+			return nil, fmt.Errorf("data:generate field %d requires a 'name' and a 'type'", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("data:generate field '%s' is defined more than once", name)
+		}
+		seen[name] = true
+
+		value, _ := fieldMap["value"].(string)
+		fields = append(fields, datasetField{name: name, kind: strings.TrimPrefix(kind, "faker."), value: value})
+	}
+	return fields, nil
+}
+
+// generateFieldValue produces the value of a field for the record at index
+func generateFieldValue(field datasetField, index int, runContext *automation.RunContext) (string, error) {
+	switch field.kind {
+	case "sequence":
+		return strconv.Itoa(index + 1), nil
+	case "static":
+		return field.value, nil
+	}
+
+	// Faker methods and functions are generated through the regular variable resolver
+	placeholder := "{{faker." + field.kind + "}}"
+	if strings.HasPrefix(field.kind, "function.") {
+		placeholder = "{{" + field.kind + "}}"
+	}
+	value, err := runContext.Runner.ResolveVariablesInString(placeholder, runContext.VariableContext, runContext.AutomationConfig)
+	if err != nil {
+		return "", err
+	}
+	if value == placeholder {
+		return "", fmt.Errorf("data:generate field '%s' has unknown type '%s'", field.name, field.kind)
+	}
+	return value, nil
+}
+
+// uploadDatasetCSV writes the records as CSV to storage and reports it as a dataset output file
+func uploadDatasetCSV(ctx context.Context, actionConfig map[string]interface{}, saveAs string, fields []datasetField, records []interface{}, runContext *automation.RunContext) (string, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.name
+	}
+	writer.Write(header)
+	for _, recordInterface := range records {
+		record := recordInterface.(map[string]interface{})
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = fmt.Sprintf("%v", record[field.name])
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write dataset CSV: %w", err)
+	}
+
+	pathTemplate, _ := actionConfig["csv_path"].(string)
+	if pathTemplate == "" {
+		pathTemplate = "datasets/{{runId}}/{{loopIndex}}-" + saveAs
+	}
+	key := automation.RenderArtifactPath(strings.TrimSuffix(pathTemplate, ".csv"), runContext, ".csv", true)
+
+	url, err := runContext.StorageService.UploadFile(ctx, key, bytes.NewReader(buffer.Bytes()), "text/csv")
+	if err != nil {
+		return "", fmt.Errorf("failed to upload dataset CSV: %w", err)
+	}
+
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeOutputFile,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     "data:generate",
+			OutputFile:     url,
+			OutputFileKind: automation.OutputFileKindDataset,
+			StorageKey:     key,
+			ContentType:    "text/csv",
+			Size:           int64(buffer.Len()),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+
+	return url, nil
+}
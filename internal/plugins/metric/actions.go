@@ -0,0 +1,109 @@
+// Package metric provides the metric:* actions for timing transactions that span several actions
+// or steps, such as logging in and reaching the dashboard.
+package metric
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+)
+
+func init() {
+	automation.RegisterAction("metric:start_timer", func() automation.PluginAction { return &StartTimerAction{} })
+	automation.RegisterAction("metric:stop_timer", func() automation.PluginAction { return &StopTimerAction{} })
+}
+
+// Helper function to send success event for metric actions
+func sendMetricSuccessEvent(runContext *automation.RunContext, actionType, message string, duration time.Duration, data map[string]interface{}) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeLog,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Message:        message,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+			Data:           data,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// timerName reads the required 'name' of a timer
+func timerName(actionType string, actionConfig map[string]interface{}, runContext *automation.RunContext) (string, error) {
+	if runContext.Timers == nil {
+		return "", fmt.Errorf("%s is only available in automation runs", actionType)
+	}
+	name, _ := actionConfig["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("%s action requires a 'name' string in config", actionType)
+	}
+	return name, nil
+}
+
+// StartTimerAction starts timing a named transaction for the current user
+type StartTimerAction struct{}
+
+func (a *StartTimerAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	name, err := timerName("metric:start_timer", actionConfig, runContext)
+	if err != nil {
+		return err
+	}
+
+	runContext.Logger.Info("Executing metric:start_timer", "name", name)
+
+	message := fmt.Sprintf("Started timer '%s'", name)
+	if _, running := runContext.Timers[name]; running {
+		message = fmt.Sprintf("Restarted timer '%s'", name)
+	}
+	runContext.Timers[name] = startTime
+
+	sendMetricSuccessEvent(runContext, "metric:start_timer", message, time.Since(startTime), nil)
+	return nil
+}
+
+// StopTimerAction stops a named transaction timer and reports its duration, which run reports
+// aggregate across users
+type StopTimerAction struct{}
+
+func (a *StopTimerAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	name, err := timerName("metric:stop_timer", actionConfig, runContext)
+	if err != nil {
+		return err
+	}
+
+	runContext.Logger.Info("Executing metric:stop_timer", "name", name)
+
+	started, running := runContext.Timers[name]
+	if !running {
+		return fmt.Errorf("metric:stop_timer timer '%s' was not started", name)
+	}
+	delete(runContext.Timers, name)
+	elapsed := startTime.Sub(started)
+
+	if saveAs, _ := actionConfig["save_as"].(string); saveAs != "" {
+		automation.SaveVariable(actionConfig, runContext, elapsed.Milliseconds())
+	}
+
+	sendMetricSuccessEvent(runContext, "metric:stop_timer", fmt.Sprintf("Timer '%s' took %s", name, elapsed.Round(time.Millisecond)),
+		time.Since(startTime), map[string]interface{}{
+			automation.TimerNameKey:     name,
+			automation.TimerDurationKey: elapsed.Milliseconds(),
+		})
+	return nil
+}
@@ -3,8 +3,14 @@ package playwright
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"mime"
+	"net/http"
+	"os"
+	"path"
 	"strings"
 	"time"
 
@@ -24,13 +30,16 @@ func init() {
 	automation.RegisterAction("playwright:check", func() automation.PluginAction { return &CheckAction{} })
 	automation.RegisterAction("playwright:uncheck", func() automation.PluginAction { return &UncheckAction{} })
 	automation.RegisterAction("playwright:select_option", func() automation.PluginAction { return &SelectOptionAction{} })
+	automation.RegisterAction("playwright:fill_form", func() automation.PluginAction { return &FillFormAction{} })
 	automation.RegisterAction("playwright:wait_for_selector", func() automation.PluginAction { return &WaitForSelectorAction{} })
 	automation.RegisterAction("playwright:wait_for_timeout", func() automation.PluginAction { return &WaitForTimeoutAction{} })
 	automation.RegisterAction("playwright:screenshot", func() automation.PluginAction { return &ScreenshotAction{} })
+	automation.RegisterAction("playwright:download", func() automation.PluginAction { return &DownloadAction{} })
 	automation.RegisterAction("playwright:evaluate", func() automation.PluginAction { return &EvaluateAction{} })
 	automation.RegisterAction("playwright:hover", func() automation.PluginAction { return &HoverAction{} })
 	automation.RegisterAction("playwright:scroll", func() automation.PluginAction { return &ScrollAction{} })
 	automation.RegisterAction("playwright:get_text", func() automation.PluginAction { return &GetTextAction{} })
+	automation.RegisterAction("playwright:assert_text", func() automation.PluginAction { return &AssertTextAction{} })
 	automation.RegisterAction("playwright:get_attribute", func() automation.PluginAction { return &GetAttributeAction{} })
 	automation.RegisterAction("playwright:wait_for_load_state", func() automation.PluginAction { return &WaitForLoadStateAction{} })
 	automation.RegisterAction("playwright:set_viewport", func() automation.PluginAction { return &SetViewportAction{} })
@@ -40,6 +49,7 @@ func init() {
 	automation.RegisterAction("playwright:if_else", func() automation.PluginAction { return &IfElseAction{} })
 	automation.RegisterAction("playwright:log", func() automation.PluginAction { return &LogAction{} })
 	automation.RegisterAction("playwright:loop_until", func() automation.PluginAction { return &LoopUntilAction{} })
+	automation.RegisterAction("playwright:pause", func() automation.PluginAction { return &PauseAction{} })
 }
 
 // Helper functions for loop index conditions
@@ -133,6 +143,31 @@ func sendErrorEvent(runContext *automation.RunContext, actionType, errorMsg stri
 	}
 }
 
+// sendViolationEvent reports a script sandbox violation as an error event carrying the violation
+func sendViolationEvent(runContext *automation.RunContext, actionType string, violation *automation.ScriptViolation, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeError,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Error:          violation.Error(),
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+			Data:           violation.EventData(),
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
 // BaseAction provides common validation for selector-based actions.
 type BaseAction struct{}
 
@@ -429,6 +464,138 @@ func (a *SelectOptionAction) Execute(ctx context.Context, actionConfig map[strin
 	return nil
 }
 
+// FillFormAction fills several form fields, located by their label or aria-label, using the
+// keyboard: text fields are typed into, checkboxes and radios toggled with Space
+type FillFormAction struct{}
+
+func (a *FillFormAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+	fields, err := formFields(actionConfig)
+	if err != nil {
+		return err
+	}
+
+	exact, _ := actionConfig["exact"].(bool)
+	skipMissing, _ := actionConfig["skip_missing"].(bool)
+	typeOptions := playwright.LocatorPressSequentiallyOptions{}
+	if delay, ok := actionConfig["type_delay_ms"].(float64); ok && delay > 0 {
+		typeOptions.Delay = playwright.Float(delay)
+	}
+	var timeout *float64
+	if t, ok := actionConfig["timeout"].(float64); ok && t > 0 {
+		timeout = playwright.Float(t)
+	}
+
+	runContext.Logger.Info("Executing playwright:fill_form", "fields", len(fields))
+
+	filled := 0
+	for _, field := range fields {
+		label, value := field.label, field.value
+		locator := runContext.PlaywrightPage.GetByLabel(label, playwright.PageGetByLabelOptions{Exact: playwright.Bool(exact)}).First()
+
+		if err := locator.WaitFor(playwright.LocatorWaitForOptions{State: playwright.WaitForSelectorStateAttached, Timeout: timeout}); err != nil {
+			if skipMissing {
+				runContext.Logger.Warn("Skipping form field with no matching label", "label", label)
+				continue
+			}
+			err = fmt.Errorf("no form field labelled '%s': %w", label, err)
+			sendErrorEvent(runContext, "playwright:fill_form", err.Error(), time.Since(startTime))
+			return err
+		}
+
+		if err := fillFormField(locator, value, typeOptions, timeout); err != nil {
+			err = fmt.Errorf("failed to fill form field '%s': %w", label, err)
+			sendErrorEvent(runContext, "playwright:fill_form", err.Error(), time.Since(startTime))
+			return err
+		}
+		filled++
+	}
+
+	sendSuccessEvent(runContext, "playwright:fill_form", fmt.Sprintf("Successfully filled %d of %d form fields", filled, len(fields)), time.Since(startTime))
+	return nil
+}
+
+// formField is a field of playwright:fill_form, found by its label
+type formField struct {
+	label string
+	value string
+}
+
+// formFields reads the 'fields' list in the order the author gave it, since later fields may
+// depend on earlier ones (e.g. a state list filled in once a country is chosen)
+func formFields(actionConfig map[string]interface{}) ([]formField, error) {
+	items, ok := actionConfig["fields"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("playwright:fill_form action requires a 'fields' list of {label, value} objects in config")
+	}
+
+	fields := make([]formField, 0, len(items))
+	for i, item := range items {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("playwright:fill_form field %d must be an object with 'label' and 'value'", i+1)
+		}
+		label, _ := entry["label"].(string)
+		if strings.TrimSpace(label) == "" {
+			return nil, fmt.Errorf("playwright:fill_form field %d requires a 'label'", i+1)
+		}
+		value := ""
+		if entry["value"] != nil {
+			value = fmt.Sprintf("%v", entry["value"])
+		}
+		fields = append(fields, formField{label: label, value: value})
+	}
+	return fields, nil
+}
+
+// fillFormField sets a single field according to its element type
+func fillFormField(locator playwright.Locator, value string, typeOptions playwright.LocatorPressSequentiallyOptions, timeout *float64) error {
+	kind, err := locator.Evaluate(`el => {
+		const tag = el.tagName.toLowerCase();
+		if (tag === "select") return "select";
+		const type = (el.getAttribute("type") || "").toLowerCase();
+		if (tag === "input" && (type === "checkbox" || type === "radio")) return "toggle";
+		if (el.getAttribute("role") === "checkbox" || el.getAttribute("role") === "switch") return "toggle";
+		return "text";
+	}`, nil)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "select":
+		// Native selects don't respond consistently to typing, so pick the option by label, then value
+		if _, err := locator.SelectOption(playwright.SelectOptionValues{Labels: &[]string{value}}, playwright.LocatorSelectOptionOptions{Timeout: timeout}); err == nil {
+			return nil
+		}
+		_, err := locator.SelectOption(playwright.SelectOptionValues{Values: &[]string{value}}, playwright.LocatorSelectOptionOptions{Timeout: timeout})
+		return err
+	case "toggle":
+		want := false
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true", "yes", "on", "1", "checked":
+			want = true
+		}
+		checked, err := locator.IsChecked(playwright.LocatorIsCheckedOptions{Timeout: timeout})
+		if err != nil {
+			return err
+		}
+		if checked == want {
+			return nil
+		}
+		if err := locator.Focus(playwright.LocatorFocusOptions{Timeout: timeout}); err != nil {
+			return err
+		}
+		return locator.Press("Space", playwright.LocatorPressOptions{Timeout: timeout})
+	default:
+		if err := locator.Clear(playwright.LocatorClearOptions{Timeout: timeout}); err != nil {
+			return err
+		}
+		typeOptions.Timeout = timeout
+		return locator.PressSequentially(value, typeOptions)
+	}
+}
+
 // WaitForSelectorAction implements waiting for elements
 type WaitForSelectorAction struct {
 	BaseAction
@@ -483,6 +650,32 @@ func (a *WaitForTimeoutAction) Execute(ctx context.Context, actionConfig map[str
 	return nil
 }
 
+// PauseAction holds the user on its page so expressions can be evaluated on it from the run's
+// console. It does nothing unless the debug console is enabled.
+type PauseAction struct{}
+
+func (a *PauseAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+	timeoutMs, _ := actionConfig["timeout_ms"].(float64)
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+
+	runContext.Logger.Info("Executing playwright:pause", "timeout_ms", timeoutMs)
+
+	err := runContext.Runner.PauseForConsole(ctx, runContext, timeout)
+	duration := time.Since(startTime)
+	if errors.Is(err, automation.ErrConsoleDisabled) {
+		sendSuccessEvent(runContext, "playwright:pause", "Debug console is disabled, continuing without pausing", duration)
+		return nil
+	}
+	if err != nil {
+		sendErrorEvent(runContext, "playwright:pause", err.Error(), duration)
+		return err
+	}
+
+	sendSuccessEvent(runContext, "playwright:pause", fmt.Sprintf("Resumed after %s", duration.Round(time.Second)), duration)
+	return nil
+}
+
 // ScreenshotAction implements taking screenshots
 type ScreenshotAction struct{}
 
@@ -517,27 +710,47 @@ func (a *ScreenshotAction) Execute(ctx context.Context, actionConfig map[string]
 		return fmt.Errorf("failed to take screenshot: %w", err)
 	}
 
+	// Highlight the element the screenshot is about, so galleries explain themselves
+	if selector, _ := actionConfig["selector"].(string); selector != "" {
+		quality, _ := actionConfig["quality"].(float64)
+		annotated, err := automation.AnnotateScreenshot(runContext.PlaywrightPage, screenshotBytes, selector, *options.FullPage, int(quality))
+		if err != nil {
+			runContext.Logger.Warn("Screenshot saved without highlight", "selector", selector, "error", err)
+		} else {
+			screenshotBytes = annotated
+		}
+	}
+
 	// Check if we should upload to R2
 	uploadToR2, _ := actionConfig["upload_to_r2"].(bool)
 	if uploadToR2 {
-		r2Key, ok := actionConfig["r2_key"].(string)
-		if !ok || r2Key == "" {
-			errMsg := "playwright:screenshot with upload_to_r2 requires an 'r2_key' string in config"
-			sendErrorEvent(runContext, "playwright:screenshot", errMsg, duration)
-			return fmt.Errorf(errMsg)
-		}
-
 		// Determine content type
 		contentType := "image/png" // Default
+		extension := "png"
 		if format, ok := actionConfig["format"].(string); ok {
 			switch format {
 			case "jpeg":
 				contentType = "image/jpeg"
+				extension = "jpeg"
 			case "png":
 				contentType = "image/png"
 			}
 		}
 
+		// r2_key is a path template; fall back to the automation's screenshot path
+		r2Key, _ := actionConfig["r2_key"].(string)
+		if r2Key == "" && runContext.AutomationConfig != nil {
+			r2Key = runContext.AutomationConfig.Screenshots.Path
+		}
+		if r2Key == "" {
+			r2Key = automation.DefaultScreenshotPath
+		}
+		uniqueSuffix := true
+		if unique, ok := actionConfig["unique_suffix"].(bool); ok {
+			uniqueSuffix = unique
+		}
+		r2Key = automation.RenderArtifactPath(r2Key, runContext, extension, uniqueSuffix)
+
 		// Upload to R2
 		reader := bytes.NewReader(screenshotBytes)
 		_, err := runContext.StorageService.UploadFile(ctx, r2Key, reader, contentType)
@@ -564,6 +777,10 @@ func (a *ScreenshotAction) Execute(ctx context.Context, actionConfig map[string]
 				StepName:       runContext.StepName,
 				ActionType:     "playwright:screenshot",
 				OutputFile:     publicURL,
+				OutputFileKind: automation.OutputFileKindScreenshot,
+				StorageKey:     r2Key,
+				ContentType:    contentType,
+				Size:           int64(len(screenshotBytes)),
 				Duration:       duration.Milliseconds(),
 				LoopIndex:      runContext.LoopIndex,
 				LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
@@ -581,6 +798,115 @@ func (a *ScreenshotAction) Execute(ctx context.Context, actionConfig map[string]
 	return nil
 }
 
+// maxDownloadBytes bounds the files playwright:download stores
+const maxDownloadBytes = 100 << 20
+
+// DownloadAction clicks an element starting a file download and stores the downloaded file as
+// an output file of the run, named as the site suggested, e.g. to compare an export against an
+// artifact fixture
+type DownloadAction struct {
+	BaseAction
+}
+
+func (a *DownloadAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+	selector, err := a.getSelector(actionConfig)
+	if err != nil {
+		return fmt.Errorf("playwright:download %w", err)
+	}
+
+	runContext.Logger.Info("Executing playwright:download", "selector", selector)
+
+	options := playwright.PageExpectDownloadOptions{}
+	if timeout, ok := actionConfig["timeout"].(float64); ok && timeout > 0 {
+		options.Timeout = playwright.Float(timeout)
+	}
+	download, err := runContext.PlaywrightPage.ExpectDownload(func() error {
+		return runContext.PlaywrightPage.Locator(selector).First().Click()
+	}, options)
+	if err != nil {
+		sendErrorEvent(runContext, "playwright:download", fmt.Sprintf("no download started: %v", err), time.Since(startTime))
+		return fmt.Errorf("no download started: %w", err)
+	}
+	defer download.Delete()
+
+	data, err := readDownload(download)
+	duration := time.Since(startTime)
+	if err != nil {
+		sendErrorEvent(runContext, "playwright:download", err.Error(), duration)
+		return err
+	}
+
+	fileName := automation.DownloadFileName(download.SuggestedFilename())
+	contentType := mime.TypeByExtension(path.Ext(fileName))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	// The file keeps its name, so a directory per download keeps parallel users apart
+	dir, _ := actionConfig["path"].(string)
+	if dir == "" {
+		dir = "downloads/{{runId}}/{{loopIndex}}-{{unique}}"
+	}
+	key := automation.RenderArtifactPath(strings.TrimSuffix(dir, "/")+"/"+fileName, runContext, "", false)
+
+	publicURL, err := runContext.StorageService.UploadFile(ctx, key, bytes.NewReader(data), contentType)
+	if err != nil {
+		sendErrorEvent(runContext, "playwright:download", fmt.Sprintf("failed to upload download: %v", err), duration)
+		return fmt.Errorf("failed to upload download: %w", err)
+	}
+
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			Type:           automation.RunEventTypeOutputFile,
+			Timestamp:      time.Now(),
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionName:     runContext.ActionName,
+			ParentActionID: runContext.ParentActionID,
+			StepName:       runContext.StepName,
+			ActionType:     "playwright:download",
+			OutputFile:     publicURL,
+			OutputFileKind: automation.OutputFileKindDownload,
+			StorageKey:     key,
+			ContentType:    contentType,
+			Size:           int64(len(data)),
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+
+	sendSuccessEvent(runContext, "playwright:download", fmt.Sprintf("Downloaded %s (%d bytes)", fileName, len(data)), duration)
+	return nil
+}
+
+// readDownload reads a finished download of at most maxDownloadBytes
+func readDownload(download playwright.Download) ([]byte, error) {
+	filePath, err := download.Path()
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxDownloadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download: %w", err)
+	}
+	if len(data) > maxDownloadBytes {
+		return nil, fmt.Errorf("download is larger than %d MB", maxDownloadBytes>>20)
+	}
+	return data, nil
+}
+
 // EvaluateAction implements executing JavaScript
 type EvaluateAction struct{}
 
@@ -593,9 +919,14 @@ func (a *EvaluateAction) Execute(ctx context.Context, actionConfig map[string]in
 
 	runContext.Logger.Info("Executing playwright:evaluate", "expression", expression)
 
-	_, err := runContext.PlaywrightPage.Evaluate(expression)
+	_, err := automation.EvaluateScript(ctx, runContext.PlaywrightPage, expression, runContext.ScriptSandbox)
 	duration := time.Since(startTime)
 
+	var violation *automation.ScriptViolation
+	if errors.As(err, &violation) {
+		sendViolationEvent(runContext, "playwright:evaluate", violation, duration)
+		return err
+	}
 	if err != nil {
 		sendErrorEvent(runContext, "playwright:evaluate", err.Error(), duration)
 		return err
@@ -694,6 +1025,88 @@ func (a *GetTextAction) Execute(ctx context.Context, actionConfig map[string]int
 	return nil
 }
 
+// AssertTextAction fails the action when an element's text doesn't match. The expected text is
+// given literally, or as a message key resolved in the project's translation catalog for the
+// run's locale, so one automation can check UI copy in every supported language.
+type AssertTextAction struct {
+	BaseAction
+}
+
+func (a *AssertTextAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+	selector, err := a.getSelector(actionConfig)
+	if err != nil {
+		return fmt.Errorf("playwright:assert_text %w", err)
+	}
+
+	expected, _ := actionConfig["expected"].(string)
+	messageKey, _ := actionConfig["message_key"].(string)
+	if (expected == "") == (messageKey == "") {
+		return fmt.Errorf("playwright:assert_text action requires either an 'expected' string or a 'message_key' string in config")
+	}
+
+	match := "equals"
+	if m, ok := actionConfig["match"].(string); ok && m != "" {
+		match = m
+	}
+	if match != "equals" && match != "contains" {
+		return fmt.Errorf("playwright:assert_text 'match' must be equals or contains, got '%s'", match)
+	}
+	ignoreCase, _ := actionConfig["ignore_case"].(bool)
+
+	locale := runContext.VariableContext.Locale
+	description := fmt.Sprintf("%q", expected)
+	if messageKey != "" {
+		expected, err = runContext.Runner.ResolveMessage(ctx, runContext.VariableContext.ProjectID, locale, messageKey)
+		if err != nil {
+			sendErrorEvent(runContext, "playwright:assert_text", err.Error(), time.Since(startTime))
+			return err
+		}
+		params := make(map[string]string)
+		if rawParams, ok := actionConfig["params"].(map[string]interface{}); ok {
+			for name, value := range rawParams {
+				params[name] = fmt.Sprintf("%v", value)
+			}
+		}
+		expected = automation.FormatMessage(expected, params)
+		description = fmt.Sprintf("%q (%s in %s)", expected, messageKey, locale)
+	}
+
+	runContext.Logger.Info("Executing playwright:assert_text", "selector", selector, "message_key", messageKey, "locale", locale, "match", match)
+
+	options := playwright.LocatorInnerTextOptions{}
+	if timeout, ok := actionConfig["timeout"].(float64); ok && timeout > 0 {
+		options.Timeout = playwright.Float(timeout)
+	}
+	text, err := runContext.PlaywrightPage.Locator(selector).First().InnerText(options)
+	duration := time.Since(startTime)
+	if err != nil {
+		sendErrorEvent(runContext, "playwright:assert_text", err.Error(), duration)
+		return err
+	}
+
+	// Layout whitespace differs between browsers and translations, so it isn't compared
+	actual := strings.Join(strings.Fields(text), " ")
+	want := strings.Join(strings.Fields(expected), " ")
+	if ignoreCase {
+		actual = strings.ToLower(actual)
+		want = strings.ToLower(want)
+	}
+
+	matched, verb := actual == want, "equal"
+	if match == "contains" {
+		matched, verb = strings.Contains(actual, want), "contain"
+	}
+	if !matched {
+		errorMsg := fmt.Sprintf("text of %s should %s %s, got %q", selector, verb, description, text)
+		sendErrorEvent(runContext, "playwright:assert_text", errorMsg, duration)
+		return errors.New(errorMsg)
+	}
+
+	sendSuccessEvent(runContext, "playwright:assert_text", fmt.Sprintf("Text of %s matches %s", selector, description), duration)
+	return nil
+}
+
 // GetAttributeAction implements getting element attributes
 type GetAttributeAction struct {
 	BaseAction
@@ -1248,7 +1661,7 @@ func (a *LoopUntilAction) Execute(ctx context.Context, actionConfig map[string]a
 			message := fmt.Sprintf("Loop force stopped: %s", forceStopReason)
 			if failOnForceStop {
 				runContext.Logger.Error("Loop force stopped", "reason", forceStopReason, "loops_completed", loopCount)
-				executionError = fmt.Errorf(message)
+				executionError = errors.New(message)
 				return executionError
 			} else {
 				runContext.Logger.Warn("Loop force stopped", "reason", forceStopReason, "loops_completed", loopCount)
@@ -0,0 +1,175 @@
+// Package pool provides the pool:* actions that lease test accounts from a project's account
+// pools, so parallel users of a run never log into the same account.
+package pool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+)
+
+const (
+	// defaultLeaseWait bounds how long a user waits for an account to be released before failing
+	defaultLeaseWait = 30 * time.Second
+	// leasePollInterval is how often a waiting user retries the lease
+	leasePollInterval = time.Second
+)
+
+func init() {
+	automation.RegisterAction("pool:lease", func() automation.PluginAction { return &LeaseAction{} })
+	automation.RegisterAction("pool:release", func() automation.PluginAction { return &ReleaseAction{} })
+}
+
+// Helper function to send success event for pool actions
+func sendPoolSuccessEvent(runContext *automation.RunContext, actionType, message string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeLog,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Message:        message,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// Helper function to send error event for pool actions
+func sendPoolErrorEvent(runContext *automation.RunContext, actionType, errorMsg string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeError,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Error:          errorMsg,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// LeaseAction leases an account from a pool for this user and saves it as a runtime variable.
+// When every account is leased it waits for one to be released. Leases still held when the
+// user finishes are released automatically.
+type LeaseAction struct{}
+
+func (a *LeaseAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	poolName, _ := actionConfig["pool"].(string)
+	if poolName == "" {
+		return fmt.Errorf("pool:lease action requires a 'pool' name in config")
+	}
+	saveAs, _ := actionConfig["save_as"].(string)
+	if saveAs == "" {
+		return fmt.Errorf("pool:lease action requires a 'save_as' string in config")
+	}
+	environment, _ := actionConfig["environment"].(string)
+	if environment == "" {
+		environment = automation.DefaultAccountPoolEnvironment
+	}
+	wait := defaultLeaseWait
+	if waitMs, ok := actionConfig["wait_timeout_ms"].(float64); ok && waitMs >= 0 {
+		wait = time.Duration(waitMs) * time.Millisecond
+	}
+
+	runContext.Logger.Info("Executing pool:lease", "pool", poolName, "environment", environment, "save_as", saveAs)
+
+	deadline := time.Now().Add(wait)
+	for {
+		account, pool, err := runContext.Runner.LeaseAccount(ctx, runContext.VariableContext.ProjectID, environment, poolName, runContext.VariableContext.RunID, runContext.LoopIndex)
+		if err != nil {
+			sendPoolErrorEvent(runContext, "pool:lease", err.Error(), time.Since(startTime))
+			return err
+		}
+
+		if account != nil {
+			value := make(map[string]interface{}, len(account.Credentials)+5)
+			// Credentials are exposed directly, e.g. {{runtime.account.username}}
+			for key, credential := range account.Credentials {
+				value[key] = credential
+			}
+			value["id"] = account.ID
+			value["label"] = account.Label
+			value["pool"] = pool.Name
+			value["environment"] = pool.Environment
+			value["credentials"] = account.Credentials
+			runContext.VariableContext.RuntimeVars[saveAs] = value
+
+			sendPoolSuccessEvent(runContext, "pool:lease", fmt.Sprintf("Leased account '%s' from pool '%s' (%s), saved as runtime.%s", account.Label, pool.Name, pool.Environment, saveAs), time.Since(startTime))
+			return nil
+		}
+
+		if !time.Now().Before(deadline) {
+			err := fmt.Errorf("pool:lease found no available account in pool '%s' (%s) after %s", poolName, environment, wait)
+			sendPoolErrorEvent(runContext, "pool:lease", err.Error(), time.Since(startTime))
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("pool:lease cancelled")
+		case <-time.After(leasePollInterval):
+		}
+	}
+}
+
+// ReleaseAction returns a leased account to its pool before the user finishes, e.g. so another
+// user can lease it. Accounts left in an unknown state can be released as dirty.
+type ReleaseAction struct{}
+
+func (a *ReleaseAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	account, _ := actionConfig["account"].(string)
+	if account == "" {
+		return fmt.Errorf("pool:release action requires an 'account' in config: the save_as name of a pool:lease, or an account ID")
+	}
+	dirty, _ := actionConfig["dirty"].(bool)
+
+	// The account is usually the variable a pool:lease saved
+	accountID := account
+	leased, isVariable := runContext.VariableContext.RuntimeVars[account].(map[string]interface{})
+	if isVariable {
+		accountID, _ = leased["id"].(string)
+	}
+
+	runContext.Logger.Info("Executing pool:release", "account", accountID, "dirty", dirty)
+
+	if err := runContext.Runner.ReleaseAccount(ctx, accountID, runContext.VariableContext.RunID, runContext.LoopIndex, dirty); err != nil {
+		sendPoolErrorEvent(runContext, "pool:release", err.Error(), time.Since(startTime))
+		return err
+	}
+	if isVariable {
+		delete(runContext.VariableContext.RuntimeVars, account)
+	}
+
+	state := automation.PoolAccountAvailable
+	if dirty {
+		state = automation.PoolAccountDirty
+	}
+	sendPoolSuccessEvent(runContext, "pool:release", fmt.Sprintf("Released account %s as %s", accountID, state), time.Since(startTime))
+	return nil
+}
@@ -111,13 +111,16 @@ func (a *UploadAction) Execute(ctx context.Context, actionConfig map[string]inte
 	if runContext.EventCh != nil {
 		select {
 		case runContext.EventCh <- automation.RunEvent{
-			Type:       automation.RunEventTypeOutputFile,
-			Timestamp:  time.Now(),
-			StepName:   runContext.StepName,
-			ActionType: "r2:upload",
-			OutputFile: publicURL,
-			Duration:   duration.Milliseconds(),
-			LoopIndex:  runContext.LoopIndex,
+			Type:        automation.RunEventTypeOutputFile,
+			Timestamp:   time.Now(),
+			StepName:    runContext.StepName,
+			ActionType:  "r2:upload",
+			OutputFile:  publicURL,
+			StorageKey:  key,
+			ContentType: contentType,
+			Size:        int64(len(content)),
+			Duration:    duration.Milliseconds(),
+			LoopIndex:   runContext.LoopIndex,
 		}:
 		default:
 			// Channel is full, skip this event to avoid blocking
@@ -0,0 +1,178 @@
+// Package store provides the store:* actions backed by the run's encrypted key-value store.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+)
+
+func init() {
+	automation.RegisterAction("store:put", func() automation.PluginAction { return &PutAction{} })
+	automation.RegisterAction("store:get", func() automation.PluginAction { return &GetAction{} })
+}
+
+// Helper function to send success event for store actions
+func sendStoreSuccessEvent(runContext *automation.RunContext, actionType, message string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeLog,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Message:        message,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// Helper function to send error event for store actions
+func sendStoreErrorEvent(runContext *automation.RunContext, actionType, errorMsg string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeError,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Error:          errorMsg,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// storeKey resolves the namespaced key for the config. Persisted keys belong to an automation
+// (the current one unless 'automation_id' names another in the project), other keys to the run.
+func storeKey(actionType string, actionConfig map[string]interface{}, runContext *automation.RunContext) (string, string, bool, error) {
+	if runContext.KVStore == nil {
+		return "", "", false, fmt.Errorf("%s requires the key-value store, which is not configured on this server", actionType)
+	}
+	key, _ := actionConfig["key"].(string)
+	if key == "" {
+		return "", "", false, fmt.Errorf("%s action requires a 'key' string in config", actionType)
+	}
+
+	varContext := runContext.VariableContext
+	persist, _ := actionConfig["persist"].(bool)
+	if !persist {
+		return key, automation.RunKVKey(varContext.RunID, key), false, nil
+	}
+
+	automationID, _ := actionConfig["automation_id"].(string)
+	if automationID == "" {
+		automationID = varContext.AutomationID
+	}
+	return key, automation.AutomationKVKey(varContext.ProjectID, automationID, key), true, nil
+}
+
+// PutAction stores a value for later actions, runs or chained automations
+type PutAction struct{}
+
+func (a *PutAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	key, storageKey, persist, err := storeKey("store:put", actionConfig, runContext)
+	if err != nil {
+		return err
+	}
+	value, exists := actionConfig["value"]
+	if !exists {
+		return fmt.Errorf("store:put action requires a 'value' in config")
+	}
+
+	// Values keep their JSON type so objects and numbers read back as they were written
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("store:put value is not JSON serializable: %w", err)
+	}
+
+	ttl := automation.RunScopedKVTTL
+	if persist {
+		ttl = 0
+	}
+	if ttlSeconds, ok := actionConfig["ttl_seconds"].(float64); ok && ttlSeconds > 0 {
+		ttl = time.Duration(ttlSeconds) * time.Second
+	}
+
+	runContext.Logger.Info("Executing store:put", "key", key, "persist", persist, "ttl", ttl)
+
+	if err := runContext.KVStore.Put(ctx, storageKey, string(encoded), ttl); err != nil {
+		err = fmt.Errorf("store:put failed to store '%s': %w", key, err)
+		sendStoreErrorEvent(runContext, "store:put", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	sendStoreSuccessEvent(runContext, "store:put", fmt.Sprintf("Stored '%s'", key), time.Since(startTime))
+	return nil
+}
+
+// GetAction reads a stored value into a variable
+type GetAction struct{}
+
+func (a *GetAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	key, storageKey, persist, err := storeKey("store:get", actionConfig, runContext)
+	if err != nil {
+		return err
+	}
+	saveAs, _ := actionConfig["save_as"].(string)
+	if saveAs == "" {
+		return fmt.Errorf("store:get action requires a 'save_as' string in config")
+	}
+
+	runContext.Logger.Info("Executing store:get", "key", key, "persist", persist, "save_as", saveAs)
+
+	encoded, found, err := runContext.KVStore.Get(ctx, storageKey)
+	if err != nil {
+		err = fmt.Errorf("store:get failed to read '%s': %w", key, err)
+		sendStoreErrorEvent(runContext, "store:get", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	var value interface{}
+	if found {
+		if err := json.Unmarshal([]byte(encoded), &value); err != nil {
+			err = fmt.Errorf("store:get value of '%s' is not valid JSON: %w", key, err)
+			sendStoreErrorEvent(runContext, "store:get", err.Error(), time.Since(startTime))
+			return err
+		}
+	} else if defaultValue, hasDefault := actionConfig["default"]; hasDefault {
+		value = defaultValue
+	} else {
+		err := fmt.Errorf("store:get found no value for '%s'", key)
+		sendStoreErrorEvent(runContext, "store:get", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	automation.SaveVariable(actionConfig, runContext, value)
+
+	message := fmt.Sprintf("Read '%s' into runtime.%s", key, saveAs)
+	if !found {
+		message = fmt.Sprintf("No value for '%s', saved the default as runtime.%s", key, saveAs)
+	}
+	sendStoreSuccessEvent(runContext, "store:get", message, time.Since(startTime))
+	return nil
+}
@@ -0,0 +1,473 @@
+package util
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/qplayground/internal/modules/automation"
+)
+
+func init() {
+	automation.RegisterAction("util:set_variable", func() automation.PluginAction { return &SetVariableAction{} })
+	automation.RegisterAction("util:math", func() automation.PluginAction { return &MathAction{} })
+	automation.RegisterAction("util:string", func() automation.PluginAction { return &StringAction{} })
+	automation.RegisterAction("util:random_choice", func() automation.PluginAction { return &RandomChoiceAction{} })
+}
+
+// Helper function to send success event for util actions
+func sendUtilSuccessEvent(runContext *automation.RunContext, actionType, message string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeLog,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Message:        message,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// Helper function to send error event for util actions
+func sendUtilErrorEvent(runContext *automation.RunContext, actionType, errorMsg string, duration time.Duration) {
+	if runContext.EventCh != nil {
+		select {
+		case runContext.EventCh <- automation.RunEvent{
+			ParentActionID: runContext.ParentActionID,
+			LocalLoopIndex: runContext.VariableContext.LocalLoopIndex,
+			Type:           automation.RunEventTypeError,
+			Timestamp:      time.Now(),
+			StepName:       runContext.StepName,
+			ActionName:     runContext.ActionName,
+			StepID:         runContext.StepID,
+			ActionID:       runContext.ActionID,
+			ActionType:     actionType,
+			Error:          errorMsg,
+			Duration:       duration.Milliseconds(),
+			LoopIndex:      runContext.LoopIndex,
+		}:
+		default:
+			// Channel is full, skip this event to avoid blocking
+		}
+	}
+}
+
+// SetVariableAction sets a runtime variable to a value, optionally converted to another type
+type SetVariableAction struct{}
+
+func (a *SetVariableAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	value, exists := actionConfig["value"]
+	if !exists {
+		return fmt.Errorf("util:set_variable action requires a 'value' in config")
+	}
+
+	// Values arrive as strings once variables are resolved; value_type converts them back
+	if str, ok := value.(string); ok {
+		valueType, _ := actionConfig["value_type"].(string)
+		switch valueType {
+		case "", "string":
+		case "number":
+			number, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+			if err != nil {
+				return fmt.Errorf("util:set_variable value '%s' is not a number", str)
+			}
+			value = number
+		case "boolean":
+			boolean, err := strconv.ParseBool(strings.TrimSpace(str))
+			if err != nil {
+				return fmt.Errorf("util:set_variable value '%s' is not a boolean", str)
+			}
+			value = boolean
+		case "json":
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(str), &parsed); err != nil {
+				return fmt.Errorf("util:set_variable value is not valid JSON: %w", err)
+			}
+			value = parsed
+		default:
+			return fmt.Errorf("util:set_variable unsupported value_type: %s", valueType)
+		}
+	}
+
+	saveAs, err := automation.SaveVariable(actionConfig, runContext, value)
+	if err != nil {
+		return fmt.Errorf("util:set_variable %w", err)
+	}
+
+	runContext.Logger.Info("Executing util:set_variable", "save_as", saveAs, "value_type", fmt.Sprintf("%T", value))
+	sendUtilSuccessEvent(runContext, "util:set_variable", fmt.Sprintf("Set runtime.%s", saveAs), time.Since(startTime))
+	return nil
+}
+
+// MathAction applies an arithmetic operation to one or two operands
+type MathAction struct{}
+
+func (a *MathAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	operation, _ := actionConfig["operation"].(string)
+	if operation == "" {
+		return fmt.Errorf("util:math action requires an 'operation' string in config")
+	}
+
+	left, err := toNumber(actionConfig["a"])
+	if err != nil {
+		return fmt.Errorf("util:math operand 'a': %w", err)
+	}
+
+	var result float64
+	switch operation {
+	case "abs":
+		result = math.Abs(left)
+	case "round":
+		result = math.Round(left)
+	case "floor":
+		result = math.Floor(left)
+	case "ceil":
+		result = math.Ceil(left)
+	case "add", "subtract", "multiply", "divide", "modulo", "min", "max", "pow":
+		right, err := toNumber(actionConfig["b"])
+		if err != nil {
+			return fmt.Errorf("util:math operand 'b': %w", err)
+		}
+		switch operation {
+		case "add":
+			result = left + right
+		case "subtract":
+			result = left - right
+		case "multiply":
+			result = left * right
+		case "divide":
+			if right == 0 {
+				err := fmt.Errorf("util:math division by zero")
+				sendUtilErrorEvent(runContext, "util:math", err.Error(), time.Since(startTime))
+				return err
+			}
+			result = left / right
+		case "modulo":
+			if right == 0 {
+				err := fmt.Errorf("util:math modulo by zero")
+				sendUtilErrorEvent(runContext, "util:math", err.Error(), time.Since(startTime))
+				return err
+			}
+			result = math.Mod(left, right)
+		case "min":
+			result = math.Min(left, right)
+		case "max":
+			result = math.Max(left, right)
+		case "pow":
+			result = math.Pow(left, right)
+		}
+	default:
+		return fmt.Errorf("util:math unsupported operation: %s", operation)
+	}
+
+	if precision, ok := actionConfig["precision"].(float64); ok && precision >= 0 {
+		factor := math.Pow(10, precision)
+		result = math.Round(result*factor) / factor
+	}
+
+	saveAs, err := automation.SaveVariable(actionConfig, runContext, result)
+	if err != nil {
+		return fmt.Errorf("util:math %w", err)
+	}
+
+	runContext.Logger.Info("Executing util:math", "operation", operation, "result", result)
+	sendUtilSuccessEvent(runContext, "util:math", fmt.Sprintf("%s = %v, saved as runtime.%s", operation, result, saveAs), time.Since(startTime))
+	return nil
+}
+
+// toNumber converts a config operand, usually a resolved variable string, to a float
+func toNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a number", v)
+		}
+		return number, nil
+	case nil:
+		return 0, fmt.Errorf("value is required")
+	default:
+		return 0, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// StringAction applies a string operation to 'input' (or 'values' for concat)
+type StringAction struct{}
+
+func (a *StringAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	operation, _ := actionConfig["operation"].(string)
+	if operation == "" {
+		return fmt.Errorf("util:string action requires an 'operation' string in config")
+	}
+	input := fmt.Sprintf("%v", actionConfig["input"])
+	if actionConfig["input"] == nil {
+		input = ""
+	}
+
+	var result interface{}
+	var err error
+	switch operation {
+	case "concat":
+		values, _ := actionConfig["values"].([]interface{})
+		separator, _ := actionConfig["separator"].(string)
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = fmt.Sprintf("%v", value)
+		}
+		result = strings.Join(parts, separator)
+	case "substring":
+		result, err = substring(input, actionConfig)
+	case "regex_extract":
+		result, err = regexExtract(input, actionConfig)
+	case "replace":
+		search, _ := actionConfig["search"].(string)
+		replacement, _ := actionConfig["replacement"].(string)
+		result = strings.ReplaceAll(input, search, replacement)
+	case "upper":
+		result = strings.ToUpper(input)
+	case "lower":
+		result = strings.ToLower(input)
+	case "trim":
+		result = strings.TrimSpace(input)
+	case "length":
+		result = float64(len([]rune(input)))
+	case "base64_encode":
+		result = base64.StdEncoding.EncodeToString([]byte(input))
+	case "base64_decode":
+		var decoded []byte
+		decoded, err = base64.StdEncoding.DecodeString(input)
+		result = string(decoded)
+	case "url_encode":
+		result = url.QueryEscape(input)
+	case "url_decode":
+		result, err = url.QueryUnescape(input)
+	default:
+		return fmt.Errorf("util:string unsupported operation: %s", operation)
+	}
+	if err != nil {
+		err = fmt.Errorf("util:string %s failed: %w", operation, err)
+		sendUtilErrorEvent(runContext, "util:string", err.Error(), time.Since(startTime))
+		return err
+	}
+
+	saveAs, err := automation.SaveVariable(actionConfig, runContext, result)
+	if err != nil {
+		return fmt.Errorf("util:string %w", err)
+	}
+
+	runContext.Logger.Info("Executing util:string", "operation", operation, "save_as", saveAs)
+	sendUtilSuccessEvent(runContext, "util:string", fmt.Sprintf("Applied %s, saved as runtime.%s", operation, saveAs), time.Since(startTime))
+	return nil
+}
+
+// substring returns 'length' runes of input starting at 'start'; a negative start counts from the end
+func substring(input string, actionConfig map[string]interface{}) (string, error) {
+	runes := []rune(input)
+
+	start := 0
+	if s, ok := actionConfig["start"].(float64); ok {
+		start = int(s)
+	}
+	if start < 0 {
+		start += len(runes)
+	}
+	if start < 0 || start > len(runes) {
+		return "", fmt.Errorf("start %d is out of range for a string of length %d", start, len(runes))
+	}
+
+	end := len(runes)
+	if length, ok := actionConfig["length"].(float64); ok && length >= 0 {
+		end = min(start+int(length), len(runes))
+	}
+	return string(runes[start:end]), nil
+}
+
+// regexExtract returns the first match of 'pattern' (or capture 'group'), or every match when 'all' is set
+func regexExtract(input string, actionConfig map[string]interface{}) (interface{}, error) {
+	pattern, _ := actionConfig["pattern"].(string)
+	if pattern == "" {
+		return nil, fmt.Errorf("a 'pattern' string is required")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	group := 0
+	if g, ok := actionConfig["group"].(float64); ok {
+		group = int(g)
+	}
+	if group < 0 || group > re.NumSubexp() {
+		return nil, fmt.Errorf("pattern has no group %d", group)
+	}
+
+	if all, _ := actionConfig["all"].(bool); all {
+		var matches []interface{}
+		for _, match := range re.FindAllStringSubmatch(input, -1) {
+			matches = append(matches, match[group])
+		}
+		return matches, nil
+	}
+
+	match := re.FindStringSubmatch(input)
+	if match == nil {
+		return nil, fmt.Errorf("pattern '%s' did not match", pattern)
+	}
+	return match[group], nil
+}
+
+// RandomChoiceAction runs one of several groups of nested actions, picked at random by weight,
+// and records which branch was taken
+type RandomChoiceAction struct{}
+
+func (a *RandomChoiceAction) Execute(ctx context.Context, actionConfig map[string]interface{}, runContext *automation.RunContext) error {
+	startTime := time.Now()
+
+	branches, _ := actionConfig["branches"].([]interface{})
+	if len(branches) == 0 {
+		return fmt.Errorf("util:random_choice action requires a non-empty 'branches' array in config")
+	}
+
+	weights := make([]float64, len(branches))
+	var totalWeight float64
+	for i, branchInterface := range branches {
+		branch, ok := branchInterface.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("util:random_choice branch %d must be an object", i+1)
+		}
+		// Branches without a weight count as weight 1
+		weight := 1.0
+		if w, ok := branch["weight"].(float64); ok {
+			weight = w
+		}
+		if weight < 0 {
+			return fmt.Errorf("util:random_choice branch %d has a negative weight", i+1)
+		}
+		weights[i] = weight
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return fmt.Errorf("util:random_choice requires at least one branch with a positive weight")
+	}
+
+	// Pick a branch with probability proportional to its weight
+	chosen := len(branches) - 1
+	pick := rand.Float64() * totalWeight
+	for i, weight := range weights {
+		if pick < weight {
+			chosen = i
+			break
+		}
+		pick -= weight
+	}
+
+	branch := branches[chosen].(map[string]interface{})
+	branchName, _ := branch["name"].(string)
+	if branchName == "" {
+		branchName = fmt.Sprintf("branch %d", chosen+1)
+	}
+
+	runContext.Logger.Info("Executing util:random_choice", "branch", branchName, "index", chosen, "probability", weights[chosen]/totalWeight)
+
+	// Record the branch before running it, so later actions see it even if the branch fails
+	if saveAs, _ := actionConfig["save_as"].(string); saveAs != "" {
+		automation.SaveVariable(actionConfig, runContext, map[string]interface{}{
+			"name":  branchName,
+			"index": chosen,
+		})
+	}
+	sendUtilSuccessEvent(runContext, "util:random_choice", fmt.Sprintf("Took branch '%s' (%.0f%% chance)", branchName, weights[chosen]/totalWeight*100), time.Since(startTime))
+
+	actions, _ := branch["actions"].([]interface{})
+	if err := a.executeNestedActions(ctx, actions, runContext); err != nil {
+		err = fmt.Errorf("util:random_choice branch '%s': %w", branchName, err)
+		sendUtilErrorEvent(runContext, "util:random_choice", err.Error(), time.Since(startTime))
+		return err
+	}
+	return nil
+}
+
+// executeNestedActions executes a list of nested actions
+func (a *RandomChoiceAction) executeNestedActions(ctx context.Context, actions []interface{}, runContext *automation.RunContext) error {
+	for i, actionInterface := range actions {
+		actionMap, ok := actionInterface.(map[string]interface{})
+		if !ok {
+			runContext.Logger.Warn("Invalid nested action format", "index", i)
+			continue
+		}
+
+		actionType, ok := actionMap["action_type"].(string)
+		if !ok || actionType == "" {
+			runContext.Logger.Warn("Missing action_type in nested action", "index", i)
+			continue
+		}
+
+		actionConfig, ok := actionMap["action_config"].(map[string]interface{})
+		if !ok {
+			actionConfig = make(map[string]interface{})
+		}
+
+		// Resolve variables in nested action config
+		if runContext.Runner != nil {
+			resolvedActionConfig, err := runContext.Runner.ResolveVariablesInConfig(actionConfig, runContext.VariableContext, runContext.AutomationConfig)
+			if err != nil {
+				return fmt.Errorf("failed to resolve variables in nested action '%s': %w", actionType, err)
+			}
+			actionConfig = resolvedActionConfig
+		}
+
+		pluginAction, err := automation.GetAction(actionType)
+		if err != nil {
+			return fmt.Errorf("failed to get nested action '%s': %w", actionType, err)
+		}
+
+		// Set nested action context, restored once the action finishes
+		originalActionID := runContext.ActionID
+		originalParentActionID := runContext.ParentActionID
+		nestedActionID, _ := actionMap["id"].(string)
+		if nestedActionID == "" {
+			nestedActionID = fmt.Sprintf("%s-nested-%d", runContext.ActionID, i)
+		}
+		runContext.ParentActionID = runContext.ActionID
+		runContext.ActionID = nestedActionID
+
+		runContext.Logger.Info("Executing nested action", "index", i, "action_type", actionType)
+		err = pluginAction.Execute(ctx, actionConfig, runContext)
+
+		runContext.ActionID = originalActionID
+		runContext.ParentActionID = originalParentActionID
+
+		if err != nil {
+			return fmt.Errorf("nested action '%s' failed: %w", actionType, err)
+		}
+	}
+
+	return nil
+}
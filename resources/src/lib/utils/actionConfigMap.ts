@@ -7,16 +7,20 @@ import PlaywrightPressConfig from "../components/ActionConfigs/PlaywrightPressCo
 import PlaywrightCheckConfig from "../components/ActionConfigs/PlaywrightCheckConfig.svelte";
 import PlaywrightUncheckConfig from "../components/ActionConfigs/PlaywrightUncheckConfig.svelte";
 import PlaywrightSelectOptionConfig from "../components/ActionConfigs/PlaywrightSelectOptionConfig.svelte";
+import PlaywrightFillFormConfig from "../components/ActionConfigs/PlaywrightFillFormConfig.svelte";
 import PlaywrightHoverConfig from "../components/ActionConfigs/PlaywrightHoverConfig.svelte";
 import PlaywrightScrollConfig from "../components/ActionConfigs/PlaywrightScrollConfig.svelte";
 import PlaywrightGetTextConfig from "../components/ActionConfigs/PlaywrightGetTextConfig.svelte";
+import PlaywrightAssertTextConfig from "../components/ActionConfigs/PlaywrightAssertTextConfig.svelte";
 import PlaywrightGetAttributeConfig from "../components/ActionConfigs/PlaywrightGetAttributeConfig.svelte";
 import PlaywrightSetViewportConfig from "../components/ActionConfigs/PlaywrightSetViewportConfig.svelte";
 import PlaywrightReloadConfig from "../components/ActionConfigs/PlaywrightReloadConfig.svelte";
 import PlaywrightGoBackConfig from "../components/ActionConfigs/PlaywrightGoBackConfig.svelte";
 import PlaywrightGoForwardConfig from "../components/ActionConfigs/PlaywrightGoForwardConfig.svelte";
 import PlaywrightScreenshotConfig from "../components/ActionConfigs/PlaywrightScreenshotConfig.svelte";
+import PlaywrightDownloadConfig from "../components/ActionConfigs/PlaywrightDownloadConfig.svelte";
 import PlaywrightWaitConfig from "../components/ActionConfigs/PlaywrightWaitConfig.svelte";
+import PlaywrightPauseConfig from "../components/ActionConfigs/PlaywrightPauseConfig.svelte";
 import PlaywrightEvaluateConfig from "../components/ActionConfigs/PlaywrightEvaluateConfig.svelte";
 import R2UploadConfig from "../components/ActionConfigs/R2UploadConfig.svelte";
 import R2DeleteConfig from "../components/ActionConfigs/R2DeleteConfig.svelte";
@@ -30,6 +34,27 @@ import ApiPatchConfig from "../components/ActionConfigs/ApiPatchConfig.svelte";
 import ApiDeleteConfig from "../components/ActionConfigs/ApiDeleteConfig.svelte";
 import ApiIfElseConfig from "../components/ActionConfigs/ApiIfElseConfig.svelte";
 import ApiRuntimeLoopUntilConfig from "../components/ActionConfigs/ApiRuntimeLoopUntilConfig.svelte";
+import ApiWaitUntilConfig from "../components/ActionConfigs/ApiWaitUntilConfig.svelte";
+import ApiCreateAndWaitConfig from "../components/ActionConfigs/ApiCreateAndWaitConfig.svelte";
+import ApiBatchConfig from "../components/ActionConfigs/ApiBatchConfig.svelte";
+import DataGenerateConfig from "../components/ActionConfigs/DataGenerateConfig.svelte";
+import DataDatasetConfig from "../components/ActionConfigs/DataDatasetConfig.svelte";
+import UtilSetVariableConfig from "../components/ActionConfigs/UtilSetVariableConfig.svelte";
+import UtilMathConfig from "../components/ActionConfigs/UtilMathConfig.svelte";
+import UtilStringConfig from "../components/ActionConfigs/UtilStringConfig.svelte";
+import UtilRandomChoiceConfig from "../components/ActionConfigs/UtilRandomChoiceConfig.svelte";
+import TimeNowConfig from "../components/ActionConfigs/TimeNowConfig.svelte";
+import TimeAddConfig from "../components/ActionConfigs/TimeAddConfig.svelte";
+import TimeWaitUntilConfig from "../components/ActionConfigs/TimeWaitUntilConfig.svelte";
+import StorePutConfig from "../components/ActionConfigs/StorePutConfig.svelte";
+import StoreGetConfig from "../components/ActionConfigs/StoreGetConfig.svelte";
+import SyncBarrierConfig from "../components/ActionConfigs/SyncBarrierConfig.svelte";
+import SyncSignalConfig from "../components/ActionConfigs/SyncSignalConfig.svelte";
+import SyncWaitConfig from "../components/ActionConfigs/SyncWaitConfig.svelte";
+import PoolLeaseConfig from "../components/ActionConfigs/PoolLeaseConfig.svelte";
+import PoolReleaseConfig from "../components/ActionConfigs/PoolReleaseConfig.svelte";
+import MetricStartTimerConfig from "../components/ActionConfigs/MetricStartTimerConfig.svelte";
+import MetricStopTimerConfig from "../components/ActionConfigs/MetricStopTimerConfig.svelte";
 import ApiLogConfig from "../components/ActionConfigs/ApiLogConfig.svelte";
 
 // List of supported action types
@@ -42,22 +67,26 @@ export const actionTypes = [
   "playwright:check",
   "playwright:uncheck",
   "playwright:select_option",
+  "playwright:fill_form",
   "playwright:wait_for_selector",
   "playwright:if_else",
   "playwright:log",
   "playwright:wait_for_timeout",
   "playwright:wait_for_load_state",
   "playwright:screenshot",
+  "playwright:download",
   "playwright:evaluate",
   "playwright:hover",
   "playwright:scroll",
   "playwright:get_text",
+  "playwright:assert_text",
   "playwright:get_attribute",
   "playwright:set_viewport",
   "playwright:reload",
   "playwright:go_back",
   "playwright:go_forward",
   "playwright:loop_until",
+  "playwright:pause",
   "r2:upload",
   "r2:delete",
   "api:get",
@@ -67,7 +96,28 @@ export const actionTypes = [
   "api:delete",
   "api:if_else",
   "api:runtime_loop_until",
+  "api:wait_until",
+  "api:create_and_wait",
+  "api:batch",
   "api:log",
+  "data:generate",
+  "data:dataset",
+  "util:set_variable",
+  "util:math",
+  "util:string",
+  "util:random_choice",
+  "time:now",
+  "time:add",
+  "time:wait_until",
+  "store:put",
+  "store:get",
+  "sync:barrier",
+  "sync:signal",
+  "sync:wait",
+  "pool:lease",
+  "pool:release",
+  "metric:start_timer",
+  "metric:stop_timer",
 ];
 
 // List of action types that can be used in nested contexts (excluding if_else to prevent infinite nesting)
@@ -88,15 +138,18 @@ export const actionConfigComponents: Record<string, any> = {
   "playwright:check": PlaywrightCheckConfig,
   "playwright:uncheck": PlaywrightUncheckConfig,
   "playwright:select_option": PlaywrightSelectOptionConfig,
+  "playwright:fill_form": PlaywrightFillFormConfig,
   "playwright:hover": PlaywrightHoverConfig,
   "playwright:scroll": PlaywrightScrollConfig,
   "playwright:get_text": PlaywrightGetTextConfig,
+  "playwright:assert_text": PlaywrightAssertTextConfig,
   "playwright:get_attribute": PlaywrightGetAttributeConfig,
   "playwright:set_viewport": PlaywrightSetViewportConfig,
   "playwright:reload": PlaywrightReloadConfig,
   "playwright:go_back": PlaywrightGoBackConfig,
   "playwright:go_forward": PlaywrightGoForwardConfig,
   "playwright:screenshot": PlaywrightScreenshotConfig,
+  "playwright:download": PlaywrightDownloadConfig,
   "playwright:wait_for_selector": PlaywrightWaitConfig,
   "playwright:wait_for_timeout": PlaywrightWaitConfig,
   "playwright:wait_for_load_state": PlaywrightWaitConfig,
@@ -106,6 +159,7 @@ export const actionConfigComponents: Record<string, any> = {
   "playwright:if_else": PlaywrightIfElseConfig,
   "playwright:log": PlaywrightLogConfig,
   "playwright:loop_until": PlaywrightLoopUntilConfig,
+  "playwright:pause": PlaywrightPauseConfig,
   "api:get": ApiGetConfig,
   "api:post": ApiPostConfig,
   "api:put": ApiPutConfig,
@@ -113,7 +167,28 @@ export const actionConfigComponents: Record<string, any> = {
   "api:delete": ApiDeleteConfig,
   "api:if_else": ApiIfElseConfig,
   "api:runtime_loop_until": ApiRuntimeLoopUntilConfig,
+  "api:wait_until": ApiWaitUntilConfig,
+  "api:create_and_wait": ApiCreateAndWaitConfig,
+  "api:batch": ApiBatchConfig,
   "api:log": ApiLogConfig,
+  "data:generate": DataGenerateConfig,
+  "data:dataset": DataDatasetConfig,
+  "util:set_variable": UtilSetVariableConfig,
+  "util:math": UtilMathConfig,
+  "util:string": UtilStringConfig,
+  "util:random_choice": UtilRandomChoiceConfig,
+  "time:now": TimeNowConfig,
+  "time:add": TimeAddConfig,
+  "time:wait_until": TimeWaitUntilConfig,
+  "store:put": StorePutConfig,
+  "store:get": StoreGetConfig,
+  "sync:barrier": SyncBarrierConfig,
+  "sync:signal": SyncSignalConfig,
+  "sync:wait": SyncWaitConfig,
+  "pool:lease": PoolLeaseConfig,
+  "pool:release": PoolReleaseConfig,
+  "metric:start_timer": MetricStartTimerConfig,
+  "metric:stop_timer": MetricStopTimerConfig,
 };
 
 // Validation function for action configurations
@@ -135,6 +210,7 @@ export function validateActionConfig(
     case "playwright:uncheck":
     case "playwright:select_option":
     case "playwright:hover":
+    case "playwright:download":
     case "playwright:get_text":
     case "playwright:get_attribute":
     case "playwright:wait_for_selector":
@@ -153,20 +229,29 @@ export function validateActionConfig(
         }
       }
       break;
+    case "playwright:fill_form":
+      if (!Array.isArray(config.fields) || config.fields.length === 0)
+        errors.push("At least one field is required");
+      break;
     case "playwright:wait_for_timeout":
       if (!config.timeout_ms || config.timeout_ms <= 0)
         errors.push("Timeout (ms) is required and must be positive");
       break;
+    case "playwright:assert_text":
+      if (!config.selector) errors.push("Selector is required");
+      if (!config.expected && !config.message_key)
+        errors.push("Expected text or a message key is required");
+      break;
+    case "playwright:pause":
+      if (config.timeout_ms !== undefined && (config.timeout_ms < 0 || config.timeout_ms > 1800000))
+        errors.push("Pause timeout must be between 0 and 1800000 ms");
+      break;
     case "playwright:set_viewport":
       if (!config.width || config.width <= 0)
         errors.push("Width is required and must be positive");
       if (!config.height || config.height <= 0)
         errors.push("Height is required and must be positive");
       break;
-    case "playwright:screenshot":
-      if (config.upload_to_r2 && !config.r2_key)
-        errors.push("R2 key is required when uploading to R2");
-      break;
     case "playwright:evaluate":
       if (!config.expression)
         errors.push("JavaScript expression is required");
@@ -368,9 +453,119 @@ export function validateActionConfig(
         }
       }
       break;
+    case "api:wait_until":
+      if (!config.url) errors.push("URL is required");
+      if (!config.condition_type) errors.push("Condition type is required");
+      if (!["is_null", "is_not_null", "is_true", "is_false"].includes(config.condition_type) && config.expected_value === undefined) {
+        errors.push("Expected value is required for this condition type");
+      }
+      if (config.timeout_ms && config.timeout_ms <= 0) errors.push("Timeout must be a positive number");
+      if (config.backoff_multiplier && config.backoff_multiplier < 1) errors.push("Backoff multiplier must be at least 1");
+      break;
+    case "api:create_and_wait":
+      if (!config.url) errors.push("Creation URL is required");
+      if (!config.status_url) errors.push("Status URL is required");
+      if (!config.target_state) errors.push("Target state is required");
+      if (config.timeout_ms && config.timeout_ms <= 0) errors.push("Timeout must be a positive number");
+      if (config.backoff_multiplier && config.backoff_multiplier < 1) errors.push("Backoff multiplier must be at least 1");
+      break;
+    case "api:batch":
+      if (!config.requests || config.requests.length === 0) {
+        errors.push("At least one request is required");
+      } else if (config.requests.some((request: any) => !request.url)) {
+        errors.push("URL is required for all requests");
+      }
+      if (config.concurrency && config.concurrency < 1) errors.push("Concurrency must be at least 1");
+      if (!config.save_as) errors.push("Save as variable name is required");
+      break;
     case "api:log":
       if (!config.message) errors.push("Log message is required");
       break;
+    case "data:generate":
+      if (!config.count || config.count < 1) errors.push("Record count must be positive");
+      if (config.count > 10000) errors.push("At most 10000 records can be generated");
+      if (!config.save_as) errors.push("Save as variable name is required");
+      if (!config.fields || config.fields.length === 0) {
+        errors.push("At least one field is required");
+      } else if (config.fields.some((field: any) => !field.name || !field.type)) {
+        errors.push("All fields need a name and a type");
+      }
+      break;
+    case "data:dataset":
+      if (!config.dataset) errors.push("Dataset name is required");
+      if (!config.save_as) errors.push("Save as variable name is required");
+      break;
+    case "util:set_variable":
+    case "util:math":
+    case "util:string":
+      if (!config.save_as) errors.push("Save as variable name is required");
+      if (actionType === "util:set_variable" && config.value === undefined)
+        errors.push("Value is required");
+      if (actionType === "util:math") {
+        if (!config.operation) errors.push("Operation is required");
+        if (config.a === undefined || config.a === "") errors.push("Operand A is required");
+        if (!["abs", "round", "floor", "ceil"].includes(config.operation) && (config.b === undefined || config.b === ""))
+          errors.push("Operand B is required for this operation");
+      }
+      if (actionType === "util:string") {
+        if (!config.operation) errors.push("Operation is required");
+        if (config.operation === "regex_extract" && !config.pattern) errors.push("Pattern is required");
+        if (config.operation === "replace" && !config.search) errors.push("Search text is required");
+      }
+      break;
+    case "util:random_choice":
+      if (!config.branches || config.branches.length === 0) {
+        errors.push("At least one branch is required");
+        break;
+      }
+      if (config.branches.some((branch: any) => branch.weight < 0))
+        errors.push("Branch weights cannot be negative");
+      if (!config.branches.some((branch: any) => branch.weight > 0))
+        errors.push("At least one branch needs a positive weight");
+      for (const branch of config.branches) {
+        if (branch.actions?.some((action: any) => !action.action_type)) {
+          errors.push("All branch actions must have an action type");
+          break;
+        }
+      }
+      break;
+    case "time:now":
+    case "time:add":
+      if (!config.save_as) errors.push("Save as variable name is required");
+      if (actionType === "time:add" && !config.duration && !config.days && !config.months && !config.years)
+        errors.push("A duration, days, months or years offset is required");
+      break;
+    case "time:wait_until":
+      if (!config.time) errors.push("Target time is required");
+      if (config.max_wait_ms !== undefined && config.max_wait_ms !== null && config.max_wait_ms < 1000)
+        errors.push("Maximum wait must be at least 1000ms");
+      break;
+    case "store:put":
+    case "store:get":
+      if (!config.key) errors.push("Key is required");
+      if (actionType === "store:put" && config.value === undefined) errors.push("Value is required");
+      if (actionType === "store:get" && !config.save_as) errors.push("Save as variable name is required");
+      break;
+    case "sync:barrier":
+    case "sync:signal":
+    case "sync:wait":
+      if (!config.name) errors.push("Name is required");
+      if (config.parties !== undefined && config.parties !== null && config.parties < 1)
+        errors.push("Users to wait for must be at least 1");
+      break;
+    case "pool:lease":
+      if (!config.pool) errors.push("Pool name is required");
+      if (!config.save_as) errors.push("Save as variable name is required");
+      if (config.wait_timeout_ms !== undefined && config.wait_timeout_ms !== null && config.wait_timeout_ms < 0)
+        errors.push("Wait timeout cannot be negative");
+      break;
+    case "pool:release":
+      if (!config.account) errors.push("Account is required");
+      break;
+    case "metric:start_timer":
+    case "metric:stop_timer":
+      if (!config.name) errors.push("Timer name is required");
+      break;
   }
 
   return errors;